@@ -2,23 +2,58 @@ package main
 
 import (
     "context"
+    "database/sql"
     "fmt"
     "log"
+    "net"
     "net/http"
+    "net/url"
     "os"
     "os/signal"
     "syscall"
     "time"
 
     "github.com/gin-gonic/gin"
+    "github.com/go-redis/redis/v8"
+    _ "github.com/lib/pq"
+    "github.com/prometheus/client_golang/prometheus"
+    "github.com/prometheus/client_golang/prometheus/collectors"
 
+    "rate-limiter/internal/adminauth"
+    "rate-limiter/internal/analytics"
+    "rate-limiter/internal/audit"
+    "rate-limiter/internal/anomaly"
+    "rate-limiter/internal/capacity"
+    "rate-limiter/internal/cache"
+    "rate-limiter/internal/cluster"
     "rate-limiter/internal/config"
+    "rate-limiter/internal/decisiontrace"
+    "rate-limiter/internal/domain"
+    "rate-limiter/internal/drain"
+    "rate-limiter/internal/events"
     "rate-limiter/internal/handler"
+    "rate-limiter/internal/learning"
     "rate-limiter/internal/logger"
+    "rate-limiter/internal/maintenance"
+    "rate-limiter/internal/metrics"
+    "rate-limiter/internal/middleware"
+    "rate-limiter/internal/observe"
+    "rate-limiter/internal/proxy"
+    "rate-limiter/internal/reports"
+    "rate-limiter/internal/rollout"
+    "rate-limiter/internal/security"
     "rate-limiter/internal/service"
+    "rate-limiter/internal/shadow"
+    "rate-limiter/internal/staging"
+    "rate-limiter/internal/stats"
     "rate-limiter/internal/storage"
+    "rate-limiter/internal/tracing"
+    "rate-limiter/pkg/version"
 )
 
+// appVersion é a versão reportada nos logs e no registro de instâncias
+const appVersion = version.Version
+
 func main() {
 	// Carregar configurações
 	configLoader := config.NewConfigLoader()
@@ -33,11 +68,64 @@ func main() {
     // Inicializar logger
 	appLogger := logger.NewLogger(serverConfig.LogLevel, serverConfig.LogFormat)
 	appLogger.Info("Starting Rate Limiter API", map[string]interface{}{
-		"version":   "1.0.0",
+		"version":   appVersion,
 		"log_level": serverConfig.LogLevel,
 		"port":      serverConfig.ServerPort,
 	})
 
+	// Tracing: spans do middleware, do service e dos storages exportados por OTLP
+	shutdownTracing := tracing.Shutdown(func(context.Context) error { return nil })
+	if serverConfig.TracingEndpoint != "" {
+		shutdownTracing, err = tracing.Setup(context.Background(), tracing.Config{
+			Endpoint:       serverConfig.TracingEndpoint,
+			ServiceName:    serverConfig.TracingServiceName,
+			ServiceVersion: appVersion,
+			SampleRatio:    serverConfig.TracingSampleRatio,
+		})
+		if err != nil {
+			log.Fatalf("Failed to initialize tracing: %v", err)
+		}
+		appLogger.Info("OpenTelemetry tracing enabled", map[string]interface{}{
+			"endpoint":     serverConfig.TracingEndpoint,
+			"service_name": serverConfig.TracingServiceName,
+			"sample_ratio": serverConfig.TracingSampleRatio,
+		})
+	}
+
+	// Métricas do limiter (decisões, cota restante, bloqueios, latência do storage) por OTLP
+	shutdownMetrics := tracing.Shutdown(func(context.Context) error { return nil })
+	if serverConfig.MetricsEndpoint != "" {
+		shutdownMetrics, err = tracing.SetupMetrics(context.Background(), tracing.MetricsConfig{
+			Endpoint:       serverConfig.MetricsEndpoint,
+			ServiceName:    serverConfig.TracingServiceName,
+			ServiceVersion: appVersion,
+			Interval:       time.Duration(serverConfig.MetricsExportInterval) * time.Millisecond,
+		})
+		if err != nil {
+			log.Fatalf("Failed to initialize metrics export: %v", err)
+		}
+		appLogger.Info("OpenTelemetry metrics export enabled", map[string]interface{}{
+			"endpoint":    serverConfig.MetricsEndpoint,
+			"interval_ms": serverConfig.MetricsExportInterval,
+		})
+	}
+
+	// Criptografia em repouso: identificadores nas chaves e status gravados no Redis
+	var cipher *storage.Cipher
+	if serverConfig.StorageEncryptionKey != "" || serverConfig.StorageEncryptionKeyFile != "" {
+		encryptionKey, err := storage.LoadEncryptionKey(serverConfig.StorageEncryptionKey, serverConfig.StorageEncryptionKeyFile)
+		if err != nil {
+			log.Fatalf("Failed to load storage encryption key: %v", err)
+		}
+		cipher, err = storage.NewCipher(encryptionKey)
+		if err != nil {
+			log.Fatalf("Failed to initialize storage encryption: %v", err)
+		}
+		appLogger.Info("Storage encrypted at rest", map[string]interface{}{
+			"key_bits": len(encryptionKey) * 8,
+		})
+	}
+
     // Inicializar storage: usar Redis por padrão; permitir alternar via STORAGE_TYPE
     storageType := os.Getenv("STORAGE_TYPE")
     if storageType == "" {
@@ -51,6 +139,26 @@ func main() {
         serverConfig.RedisPassword,
         serverConfig.RedisDB,
     )
+    // Novas tentativas no Redis, suspensas enquanto o circuito do health monitor não fecha
+    retryPolicy := newRetryPolicy(serverConfig)
+    if storageCfg.RedisConfig != nil {
+        storageCfg.RedisConfig = newRedisConfig(serverConfig)
+        storageCfg.RedisConfig.RetryPolicy = retryPolicy
+    }
+    storageCfg.MemoryConfig = &storage.MemoryConfig{ExpectedKeys: serverConfig.MemoryExpectedKeys, MaxEntries: serverConfig.MemoryMaxKeys}
+    storageCfg.HybridConfig = newHybridConfig(serverConfig)
+    storageCfg.TieredConfig = &storage.TieredConfig{CacheTTL: time.Duration(serverConfig.TieredCacheTTL) * time.Millisecond}
+    storageCfg.EtcdConfig = newEtcdConfig(serverConfig)
+    storageCfg.Algorithm = storage.Algorithm(serverConfig.RateLimitAlgorithm)
+    storageCfg.HistorySize = serverConfig.WindowHistorySize
+    storageCfg.BlockFilter = newBlockFilterConfig(serverConfig)
+    storageCfg.Cipher = cipher
+    if !storage.SupportsAlgorithm(storage.StorageType(storageType), storageCfg.Algorithm) {
+        log.Fatalf("STORAGE_TYPE %s does not support RATE_LIMIT_ALGORITHM %s", storageType, serverConfig.RateLimitAlgorithm)
+    }
+    if err := storage.ValidateEncryption(storageCfg); err != nil {
+        log.Fatalf("STORAGE_ENCRYPTION_KEY: %v", err)
+    }
 
     factory := storage.NewStorageFactory()
     rateLimiterStorage, err := factory.CreateStorage(storageCfg, appLogger)
@@ -58,18 +166,467 @@ func main() {
         appLogger.Error("Failed to initialize configured storage, falling back to memory", err, map[string]interface{}{
             "storage_type": storageType,
         })
-        rateLimiterStorage = storage.NewMemoryStorage(appLogger)
+        // O fallback mantém o algoritmo configurado
+        rateLimiterStorage, err = factory.CreateStorage(&storage.StorageConfig{
+            Type:         storage.MemoryStorageType,
+            MemoryConfig: storageCfg.MemoryConfig,
+            Algorithm:    storageCfg.Algorithm,
+            HistorySize:  storageCfg.HistorySize,
+        }, appLogger)
+        if err != nil {
+            log.Fatalf("Failed to initialize fallback memory storage: %v", err)
+        }
+        storageType = string(storage.MemoryStorageType)
     } else {
         appLogger.Info("Storage initialized", map[string]interface{}{
-            "type": storageType,
+            "type":      storageType,
+            "algorithm": string(storageCfg.Algorithm),
         })
     }
 
+	// Registro de storages nomeados: regras podem fixar um backend específico
+	registry := storage.NewRegistry(storageType, rateLimiterStorage)
+	defer registry.Close()
+	registerReferencedStorages(registry, factory, cfg, serverConfig, cipher, appLogger)
+
+	// Monitor de saúde do storage em background (com reconexão automática)
+	healthMonitor := storage.NewHealthMonitor(
+		rateLimiterStorage,
+		appLogger,
+		time.Duration(serverConfig.HealthCheckInterval)*time.Second,
+		time.Duration(serverConfig.HealthCheckMaxBackoff)*time.Second,
+	)
+	healthMonitor.SetRecoveryChecks(serverConfig.HealthRecoveryChecks)
+	retryPolicy.SetCircuit(healthMonitor)
+	healthMonitor.Start()
+	defer healthMonitor.Stop()
+
+	// Readiness só libera tráfego depois de N checks saudáveis consecutivos
+	readinessGate := storage.NewReadinessGate(healthMonitor, serverConfig.ReadinessHealthChecks)
+
+	serviceOptions := []service.Option{
+		service.WithHealthReporter(healthMonitor),
+	}
+	if serverConfig.RedisHashTags {
+		serviceOptions = append(serviceOptions, service.WithHashTaggedKeys())
+	}
+	if cipher != nil {
+		serviceOptions = append(serviceOptions, service.WithEncryptedKeys(cipher))
+	}
+
+	// Snapshot do storage em memória: um reinício curto não zera contadores nem
+	// desbloqueia clientes. Fechado antes do registry, que limpa o storage
+	if memoryStorage, ok := rateLimiterStorage.(*storage.MemoryStorage); ok && serverConfig.MemorySnapshotPath != "" {
+		snapshotter := storage.NewMemorySnapshotter(memoryStorage, storage.MemorySnapshotConfig{
+			Path:     serverConfig.MemorySnapshotPath,
+			Interval: time.Duration(serverConfig.MemorySnapshotInterval) * time.Second,
+			Cipher:   cipher,
+		}, appLogger)
+		defer func() {
+			if err := snapshotter.Close(); err != nil {
+				appLogger.Error("Failed to write final memory snapshot", err, nil)
+			}
+		}()
+	}
+
+	// Fonte de tokens no banco (billing): snapshot periódico ou cache read-through
+	if serverConfig.TokenSource == "sql" && serverConfig.TokenCacheTTL > 0 {
+		tokenSource, err := newSQLTokenSource(serverConfig, appLogger)
+		if err != nil {
+			log.Fatalf("Failed to initialize SQL token source: %v", err)
+		}
+
+		tokenCache := cache.NewTokenConfigCache(
+			tokenSource,
+			time.Duration(serverConfig.TokenCacheTTL)*time.Second,
+			time.Duration(serverConfig.TokenNegativeTTL)*time.Second,
+			appLogger,
+		)
+		serviceOptions = append(serviceOptions, service.WithTokenConfigProvider(tokenCache))
+		appLogger.Info("Token configs resolved on demand through cache", map[string]interface{}{
+			"table":            serverConfig.TokenDBTable,
+			"ttl_seconds":      serverConfig.TokenCacheTTL,
+			"negative_seconds": serverConfig.TokenNegativeTTL,
+		})
+	} else if serverConfig.TokenSource == "sql" {
+		tokenSource, err := newSQLTokenSource(serverConfig, appLogger)
+		if err != nil {
+			log.Fatalf("Failed to initialize SQL token source: %v", err)
+		}
+		defer tokenSource.Stop()
+
+		if tokens, err := tokenSource.LoadTokenConfigs(); err != nil {
+			appLogger.Error("Failed to load token configs from database, will retry in background", err, nil)
+			// Sem os tokens a instância aplicaria os limites padrão: a readiness espera a primeira carga
+			readinessGate.Require("token_configs")
+			tokenSource.OnLoad(func() { readinessGate.Complete("token_configs") })
+		} else {
+			cfg.TokenConfigs = tokens
+			appLogger.Info("Token configs loaded from database", map[string]interface{}{
+				"tokens": len(tokens),
+				"table":  serverConfig.TokenDBTable,
+			})
+		}
+
+		tokenSource.Start(time.Duration(serverConfig.TokenRefreshInterval) * time.Second)
+		serviceOptions = append(serviceOptions, service.WithTokenConfigProvider(tokenSource))
+	}
+
+	// Registro da instância na frota (heartbeats no storage compartilhado)
+	membership := newMembership(serverConfig, cfg, storageType, appLogger)
+	membership.Start()
+	defer membership.Stop()
+
+	// Particionamento: limites de storages locais divididos entre réplicas vivas
+	if serverConfig.PartitionLimits {
+		serviceOptions = append(serviceOptions, service.WithLimitPartitioning(membership))
+		appLogger.Info("Instance-aware limit partitioning enabled", map[string]interface{}{
+			"instance_id":    membership.Self().ID,
+			"live_instances": membership.LiveInstances(),
+		})
+	}
+
+	// Resets administrativos propagados para as demais réplicas
+	invalidationBus := newInvalidationBus(serverConfig, storageType, membership.Self().ID, appLogger)
+	defer invalidationBus.Stop()
+	serviceOptions = append(serviceOptions, service.WithInvalidation(invalidationBus))
+
+	// Overrides temporários gravados no Redis valem para todas as réplicas e sobrevivem a reinícios
+	if usesRedis(storageType) {
+		overrideStore := cluster.NewRedisOverrideStore(newRedisClient(serverConfig))
+		serviceOptions = append(serviceOptions, service.WithOverrideStore(overrideStore))
+	}
+
+	// Eventos operacionais (transições de manutenção, anomalias etc.) registrados no log
+	eventBus := events.NewBus()
+	eventBus.Subscribe(events.LogHandler(appLogger))
+	if serverConfig.EventsWebhookURL != "" {
+		// Registrado antes dos produtores: o defer fecha o webhook por último
+		webhook := events.NewWebhook(serverConfig.EventsWebhookURL, appLogger)
+		defer webhook.Close()
+		eventBus.Subscribe(webhook.Handle)
+	}
+
+	// Eventos de enforcement (bloqueios, avisos de soft limit, overrides) em barramento próprio:
+	// o volume não passa pelo log nem pelo webhook de eventos operacionais
+	enforcementEvents := events.NewBus()
+
+	// Bloqueios e desbloqueios em um Redis Stream limitado (consumidores externos e /admin/events/blocks)
+	var blockEventStream *events.RedisStream
+	if serverConfig.BlockEventsStream != "" {
+		blockEventStream = newBlockEventStream(serverConfig, appLogger)
+		defer blockEventStream.Close()
+		enforcementEvents.Subscribe(events.Filter(blockEventStream.Handle, service.EventKeyBlocked, service.EventKeyUnblocked))
+	}
+
+	// Canal de log de segurança com severidade, para alertas de SIEM
+	if serverConfig.SecurityLog {
+		securityLog, closeSecurityLog, err := newSecurityLog(serverConfig)
+		if err != nil {
+			log.Fatalf("Failed to open security log: %v", err)
+		}
+		defer closeSecurityLog()
+		enforcementEvents.Subscribe(securityLog.Handle)
+		serviceOptions = append(serviceOptions, service.WithSoftLimitWarning(serverConfig.SoftLimitWarningPercent))
+		appLogger.Info("Security log enabled", map[string]interface{}{
+			"path":         serverConfig.SecurityLogPath,
+			"min_severity": serverConfig.SecurityLogMinSeverity,
+		})
+	}
+
+	if serverConfig.BlockEventsStream != "" || serverConfig.SecurityLog {
+		serviceOptions = append(serviceOptions, service.WithEventPublisher(enforcementEvents))
+	}
+
+	// Webhooks de uso dos tokens (webhookUrl/webhookThreshold no tokens.json)
+	clientWebhooks := events.NewClientWebhooks(appLogger)
+	defer clientWebhooks.Close()
+	serviceOptions = append(serviceOptions, service.WithUsageNotifier(clientWebhooks))
+
+	// Janelas de manutenção: arquivo declarado + admin API
+	maintenanceManager := newMaintenanceManager(serverConfig, eventBus, appLogger)
+	maintenanceManager.Start(time.Second)
+	defer maintenanceManager.Stop()
+	serviceOptions = append(serviceOptions, service.WithMaintenance(maintenanceManager))
+
+	// Configuração staged com promoção explícita (/admin/config)
+	configStager := staging.NewManager(cfg, appLogger)
+	serviceOptions = append(serviceOptions, service.WithConfigSource(configStager))
+
+	// Rollouts canário de novas versões de regras (admin API)
+	rolloutManager := rollout.NewManager(appLogger)
+	serviceOptions = append(serviceOptions, service.WithRuleRollouts(rolloutManager))
+
+	// Avaliação em shadow: decisões sob a config staged, sem aplicar
+	shadowEvaluator := newShadowEvaluator(serverConfig, cfg, rateLimiterStorage, appLogger)
+	if shadowEvaluator != nil {
+		serviceOptions = append(serviceOptions, service.WithShadowObserver(shadowEvaluator))
+	}
+
+	// Detecção de picos de tráfego antes que os limites sejam atingidos
+	if serverConfig.AnomalyDetection {
+		detector := anomaly.NewDetector(anomaly.Config{
+			Interval:    time.Duration(serverConfig.AnomalyInterval) * time.Second,
+			ZThreshold:  serverConfig.AnomalyZThreshold,
+			MinRequests: serverConfig.AnomalyMinRequests,
+		}, eventBus, appLogger)
+		detector.Start()
+		defer detector.Stop()
+		serviceOptions = append(serviceOptions, service.WithTrafficObserver(detector))
+	}
+
+	// Amostragem ao vivo da taxa de uma chave (/admin/observe)
+	keyObserver := observe.NewObserver(observe.DefaultMaxWatches)
+	serviceOptions = append(serviceOptions, service.WithTrafficObserver(keyObserver))
+
+	// Gravação das próximas decisões completas de uma chave (/admin/debug/decisions)
+	decisionRecorder := decisiontrace.NewRecorder(decisiontrace.DefaultMaxWatches)
+	serviceOptions = append(serviceOptions, service.WithDecisionTracer(decisionRecorder))
+
+	// Planejamento de capacidade: limites sugeridos a partir do tráfego real (/admin/capacity)
+	var capacityPlanner *capacity.Planner
+	if serverConfig.CapacityPlanning {
+		capacityPlanner = capacity.NewPlanner(capacity.Config{}, configStager)
+		capacityPlanner.Start()
+		defer capacityPlanner.Stop()
+		serviceOptions = append(serviceOptions, service.WithTrafficObserver(capacityPlanner))
+	}
+
+	// Modo de aprendizado: limites por chave a partir da taxa normal (/admin/learning)
+	var limitLearner *learning.Learner
+	if serverConfig.LearningMode == "propose" || serverConfig.LearningMode == "apply" {
+		limitLearner = learning.NewLearner(learning.Config{
+			TrainingPeriod: time.Duration(serverConfig.LearningTrainingPeriod) * time.Second,
+			Headroom:       serverConfig.LearningHeadroom,
+			MinFactor:      serverConfig.LearningMinFactor,
+			MaxFactor:      serverConfig.LearningMaxFactor,
+			AutoApply:      serverConfig.LearningMode == "apply",
+		}, configStager, appLogger)
+		limitLearner.Start()
+		defer limitLearner.Stop()
+		serviceOptions = append(serviceOptions, service.WithTrafficObserver(limitLearner))
+	}
+
+	// Identidades que não aplicam o limite também são contabilizadas
+	if serverConfig.EvaluateAllIdentities {
+		serviceOptions = append(serviceOptions, service.WithAllIdentities())
+	}
+
+	// Histórico de bloqueios para relatórios (/admin/reports/blocks)
+	blockLog := reports.NewBlockLog(reports.DefaultBlockLogCapacity)
+	serviceOptions = append(serviceOptions, service.WithBlockRecorder(blockLog))
+
+	// Analytics por minuto (/admin/analytics)
+	var analyticsAggregator *analytics.Aggregator
+	if serverConfig.AnalyticsRetentionHours > 0 {
+		analyticsAggregator = newAnalyticsAggregator(serverConfig, appLogger)
+		analyticsAggregator.Start()
+		defer analyticsAggregator.Stop()
+		serviceOptions = append(serviceOptions, service.WithDecisionObserver(analyticsAggregator))
+	}
+
+	// Estatísticas recentes por tipo e chaves mais ativas (/admin/stats)
+	var statsCollector *stats.Collector
+	if serverConfig.StatsRetentionMinutes > 0 {
+		statsCollector = stats.NewCollector(stats.Config{
+			Retention: time.Duration(serverConfig.StatsRetentionMinutes) * time.Minute,
+			MaxKeys:   serverConfig.StatsMaxKeys,
+		})
+		serviceOptions = append(serviceOptions,
+			service.WithDecisionObserver(statsCollector),
+			service.WithBlockRecorder(statsCollector),
+		)
+	}
+
 	// Inicializar service
-	rateLimiterService := service.NewRateLimiterService(rateLimiterStorage, cfg, appLogger)
+	rateLimiterService := newRateLimiterService(
+		registry,
+		cfg,
+		appLogger,
+		serviceOptions...,
+	)
+	if limitLearner != nil {
+		limitLearner.SetApplier(rateLimiterService)
+	}
 
 	// Inicializar handlers
 	handlers := handler.NewHandlers(rateLimiterService, appLogger)
+	handlers.SetHealthReporter(healthMonitor)
+	handlers.SetStorageRegistry(registry)
+	handlers.SetReadinessGate(readinessGate)
+
+	// Drenagem para o desligamento: /admin/drain (ex: preStop) e o SIGTERM
+	inFlight := middleware.NewInFlight()
+	handlers.SetInFlight(inFlight)
+	drainer := drain.NewDrainer(readinessGate, inFlight, appLogger)
+	for name, registered := range registry.Storages() {
+		if flusher, ok := registered.(drain.Flusher); ok {
+			drainer.AddFlusher(name, flusher)
+		}
+	}
+	handlers.SetDrainer(drainer)
+	handlers.SetFleet(membership)
+	handlers.SetMaintenance(maintenanceManager)
+	handlers.SetRuleRollouts(rolloutManager)
+	if shadowEvaluator != nil {
+		handlers.SetShadow(shadowEvaluator)
+	}
+	handlers.SetBlockReports(blockLog)
+	if blockEventStream != nil {
+		handlers.SetBlockEvents(blockEventStream)
+	}
+	handlers.SetObserver(keyObserver)
+	handlers.SetDecisionRecorder(decisionRecorder)
+	handlers.SetConfigStager(configStager)
+	handlers.SetRuleEditor(configStager)
+	if capacityPlanner != nil {
+		handlers.SetCapacity(capacityPlanner)
+	}
+	if limitLearner != nil {
+		handlers.SetLearning(limitLearner)
+	}
+	if statsCollector != nil {
+		handlers.SetStats(statsCollector)
+	}
+	if analyticsAggregator != nil {
+		handlers.SetAnalytics(analyticsAggregator)
+	}
+	// Falhas de decisão do middleware (timeouts e erros) exportadas em /metrics/prometheus
+	decisionCounters := &middleware.Counters{}
+	handlers.SetPrometheusGatherer(newPrometheusRegistry(registry, rolloutManager, decisionCounters))
+
+	// Modo reverse proxy: rotas não registradas vão ao upstream com os headers de correlação
+	if serverConfig.ProxyUpstreamURL != "" {
+		upstream, err := url.Parse(serverConfig.ProxyUpstreamURL)
+		if err != nil {
+			log.Fatalf("Failed to parse proxy upstream: %v", err)
+		}
+		handlers.SetProxy(proxy.New(upstream, appLogger))
+		appLogger.Info("Reverse proxy mode enabled", map[string]interface{}{
+			"upstream": upstream.Host,
+		})
+	}
+
+	// Autenticação da API administrativa: chave estática e/ou requisições assinadas (HMAC),
+	// e chaves nomeadas com papel e tenants (já validadas pelo config)
+	adminKeys, _ := adminauth.ParseKeys(serverConfig.AdminKeys)
+	if adminAuth := adminauth.New(adminauth.Config{
+		APIKey:     serverConfig.AdminAPIKey,
+		HMACSecret: serverConfig.AdminHMACSecret,
+		MaxSkew:    time.Duration(serverConfig.AdminSignatureMaxSkew) * time.Second,
+		Keys:       adminKeys,
+	}); adminAuth != nil {
+		handlers.SetAdminAuthenticator(adminAuth)
+		appLogger.Info("Admin authentication enabled", map[string]interface{}{
+			"api_key":     serverConfig.AdminAPIKey != "",
+			"hmac_signed": serverConfig.AdminHMACSecret != "",
+			"scoped_keys": len(adminKeys),
+		})
+	} else {
+		appLogger.Warn("Admin API is not authenticated", map[string]interface{}{
+			"hint": "set ADMIN_API_KEY, ADMIN_HMAC_SECRET or ADMIN_KEYS",
+		})
+	}
+
+	// Auditoria das operações administrativas (/admin/audit, arquivo e webhook)
+	if serverConfig.AuditLogCapacity > 0 {
+		auditLog, closeAuditLog, err := newAuditLog(serverConfig, appLogger)
+		if err != nil {
+			log.Fatalf("Failed to open audit log: %v", err)
+		}
+		defer closeAuditLog()
+		handlers.SetAuditLog(auditLog)
+		appLogger.Info("Admin audit log enabled", map[string]interface{}{
+			"capacity": serverConfig.AuditLogCapacity,
+			"path":     serverConfig.AuditLogPath,
+			"webhook":  serverConfig.AuditWebhookURL != "",
+		})
+	}
+
+	// Compressão gzip das respostas por grupo de rotas (relatórios e listagens do admin)
+	if len(serverConfig.CompressionGroups) > 0 {
+		handlers.SetCompression(middleware.CompressionConfig{
+			MinSize:  serverConfig.CompressionMinSize,
+			Encoders: []middleware.Encoder{middleware.GzipEncoder(serverConfig.CompressionLevel)},
+		}, serverConfig.CompressionGroups...)
+		appLogger.Info("Response compression enabled", map[string]interface{}{
+			"groups":   serverConfig.CompressionGroups,
+			"min_size": serverConfig.CompressionMinSize,
+		})
+	}
+
+	// Profiling (net/http/pprof): no grupo /admin ou, com PPROF_ADDR, em um listener próprio
+	if serverConfig.PprofEnabled && serverConfig.PprofAddr == "" {
+		handlers.SetProfiling(true)
+		appLogger.Info("Profiling enabled on the admin API", map[string]interface{}{
+			"path": "/admin/debug/pprof/",
+		})
+	}
+
+	// Allowlist: identidades que não passam pelo storage
+	allowlist, err := middleware.NewAllowlist(serverConfig.AllowlistIPs, serverConfig.AllowlistTokens)
+	if err != nil {
+		log.Fatalf("Failed to build allowlist: %v", err)
+	}
+
+	middlewareConfig := middleware.Config{
+		FailureMode: middleware.FailureMode(serverConfig.FailureMode),
+		Timeout:     time.Duration(serverConfig.DecisionTimeout) * time.Millisecond,
+		Counters:    decisionCounters,
+		RejectionCache: middleware.RejectionCache{
+			TTL:     time.Duration(serverConfig.RateLimitedCacheTTL) * time.Second,
+			Headers: serverConfig.RateLimitedCacheHeaders,
+		},
+		Tarpit: middleware.Tarpit{
+			MaxDelay:      time.Duration(serverConfig.TarpitMaxDelay) * time.Millisecond,
+			BandPercent:   serverConfig.TarpitBandPercent,
+			MaxConcurrent: serverConfig.TarpitMaxConcurrent,
+		},
+		Challenge: middleware.Challenge{
+			URL:    serverConfig.ChallengeURL,
+			Secret: serverConfig.ChallengeSecret,
+		},
+	}
+	if middlewareConfig.RejectionCache.Enabled() {
+		appLogger.Info("Rate limited responses cacheable by CDNs", map[string]interface{}{
+			"ttl_seconds": serverConfig.RateLimitedCacheTTL,
+			"headers":     serverConfig.RateLimitedCacheHeaders,
+		})
+	}
+	if middlewareConfig.Tarpit.Enabled() {
+		appLogger.Info("Tarpit enabled for suspicious clients", map[string]interface{}{
+			"max_delay_ms":   serverConfig.TarpitMaxDelay,
+			"band_percent":   serverConfig.TarpitBandPercent,
+			"max_concurrent": serverConfig.TarpitMaxConcurrent,
+		})
+	}
+	if middlewareConfig.Challenge.Enabled() {
+		appLogger.Info("Challenge enabled for rate limited clients", map[string]interface{}{
+			"challenge_url": serverConfig.ChallengeURL,
+		})
+	}
+
+	// Health checks de infraestrutura não consomem cota
+	probeFilter, err := middleware.NewProbeFilter(serverConfig.ProbeUserAgents, serverConfig.ProbeSourceRanges)
+	if err != nil {
+		log.Fatalf("Failed to build probe filter: %v", err)
+	}
+	if !probeFilter.IsEmpty() {
+		middlewareConfig.PreChecks = append(middlewareConfig.PreChecks, probeFilter.PreCheck())
+		appLogger.Info("Infrastructure probe exclusion enabled", map[string]interface{}{
+			"user_agents":   serverConfig.ProbeUserAgents,
+			"source_ranges": len(serverConfig.ProbeSourceRanges),
+		})
+	}
+
+	if !allowlist.IsEmpty() {
+		middlewareConfig.PreChecks = append(middlewareConfig.PreChecks, allowlist.PreCheck())
+		appLogger.Info("Allowlist enabled", map[string]interface{}{
+			"ips":    len(serverConfig.AllowlistIPs),
+			"tokens": len(serverConfig.AllowlistTokens),
+		})
+	}
+	handlers.SetMiddlewareConfig(middlewareConfig)
 
 	// Configurar Gin
 	if serverConfig.GinMode == "release" {
@@ -84,8 +641,8 @@ func main() {
 	// Middlewares globais
 	router.Use(gin.Recovery())
 	
-	// Middleware de logging customizado
-	router.Use(gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
+	// Middleware de logging customizado (probes de infraestrutura ficam fora do access log)
+	router.Use(probeFilter.SkipLogging(gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
 		return fmt.Sprintf("[%s] \"%s %s %s %d %s \"%s\" %s\"\n",
 			param.TimeStamp.Format("2006/01/02 - 15:04:05"),
 			param.Method,
@@ -96,7 +653,10 @@ func main() {
 			param.Request.UserAgent(),
 			param.ErrorMessage,
 		)
-	}))
+	})))
+
+	// Corpo limitado antes do rate limiting e dos handlers (413 acima de MAX_BODY_BYTES)
+	router.Use(middleware.BodyLimit(serverConfig.MaxBodyBytes))
 
 	// Configurar rotas
 	handlers.SetupRoutes(router)
@@ -108,21 +668,55 @@ func main() {
 		ReadTimeout:  30 * time.Second,
 		WriteTimeout: 30 * time.Second,
 		IdleTimeout:  60 * time.Second,
+
+		// Headers maiores são recusados pelo net/http com 431
+		MaxHeaderBytes: serverConfig.MaxHeaderBytes,
+	}
+
+	// Criar listener (TCP ou Unix domain socket)
+	listener, err := newListener(serverConfig, server.Addr)
+	if err != nil {
+		appLogger.Error("Failed to create listener", err, map[string]interface{}{
+			"socket_path": serverConfig.SocketPath,
+		})
+		os.Exit(1)
 	}
 
 	// Iniciar servidor em goroutine
 	go func() {
 		appLogger.Info("Starting HTTP server", map[string]interface{}{
-			"port": serverConfig.ServerPort,
-			"addr": server.Addr,
+			"port":    serverConfig.ServerPort,
+			"addr":    listener.Addr().String(),
+			"network": listener.Addr().Network(),
 		})
 		
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
 			appLogger.Error("Failed to start server", err, nil)
 			os.Exit(1)
 		}
 	}()
 
+	// Listener dedicado ao pprof, sem WriteTimeout para perfis de CPU e trace longos
+	var profilingServer *http.Server
+	if serverConfig.PprofEnabled && serverConfig.PprofAddr != "" {
+		profilingServer = &http.Server{
+			Addr:        serverConfig.PprofAddr,
+			Handler:     handlers.ProfilingHTTPHandler(""),
+			ReadTimeout: 30 * time.Second,
+			IdleTimeout: 60 * time.Second,
+		}
+		go func() {
+			appLogger.Info("Starting profiling server", map[string]interface{}{
+				"addr": serverConfig.PprofAddr,
+				"path": "/debug/pprof/",
+			})
+			// O profiling é acessório: uma falha aqui não derruba a API
+			if err := profilingServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				appLogger.Error("Failed to start profiling server", err, nil)
+			}
+		}()
+	}
+
 	// Aguardar sinais de interrupção
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -131,10 +725,64 @@ func main() {
 		"port": serverConfig.ServerPort,
 		"endpoints": []string{
 			"GET  /health",
+			"GET  /ready",
+			"GET  /livez",
+			"GET  /readyz",
 			"GET  /metrics", 
+			"GET  /metrics/prometheus",
+			"GET  /me/limits    (rate limited)",
 			"GET  /             (rate limited)",
+			"GET  /check        (rate limited)",
+			"HEAD /check        (rate limited)",
+			"*    (no route)    (rate limited, proxied when PROXY_UPSTREAM_URL is set)",
 			"GET  /admin/status",
+			"POST /admin/status",
 			"POST /admin/reset",
+			"POST /admin/reset/bulk",
+			"POST /admin/block",
+			"POST /admin/override",
+			"GET  /admin/instances",
+			"GET  /admin/maintenance",
+			"POST /admin/maintenance",
+			"DEL  /admin/maintenance/:id",
+			"GET  /admin/rules/rollouts",
+			"POST /admin/rules/canary",
+			"POST /admin/rules/promote",
+			"POST /admin/rules/rollback",
+			"POST /admin/rules",
+			"GET  /admin/rules/:id",
+			"PUT  /admin/rules/:id",
+			"PATCH /admin/rules/:id",
+			"DEL  /admin/rules/:id",
+			"GET  /admin/rules/:id/history",
+			"POST /admin/rules/:id/restore",
+			"GET  /admin/shadow",
+			"POST /admin/shadow/reset",
+			"GET  /admin/capacity",
+			"POST /admin/capacity/reset",
+			"GET  /admin/learning",
+			"POST /admin/learning/apply",
+			"POST /admin/learning/reset",
+			"GET  /admin/reports/blocks",
+			"GET  /admin/events/blocks",
+			"GET  /admin/analytics",
+			"GET  /admin/stats",
+			"GET  /admin/keys",
+			"GET  /admin/export",
+			"POST /admin/import",
+			"GET  /admin/debug/key",
+			"GET  /admin/observe",
+			"GET  /admin/debug/decisions",
+			"GET  /admin/config/stage",
+			"POST /admin/config/stage",
+			"DEL  /admin/config/stage",
+			"POST /admin/config/promote",
+			"POST /admin/config/rollback",
+			"GET  /admin/routes",
+			"POST /admin/drain",
+			"GET  /admin/audit",
+			"GET  /admin/ui     (dashboard, no auth; its API calls send the admin key)",
+			"GET  /admin/debug/pprof/ (PPROF_ENABLED, or on PPROF_ADDR)",
 		},
 		"rate_limits": map[string]interface{}{
 			"default_ip":    cfg.DefaultIPLimit,
@@ -152,10 +800,441 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
-	if err := server.Shutdown(ctx); err != nil {
-		appLogger.Error("Server forced to shutdown", err, nil)
+	// Sem /admin/drain prévio, drena agora; depois dele, só confirma o estado
+	drainer.Drain(ctx)
+
+	shutdownErr := server.Shutdown(ctx)
+	// O socket não sobrevive ao processo: clientes falham na hora em vez de esperar
+	if serverConfig.SocketPath != "" {
+		if err := removeSocket(serverConfig.SocketPath); err != nil {
+			appLogger.Error("Failed to remove unix socket", err, map[string]interface{}{
+				"socket_path": serverConfig.SocketPath,
+			})
+		}
+	}
+	if shutdownErr != nil {
+		appLogger.Error("Server forced to shutdown", shutdownErr, nil)
 		os.Exit(1)
 	}
+	// Perfis em andamento são descartados
+	if profilingServer != nil {
+		_ = profilingServer.Close()
+	}
+
+	// Envia os spans e as medições ainda pendentes antes de sair
+	if err := shutdownTracing(ctx); err != nil {
+		appLogger.Error("Failed to flush traces", err, nil)
+	}
+	if err := shutdownMetrics(ctx); err != nil {
+		appLogger.Error("Failed to flush metrics", err, nil)
+	}
 
 	appLogger.Info("Server stopped gracefully", nil)
-} 
\ No newline at end of file
+} 
+
+// registerReferencedStorages cria os backends referenciados pelas regras
+// que ainda não estão no registro (ex: TOKEN_STORAGE=redis com STORAGE_TYPE=memory)
+func registerReferencedStorages(
+	registry *storage.Registry,
+	factory *storage.StorageFactory,
+	cfg *domain.RateLimitConfig,
+	serverConfig *config.Config,
+	cipher *storage.Cipher,
+	appLogger domain.Logger,
+) {
+	names := []string{cfg.IPStorage, cfg.TokenStorage}
+	for _, tokenConfig := range cfg.TokenConfigs {
+		names = append(names, tokenConfig.Storage)
+	}
+
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		if _, exists := registry.Get(name); exists {
+			continue
+		}
+
+		storageCfg := storage.BuildStorageConfigFromEnv(
+			name,
+			serverConfig.RedisHost,
+			serverConfig.RedisPort,
+			serverConfig.RedisPassword,
+			serverConfig.RedisDB,
+		)
+		if storageCfg.RedisConfig != nil {
+			storageCfg.RedisConfig = newRedisConfig(serverConfig)
+		}
+		storageCfg.MemoryConfig = &storage.MemoryConfig{ExpectedKeys: serverConfig.MemoryExpectedKeys, MaxEntries: serverConfig.MemoryMaxKeys}
+		storageCfg.HybridConfig = newHybridConfig(serverConfig)
+		storageCfg.TieredConfig = &storage.TieredConfig{CacheTTL: time.Duration(serverConfig.TieredCacheTTL) * time.Millisecond}
+		storageCfg.EtcdConfig = newEtcdConfig(serverConfig)
+		storageCfg.Algorithm = storage.Algorithm(serverConfig.RateLimitAlgorithm)
+		storageCfg.HistorySize = serverConfig.WindowHistorySize
+		storageCfg.BlockFilter = newBlockFilterConfig(serverConfig)
+		storageCfg.Cipher = cipher
+
+		namedStorage, err := factory.CreateStorage(storageCfg, appLogger)
+		if err != nil {
+			appLogger.Error("Failed to initialize named storage, rules will use the default storage", err, map[string]interface{}{
+				"storage": name,
+			})
+			continue
+		}
+
+		if err := registry.Register(name, namedStorage); err != nil {
+			namedStorage.Close()
+			continue
+		}
+
+		appLogger.Info("Named storage initialized", map[string]interface{}{
+			"storage": name,
+		})
+	}
+}
+
+// newRedisConfig converte a configuração do Redis, com o modo de conexão
+func newRedisConfig(serverConfig *config.Config) *storage.RedisConfig {
+	return &storage.RedisConfig{
+		Host:     serverConfig.RedisHost,
+		Port:     serverConfig.RedisPort,
+		Username: serverConfig.RedisUsername,
+		Password: serverConfig.RedisPassword,
+		Database: serverConfig.RedisDB,
+		TLS: &storage.RedisTLSConfig{
+			Enabled:            serverConfig.RedisTLSEnabled,
+			CAFile:             serverConfig.RedisTLSCAFile,
+			CertFile:           serverConfig.RedisTLSCertFile,
+			KeyFile:            serverConfig.RedisTLSKeyFile,
+			InsecureSkipVerify: serverConfig.RedisTLSInsecureSkipVerify,
+		},
+		Mode:             serverConfig.RedisMode,
+		MasterName:       serverConfig.RedisSentinelMaster,
+		SentinelAddrs:    serverConfig.RedisSentinelAddrs,
+		SentinelUsername: serverConfig.RedisSentinelUsername,
+		SentinelPassword: serverConfig.RedisSentinelPassword,
+	}
+}
+
+// newRedisClient cria um cliente auxiliar do Redis no modo de conexão do storage
+// Certificados TLS ilegíveis interrompem a inicialização
+func newRedisClient(serverConfig *config.Config) *redis.Client {
+	client, err := storage.NewRedisClient(newRedisConfig(serverConfig))
+	if err != nil {
+		log.Fatalf("Failed to create Redis client: %v", err)
+	}
+	return client
+}
+
+// newHybridConfig converte a configuração do storage híbrido
+func newHybridConfig(serverConfig *config.Config) *storage.HybridConfig {
+	return &storage.HybridConfig{
+		SyncInterval: time.Duration(serverConfig.HybridSyncInterval) * time.Millisecond,
+		BatchSize:    serverConfig.HybridSyncBatchSize,
+	}
+}
+
+// newRetryPolicy cria a política de novas tentativas do Redis (REDIS_MAX_RETRIES=0 desabilita)
+func newRetryPolicy(serverConfig *config.Config) *storage.RetryPolicy {
+	maxRetries := serverConfig.RedisMaxRetries
+	if maxRetries == 0 {
+		maxRetries = -1
+	}
+	return storage.NewRetryPolicy(storage.RetryConfig{
+		MaxRetries: maxRetries,
+		MinBackoff: time.Duration(serverConfig.RedisMinRetryBackoff) * time.Millisecond,
+		MaxBackoff: time.Duration(serverConfig.RedisMaxRetryBackoff) * time.Millisecond,
+	})
+}
+
+// newBlockFilterConfig converte a configuração do filtro de bloqueios (nil = desabilitado)
+func newBlockFilterConfig(serverConfig *config.Config) *storage.BlockFilterConfig {
+	if serverConfig.BlockFilterExpectedKeys == 0 {
+		return nil
+	}
+	return &storage.BlockFilterConfig{
+		ExpectedKeys:    serverConfig.BlockFilterExpectedKeys,
+		Partitions:      serverConfig.BlockFilterPartitions,
+		RefreshInterval: time.Duration(serverConfig.BlockFilterRefresh) * time.Millisecond,
+	}
+}
+
+// newEtcdConfig converte a configuração do storage etcd
+func newEtcdConfig(serverConfig *config.Config) *storage.EtcdConfig {
+	return &storage.EtcdConfig{
+		Endpoints: serverConfig.EtcdEndpoints,
+		Username:  serverConfig.EtcdUsername,
+		Password:  serverConfig.EtcdPassword,
+		Prefix:    serverConfig.EtcdPrefix,
+	}
+}
+
+// usesRedis informa se o storage principal mantém estado compartilhado no Redis
+func usesRedis(storageType string) bool {
+	switch storage.StorageType(storageType) {
+	case storage.RedisStorageType, storage.HybridStorageType, storage.TieredStorageType:
+		return true
+	default:
+		return false
+	}
+}
+
+// newMembership cria o registro da instância na frota
+// Usa o Redis como registro compartilhado quando disponível; caso contrário só a própria instância é visível
+func newMembership(serverConfig *config.Config, cfg *domain.RateLimitConfig, storageType string, appLogger domain.Logger) *cluster.Membership {
+	var store cluster.Store = cluster.NewMemoryStore()
+	if usesRedis(storageType) || serverConfig.PartitionLimits {
+		client := newRedisClient(serverConfig)
+		store = cluster.NewRedisStore(client)
+	}
+
+	self := cluster.Instance{
+		ID:          serverConfig.InstanceID,
+		Version:     appVersion,
+		StorageType: storageType,
+		ConfigHash:  config.Fingerprint(cfg),
+	}
+
+	return cluster.NewMembership(
+		store,
+		self,
+		time.Duration(serverConfig.InstanceHeartbeatInterval)*time.Second,
+		time.Duration(serverConfig.InstanceTTL)*time.Second,
+		appLogger,
+	)
+}
+
+// invalidationBus é o barramento de invalidações com encerramento
+type invalidationBus interface {
+	domain.InvalidationBus
+	Stop()
+}
+
+// newInvalidationBus usa Redis pub/sub quando há Redis; sem ele, só existe estado local nesta instância
+func newInvalidationBus(serverConfig *config.Config, storageType, instanceID string, appLogger domain.Logger) invalidationBus {
+	local := cluster.NewLocalInvalidationBus(instanceID)
+	if !usesRedis(storageType) {
+		return local
+	}
+
+	client := newRedisClient(serverConfig)
+	bus := cluster.NewRedisInvalidationBus(client, instanceID, appLogger)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := bus.Start(ctx); err != nil {
+		appLogger.Error("Failed to subscribe to cluster invalidations, resets will not propagate", err, nil)
+		return local
+	}
+	return bus
+}
+
+// newBlockEventStream cria o sink de eventos de bloqueio no Redis configurado
+func newBlockEventStream(serverConfig *config.Config, appLogger domain.Logger) *events.RedisStream {
+	client := newRedisClient(serverConfig)
+	appLogger.Info("Block event stream enabled", map[string]interface{}{
+		"stream": serverConfig.BlockEventsStream,
+		"maxlen": serverConfig.BlockEventsStreamMaxLen,
+	})
+	return events.NewRedisStream(client, serverConfig.BlockEventsStream, int64(serverConfig.BlockEventsStreamMaxLen), appLogger)
+}
+
+// newSecurityLog cria o log de segurança em JSON no SECURITY_LOG_PATH (vazio = stdout)
+// A função retornada fecha o arquivo
+func newSecurityLog(serverConfig *config.Config) (*security.Log, func(), error) {
+	minSeverity, err := security.ParseSeverity(serverConfig.SecurityLogMinSeverity)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	output, closeOutput := os.Stdout, func() {}
+	if serverConfig.SecurityLogPath != "" {
+		file, err := os.OpenFile(serverConfig.SecurityLogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open %s: %w", serverConfig.SecurityLogPath, err)
+		}
+		output, closeOutput = file, func() { file.Close() }
+	}
+
+	securityLog := security.NewLog(logger.NewLoggerWithOutput("info", "json", output), security.Config{
+		MinSeverity:     minSeverity,
+		RepeatThreshold: serverConfig.SecurityRepeatBlocks,
+		RepeatWindow:    time.Duration(serverConfig.SecurityRepeatWindow) * time.Second,
+	})
+	return securityLog, closeOutput, nil
+}
+
+// newAuditLog cria o log de auditoria com os sinks configurados: o arquivo JSON
+// (stdout por padrão) e o webhook. O fechamento entrega os registros pendentes
+func newAuditLog(serverConfig *config.Config, appLogger domain.Logger) (*audit.Log, func(), error) {
+	auditLog := audit.NewLog(serverConfig.AuditLogCapacity)
+	var closers []func()
+
+	if serverConfig.AuditLogPath != "none" {
+		output := os.Stdout
+		if serverConfig.AuditLogPath != "" {
+			file, err := os.OpenFile(serverConfig.AuditLogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0640)
+			if err != nil {
+				return nil, nil, fmt.Errorf("failed to open %s: %w", serverConfig.AuditLogPath, err)
+			}
+			output = file
+			closers = append(closers, func() { file.Close() })
+		}
+		auditLog.Subscribe(audit.LogHandler(logger.NewLoggerWithOutput("info", "json", output)))
+	}
+
+	if serverConfig.AuditWebhookURL != "" {
+		webhook := events.NewWebhook(serverConfig.AuditWebhookURL, appLogger)
+		auditLog.Subscribe(webhook.Handle)
+		closers = append(closers, webhook.Close)
+	}
+
+	return auditLog, func() {
+		for _, closer := range closers {
+			closer()
+		}
+	}, nil
+}
+
+// newAnalyticsAggregator cria o agregador por minuto no store configurado
+// Com ANALYTICS_STORAGE=redis os buckets somam o tráfego de todas as réplicas
+func newAnalyticsAggregator(serverConfig *config.Config, appLogger domain.Logger) *analytics.Aggregator {
+	retention := time.Duration(serverConfig.AnalyticsRetentionHours) * time.Hour
+
+	var store analytics.Store = analytics.NewMemoryStore(retention)
+	if serverConfig.AnalyticsStorage == string(storage.RedisStorageType) {
+		client := newRedisClient(serverConfig)
+		store = analytics.NewRedisStore(client, retention)
+	}
+
+	return analytics.NewAggregator(store, appLogger)
+}
+
+// newMaintenanceManager cria o gerenciador e registra as janelas do MAINTENANCE_FILE
+// Janelas inválidas ou já encerradas são ignoradas com aviso
+func newMaintenanceManager(serverConfig *config.Config, publisher events.Publisher, appLogger domain.Logger) *maintenance.Manager {
+	manager := maintenance.NewManager(publisher, appLogger)
+
+	windows, err := config.LoadMaintenanceWindows(serverConfig.MaintenanceFile)
+	if err != nil {
+		appLogger.Error("Failed to load maintenance windows", err, map[string]interface{}{
+			"file": serverConfig.MaintenanceFile,
+		})
+		return manager
+	}
+
+	for _, window := range windows {
+		if _, err := manager.Add(context.Background(), window); err != nil {
+			appLogger.Warn("Skipping maintenance window", map[string]interface{}{
+				"window_id": window.ID,
+				"error":     err.Error(),
+			})
+		}
+	}
+
+	return manager
+}
+
+// newShadowEvaluator cria o avaliador em shadow quando SHADOW_CONFIG_FILE está definido
+// Os contadores em shadow ficam isolados no mesmo storage sob o prefixo "shadow:"
+func newShadowEvaluator(serverConfig *config.Config, cfg *domain.RateLimitConfig, rateLimiterStorage domain.RateLimiterStorage, appLogger domain.Logger) *shadow.Evaluator {
+	if serverConfig.ShadowConfigFile == "" {
+		return nil
+	}
+
+	staged, err := config.LoadStagedConfig(serverConfig.ShadowConfigFile, cfg)
+	if err != nil {
+		appLogger.Error("Failed to load staged config, shadow evaluation disabled", err, map[string]interface{}{
+			"file": serverConfig.ShadowConfigFile,
+		})
+		return nil
+	}
+
+	shadowService := service.NewRateLimiterService(
+		storage.NewPrefixedStorage(rateLimiterStorage, "shadow:"),
+		staged,
+		logger.NewNopLogger(),
+	)
+
+	appLogger.Info("Shadow evaluation enabled", map[string]interface{}{
+		"file":                serverConfig.ShadowConfigFile,
+		"staged_ip_limit":     staged.DefaultIPLimit,
+		"staged_token_limit":  staged.DefaultTokenLimit,
+		"staged_token_config": len(staged.TokenConfigs),
+	})
+	return shadow.NewEvaluator(shadowService, shadow.DefaultMaxInFlight)
+}
+
+// newSQLTokenSource abre a conexão com o banco de tokens
+func newSQLTokenSource(serverConfig *config.Config, appLogger domain.Logger) (*config.SQLTokenSource, error) {
+	db, err := sql.Open(serverConfig.TokenDBDriver, serverConfig.TokenDBDSN)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open token database: %w", err)
+	}
+
+	return config.NewSQLTokenSource(db, serverConfig.TokenDBDriver, serverConfig.TokenDBTable, appLogger)
+}
+
+// newPrometheusRegistry registra os collectors exportados em /metrics/prometheus
+func newPrometheusRegistry(registry *storage.Registry, rollouts metrics.RolloutSource, decisions metrics.DecisionFailureSource) *prometheus.Registry {
+	promRegistry := prometheus.NewRegistry()
+	promRegistry.MustRegister(collectors.NewGoCollector())
+	promRegistry.MustRegister(metrics.NewRolloutCollector(rollouts))
+	promRegistry.MustRegister(metrics.NewDecisionCollector(decisions))
+
+	memoryStorages := make(map[string]*storage.MemoryStorage)
+	for name, namedStorage := range registry.Storages() {
+		if memoryStorage, ok := namedStorage.(*storage.MemoryStorage); ok {
+			memoryStorages[name] = memoryStorage
+		}
+	}
+	if len(memoryStorages) > 0 {
+		promRegistry.MustRegister(metrics.NewMemoryCollector(memoryStorages))
+	}
+
+	return promRegistry
+}
+
+// newListener cria o listener do servidor
+// Quando SERVER_SOCKET_PATH está definido, usa Unix domain socket em vez de TCP
+func newListener(cfg *config.Config, addr string) (net.Listener, error) {
+	if cfg.SocketPath == "" {
+		return net.Listen("tcp", addr)
+	}
+
+	// Remove socket remanescente de uma execução anterior
+	if err := removeSocket(cfg.SocketPath); err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("unix", cfg.SocketPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on unix socket %s: %w", cfg.SocketPath, err)
+	}
+
+	if err := os.Chmod(cfg.SocketPath, cfg.SocketMode); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to set socket permissions: %w", err)
+	}
+
+	return listener, nil
+}
+
+// removeSocket remove o arquivo de socket em path, se existir. Qualquer outro tipo
+// de arquivo indica configuração errada e não é apagado
+func removeSocket(path string) error {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat socket path %s: %w", path, err)
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("socket path %s exists and is not a socket (mode %s)", path, info.Mode())
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove socket %s: %w", path, err)
+	}
+	return nil
+}
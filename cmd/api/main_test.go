@@ -0,0 +1,59 @@
+package main
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/config"
+)
+
+func TestNewListener_UnixSocket(t *testing.T) {
+	// Arrange: socket remanescente de uma execução que não removeu o arquivo
+	path := filepath.Join(t.TempDir(), "api.sock")
+	stale, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+	cfg := &config.Config{SocketPath: path, SocketMode: 0o660}
+
+	// Act
+	listener, err := newListener(cfg, "")
+	require.NoError(t, err)
+	conn, dialErr := net.Dial("unix", path)
+
+	// Assert
+	require.NoError(t, dialErr)
+	conn.Close()
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o660), info.Mode().Perm())
+
+	// O desligamento remove o socket
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, listener.Close())
+	require.NoError(t, removeSocket(path))
+	_, err = os.Lstat(path)
+	assert.True(t, os.IsNotExist(err))
+	assert.NoError(t, removeSocket(path))
+}
+
+func TestNewListener_RefusesToRemoveRegularFile(t *testing.T) {
+	// Arrange: SERVER_SOCKET_PATH apontando para um arquivo comum por engano
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte("{}"), 0o600))
+
+	// Act
+	_, err := newListener(&config.Config{SocketPath: path, SocketMode: 0o660}, "")
+
+	// Assert: o arquivo continua lá
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not a socket")
+	content, readErr := os.ReadFile(path)
+	require.NoError(t, readErr)
+	assert.Equal(t, "{}", string(content))
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/service"
+	"rate-limiter/internal/storage"
+)
+
+// newRateLimiterService cria o service sobre o storage padrão do registro, com os
+// storages nomeados disponíveis para as regras (IP_STORAGE, TOKEN_STORAGE e tokens)
+func newRateLimiterService(
+	registry *storage.Registry,
+	cfg *domain.RateLimitConfig,
+	appLogger domain.Logger,
+	options ...service.Option,
+) domain.RateLimiterService {
+	options = append([]service.Option{service.WithStorages(registry.Storages())}, options...)
+	return service.NewRateLimiterService(registry.Default(), cfg, appLogger, options...)
+}
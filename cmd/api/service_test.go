@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/config"
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/logger"
+	"rate-limiter/internal/storage"
+)
+
+func TestNewRateLimiterService_UsesNamedStorages(t *testing.T) {
+	// Arrange: storage padrão "redis" (um memory no teste) e IP_STORAGE=memory
+	ctx := context.Background()
+	testLogger := logger.NewLogger("error", "json")
+	defaultStorage := storage.NewMemoryStorage(nil)
+	registry := storage.NewRegistry("redis", defaultStorage)
+	defer registry.Close()
+	cfg := &domain.RateLimitConfig{
+		DefaultIPLimit:    10,
+		DefaultTokenLimit: 100,
+		Window:            60,
+		BlockDuration:     60,
+		TokenConfigs:      map[string]domain.TokenConfig{},
+		IPStorage:         "memory",
+	}
+	registerReferencedStorages(registry, storage.NewStorageFactory(), cfg, &config.Config{}, nil, testLogger)
+	rateLimiterService := newRateLimiterService(registry, cfg, testLogger)
+
+	// Act
+	_, ipErr := rateLimiterService.CheckLimit(ctx, "192.168.1.1", "")
+	_, tokenErr := rateLimiterService.CheckLimit(ctx, "192.168.1.1", "abc123")
+
+	// Assert: o contador de IP fica no backend nomeado, o de token no padrão
+	require.NoError(t, ipErr)
+	require.NoError(t, tokenErr)
+	ipStorage, exists := registry.Get("memory")
+	require.True(t, exists)
+	require.NotSame(t, defaultStorage, ipStorage)
+
+	ipStatus, err := ipStorage.Get(ctx, "rate_limit:ip:192.168.1.1")
+	require.NoError(t, err)
+	require.NotNil(t, ipStatus)
+	assert.Equal(t, 1, ipStatus.Count)
+	defaultIPStatus, err := defaultStorage.Get(ctx, "rate_limit:ip:192.168.1.1")
+	require.NoError(t, err)
+	assert.Nil(t, defaultIPStatus)
+
+	tokenStatus, err := defaultStorage.Get(ctx, "rate_limit:token:abc123")
+	require.NoError(t, err)
+	require.NotNil(t, tokenStatus)
+	assert.Equal(t, 1, tokenStatus.Count)
+}
@@ -0,0 +1,146 @@
+// Command benchalgo envia a mesma carga sintética a cada combinação de algoritmo
+// e storage e compara precisão (admissões acima ou abaixo de um limitador
+// ideal), latência e memória, para orientar a escolha do algoritmo por ambiente.
+//
+//	go run ./cmd/benchalgo -pattern boundary -load 1.5
+//	go run ./cmd/benchalgo -storages memory,redis -window 1s -duration 10s
+//
+// O storage memory roda com relógio simulado, sem esperar a carga. Storages
+// remotos (redis, hybrid e tiered, conectados pelas variáveis REDIS_*) rodam em
+// tempo real: cada combinação leva -duration.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"rate-limiter/internal/benchalgo"
+	"rate-limiter/internal/config"
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/logger"
+	"rate-limiter/internal/storage"
+)
+
+func main() {
+	pattern := flag.String("pattern", "steady", "workload pattern (steady, bursty or boundary)")
+	keys := flag.Int("keys", 100, "independent keys (clients)")
+	limit := flag.Int("limit", 10, "requests allowed per window")
+	window := flag.Duration("window", 10*time.Second, "rate limit window")
+	duration := flag.Duration("duration", 2*time.Minute, "workload duration")
+	load := flag.Float64("load", 1.2, "offered rate relative to the limit (1 = exactly the limit)")
+	seed := flag.Int64("seed", 1, "workload random seed")
+	algorithms := flag.String("algorithms", "", "comma-separated algorithms (default: all)")
+	storages := flag.String("storages", "memory", "comma-separated storages (memory, redis, hybrid or tiered)")
+	output := flag.String("output", "", "file to write the JSON results")
+	flag.Parse()
+
+	parsedPattern, err := benchalgo.ParsePattern(*pattern)
+	if err != nil {
+		log.Fatalf("Invalid pattern: %v", err)
+	}
+	parsedAlgorithms, err := benchalgo.ParseAlgorithms(*algorithms)
+	if err != nil {
+		log.Fatalf("Invalid algorithms: %v", err)
+	}
+	backends, err := newBackends(*storages)
+	if err != nil {
+		log.Fatalf("Invalid storages: %v", err)
+	}
+
+	workload := benchalgo.Workload{
+		Pattern:  parsedPattern,
+		Keys:     *keys,
+		Limit:    *limit,
+		Window:   *window,
+		Duration: *duration,
+		Load:     *load,
+		Seed:     *seed,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	results, err := benchalgo.Run(ctx, workload, backends, parsedAlgorithms)
+	if err != nil {
+		log.Fatalf("Benchmark failed: %v", err)
+	}
+
+	printResults(workload, results)
+
+	if *output != "" {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			log.Fatalf("Failed to encode results: %v", err)
+		}
+		if err := os.WriteFile(*output, data, 0o644); err != nil {
+			log.Fatalf("Failed to write results: %v", err)
+		}
+		fmt.Printf("\nResults written to %s\n", *output)
+	}
+}
+
+// newBackends cria os backends pedidos; os remotos usam o Redis das variáveis de ambiente
+func newBackends(value string) ([]benchalgo.Backend, error) {
+	var redisConfig *storage.RedisConfig
+	backends := make([]benchalgo.Backend, 0)
+
+	for _, name := range strings.Split(value, ",") {
+		storageType := storage.StorageType(strings.ToLower(strings.TrimSpace(name)))
+		switch storageType {
+		case storage.MemoryStorageType:
+		case storage.RedisStorageType, storage.HybridStorageType, storage.TieredStorageType:
+			if redisConfig == nil {
+				configLoader := config.NewConfigLoader()
+				if _, err := configLoader.LoadConfig(); err != nil {
+					return nil, fmt.Errorf("failed to load config: %w", err)
+				}
+				serverConfig := configLoader.GetConfig()
+				redisConfig = &storage.RedisConfig{
+					Host:     serverConfig.RedisHost,
+					Port:     serverConfig.RedisPort,
+					Username: serverConfig.RedisUsername,
+					Password: serverConfig.RedisPassword,
+					Database: serverConfig.RedisDB,
+				}
+			}
+		default:
+			return nil, fmt.Errorf("unsupported storage: %s", name)
+		}
+
+		storageConfig := storage.StorageConfig{Type: storageType, RedisConfig: redisConfig}
+		backends = append(backends, benchalgo.Backend{
+			Type: storageType,
+			New: func(algorithm storage.Algorithm) (domain.RateLimiterStorage, error) {
+				config := storageConfig
+				config.Algorithm = algorithm
+				// Logs do storage só em caso de erro, para não misturar com o relatório
+				return storage.NewStorageFactory().CreateStorage(&config, logger.NewLoggerWithOutput("error", "text", os.Stderr))
+			},
+		})
+	}
+	return backends, nil
+}
+
+// printResults imprime a carga e uma linha por combinação
+func printResults(workload benchalgo.Workload, results []benchalgo.Result) {
+	fmt.Printf("Workload: %s, %d keys, limit %d per %s, load %.2fx, %s\n\n",
+		workload.Pattern, workload.Keys, workload.Limit, workload.Window, workload.Load, workload.Duration)
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(writer, "STORAGE\tALGORITHM\tREQUESTS\tADMITTED\tIDEAL\tOVER\tUNDER\tACCURACY\tPEAK\tP50\tP99\tALLOCS/OP\tBYTES/KEY\tERRORS\t")
+	for _, result := range results {
+		fmt.Fprintf(writer, "%s\t%s\t%d\t%d\t%d\t%d\t%d\t%.2f%%\t%.2fx\t%s\t%s\t%.1f\t%d\t%d\t\n",
+			result.Storage, result.Algorithm, result.Requests, result.Admitted, result.IdealAdmitted,
+			result.OverAdmitted, result.UnderAdmitted, result.Accuracy()*100, result.PeakRatio,
+			result.LatencyP50, result.LatencyP99, result.AllocsPerOp, result.BytesPerKey, result.Errors)
+	}
+	writer.Flush()
+}
@@ -0,0 +1,105 @@
+// Command replay reproduz um log de tráfego gravado contra a configuração do
+// ambiente (variáveis, .env e TOKEN_CONFIG_FILE) com relógio simulado, para
+// validar uma configuração proposta com tráfego histórico real.
+//
+//	go run ./cmd/replay -log traffic.csv -output report.json
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+	"time"
+
+	"rate-limiter/internal/config"
+	"rate-limiter/internal/logger"
+	"rate-limiter/internal/replay"
+	"rate-limiter/internal/storage"
+)
+
+func main() {
+	trafficLog := flag.String("log", "", "traffic log (.csv, .json, .jsonl or .ndjson) with timestamp, ip, token and path")
+	output := flag.String("output", "", "file to write the full JSON report, including every decision")
+	algorithm := flag.String("algorithm", "", "RATE_LIMIT_ALGORITHM override (fixed_window, sliding_log or sliding_window)")
+	top := flag.Int("top", 10, "keys with the most rejections to print")
+	flag.Parse()
+
+	if *trafficLog == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	configLoader := config.NewConfigLoader()
+	cfg, err := configLoader.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	if *algorithm == "" {
+		*algorithm = configLoader.GetConfig().RateLimitAlgorithm
+	}
+	parsedAlgorithm, err := storage.ParseAlgorithm(*algorithm)
+	if err != nil {
+		log.Fatalf("Invalid algorithm: %v", err)
+	}
+
+	entries, err := replay.LoadFile(*trafficLog)
+	if err != nil {
+		log.Fatalf("Failed to load traffic log: %v", err)
+	}
+
+	// Logs do serviço só em caso de erro, para não misturar com o relatório
+	simulator, err := replay.NewSimulator(cfg, parsedAlgorithm, logger.NewLoggerWithOutput("error", "text", os.Stderr))
+	if err != nil {
+		log.Fatalf("Failed to create simulator: %v", err)
+	}
+	defer simulator.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := simulator.Run(ctx, entries)
+	if err != nil {
+		log.Fatalf("Replay interrupted: %v", err)
+	}
+
+	printSummary(report, *top)
+
+	if *output != "" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			log.Fatalf("Failed to encode report: %v", err)
+		}
+		if err := os.WriteFile(*output, data, 0o644); err != nil {
+			log.Fatalf("Failed to write report: %v", err)
+		}
+		fmt.Printf("\nFull report written to %s\n", *output)
+	}
+}
+
+// printSummary imprime os totais e as chaves mais rejeitadas
+func printSummary(report *replay.Report, top int) {
+	fmt.Printf("Replayed %d requests from %s to %s\n", report.Requests,
+		report.Start.Format(time.RFC3339), report.End.Format(time.RFC3339))
+
+	rejectedPercent := 0.0
+	if report.Requests > 0 {
+		rejectedPercent = float64(report.Rejected) * 100 / float64(report.Requests)
+	}
+	fmt.Printf("Allowed: %d  Rejected: %d (%.2f%%)  Errors: %d\n\n", report.Allowed, report.Rejected, rejectedPercent, report.Errors)
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "TYPE\tKEY\tREQUESTS\tREJECTED\tFIRST REJECTED")
+	for i, key := range report.Keys {
+		if i >= top || key.Rejected == 0 {
+			break
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%d\t%d\t%s\n", key.Type, key.Key, key.Requests, key.Rejected,
+			key.FirstRejectedAt.Format(time.RFC3339))
+	}
+	writer.Flush()
+}
@@ -0,0 +1,226 @@
+// Package adminauth autentica as requisições da API administrativa por chave
+// estática (Authorization: Bearer) ou por assinatura HMAC com proteção contra
+// replay, para automações que não podem guardar uma chave de longa duração
+package adminauth
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Headers das requisições assinadas
+const (
+	TimestampHeader = "X-Admin-Timestamp" // Unix em segundos
+	NonceHeader     = "X-Admin-Nonce"     // Valor único por requisição
+	SignatureHeader = "X-Admin-Signature" // Hex do HMAC-SHA256 da string canônica
+)
+
+// DefaultMaxSkew é a diferença máxima aceita entre o timestamp assinado e o relógio do servidor
+const DefaultMaxSkew = 5 * time.Minute
+
+// maxNonceLength limita os nonces guardados para a proteção contra replay
+const maxNonceLength = 128
+
+// maxSignedBodyBytes limita o corpo lido para calcular o hash da assinatura
+const maxSignedBodyBytes = 1 << 20
+
+var (
+	ErrMissingCredentials = errors.New("missing admin credentials")
+	ErrInvalidKey         = errors.New("invalid admin key")
+	ErrInvalidSignature   = errors.New("invalid request signature")
+	ErrStaleTimestamp     = errors.New("request timestamp outside the allowed window")
+	ErrReplayed           = errors.New("request nonce already used")
+)
+
+// Config contém as credenciais aceitas; vazias desabilitam o respectivo método.
+// APIKey e HMACSecret identificam um administrador sem restrição de tenant
+type Config struct {
+	APIKey     string        // Chave estática (Authorization: Bearer <chave>)
+	HMACSecret string        // Segredo compartilhado das requisições assinadas
+	MaxSkew    time.Duration // 0 = DefaultMaxSkew
+	Keys       []KeyConfig   // Chaves estáticas nomeadas, com papel e tenants
+}
+
+// Authenticator valida as credenciais das requisições administrativas
+type Authenticator struct {
+	config Config
+	now    func() time.Time
+
+	mutex     sync.Mutex
+	nonces    map[string]time.Time // nonce -> expiração
+	lastSweep time.Time
+}
+
+// New cria o autenticador; retorna nil quando nenhuma credencial está configurada
+// (API administrativa aberta, comportamento anterior)
+func New(config Config) *Authenticator {
+	if config.APIKey == "" && config.HMACSecret == "" && len(config.Keys) == 0 {
+		return nil
+	}
+	if config.MaxSkew <= 0 {
+		config.MaxSkew = DefaultMaxSkew
+	}
+
+	return &Authenticator{
+		config: config,
+		now:    time.Now,
+		nonces: make(map[string]time.Time),
+	}
+}
+
+// Authenticate aceita a chave estática ou a assinatura HMAC. O corpo lido para
+// a assinatura é devolvido à requisição para os handlers
+func (a *Authenticator) Authenticate(r *http.Request) error {
+	_, err := a.Identify(r)
+	return err
+}
+
+// Identify autentica a requisição e retorna a identidade da credencial usada
+func (a *Authenticator) Identify(r *http.Request) (*Principal, error) {
+	if key, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok && (a.config.APIKey != "" || len(a.config.Keys) > 0) {
+		return a.identifyKey(key)
+	}
+
+	if r.Header.Get(SignatureHeader) != "" && a.config.HMACSecret != "" {
+		if err := a.verifySignature(r); err != nil {
+			return nil, err
+		}
+		return &Principal{Name: "hmac", Role: RoleAdmin}, nil
+	}
+
+	return nil, ErrMissingCredentials
+}
+
+// identifyKey compara a chave com todas as configuradas, sem parar na primeira,
+// para que o tempo de resposta não indique qual delas quase coincidiu
+func (a *Authenticator) identifyKey(key string) (*Principal, error) {
+	var principal *Principal
+	if a.config.APIKey != "" && subtle.ConstantTimeCompare([]byte(key), []byte(a.config.APIKey)) == 1 {
+		principal = &Principal{Name: "admin", Role: RoleAdmin}
+	}
+	for _, configured := range a.config.Keys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(configured.Key)) == 1 && principal == nil {
+			principal = &Principal{Name: configured.Name, Role: configured.Role, Tenants: configured.Tenants}
+		}
+	}
+
+	if principal == nil {
+		return nil, ErrInvalidKey
+	}
+	return principal, nil
+}
+
+// verifySignature valida timestamp, assinatura e nonce, nessa ordem: o nonce
+// só é consumido por requisições autênticas
+func (a *Authenticator) verifySignature(r *http.Request) error {
+	timestamp := r.Header.Get(TimestampHeader)
+	nonce := r.Header.Get(NonceHeader)
+	if timestamp == "" || nonce == "" || len(nonce) > maxNonceLength {
+		return fmt.Errorf("%w: %s and %s are required", ErrInvalidSignature, TimestampHeader, NonceHeader)
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: invalid %s", ErrInvalidSignature, TimestampHeader)
+	}
+	now := a.now()
+	signedAt := time.Unix(seconds, 0)
+	if signedAt.Before(now.Add(-a.config.MaxSkew)) || signedAt.After(now.Add(a.config.MaxSkew)) {
+		return ErrStaleTimestamp
+	}
+
+	body, err := readBody(r)
+	if err != nil {
+		return err
+	}
+
+	expected := Signature(a.config.HMACSecret, r.Method, r.URL.RequestURI(), timestamp, nonce, body)
+	if !hmac.Equal([]byte(strings.ToLower(r.Header.Get(SignatureHeader))), []byte(expected)) {
+		return ErrInvalidSignature
+	}
+
+	return a.useNonce(nonce, now)
+}
+
+// useNonce registra o nonce até o fim da janela de timestamp; depois disso a
+// própria validação do timestamp rejeita a repetição
+func (a *Authenticator) useNonce(nonce string, now time.Time) error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if now.Sub(a.lastSweep) >= a.config.MaxSkew {
+		a.lastSweep = now
+		for value, expiresAt := range a.nonces {
+			if !now.Before(expiresAt) {
+				delete(a.nonces, value)
+			}
+		}
+	}
+
+	if expiresAt, used := a.nonces[nonce]; used && now.Before(expiresAt) {
+		return ErrReplayed
+	}
+	a.nonces[nonce] = now.Add(2 * a.config.MaxSkew)
+	return nil
+}
+
+// readBody lê o corpo para o hash da assinatura e o restaura na requisição
+func readBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxSignedBodyBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+	if len(body) > maxSignedBodyBytes {
+		return nil, fmt.Errorf("%w: body exceeds %d bytes", ErrInvalidSignature, maxSignedBodyBytes)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// Signature calcula a assinatura de uma requisição administrativa:
+//
+//	hex(HMAC-SHA256(secret, timestamp + "\n" + nonce + "\n" + METHOD + "\n" + path?query + "\n" + hex(SHA256(body))))
+func Signature(secret, method, requestURI, timestamp, nonce string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	canonical := strings.Join([]string{
+		timestamp,
+		nonce,
+		strings.ToUpper(method),
+		requestURI,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(canonical))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Sign assina a requisição com o segredo, preenchendo os headers de timestamp,
+// nonce e assinatura (uso em clientes e testes)
+func Sign(r *http.Request, secret, nonce string, now time.Time) error {
+	body, err := readBody(r)
+	if err != nil {
+		return err
+	}
+
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	r.Header.Set(TimestampHeader, timestamp)
+	r.Header.Set(NonceHeader, nonce)
+	r.Header.Set(SignatureHeader, Signature(secret, r.Method, r.URL.RequestURI(), timestamp, nonce, body))
+	return nil
+}
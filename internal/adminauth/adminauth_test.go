@@ -0,0 +1,123 @@
+package adminauth
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+// newTestAuthenticator cria o autenticador com relógio controlado
+func newTestAuthenticator(config Config) (*Authenticator, *time.Time) {
+	authenticator := New(config)
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	authenticator.now = func() time.Time { return now }
+	return authenticator, &now
+}
+
+func signedRequest(t *testing.T, body, nonce string, at time.Time) *http.Request {
+	req := httptest.NewRequest("POST", "/admin/reset?key=10.0.0.1", strings.NewReader(body))
+	require.NoError(t, Sign(req, testSecret, nonce, at))
+	return req
+}
+
+func TestNew_Disabled(t *testing.T) {
+	assert.Nil(t, New(Config{}))
+}
+
+func TestAuthenticate_APIKey(t *testing.T) {
+	// Arrange
+	authenticator, _ := newTestAuthenticator(Config{APIKey: "admin-key"})
+
+	valid := httptest.NewRequest("GET", "/admin/status", nil)
+	valid.Header.Set("Authorization", "Bearer admin-key")
+	invalid := httptest.NewRequest("GET", "/admin/status", nil)
+	invalid.Header.Set("Authorization", "Bearer other-key")
+
+	// Act & Assert
+	assert.NoError(t, authenticator.Authenticate(valid))
+	assert.ErrorIs(t, authenticator.Authenticate(invalid), ErrInvalidKey)
+	assert.ErrorIs(t, authenticator.Authenticate(httptest.NewRequest("GET", "/admin/status", nil)), ErrMissingCredentials)
+}
+
+func TestAuthenticate_Signature(t *testing.T) {
+	// Arrange
+	authenticator, now := newTestAuthenticator(Config{HMACSecret: testSecret})
+	req := signedRequest(t, `{"key":"10.0.0.1"}`, "nonce-1", *now)
+
+	// Act
+	err := authenticator.Authenticate(req)
+
+	// Assert: o corpo continua disponível para o handler
+	require.NoError(t, err)
+	body, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, `{"key":"10.0.0.1"}`, string(body))
+}
+
+func TestAuthenticate_SignatureRejected(t *testing.T) {
+	authenticator, now := newTestAuthenticator(Config{HMACSecret: testSecret, MaxSkew: time.Minute})
+
+	t.Run("tampered body", func(t *testing.T) {
+		req := signedRequest(t, `{"key":"10.0.0.1"}`, "nonce-body", *now)
+		req.Body = io.NopCloser(strings.NewReader(`{"key":"10.0.0.2"}`))
+		assert.ErrorIs(t, authenticator.Authenticate(req), ErrInvalidSignature)
+	})
+
+	t.Run("tampered query", func(t *testing.T) {
+		req := signedRequest(t, "", "nonce-query", *now)
+		req.URL.RawQuery = "key=10.0.0.2"
+		assert.ErrorIs(t, authenticator.Authenticate(req), ErrInvalidSignature)
+	})
+
+	t.Run("stale timestamp", func(t *testing.T) {
+		req := signedRequest(t, "", "nonce-stale", now.Add(-2*time.Minute))
+		assert.ErrorIs(t, authenticator.Authenticate(req), ErrStaleTimestamp)
+	})
+
+	t.Run("missing nonce", func(t *testing.T) {
+		req := signedRequest(t, "", "nonce-missing", *now)
+		req.Header.Del(NonceHeader)
+		assert.ErrorIs(t, authenticator.Authenticate(req), ErrInvalidSignature)
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/admin/status", nil)
+		require.NoError(t, Sign(req, "another-secret-another-secret-00", "nonce-secret", *now))
+		assert.ErrorIs(t, authenticator.Authenticate(req), ErrInvalidSignature)
+	})
+}
+
+func TestAuthenticate_Replay(t *testing.T) {
+	// Arrange
+	authenticator, now := newTestAuthenticator(Config{HMACSecret: testSecret, MaxSkew: time.Minute})
+	signedAt := *now
+
+	// Act & Assert: a mesma requisição assinada só é aceita uma vez
+	require.NoError(t, authenticator.Authenticate(signedRequest(t, "", "nonce-1", signedAt)))
+	assert.ErrorIs(t, authenticator.Authenticate(signedRequest(t, "", "nonce-1", signedAt)), ErrReplayed)
+
+	// Após a janela, o timestamp antigo é rejeitado antes do nonce
+	*now = now.Add(3 * time.Minute)
+	assert.ErrorIs(t, authenticator.Authenticate(signedRequest(t, "", "nonce-1", signedAt)), ErrStaleTimestamp)
+	assert.NoError(t, authenticator.Authenticate(signedRequest(t, "", "nonce-2", *now)))
+	assert.NotContains(t, authenticator.nonces, "nonce-1")
+}
+
+func TestSignature(t *testing.T) {
+	// Assinatura de referência para implementações em outras linguagens
+	timestamp := strconv.FormatInt(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Unix(), 10)
+	signature := Signature(testSecret, "post", "/admin/reset", timestamp, "abc", []byte(`{"key":"10.0.0.1"}`))
+
+	assert.Len(t, signature, 64)
+	assert.Equal(t, signature, Signature(testSecret, "POST", "/admin/reset", timestamp, "abc", []byte(`{"key":"10.0.0.1"}`)))
+	assert.NotEqual(t, signature, Signature(testSecret, "POST", "/admin/reset", timestamp, "abd", []byte(`{"key":"10.0.0.1"}`)))
+}
@@ -0,0 +1,120 @@
+package adminauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Role é o papel de uma credencial administrativa; cada papel inclui os anteriores
+type Role string
+
+const (
+	RoleReadOnly Role = "read-only" // Consultas (status, relatórios, regras)
+	RoleOperator Role = "operator"  // Ações operacionais (reset, override, manutenção, drain)
+	RoleAdmin    Role = "admin"     // Mudanças de regras e de configuração
+)
+
+var roleRanks = map[Role]int{RoleReadOnly: 1, RoleOperator: 2, RoleAdmin: 3}
+
+// ErrForbidden indica uma credencial válida sem permissão para a operação
+var ErrForbidden = errors.New("insufficient admin permissions")
+
+// ParseRole valida o nome do papel
+func ParseRole(value string) (Role, error) {
+	role := Role(strings.ToLower(strings.TrimSpace(value)))
+	if _, ok := roleRanks[role]; !ok {
+		return "", fmt.Errorf("invalid admin role %q: must be 'read-only', 'operator' or 'admin'", value)
+	}
+	return role, nil
+}
+
+// Allows informa se o papel cobre o papel exigido
+func (r Role) Allows(required Role) bool {
+	return roleRanks[r] >= roleRanks[required]
+}
+
+// Principal é a identidade autenticada de uma requisição administrativa
+type Principal struct {
+	Name    string   `json:"name"`
+	Role    Role     `json:"role"`
+	Tenants []string `json:"tenants,omitempty"` // Organizações acessíveis (vazio = todas)
+}
+
+// Scoped informa se a credencial é restrita a alguns tenants
+func (p *Principal) Scoped() bool {
+	return len(p.Tenants) > 0
+}
+
+// InTenant informa se a credencial alcança o tenant
+func (p *Principal) InTenant(tenant string) bool {
+	if !p.Scoped() {
+		return true
+	}
+	for _, allowed := range p.Tenants {
+		if tenant != "" && allowed == tenant {
+			return true
+		}
+	}
+	return false
+}
+
+// KeyConfig é uma chave estática nomeada, com papel e tenants próprios
+type KeyConfig struct {
+	Name    string
+	Key     string
+	Role    Role
+	Tenants []string
+}
+
+// ParseKeys lê a lista de chaves no formato "nome:papel:chave[:tenant|tenant],..."
+// (ex: "suporte:read-only:sk_123,acme-ops:operator:sk_456:acme")
+func ParseKeys(spec string) ([]KeyConfig, error) {
+	var keys []KeyConfig
+	names := make(map[string]bool)
+	for i, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.Split(entry, ":")
+		if len(parts) < 3 || len(parts) > 4 || parts[0] == "" || parts[2] == "" {
+			return nil, fmt.Errorf("invalid admin key #%d: expected name:role:key[:tenant|tenant]", i+1)
+		}
+		if names[parts[0]] {
+			return nil, fmt.Errorf("duplicate admin key name %q", parts[0])
+		}
+		names[parts[0]] = true
+
+		role, err := ParseRole(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid admin key %q: %w", parts[0], err)
+		}
+
+		key := KeyConfig{Name: parts[0], Key: parts[2], Role: role}
+		if len(parts) == 4 {
+			for _, tenant := range strings.Split(parts[3], "|") {
+				if tenant = strings.TrimSpace(tenant); tenant != "" {
+					key.Tenants = append(key.Tenants, tenant)
+				}
+			}
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+type principalContextKey struct{}
+
+// WithPrincipal guarda a identidade autenticada no contexto da requisição
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFrom retorna a identidade autenticada (nil = API administrativa aberta)
+func PrincipalFrom(ctx context.Context) *Principal {
+	principal, _ := ctx.Value(principalContextKey{}).(*Principal)
+	return principal
+}
@@ -0,0 +1,79 @@
+package adminauth
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseKeys(t *testing.T) {
+	// Act
+	keys, err := ParseKeys("support:read-only:sk_support, acme-ops:Operator:sk_acme:acme|acme-eu,")
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.Equal(t, KeyConfig{Name: "support", Key: "sk_support", Role: RoleReadOnly}, keys[0])
+	assert.Equal(t, KeyConfig{Name: "acme-ops", Key: "sk_acme", Role: RoleOperator, Tenants: []string{"acme", "acme-eu"}}, keys[1])
+
+	for _, invalid := range []string{"sk_only", "support:owner:sk_1", "a:admin:k1,a:admin:k2", "a:admin:"} {
+		_, err := ParseKeys(invalid)
+		assert.Error(t, err, invalid)
+	}
+	_, err = ParseKeys("sk_secret_value")
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "sk_secret_value", "errors must not echo the key")
+}
+
+func TestRole_Allows(t *testing.T) {
+	assert.True(t, RoleAdmin.Allows(RoleOperator))
+	assert.True(t, RoleOperator.Allows(RoleReadOnly))
+	assert.True(t, RoleReadOnly.Allows(RoleReadOnly))
+	assert.False(t, RoleReadOnly.Allows(RoleOperator))
+	assert.False(t, RoleOperator.Allows(RoleAdmin))
+	assert.False(t, Role("").Allows(RoleReadOnly))
+}
+
+func TestIdentify_NamedKeys(t *testing.T) {
+	// Arrange
+	authenticator, _ := newTestAuthenticator(Config{
+		APIKey: "admin-key",
+		Keys: []KeyConfig{
+			{Name: "support", Key: "support-key", Role: RoleReadOnly},
+			{Name: "acme-ops", Key: "acme-key", Role: RoleOperator, Tenants: []string{"acme"}},
+		},
+	})
+	identify := func(key string) (*Principal, error) {
+		req := httptest.NewRequest("GET", "/admin/status", nil)
+		req.Header.Set("Authorization", "Bearer "+key)
+		return authenticator.Identify(req)
+	}
+
+	// Act
+	admin, adminErr := identify("admin-key")
+	support, supportErr := identify("support-key")
+	scoped, scopedErr := identify("acme-key")
+	_, invalidErr := identify("other-key")
+
+	// Assert
+	require.NoError(t, adminErr)
+	assert.Equal(t, RoleAdmin, admin.Role)
+	assert.False(t, admin.Scoped())
+	require.NoError(t, supportErr)
+	assert.Equal(t, &Principal{Name: "support", Role: RoleReadOnly}, support)
+	require.NoError(t, scopedErr)
+	assert.True(t, scoped.InTenant("acme"))
+	assert.False(t, scoped.InTenant("globex"))
+	assert.False(t, scoped.InTenant(""), "scoped credentials never reach keys without a tenant")
+	assert.ErrorIs(t, invalidErr, ErrInvalidKey)
+}
+
+func TestPrincipalContext(t *testing.T) {
+	principal := &Principal{Name: "support", Role: RoleReadOnly}
+
+	assert.Nil(t, PrincipalFrom(context.Background()))
+	assert.Same(t, principal, PrincipalFrom(WithPrincipal(context.Background(), principal)))
+}
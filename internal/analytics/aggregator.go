@@ -0,0 +1,181 @@
+package analytics
+
+import (
+	"context"
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"rate-limiter/internal/domain"
+)
+
+// DefaultMaxKeysPerMinute limita as chaves únicas acompanhadas por minuto
+// Acima disso a contagem satura em vez de crescer a memória sem limite
+const DefaultMaxKeysPerMinute = 100000
+
+// Aggregator acumula as decisões do minuto corrente e grava o bucket no Store
+// quando o minuto fecha; o minuto em andamento não aparece nas consultas
+type Aggregator struct {
+	store   Store
+	logger  domain.Logger
+	maxKeys int
+
+	mutex   sync.Mutex
+	current Bucket
+	keys    map[string]struct{}
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+	started  atomic.Bool
+
+	now func() time.Time
+}
+
+// NewAggregator cria o agregador sobre um Store
+func NewAggregator(store Store, logger domain.Logger) *Aggregator {
+	aggregator := &Aggregator{
+		store:   store,
+		logger:  logger,
+		maxKeys: DefaultMaxKeysPerMinute,
+		keys:    make(map[string]struct{}),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+		now:     time.Now,
+	}
+	aggregator.current.Minute = aggregator.now().UTC().Truncate(time.Minute)
+	return aggregator
+}
+
+// ObserveDecision implementa domain.DecisionObserver
+func (a *Aggregator) ObserveDecision(key string, limiterType domain.LimiterType, allowed bool) {
+	minute := a.now().UTC().Truncate(time.Minute)
+	id := hashKey(key, limiterType)
+
+	a.mutex.Lock()
+	if minute.After(a.current.Minute) {
+		// Normalmente o loop de background vira o minuto antes; aqui a gravação não bloqueia a requisição
+		closed, keys := a.rotateLocked(minute)
+		go a.write(closed, keys)
+	}
+	if allowed {
+		a.current.Allowed++
+	} else {
+		a.current.Denied++
+	}
+	if len(a.keys) < a.maxKeys {
+		a.keys[id] = struct{}{}
+	}
+	a.mutex.Unlock()
+}
+
+// Buckets retorna os minutos fechados em [from, to), preenchendo minutos sem tráfego com zero
+func (a *Aggregator) Buckets(ctx context.Context, from, to time.Time) ([]Bucket, error) {
+	from = from.UTC().Truncate(time.Minute)
+	stored, err := a.store.Buckets(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	byMinute := make(map[int64]Bucket, len(stored))
+	for _, bucket := range stored {
+		byMinute[bucket.Minute.Unix()] = bucket
+	}
+
+	result := make([]Bucket, 0)
+	for minute := from; minute.Before(to); minute = minute.Add(time.Minute) {
+		bucket, exists := byMinute[minute.Unix()]
+		if !exists {
+			bucket = Bucket{Minute: minute}
+		}
+		result = append(result, bucket)
+	}
+	return result, nil
+}
+
+// Start inicia a gravação dos minutos fechados mesmo sem tráfego novo
+func (a *Aggregator) Start() {
+	if a.started.CompareAndSwap(false, true) {
+		go a.run()
+	}
+}
+
+// Stop grava o minuto em andamento e encerra o agregador
+func (a *Aggregator) Stop() {
+	a.stopOnce.Do(func() {
+		close(a.stop)
+	})
+	if a.started.Load() {
+		<-a.done
+	}
+}
+
+// run verifica a virada do minuto a cada segundo
+func (a *Aggregator) run() {
+	defer close(a.done)
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.stop:
+			a.flush(a.now().UTC().Truncate(time.Minute).Add(time.Minute))
+			return
+		case <-ticker.C:
+			a.flush(a.now().UTC().Truncate(time.Minute))
+		}
+	}
+}
+
+// flush grava o bucket corrente se o minuto dele já fechou
+func (a *Aggregator) flush(minute time.Time) {
+	a.mutex.Lock()
+	if !minute.After(a.current.Minute) {
+		a.mutex.Unlock()
+		return
+	}
+	closed, keys := a.rotateLocked(minute)
+	a.mutex.Unlock()
+
+	a.write(closed, keys)
+}
+
+// rotateLocked fecha o bucket corrente e inicia o do minuto informado
+func (a *Aggregator) rotateLocked(minute time.Time) (Bucket, []string) {
+	closed := a.current
+	keys := make([]string, 0, len(a.keys))
+	for key := range a.keys {
+		keys = append(keys, key)
+	}
+
+	a.current = Bucket{Minute: minute}
+	a.keys = make(map[string]struct{})
+	return closed, keys
+}
+
+// write grava um bucket fechado no store
+func (a *Aggregator) write(closed Bucket, keys []string) {
+	if closed.Allowed == 0 && closed.Denied == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := a.store.Add(ctx, closed, keys); err != nil && a.logger != nil {
+		a.logger.Error("Failed to store analytics bucket", err, map[string]interface{}{
+			"minute": closed.Minute.Format(time.RFC3339),
+		})
+	}
+}
+
+// hashKey evita guardar IPs e tokens em claro no store
+func hashKey(key string, limiterType domain.LimiterType) string {
+	hash := fnv.New64a()
+	hash.Write([]byte(limiterType))
+	hash.Write([]byte{0})
+	hash.Write([]byte(key))
+	return strconv.FormatUint(hash.Sum64(), 36)
+}
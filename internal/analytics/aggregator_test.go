@@ -0,0 +1,79 @@
+package analytics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/domain"
+)
+
+func TestAggregator_FlushesClosedMinutes(t *testing.T) {
+	// Arrange
+	now := time.Date(2024, 1, 1, 10, 0, 30, 0, time.UTC)
+	store := NewMemoryStore(time.Hour)
+	aggregator := NewAggregator(store, nil)
+	aggregator.now = func() time.Time { return now }
+	aggregator.current.Minute = now.Truncate(time.Minute)
+
+	aggregator.ObserveDecision("10.0.0.1", domain.IPLimiter, true)
+	aggregator.ObserveDecision("10.0.0.1", domain.IPLimiter, true)
+	aggregator.ObserveDecision("10.0.0.2", domain.IPLimiter, false)
+	aggregator.ObserveDecision("10.0.0.2", domain.TokenLimiter, true) // mesmo valor, outro tipo
+
+	// Minuto em andamento ainda não aparece
+	buckets, err := aggregator.Buckets(context.Background(), now.Add(-time.Minute), now.Add(time.Minute))
+	require.NoError(t, err)
+	for _, bucket := range buckets {
+		assert.Zero(t, bucket.Allowed+bucket.Denied)
+	}
+
+	// Act: virada do minuto
+	now = now.Add(2 * time.Minute)
+	aggregator.flush(now.Truncate(time.Minute))
+
+	// Assert: minutos contínuos, sem tráfego preenchidos com zero
+	from := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	buckets, err = aggregator.Buckets(context.Background(), from, from.Add(3*time.Minute))
+	require.NoError(t, err)
+	require.Len(t, buckets, 3)
+	assert.Equal(t, Bucket{Minute: from, Allowed: 3, Denied: 1, UniqueKeys: 3}, buckets[0])
+	assert.Equal(t, Bucket{Minute: from.Add(time.Minute)}, buckets[1])
+}
+
+func TestAggregator_CapsUniqueKeys(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	store := NewMemoryStore(time.Hour)
+	aggregator := NewAggregator(store, nil)
+	aggregator.now = func() time.Time { return now }
+	aggregator.current.Minute = now
+	aggregator.maxKeys = 2
+
+	for _, key := range []string{"a", "b", "c", "d"} {
+		aggregator.ObserveDecision(key, domain.TokenLimiter, true)
+	}
+	aggregator.flush(now.Add(time.Minute))
+
+	buckets, err := store.Buckets(context.Background(), now, now.Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, buckets, 1)
+	assert.Equal(t, uint64(4), buckets[0].Allowed)
+	assert.Equal(t, uint64(2), buckets[0].UniqueKeys)
+}
+
+func TestMemoryStore_DropsMinutesBeyondRetention(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	store := NewMemoryStore(10 * time.Minute)
+
+	for i := 0; i < 30; i++ {
+		require.NoError(t, store.Add(context.Background(), Bucket{Minute: start.Add(time.Duration(i) * time.Minute), Allowed: 1}, nil))
+	}
+
+	buckets, err := store.Buckets(context.Background(), start, start.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, buckets, 10)
+	assert.Equal(t, start.Add(20*time.Minute), buckets[0].Minute)
+}
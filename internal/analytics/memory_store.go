@@ -0,0 +1,65 @@
+package analytics
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore mantém os buckets apenas nesta instância
+type MemoryStore struct {
+	retention time.Duration
+
+	mutex   sync.RWMutex
+	buckets map[int64]Bucket // minuto (unix) -> bucket
+}
+
+// NewMemoryStore cria um store em memória que descarta minutos além da retenção
+func NewMemoryStore(retention time.Duration) *MemoryStore {
+	return &MemoryStore{
+		retention: retention,
+		buckets:   make(map[int64]Bucket),
+	}
+}
+
+// Add soma as decisões ao bucket do minuto
+// Em uma única instância cada minuto é gravado uma vez, então len(keys) é a contagem exata
+func (s *MemoryStore) Add(ctx context.Context, bucket Bucket, keys []string) error {
+	minute := bucket.Minute.Unix()
+	cutoff := bucket.Minute.Add(-s.retention).Unix()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	existing := s.buckets[minute]
+	existing.Minute = bucket.Minute.UTC()
+	existing.Allowed += bucket.Allowed
+	existing.Denied += bucket.Denied
+	existing.UniqueKeys += uint64(len(keys))
+	s.buckets[minute] = existing
+
+	for stored := range s.buckets {
+		if stored <= cutoff {
+			delete(s.buckets, stored)
+		}
+	}
+	return nil
+}
+
+// Buckets retorna os buckets com dados em [from, to)
+func (s *MemoryStore) Buckets(ctx context.Context, from, to time.Time) ([]Bucket, error) {
+	s.mutex.RLock()
+	result := make([]Bucket, 0)
+	for _, bucket := range s.buckets {
+		if !bucket.Minute.Before(from) && bucket.Minute.Before(to) {
+			result = append(result, bucket)
+		}
+	}
+	s.mutex.RUnlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Minute.Before(result[j].Minute)
+	})
+	return result, nil
+}
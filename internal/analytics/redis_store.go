@@ -0,0 +1,133 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// DefaultKeyPrefix é o prefixo das chaves de analytics no Redis
+const DefaultKeyPrefix = "rate_limiter:analytics:"
+
+// pfaddBatch limita quantas chaves vão em cada PFADD
+const pfaddBatch = 1000
+
+// RedisStore compartilha os buckets entre réplicas
+// Contadores ficam em um hash por minuto e as chaves únicas em um HyperLogLog,
+// então a união entre instâncias é aproximada (erro típico < 1%)
+type RedisStore struct {
+	client    redis.Cmdable
+	prefix    string
+	retention time.Duration
+}
+
+// NewRedisStore cria o store sobre um cliente Redis
+func NewRedisStore(client redis.Cmdable, retention time.Duration) *RedisStore {
+	return &RedisStore{
+		client:    client,
+		prefix:    DefaultKeyPrefix,
+		retention: retention,
+	}
+}
+
+// Add soma as decisões da instância ao bucket do minuto
+func (s *RedisStore) Add(ctx context.Context, bucket Bucket, keys []string) error {
+	countersKey, keysKey := s.bucketKeys(bucket.Minute)
+	// A expiração conta a partir do fim do minuto
+	expireAt := bucket.Minute.Add(time.Minute + s.retention)
+
+	pipe := s.client.TxPipeline()
+	pipe.HIncrBy(ctx, countersKey, "allowed", int64(bucket.Allowed))
+	pipe.HIncrBy(ctx, countersKey, "denied", int64(bucket.Denied))
+	pipe.ExpireAt(ctx, countersKey, expireAt)
+	for start := 0; start < len(keys); start += pfaddBatch {
+		end := start + pfaddBatch
+		if end > len(keys) {
+			end = len(keys)
+		}
+		members := make([]interface{}, 0, end-start)
+		for _, key := range keys[start:end] {
+			members = append(members, key)
+		}
+		pipe.PFAdd(ctx, keysKey, members...)
+	}
+	if len(keys) > 0 {
+		pipe.ExpireAt(ctx, keysKey, expireAt)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to store analytics bucket: %w", err)
+	}
+	return nil
+}
+
+// Buckets lê os minutos de [from, to) em um único pipeline
+func (s *RedisStore) Buckets(ctx context.Context, from, to time.Time) ([]Bucket, error) {
+	type pending struct {
+		minute   time.Time
+		counters *redis.SliceCmd
+		unique   *redis.IntCmd
+	}
+
+	pipe := s.client.Pipeline()
+	var reads []pending
+	for minute := from.Truncate(time.Minute); minute.Before(to); minute = minute.Add(time.Minute) {
+		if minute.Before(from) {
+			continue
+		}
+		countersKey, keysKey := s.bucketKeys(minute)
+		reads = append(reads, pending{
+			minute:   minute.UTC(),
+			counters: pipe.HMGet(ctx, countersKey, "allowed", "denied"),
+			unique:   pipe.PFCount(ctx, keysKey),
+		})
+	}
+	if len(reads) == 0 {
+		return []Bucket{}, nil
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to read analytics buckets: %w", err)
+	}
+
+	result := make([]Bucket, 0, len(reads))
+	for _, read := range reads {
+		values := read.counters.Val()
+		bucket := Bucket{
+			Minute:     read.minute,
+			Allowed:    parseCounter(values, 0),
+			Denied:     parseCounter(values, 1),
+			UniqueKeys: uint64(read.unique.Val()),
+		}
+		if bucket.Allowed == 0 && bucket.Denied == 0 {
+			continue
+		}
+		result = append(result, bucket)
+	}
+	return result, nil
+}
+
+// bucketKeys monta as chaves de contadores e de chaves únicas do minuto
+func (s *RedisStore) bucketKeys(minute time.Time) (string, string) {
+	base := fmt.Sprintf("%s%d", s.prefix, minute.Unix())
+	return base, base + ":keys"
+}
+
+// parseCounter converte um campo do HMGET (string ou nil) em contador
+func parseCounter(values []interface{}, index int) uint64 {
+	if index >= len(values) {
+		return 0
+	}
+	raw, ok := values[index].(string)
+	if !ok {
+		return 0
+	}
+	value, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return value
+}
@@ -0,0 +1,23 @@
+package analytics
+
+import (
+	"context"
+	"time"
+)
+
+// Bucket agrega as decisões de um minuto
+type Bucket struct {
+	Minute     time.Time `json:"minute"`
+	Allowed    uint64    `json:"allowed"`
+	Denied     uint64    `json:"denied"`
+	UniqueKeys uint64    `json:"uniqueKeys"`
+}
+
+// Store persiste os buckets por minuto com retenção limitada
+type Store interface {
+	// Add soma ao bucket do minuto as decisões e as chaves (hashes) vistas por uma instância
+	Add(ctx context.Context, bucket Bucket, keys []string) error
+
+	// Buckets retorna os buckets com dados em [from, to), ordenados por minuto
+	Buckets(ctx context.Context, from, to time.Time) ([]Bucket, error)
+}
@@ -0,0 +1,268 @@
+package anomaly
+
+import (
+	"context"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/events"
+)
+
+// EventSpike é o tipo do evento emitido quando um pico é detectado
+const EventSpike = "anomaly.spike"
+
+// globalKey identifica a série agregada de todas as requisições
+const globalKey = "*"
+
+// Config define a sensibilidade do detector
+type Config struct {
+	Interval    time.Duration // Duração de cada amostra
+	Alpha       float64       // Peso da amostra mais recente no EWMA (0-1)
+	ZThreshold  float64       // z-score a partir do qual a amostra é um pico
+	MinRequests int           // Amostras abaixo disso nunca alertam (evita ruído em chaves frias)
+	Warmup      int           // Amostras necessárias antes de alertar
+	MaxKeys     int           // Limite de chaves acompanhadas individualmente
+}
+
+// DefaultConfig retorna valores conservadores para produção
+func DefaultConfig() Config {
+	return Config{
+		Interval:    10 * time.Second,
+		Alpha:       0.3,
+		ZThreshold:  4,
+		MinRequests: 20,
+		Warmup:      3,
+		MaxKeys:     10000,
+	}
+}
+
+// series acompanha a taxa de uma chave com média e variância exponenciais
+type series struct {
+	limiterType domain.LimiterType
+	current     atomic.Int64
+	mean        float64
+	variance    float64
+	samples     int
+	flagged     bool // já alertou neste pico; rearma quando a taxa normaliza
+}
+
+// Detector sinaliza picos súbitos de taxa de requisições por chave e no total
+// antes que os limites sejam atingidos (ex: scraping, credential stuffing)
+type Detector struct {
+	config    Config
+	publisher events.Publisher
+	logger    domain.Logger
+
+	mutex  sync.RWMutex
+	keys   map[string]*series
+	global *series
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+	started  atomic.Bool
+}
+
+// NewDetector cria o detector; valores zerados em config usam DefaultConfig
+func NewDetector(config Config, publisher events.Publisher, logger domain.Logger) *Detector {
+	defaults := DefaultConfig()
+	if config.Interval <= 0 {
+		config.Interval = defaults.Interval
+	}
+	if config.Alpha <= 0 || config.Alpha > 1 {
+		config.Alpha = defaults.Alpha
+	}
+	if config.ZThreshold <= 0 {
+		config.ZThreshold = defaults.ZThreshold
+	}
+	if config.MinRequests <= 0 {
+		config.MinRequests = defaults.MinRequests
+	}
+	if config.Warmup <= 0 {
+		config.Warmup = defaults.Warmup
+	}
+	if config.MaxKeys <= 0 {
+		config.MaxKeys = defaults.MaxKeys
+	}
+
+	return &Detector{
+		config:    config,
+		publisher: publisher,
+		logger:    logger,
+		keys:      make(map[string]*series),
+		global:    &series{},
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// ObserveRequest implementa domain.TrafficObserver
+func (d *Detector) ObserveRequest(key string, limiterType domain.LimiterType) {
+	d.global.current.Add(1)
+
+	d.mutex.RLock()
+	tracked, exists := d.keys[key]
+	d.mutex.RUnlock()
+
+	if !exists {
+		d.mutex.Lock()
+		tracked, exists = d.keys[key]
+		if !exists {
+			if len(d.keys) >= d.config.MaxKeys {
+				d.mutex.Unlock()
+				return
+			}
+			tracked = &series{limiterType: limiterType}
+			d.keys[key] = tracked
+		}
+		d.mutex.Unlock()
+	}
+
+	tracked.current.Add(1)
+}
+
+// Start inicia o fechamento periódico das amostras
+func (d *Detector) Start() {
+	if d.started.CompareAndSwap(false, true) {
+		go d.run()
+	}
+}
+
+// Stop encerra o detector
+func (d *Detector) Stop() {
+	d.stopOnce.Do(func() {
+		close(d.stop)
+	})
+	if d.started.Load() {
+		<-d.done
+	}
+}
+
+// TrackedKeys retorna quantas chaves estão sendo acompanhadas
+func (d *Detector) TrackedKeys() int {
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+	return len(d.keys)
+}
+
+// run é o loop de amostragem
+func (d *Detector) run() {
+	defer close(d.done)
+
+	ticker := time.NewTicker(d.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+			d.Tick()
+		}
+	}
+}
+
+// Tick fecha a amostra atual de todas as séries e emite os alertas
+func (d *Detector) Tick() {
+	if found, ok := d.sample(d.global); ok {
+		d.emit(globalKey, "", found)
+	}
+
+	d.mutex.Lock()
+	type alert struct {
+		key         string
+		limiterType domain.LimiterType
+		spike       spike
+	}
+	var alerts []alert
+	for key, tracked := range d.keys {
+		if found, ok := d.sample(tracked); ok {
+			alerts = append(alerts, alert{key: key, limiterType: tracked.limiterType, spike: found})
+		}
+		// Chaves ociosas saem do acompanhamento quando a média decai
+		if tracked.mean < 0.1 && tracked.current.Load() == 0 {
+			delete(d.keys, key)
+		}
+	}
+	d.mutex.Unlock()
+
+	for _, found := range alerts {
+		d.emit(found.key, found.limiterType, found.spike)
+	}
+}
+
+// spike descreve a amostra anômala
+type spike struct {
+	rate     int64
+	baseline float64
+	zScore   float64
+}
+
+// sample fecha a amostra da série, atualiza o EWMA e informa se houve pico
+// A série é acessada apenas pelo Tick (com o lock do detector no caso das chaves)
+func (d *Detector) sample(s *series) (spike, bool) {
+	rate := s.current.Swap(0)
+	x := float64(rate)
+
+	// Contagens de requisições se aproximam de Poisson: o desvio nunca é menor que sqrt(média)
+	deviation := math.Max(math.Sqrt(s.variance), math.Sqrt(math.Max(s.mean, 1)))
+	zScore := (x - s.mean) / deviation
+
+	found := spike{rate: rate, baseline: s.mean, zScore: zScore}
+	isSpike := s.samples >= d.config.Warmup &&
+		rate >= int64(d.config.MinRequests) &&
+		zScore >= d.config.ZThreshold
+
+	alert := isSpike && !s.flagged
+	s.flagged = isSpike
+
+	// Picos não contaminam a linha de base enquanto durarem
+	if !isSpike {
+		alpha := d.config.Alpha
+		diff := x - s.mean
+		s.mean += alpha * diff
+		s.variance = (1 - alpha) * (s.variance + alpha*diff*diff)
+	}
+	s.samples++
+
+	return found, alert
+}
+
+// emit registra e publica o alerta de pico
+func (d *Detector) emit(key string, limiterType domain.LimiterType, found spike) {
+	data := map[string]interface{}{
+		"scope":            "key",
+		"key":              maskKey(key, limiterType),
+		"limiter_type":     string(limiterType),
+		"requests":         found.rate,
+		"baseline":         math.Round(found.baseline*100) / 100,
+		"z_score":          math.Round(found.zScore*100) / 100,
+		"interval_seconds": d.config.Interval.Seconds(),
+	}
+	if key == globalKey {
+		data["scope"] = "global"
+		delete(data, "key")
+		delete(data, "limiter_type")
+	}
+
+	if d.logger != nil {
+		d.logger.Warn("Traffic spike detected", data)
+	}
+	if d.publisher != nil {
+		d.publisher.Publish(context.Background(), EventSpike, data)
+	}
+}
+
+// maskKey mascara tokens antes de enviá-los para fora (mesma regra dos logs)
+func maskKey(key string, limiterType domain.LimiterType) string {
+	if limiterType != domain.TokenLimiter || key == "" {
+		return key
+	}
+	if len(key) <= 8 {
+		return key + "***"
+	}
+	return key[:8] + "***"
+}
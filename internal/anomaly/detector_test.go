@@ -0,0 +1,113 @@
+package anomaly
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/events"
+)
+
+// newTestDetector cria um detector com captura dos eventos publicados
+func newTestDetector(config Config) (*Detector, *[]events.Event) {
+	bus := events.NewBus()
+	received := &[]events.Event{}
+	bus.Subscribe(func(event events.Event) { *received = append(*received, event) })
+	return NewDetector(config, bus, nil), received
+}
+
+// observe simula n requisições de uma chave na amostra atual
+func observe(detector *Detector, key string, limiterType domain.LimiterType, n int) {
+	for i := 0; i < n; i++ {
+		detector.ObserveRequest(key, limiterType)
+	}
+}
+
+func TestDetector_FlagsSpikeOnce(t *testing.T) {
+	// Arrange: linha de base estável de ~10 req por amostra
+	detector, received := newTestDetector(Config{ZThreshold: 4, MinRequests: 20, Warmup: 3})
+	for i := 0; i < 5; i++ {
+		observe(detector, "10.0.0.1", domain.IPLimiter, 10)
+		detector.Tick()
+	}
+	require.Empty(t, *received)
+
+	// Act: pico sustentado por duas amostras
+	observe(detector, "10.0.0.1", domain.IPLimiter, 80)
+	detector.Tick()
+	observe(detector, "10.0.0.1", domain.IPLimiter, 80)
+	detector.Tick()
+
+	// Assert: um alerta por chave e um global, apenas na transição
+	require.Len(t, *received, 2)
+	scopes := map[string]map[string]interface{}{}
+	for _, event := range *received {
+		assert.Equal(t, EventSpike, event.Type)
+		scopes[event.Data["scope"].(string)] = event.Data
+	}
+	assert.Equal(t, "10.0.0.1", scopes["key"]["key"])
+	assert.Equal(t, int64(80), scopes["key"]["requests"])
+	assert.Greater(t, scopes["key"]["z_score"].(float64), 4.0)
+	assert.NotContains(t, scopes["global"], "key")
+
+	// Act: tráfego normaliza e um novo pico volta a alertar
+	observe(detector, "10.0.0.1", domain.IPLimiter, 10)
+	detector.Tick()
+	observe(detector, "10.0.0.1", domain.IPLimiter, 80)
+	detector.Tick()
+	assert.Len(t, *received, 4)
+}
+
+func TestDetector_IgnoresQuietKeysAndWarmup(t *testing.T) {
+	detector, received := newTestDetector(Config{ZThreshold: 4, MinRequests: 20, Warmup: 3})
+
+	// Chave nova com rajada antes do aquecimento não alerta
+	observe(detector, "10.0.0.2", domain.IPLimiter, 100)
+	detector.Tick()
+	assert.Empty(t, *received)
+
+	// Salto grande em termos relativos, mas abaixo do mínimo absoluto
+	quiet, quietReceived := newTestDetector(Config{ZThreshold: 4, MinRequests: 20, Warmup: 3})
+	for i := 0; i < 5; i++ {
+		observe(quiet, "10.0.0.3", domain.IPLimiter, 1)
+		quiet.Tick()
+	}
+	observe(quiet, "10.0.0.3", domain.IPLimiter, 15)
+	quiet.Tick()
+	assert.Empty(t, *quietReceived)
+}
+
+func TestDetector_MasksTokensInEvents(t *testing.T) {
+	detector, received := newTestDetector(Config{ZThreshold: 4, MinRequests: 20, Warmup: 1})
+	observe(detector, "abcdefghijkl", domain.TokenLimiter, 1)
+	detector.Tick()
+
+	observe(detector, "abcdefghijkl", domain.TokenLimiter, 100)
+	detector.Tick()
+
+	for _, event := range *received {
+		if event.Data["scope"] == "key" {
+			assert.Equal(t, "abcdefgh***", event.Data["key"])
+			assert.Equal(t, "token", event.Data["limiter_type"])
+			return
+		}
+	}
+	t.Fatal("expected a key-scoped spike event")
+}
+
+func TestDetector_BoundsAndPrunesKeys(t *testing.T) {
+	detector, _ := newTestDetector(Config{MaxKeys: 3})
+	for i := 0; i < 10; i++ {
+		detector.ObserveRequest(fmt.Sprintf("10.0.0.%d", i), domain.IPLimiter)
+	}
+	assert.Equal(t, 3, detector.TrackedKeys())
+
+	// Sem tráfego, a média decai e as chaves deixam de ser acompanhadas
+	for i := 0; i < 20; i++ {
+		detector.Tick()
+	}
+	assert.Equal(t, 0, detector.TrackedKeys())
+}
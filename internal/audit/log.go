@@ -0,0 +1,159 @@
+// Package audit registra as operações administrativas que alteram estado (quem,
+// sobre quais chaves, quando e de onde) em um log próprio, consultável pela API
+// e entregue a sinks externos (arquivo JSON, webhook)
+package audit
+
+import (
+	"sync"
+	"time"
+
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/events"
+)
+
+// EventAdminAction é o tipo do evento entregue aos sinks
+const EventAdminAction = "admin.action"
+
+// DefaultCapacity limita os registros mantidos em memória para consulta
+const DefaultCapacity = 1000
+
+// Target é uma chave alcançada pela operação (tokens chegam mascarados)
+type Target struct {
+	Type string `json:"type"`
+	Key  string `json:"key"`
+}
+
+// Record é uma operação administrativa executada ou negada
+type Record struct {
+	Timestamp    time.Time         `json:"timestamp"`
+	Principal    string            `json:"principal"` // Nome da credencial ("anonymous" com a API aberta)
+	Role         string            `json:"role,omitempty"`
+	Method       string            `json:"method"`
+	Path         string            `json:"path"`
+	Targets      []Target          `json:"targets,omitempty"`
+	Params       map[string]string `json:"params,omitempty"` // Parâmetros de rotas globais (ex: id da manutenção)
+	SourceIP     string            `json:"source_ip"`
+	ForwardedFor string            `json:"forwarded_for,omitempty"`
+	RequestID    string            `json:"request_id,omitempty"`
+	Status       int               `json:"status"`
+}
+
+// fields retorna o registro como campos de log e dados de evento; o instante
+// segue no timestamp do próprio evento ou entrada de log
+func (r Record) fields() map[string]interface{} {
+	fields := map[string]interface{}{
+		"principal": r.Principal,
+		"method":    r.Method,
+		"path":      r.Path,
+		"source_ip": r.SourceIP,
+		"status":    r.Status,
+	}
+	if r.Role != "" {
+		fields["role"] = r.Role
+	}
+	if len(r.Targets) > 0 {
+		fields["targets"] = r.Targets
+	}
+	if len(r.Params) > 0 {
+		fields["params"] = r.Params
+	}
+	if r.ForwardedFor != "" {
+		fields["forwarded_for"] = r.ForwardedFor
+	}
+	return fields
+}
+
+// Log guarda os registros mais recentes em memória (buffer circular) e os entrega
+// aos sinks inscritos. Sinks são chamados de forma síncrona e devem ser rápidos
+type Log struct {
+	mutex    sync.RWMutex
+	records  []Record
+	next     int
+	full     bool
+	handlers []events.Handler
+}
+
+// NewLog cria o log de auditoria; capacity <= 0 usa DefaultCapacity
+func NewLog(capacity int) *Log {
+	if capacity <= 0 {
+		capacity = DefaultCapacity
+	}
+	return &Log{records: make([]Record, capacity)}
+}
+
+// Subscribe registra um sink para os novos registros (ex: LogHandler, events.Webhook.Handle)
+func (l *Log) Subscribe(handler events.Handler) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.handlers = append(l.handlers, handler)
+}
+
+// Record guarda o registro e o entrega aos sinks
+func (l *Log) Record(record Record) {
+	if record.Timestamp.IsZero() {
+		record.Timestamp = time.Now()
+	}
+
+	l.mutex.Lock()
+	l.records[l.next] = record
+	l.next = (l.next + 1) % len(l.records)
+	if l.next == 0 {
+		l.full = true
+	}
+	handlers := make([]events.Handler, len(l.handlers))
+	copy(handlers, l.handlers)
+	l.mutex.Unlock()
+
+	event := events.Event{
+		Type:      EventAdminAction,
+		Timestamp: record.Timestamp.UTC(),
+		RequestID: record.RequestID,
+		Data:      record.fields(),
+	}
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
+
+// Recent retorna até count registros, do mais recente ao mais antigo; principal
+// não vazio filtra pela credencial
+func (l *Log) Recent(count int, principal string) []Record {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	size := l.next
+	if l.full {
+		size = len(l.records)
+	}
+
+	result := make([]Record, 0)
+	for i := 1; i <= size && len(result) < count; i++ {
+		record := l.records[(l.next-i+len(l.records))%len(l.records)]
+		if principal != "" && record.Principal != principal {
+			continue
+		}
+		result = append(result, record)
+	}
+	return result
+}
+
+// LogHandler grava os registros no logger de auditoria (channel=audit), para
+// arquivos ou coletores de log separados do log da aplicação
+func LogHandler(log domain.Logger) events.Handler {
+	return func(event events.Event) {
+		if event.Type != EventAdminAction {
+			return
+		}
+		fields := map[string]interface{}{
+			"channel":    "audit",
+			"event_type": event.Type,
+		}
+		if event.RequestID != "" {
+			fields["request_id"] = event.RequestID
+		}
+		for key, value := range event.Data {
+			fields[key] = value
+		}
+		log.Info("Admin action", fields)
+	}
+}
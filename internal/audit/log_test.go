@@ -0,0 +1,80 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/events"
+	"rate-limiter/internal/logger"
+)
+
+func TestLog_RecentIsBoundedAndNewestFirst(t *testing.T) {
+	// Arrange
+	log := NewLog(3)
+	for i := 0; i < 5; i++ {
+		principal := "ops"
+		if i%2 == 0 {
+			principal = "ci"
+		}
+		log.Record(Record{Principal: principal, Method: "POST", Path: fmt.Sprintf("/admin/reset/%d", i), Status: 200})
+	}
+
+	// Act
+	all := log.Recent(10, "")
+	ci := log.Recent(10, "ci")
+	latest := log.Recent(1, "")
+
+	// Assert: só os 3 mais recentes permanecem, do mais novo ao mais antigo
+	require.Len(t, all, 3)
+	assert.Equal(t, "/admin/reset/4", all[0].Path)
+	assert.Equal(t, "/admin/reset/2", all[2].Path)
+	assert.False(t, all[0].Timestamp.IsZero())
+	require.Len(t, ci, 2)
+	assert.Equal(t, "/admin/reset/4", ci[0].Path)
+	assert.Equal(t, "/admin/reset/2", ci[1].Path)
+	require.Len(t, latest, 1)
+	assert.Empty(t, NewLog(0).Recent(10, ""))
+}
+
+func TestLog_DeliversToSinks(t *testing.T) {
+	// Arrange
+	var output bytes.Buffer
+	var delivered []events.Event
+	log := NewLog(10)
+	log.Subscribe(LogHandler(logger.NewLoggerWithOutput("info", "json", &output)))
+	log.Subscribe(func(event events.Event) { delivered = append(delivered, event) })
+
+	// Act
+	log.Record(Record{
+		Timestamp: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+		Principal: "oncall",
+		Role:      "operator",
+		Method:    "POST",
+		Path:      "/admin/reset",
+		Targets:   []Target{{Type: "ip", Key: "10.0.0.1"}},
+		SourceIP:  "192.168.1.10",
+		RequestID: "req-1",
+		Status:    200,
+	})
+
+	// Assert
+	require.Len(t, delivered, 1)
+	assert.Equal(t, EventAdminAction, delivered[0].Type)
+	assert.Equal(t, "req-1", delivered[0].RequestID)
+	assert.Equal(t, time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), delivered[0].Timestamp)
+	assert.Equal(t, "oncall", delivered[0].Data["principal"])
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(output.Bytes(), &entry))
+	assert.Equal(t, "audit", entry["channel"])
+	assert.Equal(t, "Admin action", entry["message"])
+	assert.Equal(t, "oncall", entry["principal"])
+	assert.Equal(t, "192.168.1.10", entry["source_ip"])
+	assert.Equal(t, float64(200), entry["status"])
+}
@@ -0,0 +1,302 @@
+package benchalgo
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/replay"
+	"rate-limiter/internal/storage"
+)
+
+// Algorithm é a estratégia comparada: os algoritmos de janela do storage e os
+// baldes configurados nas regras (RefillRate e LeakRate)
+type Algorithm string
+
+const (
+	AlgorithmFixedWindow   = Algorithm(storage.AlgorithmFixedWindow)
+	AlgorithmSlidingLog    = Algorithm(storage.AlgorithmSlidingLog)
+	AlgorithmSlidingWindow = Algorithm(storage.AlgorithmSlidingWindow)
+	// AlgorithmTokenBucket usa balde com capacidade Limit reposto a Limit por Window
+	AlgorithmTokenBucket Algorithm = "token_bucket"
+	// AlgorithmLeakyBucket usa fila com capacidade Limit escoada a Limit por Window;
+	// a espera na fila não entra na latência medida
+	AlgorithmLeakyBucket Algorithm = "leaky_bucket"
+)
+
+// Algorithms retorna todas as estratégias comparadas
+func Algorithms() []Algorithm {
+	return []Algorithm{
+		AlgorithmFixedWindow,
+		AlgorithmSlidingLog,
+		AlgorithmSlidingWindow,
+		AlgorithmTokenBucket,
+		AlgorithmLeakyBucket,
+	}
+}
+
+// ParseAlgorithms interpreta uma lista separada por vírgulas; vazia equivale a Algorithms()
+func ParseAlgorithms(value string) ([]Algorithm, error) {
+	if strings.TrimSpace(value) == "" {
+		return Algorithms(), nil
+	}
+
+	var algorithms []Algorithm
+	for _, name := range strings.Split(value, ",") {
+		algorithm := Algorithm(strings.ToLower(strings.TrimSpace(name)))
+		known := false
+		for _, candidate := range Algorithms() {
+			known = known || candidate == algorithm
+		}
+		if !known {
+			return nil, fmt.Errorf("unsupported algorithm: %s", name)
+		}
+		algorithms = append(algorithms, algorithm)
+	}
+	return algorithms, nil
+}
+
+// windowAlgorithm é o algoritmo de janela do storage; os baldes não dependem dele
+func (a Algorithm) windowAlgorithm() storage.Algorithm {
+	switch a {
+	case AlgorithmSlidingLog, AlgorithmSlidingWindow:
+		return storage.Algorithm(a)
+	default:
+		return storage.AlgorithmFixedWindow
+	}
+}
+
+// decide envia a requisição da chave ao storage e informa se ela foi admitida
+func (a Algorithm) decide(ctx context.Context, s domain.RateLimiterStorage, key string, workload Workload) (bool, error) {
+	rate := float64(workload.Limit) / workload.Window.Seconds()
+
+	switch a {
+	case AlgorithmTokenBucket:
+		status, err := s.TakeToken(ctx, key, domain.TokenBucket{Capacity: workload.Limit, RefillRate: rate})
+		if err != nil {
+			return false, err
+		}
+		return !status.IsBlocked, nil
+	case AlgorithmLeakyBucket:
+		status, err := s.Leak(ctx, key, domain.LeakyBucket{Capacity: workload.Limit, LeakRate: rate})
+		if err != nil {
+			return false, err
+		}
+		return !status.IsBlocked, nil
+	default:
+		count, _, err := s.Increment(ctx, key, workload.Limit, workload.Window)
+		if err != nil {
+			return false, err
+		}
+		return count <= workload.Limit, nil
+	}
+}
+
+// Backend cria storages vazios de um tipo para cada execução
+type Backend struct {
+	Type storage.StorageType
+
+	// New cria o storage com o algoritmo de janela informado
+	New func(algorithm storage.Algorithm) (domain.RateLimiterStorage, error)
+}
+
+// Result é a medição de uma combinação de algoritmo e storage. A precisão é
+// medida contra um limitador ideal (log deslizante exato) que recebe as mesmas
+// requisições: OverAdmitted são as admitidas que ele negaria e UnderAdmitted,
+// as negadas que ele admitiria. Requisições com erro contam como negadas
+type Result struct {
+	Storage   storage.StorageType `json:"storage"`
+	Algorithm Algorithm           `json:"algorithm"`
+	Simulated bool                `json:"simulated"` // relógio simulado; false = carga em tempo real
+
+	Requests      int `json:"requests"`
+	Admitted      int `json:"admitted"`
+	IdealAdmitted int `json:"ideal_admitted"`
+	OverAdmitted  int `json:"over_admitted"`
+	UnderAdmitted int `json:"under_admitted"`
+	Errors        int `json:"errors"`
+
+	// PeakRatio é o maior número de admitidas em uma janela deslizante de uma
+	// chave, em relação ao limite (1 = nunca passou do limite)
+	PeakRatio float64 `json:"peak_ratio"`
+
+	LatencyP50 time.Duration `json:"latency_p50_ns"`
+	LatencyP99 time.Duration `json:"latency_p99_ns"`
+	LatencyMax time.Duration `json:"latency_max_ns"`
+
+	// AllocsPerOp e BytesPerKey medem o heap do processo: em storages remotos,
+	// apenas o lado do cliente
+	AllocsPerOp float64 `json:"allocs_per_op"`
+	BytesPerKey int64   `json:"bytes_per_key"` // heap retido por chave ao fim da carga
+}
+
+// Accuracy é a fração de decisões iguais às do limitador ideal
+func (r Result) Accuracy() float64 {
+	if r.Requests == 0 {
+		return 1
+	}
+	return 1 - float64(r.OverAdmitted+r.UnderAdmitted)/float64(r.Requests)
+}
+
+// clockSetter é um storage que aceita relógio simulado (ex: MemoryStorage)
+type clockSetter interface {
+	SetClock(now func() time.Time)
+}
+
+// Run envia a mesma carga a cada combinação de backend e algoritmo, em
+// sequência para não disputarem CPU. Combinações que o storage não suporta
+// são puladas. Storages com relógio simulado rodam a carga instantaneamente;
+// os demais, em tempo real (Workload.Duration por combinação)
+func Run(ctx context.Context, workload Workload, backends []Backend, algorithms []Algorithm) ([]Result, error) {
+	if err := workload.Validate(); err != nil {
+		return nil, err
+	}
+	arrivals := workload.Generate()
+
+	var results []Result
+	for _, backend := range backends {
+		for _, algorithm := range algorithms {
+			if !storage.SupportsAlgorithm(backend.Type, algorithm.windowAlgorithm()) {
+				continue
+			}
+			result, err := runCombination(ctx, workload, arrivals, backend, algorithm)
+			if err != nil {
+				return nil, fmt.Errorf("%s/%s: %w", backend.Type, algorithm, err)
+			}
+			results = append(results, *result)
+		}
+	}
+	return results, nil
+}
+
+// runCombination executa a carga contra um storage novo do backend
+func runCombination(ctx context.Context, workload Workload, arrivals []Arrival, backend Backend, algorithm Algorithm) (*Result, error) {
+	// Alocados antes da medição de memória, que deve refletir só o storage
+	times := make([]time.Time, len(arrivals))
+	admitted := make([]bool, len(arrivals))
+	latencies := make([]time.Duration, len(arrivals))
+	result := &Result{Storage: backend.Type, Algorithm: algorithm, Requests: len(arrivals)}
+
+	var before runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	created, err := backend.New(algorithm.windowAlgorithm())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage: %w", err)
+	}
+	defer created.Close()
+
+	start := time.Now()
+	var clock *replay.Clock
+	if setter, ok := created.(clockSetter); ok {
+		clock = replay.NewClock(start)
+		setter.SetClock(clock.Now)
+		result.Simulated = true
+	}
+	// Prefixo por execução: em storages compartilhados (ex: redis) as chaves de
+	// uma combinação anterior ainda não expiraram
+	isolated := storage.NewPrefixedStorage(created, fmt.Sprintf("benchalgo:%d:", start.UnixNano()))
+
+	for i, arrival := range arrivals {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		at := start.Add(arrival.Offset)
+		if clock != nil {
+			clock.Set(at)
+		} else {
+			time.Sleep(time.Until(at))
+			at = time.Now()
+		}
+		times[i] = at
+
+		begin := time.Now()
+		allowed, err := algorithm.decide(ctx, isolated, arrival.Key, workload)
+		latencies[i] = time.Since(begin)
+		if err != nil {
+			result.Errors++
+			continue
+		}
+		admitted[i] = allowed
+	}
+
+	var after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	runtime.KeepAlive(created)
+
+	if len(arrivals) > 0 {
+		result.AllocsPerOp = float64(after.Mallocs-before.Mallocs) / float64(len(arrivals))
+	}
+	if after.HeapAlloc > before.HeapAlloc {
+		result.BytesPerKey = int64(after.HeapAlloc-before.HeapAlloc) / int64(workload.Keys)
+	}
+	result.LatencyP50, result.LatencyP99, result.LatencyMax = percentiles(latencies)
+	compare(result, workload, arrivals, times, admitted)
+	return result, nil
+}
+
+// compare confronta as decisões com o limitador ideal e calcula o pico por janela
+func compare(result *Result, workload Workload, arrivals []Arrival, times []time.Time, admitted []bool) {
+	ideal := make(map[string][]time.Time)
+	actual := make(map[string][]time.Time)
+	peak := 0
+
+	for i, arrival := range arrivals {
+		cutoff := times[i].Add(-workload.Window)
+
+		idealLog := trim(ideal[arrival.Key], cutoff)
+		idealAllowed := len(idealLog) < workload.Limit
+		if idealAllowed {
+			idealLog = append(idealLog, times[i])
+			result.IdealAdmitted++
+		}
+		ideal[arrival.Key] = idealLog
+
+		actualLog := trim(actual[arrival.Key], cutoff)
+		if admitted[i] {
+			actualLog = append(actualLog, times[i])
+			result.Admitted++
+			peak = max(peak, len(actualLog))
+		}
+		actual[arrival.Key] = actualLog
+
+		switch {
+		case admitted[i] && !idealAllowed:
+			result.OverAdmitted++
+		case !admitted[i] && idealAllowed:
+			result.UnderAdmitted++
+		}
+	}
+
+	result.PeakRatio = float64(peak) / float64(workload.Limit)
+}
+
+// trim descarta os instantes até cutoff (inclusive), como o sliding_log do storage
+func trim(log []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(log) && !log[i].After(cutoff) {
+		i++
+	}
+	return log[i:]
+}
+
+// percentiles retorna p50, p99 e o máximo das latências
+func percentiles(latencies []time.Duration) (p50, p99, maximum time.Duration) {
+	if len(latencies) == 0 {
+		return 0, 0, 0
+	}
+	sorted := make([]time.Duration, len(latencies))
+	copy(sorted, latencies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	at := func(quantile float64) time.Duration {
+		return sorted[int(quantile*float64(len(sorted)-1))]
+	}
+	return at(0.50), at(0.99), sorted[len(sorted)-1]
+}
@@ -0,0 +1,136 @@
+package benchalgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/logger"
+	"rate-limiter/internal/storage"
+)
+
+// memoryBackend cria storages em memória, com relógio simulado
+func memoryBackend(storageType storage.StorageType) Backend {
+	return Backend{
+		Type: storageType,
+		New: func(algorithm storage.Algorithm) (domain.RateLimiterStorage, error) {
+			return storage.NewStorageFactory().CreateStorage(&storage.StorageConfig{
+				Type:      storage.MemoryStorageType,
+				Algorithm: algorithm,
+			}, logger.NewNopLogger())
+		},
+	}
+}
+
+func TestRun_SlidingLogMatchesIdeal(t *testing.T) {
+	for _, pattern := range []Pattern{PatternSteady, PatternBursty, PatternBoundary} {
+		t.Run(string(pattern), func(t *testing.T) {
+			// Act
+			results, err := Run(context.Background(), testWorkload(pattern),
+				[]Backend{memoryBackend(storage.MemoryStorageType)}, []Algorithm{AlgorithmSlidingLog})
+
+			// Assert: o sliding_log é exato, como o limitador ideal
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+			result := results[0]
+			assert.True(t, result.Simulated)
+			assert.Equal(t, result.IdealAdmitted, result.Admitted)
+			assert.Zero(t, result.OverAdmitted)
+			assert.Zero(t, result.UnderAdmitted)
+			assert.Equal(t, 1.0, result.Accuracy())
+			assert.Equal(t, 1.0, result.PeakRatio)
+			assert.Positive(t, result.LatencyP50)
+		})
+	}
+}
+
+func TestRun_FixedWindowOverAdmitsAtBoundary(t *testing.T) {
+	// Act
+	results, err := Run(context.Background(), testWorkload(PatternBoundary),
+		[]Backend{memoryBackend(storage.MemoryStorageType)}, []Algorithm{AlgorithmFixedWindow})
+
+	// Assert: na virada da janela o fixed_window admite perto de 2x o limite
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Greater(t, results[0].OverAdmitted, 0)
+	assert.Greater(t, results[0].PeakRatio, 1.5)
+	assert.Less(t, results[0].Accuracy(), 1.0)
+}
+
+func TestRun_SkipsUnsupportedCombinations(t *testing.T) {
+	// Act: o hybrid só suporta a janela fixa entre os algoritmos de janela
+	results, err := Run(context.Background(), testWorkload(PatternSteady),
+		[]Backend{memoryBackend(storage.HybridStorageType)}, Algorithms())
+
+	// Assert
+	require.NoError(t, err)
+	var algorithms []Algorithm
+	for _, result := range results {
+		algorithms = append(algorithms, result.Algorithm)
+	}
+	assert.Equal(t, []Algorithm{AlgorithmFixedWindow, AlgorithmTokenBucket, AlgorithmLeakyBucket}, algorithms)
+}
+
+func TestRun_RealTime(t *testing.T) {
+	// Arrange: o RedisStorage não aceita relógio simulado
+	server := miniredis.RunT(t)
+	backend := Backend{
+		Type: storage.RedisStorageType,
+		New: func(algorithm storage.Algorithm) (domain.RateLimiterStorage, error) {
+			return storage.NewRedisStorageWithClient(redis.NewClient(&redis.Options{Addr: server.Addr()}), logger.NewNopLogger()), nil
+		},
+	}
+	workload := Workload{Pattern: PatternSteady, Keys: 2, Limit: 5, Window: 100 * time.Millisecond, Duration: 200 * time.Millisecond, Load: 2, Seed: 7}
+
+	// Act
+	start := time.Now()
+	results, err := Run(context.Background(), workload, []Backend{backend}, []Algorithm{AlgorithmFixedWindow})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.False(t, results[0].Simulated)
+	assert.Zero(t, results[0].Errors)
+	assert.Positive(t, results[0].Admitted)
+	assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+}
+
+func TestCompare(t *testing.T) {
+	// Arrange: limite 2 por 10s; o algoritmo admite a 3ª e nega a 4ª, depois da janela
+	workload := Workload{Limit: 2, Window: 10 * time.Second}
+	start := time.Now()
+	arrivals := []Arrival{{Key: "a"}, {Key: "a"}, {Key: "a"}, {Key: "a"}}
+	times := []time.Time{start, start.Add(time.Second), start.Add(2 * time.Second), start.Add(11 * time.Second)}
+	admitted := []bool{true, true, true, false}
+	result := &Result{Requests: len(arrivals)}
+
+	// Act
+	compare(result, workload, arrivals, times, admitted)
+
+	// Assert
+	assert.Equal(t, 3, result.Admitted)
+	assert.Equal(t, 3, result.IdealAdmitted)
+	assert.Equal(t, 1, result.OverAdmitted)
+	assert.Equal(t, 1, result.UnderAdmitted)
+	assert.Equal(t, 0.5, result.Accuracy())
+	assert.Equal(t, 1.5, result.PeakRatio)
+}
+
+func TestParseAlgorithms(t *testing.T) {
+	algorithms, err := ParseAlgorithms(" sliding_log, TOKEN_BUCKET ")
+	require.NoError(t, err)
+	assert.Equal(t, []Algorithm{AlgorithmSlidingLog, AlgorithmTokenBucket}, algorithms)
+
+	all, err := ParseAlgorithms("")
+	require.NoError(t, err)
+	assert.Equal(t, Algorithms(), all)
+
+	_, err = ParseAlgorithms("gcra")
+	assert.Error(t, err)
+}
@@ -0,0 +1,135 @@
+// Package benchalgo compara os algoritmos de rate limiting sob a mesma carga
+// sintética: precisão contra um limitador ideal, latência e memória de cada
+// combinação de algoritmo e storage.
+package benchalgo
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Pattern é a forma da carga gerada para cada chave
+type Pattern string
+
+const (
+	// PatternSteady chega em processo de Poisson à taxa média da carga
+	PatternSteady Pattern = "steady"
+	// PatternBursty concentra o tráfego de duas janelas em rajadas curtas, em metade delas
+	PatternBursty Pattern = "bursty"
+	// PatternBoundary concentra o tráfego de cada janela em torno da virada da
+	// janela fixa, o pior caso para o fixed_window
+	PatternBoundary Pattern = "boundary"
+)
+
+// ParsePattern interpreta o nome da forma de carga; vazio equivale a PatternSteady
+func ParsePattern(value string) (Pattern, error) {
+	switch pattern := Pattern(strings.ToLower(strings.TrimSpace(value))); pattern {
+	case "":
+		return PatternSteady, nil
+	case PatternSteady, PatternBursty, PatternBoundary:
+		return pattern, nil
+	default:
+		return "", fmt.Errorf("unsupported workload pattern: %s", value)
+	}
+}
+
+// Workload descreve a carga sintética; a mesma Seed gera sempre as mesmas chegadas
+type Workload struct {
+	Pattern  Pattern
+	Keys     int           // Chaves (clientes) independentes
+	Limit    int           // Requisições permitidas por janela
+	Window   time.Duration // Janela do limite
+	Duration time.Duration // Duração da carga
+	Load     float64       // Taxa oferecida em relação ao limite (1 = exatamente o limite)
+	Seed     int64
+}
+
+// Validate verifica se a carga pode ser gerada
+func (w Workload) Validate() error {
+	if _, err := ParsePattern(string(w.Pattern)); err != nil {
+		return err
+	}
+	if w.Keys <= 0 || w.Limit <= 0 {
+		return fmt.Errorf("keys and limit must be greater than 0")
+	}
+	if w.Window < time.Millisecond || w.Duration < w.Window {
+		return fmt.Errorf("window must be at least 1ms and duration at least one window")
+	}
+	if w.Load <= 0 {
+		return fmt.Errorf("load must be greater than 0")
+	}
+	return nil
+}
+
+// Arrival é uma requisição da carga, a Offset do início
+type Arrival struct {
+	Offset time.Duration
+	Key    string
+}
+
+// Generate gera as chegadas de todas as chaves, em ordem de Offset
+func (w Workload) Generate() []Arrival {
+	random := rand.New(rand.NewSource(w.Seed))
+	perWindow := w.Load * float64(w.Limit)
+	windows := int(w.Duration / w.Window)
+
+	var arrivals []Arrival
+	for k := 0; k < w.Keys; k++ {
+		key := fmt.Sprintf("key:%d", k)
+		add := func(offset time.Duration) {
+			if offset >= 0 && offset < w.Duration {
+				arrivals = append(arrivals, Arrival{Offset: offset, Key: key})
+			}
+		}
+
+		switch w.Pattern {
+		case PatternBursty:
+			for i := 0; i < windows; i++ {
+				if random.Intn(2) == 0 {
+					continue
+				}
+				start := time.Duration(i)*w.Window + time.Duration(random.Float64()*0.9*float64(w.Window))
+				for n := poisson(random, 2*perWindow); n > 0; n-- {
+					add(start + time.Duration(random.Float64()*0.1*float64(w.Window)))
+				}
+			}
+		case PatternBoundary:
+			// A primeira chegada abre a janela fixa em 0; as demais cercam cada virada
+			add(0)
+			for i := 1; i <= windows; i++ {
+				boundary := time.Duration(i) * w.Window
+				for n := poisson(random, perWindow); n > 0; n-- {
+					add(boundary + time.Duration((random.Float64()-0.5)*0.1*float64(w.Window)))
+				}
+			}
+		default:
+			rate := perWindow / float64(w.Window)
+			for offset := time.Duration(random.ExpFloat64() / rate); offset < w.Duration; offset += time.Duration(random.ExpFloat64() / rate) {
+				add(offset)
+			}
+		}
+	}
+
+	sort.SliceStable(arrivals, func(i, j int) bool {
+		return arrivals[i].Offset < arrivals[j].Offset
+	})
+	return arrivals
+}
+
+// poisson sorteia uma contagem com média mean (Knuth para médias pequenas,
+// aproximação normal para as grandes)
+func poisson(random *rand.Rand, mean float64) int {
+	if mean > 30 {
+		return max(0, int(math.Round(mean+random.NormFloat64()*math.Sqrt(mean))))
+	}
+	limit, product, count := math.Exp(-mean), random.Float64(), 0
+	for product > limit {
+		product *= random.Float64()
+		count++
+	}
+	return count
+}
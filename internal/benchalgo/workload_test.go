@@ -0,0 +1,78 @@
+package benchalgo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testWorkload(pattern Pattern) Workload {
+	return Workload{
+		Pattern:  pattern,
+		Keys:     20,
+		Limit:    10,
+		Window:   10 * time.Second,
+		Duration: 5 * time.Minute,
+		Load:     1.5,
+		Seed:     42,
+	}
+}
+
+func TestWorkload_Generate(t *testing.T) {
+	for _, pattern := range []Pattern{PatternSteady, PatternBursty, PatternBoundary} {
+		t.Run(string(pattern), func(t *testing.T) {
+			// Arrange
+			workload := testWorkload(pattern)
+
+			// Act
+			arrivals := workload.Generate()
+
+			// Assert: mesma semente, mesmas chegadas, em ordem e dentro da duração
+			require.NotEmpty(t, arrivals)
+			assert.Equal(t, arrivals, workload.Generate())
+			for i, arrival := range arrivals {
+				assert.GreaterOrEqual(t, arrival.Offset, time.Duration(0))
+				assert.Less(t, arrival.Offset, workload.Duration)
+				if i > 0 {
+					assert.LessOrEqual(t, arrivals[i-1].Offset, arrival.Offset)
+				}
+			}
+
+			// A taxa média acompanha Load (30 janelas, 20 chaves, 15 por janela)
+			expected := 30 * 20 * 15.0
+			assert.InDelta(t, expected, float64(len(arrivals)), expected*0.1)
+		})
+	}
+}
+
+func TestWorkload_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(w *Workload)
+		valid  bool
+	}{
+		{"valid", func(w *Workload) {}, true},
+		{"unknown pattern", func(w *Workload) { w.Pattern = "spiky" }, false},
+		{"no keys", func(w *Workload) { w.Keys = 0 }, false},
+		{"no limit", func(w *Workload) { w.Limit = 0 }, false},
+		{"duration shorter than window", func(w *Workload) { w.Duration = time.Second }, false},
+		{"no load", func(w *Workload) { w.Load = 0 }, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workload := testWorkload(PatternSteady)
+			tt.modify(&workload)
+
+			err := workload.Validate()
+
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,206 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"rate-limiter/internal/domain"
+)
+
+// DefaultMaxEntries limita o tamanho do cache (tokens aleatórios não crescem sem limite)
+const DefaultMaxEntries = 100000
+
+// entry é um resultado em cache (positivo ou negativo)
+type entry struct {
+	config    domain.TokenConfig
+	found     bool
+	expiresAt time.Time
+}
+
+// lruItem é o valor de cada elemento da lista de recência
+type lruItem struct {
+	token string
+	entry entry
+}
+
+// call é uma consulta à fonte em andamento, compartilhada por todos que a aguardam
+type call struct {
+	done        chan struct{}
+	result      entry
+	err         error
+	invalidated bool // Invalidate durante a consulta: o resultado não vai para o cache
+}
+
+// TokenConfigCache é um cache read-through com TTL para configurações de token
+// Tokens inexistentes também ficam em cache (negative caching) com TTL próprio.
+// Entradas expiradas continuam sendo servidas enquanto a atualização ocorre em
+// background, de forma que apenas o primeiro acesso a um token consulta a fonte.
+// Acessos simultâneos ao mesmo token compartilham uma única consulta e, com o cache
+// cheio, o token usado há mais tempo é descartado (LRU)
+type TokenConfigCache struct {
+	fetcher      domain.TokenConfigFetcher
+	ttl          time.Duration
+	negativeTTL  time.Duration
+	fetchTimeout time.Duration
+	maxEntries   int
+	logger       domain.Logger
+
+	mutex    sync.Mutex
+	entries  map[string]*list.Element // token -> elemento de recency
+	recency  *list.List               // mais recente na frente
+	inflight map[string]*call
+
+	now func() time.Time
+}
+
+// NewTokenConfigCache cria o cache sobre uma fonte remota
+func NewTokenConfigCache(fetcher domain.TokenConfigFetcher, ttl, negativeTTL time.Duration, logger domain.Logger) *TokenConfigCache {
+	if ttl <= 0 {
+		ttl = time.Minute
+	}
+	if negativeTTL <= 0 {
+		negativeTTL = ttl
+	}
+
+	return &TokenConfigCache{
+		fetcher:      fetcher,
+		ttl:          ttl,
+		negativeTTL:  negativeTTL,
+		fetchTimeout: 2 * time.Second,
+		maxEntries:   DefaultMaxEntries,
+		logger:       logger,
+		entries:      make(map[string]*list.Element),
+		recency:      list.New(),
+		inflight:     make(map[string]*call),
+		now:          time.Now,
+	}
+}
+
+// TokenConfig implementa domain.TokenConfigProvider
+// Só retorna erro no primeiro acesso a um token quando a fonte falha
+func (c *TokenConfigCache) TokenConfig(ctx context.Context, token string) (domain.TokenConfig, bool, error) {
+	c.mutex.Lock()
+	if element, exists := c.entries[token]; exists {
+		c.recency.MoveToFront(element)
+		cached := element.Value.(*lruItem).entry
+		// Expirada: serve o valor antigo e atualiza em background
+		if !c.now().Before(cached.expiresAt) {
+			c.startFetchLocked(token)
+		}
+		c.mutex.Unlock()
+		return cached.config, cached.found, nil
+	}
+
+	// Primeiro acesso: aguarda a consulta (própria ou de outro acesso ao mesmo token)
+	pending := c.startFetchLocked(token)
+	c.mutex.Unlock()
+
+	select {
+	case <-pending.done:
+	case <-ctx.Done():
+		return domain.TokenConfig{}, false, fmt.Errorf("failed to fetch token config: %w", ctx.Err())
+	}
+	if pending.err != nil {
+		return domain.TokenConfig{}, false, pending.err
+	}
+	return pending.result.config, pending.result.found, nil
+}
+
+// Invalidate remove um token do cache
+// Uma consulta em andamento pode ter lido a configuração antiga: o resultado dela
+// é descartado e o próximo acesso consulta a fonte de novo
+func (c *TokenConfigCache) Invalidate(token string) {
+	c.mutex.Lock()
+	if element, exists := c.entries[token]; exists {
+		c.recency.Remove(element)
+		delete(c.entries, token)
+	}
+	if pending, inFlight := c.inflight[token]; inFlight {
+		pending.invalidated = true
+		delete(c.inflight, token)
+	}
+	c.mutex.Unlock()
+}
+
+// Len retorna o número de entradas em cache
+func (c *TokenConfigCache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.entries)
+}
+
+// startFetchLocked inicia a consulta do token em background, uma por vez por token,
+// e retorna a consulta em andamento (requer o lock)
+// O timeout não depende do contexto de quem iniciou: os demais podem estar aguardando
+func (c *TokenConfigCache) startFetchLocked(token string) *call {
+	if pending, inFlight := c.inflight[token]; inFlight {
+		return pending
+	}
+
+	pending := &call{done: make(chan struct{})}
+	c.inflight[token] = pending
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), c.fetchTimeout)
+		defer cancel()
+
+		pending.result, pending.err = c.fetch(ctx, token, pending)
+
+		c.mutex.Lock()
+		if c.inflight[token] == pending {
+			delete(c.inflight, token)
+		}
+		c.mutex.Unlock()
+		close(pending.done)
+
+		if pending.err != nil && c.logger != nil {
+			c.logger.Warn("Failed to fetch token config", map[string]interface{}{
+				"error": pending.err.Error(),
+			})
+		}
+	}()
+
+	return pending
+}
+
+// fetch consulta a fonte e grava o resultado no cache
+// Em caso de erro o cache não é alterado (valores antigos continuam válidos), nem
+// quando o token foi invalidado durante a consulta
+func (c *TokenConfigCache) fetch(ctx context.Context, token string, pending *call) (entry, error) {
+	config, found, err := c.fetcher.FetchTokenConfig(ctx, token)
+	if err != nil {
+		return entry{}, fmt.Errorf("failed to fetch token config: %w", err)
+	}
+
+	ttl := c.ttl
+	if !found {
+		ttl = c.negativeTTL
+	}
+
+	result := entry{config: config, found: found, expiresAt: c.now().Add(ttl)}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if pending.invalidated {
+		return result, nil
+	}
+
+	if element, exists := c.entries[token]; exists {
+		element.Value.(*lruItem).entry = result
+		c.recency.MoveToFront(element)
+		return result, nil
+	}
+
+	// Cache cheio: descarta o token usado há mais tempo
+	for len(c.entries) >= c.maxEntries {
+		oldest := c.recency.Back()
+		c.recency.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruItem).token)
+	}
+	c.entries[token] = c.recency.PushFront(&lruItem{token: token, entry: result})
+
+	return result, nil
+}
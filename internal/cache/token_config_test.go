@@ -0,0 +1,262 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/domain"
+)
+
+// fakeFetcher conta as consultas à fonte remota
+type fakeFetcher struct {
+	mutex   sync.Mutex
+	calls   int
+	configs map[string]domain.TokenConfig
+	err     error
+}
+
+func (f *fakeFetcher) FetchTokenConfig(ctx context.Context, token string) (domain.TokenConfig, bool, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.calls++
+	if f.err != nil {
+		return domain.TokenConfig{}, false, f.err
+	}
+	config, found := f.configs[token]
+	return config, found, nil
+}
+
+func (f *fakeFetcher) callCount() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.calls
+}
+
+func (f *fakeFetcher) setLimit(token string, limit int) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.configs[token] = domain.TokenConfig{Token: token, Limit: limit}
+}
+
+func newFakeFetcher() *fakeFetcher {
+	return &fakeFetcher{configs: map[string]domain.TokenConfig{
+		"premium": {Token: "premium", Limit: 1000},
+	}}
+}
+
+func TestTokenConfigCache_HitDoesNotRefetch(t *testing.T) {
+	// Arrange
+	fetcher := newFakeFetcher()
+	cache := NewTokenConfigCache(fetcher, time.Minute, 0, nil)
+
+	// Act
+	first, found, _ := cache.TokenConfig(context.Background(), "premium")
+	second, _, _ := cache.TokenConfig(context.Background(), "premium")
+
+	// Assert
+	assert.True(t, found)
+	assert.Equal(t, 1000, first.Limit)
+	assert.Equal(t, first, second)
+	assert.Equal(t, 1, fetcher.callCount())
+}
+
+func TestTokenConfigCache_NegativeCaching(t *testing.T) {
+	// Arrange
+	fetcher := newFakeFetcher()
+	cache := NewTokenConfigCache(fetcher, time.Minute, 10*time.Second, nil)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	// Act
+	_, found, _ := cache.TokenConfig(context.Background(), "unknown")
+	_, foundAgain, _ := cache.TokenConfig(context.Background(), "unknown")
+
+	// Assert
+	assert.False(t, found)
+	assert.False(t, foundAgain)
+	assert.Equal(t, 1, fetcher.callCount())
+	assert.Equal(t, 1, cache.Len())
+}
+
+func TestTokenConfigCache_ServesStaleWhileRefreshing(t *testing.T) {
+	// Arrange
+	fetcher := newFakeFetcher()
+	cache := NewTokenConfigCache(fetcher, time.Minute, 0, nil)
+	now := time.Now()
+	var nowMutex sync.Mutex
+	cache.now = func() time.Time {
+		nowMutex.Lock()
+		defer nowMutex.Unlock()
+		return now
+	}
+	cache.TokenConfig(context.Background(), "premium")
+
+	fetcher.setLimit("premium", 2000)
+	nowMutex.Lock()
+	now = now.Add(2 * time.Minute)
+	nowMutex.Unlock()
+
+	// Act
+	stale, found, _ := cache.TokenConfig(context.Background(), "premium")
+
+	// Assert
+	assert.True(t, found)
+	assert.Equal(t, 1000, stale.Limit)
+	require.Eventually(t, func() bool {
+		config, _, _ := cache.TokenConfig(context.Background(), "premium")
+		return config.Limit == 2000
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestTokenConfigCache_ErrorsAreNotCached(t *testing.T) {
+	// Arrange
+	fetcher := newFakeFetcher()
+	fetcher.err = errors.New("database unavailable")
+	cache := NewTokenConfigCache(fetcher, time.Minute, 0, nil)
+
+	// Act
+	_, found, err := cache.TokenConfig(context.Background(), "premium")
+	fetcher.mutex.Lock()
+	fetcher.err = nil
+	fetcher.mutex.Unlock()
+	config, foundAfterRecovery, recoveryErr := cache.TokenConfig(context.Background(), "premium")
+
+	// Assert
+	assert.ErrorContains(t, err, "database unavailable")
+	assert.False(t, found)
+	assert.NoError(t, recoveryErr)
+	assert.True(t, foundAfterRecovery)
+	assert.Equal(t, 1000, config.Limit)
+	assert.Equal(t, 2, fetcher.callCount())
+}
+
+func TestTokenConfigCache_Invalidate(t *testing.T) {
+	// Arrange
+	fetcher := newFakeFetcher()
+	cache := NewTokenConfigCache(fetcher, time.Minute, 0, nil)
+	cache.TokenConfig(context.Background(), "premium")
+
+	// Act
+	cache.Invalidate("premium")
+	cache.TokenConfig(context.Background(), "premium")
+
+	// Assert
+	assert.Equal(t, 2, fetcher.callCount())
+}
+
+func TestTokenConfigCache_InvalidateDuringFetch(t *testing.T) {
+	// Arrange - a consulta começa antes da mudança na fonte e termina depois
+	fetcher := &blockingFetcher{fakeFetcher: newFakeFetcher(), release: make(chan struct{})}
+	cache := NewTokenConfigCache(fetcher, time.Minute, 0, nil)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		cache.TokenConfig(context.Background(), "premium")
+	}()
+	require.Eventually(t, func() bool {
+		cache.mutex.Lock()
+		defer cache.mutex.Unlock()
+		return len(cache.inflight) == 1
+	}, time.Second, time.Millisecond)
+
+	// Act
+	cache.Invalidate("premium")
+	close(fetcher.release)
+	<-done
+
+	// Assert - o resultado da consulta invalidada não fica em cache
+	assert.Equal(t, 0, cache.Len())
+	fetcher.setLimit("premium", 5000)
+	config, _, err := cache.TokenConfig(context.Background(), "premium")
+	require.NoError(t, err)
+	assert.Equal(t, 2, fetcher.callCount())
+	assert.Equal(t, 5000, config.Limit)
+}
+
+func TestTokenConfigCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	// Arrange
+	fetcher := newFakeFetcher()
+	cache := NewTokenConfigCache(fetcher, time.Minute, 0, nil)
+	cache.maxEntries = 2
+	cache.TokenConfig(context.Background(), "premium")
+	cache.TokenConfig(context.Background(), "unknown-a")
+	cache.TokenConfig(context.Background(), "premium")
+
+	// Act - cache cheio: o novo token entra no lugar do usado há mais tempo
+	cache.TokenConfig(context.Background(), "unknown-b")
+	cache.TokenConfig(context.Background(), "premium")
+	cache.TokenConfig(context.Background(), "unknown-b")
+
+	// Assert
+	assert.Equal(t, 2, cache.Len())
+	assert.Equal(t, 3, fetcher.callCount())
+
+	cache.TokenConfig(context.Background(), "unknown-a")
+	assert.Equal(t, 4, fetcher.callCount())
+}
+
+// blockingFetcher segura as consultas até release ser fechado
+type blockingFetcher struct {
+	*fakeFetcher
+	release chan struct{}
+}
+
+func (f *blockingFetcher) FetchTokenConfig(ctx context.Context, token string) (domain.TokenConfig, bool, error) {
+	<-f.release
+	return f.fakeFetcher.FetchTokenConfig(ctx, token)
+}
+
+func TestTokenConfigCache_ConcurrentMissesShareOneFetch(t *testing.T) {
+	// Arrange
+	fetcher := &blockingFetcher{fakeFetcher: newFakeFetcher(), release: make(chan struct{})}
+	cache := NewTokenConfigCache(fetcher, time.Minute, 0, nil)
+
+	// Act
+	var wg sync.WaitGroup
+	limits := make([]int, 10)
+	for i := range limits {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			config, _, err := cache.TokenConfig(context.Background(), "premium")
+			assert.NoError(t, err)
+			limits[i] = config.Limit
+		}(i)
+	}
+	require.Eventually(t, func() bool {
+		cache.mutex.Lock()
+		defer cache.mutex.Unlock()
+		return len(cache.inflight) == 1
+	}, time.Second, time.Millisecond)
+	time.Sleep(10 * time.Millisecond)
+	close(fetcher.release)
+	wg.Wait()
+
+	// Assert
+	assert.Equal(t, 1, fetcher.callCount())
+	for _, limit := range limits {
+		assert.Equal(t, 1000, limit)
+	}
+}
+
+func TestTokenConfigCache_WaiterHonoursOwnContext(t *testing.T) {
+	// Arrange
+	fetcher := &blockingFetcher{fakeFetcher: newFakeFetcher(), release: make(chan struct{})}
+	defer close(fetcher.release)
+	cache := NewTokenConfigCache(fetcher, time.Minute, 0, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// Act
+	_, found, err := cache.TokenConfig(ctx, "premium")
+
+	// Assert
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.False(t, found)
+}
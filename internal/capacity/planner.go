@@ -0,0 +1,337 @@
+package capacity
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"rate-limiter/internal/domain"
+)
+
+// DefaultMaxKeys limita as chaves acompanhadas por janela
+const DefaultMaxKeys = 10000
+
+// wildcardKey identifica a regra padrão de um tipo (ex: "ip:*")
+const wildcardKey = "*"
+
+// Config define o acompanhamento
+type Config struct {
+	Interval time.Duration // Duração de cada janela; zero usa a janela da configuração ativa
+	MaxKeys  int           // Chaves acima disso na mesma janela são descartadas
+}
+
+// counter acumula as requisições de uma chave na janela atual
+type counter struct {
+	limiterType domain.LimiterType
+	key         string
+	current     atomic.Int64
+}
+
+// histogram conta quantas vezes cada total por chave e janela apareceu
+type histogram struct {
+	frequency  map[int64]uint64
+	requests   uint64
+	keyWindows uint64
+}
+
+// RuleReport é a análise de uma regra
+type RuleReport struct {
+	Rule           string  // "ip:*", "token:*" ou "token:<token>"
+	CurrentLimit   int     // Limite da regra na configuração ativa
+	Requests       uint64  // Requisições observadas (permitidas ou não)
+	KeyWindows     uint64  // Pares chave/janela com tráfego
+	RejectPercent  float64 // Requisições acima do limite atual, em %
+	SuggestedLimit int     // Menor limite que mantém as rejeições abaixo do alvo
+	P50            int64   // Percentis das requisições por chave e janela
+	P95            int64
+	P99            int64
+	Max            int64
+}
+
+// Report é o relatório de capacidade
+type Report struct {
+	TargetPercent   float64
+	Interval        time.Duration
+	Windows         uint64 // Janelas fechadas desde o início ou o último reset
+	DroppedRequests uint64 // Requisições de chaves acima de MaxKeys, fora da análise
+	Since           time.Time
+	Rules           []RuleReport
+}
+
+// Planner acumula, por regra, quantas requisições cada chave faz por janela,
+// para estimar a taxa de rejeição de limites candidatos.
+// Implementa domain.TrafficObserver: o custo por requisição é um incremento atômico
+type Planner struct {
+	source   domain.ConfigSource
+	interval time.Duration
+	maxKeys  int
+
+	mutex   sync.RWMutex
+	current map[string]*counter
+
+	statsMutex sync.Mutex
+	rules      map[string]*histogram
+	windows    uint64
+	since      time.Time
+	dropped    atomic.Uint64
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+	started  atomic.Bool
+}
+
+// NewPlanner cria o planejador sobre a configuração ativa
+func NewPlanner(config Config, source domain.ConfigSource) *Planner {
+	if config.Interval <= 0 {
+		config.Interval = time.Duration(source.ActiveConfig().Window) * time.Second
+	}
+	if config.MaxKeys <= 0 {
+		config.MaxKeys = DefaultMaxKeys
+	}
+
+	return &Planner{
+		source:   source,
+		interval: config.Interval,
+		maxKeys:  config.MaxKeys,
+		current:  make(map[string]*counter),
+		rules:    make(map[string]*histogram),
+		since:    time.Now(),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// ObserveRequest implementa domain.TrafficObserver
+func (p *Planner) ObserveRequest(key string, limiterType domain.LimiterType) {
+	id := string(limiterType) + ":" + key
+
+	p.mutex.RLock()
+	tracked, exists := p.current[id]
+	p.mutex.RUnlock()
+
+	if !exists {
+		p.mutex.Lock()
+		tracked, exists = p.current[id]
+		if !exists {
+			if len(p.current) >= p.maxKeys {
+				p.mutex.Unlock()
+				p.dropped.Add(1)
+				return
+			}
+			tracked = &counter{limiterType: limiterType, key: key}
+			p.current[id] = tracked
+		}
+		p.mutex.Unlock()
+	}
+
+	tracked.current.Add(1)
+}
+
+// Start inicia o fechamento periódico das janelas
+func (p *Planner) Start() {
+	if p.started.CompareAndSwap(false, true) {
+		go p.run()
+	}
+}
+
+// Stop encerra o planejador
+func (p *Planner) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+	})
+	if p.started.Load() {
+		<-p.done
+	}
+}
+
+func (p *Planner) run() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.Tick()
+		}
+	}
+}
+
+// Tick fecha a janela atual e acumula o total de cada chave na sua regra
+func (p *Planner) Tick() {
+	cfg := p.source.ActiveConfig()
+	totals := make(map[string][]int64)
+
+	p.mutex.Lock()
+	for id, tracked := range p.current {
+		count := tracked.current.Swap(0)
+		if count == 0 {
+			// Chave ociosa durante a janela inteira sai do acompanhamento
+			delete(p.current, id)
+			continue
+		}
+		rule := ruleID(cfg, tracked.key, tracked.limiterType)
+		totals[rule] = append(totals[rule], count)
+	}
+	p.mutex.Unlock()
+
+	p.statsMutex.Lock()
+	defer p.statsMutex.Unlock()
+
+	p.windows++
+	for rule, counts := range totals {
+		stats, exists := p.rules[rule]
+		if !exists {
+			stats = &histogram{frequency: make(map[int64]uint64)}
+			p.rules[rule] = stats
+		}
+		for _, count := range counts {
+			stats.frequency[count]++
+			stats.requests += uint64(count)
+			stats.keyWindows++
+		}
+	}
+}
+
+// Reset descarta o histórico acumulado
+func (p *Planner) Reset() {
+	p.statsMutex.Lock()
+	defer p.statsMutex.Unlock()
+
+	p.rules = make(map[string]*histogram)
+	p.windows = 0
+	p.since = time.Now()
+	p.dropped.Store(0)
+}
+
+// Report sugere, por regra, o menor limite que mantém as rejeições em até targetPercent
+func (p *Planner) Report(targetPercent float64) Report {
+	cfg := p.source.ActiveConfig()
+
+	p.statsMutex.Lock()
+	defer p.statsMutex.Unlock()
+
+	report := Report{
+		TargetPercent:   targetPercent,
+		Interval:        p.interval,
+		Windows:         p.windows,
+		DroppedRequests: p.dropped.Load(),
+		Since:           p.since,
+		Rules:           make([]RuleReport, 0, len(p.rules)),
+	}
+
+	for rule, stats := range p.rules {
+		counts := sortedCounts(stats.frequency)
+		currentLimit := ruleLimit(cfg, rule)
+
+		ruleReport := RuleReport{
+			Rule:           rule,
+			CurrentLimit:   currentLimit,
+			Requests:       stats.requests,
+			KeyWindows:     stats.keyWindows,
+			RejectPercent:  rejectPercent(stats, counts, int64(currentLimit)),
+			SuggestedLimit: suggestLimit(stats, counts, targetPercent),
+			P50:            percentile(stats, counts, 0.50),
+			P95:            percentile(stats, counts, 0.95),
+			P99:            percentile(stats, counts, 0.99),
+		}
+		if len(counts) > 0 {
+			ruleReport.Max = counts[len(counts)-1]
+		}
+		report.Rules = append(report.Rules, ruleReport)
+	}
+
+	sort.Slice(report.Rules, func(i, j int) bool {
+		return report.Rules[i].Rule < report.Rules[j].Rule
+	})
+	return report
+}
+
+// ruleID identifica a regra que se aplica à chave na configuração ativa
+func ruleID(cfg *domain.RateLimitConfig, key string, limiterType domain.LimiterType) string {
+	if limiterType == domain.TokenLimiter {
+		if _, exists := cfg.TokenConfigs[key]; exists {
+			return string(limiterType) + ":" + key
+		}
+	}
+	return string(limiterType) + ":" + wildcardKey
+}
+
+// ruleLimit retorna o limite configurado da regra (0 se ela não existe mais)
+func ruleLimit(cfg *domain.RateLimitConfig, rule string) int {
+	switch rule {
+	case string(domain.IPLimiter) + ":" + wildcardKey:
+		return cfg.DefaultIPLimit
+	case string(domain.TokenLimiter) + ":" + wildcardKey:
+		return cfg.DefaultTokenLimit
+	}
+	token := rule[len(domain.TokenLimiter)+1:]
+	return cfg.TokenConfigs[token].Limit
+}
+
+func sortedCounts(frequency map[int64]uint64) []int64 {
+	counts := make([]int64, 0, len(frequency))
+	for count := range frequency {
+		counts = append(counts, count)
+	}
+	sort.Slice(counts, func(i, j int) bool { return counts[i] < counts[j] })
+	return counts
+}
+
+// excess conta as requisições acima do limite em cada janela de cada chave
+func excess(stats *histogram, counts []int64, limit int64) uint64 {
+	var total uint64
+	for i := len(counts) - 1; i >= 0 && counts[i] > limit; i-- {
+		total += uint64(counts[i]-limit) * stats.frequency[counts[i]]
+	}
+	return total
+}
+
+func rejectPercent(stats *histogram, counts []int64, limit int64) float64 {
+	if stats.requests == 0 || limit <= 0 {
+		return 0
+	}
+	return float64(excess(stats, counts, limit)) * 100 / float64(stats.requests)
+}
+
+// suggestLimit busca o menor limite cujas rejeições ficam dentro do alvo
+func suggestLimit(stats *histogram, counts []int64, targetPercent float64) int {
+	if len(counts) == 0 {
+		return 0
+	}
+
+	allowed := uint64(math.Floor(float64(stats.requests) * targetPercent / 100))
+	low, high := int64(1), counts[len(counts)-1]
+	for low < high {
+		mid := low + (high-low)/2
+		if excess(stats, counts, mid) <= allowed {
+			high = mid
+		} else {
+			low = mid + 1
+		}
+	}
+	return int(low)
+}
+
+// percentile retorna o total por chave e janela no percentil informado
+func percentile(stats *histogram, counts []int64, quantile float64) int64 {
+	if stats.keyWindows == 0 {
+		return 0
+	}
+
+	rank := uint64(math.Ceil(quantile * float64(stats.keyWindows)))
+	var seen uint64
+	for _, count := range counts {
+		seen += stats.frequency[count]
+		if seen >= rank {
+			return count
+		}
+	}
+	return counts[len(counts)-1]
+}
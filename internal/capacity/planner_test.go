@@ -0,0 +1,98 @@
+package capacity
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/domain"
+)
+
+// staticConfig é uma fonte de configuração fixa
+type staticConfig struct {
+	config *domain.RateLimitConfig
+}
+
+func (s staticConfig) ActiveConfig() *domain.RateLimitConfig { return s.config }
+
+func newTestPlanner() *Planner {
+	return NewPlanner(Config{}, staticConfig{&domain.RateLimitConfig{
+		DefaultIPLimit:    10,
+		DefaultTokenLimit: 100,
+		Window:            60,
+		TokenConfigs:      map[string]domain.TokenConfig{"premium_token": {Token: "premium_token", Limit: 1000}},
+	}})
+}
+
+// observe simula n requisições de uma chave na janela atual
+func observe(planner *Planner, key string, limiterType domain.LimiterType, n int) {
+	for i := 0; i < n; i++ {
+		planner.ObserveRequest(key, limiterType)
+	}
+}
+
+func TestPlanner_SuggestsLimitWithinTarget(t *testing.T) {
+	// Arrange: 99 IPs com 5 req por janela e um IP com 50
+	planner := newTestPlanner()
+	for i := 0; i < 99; i++ {
+		observe(planner, fmt.Sprintf("10.0.0.%d", i), domain.IPLimiter, 5)
+	}
+	observe(planner, "10.0.1.1", domain.IPLimiter, 50)
+	observe(planner, "premium_token", domain.TokenLimiter, 20)
+	observe(planner, "unknown_token", domain.TokenLimiter, 7)
+	planner.Tick()
+
+	// Act
+	report := planner.Report(1)
+
+	// Assert
+	require.Len(t, report.Rules, 3)
+	assert.Equal(t, uint64(1), report.Windows)
+	assert.Equal(t, []string{"ip:*", "token:*", "token:premium_token"},
+		[]string{report.Rules[0].Rule, report.Rules[1].Rule, report.Rules[2].Rule})
+
+	ip := report.Rules[0]
+	assert.Equal(t, 10, ip.CurrentLimit)
+	assert.Equal(t, uint64(545), ip.Requests)
+	assert.Equal(t, uint64(100), ip.KeyWindows)
+	// 40 das 545 requisições ficam acima do limite atual
+	assert.InDelta(t, 40*100.0/545, ip.RejectPercent, 0.001)
+	// Até 5 rejeições (1% de 545): o IP pesado precisa de limite 45
+	assert.Equal(t, 45, ip.SuggestedLimit)
+	assert.Equal(t, int64(5), ip.P50)
+	assert.Equal(t, int64(5), ip.P99)
+	assert.Equal(t, int64(50), ip.Max)
+
+	assert.Equal(t, 1000, report.Rules[2].CurrentLimit)
+	assert.Equal(t, 0.0, report.Rules[2].RejectPercent)
+}
+
+func TestPlanner_AccumulatesWindowsAndResets(t *testing.T) {
+	planner := newTestPlanner()
+	observe(planner, "10.0.0.1", domain.IPLimiter, 8)
+	planner.Tick()
+	observe(planner, "10.0.0.1", domain.IPLimiter, 12)
+	planner.Tick()
+	planner.Tick() // janela ociosa: a chave sai do acompanhamento
+
+	report := planner.Report(0)
+	require.Len(t, report.Rules, 1)
+	assert.Equal(t, uint64(3), report.Windows)
+	assert.Equal(t, uint64(2), report.Rules[0].KeyWindows)
+	assert.Equal(t, 12, report.Rules[0].SuggestedLimit)
+	assert.Empty(t, planner.current)
+
+	planner.Reset()
+	assert.Empty(t, planner.Report(1).Rules)
+}
+
+func TestPlanner_DropsKeysAboveMax(t *testing.T) {
+	planner := NewPlanner(Config{MaxKeys: 2}, staticConfig{&domain.RateLimitConfig{DefaultIPLimit: 10, Window: 60}})
+	observe(planner, "a", domain.IPLimiter, 1)
+	observe(planner, "b", domain.IPLimiter, 1)
+	observe(planner, "c", domain.IPLimiter, 3)
+
+	assert.Equal(t, uint64(3), planner.Report(1).DroppedRequests)
+}
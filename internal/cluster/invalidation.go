@@ -0,0 +1,143 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/go-redis/redis/v8"
+
+	"rate-limiter/internal/domain"
+)
+
+// DefaultInvalidationChannel é o canal pub/sub das invalidações no Redis
+const DefaultInvalidationChannel = "rate_limiter:invalidations"
+
+// handlerSet guarda os handlers de invalidação inscritos
+type handlerSet struct {
+	mutex    sync.RWMutex
+	handlers []func(domain.Invalidation)
+}
+
+func (h *handlerSet) Subscribe(handler func(domain.Invalidation)) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.handlers = append(h.handlers, handler)
+}
+
+func (h *handlerSet) deliver(invalidation domain.Invalidation) {
+	h.mutex.RLock()
+	handlers := make([]func(domain.Invalidation), len(h.handlers))
+	copy(handlers, h.handlers)
+	h.mutex.RUnlock()
+
+	for _, handler := range handlers {
+		handler(invalidation)
+	}
+}
+
+// LocalInvalidationBus entrega as invalidações apenas nesta instância
+// Usado quando não há Redis: não existe outra instância com quem compartilhar estado
+type LocalInvalidationBus struct {
+	handlerSet
+	origin string
+}
+
+// NewLocalInvalidationBus cria o barramento em processo
+func NewLocalInvalidationBus(origin string) *LocalInvalidationBus {
+	return &LocalInvalidationBus{origin: origin}
+}
+
+// Broadcast entrega a invalidação aos handlers de forma síncrona
+func (b *LocalInvalidationBus) Broadcast(ctx context.Context, invalidation domain.Invalidation) error {
+	invalidation.Origin = b.origin
+	b.deliver(invalidation)
+	return nil
+}
+
+// Stop não tem efeito: não há inscrição a encerrar
+func (b *LocalInvalidationBus) Stop() {}
+
+// RedisInvalidationBus propaga invalidações para todas as réplicas via Redis pub/sub
+// A entrega é best-effort: uma réplica desconectada no momento perde a mensagem
+// e depende das expirações normais para convergir
+type RedisInvalidationBus struct {
+	handlerSet
+	client  redis.UniversalClient
+	channel string
+	origin  string
+	logger  domain.Logger
+
+	pubsub   *redis.PubSub
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// NewRedisInvalidationBus cria o barramento sobre um cliente Redis
+func NewRedisInvalidationBus(client redis.UniversalClient, origin string, logger domain.Logger) *RedisInvalidationBus {
+	return &RedisInvalidationBus{
+		client:  client,
+		channel: DefaultInvalidationChannel,
+		origin:  origin,
+		logger:  logger,
+		done:    make(chan struct{}),
+	}
+}
+
+// Start inscreve a instância no canal e entrega as mensagens recebidas (inclusive as próprias)
+func (b *RedisInvalidationBus) Start(ctx context.Context) error {
+	pubsub := b.client.Subscribe(ctx, b.channel)
+	// Confirma a inscrição antes de retornar para não perder mensagens publicadas logo depois
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return fmt.Errorf("failed to subscribe to invalidations: %w", err)
+	}
+
+	b.pubsub = pubsub
+	go b.run(pubsub.Channel())
+	return nil
+}
+
+// Stop cancela a inscrição
+func (b *RedisInvalidationBus) Stop() {
+	if b.pubsub == nil {
+		return
+	}
+	b.stopOnce.Do(func() {
+		b.pubsub.Close()
+	})
+	<-b.done
+}
+
+// Broadcast publica a invalidação para todas as instâncias inscritas
+func (b *RedisInvalidationBus) Broadcast(ctx context.Context, invalidation domain.Invalidation) error {
+	invalidation.Origin = b.origin
+	payload, err := json.Marshal(invalidation)
+	if err != nil {
+		return fmt.Errorf("failed to marshal invalidation: %w", err)
+	}
+
+	if err := b.client.Publish(ctx, b.channel, payload).Err(); err != nil {
+		return fmt.Errorf("failed to publish invalidation: %w", err)
+	}
+	return nil
+}
+
+// run entrega as mensagens do canal até a inscrição ser encerrada
+func (b *RedisInvalidationBus) run(messages <-chan *redis.Message) {
+	defer close(b.done)
+
+	for message := range messages {
+		var invalidation domain.Invalidation
+		if err := json.Unmarshal([]byte(message.Payload), &invalidation); err != nil {
+			if b.logger != nil {
+				b.logger.Warn("Ignoring malformed invalidation message", map[string]interface{}{
+					"error": err.Error(),
+				})
+			}
+			continue
+		}
+		b.deliver(invalidation)
+	}
+}
@@ -0,0 +1,29 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/domain"
+)
+
+func TestLocalInvalidationBus_DeliversToSubscribers(t *testing.T) {
+	// Arrange
+	bus := NewLocalInvalidationBus("instance-a")
+	var first, second []domain.Invalidation
+	bus.Subscribe(func(invalidation domain.Invalidation) { first = append(first, invalidation) })
+	bus.Subscribe(func(invalidation domain.Invalidation) { second = append(second, invalidation) })
+
+	// Act
+	err := bus.Broadcast(context.Background(), domain.Invalidation{Key: "192.168.1.1", Type: domain.IPLimiter, Reason: "reset"})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, first, 1)
+	require.Len(t, second, 1)
+	assert.Equal(t, "192.168.1.1", first[0].Key)
+	assert.Equal(t, "instance-a", first[0].Origin)
+}
@@ -0,0 +1,188 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"rate-limiter/internal/domain"
+)
+
+// Membership registra a instância atual via heartbeats e acompanha as réplicas vivas
+// A contagem fica em cache para não consultar o store a cada requisição
+type Membership struct {
+	store    Store
+	logger   domain.Logger
+	interval time.Duration
+	ttl      time.Duration
+
+	mutex     sync.RWMutex
+	self      Instance
+	instances []Instance
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// NewMembership cria o controle de participação no cluster
+// self descreve a instância atual (ID vazio gera um automaticamente)
+// O ttl deve ser maior que o intervalo para tolerar heartbeats atrasados
+func NewMembership(store Store, self Instance, interval, ttl time.Duration, logger domain.Logger) *Membership {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	if ttl <= interval {
+		ttl = 3 * interval
+	}
+	if self.ID == "" {
+		self.ID = GenerateInstanceID()
+	}
+
+	now := time.Now()
+	self.StartedAt = now
+	self.LastSeen = now
+
+	return &Membership{
+		store:    store,
+		logger:   logger,
+		interval: interval,
+		ttl:      ttl,
+		self:     self,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// GenerateInstanceID gera um ID no formato hostname-xxxxxxxx
+func GenerateInstanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		hostname = "instance"
+	}
+	return fmt.Sprintf("%s-%s", hostname, uuid.New().String()[:8])
+}
+
+// Start executa o primeiro heartbeat e inicia o loop em background
+func (m *Membership) Start() {
+	m.beat()
+	go m.run()
+}
+
+// Stop encerra os heartbeats e remove o registro da instância
+func (m *Membership) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.stop)
+	})
+	<-m.done
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.interval)
+	defer cancel()
+	if err := m.store.Deregister(ctx, m.Self().ID); err != nil && m.logger != nil {
+		m.logger.Warn("Failed to deregister instance", map[string]interface{}{
+			"instance_id": m.Self().ID,
+			"error":       err.Error(),
+		})
+	}
+}
+
+// Self retorna o registro da instância atual
+func (m *Membership) Self() Instance {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.self
+}
+
+// Instances retorna as instâncias vivas observadas no último heartbeat
+func (m *Membership) Instances() []Instance {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	instances := make([]Instance, len(m.instances))
+	copy(instances, m.instances)
+	return instances
+}
+
+// ListInstances consulta o store e retorna as instâncias vivas no momento
+func (m *Membership) ListInstances(ctx context.Context) ([]Instance, error) {
+	return m.store.List(ctx)
+}
+
+// LiveInstances retorna o número de instâncias vivas (no mínimo 1, a própria)
+func (m *Membership) LiveInstances() int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if len(m.instances) == 0 {
+		return 1
+	}
+	return len(m.instances)
+}
+
+// run é o loop de heartbeats
+func (m *Membership) run() {
+	defer close(m.done)
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+			m.beat()
+		}
+	}
+}
+
+// beat renova o registro da instância e atualiza a lista de réplicas
+// Em caso de falha a última contagem conhecida é mantida
+func (m *Membership) beat() {
+	ctx, cancel := context.WithTimeout(context.Background(), m.interval)
+	defer cancel()
+
+	m.mutex.Lock()
+	m.self.LastSeen = time.Now()
+	self := m.self
+	m.mutex.Unlock()
+
+	if err := m.store.Register(ctx, self, m.ttl); err != nil {
+		m.logFailure("Failed to register instance heartbeat", err)
+		return
+	}
+
+	instances, err := m.store.List(ctx)
+	if err != nil {
+		m.logFailure("Failed to list live instances", err)
+		return
+	}
+
+	m.mutex.Lock()
+	previous := len(m.instances)
+	m.instances = instances
+	m.mutex.Unlock()
+
+	if previous != len(instances) && m.logger != nil {
+		m.logger.Info("Live instance count changed", map[string]interface{}{
+			"instance_id":    self.ID,
+			"live_instances": len(instances),
+			"previous":       previous,
+		})
+	}
+}
+
+// logFailure registra falhas de heartbeat
+func (m *Membership) logFailure(msg string, err error) {
+	if m.logger == nil {
+		return
+	}
+	m.logger.Warn(msg, map[string]interface{}{
+		"instance_id": m.Self().ID,
+		"error":       err.Error(),
+	})
+}
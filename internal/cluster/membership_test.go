@@ -0,0 +1,98 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMembership_LiveInstances(t *testing.T) {
+	// Arrange
+	store := NewMemoryStore()
+	first := NewMembership(store, Instance{ID: "instance-a"}, time.Hour, 2*time.Hour, nil)
+	second := NewMembership(store, Instance{ID: "instance-b"}, time.Hour, 2*time.Hour, nil)
+
+	// Act
+	first.Start()
+	second.Start()
+
+	// Assert - o segundo já enxerga os dois; o primeiro só após o próximo heartbeat
+	assert.Equal(t, 1, first.LiveInstances())
+	assert.Equal(t, 2, second.LiveInstances())
+
+	first.beat()
+	assert.Equal(t, 2, first.LiveInstances())
+	assert.Equal(t, []string{"instance-a", "instance-b"}, instanceIDs(first.Instances()))
+
+	// Instância encerrada deixa o registro
+	second.Stop()
+	first.beat()
+	assert.Equal(t, 1, first.LiveInstances())
+
+	first.Stop()
+	instances, err := store.List(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, instances)
+}
+
+func TestMembership_KeepsLastCountOnFailure(t *testing.T) {
+	// Arrange
+	store := &failingStore{MemoryStore: NewMemoryStore()}
+	membership := NewMembership(store, Instance{ID: "instance-a"}, time.Hour, 2*time.Hour, nil)
+	require.NoError(t, store.Register(context.Background(), Instance{ID: "instance-b"}, time.Hour))
+	membership.beat()
+	require.Equal(t, 2, membership.LiveInstances())
+
+	// Act
+	store.fail = true
+	membership.beat()
+
+	// Assert
+	assert.Equal(t, 2, membership.LiveInstances())
+}
+
+func TestMemoryStore_Expiration(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	require.NoError(t, store.Register(ctx, Instance{ID: "expired"}, -time.Second))
+	require.NoError(t, store.Register(ctx, Instance{ID: "alive"}, time.Minute))
+
+	instances, err := store.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alive"}, instanceIDs(instances))
+}
+
+func TestNewMembership_Defaults(t *testing.T) {
+	membership := NewMembership(NewMemoryStore(), Instance{}, 0, 0, nil)
+
+	assert.NotEmpty(t, membership.Self().ID)
+	assert.Equal(t, 5*time.Second, membership.interval)
+	assert.Equal(t, 15*time.Second, membership.ttl)
+	assert.Equal(t, 1, membership.LiveInstances())
+}
+
+// failingStore simula indisponibilidade do store compartilhado
+type failingStore struct {
+	*MemoryStore
+	fail bool
+}
+
+func (s *failingStore) Register(ctx context.Context, instance Instance, ttl time.Duration) error {
+	if s.fail {
+		return errors.New("connection refused")
+	}
+	return s.MemoryStore.Register(ctx, instance, ttl)
+}
+
+func instanceIDs(instances []Instance) []string {
+	ids := make([]string, 0, len(instances))
+	for _, instance := range instances {
+		ids = append(ids, instance.ID)
+	}
+	return ids
+}
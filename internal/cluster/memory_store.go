@@ -0,0 +1,63 @@
+package cluster
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryStore mantém registros em memória (instância única ou testes)
+type MemoryStore struct {
+	mutex     sync.Mutex
+	instances map[string]Instance
+	expiresAt map[string]time.Time
+}
+
+// NewMemoryStore cria um store de instâncias em memória
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		instances: make(map[string]Instance),
+		expiresAt: make(map[string]time.Time),
+	}
+}
+
+// Register grava o registro da instância com expiração
+func (s *MemoryStore) Register(ctx context.Context, instance Instance, ttl time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.instances[instance.ID] = instance
+	s.expiresAt[instance.ID] = time.Now().Add(ttl)
+	return nil
+}
+
+// Deregister remove o registro da instância
+func (s *MemoryStore) Deregister(ctx context.Context, id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.instances, id)
+	delete(s.expiresAt, id)
+	return nil
+}
+
+// List retorna as instâncias não expiradas ordenadas por ID
+func (s *MemoryStore) List(ctx context.Context) ([]Instance, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	instances := make([]Instance, 0, len(s.instances))
+	for id, instance := range s.instances {
+		if now.After(s.expiresAt[id]) {
+			delete(s.instances, id)
+			delete(s.expiresAt, id)
+			continue
+		}
+		instances = append(instances, instance)
+	}
+
+	sort.Slice(instances, func(i, j int) bool { return instances[i].ID < instances[j].ID })
+	return instances, nil
+}
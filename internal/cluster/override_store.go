@@ -0,0 +1,116 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"rate-limiter/internal/domain"
+)
+
+// DefaultOverridesKey é o hash do Redis com os overrides de todas as réplicas
+const DefaultOverridesKey = "rate_limiter:overrides"
+
+// storedOverride é o valor gravado por chave de storage; o identificador não é
+// repetido, pois a chave pode estar cifrada (STORAGE_ENCRYPTION_KEY)
+type storedOverride struct {
+	Type      domain.LimiterType `json:"type"`
+	Limit     int                `json:"limit"`
+	ExpiresAt time.Time          `json:"expiresAt"`
+}
+
+// MemoryOverrideStore mantém os overrides em memória (instância única ou testes)
+type MemoryOverrideStore struct {
+	mutex     sync.Mutex
+	overrides map[string]domain.LimitOverride
+}
+
+// NewMemoryOverrideStore cria um store de overrides em memória
+func NewMemoryOverrideStore() *MemoryOverrideStore {
+	return &MemoryOverrideStore{overrides: make(map[string]domain.LimitOverride)}
+}
+
+// SaveOverride grava (ou substitui) o override da chave de storage
+func (s *MemoryOverrideStore) SaveOverride(ctx context.Context, storageKey string, override domain.LimitOverride) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	override.Key = ""
+	s.overrides[storageKey] = override
+	return nil
+}
+
+// ListOverrides retorna os overrides vigentes, descartando os expirados
+func (s *MemoryOverrideStore) ListOverrides(ctx context.Context) (map[string]domain.LimitOverride, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	overrides := make(map[string]domain.LimitOverride, len(s.overrides))
+	for storageKey, override := range s.overrides {
+		if !now.Before(override.ExpiresAt) {
+			delete(s.overrides, storageKey)
+			continue
+		}
+		overrides[storageKey] = override
+	}
+	return overrides, nil
+}
+
+// RedisOverrideStore guarda os overrides em um único hash do Redis (campo = chave
+// de storage), lido de uma vez com HGETALL; os expirados são removidos na leitura
+type RedisOverrideStore struct {
+	client redis.Cmdable
+	key    string
+}
+
+// NewRedisOverrideStore cria um store de overrides sobre um cliente Redis
+func NewRedisOverrideStore(client redis.Cmdable) *RedisOverrideStore {
+	return &RedisOverrideStore{
+		client: client,
+		key:    DefaultOverridesKey,
+	}
+}
+
+// SaveOverride grava (ou substitui) o override da chave de storage
+func (s *RedisOverrideStore) SaveOverride(ctx context.Context, storageKey string, override domain.LimitOverride) error {
+	data, err := json.Marshal(storedOverride{Type: override.Type, Limit: override.Limit, ExpiresAt: override.ExpiresAt})
+	if err != nil {
+		return fmt.Errorf("failed to marshal override: %w", err)
+	}
+
+	if err := s.client.HSet(ctx, s.key, storageKey, data).Err(); err != nil {
+		return fmt.Errorf("failed to save override: %w", err)
+	}
+	return nil
+}
+
+// ListOverrides retorna os overrides vigentes por chave de storage
+func (s *RedisOverrideStore) ListOverrides(ctx context.Context) (map[string]domain.LimitOverride, error) {
+	values, err := s.client.HGetAll(ctx, s.key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load overrides: %w", err)
+	}
+
+	now := time.Now()
+	overrides := make(map[string]domain.LimitOverride, len(values))
+	var expired []string
+	for storageKey, raw := range values {
+		var stored storedOverride
+		if err := json.Unmarshal([]byte(raw), &stored); err != nil || !now.Before(stored.ExpiresAt) {
+			expired = append(expired, storageKey)
+			continue
+		}
+		overrides[storageKey] = domain.LimitOverride{Type: stored.Type, Limit: stored.Limit, ExpiresAt: stored.ExpiresAt}
+	}
+
+	// A limpeza é best-effort: um campo expirado que sobrar é ignorado na próxima leitura
+	if len(expired) > 0 {
+		_ = s.client.HDel(ctx, s.key, expired...).Err()
+	}
+	return overrides, nil
+}
@@ -0,0 +1,56 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/domain"
+)
+
+func TestOverrideStores_SaveAndList(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	stores := map[string]domain.OverrideStore{
+		"memory": NewMemoryOverrideStore(),
+		"redis":  NewRedisOverrideStore(client),
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+			require.NoError(t, store.SaveOverride(ctx, "rate_limit:token:basic_token", domain.LimitOverride{
+				Key: "basic_token", Type: domain.TokenLimiter, Limit: 5000, ExpiresAt: expiresAt,
+			}))
+			require.NoError(t, store.SaveOverride(ctx, "rate_limit:ip:10.0.0.1", domain.LimitOverride{
+				Key: "10.0.0.1", Type: domain.IPLimiter, Limit: 1, ExpiresAt: time.Now().Add(-time.Second),
+			}))
+
+			// Act
+			overrides, err := store.ListOverrides(ctx)
+
+			// Assert - o expirado não volta e o identificador não é gravado
+			require.NoError(t, err)
+			require.Len(t, overrides, 1)
+			override := overrides["rate_limit:token:basic_token"]
+			assert.Equal(t, domain.TokenLimiter, override.Type)
+			assert.Equal(t, 5000, override.Limit)
+			assert.True(t, expiresAt.Equal(override.ExpiresAt))
+			assert.Empty(t, override.Key)
+		})
+	}
+
+	// O campo expirado é removido do hash na leitura
+	fields, err := client.HKeys(context.Background(), DefaultOverridesKey).Result()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"rate_limit:token:basic_token"}, fields)
+}
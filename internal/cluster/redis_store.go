@@ -0,0 +1,93 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// DefaultKeyPrefix é o prefixo das chaves de registro no Redis
+const DefaultKeyPrefix = "rate_limiter:instances:"
+
+// RedisStore registra instâncias como chaves com TTL no Redis
+type RedisStore struct {
+	client redis.Cmdable
+	prefix string
+}
+
+// NewRedisStore cria um store de instâncias sobre um cliente Redis
+func NewRedisStore(client redis.Cmdable) *RedisStore {
+	return &RedisStore{
+		client: client,
+		prefix: DefaultKeyPrefix,
+	}
+}
+
+// Register grava o registro da instância com expiração
+func (s *RedisStore) Register(ctx context.Context, instance Instance, ttl time.Duration) error {
+	data, err := json.Marshal(instance)
+	if err != nil {
+		return fmt.Errorf("failed to marshal instance: %w", err)
+	}
+
+	if err := s.client.Set(ctx, s.prefix+instance.ID, data, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to register instance: %w", err)
+	}
+	return nil
+}
+
+// Deregister remove o registro da instância
+func (s *RedisStore) Deregister(ctx context.Context, id string) error {
+	if err := s.client.Del(ctx, s.prefix+id).Err(); err != nil {
+		return fmt.Errorf("failed to deregister instance: %w", err)
+	}
+	return nil
+}
+
+// List retorna as instâncias registradas ordenadas por ID
+func (s *RedisStore) List(ctx context.Context) ([]Instance, error) {
+	var keys []string
+	var cursor uint64
+	for {
+		batch, next, err := s.client.Scan(ctx, cursor, s.prefix+"*", 100).Result()
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan instances: %w", err)
+		}
+		keys = append(keys, batch...)
+		if next == 0 {
+			break
+		}
+		cursor = next
+	}
+
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	values, err := s.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load instances: %w", err)
+	}
+
+	instances := make([]Instance, 0, len(values))
+	for _, value := range values {
+		// Chave pode ter expirado entre o SCAN e o MGET
+		raw, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		var instance Instance
+		if err := json.Unmarshal([]byte(raw), &instance); err != nil {
+			continue
+		}
+		instances = append(instances, instance)
+	}
+
+	sort.Slice(instances, func(i, j int) bool { return instances[i].ID < instances[j].ID })
+	return instances, nil
+}
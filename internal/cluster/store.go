@@ -0,0 +1,36 @@
+package cluster
+
+import (
+	"context"
+	"time"
+)
+
+// Instance descreve uma réplica registrada no cluster
+type Instance struct {
+	ID          string    `json:"id"`
+	Version     string    `json:"version,omitempty"`
+	StorageType string    `json:"storageType,omitempty"`
+	ConfigHash  string    `json:"configHash,omitempty"` // Impressão digital da configuração carregada
+	StartedAt   time.Time `json:"startedAt"`
+	LastSeen    time.Time `json:"lastSeen"`
+}
+
+// Uptime retorna há quanto tempo a instância está no ar, no instante now
+func (i Instance) Uptime(now time.Time) time.Duration {
+	if i.StartedAt.IsZero() {
+		return 0
+	}
+	return now.Sub(i.StartedAt)
+}
+
+// Store persiste os registros de instância com expiração (heartbeat)
+type Store interface {
+	// Register grava (ou renova) o registro da instância por ttl
+	Register(ctx context.Context, instance Instance, ttl time.Duration) error
+
+	// Deregister remove o registro da instância
+	Deregister(ctx context.Context, id string) error
+
+	// List retorna as instâncias com registro ainda válido
+	List(ctx context.Context) ([]Instance, error)
+}
@@ -1,12 +1,21 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
+	"regexp"
 	"strconv"
+	"strings"
 
+	"rate-limiter/internal/adminauth"
 	"rate-limiter/internal/domain"
+	"rate-limiter/internal/schedule"
 
 	"github.com/joho/godotenv"
 )
@@ -16,8 +25,39 @@ type Config struct {
 	// Redis Configuration
 	RedisHost     string
 	RedisPort     string
+	RedisUsername string // Usuário ACL (Redis 6+)
 	RedisPassword string
 	RedisDB       int
+	RedisHashTags bool // {hash tags} nas chaves, para Redis Cluster
+
+	// Redis TLS (ElastiCache com criptografia em trânsito, Azure Cache)
+	RedisTLSEnabled            bool
+	RedisTLSCAFile             string
+	RedisTLSCertFile           string
+	RedisTLSKeyFile            string
+	RedisTLSInsecureSkipVerify bool
+
+	// Redis Retries (novas tentativas só com o circuito fechado e dentro do prazo da requisição)
+	RedisMaxRetries      int // 0 = sem novas tentativas
+	RedisMinRetryBackoff int // em milissegundos (0 = padrão)
+	RedisMaxRetryBackoff int // em milissegundos (0 = padrão)
+
+	// Redis Sentinel (REDIS_MODE=sentinel; REDIS_HOST e REDIS_PORT são ignorados)
+	RedisMode             string // "standalone" ou "sentinel"
+	RedisSentinelMaster   string
+	RedisSentinelAddrs    []string // host:porta de cada sentinel
+	RedisSentinelUsername string
+	RedisSentinelPassword string
+
+	// etcd Configuration (STORAGE_TYPE=etcd; URLs do gateway da API v3)
+	EtcdEndpoints []string
+	EtcdUsername  string
+	EtcdPassword  string
+	EtcdPrefix    string
+
+	// Storage Encryption (chave AES em base64; vazias = identificadores e status em claro)
+	StorageEncryptionKey     string
+	StorageEncryptionKeyFile string // segredo montado por KMS/secret manager
 
 	// Rate Limiting Configuration
 	DefaultIPLimit    int
@@ -26,8 +66,12 @@ type Config struct {
 	BlockDuration     int // em segundos
 
 	// Server Configuration
-	ServerPort string
-	GinMode    string
+	ServerPort     string
+	GinMode        string
+	SocketPath     string      // Unix domain socket (opcional, substitui a porta TCP)
+	SocketMode     os.FileMode // Permissões do arquivo de socket
+	MaxHeaderBytes int         // tamanho máximo dos headers da requisição (0 = padrão do net/http, 1MB)
+	MaxBodyBytes   int64       // tamanho máximo do corpo da requisição (0 = sem limite)
 
 	// Logging Configuration
 	LogLevel  string
@@ -35,17 +79,195 @@ type Config struct {
 
 	// Token Configuration File
 	TokenConfigFile string
+
+	// Maintenance Windows File (vazio = apenas via admin API)
+	MaintenanceFile string
+
+	// Staged Config File avaliada em shadow (vazio = desabilitado)
+	ShadowConfigFile string
+
+	// Token Configuration Source ("file" ou "sql")
+	TokenSource          string
+	TokenDBDriver        string
+	TokenDBDSN           string
+	TokenDBTable         string
+	TokenRefreshInterval int // em segundos
+	TokenCacheTTL        int // em segundos, > 0 consulta sob demanda via cache
+	TokenNegativeTTL     int // em segundos, 0 = TokenCacheTTL
+
+	// Scheduled Reset Configuration (cron, vazio = janela deslizante)
+	IPResetSchedule    string
+	TokenResetSchedule string
+	QuotaRolloverPercent int // % da cota agendada não usada levada ao próximo período
+	AlignWindows         bool // Janelas alinhadas ao relógio em vez da primeira requisição
+
+	// Token bucket padrão (fichas repostas por segundo, capacidade = limite padrão; 0 = janela)
+	IPRefillRate    float64
+	TokenRefillRate float64
+
+	// Leaky bucket padrão (requisições escoadas por segundo, fila = limite padrão; 0 = janela)
+	IPLeakRate    float64
+	TokenLeakRate float64
+
+	// Storage nomeado por tipo de limiter ("memory", "redis", "hybrid", "tiered" ou "etcd"; vazio = STORAGE_TYPE)
+	IPStorage    string
+	TokenStorage string
+
+	// Algoritmo de contagem ("fixed_window", "sliding_log" ou "sliding_window")
+	RateLimitAlgorithm string
+
+	// Contabiliza também o IP de requisições limitadas pelo token (sem bloqueá-lo)
+	EvaluateAllIdentities bool
+
+	// Janelas recentes guardadas por chave para /admin/status?history=true (0 = desabilitado)
+	WindowHistorySize int
+
+	// Block Response Configuration (mensagem e documentação do 429 por tipo)
+	IPBlockMessage    string
+	IPDocsURL         string
+	TokenBlockMessage string
+	TokenDocsURL      string
+
+	// Cache do 429 em CDNs (TTL em segundos, 0 = desabilitado; headers de CDN com o mesmo max-age)
+	RateLimitedCacheTTL     int
+	RateLimitedCacheHeaders []string
+
+	// Tarpit: atraso das respostas de clientes suspeitos (em ms, 0 = desabilitado)
+	TarpitMaxDelay      int // atraso das recusas e teto da faixa suspeita
+	TarpitBandPercent   int // % do limite a partir do qual as permitidas também são atrasadas (0 = só recusas)
+	TarpitMaxConcurrent int // requisições retidas ao mesmo tempo (0 = sem limite)
+
+	// Desafio (ex: CAPTCHA) no lugar do 429 (URL vazia = desabilitado)
+	ChallengeURL    string // página de verificação, absoluta ou caminho no mesmo host
+	ChallengeSecret string // assina os comprovantes emitidos pela página de verificação
+
+	// Tracing OpenTelemetry exportado por OTLP/HTTP (endpoint vazio = desabilitado)
+	TracingEndpoint    string  // URL completa do coletor para traces
+	TracingServiceName string  // service.name dos spans
+	TracingSampleRatio float64 // fração dos traces iniciados aqui que são gravados (0 a 1)
+
+	// Métricas OpenTelemetry exportadas por OTLP/HTTP (endpoint vazio = desabilitado)
+	MetricsEndpoint       string // URL completa do coletor para métricas
+	MetricsExportInterval int    // intervalo entre exportações, em milissegundos
+
+	// Profiling (net/http/pprof) nas rotas /admin/debug/pprof ou em um listener próprio
+	PprofEnabled bool
+	PprofAddr    string // host:port do listener dedicado (vazio = no grupo /admin)
+
+	// Storage Health Configuration
+	HealthCheckInterval   int    // em segundos
+	HealthCheckMaxBackoff int    // em segundos
+	ReadinessHealthChecks int    // checks saudáveis consecutivos antes da readiness
+	HealthRecoveryChecks  int    // checks saudáveis consecutivos, após uma falha, até fechar o circuito (0 = padrão)
+	FailureMode           string // "closed" ou "open"
+	DecisionTimeout       int    // em milissegundos, espera máxima pela decisão (0 = padrão)
+
+	// Memory Storage Configuration
+	MemoryExpectedKeys int // chaves pré-alocadas no storage em memória
+	MemoryMaxKeys      int // limite de chaves com descarte LRU (0 = sem limite)
+
+	// Snapshot do storage em memória em disco (restaurado na inicialização)
+	MemorySnapshotPath     string // vazio = desabilitado
+	MemorySnapshotInterval int    // em segundos
+
+	// Hybrid Storage Configuration (decisões em memória, sincronizadas com o Redis)
+	HybridSyncInterval  int // em milissegundos (0 = padrão)
+	HybridSyncBatchSize int // chaves por pipeline no Redis (0 = padrão)
+
+	// Tiered Storage Configuration (cache em memória de curta duração na frente do Redis)
+	TieredCacheTTL int // em milissegundos
+
+	// Block Filter Configuration (filtro de Bloom das chaves bloqueadas; apenas STORAGE_TYPE=redis)
+	BlockFilterExpectedKeys int // 0 = desabilitado
+	BlockFilterPartitions   int // partições do índice de bloqueios no Redis
+	BlockFilterRefresh      int // em milissegundos
+
+	// Instance Partitioning Configuration (limites divididos entre réplicas vivas)
+	PartitionLimits           bool
+	InstanceID                string
+	InstanceHeartbeatInterval int // em segundos
+	InstanceTTL               int // em segundos
+
+	// Allowlist Configuration (identidades que ignoram o rate limiter)
+	AllowlistIPs    []string
+	AllowlistTokens []string
+
+	// Probe Exclusion (health checks de infraestrutura fora da cota e do access log)
+	ProbeUserAgents   []string
+	ProbeSourceRanges []string
+
+	// Anomaly Detection Configuration (picos de tráfego antes do limite)
+	AnomalyDetection   bool
+	AnomalyInterval    int     // em segundos, duração de cada amostra
+	AnomalyZThreshold  float64 // z-score mínimo para alertar
+	AnomalyMinRequests int     // requisições mínimas na amostra para alertar
+
+	// Capacity Planning (limites sugeridos em /admin/capacity)
+	CapacityPlanning bool
+
+	// Learning Mode (limites por chave aprendidos do tráfego, em /admin/learning)
+	LearningMode           string  // "off", "propose" (só relatório) ou "apply" (overrides automáticos)
+	LearningTrainingPeriod int     // em segundos, observação de cada chave antes da proposta
+	LearningHeadroom       float64 // multiplicador sobre a taxa normal aprendida
+	LearningMinFactor      float64 // piso da proposta, em fração do limite da regra
+	LearningMaxFactor      float64 // teto da proposta, em fração do limite da regra
+
+	// Events Webhook (vazio = eventos apenas no log)
+	EventsWebhookURL string
+
+	// Block Events Stream (eventos de bloqueio em um Redis Stream; vazio = desabilitado)
+	BlockEventsStream       string
+	BlockEventsStreamMaxLen int // entradas mantidas no stream (corte aproximado)
+
+	// Security Log (eventos de enforcement classificados por severidade, para SIEM)
+	SecurityLog             bool
+	SecurityLogPath         string // vazio = stdout
+	SecurityLogMinSeverity  string // low, medium, high ou critical
+	SecurityRepeatBlocks    int    // bloqueios da mesma chave que caracterizam repetição (0 = padrão)
+	SecurityRepeatWindow    int    // em segundos, janela dos bloqueios repetidos (0 = padrão)
+	SoftLimitWarningPercent int    // % do limite que gera o aviso de soft limit (0 = desabilitado)
+
+	// Audit Log (operações administrativas que alteram estado, consultáveis em /admin/audit)
+	AuditLogCapacity int    // registros mantidos em memória (0 = auditoria desabilitada)
+	AuditLogPath     string // arquivo JSON dos registros (vazio = stdout, "none" = sem arquivo)
+	AuditWebhookURL  string // entrega de cada registro por webhook (vazio = desabilitado)
+
+	// Reverse Proxy (rotas não registradas encaminhadas ao upstream; vazio = desabilitado)
+	ProxyUpstreamURL string
+
+	// Admin Authentication (vazios = API administrativa aberta)
+	AdminAPIKey           string // Authorization: Bearer <chave>
+	AdminHMACSecret       string // requisições assinadas (X-Admin-Timestamp, X-Admin-Nonce, X-Admin-Signature)
+	AdminSignatureMaxSkew int    // em segundos, diferença máxima do timestamp assinado (0 = padrão)
+	AdminKeys             string // chaves nomeadas com papel e tenants: "nome:papel:chave[:tenant|tenant],..."
+
+	// Response Compression (grupos de rotas "admin", "public" e "protected"; vazio = desabilitada)
+	CompressionGroups  []string
+	CompressionMinSize int // em bytes, respostas menores seguem sem compressão (0 = padrão)
+	CompressionLevel   int // nível do gzip, 1-9 (0 = padrão)
+
+	// Analytics Configuration (agregados por minuto em /admin/analytics)
+	AnalyticsRetentionHours int    // 0 = desabilitado
+	AnalyticsStorage        string // "memory" (por instância) ou "redis" (compartilhado)
+
+	// Estatísticas recentes em memória, por tipo e por chave (/admin/stats)
+	StatsRetentionMinutes int // 0 = desabilitado
+	StatsMaxKeys          int // chaves acompanhadas por intervalo de 10s
 }
 
 // TokensFile representa a estrutura do arquivo tokens.json
 type TokensFile struct {
-	Tokens map[string]domain.TokenConfig `json:"tokens"`
+	Tokens        map[string]domain.TokenConfig        `json:"tokens"`
+	Projects      map[string]domain.ProjectConfig      `json:"projects,omitempty"`
+	Organizations map[string]domain.OrganizationConfig `json:"organizations,omitempty"`
 }
 
 // ConfigLoader implementa a interface domain.ConfigLoader
 type ConfigLoader struct {
 	config      *Config
 	tokenConfigs map[string]domain.TokenConfig
+	projects      map[string]domain.ProjectConfig
+	organizations map[string]domain.OrganizationConfig
 }
 
 // NewConfigLoader cria uma nova instância do ConfigLoader
@@ -84,6 +306,28 @@ func (c *ConfigLoader) LoadConfig() (*domain.RateLimitConfig, error) {
 		Window:           config.RateWindow,
 		BlockDuration:    config.BlockDuration,
 		TokenConfigs:     tokenConfigs,
+		Projects:         c.projects,
+		Organizations:    c.organizations,
+
+		IPResetSchedule:    config.IPResetSchedule,
+		TokenResetSchedule: config.TokenResetSchedule,
+
+		QuotaRolloverPercent: config.QuotaRolloverPercent,
+		AlignWindows:         config.AlignWindows,
+
+		IPRefillRate:    config.IPRefillRate,
+		TokenRefillRate: config.TokenRefillRate,
+
+		IPLeakRate:    config.IPLeakRate,
+		TokenLeakRate: config.TokenLeakRate,
+
+		IPStorage:    config.IPStorage,
+		TokenStorage: config.TokenStorage,
+
+		IPBlockMessage:    config.IPBlockMessage,
+		IPDocsURL:         config.IPDocsURL,
+		TokenBlockMessage: config.TokenBlockMessage,
+		TokenDocsURL:      config.TokenDocsURL,
 	}
 
 	return rateLimitConfig, nil
@@ -112,19 +356,116 @@ func (c *ConfigLoader) LoadTokenConfigs() (map[string]domain.TokenConfig, error)
 	}
 
 	// Valida as configurações de tokens
-	for token, config := range tokensFile.Tokens {
+	if err := validateTokenConfigs(tokensFile.Tokens); err != nil {
+		return nil, err
+	}
+	if err := validateLimitHierarchy(tokensFile); err != nil {
+		return nil, err
+	}
+
+	c.tokenConfigs = tokensFile.Tokens
+	c.projects = tokensFile.Projects
+	c.organizations = tokensFile.Organizations
+	return tokensFile.Tokens, nil
+}
+
+// validateTokenConfigs valida e normaliza as configurações de tokens (arquivo ou banco)
+func validateTokenConfigs(tokens map[string]domain.TokenConfig) error {
+	families := make(map[string]string) // família -> primeiro token visto
+	for token, config := range tokens {
 		if config.Limit <= 0 {
-			return nil, fmt.Errorf("invalid token limit for token %s: must be greater than 0", token)
+			return fmt.Errorf("invalid token limit for token %s: must be greater than 0", token)
+		}
+		if config.ResetSchedule != "" {
+			if _, err := schedule.Parse(config.ResetSchedule); err != nil {
+				return fmt.Errorf("invalid reset schedule for token %s: %w", token, err)
+			}
+		}
+		if config.RolloverPercent != nil && (*config.RolloverPercent < 0 || *config.RolloverPercent > 100) {
+			return fmt.Errorf("invalid rollover percent for token %s: must be between 0 and 100", token)
+		}
+		if config.Capacity < 0 || config.RefillRate < 0 {
+			return fmt.Errorf("invalid token bucket for token %s: capacity and refill rate must not be negative", token)
+		}
+		if config.LeakRate < 0 {
+			return fmt.Errorf("invalid leaky bucket for token %s: leak rate must not be negative", token)
+		}
+		if config.RefillRate > 0 && config.LeakRate > 0 {
+			return fmt.Errorf("invalid algorithm for token %s: refillRate and leakRate are mutually exclusive", token)
+		}
+		if !isValidStorageName(config.Storage) {
+			return fmt.Errorf("invalid storage for token %s: must be 'memory' or 'redis'", token)
 		}
+		config.Storage = strings.ToLower(config.Storage)
+		if config.DocsURL != "" && !isValidHTTPURL(config.DocsURL) {
+			return fmt.Errorf("invalid docs URL for token %s: must be an absolute http(s) URL", token)
+		}
+		if config.WebhookURL != "" && !isValidHTTPURL(config.WebhookURL) {
+			return fmt.Errorf("invalid webhook URL for token %s: must be an absolute http(s) URL", token)
+		}
+		if config.WebhookThreshold < 0 || config.WebhookThreshold > 100 {
+			return fmt.Errorf("invalid webhook threshold for token %s: must be between 0 and 100", token)
+		}
+		if err := validateResponseHeaders(config.Headers); err != nil {
+			return fmt.Errorf("invalid headers for token %s: %w", token, err)
+		}
+		if config.Project != "" && (config.ResetSchedule != "" || config.RefillRate > 0 || config.LeakRate > 0) {
+			return fmt.Errorf("invalid project for token %s: tokens in a project use the fixed window, without resetSchedule, refillRate or leakRate", token)
+		}
+		if config.Family != "" {
+			if strings.TrimSpace(config.Family) != config.Family || strings.ContainsAny(config.Family, ":{}") {
+				return fmt.Errorf("invalid family for token %s: must not contain spaces around it, ':' or braces", token)
+			}
+			// Os membros dividem um único contador, então precisam da mesma regra
+			if first, ok := families[config.Family]; ok && !sameFamilyRule(config, tokens[first]) {
+				return fmt.Errorf("invalid family for token %s: tokens of family %s must share limit, algorithm, reset schedule, project and storage", token, config.Family)
+			} else if !ok {
+				families[config.Family] = token
+			}
+		}
+
 		// Adiciona o token à configuração se não estiver presente
 		if config.Token == "" {
 			config.Token = token
-			tokensFile.Tokens[token] = config
 		}
+		tokens[token] = config
 	}
+	return nil
+}
 
-	c.tokenConfigs = tokensFile.Tokens
-	return tokensFile.Tokens, nil
+// validateLimitHierarchy confere a hierarquia org → projeto → token do arquivo:
+// todo nível referenciado existe e todo limite é positivo
+func validateLimitHierarchy(file TokensFile) error {
+	for name, org := range file.Organizations {
+		if org.Limit <= 0 {
+			return fmt.Errorf("invalid limit for organization %s: must be greater than 0", name)
+		}
+	}
+	for name, project := range file.Projects {
+		if project.Limit <= 0 {
+			return fmt.Errorf("invalid limit for project %s: must be greater than 0", name)
+		}
+		if _, ok := file.Organizations[project.Organization]; project.Organization != "" && !ok {
+			return fmt.Errorf("invalid organization for project %s: organization %s is not configured", name, project.Organization)
+		}
+	}
+	for token, config := range file.Tokens {
+		if _, ok := file.Projects[config.Project]; config.Project != "" && !ok {
+			return fmt.Errorf("invalid project for token %s: project %s is not configured", token, config.Project)
+		}
+	}
+	return nil
+}
+
+// sameFamilyRule informa se dois tokens da mesma família contam da mesma forma
+func sameFamilyRule(a, b domain.TokenConfig) bool {
+	return a.Limit == b.Limit &&
+		a.Capacity == b.Capacity &&
+		a.RefillRate == b.RefillRate &&
+		a.LeakRate == b.LeakRate &&
+		a.ResetSchedule == b.ResetSchedule &&
+		a.Project == b.Project &&
+		strings.EqualFold(a.Storage, b.Storage)
 }
 
 // Reload recarrega todas as configurações
@@ -150,11 +491,31 @@ func (c *ConfigLoader) loadFromEnv() (*Config, error) {
 		// Redis defaults
 		RedisHost:     getEnvWithDefault("REDIS_HOST", "localhost"),
 		RedisPort:     getEnvWithDefault("REDIS_PORT", "6379"),
+		RedisUsername: getEnvWithDefault("REDIS_USERNAME", ""),
 		RedisPassword: getEnvWithDefault("REDIS_PASSWORD", ""),
+
+		// Redis TLS defaults
+		RedisTLSCAFile:   getEnvWithDefault("REDIS_TLS_CA_FILE", ""),
+		RedisTLSCertFile: getEnvWithDefault("REDIS_TLS_CERT_FILE", ""),
+		RedisTLSKeyFile:  getEnvWithDefault("REDIS_TLS_KEY_FILE", ""),
+
+		// Redis Sentinel defaults
+		RedisMode:             strings.ToLower(getEnvWithDefault("REDIS_MODE", "standalone")),
+		RedisSentinelMaster:   getEnvWithDefault("REDIS_SENTINEL_MASTER", "mymaster"),
+		RedisSentinelAddrs:    getEnvList("REDIS_SENTINEL_ADDRS"),
+		RedisSentinelUsername: getEnvWithDefault("REDIS_SENTINEL_USERNAME", ""),
+		RedisSentinelPassword: getEnvWithDefault("REDIS_SENTINEL_PASSWORD", ""),
+
+		// etcd defaults
+		EtcdEndpoints: getEnvList("ETCD_ENDPOINTS"),
+		EtcdUsername:  getEnvWithDefault("ETCD_USERNAME", ""),
+		EtcdPassword:  getEnvWithDefault("ETCD_PASSWORD", ""),
+		EtcdPrefix:    getEnvWithDefault("ETCD_PREFIX", "/rate-limiter/"),
 		
 		// Server defaults
 		ServerPort: getEnvWithDefault("SERVER_PORT", "8080"),
 		GinMode:    getEnvWithDefault("GIN_MODE", "debug"),
+		SocketPath: getEnvWithDefault("SERVER_SOCKET_PATH", ""),
 		
 		// Logging defaults
 		LogLevel:  getEnvWithDefault("LOG_LEVEL", "info"),
@@ -162,6 +523,87 @@ func (c *ConfigLoader) loadFromEnv() (*Config, error) {
 		
 		// Token config file
 		TokenConfigFile: getEnvWithDefault("TOKEN_CONFIG_FILE", "internal/config/tokens.json"),
+
+		// Janelas de manutenção declaradas
+		MaintenanceFile: getEnvWithDefault("MAINTENANCE_FILE", ""),
+
+		// Configuração staged avaliada em shadow
+		ShadowConfigFile: getEnvWithDefault("SHADOW_CONFIG_FILE", ""),
+
+		// Token source (arquivo ou banco)
+		TokenSource:   strings.ToLower(getEnvWithDefault("TOKEN_SOURCE", "file")),
+		TokenDBDriver: getEnvWithDefault("TOKEN_DB_DRIVER", "postgres"),
+		TokenDBDSN:    getEnvWithDefault("TOKEN_DB_DSN", ""),
+		TokenDBTable:  getEnvWithDefault("TOKEN_DB_TABLE", DefaultTokenTable),
+
+		// Scheduled resets
+		IPResetSchedule:    getEnvWithDefault("IP_RESET_SCHEDULE", ""),
+		TokenResetSchedule: getEnvWithDefault("TOKEN_RESET_SCHEDULE", ""),
+
+		// Snapshot do storage em memória
+		MemorySnapshotPath: getEnvWithDefault("MEMORY_SNAPSHOT_PATH", ""),
+
+		// Named storages
+		IPStorage:    strings.ToLower(getEnvWithDefault("IP_STORAGE", "")),
+		TokenStorage: strings.ToLower(getEnvWithDefault("TOKEN_STORAGE", "")),
+
+		// Rate limit algorithm
+		RateLimitAlgorithm: strings.ToLower(getEnvWithDefault("RATE_LIMIT_ALGORITHM", "fixed_window")),
+
+		// Block response
+		IPBlockMessage:    getEnvWithDefault("IP_BLOCK_MESSAGE", ""),
+		IPDocsURL:         getEnvWithDefault("IP_DOCS_URL", ""),
+		TokenBlockMessage: getEnvWithDefault("TOKEN_BLOCK_MESSAGE", ""),
+		TokenDocsURL:      getEnvWithDefault("TOKEN_DOCS_URL", ""),
+
+		// Cache do 429 em CDNs
+		RateLimitedCacheHeaders: getEnvList("RATE_LIMITED_CACHE_HEADERS"),
+
+		// Instance partitioning
+		InstanceID: getEnvWithDefault("INSTANCE_ID", ""),
+
+		// Failure mode
+		FailureMode: strings.ToLower(getEnvWithDefault("FAILURE_MODE", "closed")),
+
+		// Allowlist
+		AllowlistIPs:    getEnvList("ALLOWLIST_IPS"),
+		AllowlistTokens: getEnvList("ALLOWLIST_TOKENS"),
+
+		// Probes de infraestrutura
+		ProbeUserAgents:   getEnvList("PROBE_USER_AGENTS"),
+		ProbeSourceRanges: getEnvList("PROBE_SOURCE_RANGES"),
+
+		// Webhook de eventos
+		EventsWebhookURL: getEnvWithDefault("EVENTS_WEBHOOK_URL", ""),
+
+		// Stream de eventos de bloqueio
+		BlockEventsStream: getEnvWithDefault("BLOCK_EVENTS_STREAM", ""),
+
+		// Log de segurança
+		SecurityLogPath:        getEnvWithDefault("SECURITY_LOG_PATH", ""),
+		SecurityLogMinSeverity: strings.ToLower(getEnvWithDefault("SECURITY_LOG_MIN_SEVERITY", "low")),
+
+		// Log de auditoria
+		AuditLogPath:    getEnvWithDefault("AUDIT_LOG_PATH", ""),
+		AuditWebhookURL: getEnvWithDefault("AUDIT_WEBHOOK_URL", ""),
+
+		// Modo reverse proxy
+		ProxyUpstreamURL: getEnvWithDefault("PROXY_UPSTREAM_URL", ""),
+
+		// Autenticação da API administrativa
+		AdminAPIKey:     getEnvWithDefault("ADMIN_API_KEY", ""),
+		AdminHMACSecret: getEnvWithDefault("ADMIN_HMAC_SECRET", ""),
+		AdminKeys:       getEnvWithDefault("ADMIN_KEYS", ""),
+
+		// Compressão de respostas
+		CompressionGroups: getEnvList("COMPRESSION_GROUPS"),
+
+		// Analytics
+		AnalyticsStorage: strings.ToLower(getEnvWithDefault("ANALYTICS_STORAGE", "memory")),
+
+		// Storage Encryption
+		StorageEncryptionKey:     getEnvWithDefault("STORAGE_ENCRYPTION_KEY", ""),
+		StorageEncryptionKeyFile: getEnvWithDefault("STORAGE_ENCRYPTION_KEY_FILE", ""),
 	}
 
 	// Parse Redis DB
@@ -171,6 +613,65 @@ func (c *ConfigLoader) loadFromEnv() (*Config, error) {
 	}
 	config.RedisDB = redisDB
 
+	redisHashTags, err := strconv.ParseBool(getEnvWithDefault("REDIS_HASH_TAGS", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_HASH_TAGS value: %w", err)
+	}
+	config.RedisHashTags = redisHashTags
+
+	redisTLSEnabled, err := strconv.ParseBool(getEnvWithDefault("REDIS_TLS_ENABLED", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_TLS_ENABLED value: %w", err)
+	}
+	config.RedisTLSEnabled = redisTLSEnabled
+
+	redisTLSInsecureSkipVerify, err := strconv.ParseBool(getEnvWithDefault("REDIS_TLS_INSECURE_SKIP_VERIFY", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_TLS_INSECURE_SKIP_VERIFY value: %w", err)
+	}
+	config.RedisTLSInsecureSkipVerify = redisTLSInsecureSkipVerify
+
+	redisMaxRetries, err := strconv.Atoi(getEnvWithDefault("REDIS_MAX_RETRIES", "3"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_MAX_RETRIES value: %w", err)
+	}
+	config.RedisMaxRetries = redisMaxRetries
+
+	redisMinRetryBackoff, err := strconv.Atoi(getEnvWithDefault("REDIS_MIN_RETRY_BACKOFF_MS", "8"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_MIN_RETRY_BACKOFF_MS value: %w", err)
+	}
+	config.RedisMinRetryBackoff = redisMinRetryBackoff
+
+	redisMaxRetryBackoff, err := strconv.Atoi(getEnvWithDefault("REDIS_MAX_RETRY_BACKOFF_MS", "512"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid REDIS_MAX_RETRY_BACKOFF_MS value: %w", err)
+	}
+	config.RedisMaxRetryBackoff = redisMaxRetryBackoff
+
+	if len(config.EtcdEndpoints) == 0 {
+		config.EtcdEndpoints = []string{"http://localhost:2379"}
+	}
+
+	// Parse socket permissions (octal)
+	socketMode, err := strconv.ParseUint(getEnvWithDefault("SERVER_SOCKET_MODE", "0660"), 8, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SERVER_SOCKET_MODE value: %w", err)
+	}
+	config.SocketMode = os.FileMode(socketMode)
+
+	maxHeaderBytes, err := strconv.Atoi(getEnvWithDefault("MAX_HEADER_BYTES", "65536"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid MAX_HEADER_BYTES value: %w", err)
+	}
+	config.MaxHeaderBytes = maxHeaderBytes
+
+	maxBodyBytes, err := strconv.ParseInt(getEnvWithDefault("MAX_BODY_BYTES", "4194304"), 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid MAX_BODY_BYTES value: %w", err)
+	}
+	config.MaxBodyBytes = maxBodyBytes
+
 	// Parse rate limiting configuration
 	defaultIPLimit, err := strconv.Atoi(getEnvWithDefault("DEFAULT_IP_LIMIT", "10"))
 	if err != nil {
@@ -196,6 +697,364 @@ func (c *ConfigLoader) loadFromEnv() (*Config, error) {
 	}
 	config.BlockDuration = blockDuration
 
+	healthCheckInterval, err := strconv.Atoi(getEnvWithDefault("HEALTH_CHECK_INTERVAL", "5"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid HEALTH_CHECK_INTERVAL value: %w", err)
+	}
+	config.HealthCheckInterval = healthCheckInterval
+
+	healthCheckMaxBackoff, err := strconv.Atoi(getEnvWithDefault("HEALTH_CHECK_MAX_BACKOFF", "60"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid HEALTH_CHECK_MAX_BACKOFF value: %w", err)
+	}
+	config.HealthCheckMaxBackoff = healthCheckMaxBackoff
+
+	readinessHealthChecks, err := strconv.Atoi(getEnvWithDefault("READINESS_HEALTH_CHECKS", "1"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid READINESS_HEALTH_CHECKS value: %w", err)
+	}
+	config.ReadinessHealthChecks = readinessHealthChecks
+
+	healthRecoveryChecks, err := strconv.Atoi(getEnvWithDefault("HEALTH_RECOVERY_CHECKS", "3"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid HEALTH_RECOVERY_CHECKS value: %w", err)
+	}
+	config.HealthRecoveryChecks = healthRecoveryChecks
+
+	decisionTimeout, err := strconv.Atoi(getEnvWithDefault("DECISION_TIMEOUT_MS", "50"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid DECISION_TIMEOUT_MS value: %w", err)
+	}
+	config.DecisionTimeout = decisionTimeout
+
+	rateLimitedCacheTTL, err := strconv.Atoi(getEnvWithDefault("RATE_LIMITED_CACHE_TTL", "0"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid RATE_LIMITED_CACHE_TTL value: %w", err)
+	}
+	config.RateLimitedCacheTTL = rateLimitedCacheTTL
+
+	tarpitMaxDelay, err := strconv.Atoi(getEnvWithDefault("TARPIT_MAX_DELAY_MS", "0"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid TARPIT_MAX_DELAY_MS value: %w", err)
+	}
+	config.TarpitMaxDelay = tarpitMaxDelay
+
+	tarpitBandPercent, err := strconv.Atoi(getEnvWithDefault("TARPIT_BAND_PERCENT", "0"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid TARPIT_BAND_PERCENT value: %w", err)
+	}
+	config.TarpitBandPercent = tarpitBandPercent
+
+	tarpitMaxConcurrent, err := strconv.Atoi(getEnvWithDefault("TARPIT_MAX_CONCURRENT", "1000"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid TARPIT_MAX_CONCURRENT value: %w", err)
+	}
+	config.TarpitMaxConcurrent = tarpitMaxConcurrent
+
+	config.ChallengeURL = getEnvWithDefault("CHALLENGE_URL", "")
+	config.ChallengeSecret = getEnvWithDefault("CHALLENGE_SECRET", "")
+
+	// Endpoint de traces: o específico ou o base do OTLP com o caminho padrão
+	config.TracingEndpoint = getEnvWithDefault("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+	if base := getEnvWithDefault("OTEL_EXPORTER_OTLP_ENDPOINT", ""); config.TracingEndpoint == "" && base != "" {
+		config.TracingEndpoint = strings.TrimSuffix(base, "/") + "/v1/traces"
+	}
+	config.TracingServiceName = getEnvWithDefault("OTEL_SERVICE_NAME", "rate-limiter")
+
+	tracingSampleRatio, err := strconv.ParseFloat(getEnvWithDefault("TRACING_SAMPLE_RATIO", "1"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TRACING_SAMPLE_RATIO value: %w", err)
+	}
+	config.TracingSampleRatio = tracingSampleRatio
+
+	// Endpoint de métricas: o específico ou o base do OTLP; OTEL_METRICS_EXPORTER=none desliga
+	config.MetricsEndpoint = getEnvWithDefault("OTEL_EXPORTER_OTLP_METRICS_ENDPOINT", "")
+	if base := getEnvWithDefault("OTEL_EXPORTER_OTLP_ENDPOINT", ""); config.MetricsEndpoint == "" && base != "" {
+		config.MetricsEndpoint = strings.TrimSuffix(base, "/") + "/v1/metrics"
+	}
+	if strings.EqualFold(getEnvWithDefault("OTEL_METRICS_EXPORTER", "otlp"), "none") {
+		config.MetricsEndpoint = ""
+	}
+
+	metricsExportInterval, err := strconv.Atoi(getEnvWithDefault("OTEL_METRIC_EXPORT_INTERVAL", "60000"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid OTEL_METRIC_EXPORT_INTERVAL value: %w", err)
+	}
+	config.MetricsExportInterval = metricsExportInterval
+
+	pprofEnabled, err := strconv.ParseBool(getEnvWithDefault("PPROF_ENABLED", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid PPROF_ENABLED value: %w", err)
+	}
+	config.PprofEnabled = pprofEnabled
+	config.PprofAddr = getEnvWithDefault("PPROF_ADDR", "")
+
+	tokenRefreshInterval, err := strconv.Atoi(getEnvWithDefault("TOKEN_REFRESH_INTERVAL", "30"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid TOKEN_REFRESH_INTERVAL value: %w", err)
+	}
+	config.TokenRefreshInterval = tokenRefreshInterval
+
+	tokenCacheTTL, err := strconv.Atoi(getEnvWithDefault("TOKEN_CACHE_TTL", "0"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid TOKEN_CACHE_TTL value: %w", err)
+	}
+	config.TokenCacheTTL = tokenCacheTTL
+
+	tokenNegativeTTL, err := strconv.Atoi(getEnvWithDefault("TOKEN_NEGATIVE_CACHE_TTL", "0"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid TOKEN_NEGATIVE_CACHE_TTL value: %w", err)
+	}
+	config.TokenNegativeTTL = tokenNegativeTTL
+
+	partitionLimits, err := strconv.ParseBool(getEnvWithDefault("PARTITION_LIMITS", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid PARTITION_LIMITS value: %w", err)
+	}
+	config.PartitionLimits = partitionLimits
+
+	evaluateAllIdentities, err := strconv.ParseBool(getEnvWithDefault("EVALUATE_ALL_IDENTITIES", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid EVALUATE_ALL_IDENTITIES value: %w", err)
+	}
+	config.EvaluateAllIdentities = evaluateAllIdentities
+
+	windowHistorySize, err := strconv.Atoi(getEnvWithDefault("WINDOW_HISTORY_SIZE", "0"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid WINDOW_HISTORY_SIZE value: %w", err)
+	}
+	config.WindowHistorySize = windowHistorySize
+
+	memoryExpectedKeys, err := strconv.Atoi(getEnvWithDefault("MEMORY_EXPECTED_KEYS", "0"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid MEMORY_EXPECTED_KEYS value: %w", err)
+	}
+	config.MemoryExpectedKeys = memoryExpectedKeys
+
+	memoryMaxKeys, err := strconv.Atoi(getEnvWithDefault("MEMORY_MAX_KEYS", "0"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid MEMORY_MAX_KEYS value: %w", err)
+	}
+	config.MemoryMaxKeys = memoryMaxKeys
+
+	memorySnapshotInterval, err := strconv.Atoi(getEnvWithDefault("MEMORY_SNAPSHOT_INTERVAL", "30"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid MEMORY_SNAPSHOT_INTERVAL value: %w", err)
+	}
+	config.MemorySnapshotInterval = memorySnapshotInterval
+
+	hybridSyncInterval, err := strconv.Atoi(getEnvWithDefault("HYBRID_SYNC_INTERVAL_MS", "200"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid HYBRID_SYNC_INTERVAL_MS value: %w", err)
+	}
+	config.HybridSyncInterval = hybridSyncInterval
+
+	hybridSyncBatchSize, err := strconv.Atoi(getEnvWithDefault("HYBRID_SYNC_BATCH_SIZE", "500"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid HYBRID_SYNC_BATCH_SIZE value: %w", err)
+	}
+	config.HybridSyncBatchSize = hybridSyncBatchSize
+
+	tieredCacheTTL, err := strconv.Atoi(getEnvWithDefault("TIERED_CACHE_TTL_MS", "200"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid TIERED_CACHE_TTL_MS value: %w", err)
+	}
+	config.TieredCacheTTL = tieredCacheTTL
+
+	blockFilterExpectedKeys, err := strconv.Atoi(getEnvWithDefault("BLOCK_FILTER_EXPECTED_KEYS", "0"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid BLOCK_FILTER_EXPECTED_KEYS value: %w", err)
+	}
+	config.BlockFilterExpectedKeys = blockFilterExpectedKeys
+
+	blockFilterPartitions, err := strconv.Atoi(getEnvWithDefault("BLOCK_FILTER_PARTITIONS", "16"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid BLOCK_FILTER_PARTITIONS value: %w", err)
+	}
+	config.BlockFilterPartitions = blockFilterPartitions
+
+	blockFilterRefresh, err := strconv.Atoi(getEnvWithDefault("BLOCK_FILTER_REFRESH_MS", "1000"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid BLOCK_FILTER_REFRESH_MS value: %w", err)
+	}
+	config.BlockFilterRefresh = blockFilterRefresh
+
+	blockEventsStreamMaxLen, err := strconv.Atoi(getEnvWithDefault("BLOCK_EVENTS_STREAM_MAXLEN", "10000"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid BLOCK_EVENTS_STREAM_MAXLEN value: %w", err)
+	}
+	config.BlockEventsStreamMaxLen = blockEventsStreamMaxLen
+
+	securityLog, err := strconv.ParseBool(getEnvWithDefault("SECURITY_LOG", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SECURITY_LOG value: %w", err)
+	}
+	config.SecurityLog = securityLog
+
+	securityRepeatBlocks, err := strconv.Atoi(getEnvWithDefault("SECURITY_REPEAT_BLOCKS", "3"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SECURITY_REPEAT_BLOCKS value: %w", err)
+	}
+	config.SecurityRepeatBlocks = securityRepeatBlocks
+
+	securityRepeatWindow, err := strconv.Atoi(getEnvWithDefault("SECURITY_REPEAT_WINDOW", "600"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SECURITY_REPEAT_WINDOW value: %w", err)
+	}
+	config.SecurityRepeatWindow = securityRepeatWindow
+
+	softLimitWarningPercent, err := strconv.Atoi(getEnvWithDefault("SOFT_LIMIT_WARNING_PERCENT", "80"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SOFT_LIMIT_WARNING_PERCENT value: %w", err)
+	}
+	config.SoftLimitWarningPercent = softLimitWarningPercent
+
+	adminSignatureMaxSkew, err := strconv.Atoi(getEnvWithDefault("ADMIN_SIGNATURE_MAX_SKEW", "300"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ADMIN_SIGNATURE_MAX_SKEW value: %w", err)
+	}
+	config.AdminSignatureMaxSkew = adminSignatureMaxSkew
+
+	compressionMinSize, err := strconv.Atoi(getEnvWithDefault("COMPRESSION_MIN_SIZE", "1024"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid COMPRESSION_MIN_SIZE value: %w", err)
+	}
+	config.CompressionMinSize = compressionMinSize
+
+	compressionLevel, err := strconv.Atoi(getEnvWithDefault("COMPRESSION_LEVEL", "0"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid COMPRESSION_LEVEL value: %w", err)
+	}
+	config.CompressionLevel = compressionLevel
+
+	instanceHeartbeatInterval, err := strconv.Atoi(getEnvWithDefault("INSTANCE_HEARTBEAT_INTERVAL", "5"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid INSTANCE_HEARTBEAT_INTERVAL value: %w", err)
+	}
+	config.InstanceHeartbeatInterval = instanceHeartbeatInterval
+
+	instanceTTL, err := strconv.Atoi(getEnvWithDefault("INSTANCE_TTL", "15"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid INSTANCE_TTL value: %w", err)
+	}
+	config.InstanceTTL = instanceTTL
+
+	quotaRolloverPercent, err := strconv.Atoi(getEnvWithDefault("QUOTA_ROLLOVER_PERCENT", "0"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid QUOTA_ROLLOVER_PERCENT value: %w", err)
+	}
+	config.QuotaRolloverPercent = quotaRolloverPercent
+
+	alignWindows, err := strconv.ParseBool(getEnvWithDefault("ALIGN_WINDOWS", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ALIGN_WINDOWS value: %w", err)
+	}
+	config.AlignWindows = alignWindows
+
+	ipRefillRate, err := strconv.ParseFloat(getEnvWithDefault("IP_REFILL_RATE", "0"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid IP_REFILL_RATE value: %w", err)
+	}
+	config.IPRefillRate = ipRefillRate
+
+	tokenRefillRate, err := strconv.ParseFloat(getEnvWithDefault("TOKEN_REFILL_RATE", "0"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TOKEN_REFILL_RATE value: %w", err)
+	}
+	config.TokenRefillRate = tokenRefillRate
+
+	ipLeakRate, err := strconv.ParseFloat(getEnvWithDefault("IP_LEAK_RATE", "0"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid IP_LEAK_RATE value: %w", err)
+	}
+	config.IPLeakRate = ipLeakRate
+
+	tokenLeakRate, err := strconv.ParseFloat(getEnvWithDefault("TOKEN_LEAK_RATE", "0"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid TOKEN_LEAK_RATE value: %w", err)
+	}
+	config.TokenLeakRate = tokenLeakRate
+
+	anomalyDetection, err := strconv.ParseBool(getEnvWithDefault("ANOMALY_DETECTION", "false"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ANOMALY_DETECTION value: %w", err)
+	}
+	config.AnomalyDetection = anomalyDetection
+
+	anomalyInterval, err := strconv.Atoi(getEnvWithDefault("ANOMALY_INTERVAL", "10"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ANOMALY_INTERVAL value: %w", err)
+	}
+	config.AnomalyInterval = anomalyInterval
+
+	anomalyZThreshold, err := strconv.ParseFloat(getEnvWithDefault("ANOMALY_Z_THRESHOLD", "4"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ANOMALY_Z_THRESHOLD value: %w", err)
+	}
+	config.AnomalyZThreshold = anomalyZThreshold
+
+	anomalyMinRequests, err := strconv.Atoi(getEnvWithDefault("ANOMALY_MIN_REQUESTS", "20"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ANOMALY_MIN_REQUESTS value: %w", err)
+	}
+	config.AnomalyMinRequests = anomalyMinRequests
+
+	capacityPlanning, err := strconv.ParseBool(getEnvWithDefault("CAPACITY_PLANNING", "true"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid CAPACITY_PLANNING value: %w", err)
+	}
+	config.CapacityPlanning = capacityPlanning
+
+	config.LearningMode = strings.ToLower(getEnvWithDefault("LEARNING_MODE", "off"))
+
+	learningTrainingPeriod, err := strconv.Atoi(getEnvWithDefault("LEARNING_TRAINING_PERIOD", "86400"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid LEARNING_TRAINING_PERIOD value: %w", err)
+	}
+	config.LearningTrainingPeriod = learningTrainingPeriod
+
+	learningHeadroom, err := strconv.ParseFloat(getEnvWithDefault("LEARNING_HEADROOM", "1.5"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LEARNING_HEADROOM value: %w", err)
+	}
+	config.LearningHeadroom = learningHeadroom
+
+	learningMinFactor, err := strconv.ParseFloat(getEnvWithDefault("LEARNING_MIN_FACTOR", "0.25"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LEARNING_MIN_FACTOR value: %w", err)
+	}
+	config.LearningMinFactor = learningMinFactor
+
+	learningMaxFactor, err := strconv.ParseFloat(getEnvWithDefault("LEARNING_MAX_FACTOR", "1"), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid LEARNING_MAX_FACTOR value: %w", err)
+	}
+	config.LearningMaxFactor = learningMaxFactor
+
+	analyticsRetentionHours, err := strconv.Atoi(getEnvWithDefault("ANALYTICS_RETENTION_HOURS", "24"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ANALYTICS_RETENTION_HOURS value: %w", err)
+	}
+	config.AnalyticsRetentionHours = analyticsRetentionHours
+
+	statsRetentionMinutes, err := strconv.Atoi(getEnvWithDefault("STATS_RETENTION_MINUTES", "15"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid STATS_RETENTION_MINUTES value: %w", err)
+	}
+	config.StatsRetentionMinutes = statsRetentionMinutes
+
+	statsMaxKeys, err := strconv.Atoi(getEnvWithDefault("STATS_MAX_KEYS", "10000"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid STATS_MAX_KEYS value: %w", err)
+	}
+	config.StatsMaxKeys = statsMaxKeys
+
+	auditLogCapacity, err := strconv.Atoi(getEnvWithDefault("AUDIT_LOG_CAPACITY", "1000"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid AUDIT_LOG_CAPACITY value: %w", err)
+	}
+	config.AuditLogCapacity = auditLogCapacity
+
 	// Valida configurações obrigatórias
 	if err := c.validateConfig(config); err != nil {
 		return nil, fmt.Errorf("config validation failed: %w", err)
@@ -222,10 +1081,383 @@ func (c *ConfigLoader) validateConfig(config *Config) error {
 		return fmt.Errorf("BLOCK_DURATION must be greater than 0")
 	}
 
+	if config.IPRefillRate < 0 || config.TokenRefillRate < 0 {
+		return fmt.Errorf("IP_REFILL_RATE and TOKEN_REFILL_RATE must not be negative")
+	}
+
+	if config.IPLeakRate < 0 || config.TokenLeakRate < 0 {
+		return fmt.Errorf("IP_LEAK_RATE and TOKEN_LEAK_RATE must not be negative")
+	}
+
+	if config.IPRefillRate > 0 && config.IPLeakRate > 0 {
+		return fmt.Errorf("IP_REFILL_RATE and IP_LEAK_RATE are mutually exclusive")
+	}
+
+	if config.TokenRefillRate > 0 && config.TokenLeakRate > 0 {
+		return fmt.Errorf("TOKEN_REFILL_RATE and TOKEN_LEAK_RATE are mutually exclusive")
+	}
+
+	if config.MaxHeaderBytes < 0 || config.MaxBodyBytes < 0 {
+		return fmt.Errorf("MAX_HEADER_BYTES and MAX_BODY_BYTES must not be negative")
+	}
+
+	if config.WindowHistorySize < 0 || config.WindowHistorySize > 100 {
+		return fmt.Errorf("WINDOW_HISTORY_SIZE must be between 0 and 100")
+	}
+
+	if config.MemoryExpectedKeys < 0 {
+		return fmt.Errorf("MEMORY_EXPECTED_KEYS must be greater than or equal to 0")
+	}
+
+	if config.MemoryMaxKeys < 0 {
+		return fmt.Errorf("MEMORY_MAX_KEYS must be greater than or equal to 0")
+	}
+
+	if config.MemorySnapshotInterval < 0 {
+		return fmt.Errorf("MEMORY_SNAPSHOT_INTERVAL must not be negative")
+	}
+
+	if config.HybridSyncInterval < 0 {
+		return fmt.Errorf("HYBRID_SYNC_INTERVAL_MS must not be negative")
+	}
+
+	if config.HybridSyncBatchSize < 0 {
+		return fmt.Errorf("HYBRID_SYNC_BATCH_SIZE must not be negative")
+	}
+
+	if config.TieredCacheTTL < 0 || config.TieredCacheTTL > 10000 {
+		return fmt.Errorf("TIERED_CACHE_TTL_MS must be between 0 and 10000")
+	}
+
+	if config.BlockFilterExpectedKeys < 0 {
+		return fmt.Errorf("BLOCK_FILTER_EXPECTED_KEYS must not be negative")
+	}
+
+	if config.BlockFilterExpectedKeys > 0 && (config.BlockFilterPartitions < 1 || config.BlockFilterPartitions > 1024) {
+		return fmt.Errorf("BLOCK_FILTER_PARTITIONS must be between 1 and 1024")
+	}
+
+	if config.BlockFilterExpectedKeys > 0 && config.BlockFilterRefresh < 100 {
+		return fmt.Errorf("BLOCK_FILTER_REFRESH_MS must be at least 100")
+	}
+
 	if config.RedisDB < 0 || config.RedisDB > 15 {
 		return fmt.Errorf("REDIS_DB must be between 0 and 15")
 	}
 
+	for name, expr := range map[string]string{
+		"IP_RESET_SCHEDULE":    config.IPResetSchedule,
+		"TOKEN_RESET_SCHEDULE": config.TokenResetSchedule,
+	} {
+		if expr == "" {
+			continue
+		}
+		if _, err := schedule.Parse(expr); err != nil {
+			return fmt.Errorf("invalid %s: %w", name, err)
+		}
+	}
+
+	if !isValidStorageName(config.IPStorage) || !isValidStorageName(config.TokenStorage) {
+		return fmt.Errorf("IP_STORAGE and TOKEN_STORAGE must be 'memory' or 'redis'")
+	}
+
+	switch config.RedisMode {
+	case "", "standalone":
+	case "sentinel":
+		if config.RedisSentinelMaster == "" {
+			return fmt.Errorf("REDIS_SENTINEL_MASTER is required when REDIS_MODE is 'sentinel'")
+		}
+		if len(config.RedisSentinelAddrs) == 0 {
+			return fmt.Errorf("REDIS_SENTINEL_ADDRS is required when REDIS_MODE is 'sentinel'")
+		}
+		for _, addr := range config.RedisSentinelAddrs {
+			if _, _, err := net.SplitHostPort(addr); err != nil {
+				return fmt.Errorf("REDIS_SENTINEL_ADDRS must be host:port pairs, got: %s", addr)
+			}
+		}
+	default:
+		return fmt.Errorf("REDIS_MODE must be 'standalone' or 'sentinel'")
+	}
+
+	if !config.RedisTLSEnabled && (config.RedisTLSCAFile != "" || config.RedisTLSCertFile != "" || config.RedisTLSKeyFile != "" || config.RedisTLSInsecureSkipVerify) {
+		return fmt.Errorf("REDIS_TLS_* options require REDIS_TLS_ENABLED=true")
+	}
+	if (config.RedisTLSCertFile == "") != (config.RedisTLSKeyFile == "") {
+		return fmt.Errorf("REDIS_TLS_CERT_FILE and REDIS_TLS_KEY_FILE must be set together")
+	}
+
+	if config.RedisMaxRetries < 0 || config.RedisMaxRetries > 10 {
+		return fmt.Errorf("REDIS_MAX_RETRIES must be between 0 and 10")
+	}
+	if config.RedisMinRetryBackoff < 0 || config.RedisMaxRetryBackoff < 0 || config.RedisMaxRetryBackoff > 10000 {
+		return fmt.Errorf("REDIS_MIN_RETRY_BACKOFF_MS and REDIS_MAX_RETRY_BACKOFF_MS must be between 0 and 10000")
+	}
+	if config.RedisMaxRetryBackoff > 0 && config.RedisMaxRetryBackoff < config.RedisMinRetryBackoff {
+		return fmt.Errorf("REDIS_MAX_RETRY_BACKOFF_MS must not be below REDIS_MIN_RETRY_BACKOFF_MS")
+	}
+
+	for _, endpoint := range config.EtcdEndpoints {
+		if !isValidHTTPURL(endpoint) {
+			return fmt.Errorf("ETCD_ENDPOINTS must be absolute http(s) URLs, got: %s", endpoint)
+		}
+	}
+
+	switch config.RateLimitAlgorithm {
+	case "", "fixed_window":
+	case "sliding_log", "sliding_window":
+		// O hybrid e o etcd só suportam a janela fixa
+		for _, name := range []string{"hybrid", "etcd"} {
+			if config.IPStorage == name || config.TokenStorage == name {
+				return fmt.Errorf("RATE_LIMIT_ALGORITHM '%s' is not supported by the %s storage", config.RateLimitAlgorithm, name)
+			}
+		}
+	default:
+		return fmt.Errorf("RATE_LIMIT_ALGORITHM must be 'fixed_window', 'sliding_log' or 'sliding_window'")
+	}
+
+	switch config.TokenSource {
+	case "", "file":
+	case "sql":
+		if config.TokenDBDSN == "" {
+			return fmt.Errorf("TOKEN_DB_DSN is required when TOKEN_SOURCE is 'sql'")
+		}
+		if config.TokenDBTable != "" && !tableNamePattern.MatchString(config.TokenDBTable) {
+			return fmt.Errorf("invalid TOKEN_DB_TABLE: %s", config.TokenDBTable)
+		}
+	default:
+		return fmt.Errorf("TOKEN_SOURCE must be 'file' or 'sql'")
+	}
+
+	if config.TokenRefreshInterval < 0 {
+		return fmt.Errorf("TOKEN_REFRESH_INTERVAL must not be negative")
+	}
+
+	if config.TokenCacheTTL < 0 || config.TokenNegativeTTL < 0 {
+		return fmt.Errorf("TOKEN_CACHE_TTL and TOKEN_NEGATIVE_CACHE_TTL must not be negative")
+	}
+
+	if config.InstanceHeartbeatInterval < 0 || config.InstanceTTL < 0 {
+		return fmt.Errorf("INSTANCE_HEARTBEAT_INTERVAL and INSTANCE_TTL must not be negative")
+	}
+
+	if config.InstanceTTL > 0 && config.InstanceTTL <= config.InstanceHeartbeatInterval {
+		return fmt.Errorf("INSTANCE_TTL must be greater than INSTANCE_HEARTBEAT_INTERVAL")
+	}
+
+	if config.QuotaRolloverPercent < 0 || config.QuotaRolloverPercent > 100 {
+		return fmt.Errorf("QUOTA_ROLLOVER_PERCENT must be between 0 and 100")
+	}
+
+	if config.HealthCheckInterval < 0 || config.HealthCheckMaxBackoff < 0 {
+		return fmt.Errorf("HEALTH_CHECK_INTERVAL and HEALTH_CHECK_MAX_BACKOFF must not be negative")
+	}
+
+	if config.ReadinessHealthChecks < 0 {
+		return fmt.Errorf("READINESS_HEALTH_CHECKS must not be negative")
+	}
+
+	if config.HealthRecoveryChecks < 0 {
+		return fmt.Errorf("HEALTH_RECOVERY_CHECKS must not be negative")
+	}
+
+	if config.FailureMode != "" && config.FailureMode != "open" && config.FailureMode != "closed" {
+		return fmt.Errorf("FAILURE_MODE must be 'open' or 'closed'")
+	}
+
+	if config.DecisionTimeout < 0 {
+		return fmt.Errorf("DECISION_TIMEOUT_MS must not be negative")
+	}
+
+	if config.AnomalyDetection {
+		if config.AnomalyInterval <= 0 || config.AnomalyMinRequests <= 0 {
+			return fmt.Errorf("ANOMALY_INTERVAL and ANOMALY_MIN_REQUESTS must be greater than 0")
+		}
+		if config.AnomalyZThreshold <= 0 {
+			return fmt.Errorf("ANOMALY_Z_THRESHOLD must be greater than 0")
+		}
+	}
+
+	if config.EventsWebhookURL != "" && !isValidHTTPURL(config.EventsWebhookURL) {
+		return fmt.Errorf("EVENTS_WEBHOOK_URL must be an absolute http(s) URL")
+	}
+	if config.AuditLogCapacity < 0 || config.AuditLogCapacity > 100000 {
+		return fmt.Errorf("AUDIT_LOG_CAPACITY must be between 0 and 100000")
+	}
+	if config.AuditWebhookURL != "" && !isValidHTTPURL(config.AuditWebhookURL) {
+		return fmt.Errorf("AUDIT_WEBHOOK_URL must be an absolute http(s) URL")
+	}
+
+	if config.ProxyUpstreamURL != "" && !isValidHTTPURL(config.ProxyUpstreamURL) {
+		return fmt.Errorf("PROXY_UPSTREAM_URL must be an absolute http(s) URL")
+	}
+
+	if config.BlockEventsStreamMaxLen < 0 {
+		return fmt.Errorf("BLOCK_EVENTS_STREAM_MAXLEN must not be negative")
+	}
+
+	switch config.SecurityLogMinSeverity {
+	case "", "low", "medium", "high", "critical":
+	default:
+		return fmt.Errorf("SECURITY_LOG_MIN_SEVERITY must be 'low', 'medium', 'high' or 'critical'")
+	}
+
+	if config.SecurityRepeatBlocks < 0 || config.SecurityRepeatWindow < 0 {
+		return fmt.Errorf("SECURITY_REPEAT_BLOCKS and SECURITY_REPEAT_WINDOW must not be negative")
+	}
+
+	if config.SoftLimitWarningPercent < 0 || config.SoftLimitWarningPercent > 99 {
+		return fmt.Errorf("SOFT_LIMIT_WARNING_PERCENT must be between 0 and 99")
+	}
+
+	if config.AdminHMACSecret != "" && len(config.AdminHMACSecret) < minAdminHMACSecretLength {
+		return fmt.Errorf("ADMIN_HMAC_SECRET must have at least %d characters", minAdminHMACSecretLength)
+	}
+
+	if config.AdminSignatureMaxSkew < 0 {
+		return fmt.Errorf("ADMIN_SIGNATURE_MAX_SKEW must not be negative")
+	}
+
+	if _, err := adminauth.ParseKeys(config.AdminKeys); err != nil {
+		return fmt.Errorf("invalid ADMIN_KEYS: %w", err)
+	}
+
+	for _, group := range config.CompressionGroups {
+		switch strings.ToLower(group) {
+		case "admin", "public", "protected":
+		default:
+			return fmt.Errorf("COMPRESSION_GROUPS must only contain 'admin', 'public' or 'protected'")
+		}
+	}
+
+	if config.CompressionMinSize < 0 {
+		return fmt.Errorf("COMPRESSION_MIN_SIZE must not be negative")
+	}
+
+	if config.CompressionLevel < 0 || config.CompressionLevel > 9 {
+		return fmt.Errorf("COMPRESSION_LEVEL must be between 0 and 9")
+	}
+
+	if config.RateLimitedCacheTTL < 0 {
+		return fmt.Errorf("RATE_LIMITED_CACHE_TTL must not be negative")
+	}
+
+	// A resposta retida precisa sair antes do WriteTimeout do servidor (30s)
+	if config.TarpitMaxDelay < 0 || config.TarpitMaxDelay > 20000 {
+		return fmt.Errorf("TARPIT_MAX_DELAY_MS must be between 0 and 20000")
+	}
+	if config.TarpitBandPercent < 0 || config.TarpitBandPercent > 99 {
+		return fmt.Errorf("TARPIT_BAND_PERCENT must be between 0 and 99")
+	}
+	if config.TarpitBandPercent > 0 && config.TarpitMaxDelay == 0 {
+		return fmt.Errorf("TARPIT_BAND_PERCENT requires TARPIT_MAX_DELAY_MS")
+	}
+	if config.TarpitMaxConcurrent < 0 {
+		return fmt.Errorf("TARPIT_MAX_CONCURRENT must not be negative")
+	}
+
+	if config.ChallengeURL != "" {
+		if !isValidHTTPURL(config.ChallengeURL) && !strings.HasPrefix(config.ChallengeURL, "/") {
+			return fmt.Errorf("CHALLENGE_URL must be an absolute http(s) URL or a path")
+		}
+		if len(config.ChallengeSecret) < minAdminHMACSecretLength {
+			return fmt.Errorf("CHALLENGE_SECRET must have at least %d characters", minAdminHMACSecretLength)
+		}
+	}
+
+	if config.TracingEndpoint != "" && !isValidHTTPURL(config.TracingEndpoint) {
+		return fmt.Errorf("OTEL_EXPORTER_OTLP_ENDPOINT and OTEL_EXPORTER_OTLP_TRACES_ENDPOINT must be absolute http(s) URLs")
+	}
+	if config.TracingSampleRatio < 0 || config.TracingSampleRatio > 1 {
+		return fmt.Errorf("TRACING_SAMPLE_RATIO must be between 0 and 1")
+	}
+	if config.MetricsEndpoint != "" && !isValidHTTPURL(config.MetricsEndpoint) {
+		return fmt.Errorf("OTEL_EXPORTER_OTLP_ENDPOINT and OTEL_EXPORTER_OTLP_METRICS_ENDPOINT must be absolute http(s) URLs")
+	}
+	if config.MetricsEndpoint != "" && config.MetricsExportInterval <= 0 {
+		return fmt.Errorf("OTEL_METRIC_EXPORT_INTERVAL must be greater than 0")
+	}
+	if config.PprofAddr != "" {
+		if !config.PprofEnabled {
+			return fmt.Errorf("PPROF_ADDR requires PPROF_ENABLED=true")
+		}
+		if _, _, err := net.SplitHostPort(config.PprofAddr); err != nil {
+			return fmt.Errorf("PPROF_ADDR must be a host:port pair, got: %s", config.PprofAddr)
+		}
+	}
+
+	for _, name := range config.RateLimitedCacheHeaders {
+		if !headerNamePattern.MatchString(name) {
+			return fmt.Errorf("RATE_LIMITED_CACHE_HEADERS contains invalid header name %q", name)
+		}
+		canonical := http.CanonicalHeaderKey(name)
+		if canonical == "Cache-Control" || canonical == "Vary" || canonical == "Retry-After" ||
+			strings.HasPrefix(canonical, "X-Ratelimit-") {
+			return fmt.Errorf("RATE_LIMITED_CACHE_HEADERS must not contain %s", name)
+		}
+	}
+
+	for name, value := range map[string]string{
+		"IP_DOCS_URL":    config.IPDocsURL,
+		"TOKEN_DOCS_URL": config.TokenDocsURL,
+	} {
+		if value != "" && !isValidHTTPURL(value) {
+			return fmt.Errorf("%s must be an absolute http(s) URL", name)
+		}
+	}
+
+	if config.AnalyticsRetentionHours < 0 || config.AnalyticsRetentionHours > 168 {
+		return fmt.Errorf("ANALYTICS_RETENTION_HOURS must be between 0 and 168")
+	}
+
+	if !isValidStorageName(config.AnalyticsStorage) {
+		return fmt.Errorf("ANALYTICS_STORAGE must be 'memory' or 'redis'")
+	}
+
+	if config.StatsRetentionMinutes < 0 || config.StatsRetentionMinutes > 60 {
+		return fmt.Errorf("STATS_RETENTION_MINUTES must be between 0 and 60")
+	}
+	if config.StatsRetentionMinutes > 0 && config.StatsMaxKeys <= 0 {
+		return fmt.Errorf("STATS_MAX_KEYS must be greater than 0")
+	}
+
+	switch config.LearningMode {
+	case "", "off", "propose", "apply":
+	default:
+		return fmt.Errorf("LEARNING_MODE must be 'off', 'propose' or 'apply'")
+	}
+
+	if config.LearningMode == "propose" || config.LearningMode == "apply" {
+		if config.LearningTrainingPeriod <= 0 {
+			return fmt.Errorf("LEARNING_TRAINING_PERIOD must be greater than 0")
+		}
+		if config.LearningHeadroom <= 0 {
+			return fmt.Errorf("LEARNING_HEADROOM must be greater than 0")
+		}
+		if config.LearningMinFactor <= 0 || config.LearningMaxFactor < config.LearningMinFactor {
+			return fmt.Errorf("LEARNING_MIN_FACTOR must be greater than 0 and not above LEARNING_MAX_FACTOR")
+		}
+	}
+
+	if config.StorageEncryptionKey != "" && config.StorageEncryptionKeyFile != "" {
+		return fmt.Errorf("STORAGE_ENCRYPTION_KEY and STORAGE_ENCRYPTION_KEY_FILE are mutually exclusive")
+	}
+
+	for _, entry := range config.AllowlistIPs {
+		if net.ParseIP(entry) == nil {
+			if _, _, err := net.ParseCIDR(entry); err != nil {
+				return fmt.Errorf("ALLOWLIST_IPS contains invalid IP or CIDR: %s", entry)
+			}
+		}
+	}
+
+	for _, entry := range config.ProbeSourceRanges {
+		if net.ParseIP(entry) == nil {
+			if _, _, err := net.ParseCIDR(entry); err != nil {
+				return fmt.Errorf("PROBE_SOURCE_RANGES contains invalid IP or CIDR: %s", entry)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -243,4 +1475,75 @@ func getEnvWithDefault(key, defaultValue string) string {
 		return value
 	}
 	return defaultValue
-} 
\ No newline at end of file
+} 
+
+// getEnvList retorna a variável de ambiente como lista separada por vírgulas
+func getEnvList(key string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// isValidStorageName verifica se o nome corresponde a um backend suportado
+func isValidStorageName(name string) bool {
+	switch strings.ToLower(name) {
+	case "", "memory", "redis", "hybrid", "tiered", "etcd":
+		return true
+	default:
+		return false
+	}
+}
+
+// minAdminHMACSecretLength é o tamanho mínimo do segredo das requisições assinadas
+// e dos comprovantes de desafio
+const minAdminHMACSecretLength = 32
+
+// headerNamePattern aceita apenas nomes de header válidos (token RFC 7230)
+var headerNamePattern = regexp.MustCompile("^[A-Za-z0-9!#$%&'*+.^_`|~-]+$")
+
+// validateResponseHeaders rejeita nomes inválidos, valores com quebra de linha
+// e headers controlados pelo próprio rate limiter
+func validateResponseHeaders(headers map[string]string) error {
+	for name, value := range headers {
+		if !headerNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid header name %q", name)
+		}
+		if strings.ContainsAny(value, "\r\n\x00") {
+			return fmt.Errorf("invalid value for header %s", name)
+		}
+
+		canonical := http.CanonicalHeaderKey(name)
+		if strings.HasPrefix(canonical, "X-Ratelimit-") || canonical == "Retry-After" ||
+			canonical == "X-Request-Id" || canonical == "Link" {
+			return fmt.Errorf("header %s is reserved by the rate limiter", name)
+		}
+	}
+	return nil
+}
+
+// isValidHTTPURL verifica se o valor é uma URL http(s) absoluta
+func isValidHTTPURL(value string) bool {
+	parsed, err := url.Parse(value)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+// Fingerprint retorna uma impressão digital curta da configuração de rate limit
+// Réplicas com a mesma configuração carregada produzem o mesmo valor
+func Fingerprint(cfg *domain.RateLimitConfig) string {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:12]
+}
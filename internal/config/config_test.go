@@ -6,6 +6,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/domain"
 )
 
 func TestConfigLoader_LoadConfig(t *testing.T) {
@@ -69,6 +71,20 @@ func TestConfigLoader_LoadConfig(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "Invalid reset schedule",
+			envVars: map[string]string{
+				"TOKEN_RESET_SCHEDULE": "every day",
+			},
+			expectError: true,
+		},
+		{
+			name: "Invalid socket mode",
+			envVars: map[string]string{
+				"SERVER_SOCKET_MODE": "rwx",
+			},
+			expectError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -153,6 +169,97 @@ func TestConfigLoader_LoadTokenConfigs(t *testing.T) {
 	assert.Equal(t, "test-token-2", token2.Token)
 }
 
+func TestValidateTokenConfigs_DocsURL(t *testing.T) {
+	valid := map[string]domain.TokenConfig{
+		"search": {Limit: 10, BlockMessage: "Upgrade your search plan", DocsURL: "https://example.com/search/pricing"},
+	}
+	require.NoError(t, validateTokenConfigs(valid))
+	assert.Equal(t, "Upgrade your search plan", valid["search"].BlockMessage)
+	assert.Equal(t, "https://example.com/search/pricing", valid["search"].DocsURL)
+
+	invalid := map[string]domain.TokenConfig{
+		"search": {Limit: 10, DocsURL: "javascript:alert(1)"},
+	}
+	err := validateTokenConfigs(invalid)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid docs URL for token search")
+}
+
+func TestValidateTokenConfigs_TokenBucket(t *testing.T) {
+	valid := map[string]domain.TokenConfig{
+		"burst": {Limit: 100, Capacity: 10, RefillRate: 2},
+	}
+	require.NoError(t, validateTokenConfigs(valid))
+
+	invalid := map[string]domain.TokenConfig{
+		"burst": {Limit: 100, Capacity: 10, RefillRate: -1},
+	}
+	err := validateTokenConfigs(invalid)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid token bucket for token burst")
+}
+
+func TestValidateTokenConfigs_LeakyBucket(t *testing.T) {
+	valid := map[string]domain.TokenConfig{
+		"smooth": {Limit: 100, Capacity: 20, LeakRate: 5},
+	}
+	require.NoError(t, validateTokenConfigs(valid))
+
+	both := map[string]domain.TokenConfig{
+		"smooth": {Limit: 100, RefillRate: 2, LeakRate: 5},
+	}
+	err := validateTokenConfigs(both)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "refillRate and leakRate are mutually exclusive")
+}
+
+func TestValidateTokenConfigs_Webhook(t *testing.T) {
+	valid := map[string]domain.TokenConfig{
+		"acme": {Limit: 100, WebhookURL: "https://hooks.acme.com/usage", WebhookThreshold: 80},
+	}
+	require.NoError(t, validateTokenConfigs(valid))
+
+	relative := map[string]domain.TokenConfig{
+		"acme": {Limit: 100, WebhookURL: "/usage"},
+	}
+	err := validateTokenConfigs(relative)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid webhook URL for token acme")
+
+	threshold := map[string]domain.TokenConfig{
+		"acme": {Limit: 100, WebhookURL: "https://hooks.acme.com/usage", WebhookThreshold: 120},
+	}
+	err = validateTokenConfigs(threshold)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must be between 0 and 100")
+}
+
+func TestValidateTokenConfigs_Headers(t *testing.T) {
+	valid := map[string]domain.TokenConfig{
+		"enterprise": {Limit: 10, Headers: map[string]string{"X-Plan": "enterprise", "X-Quota-Policy": "https://example.com/quota"}},
+	}
+	require.NoError(t, validateTokenConfigs(valid))
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		message string
+	}{
+		{name: "Invalid name", headers: map[string]string{"X Plan": "enterprise"}, message: "invalid header name"},
+		{name: "Header injection", headers: map[string]string{"X-Plan": "enterprise\r\nSet-Cookie: a=b"}, message: "invalid value for header X-Plan"},
+		{name: "Reserved rate limit header", headers: map[string]string{"x-ratelimit-limit": "999999"}, message: "reserved by the rate limiter"},
+		{name: "Reserved Retry-After", headers: map[string]string{"Retry-After": "0"}, message: "reserved by the rate limiter"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateTokenConfigs(map[string]domain.TokenConfig{"token": {Limit: 10, Headers: tt.headers}})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
+
 func TestConfigLoader_LoadTokenConfigs_FileNotFound(t *testing.T) {
 	// Set non-existent file
 	os.Setenv("TOKEN_CONFIG_FILE", "/tmp/non_existent_tokens.json")
@@ -230,6 +337,532 @@ func TestConfigLoader_ValidateConfig(t *testing.T) {
 			expectError: true,
 			errorMsg:    "REDIS_DB must be between 0 and 15",
 		},
+		{
+			name: "Negative memory expected keys",
+			config: &Config{
+				DefaultIPLimit:     10,
+				DefaultTokenLimit:  100,
+				RateWindow:         60,
+				BlockDuration:      180,
+				MemoryExpectedKeys: -1,
+			},
+			expectError: true,
+			errorMsg:    "MEMORY_EXPECTED_KEYS must be greater than or equal to 0",
+		},
+		{
+			name: "Negative memory max keys",
+			config: &Config{
+				DefaultIPLimit:    10,
+				DefaultTokenLimit: 100,
+				RateWindow:        60,
+				BlockDuration:     180,
+				MemoryMaxKeys:     -1,
+			},
+			expectError: true,
+			errorMsg:    "MEMORY_MAX_KEYS must be greater than or equal to 0",
+		},
+		{
+			name: "Tarpit band without delay",
+			config: &Config{
+				DefaultIPLimit:    10,
+				DefaultTokenLimit: 100,
+				RateWindow:        60,
+				BlockDuration:     180,
+				TarpitBandPercent: 80,
+			},
+			expectError: true,
+			errorMsg:    "TARPIT_BAND_PERCENT requires TARPIT_MAX_DELAY_MS",
+		},
+		{
+			name: "Challenge without secret",
+			config: &Config{
+				DefaultIPLimit:    10,
+				DefaultTokenLimit: 100,
+				RateWindow:        60,
+				BlockDuration:     180,
+				ChallengeURL:      "/verify",
+				ChallengeSecret:   "short",
+			},
+			expectError: true,
+			errorMsg:    "CHALLENGE_SECRET must have at least 32 characters",
+		},
+		{
+			name: "Invalid tracing endpoint",
+			config: &Config{
+				DefaultIPLimit:    10,
+				DefaultTokenLimit: 100,
+				RateWindow:        60,
+				BlockDuration:     180,
+				TracingEndpoint:   "otel-collector:4318",
+			},
+			expectError: true,
+			errorMsg:    "OTEL_EXPORTER_OTLP_ENDPOINT and OTEL_EXPORTER_OTLP_TRACES_ENDPOINT must be absolute http(s) URLs",
+		},
+		{
+			name: "Tracing sample ratio above 1",
+			config: &Config{
+				DefaultIPLimit:     10,
+				DefaultTokenLimit:  100,
+				RateWindow:         60,
+				BlockDuration:      180,
+				TracingSampleRatio: 1.5,
+			},
+			expectError: true,
+			errorMsg:    "TRACING_SAMPLE_RATIO must be between 0 and 1",
+		},
+		{
+			name: "Metrics export interval not positive",
+			config: &Config{
+				DefaultIPLimit:    10,
+				DefaultTokenLimit: 100,
+				RateWindow:        60,
+				BlockDuration:     180,
+				MetricsEndpoint:   "http://otel-collector:4318/v1/metrics",
+			},
+			expectError: true,
+			errorMsg:    "OTEL_METRIC_EXPORT_INTERVAL must be greater than 0",
+		},
+		{
+			name: "Invalid audit webhook URL",
+			config: &Config{
+				DefaultIPLimit:    10,
+				DefaultTokenLimit: 100,
+				RateWindow:        60,
+				BlockDuration:     180,
+				AuditWebhookURL:   "audit.example.com/hook",
+			},
+			expectError: true,
+			errorMsg:    "AUDIT_WEBHOOK_URL must be an absolute http(s) URL",
+		},
+		{
+			name: "Stats retention above one hour",
+			config: &Config{
+				DefaultIPLimit:        10,
+				DefaultTokenLimit:     100,
+				RateWindow:            60,
+				BlockDuration:         180,
+				StatsRetentionMinutes: 120,
+			},
+			expectError: true,
+			errorMsg:    "STATS_RETENTION_MINUTES must be between 0 and 60",
+		},
+		{
+			name: "Pprof address without pprof enabled",
+			config: &Config{
+				DefaultIPLimit:    10,
+				DefaultTokenLimit: 100,
+				RateWindow:        60,
+				BlockDuration:     180,
+				PprofAddr:         "127.0.0.1:6060",
+			},
+			expectError: true,
+			errorMsg:    "PPROF_ADDR requires PPROF_ENABLED=true",
+		},
+		{
+			name: "Invalid pprof address",
+			config: &Config{
+				DefaultIPLimit:    10,
+				DefaultTokenLimit: 100,
+				RateWindow:        60,
+				BlockDuration:     180,
+				PprofEnabled:      true,
+				PprofAddr:         "6060",
+			},
+			expectError: true,
+			errorMsg:    "PPROF_ADDR must be a host:port pair",
+		},
+		{
+			name: "Invalid admin key role",
+			config: &Config{
+				DefaultIPLimit:    10,
+				DefaultTokenLimit: 100,
+				RateWindow:        60,
+				BlockDuration:     180,
+				AdminKeys:         "support:owner:sk_support",
+			},
+			expectError: true,
+			errorMsg:    "invalid ADMIN_KEYS",
+		},
+		{
+			name: "Window history too long",
+			config: &Config{
+				DefaultIPLimit:    10,
+				DefaultTokenLimit: 100,
+				RateWindow:        60,
+				BlockDuration:     180,
+				WindowHistorySize: 101,
+			},
+			expectError: true,
+			errorMsg:    "WINDOW_HISTORY_SIZE must be between 0 and 100",
+		},
+		{
+			name: "Negative hybrid sync interval",
+			config: &Config{
+				DefaultIPLimit:     10,
+				DefaultTokenLimit:  100,
+				RateWindow:         60,
+				BlockDuration:      180,
+				HybridSyncInterval: -1,
+			},
+			expectError: true,
+			errorMsg:    "HYBRID_SYNC_INTERVAL_MS must not be negative",
+		},
+		{
+			name: "Invalid allowlist entry",
+			config: &Config{
+				DefaultIPLimit:    10,
+				DefaultTokenLimit: 100,
+				RateWindow:        60,
+				BlockDuration:     180,
+				RedisDB:          0,
+				AllowlistIPs:      []string{"10.0.0.0/8", "not-an-ip"},
+			},
+			expectError: true,
+			errorMsg:    "ALLOWLIST_IPS contains invalid IP or CIDR",
+		},
+		{
+			name: "Invalid named storage",
+			config: &Config{
+				DefaultIPLimit:    10,
+				DefaultTokenLimit: 100,
+				RateWindow:        60,
+				BlockDuration:     180,
+				RedisDB:          0,
+				TokenStorage:      "cassandra",
+			},
+			expectError: true,
+			errorMsg:    "IP_STORAGE and TOKEN_STORAGE must be 'memory' or 'redis'",
+		},
+		{
+			name: "Instance TTL not greater than heartbeat",
+			config: &Config{
+				DefaultIPLimit:            10,
+				DefaultTokenLimit:         100,
+				RateWindow:                60,
+				BlockDuration:             180,
+				RedisDB:                  0,
+				InstanceHeartbeatInterval: 10,
+				InstanceTTL:               10,
+			},
+			expectError: true,
+			errorMsg:    "INSTANCE_TTL must be greater than INSTANCE_HEARTBEAT_INTERVAL",
+		},
+		{
+			name: "Anomaly detection without threshold",
+			config: &Config{
+				DefaultIPLimit:     10,
+				DefaultTokenLimit:  100,
+				RateWindow:         60,
+				BlockDuration:      180,
+				AnomalyDetection:   true,
+				AnomalyInterval:    10,
+				AnomalyMinRequests: 20,
+			},
+			expectError: true,
+			errorMsg:    "ANOMALY_Z_THRESHOLD must be greater than 0",
+		},
+		{
+			name: "Relative events webhook URL",
+			config: &Config{
+				DefaultIPLimit:    10,
+				DefaultTokenLimit: 100,
+				RateWindow:        60,
+				BlockDuration:     180,
+				EventsWebhookURL:  "/hooks/rate-limiter",
+			},
+			expectError: true,
+			errorMsg:    "EVENTS_WEBHOOK_URL must be an absolute http(s) URL",
+		},
+		{
+			name: "Invalid probe source range",
+			config: &Config{
+				DefaultIPLimit:    10,
+				DefaultTokenLimit: 100,
+				RateWindow:        60,
+				BlockDuration:     180,
+				ProbeSourceRanges: []string{"10.0.0.0/33"},
+			},
+			expectError: true,
+			errorMsg:    "PROBE_SOURCE_RANGES contains invalid IP or CIDR",
+		},
+		{
+			name: "Negative readiness health checks",
+			config: &Config{
+				DefaultIPLimit:        10,
+				DefaultTokenLimit:     100,
+				RateWindow:            60,
+				BlockDuration:         180,
+				ReadinessHealthChecks: -1,
+			},
+			expectError: true,
+			errorMsg:    "READINESS_HEALTH_CHECKS must not be negative",
+		},
+		{
+			name: "Relative token docs URL",
+			config: &Config{
+				DefaultIPLimit:    10,
+				DefaultTokenLimit: 100,
+				RateWindow:        60,
+				BlockDuration:     180,
+				TokenDocsURL:      "docs/limits",
+			},
+			expectError: true,
+			errorMsg:    "TOKEN_DOCS_URL must be an absolute http(s) URL",
+		},
+		{
+			name: "Invalid security log severity",
+			config: &Config{
+				DefaultIPLimit:         10,
+				DefaultTokenLimit:      100,
+				RateWindow:             60,
+				BlockDuration:          180,
+				SecurityLogMinSeverity: "urgent",
+			},
+			expectError: true,
+			errorMsg:    "SECURITY_LOG_MIN_SEVERITY must be 'low', 'medium', 'high' or 'critical'",
+		},
+		{
+			name: "Short admin HMAC secret",
+			config: &Config{
+				DefaultIPLimit:    10,
+				DefaultTokenLimit: 100,
+				RateWindow:        60,
+				BlockDuration:     180,
+				AdminHMACSecret:   "short-secret",
+			},
+			expectError: true,
+			errorMsg:    "ADMIN_HMAC_SECRET must have at least 32 characters",
+		},
+		{
+			name: "Invalid learning mode",
+			config: &Config{
+				DefaultIPLimit:    10,
+				DefaultTokenLimit: 100,
+				RateWindow:        60,
+				BlockDuration:     180,
+				LearningMode:      "auto",
+			},
+			expectError: true,
+			errorMsg:    "LEARNING_MODE must be 'off', 'propose' or 'apply'",
+		},
+		{
+			name: "Learning min factor above max factor",
+			config: &Config{
+				DefaultIPLimit:         10,
+				DefaultTokenLimit:      100,
+				RateWindow:             60,
+				BlockDuration:          180,
+				LearningMode:           "apply",
+				LearningTrainingPeriod: 3600,
+				LearningHeadroom:       1.5,
+				LearningMinFactor:      2,
+				LearningMaxFactor:      1,
+			},
+			expectError: true,
+			errorMsg:    "LEARNING_MIN_FACTOR must be greater than 0 and not above LEARNING_MAX_FACTOR",
+		},
+		{
+			name: "Storage encryption key and key file together",
+			config: &Config{
+				DefaultIPLimit:           10,
+				DefaultTokenLimit:        100,
+				RateWindow:               60,
+				BlockDuration:            180,
+				StorageEncryptionKey:     "MDEyMzQ1Njc4OWFiY2RlZg==",
+				StorageEncryptionKeyFile: "/run/secrets/storage-key",
+			},
+			expectError: true,
+			errorMsg:    "STORAGE_ENCRYPTION_KEY and STORAGE_ENCRYPTION_KEY_FILE are mutually exclusive",
+		},
+		{
+			name: "Invalid rate limit algorithm",
+			config: &Config{
+				DefaultIPLimit:     10,
+				DefaultTokenLimit:  100,
+				RateWindow:         60,
+				BlockDuration:      180,
+				RateLimitAlgorithm: "leaky",
+			},
+			expectError: true,
+			errorMsg:    "RATE_LIMIT_ALGORITHM must be 'fixed_window', 'sliding_log' or 'sliding_window'",
+		},
+		{
+			name: "Sliding log with hybrid storage",
+			config: &Config{
+				DefaultIPLimit:     10,
+				DefaultTokenLimit:  100,
+				RateWindow:         60,
+				BlockDuration:      180,
+				RateLimitAlgorithm: "sliding_log",
+				TokenStorage:       "hybrid",
+			},
+			expectError: true,
+			errorMsg:    "RATE_LIMIT_ALGORITHM 'sliding_log' is not supported by the hybrid storage",
+		},
+		{
+			name: "Sliding window with hybrid storage",
+			config: &Config{
+				DefaultIPLimit:     10,
+				DefaultTokenLimit:  100,
+				RateWindow:         60,
+				BlockDuration:      180,
+				RateLimitAlgorithm: "sliding_window",
+				IPStorage:          "hybrid",
+			},
+			expectError: true,
+			errorMsg:    "RATE_LIMIT_ALGORITHM 'sliding_window' is not supported by the hybrid storage",
+		},
+		{
+			name: "Sliding log with etcd storage",
+			config: &Config{
+				DefaultIPLimit:     10,
+				DefaultTokenLimit:  100,
+				RateWindow:         60,
+				BlockDuration:      180,
+				RateLimitAlgorithm: "sliding_log",
+				TokenStorage:       "etcd",
+			},
+			expectError: true,
+			errorMsg:    "RATE_LIMIT_ALGORITHM 'sliding_log' is not supported by the etcd storage",
+		},
+		{
+			name: "etcd endpoint without scheme",
+			config: &Config{
+				DefaultIPLimit:    10,
+				DefaultTokenLimit: 100,
+				RateWindow:        60,
+				BlockDuration:     180,
+				EtcdEndpoints:     []string{"etcd-0.etcd:2379"},
+			},
+			expectError: true,
+			errorMsg:    "ETCD_ENDPOINTS must be absolute http(s) URLs, got: etcd-0.etcd:2379",
+		},
+		{
+			name: "Redis sentinel without addresses",
+			config: &Config{
+				DefaultIPLimit:      10,
+				DefaultTokenLimit:   100,
+				RateWindow:          60,
+				BlockDuration:       180,
+				RedisMode:           "sentinel",
+				RedisSentinelMaster: "mymaster",
+			},
+			expectError: true,
+			errorMsg:    "REDIS_SENTINEL_ADDRS is required when REDIS_MODE is 'sentinel'",
+		},
+		{
+			name: "Unknown Redis mode",
+			config: &Config{
+				DefaultIPLimit:    10,
+				DefaultTokenLimit: 100,
+				RateWindow:        60,
+				BlockDuration:     180,
+				RedisMode:         "cluster",
+			},
+			expectError: true,
+			errorMsg:    "REDIS_MODE must be 'standalone' or 'sentinel'",
+		},
+		{
+			name: "Block filter without partitions",
+			config: &Config{
+				DefaultIPLimit:          10,
+				DefaultTokenLimit:       100,
+				RateWindow:              60,
+				BlockDuration:           180,
+				BlockFilterExpectedKeys: 100000,
+				BlockFilterRefresh:      1000,
+			},
+			expectError: true,
+			errorMsg:    "BLOCK_FILTER_PARTITIONS must be between 1 and 1024",
+		},
+		{
+			name: "Redis TLS files without TLS enabled",
+			config: &Config{
+				DefaultIPLimit:    10,
+				DefaultTokenLimit: 100,
+				RateWindow:        60,
+				BlockDuration:     180,
+				RedisTLSCAFile:    "/etc/redis/ca.pem",
+			},
+			expectError: true,
+			errorMsg:    "REDIS_TLS_* options require REDIS_TLS_ENABLED=true",
+		},
+		{
+			name: "Redis TLS client certificate without key",
+			config: &Config{
+				DefaultIPLimit:    10,
+				DefaultTokenLimit: 100,
+				RateWindow:        60,
+				BlockDuration:     180,
+				RedisTLSEnabled:   true,
+				RedisTLSCertFile:  "/etc/redis/client.pem",
+			},
+			expectError: true,
+			errorMsg:    "REDIS_TLS_CERT_FILE and REDIS_TLS_KEY_FILE must be set together",
+		},
+		{
+			name: "Redis retry backoff range inverted",
+			config: &Config{
+				DefaultIPLimit:       10,
+				DefaultTokenLimit:    100,
+				RateWindow:           60,
+				BlockDuration:        180,
+				RedisMinRetryBackoff: 100,
+				RedisMaxRetryBackoff: 50,
+			},
+			expectError: true,
+			errorMsg:    "REDIS_MAX_RETRY_BACKOFF_MS must not be below REDIS_MIN_RETRY_BACKOFF_MS",
+		},
+		{
+			name: "Negative memory snapshot interval",
+			config: &Config{
+				DefaultIPLimit:         10,
+				DefaultTokenLimit:      100,
+				RateWindow:             60,
+				BlockDuration:          180,
+				MemorySnapshotPath:     "/var/lib/rate-limiter/memory.snapshot",
+				MemorySnapshotInterval: -1,
+			},
+			expectError: true,
+			errorMsg:    "MEMORY_SNAPSHOT_INTERVAL must not be negative",
+		},
+		{
+			name: "Unknown compression group",
+			config: &Config{
+				DefaultIPLimit:    10,
+				DefaultTokenLimit: 100,
+				RateWindow:        60,
+				BlockDuration:     180,
+				CompressionGroups: []string{"admin", "metrics"},
+			},
+			expectError: true,
+			errorMsg:    "COMPRESSION_GROUPS must only contain 'admin', 'public' or 'protected'",
+		},
+		{
+			name: "Negative max body bytes",
+			config: &Config{
+				DefaultIPLimit:    10,
+				DefaultTokenLimit: 100,
+				RateWindow:        60,
+				BlockDuration:     180,
+				MaxBodyBytes:      -1,
+			},
+			expectError: true,
+			errorMsg:    "MAX_HEADER_BYTES and MAX_BODY_BYTES must not be negative",
+		},
+		{
+			name: "Rate limited cache overriding Cache-Control",
+			config: &Config{
+				DefaultIPLimit:          10,
+				DefaultTokenLimit:       100,
+				RateWindow:              60,
+				BlockDuration:           180,
+				RateLimitedCacheTTL:     10,
+				RateLimitedCacheHeaders: []string{"CDN-Cache-Control", "cache-control"},
+			},
+			expectError: true,
+			errorMsg:    "RATE_LIMITED_CACHE_HEADERS must not contain cache-control",
+		},
 	}
 
 	for _, tt := range tests {
@@ -246,6 +879,16 @@ func TestConfigLoader_ValidateConfig(t *testing.T) {
 	}
 }
 
+func TestFingerprint(t *testing.T) {
+	base := &domain.RateLimitConfig{DefaultIPLimit: 10, DefaultTokenLimit: 100, Window: 60, BlockDuration: 180}
+	same := &domain.RateLimitConfig{DefaultIPLimit: 10, DefaultTokenLimit: 100, Window: 60, BlockDuration: 180}
+	changed := &domain.RateLimitConfig{DefaultIPLimit: 20, DefaultTokenLimit: 100, Window: 60, BlockDuration: 180}
+
+	assert.Len(t, Fingerprint(base), 12)
+	assert.Equal(t, Fingerprint(base), Fingerprint(same))
+	assert.NotEqual(t, Fingerprint(base), Fingerprint(changed))
+}
+
 func TestGetEnvWithDefault(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -281,4 +924,70 @@ func TestGetEnvWithDefault(t *testing.T) {
 			assert.Equal(t, tt.expected, result)
 		})
 	}
-} 
\ No newline at end of file
+} 
+
+func TestGetEnvList(t *testing.T) {
+	os.Setenv("TEST_LIST_VAR", " 10.0.0.1, ,192.168.0.0/16 ")
+	defer os.Unsetenv("TEST_LIST_VAR")
+
+	assert.Equal(t, []string{"10.0.0.1", "192.168.0.0/16"}, getEnvList("TEST_LIST_VAR"))
+	assert.Nil(t, getEnvList("NON_EXISTENT_LIST_VAR"))
+}
+
+func TestValidateTokenConfigs_Family(t *testing.T) {
+	valid := map[string]domain.TokenConfig{
+		"acme-web":    {Limit: 100, Family: "acme"},
+		"acme-mobile": {Limit: 100, Family: "acme"},
+	}
+	require.NoError(t, validateTokenConfigs(valid))
+
+	mismatch := map[string]domain.TokenConfig{
+		"acme-web":    {Limit: 100, Family: "acme"},
+		"acme-mobile": {Limit: 50, Family: "acme"},
+	}
+	err := validateTokenConfigs(mismatch)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tokens of family acme must share limit")
+
+	invalidName := map[string]domain.TokenConfig{
+		"acme-web": {Limit: 100, Family: "acme:prod"},
+	}
+	err = validateTokenConfigs(invalidName)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid family for token acme-web")
+}
+
+func TestValidateLimitHierarchy(t *testing.T) {
+	valid := TokensFile{
+		Tokens:        map[string]domain.TokenConfig{"checkout-web": {Limit: 100, Project: "checkout"}},
+		Projects:      map[string]domain.ProjectConfig{"checkout": {Organization: "acme", Limit: 500}},
+		Organizations: map[string]domain.OrganizationConfig{"acme": {Limit: 1000}},
+	}
+	require.NoError(t, validateLimitHierarchy(valid))
+
+	unknownProject := TokensFile{
+		Tokens: map[string]domain.TokenConfig{"checkout-web": {Limit: 100, Project: "checkout"}},
+	}
+	err := validateLimitHierarchy(unknownProject)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "project checkout is not configured")
+
+	unknownOrganization := TokensFile{
+		Projects: map[string]domain.ProjectConfig{"checkout": {Organization: "acme", Limit: 500}},
+	}
+	err = validateLimitHierarchy(unknownOrganization)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "organization acme is not configured")
+
+	invalidLimit := TokensFile{
+		Organizations: map[string]domain.OrganizationConfig{"acme": {Limit: 0}},
+	}
+	err = validateLimitHierarchy(invalidLimit)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid limit for organization acme")
+
+	bucket := map[string]domain.TokenConfig{"checkout-web": {Limit: 100, Project: "checkout", RefillRate: 2}}
+	err = validateTokenConfigs(bucket)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid project for token checkout-web")
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"rate-limiter/internal/domain"
+)
+
+// MaintenanceFile representa a estrutura do arquivo de janelas de manutenção
+type MaintenanceFile struct {
+	Windows []domain.MaintenanceWindow `json:"windows"`
+}
+
+// LoadMaintenanceWindows lê as janelas declaradas no arquivo (caminho vazio = nenhuma)
+// A validação de cada janela fica a cargo do maintenance.Manager
+func LoadMaintenanceWindows(path string) ([]domain.MaintenanceWindow, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read maintenance file: %w", err)
+	}
+
+	var file MaintenanceFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse maintenance file: %w", err)
+	}
+
+	for i := range file.Windows {
+		file.Windows[i].Type = domain.LimiterType(strings.ToLower(strings.TrimSpace(string(file.Windows[i].Type))))
+	}
+
+	return file.Windows, nil
+}
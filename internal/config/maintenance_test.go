@@ -0,0 +1,58 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/domain"
+)
+
+func TestLoadMaintenanceWindows(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "maintenance.json")
+	data := `{
+		"windows": [
+			{
+				"id": "black-friday-deploy",
+				"start": "2024-11-29T02:00:00Z",
+				"end": "2024-11-29T04:00:00Z",
+				"type": "TOKEN",
+				"keys": ["partner-token"],
+				"factor": 2,
+				"reason": "Partner backfill"
+			}
+		]
+	}`
+	require.NoError(t, os.WriteFile(path, []byte(data), 0644))
+
+	// Act
+	windows, err := LoadMaintenanceWindows(path)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, windows, 1)
+	assert.Equal(t, "black-friday-deploy", windows[0].ID)
+	assert.Equal(t, domain.TokenLimiter, windows[0].Type)
+	assert.Equal(t, []string{"partner-token"}, windows[0].Keys)
+	assert.Equal(t, 2.0, windows[0].Factor)
+	assert.Equal(t, time.Date(2024, 11, 29, 4, 0, 0, 0, time.UTC), windows[0].End)
+}
+
+func TestLoadMaintenanceWindows_EmptyPathAndErrors(t *testing.T) {
+	windows, err := LoadMaintenanceWindows("")
+	assert.NoError(t, err)
+	assert.Nil(t, windows)
+
+	_, err = LoadMaintenanceWindows(filepath.Join(t.TempDir(), "missing.json"))
+	assert.ErrorContains(t, err, "failed to read maintenance file")
+
+	path := filepath.Join(t.TempDir(), "invalid.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"windows": [`), 0644))
+	_, err = LoadMaintenanceWindows(path)
+	assert.ErrorContains(t, err, "failed to parse maintenance file")
+}
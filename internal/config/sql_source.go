@@ -0,0 +1,249 @@
+package config
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"rate-limiter/internal/domain"
+)
+
+// DefaultTokenTable é a tabela padrão com as configurações de tokens
+const DefaultTokenTable = "rate_limit_tokens"
+
+// tableNamePattern restringe o nome da tabela (evita injeção na query)
+var tableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// SQLTokenSource carrega TokenConfig de uma tabela SQL com atualização periódica
+// Permite que o billing controle os limites sem exportar o tokens.json
+//
+// Colunas esperadas: token, request_limit, description, reset_schedule,
+// rollover_percent (nullable) e storage_backend
+type SQLTokenSource struct {
+	db          *sql.DB
+	query       string
+	lookupQuery string
+	logger      domain.Logger
+
+	mutex  sync.RWMutex
+	tokens map[string]domain.TokenConfig
+	onLoad func()
+
+	started  atomic.Bool
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// NewSQLTokenSource cria a fonte de tokens sobre uma conexão SQL
+// O driver define o placeholder das consultas por token ($1 no PostgreSQL, ? nos demais)
+func NewSQLTokenSource(db *sql.DB, driver, table string, logger domain.Logger) (*SQLTokenSource, error) {
+	if table == "" {
+		table = DefaultTokenTable
+	}
+	if !tableNamePattern.MatchString(table) {
+		return nil, fmt.Errorf("invalid token table name: %s", table)
+	}
+
+	placeholder := "?"
+	if driver == "postgres" || driver == "pgx" {
+		placeholder = "$1"
+	}
+
+	query := fmt.Sprintf(
+		"SELECT token, request_limit, description, reset_schedule, rollover_percent, storage_backend FROM %s",
+		table,
+	)
+
+	return &SQLTokenSource{
+		db:          db,
+		query:       query,
+		lookupQuery: query + " WHERE token = " + placeholder,
+		logger:      logger,
+		tokens:      make(map[string]domain.TokenConfig),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}, nil
+}
+
+// Load lê e valida todas as linhas da tabela
+func (s *SQLTokenSource) Load(ctx context.Context) (map[string]domain.TokenConfig, error) {
+	rows, err := s.db.QueryContext(ctx, s.query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query token configs: %w", err)
+	}
+	defer rows.Close()
+
+	tokens := make(map[string]domain.TokenConfig)
+	for rows.Next() {
+		config, err := scanTokenConfig(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens[config.Token] = config
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read token configs: %w", err)
+	}
+
+	if err := validateTokenConfigs(tokens); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// FetchTokenConfig consulta um único token (domain.TokenConfigFetcher)
+// Usado com o cache read-through quando a tabela é grande demais para snapshot
+func (s *SQLTokenSource) FetchTokenConfig(ctx context.Context, token string) (domain.TokenConfig, bool, error) {
+	rows, err := s.db.QueryContext(ctx, s.lookupQuery, token)
+	if err != nil {
+		return domain.TokenConfig{}, false, fmt.Errorf("failed to query token config: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return domain.TokenConfig{}, false, fmt.Errorf("failed to read token config: %w", err)
+		}
+		return domain.TokenConfig{}, false, nil
+	}
+
+	config, err := scanTokenConfig(rows)
+	if err != nil {
+		return domain.TokenConfig{}, false, err
+	}
+
+	tokens := map[string]domain.TokenConfig{config.Token: config}
+	if err := validateTokenConfigs(tokens); err != nil {
+		return domain.TokenConfig{}, false, err
+	}
+
+	return tokens[config.Token], true, nil
+}
+
+// scanTokenConfig converte a linha atual em TokenConfig
+func scanTokenConfig(rows *sql.Rows) (domain.TokenConfig, error) {
+	var (
+		token, description, resetSchedule, storage sql.NullString
+		limit                                      int
+		rolloverPercent                            sql.NullInt64
+	)
+	if err := rows.Scan(&token, &limit, &description, &resetSchedule, &rolloverPercent, &storage); err != nil {
+		return domain.TokenConfig{}, fmt.Errorf("failed to scan token config: %w", err)
+	}
+
+	config := domain.TokenConfig{
+		Token:         token.String,
+		Limit:         limit,
+		Description:   description.String,
+		ResetSchedule: resetSchedule.String,
+		Storage:       storage.String,
+	}
+	if rolloverPercent.Valid {
+		percent := int(rolloverPercent.Int64)
+		config.RolloverPercent = &percent
+	}
+	return config, nil
+}
+
+// LoadTokenConfigs carrega os tokens do banco e atualiza o snapshot em memória
+func (s *SQLTokenSource) LoadTokenConfigs() (map[string]domain.TokenConfig, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tokens, err := s.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	s.tokens = tokens
+	onLoad := s.onLoad
+	s.mutex.Unlock()
+
+	if onLoad != nil {
+		onLoad()
+	}
+	return tokens, nil
+}
+
+// OnLoad registra uma função chamada a cada carga bem-sucedida (ex: liberar a
+// readiness quando a carga inicial falhou)
+func (s *SQLTokenSource) OnLoad(fn func()) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.onLoad = fn
+}
+
+// TokenConfig retorna a configuração do token no último snapshot carregado
+// Nunca falha: a consulta ao banco ocorre apenas no refresh em background
+func (s *SQLTokenSource) TokenConfig(ctx context.Context, token string) (domain.TokenConfig, bool, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	config, exists := s.tokens[token]
+	return config, exists, nil
+}
+
+// Start inicia a atualização periódica em background
+// Em caso de falha o último snapshot válido é mantido
+func (s *SQLTokenSource) Start(interval time.Duration) {
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+	if s.started.CompareAndSwap(false, true) {
+		go s.run(interval)
+	}
+}
+
+// Stop encerra a atualização periódica
+func (s *SQLTokenSource) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.stop)
+	})
+	if s.started.Load() {
+		<-s.done
+	}
+}
+
+// run é o loop de atualização
+func (s *SQLTokenSource) run(interval time.Duration) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			s.refresh()
+		}
+	}
+}
+
+// refresh recarrega os tokens registrando o resultado
+func (s *SQLTokenSource) refresh() {
+	tokens, err := s.LoadTokenConfigs()
+	if s.logger == nil {
+		return
+	}
+
+	if err != nil {
+		s.logger.Warn("Failed to refresh token configs from database, keeping last snapshot", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	s.logger.Debug("Token configs refreshed from database", map[string]interface{}{
+		"tokens": len(tokens),
+	})
+}
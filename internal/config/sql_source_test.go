@@ -0,0 +1,146 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var tokenColumns = []string{"token", "request_limit", "description", "reset_schedule", "rollover_percent", "storage_backend"}
+
+func TestSQLTokenSource_LoadTokenConfigs(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	source, err := NewSQLTokenSource(db, "sqlmock", "", nil)
+	require.NoError(t, err)
+
+	mock.ExpectQuery("SELECT token, request_limit, description, reset_schedule, rollover_percent, storage_backend FROM rate_limit_tokens").
+		WillReturnRows(sqlmock.NewRows(tokenColumns).
+			AddRow("premium", 1000, "Premium plan", "@monthly", 25, "Redis").
+			AddRow("basic", 50, nil, nil, nil, nil))
+
+	// Act
+	tokens, err := source.LoadTokenConfigs()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Len(t, tokens, 2)
+
+	premium, exists, _ := source.TokenConfig(context.Background(), "premium")
+	require.True(t, exists)
+	assert.Equal(t, 1000, premium.Limit)
+	assert.Equal(t, "@monthly", premium.ResetSchedule)
+	assert.Equal(t, "redis", premium.Storage)
+	require.NotNil(t, premium.RolloverPercent)
+	assert.Equal(t, 25, *premium.RolloverPercent)
+
+	basic, exists, _ := source.TokenConfig(context.Background(), "basic")
+	require.True(t, exists)
+	assert.Nil(t, basic.RolloverPercent)
+
+	_, exists, _ = source.TokenConfig(context.Background(), "unknown")
+	assert.False(t, exists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLTokenSource_KeepsSnapshotOnFailure(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	source, err := NewSQLTokenSource(db, "sqlmock", "billing.tokens", nil)
+	require.NoError(t, err)
+
+	mock.ExpectQuery("FROM billing.tokens").
+		WillReturnRows(sqlmock.NewRows(tokenColumns).AddRow("premium", 1000, "", "", nil, ""))
+	mock.ExpectQuery("FROM billing.tokens").WillReturnError(errors.New("connection reset"))
+	mock.ExpectQuery("FROM billing.tokens").
+		WillReturnRows(sqlmock.NewRows(tokenColumns).AddRow("premium", 0, "", "", nil, ""))
+
+	_, err = source.LoadTokenConfigs()
+	require.NoError(t, err)
+
+	// Act - falha de conexão e linha inválida não substituem o snapshot
+	source.refresh()
+	_, err = source.Load(context.Background())
+
+	// Assert
+	assert.Error(t, err)
+	premium, exists, _ := source.TokenConfig(context.Background(), "premium")
+	assert.True(t, exists)
+	assert.Equal(t, 1000, premium.Limit)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSQLTokenSource_OnLoad(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	source, err := NewSQLTokenSource(db, "sqlmock", "", nil)
+	require.NoError(t, err)
+
+	mock.ExpectQuery("FROM rate_limit_tokens").WillReturnError(errors.New("connection refused"))
+	mock.ExpectQuery("FROM rate_limit_tokens").
+		WillReturnRows(sqlmock.NewRows(tokenColumns).AddRow("premium", 1000, "", "", nil, ""))
+
+	loads := 0
+	source.OnLoad(func() { loads++ })
+
+	// Act - só a carga bem-sucedida do refresh notifica
+	_, err = source.LoadTokenConfigs()
+	require.Error(t, err)
+	source.refresh()
+
+	// Assert
+	assert.Equal(t, 1, loads)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNewSQLTokenSource_InvalidTable(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = NewSQLTokenSource(db, "sqlmock", "tokens; DROP TABLE users", nil)
+	assert.Error(t, err)
+}
+
+func TestSQLTokenSource_FetchTokenConfig(t *testing.T) {
+	// Arrange
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	source, err := NewSQLTokenSource(db, "sqlmock", "", nil)
+	require.NoError(t, err)
+
+	mock.ExpectQuery(`FROM rate_limit_tokens WHERE token = \?`).
+		WithArgs("premium").
+		WillReturnRows(sqlmock.NewRows(tokenColumns).AddRow("premium", 1000, "Premium plan", nil, nil, "Memory"))
+	mock.ExpectQuery(`FROM rate_limit_tokens WHERE token = \?`).
+		WithArgs("unknown").
+		WillReturnRows(sqlmock.NewRows(tokenColumns))
+
+	// Act
+	premium, found, err := source.FetchTokenConfig(context.Background(), "premium")
+	require.NoError(t, err)
+	_, unknownFound, unknownErr := source.FetchTokenConfig(context.Background(), "unknown")
+
+	// Assert
+	assert.True(t, found)
+	assert.Equal(t, 1000, premium.Limit)
+	assert.Equal(t, "memory", premium.Storage)
+	assert.NoError(t, unknownErr)
+	assert.False(t, unknownFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+
+	"rate-limiter/internal/domain"
+)
+
+// StagedConfig descreve mudanças de limites a avaliar antes de aplicá-las
+// Campos omitidos mantêm o valor da configuração ativa; tokens e IPs são mesclados
+type StagedConfig struct {
+	DefaultIPLimit    *int                          `json:"defaultIpLimit,omitempty"`
+	DefaultTokenLimit *int                          `json:"defaultTokenLimit,omitempty"`
+	Window            *int                          `json:"window,omitempty"`
+	BlockDuration     *int                          `json:"blockDuration,omitempty"`
+	Tokens            map[string]domain.TokenConfig `json:"tokens,omitempty"`
+	IPs               map[string]domain.IPConfig    `json:"ips,omitempty"`
+}
+
+// LoadStagedConfig lê o arquivo de configuração staged e o aplica sobre a ativa
+func LoadStagedConfig(path string, active *domain.RateLimitConfig) (*domain.RateLimitConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read staged config file: %w", err)
+	}
+
+	var staged StagedConfig
+	if err := json.Unmarshal(data, &staged); err != nil {
+		return nil, fmt.Errorf("failed to parse staged config file: %w", err)
+	}
+
+	return staged.Apply(active)
+}
+
+// Apply gera a configuração resultante sem alterar a ativa
+func (s StagedConfig) Apply(active *domain.RateLimitConfig) (*domain.RateLimitConfig, error) {
+	result := *active
+	result.TokenConfigs = make(map[string]domain.TokenConfig, len(active.TokenConfigs)+len(s.Tokens))
+	for token, tokenConfig := range active.TokenConfigs {
+		result.TokenConfigs[token] = tokenConfig
+	}
+	if len(active.IPConfigs)+len(s.IPs) > 0 {
+		result.IPConfigs = make(map[string]domain.IPConfig, len(active.IPConfigs)+len(s.IPs))
+		for ip, ipConfig := range active.IPConfigs {
+			result.IPConfigs[ip] = ipConfig
+		}
+	}
+
+	if s.DefaultIPLimit != nil {
+		result.DefaultIPLimit = *s.DefaultIPLimit
+	}
+	if s.DefaultTokenLimit != nil {
+		result.DefaultTokenLimit = *s.DefaultTokenLimit
+	}
+	if s.Window != nil {
+		result.Window = *s.Window
+	}
+	if s.BlockDuration != nil {
+		result.BlockDuration = *s.BlockDuration
+	}
+
+	if result.DefaultIPLimit <= 0 || result.DefaultTokenLimit <= 0 {
+		return nil, fmt.Errorf("staged default limits must be greater than 0")
+	}
+	if result.Window <= 0 {
+		return nil, fmt.Errorf("staged window must be greater than 0")
+	}
+	if result.BlockDuration < 0 {
+		return nil, fmt.Errorf("staged block duration must not be negative")
+	}
+
+	if err := validateTokenConfigs(s.Tokens); err != nil {
+		return nil, err
+	}
+	for token, tokenConfig := range s.Tokens {
+		result.TokenConfigs[token] = tokenConfig
+	}
+	if err := validateIPConfigs(s.IPs); err != nil {
+		return nil, err
+	}
+	for ip, ipConfig := range s.IPs {
+		result.IPConfigs[ip] = ipConfig
+	}
+
+	return &result, nil
+}
+
+// validateIPConfigs valida as configurações específicas de IPs
+func validateIPConfigs(ips map[string]domain.IPConfig) error {
+	for ip, config := range ips {
+		if net.ParseIP(ip) == nil {
+			return fmt.Errorf("invalid IP %q: must be an IPv4 or IPv6 address", ip)
+		}
+		if config.IP != ip {
+			return fmt.Errorf("invalid IP config for %s: ip field must match the key", ip)
+		}
+		if config.Limit <= 0 {
+			return fmt.Errorf("invalid IP limit for %s: must be greater than 0", ip)
+		}
+	}
+	return nil
+}
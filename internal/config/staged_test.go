@@ -0,0 +1,55 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/domain"
+)
+
+func TestLoadStagedConfig(t *testing.T) {
+	// Arrange
+	active := &domain.RateLimitConfig{
+		DefaultIPLimit:    10,
+		DefaultTokenLimit: 100,
+		Window:            60,
+		BlockDuration:     180,
+		TokenConfigs: map[string]domain.TokenConfig{
+			"premium": {Token: "premium", Limit: 1000},
+			"basic":   {Token: "basic", Limit: 50},
+		},
+	}
+	path := filepath.Join(t.TempDir(), "staged.json")
+	data := `{"defaultIpLimit": 20, "tokens": {"basic": {"limit": 80}}}`
+	require.NoError(t, os.WriteFile(path, []byte(data), 0644))
+
+	// Act
+	staged, err := LoadStagedConfig(path, active)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 20, staged.DefaultIPLimit)
+	assert.Equal(t, 100, staged.DefaultTokenLimit)
+	assert.Equal(t, 80, staged.TokenConfigs["basic"].Limit)
+	assert.Equal(t, "basic", staged.TokenConfigs["basic"].Token)
+	assert.Equal(t, 1000, staged.TokenConfigs["premium"].Limit)
+
+	// Configuração ativa intacta
+	assert.Equal(t, 10, active.DefaultIPLimit)
+	assert.Equal(t, 50, active.TokenConfigs["basic"].Limit)
+}
+
+func TestStagedConfig_ApplyValidation(t *testing.T) {
+	active := &domain.RateLimitConfig{DefaultIPLimit: 10, DefaultTokenLimit: 100, Window: 60, BlockDuration: 180}
+	zero := 0
+
+	_, err := StagedConfig{DefaultIPLimit: &zero}.Apply(active)
+	assert.Error(t, err)
+
+	_, err = StagedConfig{Tokens: map[string]domain.TokenConfig{"bad": {Limit: -1}}}.Apply(active)
+	assert.Error(t, err)
+}
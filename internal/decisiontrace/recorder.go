@@ -0,0 +1,151 @@
+package decisiontrace
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"rate-limiter/internal/domain"
+)
+
+// DefaultMaxWatches limita as gravações simultâneas
+const DefaultMaxWatches = 16
+
+// ErrTooManyWatches indica que o limite de gravações simultâneas foi atingido
+var ErrTooManyWatches = errors.New("too many concurrent decision traces")
+
+// collector acumula as decisões pedidas por uma gravação
+type collector struct {
+	count  int
+	traces []domain.DecisionTrace
+	done   chan struct{}
+}
+
+// Recorder grava as próximas decisões completas de chaves específicas sob demanda.
+// Implementa domain.DecisionTracer; sem gravações ativas o custo por requisição
+// é uma leitura atômica
+type Recorder struct {
+	maxWatches int
+	active     atomic.Int32
+
+	mutex   sync.RWMutex
+	watches map[string][]*collector
+}
+
+// NewRecorder cria um gravador com limite de chaves gravadas simultaneamente
+func NewRecorder(maxWatches int) *Recorder {
+	if maxWatches <= 0 {
+		maxWatches = DefaultMaxWatches
+	}
+	return &Recorder{
+		maxWatches: maxWatches,
+		watches:    make(map[string][]*collector),
+	}
+}
+
+// Tracing implementa domain.DecisionTracer
+func (r *Recorder) Tracing(key string, limiterType domain.LimiterType) bool {
+	if r.active.Load() == 0 {
+		return false
+	}
+
+	r.mutex.RLock()
+	_, exists := r.watches[watchID(key, limiterType)]
+	r.mutex.RUnlock()
+	return exists
+}
+
+// RecordTrace implementa domain.DecisionTracer
+// A decisão é entregue a todas as gravações da chave que ainda não estão completas
+func (r *Recorder) RecordTrace(trace domain.DecisionTrace) {
+	if r.active.Load() == 0 {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, c := range r.watches[watchID(trace.Key, trace.Type)] {
+		if len(c.traces) >= c.count {
+			continue
+		}
+		c.traces = append(c.traces, trace)
+		if len(c.traces) == c.count {
+			close(c.done)
+		}
+	}
+}
+
+// Record grava as próximas count decisões da chave. Ao fim do prazo retorna as
+// decisões gravadas até então (possivelmente nenhuma)
+func (r *Recorder) Record(ctx context.Context, key string, limiterType domain.LimiterType, count int, timeout time.Duration) ([]domain.DecisionTrace, error) {
+	id := watchID(key, limiterType)
+	c, err := r.acquire(id, count)
+	if err != nil {
+		return nil, err
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-c.done:
+	case <-timer.C:
+	case <-ctx.Done():
+		r.release(id, c)
+		return nil, ctx.Err()
+	}
+
+	return r.release(id, c), nil
+}
+
+// acquire registra uma gravação da chave
+func (r *Recorder) acquire(id string, count int) (*collector, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	collectors, exists := r.watches[id]
+	if !exists && len(r.watches) >= r.maxWatches {
+		return nil, ErrTooManyWatches
+	}
+
+	c := &collector{
+		count:  count,
+		traces: make([]domain.DecisionTrace, 0, count),
+		done:   make(chan struct{}),
+	}
+	r.watches[id] = append(collectors, c)
+	if !exists {
+		r.active.Add(1)
+	}
+	return c, nil
+}
+
+// release remove a gravação e retorna as decisões gravadas; a chave deixa de ser
+// gravada quando a última gravação termina
+func (r *Recorder) release(id string, c *collector) []domain.DecisionTrace {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	collectors := r.watches[id]
+	for i, candidate := range collectors {
+		if candidate == c {
+			collectors = append(collectors[:i:i], collectors[i+1:]...)
+			break
+		}
+	}
+
+	if len(collectors) == 0 {
+		delete(r.watches, id)
+		r.active.Add(-1)
+	} else {
+		r.watches[id] = collectors
+	}
+	return c.traces
+}
+
+func watchID(key string, limiterType domain.LimiterType) string {
+	return string(limiterType) + ":" + key
+}
@@ -0,0 +1,78 @@
+package decisiontrace
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/domain"
+)
+
+// recordDuring grava n decisões da chave assim que a gravação começa
+func recordDuring(recorder *Recorder, key string, limiterType domain.LimiterType, n int) {
+	go func() {
+		for !recorder.Tracing(key, limiterType) {
+			time.Sleep(time.Millisecond)
+		}
+		for i := 0; i < n; i++ {
+			recorder.RecordTrace(domain.DecisionTrace{Key: key, Type: limiterType, Remaining: i})
+		}
+	}()
+}
+
+func TestRecorder_RecordReturnsTheNextDecisionsOfTheKey(t *testing.T) {
+	// Arrange
+	recorder := NewRecorder(0)
+	recorder.RecordTrace(domain.DecisionTrace{Key: "10.0.0.1", Type: domain.IPLimiter}) // antes da gravação: ignorada
+	recordDuring(recorder, "10.0.0.1", domain.IPLimiter, 5)
+
+	// Act
+	traces, err := recorder.Record(context.Background(), "10.0.0.1", domain.IPLimiter, 3, time.Second)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, traces, 3)
+	assert.Equal(t, []int{0, 1, 2}, []int{traces[0].Remaining, traces[1].Remaining, traces[2].Remaining})
+	assert.False(t, recorder.Tracing("10.0.0.1", domain.IPLimiter))
+	assert.Empty(t, recorder.watches)
+	assert.Equal(t, int32(0), recorder.active.Load())
+}
+
+func TestRecorder_RecordReturnsPartialTracesOnTimeout(t *testing.T) {
+	// Arrange
+	recorder := NewRecorder(0)
+	recordDuring(recorder, "10.0.0.1", domain.IPLimiter, 2)
+	recordDuring(recorder, "10.0.0.1", domain.TokenLimiter, 5)
+
+	// Act
+	traces, err := recorder.Record(context.Background(), "10.0.0.1", domain.IPLimiter, 10, 100*time.Millisecond)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Len(t, traces, 2)
+	assert.Empty(t, recorder.watches)
+}
+
+func TestRecorder_RejectsWhenWatchLimitReached(t *testing.T) {
+	// Arrange
+	recorder := NewRecorder(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := recorder.Record(ctx, "a", domain.IPLimiter, 1, time.Minute)
+		done <- err
+	}()
+	require.Eventually(t, func() bool { return recorder.Tracing("a", domain.IPLimiter) }, time.Second, time.Millisecond)
+
+	// Act
+	_, err := recorder.Record(context.Background(), "b", domain.IPLimiter, 1, time.Millisecond)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrTooManyWatches)
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.Empty(t, recorder.watches)
+}
@@ -12,49 +12,380 @@ const (
 
 // RateLimitRule define as regras de rate limiting
 type RateLimitRule struct {
-	ID            string      `json:"id"`
-	Type          LimiterType `json:"type"`
-	Key           string      `json:"key"` // IP ou Token
-	Limit         int         `json:"limit"`
-	Window        int         `json:"window"`        // Janela em segundos
-	BlockDuration int         `json:"blockDuration"` // Duração do bloqueio em segundos
-	Description   string      `json:"description"`
+	ID               string            `json:"id"`
+	Type             LimiterType       `json:"type"`
+	Key              string            `json:"key"` // IP ou Token
+	Limit            int               `json:"limit"`
+	Window           int               `json:"window"`        // Janela em segundos
+	BlockDuration    int               `json:"blockDuration"` // Duração do bloqueio em segundos
+	Description      string            `json:"description"`
+	ResetSchedule    string            `json:"resetSchedule,omitempty"`    // Expressão cron para reset em horário fixo
+	AlignWindow      bool              `json:"alignWindow,omitempty"`      // Janela fixa alinhada ao relógio em vez da primeira requisição
+	RolloverPercent  int               `json:"rolloverPercent,omitempty"`  // % da cota não usada levada ao próximo período
+	Storage          string            `json:"storage,omitempty"`          // Backend nomeado (vazio = padrão)
+	Disabled         bool              `json:"disabled,omitempty"`         // Rate limiting suspenso (ex: manutenção)
+	Rollout          string            `json:"rollout,omitempty"`          // Rollout canário que cobre a regra
+	Version          string            `json:"version,omitempty"`          // Versão aplicada (stable ou canary)
+	BlockMessage     string            `json:"blockMessage,omitempty"`     // Mensagem da resposta 429 (vazio = padrão)
+	DocsURL          string            `json:"docsUrl,omitempty"`          // Página de upgrade/documentação citada no 429
+	Headers          map[string]string `json:"headers,omitempty"`          // Headers extras definidos pelo token
+	RefillRate       float64           `json:"refillRate,omitempty"`       // Fichas por segundo; > 0 aplica token bucket com capacidade Limit
+	LeakRate         float64           `json:"leakRate,omitempty"`         // Requisições escoadas por segundo; > 0 aplica leaky bucket com capacidade Limit
+	WebhookURL       string            `json:"webhookUrl,omitempty"`       // Webhook de uso do dono do token
+	WebhookThreshold int               `json:"webhookThreshold,omitempty"` // % do limite que dispara o webhook
+	Family           string            `json:"family,omitempty"`           // Família cujo contador é compartilhado pela chave
+	Parents          []LimitLevel      `json:"parents,omitempty"`          // Orçamentos acima da chave (projeto, organização)
+}
+
+// Níveis da hierarquia de limites acima do token
+const (
+	LimitLevelProject      = "project"
+	LimitLevelOrganization = "organization"
+)
+
+// LimitLevel é um orçamento acima do token na hierarquia, dividido com outros tokens
+type LimitLevel struct {
+	Level string `json:"level"` // LimitLevelProject ou LimitLevelOrganization
+	Name  string `json:"name"`
+	Limit int    `json:"limit"`
+}
+
+// ClientLimit é um dos limites aplicados ao cliente e o uso atual dele, como
+// mostrado em /me/limits. Scope é "token", "family" ou o nível da hierarquia
+// ("project:<nome>", "organization:<nome>"), como no header de escopo
+type ClientLimit struct {
+	Scope  string
+	Rule   *RateLimitRule
+	Status *RateLimitStatus // nil = sem uso registrado
+}
+
+// HierarchyCounter é o contador de um nível da hierarquia no storage
+type HierarchyCounter struct {
+	Key   string
+	Limit int
+}
+
+// HierarchyResult é o estado dos contadores após o incremento da hierarquia
+type HierarchyResult struct {
+	Counts   []int       // Contagem de cada nível, na ordem dos contadores
+	ResetAt  []time.Time // Fim da janela de cada nível
+	Exceeded int         // Primeiro nível sem espaço (-1 = todos incrementados)
+}
+
+// LimitCheck é o resultado da verificação atômica de uma chave
+type LimitCheck struct {
+	Blocked      bool       // Chave já bloqueada: a requisição não foi contada
+	Count        int        // Contagem da janela com a requisição (0 quando Blocked)
+	WindowStart  time.Time  // Início da janela atual
+	BlockedUntil *time.Time // Fim do bloqueio vigente ou aplicado nesta requisição
+}
+
+// Versões de uma regra durante um rollout canário
+const (
+	RuleVersionStable = "stable"
+	RuleVersionCanary = "canary"
+)
+
+// RuleRollout aplica uma nova versão de regra a uma fração do tráfego
+// A fração é escolhida por hash da chave, então cada cliente vê sempre a mesma versão
+type RuleRollout struct {
+	ID        string      `json:"id"`
+	Type      LimiterType `json:"type"`
+	Key       string      `json:"key,omitempty"` // Vazio = todas as chaves do tipo
+	Limit     int         `json:"limit"`
+	Percent   int         `json:"percent"` // 0-100 do tráfego na nova versão
+	Promoted  bool        `json:"promoted"`
+	CreatedAt time.Time   `json:"createdAt"`
+	UpdatedAt time.Time   `json:"updatedAt"`
 }
 
 // RateLimitStatus representa o status atual de um rate limit
 type RateLimitStatus struct {
-	Key         string    `json:"key"`
-	Type        LimiterType `json:"type"`
-	Count       int       `json:"count"`
-	Limit       int       `json:"limit"`
-	Window      int       `json:"window"`
-	LastReset   time.Time `json:"lastReset"`
-	BlockedUntil *time.Time `json:"blockedUntil,omitempty"`
-	IsBlocked   bool      `json:"isBlocked"`
+	Key          string      `json:"key"`
+	Type         LimiterType `json:"type"`
+	Count        int         `json:"count"`
+	Limit        int         `json:"limit"`
+	Window       int         `json:"window"`
+	LastReset    time.Time   `json:"lastReset"`
+	BlockedUntil *time.Time  `json:"blockedUntil,omitempty"`
+	IsBlocked    bool        `json:"isBlocked"`
+	ResetAt      *time.Time  `json:"resetAt,omitempty"`   // Reset absoluto (cotas agendadas)
+	Credit       int         `json:"credit,omitempty"`    // Crédito herdado do período anterior
+	ReleaseAt    *time.Time  `json:"releaseAt,omitempty"` // Saída da requisição do leaky bucket
+}
+
+// EffectiveLimit retorna o limite considerando o crédito acumulado
+func (s *RateLimitStatus) EffectiveLimit() int {
+	return s.Limit + s.Credit
 }
 
 // RateLimitResult representa o resultado de uma verificação de rate limit
 type RateLimitResult struct {
-	Allowed      bool          `json:"allowed"`
-	Limit        int           `json:"limit"`
-	Remaining    int           `json:"remaining"`
-	ResetTime    time.Time     `json:"resetTime"`
-	BlockedUntil *time.Time    `json:"blockedUntil,omitempty"`
-	LimiterType  LimiterType   `json:"limiterType"`
+	Allowed      bool              `json:"allowed"`
+	Limit        int               `json:"limit"`
+	Remaining    int               `json:"remaining"`
+	ResetTime    time.Time         `json:"resetTime"`
+	BlockedUntil *time.Time        `json:"blockedUntil,omitempty"`
+	LimiterType  LimiterType       `json:"limiterType"`
+	Message      string            `json:"message,omitempty"`   // Mensagem de bloqueio da regra (apenas quando negado)
+	DocsURL      string            `json:"docsUrl,omitempty"`   // Documentação da regra (apenas quando negado)
+	Headers      map[string]string `json:"headers,omitempty"`   // Headers extras da regra, enviados com os de rate limit
+	ReleaseAt    *time.Time        `json:"releaseAt,omitempty"` // Leaky bucket: a requisição aguarda até este instante
+	Scope        string            `json:"scope,omitempty"`     // Hierarquia: nível mais restrito (token, project:<nome>, organization:<nome>)
 }
 
 // TokenConfig representa a configuração de um token específico
 type TokenConfig struct {
-	Token       string `json:"token"`
+	Token            string            `json:"token"`
+	Limit            int               `json:"limit"`
+	Description      string            `json:"description"`
+	ResetSchedule    string            `json:"resetSchedule,omitempty"`    // Expressão cron (ex: "@daily")
+	RolloverPercent  *int              `json:"rolloverPercent,omitempty"`  // Sobrescreve o rollover padrão
+	Storage          string            `json:"storage,omitempty"`          // Backend nomeado (ex: "memory", "redis")
+	BlockMessage     string            `json:"blockMessage,omitempty"`     // Mensagem da resposta 429 para o token
+	DocsURL          string            `json:"docsUrl,omitempty"`          // Página de upgrade/documentação do produto
+	Headers          map[string]string `json:"headers,omitempty"`          // Headers extras nas respostas (ex: X-Plan)
+	Capacity         int               `json:"capacity,omitempty"`         // Tamanho do token/leaky bucket (0 = Limit)
+	RefillRate       float64           `json:"refillRate,omitempty"`       // Fichas repostas por segundo (0 = janela)
+	LeakRate         float64           `json:"leakRate,omitempty"`         // Requisições escoadas por segundo (0 = janela)
+	WebhookURL       string            `json:"webhookUrl,omitempty"`       // Notificado quando o uso cruza WebhookThreshold
+	WebhookThreshold int               `json:"webhookThreshold,omitempty"` // % do limite que dispara o webhook (0 = 90)
+	Family           string            `json:"family,omitempty"`           // Família de tokens com um único contador (ex: chaves de um mesmo contrato)
+	Project          string            `json:"project,omitempty"`          // Projeto cujo orçamento (e o da organização dele) também é consumido
+}
+
+// IPConfig representa a configuração específica de um IP, criada em tempo de execução
+type IPConfig struct {
+	IP          string `json:"ip"`
+	Limit       int    `json:"limit"`
+	Description string `json:"description,omitempty"`
+}
+
+// ProjectConfig é o orçamento de um projeto, consumido por todos os seus tokens
+type ProjectConfig struct {
+	Organization string `json:"organization,omitempty"` // Organização cujo orçamento também é consumido
+	Limit        int    `json:"limit"`
+	Description  string `json:"description,omitempty"`
+}
+
+// OrganizationConfig é o orçamento de uma organização, consumido por todos os seus projetos
+type OrganizationConfig struct {
 	Limit       int    `json:"limit"`
-	Description string `json:"description"`
+	Description string `json:"description,omitempty"`
 }
 
 // RateLimitConfig representa todas as configurações do rate limiter
 type RateLimitConfig struct {
 	DefaultIPLimit    int                    `json:"defaultIpLimit"`
 	DefaultTokenLimit int                    `json:"defaultTokenLimit"`
-	Window           int                    `json:"window"`
-	BlockDuration    int                    `json:"blockDuration"`
-	TokenConfigs     map[string]TokenConfig `json:"tokenConfigs"`
-} 
\ No newline at end of file
+	Window            int                    `json:"window"`
+	BlockDuration     int                    `json:"blockDuration"`
+	TokenConfigs      map[string]TokenConfig `json:"tokenConfigs"`
+
+	// Limites específicos por IP (regras criadas via /admin/rules)
+	IPConfigs map[string]IPConfig `json:"ipConfigs,omitempty"`
+
+	// Hierarquia de limites: orçamentos de projetos e organizações acima dos tokens
+	Projects      map[string]ProjectConfig      `json:"projects,omitempty"`
+	Organizations map[string]OrganizationConfig `json:"organizations,omitempty"`
+
+	// Resets agendados (cron) padrão; vazio mantém a janela deslizante
+	IPResetSchedule    string `json:"ipResetSchedule,omitempty"`
+	TokenResetSchedule string `json:"tokenResetSchedule,omitempty"`
+
+	// Percentual da cota agendada não utilizada levado ao próximo período
+	QuotaRolloverPercent int `json:"quotaRolloverPercent,omitempty"`
+
+	// Janelas alinhadas ao relógio (ex: :00 de cada minuto) em vez da primeira requisição
+	AlignWindows bool `json:"alignWindows,omitempty"`
+
+	// Token bucket padrão por tipo (fichas por segundo; 0 mantém a janela)
+	IPRefillRate    float64 `json:"ipRefillRate,omitempty"`
+	TokenRefillRate float64 `json:"tokenRefillRate,omitempty"`
+
+	// Leaky bucket padrão por tipo (requisições escoadas por segundo; 0 mantém a janela)
+	IPLeakRate    float64 `json:"ipLeakRate,omitempty"`
+	TokenLeakRate float64 `json:"tokenLeakRate,omitempty"`
+
+	// Backends nomeados padrão por tipo; vazio usa o storage principal
+	IPStorage    string `json:"ipStorage,omitempty"`
+	TokenStorage string `json:"tokenStorage,omitempty"`
+
+	// Mensagem e documentação padrão das respostas 429 por tipo; vazio usa a mensagem padrão
+	IPBlockMessage    string `json:"ipBlockMessage,omitempty"`
+	IPDocsURL         string `json:"ipDocsUrl,omitempty"`
+	TokenBlockMessage string `json:"tokenBlockMessage,omitempty"`
+	TokenDocsURL      string `json:"tokenDocsUrl,omitempty"`
+}
+
+// LimitOverride representa um limite temporário aplicado a uma chave até a expiração
+type LimitOverride struct {
+	Key       string      `json:"key"`
+	Type      LimiterType `json:"type"`
+	Limit     int         `json:"limit"`
+	ExpiresAt time.Time   `json:"expiresAt"`
+}
+
+// MaintenanceWindow relaxa ou desativa limites durante uma manutenção declarada
+// Type e Keys vazios aplicam a janela a todas as regras
+type MaintenanceWindow struct {
+	ID       string      `json:"id"`
+	Start    time.Time   `json:"start"`
+	End      time.Time   `json:"end"`
+	Type     LimiterType `json:"type,omitempty"`
+	Keys     []string    `json:"keys,omitempty"`
+	Factor   float64     `json:"factor,omitempty"`   // Multiplicador aplicado ao limite
+	Disabled bool        `json:"disabled,omitempty"` // Suspende o rate limiting
+	Reason   string      `json:"reason,omitempty"`
+}
+
+// IsActive informa se a janela está em vigor no instante informado
+func (w MaintenanceWindow) IsActive(now time.Time) bool {
+	return !now.Before(w.Start) && now.Before(w.End)
+}
+
+// Matches informa se a janela se aplica à chave e ao tipo de limiter
+func (w MaintenanceWindow) Matches(key string, limiterType LimiterType) bool {
+	if w.Type != "" && w.Type != limiterType {
+		return false
+	}
+	if len(w.Keys) == 0 {
+		return true
+	}
+	for _, candidate := range w.Keys {
+		if candidate == key {
+			return true
+		}
+	}
+	return false
+}
+
+// WindowCount é a contagem de uma janela de rate limit de uma chave
+type WindowCount struct {
+	Start time.Time `json:"start"`
+	Count int       `json:"count"`
+	Limit int       `json:"limit"`
+}
+
+// StorageRecord é o registro de uma chave exatamente como está no storage
+// Usado para diagnosticar divergências entre o estado salvo e o que a API reporta
+type StorageRecord struct {
+	Backend      string           `json:"backend"`
+	StorageKey   string           `json:"storageKey"`
+	Exists       bool             `json:"exists"`
+	Raw          string           `json:"raw,omitempty"`          // Valor salvo, sem interpretação
+	Status       *RateLimitStatus `json:"status,omitempty"`       // Raw decodificado; nil se ausente ou ilegível
+	DecodeError  string           `json:"decodeError,omitempty"`  // Motivo de Raw não ser decodificável
+	TTL          *time.Duration   `json:"ttl,omitempty"`          // nil = sem expiração ou não rastreada
+	BlockedUntil *time.Time       `json:"blockedUntil,omitempty"` // Entrada de bloqueio separada do status, se houver
+}
+
+// TrackedKey é uma chave com estado no storage, como listada pela API administrativa
+type TrackedKey struct {
+	Key          string      `json:"key"`
+	Type         LimiterType `json:"type"`
+	Count        int         `json:"count"`
+	Limit        int         `json:"limit"`
+	Remaining    int         `json:"remaining"`
+	IsBlocked    bool        `json:"isBlocked"`
+	BlockedUntil *time.Time  `json:"blockedUntil,omitempty"`
+	ResetAt      *time.Time  `json:"resetAt,omitempty"`
+}
+
+// StateEntry é o estado de uma chave exportado por /admin/export e carregado por
+// /admin/import. O identificador vai em claro para valer em outra instância, com
+// outras opções de chave (hash tag, criptografia)
+type StateEntry struct {
+	Key          string      `json:"key"`
+	Type         LimiterType `json:"type"`
+	Count        int         `json:"count"`
+	Limit        int         `json:"limit,omitempty"`
+	Window       int         `json:"window,omitempty"` // Segundos
+	Credit       int         `json:"credit,omitempty"`
+	LastReset    time.Time   `json:"lastReset"`
+	ResetAt      *time.Time  `json:"resetAt,omitempty"`
+	BlockedUntil *time.Time  `json:"blockedUntil,omitempty"`
+}
+
+// Invalidation pede que todas as instâncias descartem o estado local de uma chave
+type Invalidation struct {
+	Key     string      `json:"key"`
+	Type    LimiterType `json:"type"`
+	Reason  string      `json:"reason,omitempty"`  // Ação administrativa de origem (ex: reset)
+	Origin  string      `json:"origin,omitempty"`  // Instância que publicou
+	Pattern bool        `json:"pattern,omitempty"` // Key é um glob de identificadores (reset em massa)
+}
+
+// BlockRecord registra um bloqueio aplicado a uma chave que excedeu o limite
+type BlockRecord struct {
+	Key          string      `json:"key"`
+	Type         LimiterType `json:"type"`
+	Rule         string      `json:"rule"`
+	Limit        int         `json:"limit"`
+	RequestCount int         `json:"requestCount"` // Requisições contadas na janela ao bloquear
+	Rejected     int         `json:"rejected"`     // Requisições recusadas durante o bloqueio
+	BlockedAt    time.Time   `json:"blockedAt"`
+	Duration     int         `json:"duration"` // Duração do bloqueio em segundos
+}
+
+// BlockedUntil retorna o fim do bloqueio
+func (r BlockRecord) BlockedUntil() time.Time {
+	return r.BlockedAt.Add(time.Duration(r.Duration) * time.Second)
+}
+
+// QuotaPeriod descreve um período de cota que termina em um instante absoluto
+type QuotaPeriod struct {
+	Limit   int       `json:"limit"`
+	ResetAt time.Time `json:"resetAt"`
+
+	// RolloverPercent define quanto da cota não usada (0-100) vira crédito no próximo período
+	RolloverPercent int `json:"rolloverPercent,omitempty"`
+}
+
+// TokenBucket define o balde de fichas de uma chave: até Capacity requisições
+// em rajada, repostas continuamente à taxa RefillRate
+type TokenBucket struct {
+	Capacity   int     `json:"capacity"`
+	RefillRate float64 `json:"refillRate"` // fichas por segundo
+}
+
+// LeakyBucket define o balde que enfileira até Capacity requisições de uma chave
+// e as libera a taxa constante LeakRate; requisições que transbordam são negadas
+type LeakyBucket struct {
+	Capacity int     `json:"capacity"`
+	LeakRate float64 `json:"leakRate"` // requisições por segundo
+}
+
+// StorageHealth representa o estado do storage observado pelo monitor de saúde
+type StorageHealth struct {
+	Healthy              bool      `json:"healthy"`
+	LastCheck            time.Time `json:"lastCheck"`
+	LastError            string    `json:"lastError,omitempty"`
+	ConsecutiveFailures  int       `json:"consecutiveFailures"`
+	ConsecutiveSuccesses int       `json:"consecutiveSuccesses"`
+}
+
+// DecisionTrace registra o passo a passo de uma decisão de rate limit
+// Gravado sob demanda para depurar "por que fui limitado" sem logs de debug globais
+type DecisionTrace struct {
+	Key            string         `json:"key"`
+	Type           LimiterType    `json:"type"`
+	StorageKey     string         `json:"storageKey,omitempty"`
+	Rule           *RateLimitRule `json:"rule,omitempty"` // Regra aplicada
+	Steps          []DecisionStep `json:"steps"`
+	Allowed        bool           `json:"allowed"`
+	Remaining      int            `json:"remaining"`
+	BlockedUntil   *time.Time     `json:"blockedUntil,omitempty"`
+	ReleaseAt      *time.Time     `json:"releaseAt,omitempty"`
+	Error          string         `json:"error,omitempty"`
+	StartedAt      time.Time      `json:"startedAt"`
+	DurationMicros int64          `json:"durationMicros"`
+}
+
+// DecisionStep é uma etapa da decisão: resolução da regra ou operação no storage
+type DecisionStep struct {
+	Name           string                 `json:"name"`
+	Result         map[string]interface{} `json:"result,omitempty"` // Valores lidos ou retornados pelo storage
+	Error          string                 `json:"error,omitempty"`
+	DurationMicros int64                  `json:"durationMicros"`
+}
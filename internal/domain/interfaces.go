@@ -2,9 +2,40 @@ package domain
 
 import (
 	"context"
+	"errors"
 	"time"
 )
 
+// ErrStorageUnavailable indica que o storage está degradado e não deve ser consultado
+var ErrStorageUnavailable = errors.New("storage unavailable")
+
+// ErrInspectionUnsupported indica que o storage não expõe seus registros brutos
+var ErrInspectionUnsupported = errors.New("storage does not support raw inspection")
+
+// ErrHistoryUnsupported indica que o storage não guarda o histórico de janelas
+var ErrHistoryUnsupported = errors.New("storage does not keep window history")
+
+// ErrListingUnsupported indica que o storage não lista as chaves rastreadas
+var ErrListingUnsupported = errors.New("storage does not support listing keys")
+
+// ErrInvalidCursor indica um cursor de listagem de chaves malformado
+var ErrInvalidCursor = errors.New("invalid key listing cursor")
+
+// ErrPatternResetUnsupported indica que o storage não remove chaves por padrão
+var ErrPatternResetUnsupported = errors.New("storage does not support pattern resets")
+
+// ErrInvalidPattern indica um padrão de chaves que não pode ser aplicado
+var ErrInvalidPattern = errors.New("invalid key pattern")
+
+// ErrInvalidStateEntry indica uma entrada de estado que não pode ser importada
+var ErrInvalidStateEntry = errors.New("invalid state entry")
+
+// ErrHierarchyUnsupported indica que o storage não incrementa vários níveis atomicamente
+var ErrHierarchyUnsupported = errors.New("storage does not support hierarchical limits")
+
+// ErrCheckUnsupported indica que o storage não verifica o limite em uma única operação
+var ErrCheckUnsupported = errors.New("storage does not support single round-trip checks")
+
 // RateLimiterStorage define a interface para armazenamento do rate limiter
 // Implementa o Strategy Pattern conforme requisito do fc_rate_limiter
 type RateLimiterStorage interface {
@@ -16,6 +47,20 @@ type RateLimiterStorage interface {
 	
 	// Increment incrementa o contador para uma chave e retorna o novo valor
 	Increment(ctx context.Context, key string, limit int, window time.Duration) (int, time.Time, error)
+
+	// IncrementQuota incrementa o contador de uma cota com reset absoluto
+	// O contador é zerado quando o reset armazenado já passou
+	IncrementQuota(ctx context.Context, key string, period QuotaPeriod) (*RateLimitStatus, error)
+
+	// TakeToken consome uma ficha do balde da chave, reposto desde a última requisição.
+	// Count é a capacidade em uso (Limit+1 quando não havia ficha) e ResetAt, quando o
+	// balde volta a encher. Sem ficha, IsBlocked é true e BlockedUntil é a próxima ficha
+	TakeToken(ctx context.Context, key string, bucket TokenBucket) (*RateLimitStatus, error)
+
+	// Leak enfileira a requisição no leaky bucket da chave, escoado desde a última requisição.
+	// ReleaseAt é quando a requisição sai do balde e ResetAt, quando ele esvazia. No
+	// transbordo, IsBlocked é true e BlockedUntil é quando cabe a próxima requisição
+	Leak(ctx context.Context, key string, bucket LeakyBucket) (*RateLimitStatus, error)
 	
 	// IsBlocked verifica se uma chave está bloqueada
 	IsBlocked(ctx context.Context, key string) (bool, *time.Time, error)
@@ -43,13 +88,86 @@ type RateLimiterService interface {
 	IsAllowed(ctx context.Context, key string, limiterType LimiterType) (bool, error)
 	
 	// GetConfig retorna a configuração para uma chave específica
-	GetConfig(key string, limiterType LimiterType) *RateLimitRule
+	// Retorna erro quando a fonte dinâmica de regras está indisponível
+	GetConfig(ctx context.Context, key string, limiterType LimiterType) (*RateLimitRule, error)
 	
 	// GetStatus retorna o status atual de uma chave
 	GetStatus(ctx context.Context, key string, limiterType LimiterType) (*RateLimitStatus, error)
 	
 	// Reset limpa os dados de rate limit para uma chave
 	Reset(ctx context.Context, key string, limiterType LimiterType) error
+
+	// BlockKey bloqueia a chave manualmente; duration zero usa o BlockDuration da regra.
+	// Retorna o fim do bloqueio
+	BlockKey(ctx context.Context, key string, limiterType LimiterType, duration time.Duration) (time.Time, error)
+
+	// SetOverride aplica um limite temporário a uma chave até a expiração
+	SetOverride(ctx context.Context, override LimitOverride) error
+
+	// InspectKey retorna o registro da chave exatamente como está no storage (diagnóstico)
+	InspectKey(ctx context.Context, key string, limiterType LimiterType) (*StorageRecord, error)
+
+	// WindowHistory retorna as contagens das últimas janelas da chave, da mais recente à mais antiga
+	WindowHistory(ctx context.Context, key string, limiterType LimiterType) ([]WindowCount, error)
+
+	// ListKeys retorna uma página das chaves do tipo com estado no storage padrão e o
+	// cursor da próxima página (vazio na última)
+	ListKeys(ctx context.Context, limiterType LimiterType, cursor string, count int) ([]TrackedKey, string, error)
+
+	// ResetPattern limpa os dados de todas as chaves do tipo cujo identificador casa
+	// com o glob (ex: "192.168.1.*", "*") e retorna quantas chaves foram removidas
+	ResetPattern(ctx context.Context, pattern string, limiterType LimiterType) (int, error)
+
+	// ExportState retorna os contadores e bloqueios ativos do storage padrão
+	ExportState(ctx context.Context) ([]StateEntry, error)
+
+	// ImportState grava as entradas ainda vigentes no storage padrão e retorna quantas foram gravadas
+	ImportState(ctx context.Context, entries []StateEntry) (int, error)
+
+	// ClientLimits retorna todos os limites aplicados ao token, com o uso atual de cada um
+	ClientLimits(ctx context.Context, token string) ([]ClientLimit, error)
+}
+
+// StorageInspector é implementado por storages que expõem seus registros brutos
+type StorageInspector interface {
+	Inspect(ctx context.Context, key string) (*StorageRecord, error)
+}
+
+// WindowHistoryReader é implementado por storages que guardam as contagens das
+// últimas janelas de cada chave (a primeira é a janela atual)
+type WindowHistoryReader interface {
+	WindowHistory(ctx context.Context, key string) ([]WindowCount, error)
+}
+
+// KeyLister é implementado por storages que listam as chaves rastreadas com o
+// prefixo (SCAN no Redis, iteração dos mapas em memória), em páginas de cerca de
+// count chaves. Retorna o cursor da próxima página, vazio na última
+type KeyLister interface {
+	ListKeys(ctx context.Context, prefix, cursor string, count int) ([]*RateLimitStatus, string, error)
+}
+
+// PatternResetter é implementado por storages que removem de uma vez as chaves
+// que casam com um glob ("*" e "?"), com SCAN+DEL no Redis e filtro dos mapas em
+// memória. Retorna quantas chaves foram removidas
+type PatternResetter interface {
+	ResetPattern(ctx context.Context, pattern string) (int, error)
+}
+
+// HierarchicalIncrementer é implementado por storages que incrementam os
+// contadores de todos os níveis de uma hierarquia (token, projeto, organização)
+// em uma única operação atômica. Os contadores só são incrementados se todos os
+// níveis tiverem espaço; senão nada muda
+type HierarchicalIncrementer interface {
+	IncrementHierarchy(ctx context.Context, counters []HierarchyCounter, window time.Duration) (*HierarchyResult, error)
+}
+
+// AtomicLimitChecker é implementado por storages que, em uma única operação
+// atômica, verificam o bloqueio da chave, incrementam a janela fixa e bloqueiam a
+// chave por blockDuration quando a contagem passa do limite. Chaves bloqueadas
+// não são contadas. Retorna ErrCheckUnsupported quando o algoritmo configurado
+// não é a janela fixa
+type AtomicLimitChecker interface {
+	CheckAndIncrement(ctx context.Context, key string, limit int, window, blockDuration time.Duration) (*LimitCheck, error)
 }
 
 // Logger define a interface para logging estruturado
@@ -61,9 +179,107 @@ type Logger interface {
 	WithContext(ctx context.Context) Logger
 }
 
+// DebugEnabled informa se o logger registra mensagens de debug. Loggers que não
+// expõem o nível são tratados como habilitados. No caminho quente, evita montar
+// os campos de mensagens que seriam descartadas
+func DebugEnabled(logger Logger) bool {
+	if leveled, ok := logger.(interface{ DebugEnabled() bool }); ok {
+		return leveled.DebugEnabled()
+	}
+	return true
+}
+
 // ConfigLoader define a interface para carregamento de configurações
 type ConfigLoader interface {
 	LoadConfig() (*RateLimitConfig, error)
 	LoadTokenConfigs() (map[string]TokenConfig, error)
 	Reload() error
-} 
\ No newline at end of file
+}
+
+// TokenConfigProvider resolve configurações de tokens vindas de fontes dinâmicas (ex: banco)
+// found=false sem erro indica que o token não possui configuração específica
+type TokenConfigProvider interface {
+	TokenConfig(ctx context.Context, token string) (config TokenConfig, found bool, err error)
+}
+
+// TokenConfigFetcher consulta a configuração de um token em uma fonte remota
+// found=false indica que o token não existe na fonte
+type TokenConfigFetcher interface {
+	FetchTokenConfig(ctx context.Context, token string) (config TokenConfig, found bool, err error)
+}
+
+// ConfigSource fornece a configuração ativa quando ela pode ser trocada em execução
+type ConfigSource interface {
+	ActiveConfig() *RateLimitConfig
+}
+
+// MaintenanceProvider informa a janela de manutenção vigente para uma chave
+type MaintenanceProvider interface {
+	ActiveMaintenance(key string, limiterType LimiterType) (MaintenanceWindow, bool)
+}
+
+// RuleRolloutProvider seleciona a versão da regra por chave e contabiliza as decisões
+type RuleRolloutProvider interface {
+	ResolveRollout(key string, limiterType LimiterType) (rollout RuleRollout, version string, found bool)
+	RecordDecision(rolloutID, version string, allowed bool)
+}
+
+// ShadowObserver recebe cada decisão aplicada para compará-la com uma configuração em avaliação
+type ShadowObserver interface {
+	Observe(ctx context.Context, ip, token string, active *RateLimitResult)
+}
+
+// TrafficObserver recebe cada requisição verificada (permitida ou não) para análise de tráfego
+type TrafficObserver interface {
+	ObserveRequest(key string, limiterType LimiterType)
+}
+
+// InvalidationBus propaga invalidações de estado local entre as instâncias
+type InvalidationBus interface {
+	Broadcast(ctx context.Context, invalidation Invalidation) error
+	Subscribe(handler func(Invalidation))
+}
+
+// OverrideStore compartilha os overrides temporários entre as réplicas, indexados pela
+// chave de storage; overrides expirados não são retornados
+type OverrideStore interface {
+	SaveOverride(ctx context.Context, storageKey string, override LimitOverride) error
+	ListOverrides(ctx context.Context) (map[string]LimitOverride, error)
+}
+
+// DecisionObserver recebe o resultado de cada verificação (analytics agregados)
+type DecisionObserver interface {
+	ObserveDecision(key string, limiterType LimiterType, allowed bool)
+}
+
+// DecisionTracer grava o passo a passo das decisões de chaves em depuração
+// Tracing é consultado a cada requisição e deve ser barato quando nada é gravado
+type DecisionTracer interface {
+	Tracing(key string, limiterType LimiterType) bool
+	RecordTrace(trace DecisionTrace)
+}
+
+// BlockRecorder registra bloqueios e as requisições recusadas durante eles (relatórios)
+type BlockRecorder interface {
+	RecordBlock(record BlockRecord)
+	RecordRejected(key string, limiterType LimiterType)
+}
+
+// StorageHealthReporter expõe o estado de saúde do storage monitorado em background
+type StorageHealthReporter interface {
+	// IsHealthy informa se o storage está saudável
+	IsHealthy() bool
+
+	// HealthStatus retorna detalhes da última verificação
+	HealthStatus() StorageHealth
+}
+
+// InstanceCounter informa quantas instâncias do serviço estão vivas
+type InstanceCounter interface {
+	LiveInstances() int
+}
+
+// LocalStorage é implementado por storages cujo estado é restrito à instância
+type LocalStorage interface {
+	IsLocal() bool
+}
@@ -0,0 +1,137 @@
+package drain
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"rate-limiter/internal/domain"
+)
+
+// flushTimeout limita o envio do estado em memória de cada drenagem
+const flushTimeout = 5 * time.Second
+
+// Gate tira a instância do balanceamento (implementado por storage.ReadinessGate)
+type Gate interface {
+	Drain()
+}
+
+// InFlight acompanha as requisições em andamento (implementado por middleware.InFlight)
+type InFlight interface {
+	Count() int64
+	Wait(ctx context.Context) error
+}
+
+// Flusher envia o estado mantido em memória ao storage durável
+// (ex: incrementos pendentes do storage.HybridStorage)
+type Flusher interface {
+	Sync(ctx context.Context) error
+}
+
+// Result resume uma drenagem
+type Result struct {
+	InFlight    int64             // Requisições ainda em andamento ao fim do prazo
+	Flushed     []string          // Storages com o estado em memória enviado
+	FlushErrors map[string]string // Storages que falharam ao enviar, com o erro
+	Duration    time.Duration
+}
+
+// Complete informa se a instância pode ser desligada sem perder requisições nem estado
+func (r Result) Complete() bool {
+	return r.InFlight == 0 && len(r.FlushErrors) == 0
+}
+
+// Drainer prepara a instância para o desligamento: sai da readiness, espera as
+// requisições em andamento e envia o estado em memória. Pode ser chamado mais de
+// uma vez (ex: pelo preStop via /admin/drain e depois pelo SIGTERM)
+type Drainer struct {
+	gate     Gate
+	inFlight InFlight
+	logger   domain.Logger
+
+	mutex    sync.Mutex // uma drenagem por vez
+	flushers map[string]Flusher
+	draining atomic.Bool
+}
+
+// NewDrainer cria o drainer; gate e inFlight nil pulam as respectivas etapas
+func NewDrainer(gate Gate, inFlight InFlight, logger domain.Logger) *Drainer {
+	return &Drainer{
+		gate:     gate,
+		inFlight: inFlight,
+		logger:   logger,
+		flushers: make(map[string]Flusher),
+	}
+}
+
+// AddFlusher registra um storage cujo estado em memória é enviado na drenagem
+func (d *Drainer) AddFlusher(name string, flusher Flusher) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	d.flushers[name] = flusher
+}
+
+// Draining informa se a drenagem já começou
+func (d *Drainer) Draining() bool {
+	return d.draining.Load()
+}
+
+// Drain tira a instância da readiness, espera as requisições em andamento até o
+// fim do contexto e envia o estado em memória (mesmo que o prazo tenha acabado)
+func (d *Drainer) Drain(ctx context.Context) Result {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	start := time.Now()
+	if d.draining.CompareAndSwap(false, true) && d.logger != nil {
+		d.logger.Info("Draining instance", nil)
+	}
+	if d.gate != nil {
+		d.gate.Drain()
+	}
+
+	var result Result
+	if d.inFlight != nil {
+		if err := d.inFlight.Wait(ctx); err != nil {
+			result.InFlight = d.inFlight.Count()
+		}
+	}
+
+	names := make([]string, 0, len(d.flushers))
+	for name := range d.flushers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// O envio tem prazo próprio: roda mesmo quando a espera esgotou o contexto
+	flushCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), flushTimeout)
+	defer cancel()
+	for _, name := range names {
+		if err := d.flushers[name].Sync(flushCtx); err != nil {
+			if result.FlushErrors == nil {
+				result.FlushErrors = make(map[string]string)
+			}
+			result.FlushErrors[name] = err.Error()
+			if d.logger != nil {
+				d.logger.Error("Failed to flush storage while draining", err, map[string]interface{}{
+					"storage": name,
+				})
+			}
+			continue
+		}
+		result.Flushed = append(result.Flushed, name)
+	}
+
+	result.Duration = time.Since(start)
+	if d.logger != nil {
+		d.logger.Info("Instance drained", map[string]interface{}{
+			"in_flight":   result.InFlight,
+			"flushed":     result.Flushed,
+			"complete":    result.Complete(),
+			"duration_ms": result.Duration.Milliseconds(),
+		})
+	}
+	return result
+}
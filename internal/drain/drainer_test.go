@@ -0,0 +1,75 @@
+package drain
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeGate struct{ drained int }
+
+func (g *fakeGate) Drain() { g.drained++ }
+
+type fakeInFlight struct{ count int64 }
+
+func (f *fakeInFlight) Count() int64 { return f.count }
+
+func (f *fakeInFlight) Wait(ctx context.Context) error {
+	if f.count == 0 {
+		return nil
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+type fakeFlusher struct {
+	err    error
+	synced int
+}
+
+func (f *fakeFlusher) Sync(ctx context.Context) error {
+	f.synced++
+	return f.err
+}
+
+func TestDrainer_Drain(t *testing.T) {
+	// Arrange
+	gate := &fakeGate{}
+	hybrid := &fakeFlusher{}
+	drainer := NewDrainer(gate, &fakeInFlight{}, nil)
+	drainer.AddFlusher("hybrid", hybrid)
+
+	// Act
+	result := drainer.Drain(context.Background())
+
+	// Assert
+	assert.True(t, result.Complete())
+	assert.Equal(t, []string{"hybrid"}, result.Flushed)
+	assert.Equal(t, 1, gate.drained)
+	assert.Equal(t, 1, hybrid.synced)
+	assert.True(t, drainer.Draining())
+}
+
+func TestDrainer_DrainIncomplete(t *testing.T) {
+	// Arrange
+	hybrid := &fakeFlusher{}
+	broken := &fakeFlusher{err: errors.New("redis unavailable")}
+	drainer := NewDrainer(&fakeGate{}, &fakeInFlight{count: 2}, nil)
+	drainer.AddFlusher("hybrid", hybrid)
+	drainer.AddFlusher("broken", broken)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	// Act
+	result := drainer.Drain(ctx)
+
+	// Assert
+	assert.False(t, result.Complete())
+	assert.Equal(t, int64(2), result.InFlight)
+	// O estado é enviado mesmo com o prazo da espera esgotado
+	assert.Equal(t, []string{"hybrid"}, result.Flushed)
+	assert.Equal(t, map[string]string{"broken": "redis unavailable"}, result.FlushErrors)
+}
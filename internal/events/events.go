@@ -0,0 +1,95 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/logger"
+)
+
+// Event é uma notificação operacional (manutenção, bloqueios, anomalias)
+// RequestID é preenchido a partir do contexto quando o evento nasce de uma requisição
+type Event struct {
+	Type      string                 `json:"type"`
+	Timestamp time.Time              `json:"timestamp"`
+	RequestID string                 `json:"request_id,omitempty"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+}
+
+// Handler consome eventos publicados
+type Handler func(Event)
+
+// Publisher publica eventos para os consumidores registrados
+type Publisher interface {
+	Publish(ctx context.Context, eventType string, data map[string]interface{})
+}
+
+// Bus distribui eventos em processo para os handlers inscritos
+// Handlers são chamados de forma síncrona e devem ser rápidos
+type Bus struct {
+	mutex    sync.RWMutex
+	handlers []Handler
+	now      func() time.Time
+}
+
+// NewBus cria um barramento de eventos vazio
+func NewBus() *Bus {
+	return &Bus{now: time.Now}
+}
+
+// Subscribe registra um handler para todos os eventos
+func (b *Bus) Subscribe(handler Handler) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.handlers = append(b.handlers, handler)
+}
+
+// Publish monta o evento e o entrega aos handlers inscritos
+func (b *Bus) Publish(ctx context.Context, eventType string, data map[string]interface{}) {
+	event := Event{
+		Type:      eventType,
+		Timestamp: b.now().UTC(),
+		RequestID: logger.GetRequestID(ctx),
+		Data:      data,
+	}
+
+	b.mutex.RLock()
+	handlers := make([]Handler, len(b.handlers))
+	copy(handlers, b.handlers)
+	b.mutex.RUnlock()
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
+
+// Filter entrega ao handler apenas os eventos dos tipos informados
+func Filter(handler Handler, types ...string) Handler {
+	allowed := make(map[string]struct{}, len(types))
+	for _, eventType := range types {
+		allowed[eventType] = struct{}{}
+	}
+	return func(event Event) {
+		if _, ok := allowed[event.Type]; ok {
+			handler(event)
+		}
+	}
+}
+
+// LogHandler registra os eventos no logger estruturado
+func LogHandler(log domain.Logger) Handler {
+	return func(event Event) {
+		fields := map[string]interface{}{
+			"event_type": event.Type,
+		}
+		if event.RequestID != "" {
+			fields["request_id"] = event.RequestID
+		}
+		for key, value := range event.Data {
+			fields[key] = value
+		}
+		log.Info("Event published", fields)
+	}
+}
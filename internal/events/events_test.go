@@ -0,0 +1,53 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/logger"
+)
+
+func TestBus_PublishDeliversToSubscribers(t *testing.T) {
+	// Arrange
+	bus := NewBus()
+	var first, second []Event
+	bus.Subscribe(func(event Event) { first = append(first, event) })
+	bus.Subscribe(func(event Event) { second = append(second, event) })
+	ctx := logger.ContextWithRequestID(context.Background(), "req-123")
+
+	// Act
+	bus.Publish(ctx, "maintenance.started", map[string]interface{}{"window_id": "deploy"})
+
+	// Assert
+	require.Len(t, first, 1)
+	require.Len(t, second, 1)
+	assert.Equal(t, "maintenance.started", first[0].Type)
+	assert.Equal(t, "req-123", first[0].RequestID)
+	assert.Equal(t, "deploy", first[0].Data["window_id"])
+	assert.False(t, first[0].Timestamp.IsZero())
+}
+
+func TestBus_PublishWithoutRequestID(t *testing.T) {
+	bus := NewBus()
+	var received Event
+	bus.Subscribe(func(event Event) { received = event })
+
+	bus.Publish(context.Background(), "maintenance.ended", nil)
+
+	assert.Equal(t, "maintenance.ended", received.Type)
+	assert.Empty(t, received.RequestID)
+}
+
+func TestFilter_DeliversOnlyListedTypes(t *testing.T) {
+	bus := NewBus()
+	var received []string
+	bus.Subscribe(Filter(func(event Event) { received = append(received, event.Type) }, "rate_limit.blocked"))
+
+	bus.Publish(context.Background(), "rate_limit.soft_limit", nil)
+	bus.Publish(context.Background(), "rate_limit.blocked", nil)
+
+	assert.Equal(t, []string{"rate_limit.blocked"}, received)
+}
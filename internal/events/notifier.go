@@ -0,0 +1,90 @@
+package events
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/logger"
+)
+
+// Notifier entrega eventos a URLs definidas por cliente (ex: webhook do dono do token)
+type Notifier interface {
+	Notify(ctx context.Context, url, eventType string, data map[string]interface{})
+}
+
+// notification é um evento aguardando entrega à URL do cliente
+type notification struct {
+	url   string
+	event Event
+}
+
+// ClientWebhooks entrega eventos via HTTP POST (JSON) às URLs dos clientes.
+// Como o Webhook, a entrega é assíncrona: Notify nunca bloqueia a requisição
+// e descarta eventos quando a fila está cheia
+type ClientWebhooks struct {
+	client *http.Client
+	logger domain.Logger
+	now    func() time.Time
+
+	queue     chan notification
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// NewClientWebhooks cria o entregador e inicia o worker de entrega
+func NewClientWebhooks(logger domain.Logger) *ClientWebhooks {
+	webhooks := &ClientWebhooks{
+		client: &http.Client{Timeout: 5 * time.Second},
+		logger: logger,
+		now:    time.Now,
+		queue:  make(chan notification, DefaultWebhookQueueSize),
+		done:   make(chan struct{}),
+	}
+	go webhooks.run()
+	return webhooks
+}
+
+// Notify enfileira o evento para entrega em url
+func (w *ClientWebhooks) Notify(ctx context.Context, url, eventType string, data map[string]interface{}) {
+	event := Event{
+		Type:      eventType,
+		Timestamp: w.now().UTC(),
+		RequestID: logger.GetRequestID(ctx),
+		Data:      data,
+	}
+
+	select {
+	case w.queue <- notification{url: url, event: event}:
+	default:
+		if w.logger != nil {
+			w.logger.Warn("Client webhook queue full, dropping event", map[string]interface{}{
+				"event_type": eventType,
+			})
+		}
+	}
+}
+
+// Close entrega os eventos pendentes e encerra o worker
+// Não deve ser chamado enquanto eventos ainda estiverem sendo notificados
+func (w *ClientWebhooks) Close() {
+	w.closeOnce.Do(func() {
+		close(w.queue)
+	})
+	<-w.done
+}
+
+// run entrega os eventos da fila em ordem
+func (w *ClientWebhooks) run() {
+	defer close(w.done)
+
+	for notification := range w.queue {
+		if err := postEvent(w.client, notification.url, notification.event); err != nil && w.logger != nil {
+			w.logger.Error("Failed to deliver client webhook", err, map[string]interface{}{
+				"event_type": notification.event.Type,
+			})
+		}
+	}
+}
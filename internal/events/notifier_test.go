@@ -0,0 +1,46 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientWebhooks_DeliversEachEventToItsURL(t *testing.T) {
+	// Arrange
+	var (
+		mutex    sync.Mutex
+		received = make(map[string][]Event)
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event Event
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		mutex.Lock()
+		received[r.URL.Path] = append(received[r.URL.Path], event)
+		mutex.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	webhooks := NewClientWebhooks(nil)
+
+	// Act
+	webhooks.Notify(context.Background(), server.URL+"/acme", "rate_limit.usage_threshold", map[string]interface{}{"percent": 90})
+	webhooks.Notify(context.Background(), server.URL+"/globex", "rate_limit.usage_threshold", map[string]interface{}{"percent": 75})
+	webhooks.Close()
+
+	// Assert
+	mutex.Lock()
+	defer mutex.Unlock()
+	require.Len(t, received["/acme"], 1)
+	require.Len(t, received["/globex"], 1)
+	assert.Equal(t, "rate_limit.usage_threshold", received["/acme"][0].Type)
+	assert.Equal(t, float64(90), received["/acme"][0].Data["percent"])
+	assert.Equal(t, float64(75), received["/globex"][0].Data["percent"])
+}
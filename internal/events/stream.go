@@ -0,0 +1,181 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"rate-limiter/internal/domain"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	// DefaultStreamMaxLen limita as entradas mantidas no stream
+	DefaultStreamMaxLen = 10000
+	// DefaultStreamQueueSize limita os eventos aguardando o XADD
+	DefaultStreamQueueSize = 1024
+)
+
+// StreamEntry é um evento lido do stream, com o ID atribuído pelo Redis
+type StreamEntry struct {
+	ID    string
+	Event Event
+}
+
+// RedisStream acrescenta eventos a um Redis Stream limitado (XADD MAXLEN ~),
+// para que consumidores externos (XREAD, XREADGROUP) processem os eventos sem
+// outra infraestrutura. Como o Webhook, a escrita é assíncrona: Handle nunca
+// bloqueia o publicador e descarta eventos quando a fila está cheia
+type RedisStream struct {
+	client redis.Cmdable
+	stream string
+	maxLen int64
+	logger domain.Logger
+
+	queue     chan Event
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// NewRedisStream cria o sink e inicia o worker de escrita; maxLen <= 0 usa DefaultStreamMaxLen
+func NewRedisStream(client redis.Cmdable, stream string, maxLen int64, logger domain.Logger) *RedisStream {
+	if maxLen <= 0 {
+		maxLen = DefaultStreamMaxLen
+	}
+
+	sink := &RedisStream{
+		client: client,
+		stream: stream,
+		maxLen: maxLen,
+		logger: logger,
+		queue:  make(chan Event, DefaultStreamQueueSize),
+		done:   make(chan struct{}),
+	}
+	go sink.run()
+	return sink
+}
+
+// Handle enfileira o evento para o stream; use como Handler do Bus
+func (s *RedisStream) Handle(event Event) {
+	select {
+	case s.queue <- event:
+	default:
+		if s.logger != nil {
+			s.logger.Warn("Event stream queue full, dropping event", map[string]interface{}{
+				"event_type": event.Type,
+				"stream":     s.stream,
+			})
+		}
+	}
+}
+
+// Close grava os eventos pendentes e encerra o worker
+// Não deve ser chamado enquanto eventos ainda estiverem sendo publicados
+func (s *RedisStream) Close() {
+	s.closeOnce.Do(func() {
+		close(s.queue)
+	})
+	<-s.done
+}
+
+// Recent retorna as entradas mais recentes do stream, da mais nova para a mais antiga
+func (s *RedisStream) Recent(ctx context.Context, count int) ([]StreamEntry, error) {
+	messages, err := s.client.XRevRangeN(ctx, s.stream, "+", "-", int64(count)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stream %s: %w", s.stream, err)
+	}
+
+	entries := make([]StreamEntry, 0, len(messages))
+	for _, message := range messages {
+		event, err := decodeStreamEvent(message.Values)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode stream entry %s: %w", message.ID, err)
+		}
+		entries = append(entries, StreamEntry{ID: message.ID, Event: event})
+	}
+	return entries, nil
+}
+
+// run grava os eventos da fila em ordem
+func (s *RedisStream) run() {
+	defer close(s.done)
+
+	for event := range s.queue {
+		if err := s.append(event); err != nil && s.logger != nil {
+			s.logger.Error("Failed to append event to stream", err, map[string]interface{}{
+				"event_type": event.Type,
+				"stream":     s.stream,
+			})
+		}
+	}
+}
+
+// append grava um evento; o corte aproximado (~) mantém o XADD em O(1)
+func (s *RedisStream) append(event Event) error {
+	values, err := encodeStreamEvent(event)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return s.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: s.stream,
+		MaxLen: s.maxLen,
+		Approx: true,
+		Values: values,
+	}).Err()
+}
+
+// encodeStreamEvent achata o evento em campos do stream; Data vai como JSON
+func encodeStreamEvent(event Event) (map[string]interface{}, error) {
+	values := map[string]interface{}{
+		"type":      event.Type,
+		"timestamp": event.Timestamp.UTC().Format(time.RFC3339Nano),
+	}
+	if event.RequestID != "" {
+		values["request_id"] = event.RequestID
+	}
+	if len(event.Data) > 0 {
+		data, err := json.Marshal(event.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode event data: %w", err)
+		}
+		values["data"] = string(data)
+	}
+	return values, nil
+}
+
+// decodeStreamEvent é o inverso de encodeStreamEvent
+func decodeStreamEvent(values map[string]interface{}) (Event, error) {
+	event := Event{
+		Type:      fmt.Sprint(values["type"]),
+		RequestID: stringValue(values["request_id"]),
+	}
+
+	if timestamp := stringValue(values["timestamp"]); timestamp != "" {
+		parsed, err := time.Parse(time.RFC3339Nano, timestamp)
+		if err != nil {
+			return Event{}, fmt.Errorf("invalid timestamp: %w", err)
+		}
+		event.Timestamp = parsed
+	}
+
+	if data := stringValue(values["data"]); data != "" {
+		if err := json.Unmarshal([]byte(data), &event.Data); err != nil {
+			return Event{}, fmt.Errorf("invalid data: %w", err)
+		}
+	}
+	return event, nil
+}
+
+func stringValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
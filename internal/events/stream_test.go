@@ -0,0 +1,67 @@
+package events
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisStream_AppendsAndReadsRecent(t *testing.T) {
+	// Arrange
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+
+	bus := NewBus()
+	stream := NewRedisStream(client, "rate_limiter:events", 0, nil)
+	bus.Subscribe(stream.Handle)
+
+	// Act
+	bus.Publish(context.Background(), "rate_limit.blocked", map[string]interface{}{"key": "10.0.0.1", "limit": 10})
+	bus.Publish(context.Background(), "rate_limit.unblocked", map[string]interface{}{"key": "10.0.0.1"})
+	stream.Close()
+
+	// Assert: campos planos para consumidores externos
+	messages, err := client.XRange(context.Background(), "rate_limiter:events", "-", "+").Result()
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Equal(t, "rate_limit.blocked", messages[0].Values["type"])
+	assert.JSONEq(t, `{"key":"10.0.0.1","limit":10}`, messages[0].Values["data"].(string))
+
+	entries, err := stream.Recent(context.Background(), 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "rate_limit.unblocked", entries[0].Event.Type, "most recent first")
+	assert.Equal(t, messages[1].ID, entries[0].ID)
+	assert.Equal(t, "10.0.0.1", entries[1].Event.Data["key"])
+	assert.False(t, entries[1].Event.Timestamp.IsZero())
+}
+
+func TestRedisStream_CapsLength(t *testing.T) {
+	// Arrange
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer client.Close()
+	stream := NewRedisStream(client, "rate_limiter:events", 5, nil)
+
+	// Act
+	for i := 0; i < 20; i++ {
+		stream.Handle(Event{Type: "rate_limit.blocked", Data: map[string]interface{}{"seq": strconv.Itoa(i)}})
+	}
+	stream.Close()
+
+	// Assert: o corte aproximado pode manter algumas entradas a mais, nunca todas
+	length, err := client.XLen(context.Background(), "rate_limiter:events").Result()
+	require.NoError(t, err)
+	assert.Less(t, length, int64(20))
+
+	entries, err := stream.Recent(context.Background(), 1)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "19", entries[0].Event.Data["seq"])
+}
@@ -0,0 +1,110 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"rate-limiter/internal/domain"
+)
+
+// DefaultWebhookQueueSize limita os eventos aguardando entrega
+const DefaultWebhookQueueSize = 256
+
+// Webhook entrega eventos via HTTP POST (JSON) para uma URL externa
+// A entrega é assíncrona: Handle nunca bloqueia o publicador e descarta
+// eventos quando a fila está cheia
+type Webhook struct {
+	url    string
+	client *http.Client
+	logger domain.Logger
+
+	queue     chan Event
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+// NewWebhook cria o webhook e inicia o worker de entrega
+func NewWebhook(url string, logger domain.Logger) *Webhook {
+	webhook := &Webhook{
+		url:    url,
+		client: &http.Client{Timeout: 5 * time.Second},
+		logger: logger,
+		queue:  make(chan Event, DefaultWebhookQueueSize),
+		done:   make(chan struct{}),
+	}
+	go webhook.run()
+	return webhook
+}
+
+// Handle enfileira o evento para entrega; use como Handler do Bus
+func (w *Webhook) Handle(event Event) {
+	select {
+	case w.queue <- event:
+	default:
+		if w.logger != nil {
+			w.logger.Warn("Event webhook queue full, dropping event", map[string]interface{}{
+				"event_type": event.Type,
+			})
+		}
+	}
+}
+
+// Close entrega os eventos pendentes e encerra o worker
+// Não deve ser chamado enquanto eventos ainda estiverem sendo publicados
+func (w *Webhook) Close() {
+	w.closeOnce.Do(func() {
+		close(w.queue)
+	})
+	<-w.done
+}
+
+// run entrega os eventos da fila em ordem
+func (w *Webhook) run() {
+	defer close(w.done)
+
+	for event := range w.queue {
+		if err := w.deliver(event); err != nil && w.logger != nil {
+			w.logger.Error("Failed to deliver event webhook", err, map[string]interface{}{
+				"event_type": event.Type,
+			})
+		}
+	}
+}
+
+// deliver envia um evento; respostas fora de 2xx são tratadas como falha
+func (w *Webhook) deliver(event Event) error {
+	return postEvent(w.client, w.url, event)
+}
+
+// postEvent envia o evento em JSON para url; respostas fora de 2xx são tratadas como falha
+func postEvent(client *http.Client, url string, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), client.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhook_DeliversEventsAsJSON(t *testing.T) {
+	// Arrange
+	var (
+		mutex    sync.Mutex
+		received []Event
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var event Event
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+		mutex.Lock()
+		received = append(received, event)
+		mutex.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	bus := NewBus()
+	webhook := NewWebhook(server.URL, nil)
+	bus.Subscribe(webhook.Handle)
+
+	// Act
+	bus.Publish(context.Background(), "anomaly.spike", map[string]interface{}{"scope": "global"})
+	bus.Publish(context.Background(), "maintenance.started", map[string]interface{}{"window_id": "deploy"})
+	webhook.Close()
+
+	// Assert: entregues em ordem
+	require.Len(t, received, 2)
+	assert.Equal(t, "anomaly.spike", received[0].Type)
+	assert.Equal(t, "global", received[0].Data["scope"])
+	assert.Equal(t, "maintenance.started", received[1].Type)
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"rate-limiter/internal/adminauth"
+	"rate-limiter/internal/audit"
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/logger"
+)
+
+// audited indica se a rota altera estado: toda rota que não é de leitura é auditada
+func (r route) audited() bool {
+	return r.method != http.MethodGet && r.method != http.MethodHead && r.role != adminauth.RoleReadOnly
+}
+
+// startAudit monta o registro antes do handler, enquanto o corpo ainda pode ser
+// lido para extrair as chaves; retorna nil quando a rota não é auditada
+func (h *Handlers) startAudit(r *http.Request, adminRoute route, params map[string]string) *audit.Record {
+	if h.audit == nil || !adminRoute.audited() {
+		return nil
+	}
+
+	record := &audit.Record{
+		Timestamp:    time.Now(),
+		Principal:    "anonymous",
+		Method:       r.Method,
+		Path:         r.URL.Path,
+		SourceIP:     remoteIP(r),
+		ForwardedFor: r.Header.Get("X-Forwarded-For"),
+		RequestID:    logger.GetRequestID(r.Context()),
+	}
+	if principal := adminauth.PrincipalFrom(r.Context()); principal != nil {
+		record.Principal = principal.Name
+		record.Role = string(principal.Role)
+	}
+
+	// Rotas globais não alcançam chaves; os parâmetros identificam o recurso
+	if adminRoute.targets == nil {
+		if len(params) > 0 {
+			record.Params = params
+		}
+		return record
+	}
+
+	targets, err := adminRoute.targets(r, params)
+	if err != nil {
+		h.logger.WithContext(r.Context()).Warn("Failed to read admin audit targets", map[string]interface{}{
+			"path":   r.URL.Path,
+			"reason": err.Error(),
+		})
+	}
+	for _, target := range targets {
+		limiterType := strings.ToLower(strings.TrimSpace(target.Type))
+		key := strings.TrimSpace(target.Key)
+		if domain.LimiterType(limiterType) == domain.TokenLimiter {
+			key = h.maskToken(key)
+		}
+		record.Targets = append(record.Targets, audit.Target{Type: limiterType, Key: key})
+	}
+	return record
+}
+
+// finishAudit grava o registro iniciado por startAudit com o status da resposta
+func (h *Handlers) finishAudit(record *audit.Record, status int) {
+	if record == nil {
+		return
+	}
+	record.Status = status
+	h.audit.Record(*record)
+}
+
+// adminAudit é o middleware de auditoria de uma rota administrativa no Gin
+func (h *Handlers) adminAudit(adminRoute route) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		params := make(map[string]string, len(c.Params))
+		for _, param := range c.Params {
+			params[param.Key] = param.Value
+		}
+		record := h.startAudit(c.Request, adminRoute, params)
+		c.Next()
+		h.finishAudit(record, c.Writer.Status())
+	}
+}
+
+// remoteIP retorna o IP do peer da conexão; X-Forwarded-For é registrado à parte
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// Limites da leitura de /admin/audit
+const (
+	defaultAuditCount = 50
+	maxAuditCount     = 1000
+)
+
+// AdminAuditHandler retorna as operações administrativas mais recentes desta instância
+// Query: count (padrão 50, máximo 1000) e principal (nome da credencial)
+func (h *Handlers) AdminAuditHandler(c *Exchange) {
+	if h.audit == nil {
+		c.JSON(http.StatusNotImplemented, H{
+			"error":   "not_implemented",
+			"message": "Audit log is not enabled",
+		})
+		return
+	}
+
+	count := defaultAuditCount
+	if value := c.Query("count"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 || parsed > maxAuditCount {
+			c.JSON(http.StatusBadRequest, H{
+				"error":   "invalid_request",
+				"message": fmt.Sprintf("count must be between 1 and %d", maxAuditCount),
+			})
+			return
+		}
+		count = parsed
+	}
+
+	records := h.audit.Recent(count, strings.TrimSpace(c.Query("principal")))
+	c.JSON(http.StatusOK, H{
+		"count":     len(records),
+		"records":   records,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"rate-limiter/internal/adminauth"
+	"rate-limiter/internal/middleware"
+)
+
+// route associa método e caminho a um handler independente de framework
+type route struct {
+	method  string
+	path    string // Relativo ao prefixo; segmentos ":nome" são parâmetros
+	handler ExchangeHandler
+	role    adminauth.Role // Papel mínimo da credencial
+	targets adminTargets   // Chaves alcançadas (nil = rota global, fora do alcance de tenants)
+}
+
+// adminRoutes é a tabela única da API administrativa, montada no Gin e no net/http
+func (h *Handlers) adminRoutes() []route {
+	routes := []route{
+		{http.MethodGet, "/status", h.AdminStatusHandler, adminauth.RoleReadOnly, queryTarget},
+		{http.MethodPost, "/status", h.AdminBatchStatusHandler, adminauth.RoleReadOnly, batchTarget},
+		{http.MethodPost, "/reset", h.AdminResetHandler, adminauth.RoleOperator, bodyTarget},
+		{http.MethodPost, "/reset/bulk", h.AdminBulkResetHandler, adminauth.RoleOperator, nil},
+		{http.MethodPost, "/block", h.AdminBlockHandler, adminauth.RoleOperator, bodyTarget},
+		{http.MethodPost, "/override", h.AdminOverrideHandler, adminauth.RoleOperator, bodyTarget},
+		{http.MethodGet, "/instances", h.AdminInstancesHandler, adminauth.RoleReadOnly, nil},
+		{http.MethodGet, "/maintenance", h.AdminListMaintenanceHandler, adminauth.RoleReadOnly, nil},
+		{http.MethodPost, "/maintenance", h.AdminCreateMaintenanceHandler, adminauth.RoleOperator, nil},
+		{http.MethodDelete, "/maintenance/:id", h.AdminDeleteMaintenanceHandler, adminauth.RoleOperator, nil},
+		{http.MethodGet, "/rules/rollouts", h.AdminListRolloutsHandler, adminauth.RoleReadOnly, nil},
+		{http.MethodPost, "/rules/canary", h.AdminStartCanaryHandler, adminauth.RoleAdmin, nil},
+		{http.MethodPost, "/rules/promote", h.AdminPromoteRolloutHandler, adminauth.RoleAdmin, nil},
+		{http.MethodPost, "/rules/rollback", h.AdminRollbackRolloutHandler, adminauth.RoleAdmin, nil},
+		{http.MethodPost, "/rules", h.AdminCreateRuleHandler, adminauth.RoleAdmin, ruleBodyTarget},
+		{http.MethodGet, "/rules/:id", h.AdminGetRuleHandler, adminauth.RoleReadOnly, ruleTarget},
+		{http.MethodPut, "/rules/:id", h.AdminReplaceRuleHandler, adminauth.RoleAdmin, ruleTarget},
+		{http.MethodPatch, "/rules/:id", h.AdminPatchRuleHandler, adminauth.RoleAdmin, ruleTarget},
+		{http.MethodDelete, "/rules/:id", h.AdminDeleteRuleHandler, adminauth.RoleAdmin, ruleTarget},
+		{http.MethodGet, "/rules/:id/history", h.AdminRuleHistoryHandler, adminauth.RoleReadOnly, ruleTarget},
+		{http.MethodPost, "/rules/:id/restore", h.AdminRestoreRuleHandler, adminauth.RoleAdmin, ruleTarget},
+		{http.MethodGet, "/shadow", h.AdminShadowHandler, adminauth.RoleReadOnly, nil},
+		{http.MethodPost, "/shadow/reset", h.AdminShadowResetHandler, adminauth.RoleOperator, nil},
+		{http.MethodGet, "/capacity", h.AdminCapacityHandler, adminauth.RoleReadOnly, nil},
+		{http.MethodPost, "/capacity/reset", h.AdminCapacityResetHandler, adminauth.RoleOperator, nil},
+		{http.MethodGet, "/learning", h.AdminLearningHandler, adminauth.RoleReadOnly, nil},
+		{http.MethodPost, "/learning/apply", h.AdminLearningApplyHandler, adminauth.RoleAdmin, nil},
+		{http.MethodPost, "/learning/reset", h.AdminLearningResetHandler, adminauth.RoleOperator, nil},
+		{http.MethodGet, "/reports/blocks", h.AdminBlockReportHandler, adminauth.RoleReadOnly, nil},
+		{http.MethodGet, "/events/blocks", h.AdminBlockEventsHandler, adminauth.RoleReadOnly, nil},
+		{http.MethodGet, "/analytics", h.AdminAnalyticsHandler, adminauth.RoleReadOnly, nil},
+		{http.MethodGet, "/stats", h.AdminStatsHandler, adminauth.RoleReadOnly, nil},
+		{http.MethodGet, "/keys", h.AdminKeysHandler, adminauth.RoleReadOnly, nil},
+		{http.MethodGet, "/export", h.AdminExportHandler, adminauth.RoleAdmin, nil},
+		{http.MethodPost, "/import", h.AdminImportHandler, adminauth.RoleAdmin, nil},
+		{http.MethodGet, "/debug/key", h.AdminDebugKeyHandler, adminauth.RoleReadOnly, queryTarget},
+		{http.MethodGet, "/observe", h.AdminObserveHandler, adminauth.RoleReadOnly, nil},
+		{http.MethodGet, "/debug/decisions", h.AdminDebugDecisionsHandler, adminauth.RoleReadOnly, nil},
+		{http.MethodGet, "/config/stage", h.AdminStagedConfigHandler, adminauth.RoleReadOnly, nil},
+		{http.MethodPost, "/config/stage", h.AdminStageConfigHandler, adminauth.RoleAdmin, nil},
+		{http.MethodDelete, "/config/stage", h.AdminDiscardConfigHandler, adminauth.RoleAdmin, nil},
+		{http.MethodPost, "/config/promote", h.AdminPromoteConfigHandler, adminauth.RoleAdmin, nil},
+		{http.MethodPost, "/config/rollback", h.AdminRollbackConfigHandler, adminauth.RoleAdmin, nil},
+		{http.MethodGet, "/routes", h.AdminRoutesHandler, adminauth.RoleReadOnly, nil},
+		{http.MethodPost, "/drain", h.AdminDrainHandler, adminauth.RoleOperator, nil},
+		{http.MethodGet, "/audit", h.AdminAuditHandler, adminauth.RoleAdmin, nil},
+	}
+	if h.profiling {
+		routes = append(routes, profilingRoutes()...)
+	}
+	return routes
+}
+
+// GinHandler adapta um ExchangeHandler para o Gin
+func GinHandler(handler ExchangeHandler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		params := make(map[string]string, len(c.Params))
+		for _, param := range c.Params {
+			params[param.Key] = param.Value
+		}
+
+		exchange := NewExchange(c.Request, params)
+		handler(exchange)
+		exchange.Response.Write(c.Writer)
+	}
+}
+
+// AdminHTTPHandler expõe a API administrativa como http.Handler, para montá-la
+// em servidores que não usam Gin (ex: mux.Handle("/admin/", h.AdminHTTPHandler("/admin")))
+func (h *Handlers) AdminHTTPHandler(prefix string) http.Handler {
+	routes := h.routesHTTPHandler(prefix, h.adminRoutes())
+	dashboardPath := strings.TrimRight(prefix, "/") + "/ui"
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// O dashboard fica fora da autenticação: suas chamadas à API enviam a credencial
+		if r.URL.Path == dashboardPath {
+			DashboardHandler(w, r)
+			return
+		}
+		routes.ServeHTTP(w, r)
+	})
+	if h.compressedGroups[RouteGroupAdmin] {
+		return middleware.CompressHandler(h.compression, handler)
+	}
+	return handler
+}
+
+// ProfilingHTTPHandler expõe apenas o net/http/pprof, com a autenticação administrativa,
+// para servi-lo em uma porta separada (ex: mux.Handle("/", h.ProfilingHTTPHandler("")))
+func (h *Handlers) ProfilingHTTPHandler(prefix string) http.Handler {
+	return h.routesHTTPHandler(prefix, profilingRoutes())
+}
+
+// routesHTTPHandler roteia as rotas informadas sob prefix, autenticadas e com permissões
+func (h *Handlers) routesHTTPHandler(prefix string, routes []route) http.Handler {
+	// Rotas estáticas têm precedência sobre parâmetros (ex: /rules/rollouts antes de /rules/:id)
+	sort.SliceStable(routes, func(i, j int) bool {
+		return strings.Count(routes[i].path, ":") < strings.Count(routes[j].path, ":")
+	})
+
+	prefix = strings.TrimRight(prefix, "/")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, ok := strings.CutPrefix(r.URL.Path, prefix)
+		if !ok {
+			writeRouteError(w, http.StatusNotFound, "not_found", "Route not found")
+			return
+		}
+		r, ok = h.authorizeAdmin(w, r)
+		if !ok {
+			return
+		}
+
+		var allowed []string
+		for _, candidate := range routes {
+			params, matched := matchRoute(candidate.path, path)
+			if !matched {
+				continue
+			}
+			if candidate.method != r.Method {
+				allowed = append(allowed, candidate.method)
+				continue
+			}
+			if !h.permitAdmin(w, r, candidate, params) {
+				return
+			}
+
+			record := h.startAudit(r, candidate, params)
+			exchange := NewExchange(r, params)
+			candidate.handler(exchange)
+			exchange.Response.Write(w)
+			h.finishAudit(record, exchange.Response.Status)
+			return
+		}
+
+		if len(allowed) > 0 {
+			w.Header().Set("Allow", strings.Join(allowed, ", "))
+			writeRouteError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+			return
+		}
+		writeRouteError(w, http.StatusNotFound, "not_found", "Route not found")
+	})
+}
+
+// authorizeAdmin autentica a requisição administrativa e retorna a requisição com
+// a identidade no contexto (adminauth.PrincipalFrom); sem autenticador configurado
+// a API segue aberta. Em caso de falha responde 401 e retorna false
+func (h *Handlers) authorizeAdmin(w http.ResponseWriter, r *http.Request) (*http.Request, bool) {
+	if h.adminAuth == nil {
+		return r, true
+	}
+
+	principal, err := h.adminAuth.Identify(r)
+	if err != nil {
+		h.logger.WithContext(r.Context()).Warn("Admin authentication failed", map[string]interface{}{
+			"method": r.Method,
+			"path":   r.URL.Path,
+			"reason": err.Error(),
+		})
+		w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+		writeRouteError(w, http.StatusUnauthorized, "unauthorized", err.Error())
+		return r, false
+	}
+	return r.WithContext(adminauth.WithPrincipal(r.Context(), principal)), true
+}
+
+// matchRoute compara o caminho com o padrão e extrai os parâmetros
+func matchRoute(pattern, path string) (map[string]string, bool) {
+	patternSegments := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(patternSegments) != len(pathSegments) {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+	for i, segment := range patternSegments {
+		if name, isParam := strings.CutPrefix(segment, ":"); isParam && pathSegments[i] != "" {
+			params[name] = pathSegments[i]
+			continue
+		}
+		if segment != pathSegments[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
+
+// writeRouteError responde erros de roteamento no formato da API
+func writeRouteError(w http.ResponseWriter, status int, code, message string) {
+	exchange := &Exchange{Request: &Request{}, Response: &Response{Header: make(http.Header)}}
+	exchange.JSON(status, H{"error": code, "message": message})
+	exchange.Response.Write(w)
+}
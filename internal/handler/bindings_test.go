@@ -0,0 +1,362 @@
+package handler
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/adminauth"
+	"rate-limiter/internal/audit"
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/middleware"
+	"rate-limiter/internal/staging"
+)
+
+// TestAdminHTTPHandler testa a API administrativa montada em um http.ServeMux, sem Gin
+func TestAdminHTTPHandler(t *testing.T) {
+	// Arrange
+	mockService := new(MockRateLimiterService)
+	mockService.On("Reset", mock.Anything, "192.168.1.1", domain.IPLimiter).Return(nil)
+	mockLogger := new(MockLogger)
+	mockLogger.On("WithContext", mock.Anything).Return(mockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Maybe()
+	handlers := NewHandlers(mockService, mockLogger)
+	handlers.SetRuleEditor(staging.NewManager(&domain.RateLimitConfig{
+		DefaultIPLimit: 10, DefaultTokenLimit: 100, Window: 60,
+	}, nil))
+
+	mux := http.NewServeMux()
+	mux.Handle("/admin/", handlers.AdminHTTPHandler("/admin"))
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	// Act & Assert: corpo JSON validado pelas tags binding
+	w := serve("POST", "/admin/reset", `{"key":"192.168.1.1","type":"ip"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, http.StatusBadRequest, serve("POST", "/admin/reset", `{"type":"ip"}`).Code)
+
+	// Parâmetro de rota
+	w = serve("GET", "/admin/rules/ip:*", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"limit":10}`, w.Body.String())
+	assert.NotEmpty(t, w.Header().Get("ETag"))
+
+	// Rota estática prevalece sobre o parâmetro
+	w = serve("GET", "/admin/rules/rollouts", "")
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+
+	// Método e rota inexistentes
+	w = serve("DELETE", "/admin/status", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	assert.Equal(t, "GET, POST", w.Header().Get("Allow"))
+	w = serve("GET", "/admin/unknown", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "not_found", response["error"])
+}
+
+// TestAdminAuthentication testa a autenticação das rotas /admin no Gin e no net/http
+func TestAdminAuthentication(t *testing.T) {
+	// Arrange
+	secret := "0123456789abcdef0123456789abcdef"
+	mockLogger := new(MockLogger)
+	mockLogger.On("WithContext", mock.Anything).Return(mockLogger)
+	mockLogger.On("Warn", "Admin authentication failed", mock.Anything)
+	handlers := NewHandlers(new(MockRateLimiterService), mockLogger)
+	handlers.SetAdminAuthenticator(adminauth.New(adminauth.Config{APIKey: "admin-key", HMACSecret: secret}))
+
+	mux := http.NewServeMux()
+	mux.Handle("/admin/", handlers.AdminHTTPHandler("/admin"))
+	servers := map[string]http.Handler{"gin": setupTestRouter(handlers), "net/http": mux}
+
+	for name, server := range servers {
+		t.Run(name, func(t *testing.T) {
+			serve := func(req *http.Request) *httptest.ResponseRecorder {
+				w := httptest.NewRecorder()
+				server.ServeHTTP(w, req)
+				return w
+			}
+
+			// Act & Assert: sem credenciais
+			w := serve(httptest.NewRequest("GET", "/admin/rules/rollouts", nil))
+			require.Equal(t, http.StatusUnauthorized, w.Code)
+			assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, "unauthorized", response["error"])
+
+			// Chave estática
+			req := httptest.NewRequest("GET", "/admin/rules/rollouts", nil)
+			req.Header.Set("Authorization", "Bearer admin-key")
+			assert.Equal(t, http.StatusNotImplemented, serve(req).Code)
+
+			// Requisição assinada, aceita uma única vez
+			req = httptest.NewRequest("GET", "/admin/rules/rollouts", nil)
+			require.NoError(t, adminauth.Sign(req, secret, "nonce-"+name, time.Now()))
+			assert.Equal(t, http.StatusNotImplemented, serve(req).Code)
+			assert.Equal(t, http.StatusUnauthorized, serve(req).Code)
+		})
+	}
+}
+
+// TestDashboardHandler testa que o dashboard é servido sem credencial e com CSP,
+// enquanto a API que ele consome continua autenticada
+func TestDashboardHandler(t *testing.T) {
+	// Arrange
+	mockLogger := new(MockLogger)
+	mockLogger.On("WithContext", mock.Anything).Return(mockLogger)
+	mockLogger.On("Warn", "Admin authentication failed", mock.Anything)
+	handlers := NewHandlers(new(MockRateLimiterService), mockLogger)
+	handlers.SetAdminAuthenticator(adminauth.New(adminauth.Config{APIKey: "admin-key"}))
+
+	mux := http.NewServeMux()
+	mux.Handle("/admin/", handlers.AdminHTTPHandler("/admin"))
+	servers := map[string]http.Handler{"gin": setupTestRouter(handlers), "net/http": mux}
+
+	for name, server := range servers {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			page := httptest.NewRecorder()
+			server.ServeHTTP(page, httptest.NewRequest("GET", "/admin/ui", nil))
+			api := httptest.NewRecorder()
+			server.ServeHTTP(api, httptest.NewRequest("GET", "/admin/stats", nil))
+
+			// Assert
+			require.Equal(t, http.StatusOK, page.Code)
+			assert.Equal(t, "text/html; charset=utf-8", page.Header().Get("Content-Type"))
+			assert.Contains(t, page.Header().Get("Content-Security-Policy"), "connect-src 'self'")
+			assert.Equal(t, "DENY", page.Header().Get("X-Frame-Options"))
+			assert.Contains(t, page.Body.String(), "Rate Limiter Dashboard")
+			assert.Equal(t, http.StatusUnauthorized, api.Code)
+		})
+	}
+}
+
+func TestAdminPermissions(t *testing.T) {
+	// Arrange
+	mockService := new(MockRateLimiterService)
+	mockService.On("GetConfig", mock.Anything, "tk_acme", domain.TokenLimiter).Return(&domain.RateLimitRule{
+		Parents: []domain.LimitLevel{
+			{Level: domain.LimitLevelProject, Name: "checkout", Limit: 100},
+			{Level: domain.LimitLevelOrganization, Name: "acme", Limit: 1000},
+		},
+	}, nil)
+	mockService.On("GetConfig", mock.Anything, "tk_globex", domain.TokenLimiter).Return(&domain.RateLimitRule{}, nil)
+	mockLogger := new(MockLogger)
+	mockLogger.On("WithContext", mock.Anything).Return(mockLogger)
+	mockLogger.On("Warn", "Admin permission denied", mock.Anything)
+	handlers := NewHandlers(mockService, mockLogger)
+	handlers.SetAdminAuthenticator(adminauth.New(adminauth.Config{Keys: []adminauth.KeyConfig{
+		{Name: "support", Key: "support-key", Role: adminauth.RoleReadOnly},
+		{Name: "acme-admin", Key: "acme-key", Role: adminauth.RoleAdmin, Tenants: []string{"acme"}},
+	}}))
+
+	mux := http.NewServeMux()
+	mux.Handle("/admin/", handlers.AdminHTTPHandler("/admin"))
+	servers := map[string]http.Handler{"gin": setupTestRouter(handlers), "net/http": mux}
+
+	for name, server := range servers {
+		t.Run(name, func(t *testing.T) {
+			serve := func(method, path, key string, body string) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(method, path, strings.NewReader(body))
+				req.Header.Set("Authorization", "Bearer "+key)
+				req.Header.Set("Content-Type", "application/json")
+				w := httptest.NewRecorder()
+				server.ServeHTTP(w, req)
+				return w
+			}
+
+			// Act & Assert: papel read-only consulta, mas não executa ações
+			assert.Equal(t, http.StatusNotImplemented, serve("GET", "/admin/rules/rollouts", "support-key", "").Code)
+			w := serve("POST", "/admin/reset", "support-key", `{"key":"10.0.0.1","type":"ip"}`)
+			require.Equal(t, http.StatusForbidden, w.Code)
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, "forbidden", response["error"])
+
+			// Credencial restrita alcança apenas tokens da sua organização
+			assert.Equal(t, http.StatusNotImplemented, serve("GET", "/admin/rules/token:tk_acme", "acme-key", "").Code)
+			assert.Equal(t, http.StatusForbidden, serve("GET", "/admin/rules/token:tk_globex", "acme-key", "").Code)
+			assert.Equal(t, http.StatusForbidden, serve("GET", "/admin/rules/ip:10.0.0.1", "acme-key", "").Code)
+			assert.Equal(t, http.StatusForbidden, serve("POST", "/admin/reset", "acme-key", `{"key":"tk_globex","type":"token"}`).Code)
+			assert.Equal(t, http.StatusForbidden, serve("GET", "/admin/rules/rollouts", "acme-key", "").Code)
+		})
+	}
+}
+
+func TestAdminCompression(t *testing.T) {
+	// Arrange: compressão só no grupo admin
+	handlers := NewHandlers(new(MockRateLimiterService), new(MockLogger))
+	handlers.SetCompression(middleware.CompressionConfig{MinSize: 1}, RouteGroupAdmin)
+
+	router := setupTestRouter(handlers)
+	mux := http.NewServeMux()
+	mux.Handle("/admin/", handlers.AdminHTTPHandler("/admin"))
+	servers := map[string]http.Handler{"gin": router, "net/http": mux}
+
+	for name, server := range servers {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			req := httptest.NewRequest("GET", "/admin/rules/rollouts", nil)
+			req.Header.Set("Accept-Encoding", "gzip")
+			w := httptest.NewRecorder()
+			server.ServeHTTP(w, req)
+
+			// Assert
+			require.Equal(t, http.StatusNotImplemented, w.Code)
+			assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+			reader, err := gzip.NewReader(w.Body)
+			require.NoError(t, err)
+			var response map[string]interface{}
+			require.NoError(t, json.NewDecoder(reader).Decode(&response))
+			assert.Contains(t, response, "error")
+		})
+	}
+
+	// Grupo public sem compressão
+	req := httptest.NewRequest("GET", "/health", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+}
+
+func TestProfilingRoutes(t *testing.T) {
+	// Arrange
+	mockLogger := new(MockLogger)
+	mockLogger.On("WithContext", mock.Anything).Return(mockLogger)
+	mockLogger.On("Warn", mock.Anything, mock.Anything)
+	handlers := NewHandlers(new(MockRateLimiterService), mockLogger)
+	handlers.SetAdminAuthenticator(adminauth.New(adminauth.Config{Keys: []adminauth.KeyConfig{
+		{Name: "oncall", Key: "operator-key", Role: adminauth.RoleOperator},
+		{Name: "sre", Key: "admin-key", Role: adminauth.RoleAdmin},
+	}}))
+	disabled := setupTestRouter(handlers)
+	handlers.SetProfiling(true)
+
+	mux := http.NewServeMux()
+	mux.Handle("/admin/", handlers.AdminHTTPHandler("/admin"))
+	servers := map[string]http.Handler{
+		"gin":       setupTestRouter(handlers),
+		"net/http":  mux,
+		"dedicated": http.StripPrefix("/admin", handlers.ProfilingHTTPHandler("")),
+	}
+
+	serve := func(server http.Handler, path, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", "Bearer "+key)
+		w := httptest.NewRecorder()
+		server.ServeHTTP(w, req)
+		return w
+	}
+
+	// Act & Assert: desabilitado por padrão
+	assert.Equal(t, http.StatusNotFound, serve(disabled, "/admin/debug/pprof/", "admin-key").Code)
+
+	for name, server := range servers {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			index := serve(server, "/admin/debug/pprof/", "admin-key")
+			goroutines := serve(server, "/admin/debug/pprof/goroutine?debug=1", "admin-key")
+			cmdline := serve(server, "/admin/debug/pprof/cmdline", "admin-key")
+
+			// Assert
+			assert.Equal(t, http.StatusOK, index.Code)
+			assert.Contains(t, index.Body.String(), "goroutine")
+			assert.Equal(t, http.StatusOK, goroutines.Code)
+			assert.Contains(t, goroutines.Body.String(), "goroutine profile:")
+			assert.Equal(t, http.StatusOK, cmdline.Code)
+			assert.NotEmpty(t, cmdline.Body.String())
+			assert.Equal(t, http.StatusNotFound, serve(server, "/admin/debug/pprof/unknown", "admin-key").Code)
+
+			// Perfis expõem o processo inteiro: só o papel admin
+			assert.Equal(t, http.StatusForbidden, serve(server, "/admin/debug/pprof/heap", "operator-key").Code)
+			assert.Equal(t, http.StatusUnauthorized, serve(server, "/admin/debug/pprof/heap", "").Code)
+		})
+	}
+}
+
+func TestAdminAudit(t *testing.T) {
+	// Arrange
+	mockService := new(MockRateLimiterService)
+	mockService.On("Reset", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockLogger := new(MockLogger)
+	mockLogger.On("WithContext", mock.Anything).Return(mockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Maybe()
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Maybe()
+	handlers := NewHandlers(mockService, mockLogger)
+	handlers.SetAdminAuthenticator(adminauth.New(adminauth.Config{Keys: []adminauth.KeyConfig{
+		{Name: "support", Key: "support-key", Role: adminauth.RoleReadOnly},
+		{Name: "oncall", Key: "operator-key", Role: adminauth.RoleOperator},
+		{Name: "sre", Key: "admin-key", Role: adminauth.RoleAdmin},
+	}}))
+
+	mux := http.NewServeMux()
+	mux.Handle("/admin/", handlers.AdminHTTPHandler("/admin"))
+	servers := map[string]http.Handler{"gin": setupTestRouter(handlers), "net/http": mux}
+
+	for name, server := range servers {
+		t.Run(name, func(t *testing.T) {
+			auditLog := audit.NewLog(10)
+			handlers.SetAuditLog(auditLog)
+			serve := func(method, path, key, body string) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(method, path, strings.NewReader(body))
+				req.RemoteAddr = "10.1.2.3:4567"
+				req.Header.Set("Authorization", "Bearer "+key)
+				req.Header.Set("Content-Type", "application/json")
+				w := httptest.NewRecorder()
+				server.ServeHTTP(w, req)
+				return w
+			}
+
+			// Act
+			require.Equal(t, http.StatusOK, serve("POST", "/admin/reset", "operator-key", `{"key":"tk_1234567890abcdef","type":"token"}`).Code)
+			require.Equal(t, http.StatusForbidden, serve("POST", "/admin/reset", "support-key", `{"key":"10.0.0.1","type":"ip"}`).Code)
+			require.Equal(t, http.StatusBadRequest, serve("GET", "/admin/status", "support-key", "").Code)
+			require.Equal(t, http.StatusForbidden, serve("GET", "/admin/audit", "operator-key", "").Code)
+			w := serve("GET", "/admin/audit?principal=oncall", "admin-key", "")
+
+			// Assert: leituras não são auditadas; a tentativa negada é
+			records := auditLog.Recent(10, "")
+			require.Len(t, records, 2)
+			assert.Equal(t, "support", records[0].Principal)
+			assert.Equal(t, http.StatusForbidden, records[0].Status)
+			assert.Equal(t, []audit.Target{{Type: "ip", Key: "10.0.0.1"}}, records[0].Targets)
+
+			reset := records[1]
+			assert.Equal(t, "oncall", reset.Principal)
+			assert.Equal(t, "operator", reset.Role)
+			assert.Equal(t, "POST", reset.Method)
+			assert.Equal(t, "/admin/reset", reset.Path)
+			assert.Equal(t, []audit.Target{{Type: "token", Key: "tk_12345***"}}, reset.Targets)
+			assert.Equal(t, "10.1.2.3", reset.SourceIP)
+			assert.Equal(t, http.StatusOK, reset.Status)
+
+			// A consulta filtra pela credencial
+			require.Equal(t, http.StatusOK, w.Code)
+			var response struct {
+				Count   int            `json:"count"`
+				Records []audit.Record `json:"records"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, 1, response.Count)
+			assert.Equal(t, "/admin/reset", response.Records[0].Path)
+			assert.Equal(t, http.StatusBadRequest, serve("GET", "/admin/audit?count=0", "admin-key", "").Code)
+		})
+	}
+}
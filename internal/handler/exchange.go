@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+// H é um atalho para corpos de resposta JSON
+type H map[string]interface{}
+
+// Request é a requisição recebida, independente do framework HTTP
+type Request struct {
+	Method string
+	Path   string
+	Header http.Header
+	Query  url.Values
+	Params map[string]string // Parâmetros de rota (ex: :id)
+	Body   io.Reader
+
+	ctx context.Context
+}
+
+// Context retorna o contexto da requisição (cancelamento, tracing, request ID)
+func (r *Request) Context() context.Context {
+	if r.ctx == nil {
+		return context.Background()
+	}
+	return r.ctx
+}
+
+// Response é a resposta produzida por um handler
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Exchange liga a requisição à resposta que o handler preenche.
+// Os handlers administrativos dependem apenas dele, e não do Gin,
+// para que a API possa ser montada em qualquer servidor HTTP
+type Exchange struct {
+	Request  *Request
+	Response *Response
+}
+
+// ExchangeHandler é um handler independente do framework HTTP
+type ExchangeHandler func(c *Exchange)
+
+// NewExchange cria o exchange a partir de uma requisição net/http
+func NewExchange(req *http.Request, params map[string]string) *Exchange {
+	var body io.Reader = http.NoBody
+	if req.Body != nil {
+		body = req.Body
+	}
+
+	return &Exchange{
+		Request: &Request{
+			Method: req.Method,
+			Path:   req.URL.Path,
+			Header: req.Header,
+			Query:  req.URL.Query(),
+			Params: params,
+			Body:   body,
+			ctx:    req.Context(),
+		},
+		Response: &Response{Status: http.StatusOK, Header: make(http.Header)},
+	}
+}
+
+// Query retorna o parâmetro de query (vazio se ausente)
+func (c *Exchange) Query(name string) string {
+	return c.Request.Query.Get(name)
+}
+
+// DefaultQuery retorna o parâmetro de query ou o valor padrão se ausente
+func (c *Exchange) DefaultQuery(name, defaultValue string) string {
+	if values, ok := c.Request.Query[name]; ok && len(values) > 0 {
+		return values[0]
+	}
+	return defaultValue
+}
+
+// Param retorna o parâmetro de rota
+func (c *Exchange) Param(name string) string {
+	return c.Request.Params[name]
+}
+
+// GetHeader retorna o header da requisição
+func (c *Exchange) GetHeader(name string) string {
+	return c.Request.Header.Get(name)
+}
+
+// ContentType retorna o media type da requisição, sem parâmetros (ex: charset)
+func (c *Exchange) ContentType() string {
+	contentType := c.GetHeader("Content-Type")
+	for i, char := range contentType {
+		if char == ' ' || char == ';' {
+			return contentType[:i]
+		}
+	}
+	return contentType
+}
+
+// ShouldBindJSON decodifica o corpo e aplica as validações das tags `binding`
+func (c *Exchange) ShouldBindJSON(obj interface{}) error {
+	if err := json.NewDecoder(c.Request.Body).Decode(obj); err != nil {
+		return err
+	}
+	if binding.Validator == nil {
+		return nil
+	}
+	return binding.Validator.ValidateStruct(obj)
+}
+
+// Header define um header da resposta; valor vazio remove o header
+func (c *Exchange) Header(name, value string) {
+	if value == "" {
+		c.Response.Header.Del(name)
+		return
+	}
+	c.Response.Header.Set(name, value)
+}
+
+// Status define o status da resposta sem corpo
+func (c *Exchange) Status(status int) {
+	c.Response.Status = status
+}
+
+// JSON serializa o corpo da resposta
+func (c *Exchange) JSON(status int, body interface{}) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		status = http.StatusInternalServerError
+		data, _ = json.Marshal(H{"error": "internal_server_error", "message": "Failed to encode response"})
+	}
+	c.Data(status, "application/json; charset=utf-8", data)
+}
+
+// Data escreve o corpo da resposta com o content type informado
+func (c *Exchange) Data(status int, contentType string, data []byte) {
+	c.Response.Status = status
+	c.Response.Header.Set("Content-Type", contentType)
+	c.Response.Body = data
+}
+
+// Write escreve a resposta em um http.ResponseWriter
+func (r *Response) Write(w http.ResponseWriter) {
+	for name, values := range r.Header {
+		w.Header()[name] = values
+	}
+	w.WriteHeader(r.Status)
+	if len(r.Body) > 0 {
+		_, _ = w.Write(r.Body)
+	}
+}
@@ -1,25 +1,218 @@
 package handler
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
+	"rate-limiter/internal/adminauth"
+	"rate-limiter/internal/analytics"
+	"rate-limiter/internal/audit"
+	"rate-limiter/internal/capacity"
+	"rate-limiter/internal/drain"
+	"rate-limiter/internal/learning"
+	"rate-limiter/internal/cluster"
+	"rate-limiter/internal/config"
+	"rate-limiter/internal/decisiontrace"
 	"rate-limiter/internal/domain"
+	"rate-limiter/internal/events"
 	"rate-limiter/internal/middleware"
+	"rate-limiter/internal/observe"
+	"rate-limiter/internal/reports"
+	"rate-limiter/internal/rollout"
+	"rate-limiter/internal/shadow"
+	"rate-limiter/internal/staging"
+	"rate-limiter/internal/stats"
+	"rate-limiter/internal/storage"
+	"rate-limiter/pkg/version"
 )
 
 // Handlers contém os handlers da API
 type Handlers struct {
-	service   domain.RateLimiterService
-	logger    domain.Logger
-	startTime time.Time
+	service          domain.RateLimiterService
+	logger           domain.Logger
+	startTime        time.Time
+	middlewareConfig middleware.Config
+	healthReporter   domain.StorageHealthReporter
+	storages         StorageRegistry
+	gatherer         prometheus.Gatherer
+	fleet            FleetProvider
+	maintenance      MaintenanceScheduler
+	rollouts         RuleRolloutManager
+	shadow           ShadowReporter
+	blockReports     BlockReporter
+	analytics        AnalyticsProvider
+	stats            StatsProvider
+	audit            AuditLog
+	readinessGate    ReadinessGate
+	observer         KeyObserver
+	decisionTraces   DecisionRecorder
+	configStager     ConfigStager
+	ruleEditor       RuleEditor
+	capacity         CapacityReporter
+	learning         LimitLearner
+	drainer          Drainer
+	inFlight         *middleware.InFlight
+	blockEvents      BlockEventReader
+	proxy            gin.HandlerFunc
+	adminAuth        AdminAuthenticator
+	compression      middleware.CompressionConfig
+	compressedGroups map[string]bool
+	routes           *routeInventory
+	profiling        bool
 }
 
+// Grupos de rotas que podem ter as respostas comprimidas (SetCompression)
+const (
+	RouteGroupPublic    = "public"    // /health, /livez, /readyz e /metrics
+	RouteGroupProtected = "protected" // rotas com rate limiting e o reverse proxy
+	RouteGroupAdmin     = "admin"     // /admin, também no AdminHTTPHandler
+)
+
+// FleetProvider expõe as instâncias registradas no cluster
+type FleetProvider interface {
+	Self() cluster.Instance
+	ListInstances(ctx context.Context) ([]cluster.Instance, error)
+}
+
+// MaintenanceScheduler gerencia as janelas de manutenção declaradas
+type MaintenanceScheduler interface {
+	Add(ctx context.Context, window domain.MaintenanceWindow) (domain.MaintenanceWindow, error)
+	Remove(ctx context.Context, id string) bool
+	List() []domain.MaintenanceWindow
+}
+
+// RuleRolloutManager controla os rollouts canário de regras
+type RuleRolloutManager interface {
+	Start(rollout domain.RuleRollout) (domain.RuleRollout, error)
+	Promote(id string) (domain.RuleRollout, error)
+	Abort(id string) bool
+	List() []rollout.Snapshot
+}
+
+// ShadowReporter expõe a comparação entre a configuração ativa e a staged
+type ShadowReporter interface {
+	Stats() shadow.Stats
+	Reset()
+}
+
+// BlockReporter consulta o histórico de bloqueios
+type BlockReporter interface {
+	Query(from, to time.Time) []domain.BlockRecord
+}
+
+// AnalyticsProvider consulta os agregados por minuto
+type AnalyticsProvider interface {
+	Buckets(ctx context.Context, from, to time.Time) ([]analytics.Bucket, error)
+}
+
+// StatsProvider agrega as decisões recentes em memória (stats.Collector)
+type StatsProvider interface {
+	Snapshot(window time.Duration, top int, order stats.Order) stats.Snapshot
+	Retention() time.Duration
+}
+
+// AuditLog registra as operações administrativas e consulta as mais recentes (audit.Log)
+type AuditLog interface {
+	Record(record audit.Record)
+	Recent(count int, principal string) []audit.Record
+}
+
+// ReadinessGate informa se a instância terminou o aquecimento da inicialização
+type ReadinessGate interface {
+	Readiness() storage.ReadinessStatus
+}
+
+// KeyObserver amostra a taxa real de requisições de uma chave
+type KeyObserver interface {
+	Sample(ctx context.Context, key string, limiterType domain.LimiterType, duration time.Duration) (observe.Sample, error)
+}
+
+// DecisionRecorder grava as próximas decisões completas de uma chave
+type DecisionRecorder interface {
+	Record(ctx context.Context, key string, limiterType domain.LimiterType, count int, timeout time.Duration) ([]domain.DecisionTrace, error)
+}
+
+// ConfigStager mantém uma configuração candidata até a promoção explícita
+type ConfigStager interface {
+	Stage(candidate config.StagedConfig) (staging.Version, error)
+	Discard() bool
+	Promote() (staging.Version, error)
+	Rollback() (staging.Version, error)
+	Status() staging.Status
+}
+
+// RuleEditor edita regras individuais com controle de concorrência por ETag
+// e mantém o histórico de versões de cada regra
+type RuleEditor interface {
+	Rule(id string) (document []byte, etag string, err error)
+	CreateRule(id string, document []byte, actor string) (documentOut []byte, etag string, err error)
+	ReplaceRule(id, ifMatch string, document []byte, actor string) (documentOut []byte, etag string, err error)
+	PatchRule(id, ifMatch string, patch []byte, actor string) (document []byte, etag string, err error)
+	DeleteRule(id, ifMatch, actor string) (staging.RuleRevision, error)
+	RuleHistory(id string) ([]staging.RuleRevision, error)
+	RestoreRule(id string, version int, actor string) (document []byte, etag string, err error)
+}
+
+// AdminActorHeader identifica quem fez a alteração no histórico de regras
+const AdminActorHeader = "X-Admin-Actor"
+
+// CapacityReporter sugere limites a partir do tráfego acumulado por regra
+type CapacityReporter interface {
+	Report(targetPercent float64) capacity.Report
+	Reset()
+}
+
+// LimitLearner propõe e aplica limites por chave aprendidos do tráfego
+type LimitLearner interface {
+	Report() learning.Report
+	Apply(ctx context.Context) (int, error)
+	Reset()
+}
+
+// Drainer prepara a instância para o desligamento
+type Drainer interface {
+	Drain(ctx context.Context) drain.Result
+}
+
+// BlockEventReader lê as entradas recentes do stream de eventos de bloqueio
+type BlockEventReader interface {
+	Recent(ctx context.Context, count int) ([]events.StreamEntry, error)
+}
+
+// AdminAuthenticator valida as credenciais das requisições administrativas e
+// identifica o papel e os tenants da credencial
+type AdminAuthenticator interface {
+	Identify(r *http.Request) (*adminauth.Principal, error)
+}
+
+// Limites da amostragem de /admin/observe (o servidor encerra respostas após 30s)
+const (
+	defaultObserveSeconds = 5
+	maxObserveSeconds     = 20
+)
+
+// Limites da gravação de /admin/debug/decisions
+const (
+	defaultTraceCount   = 10
+	maxTraceCount       = 100
+	defaultTraceSeconds = 10
+	maxTraceSeconds     = 25
+)
+
 // NewHandlers cria uma nova instância dos handlers
 func NewHandlers(service domain.RateLimiterService, logger domain.Logger) *Handlers {
 	return &Handlers{
@@ -29,37 +222,297 @@ func NewHandlers(service domain.RateLimiterService, logger domain.Logger) *Handl
 	}
 }
 
+// SetMiddlewareConfig define as configurações do middleware de rate limiting
+func (h *Handlers) SetMiddlewareConfig(config middleware.Config) {
+	h.middlewareConfig = config
+}
+
+// SetHealthReporter define a fonte de estado do storage usada pela readiness
+func (h *Handlers) SetHealthReporter(reporter domain.StorageHealthReporter) {
+	h.healthReporter = reporter
+}
+
+// SetStorageRegistry faz o /health verificar os storages registrados
+func (h *Handlers) SetStorageRegistry(registry StorageRegistry) {
+	h.storages = registry
+}
+
+// SetReadinessGate segura a readiness até o aquecimento do storage concluir
+func (h *Handlers) SetReadinessGate(gate ReadinessGate) {
+	h.readinessGate = gate
+}
+
+// SetPrometheusGatherer habilita o endpoint /metrics/prometheus
+func (h *Handlers) SetPrometheusGatherer(gatherer prometheus.Gatherer) {
+	h.gatherer = gatherer
+}
+
+// SetFleet habilita o endpoint /admin/instances
+func (h *Handlers) SetFleet(fleet FleetProvider) {
+	h.fleet = fleet
+}
+
+// SetMaintenance habilita os endpoints /admin/maintenance
+func (h *Handlers) SetMaintenance(maintenance MaintenanceScheduler) {
+	h.maintenance = maintenance
+}
+
+// SetRuleRollouts habilita os endpoints /admin/rules
+func (h *Handlers) SetRuleRollouts(rollouts RuleRolloutManager) {
+	h.rollouts = rollouts
+}
+
+// SetShadow habilita os endpoints /admin/shadow
+func (h *Handlers) SetShadow(reporter ShadowReporter) {
+	h.shadow = reporter
+}
+
+// SetBlockReports habilita o endpoint /admin/reports/blocks
+func (h *Handlers) SetBlockReports(reporter BlockReporter) {
+	h.blockReports = reporter
+}
+
+// SetAnalytics habilita o endpoint /admin/analytics
+func (h *Handlers) SetAnalytics(provider AnalyticsProvider) {
+	h.analytics = provider
+}
+
+// SetStats habilita o endpoint /admin/stats
+func (h *Handlers) SetStats(provider StatsProvider) {
+	h.stats = provider
+}
+
+// SetAuditLog audita as operações administrativas que alteram estado e habilita
+// o endpoint /admin/audit. Deve ser chamado antes de SetupRoutes
+func (h *Handlers) SetAuditLog(log AuditLog) {
+	h.audit = log
+}
+
+// SetObserver habilita o endpoint /admin/observe
+func (h *Handlers) SetObserver(observer KeyObserver) {
+	h.observer = observer
+}
+
+// SetDecisionRecorder habilita o endpoint /admin/debug/decisions
+func (h *Handlers) SetDecisionRecorder(recorder DecisionRecorder) {
+	h.decisionTraces = recorder
+}
+
+// SetConfigStager habilita os endpoints /admin/config
+func (h *Handlers) SetConfigStager(stager ConfigStager) {
+	h.configStager = stager
+}
+
+// SetRuleEditor habilita os endpoints /admin/rules e /admin/rules/:id
+func (h *Handlers) SetRuleEditor(editor RuleEditor) {
+	h.ruleEditor = editor
+}
+
+// SetCapacity habilita os endpoints /admin/capacity
+func (h *Handlers) SetCapacity(reporter CapacityReporter) {
+	h.capacity = reporter
+}
+
+// SetLearning habilita os endpoints /admin/learning
+func (h *Handlers) SetLearning(learner LimitLearner) {
+	h.learning = learner
+}
+
+// SetDrainer habilita o endpoint /admin/drain
+func (h *Handlers) SetDrainer(drainer Drainer) {
+	h.drainer = drainer
+}
+
+// SetInFlight conta as requisições públicas e protegidas em andamento (drenagem).
+// Deve ser chamado antes de SetupRoutes
+func (h *Handlers) SetInFlight(tracker *middleware.InFlight) {
+	h.inFlight = tracker
+}
+
+// SetBlockEvents habilita o endpoint /admin/events/blocks
+func (h *Handlers) SetBlockEvents(reader BlockEventReader) {
+	h.blockEvents = reader
+}
+
+// SetProfiling habilita os endpoints /admin/debug/pprof (net/http/pprof).
+// Deve ser chamado antes de SetupRoutes
+func (h *Handlers) SetProfiling(enabled bool) {
+	h.profiling = enabled
+}
+
+// SetProxy habilita o modo reverse proxy: rotas não registradas passam pelo
+// rate limiting e são encaminhadas por handler (ex: proxy.New)
+func (h *Handlers) SetProxy(handler gin.HandlerFunc) {
+	h.proxy = handler
+}
+
+// SetAdminAuthenticator exige autenticação nas rotas /admin (ex: adminauth.New)
+func (h *Handlers) SetAdminAuthenticator(authenticator AdminAuthenticator) {
+	h.adminAuth = authenticator
+}
+
+// SetCompression comprime as respostas dos grupos de rotas informados
+// (RouteGroupAdmin, RouteGroupPublic, RouteGroupProtected) conforme o Accept-Encoding
+func (h *Handlers) SetCompression(config middleware.CompressionConfig, groups ...string) {
+	h.compression = config
+	h.compressedGroups = make(map[string]bool, len(groups))
+	for _, group := range groups {
+		h.compressedGroups[strings.ToLower(group)] = true
+	}
+}
+
+// groupMiddleware retorna os middlewares comuns do grupo de rotas
+func (h *Handlers) groupMiddleware(group string) []gin.HandlerFunc {
+	var handlers []gin.HandlerFunc
+	// Requisições administrativas (inclusive o próprio /admin/drain) não seguram a drenagem
+	if h.inFlight != nil && group != RouteGroupAdmin {
+		handlers = append(handlers, h.inFlight.Middleware())
+	}
+	if h.compressedGroups[group] {
+		handlers = append(handlers, middleware.Compression(h.compression))
+	}
+	return handlers
+}
+
 // SetupRoutes configura as rotas da API
 func (h *Handlers) SetupRoutes(router *gin.Engine) {
 	// Middleware de rate limiting para rotas protegidas
-	rateLimiterMiddleware := middleware.NewRateLimiterMiddleware(h.service, h.logger)
+	rateLimiterMiddleware := h.RateLimiterMiddleware()
+
+	// Request ID em todas as rotas (correlação de logs e auditoria)
+	router.Use(middleware.RequestID())
 
 	// Rotas públicas (sem rate limiting)
-	router.GET("/health", h.HealthHandler)
-	router.GET("/metrics", h.MetricsHandler)
+	h.SetupPublicRoutes(router, "/")
+
+	// Rotas protegidas por rate limiting (registradas aqui aparecem como protegidas em /admin/routes)
+	h.routes = newRouteInventory(router)
+	h.routes.markProtected(func() {
+		protected := router.Group("/", h.groupMiddleware(RouteGroupProtected)...)
+		protected.Use(rateLimiterMiddleware)
+		{
+			// No modo proxy a raiz pertence ao upstream
+			if h.proxy == nil {
+				protected.GET("/", h.ExampleHandler)
+			}
+			protected.GET("/check", h.CheckHandler)
+			protected.HEAD("/check", h.CheckHandler)
+			protected.GET("/me/limits", h.MeLimitsHandler)
+		}
+	})
 
-	// Rotas protegidas por rate limiting
-	protected := router.Group("/")
-	protected.Use(rateLimiterMiddleware)
-	{
-		protected.GET("/", h.ExampleHandler)
+	// Modo reverse proxy: demais rotas vão ao upstream depois do rate limiting
+	if h.proxy != nil {
+		router.NoRoute(append(h.groupMiddleware(RouteGroupProtected), rateLimiterMiddleware, h.proxy)...)
 	}
 
-	// Rotas administrativas (sem rate limiting)
-	admin := router.Group("/admin")
-	{
-		admin.GET("/status", h.AdminStatusHandler)
-		admin.POST("/reset", h.AdminResetHandler)
+	// Rotas administrativas (sem rate limiting), independentes do Gin
+	h.SetupAdminRoutes(router, "/admin")
+}
+
+// RateLimiterMiddleware cria o middleware de rate limiting com a configuração de SetMiddlewareConfig
+func (h *Handlers) RateLimiterMiddleware() gin.HandlerFunc {
+	return middleware.NewRateLimiterMiddleware(h.service, h.logger, middleware.WithConfig(h.middlewareConfig))
+}
+
+// SetupPublicRoutes registra health, liveness, readiness e métricas sob prefix, sem rate limiting
+func (h *Handlers) SetupPublicRoutes(router gin.IRouter, prefix string) {
+	public := router.Group(prefix, h.groupMiddleware(RouteGroupPublic)...)
+	public.GET("/health", h.HealthHandler)
+	public.GET("/ready", h.ReadyHandler)
+	public.GET("/livez", h.LivenessHandler)
+	public.GET("/readyz", h.ReadyHandler)
+	public.GET("/metrics", h.MetricsHandler)
+	if h.gatherer != nil {
+		public.GET("/metrics/prometheus", gin.WrapH(promhttp.HandlerFor(h.gatherer, promhttp.HandlerOpts{})))
+	}
+}
+
+// SetupAdminRoutes registra a API administrativa sob prefix (ex: "/admin"), autenticada
+// por SetAdminAuthenticator e sem rate limiting
+func (h *Handlers) SetupAdminRoutes(router gin.IRouter, prefix string) {
+	// O dashboard fica fora da autenticação: suas chamadas à API enviam a credencial
+	dashboard := append(h.groupMiddleware(RouteGroupAdmin), gin.WrapF(DashboardHandler))
+	router.GET(strings.TrimRight(prefix, "/")+"/ui", dashboard...)
+	router.HEAD(strings.TrimRight(prefix, "/")+"/ui", dashboard...)
+
+	admin := router.Group(prefix, h.groupMiddleware(RouteGroupAdmin)...)
+	admin.Use(func(c *gin.Context) {
+		request, ok := h.authorizeAdmin(c.Writer, c.Request)
+		if !ok {
+			c.Abort()
+			return
+		}
+		c.Request = request
+	})
+	for _, adminRoute := range h.adminRoutes() {
+		admin.Handle(adminRoute.method, adminRoute.path, h.adminPermissions(adminRoute), h.adminAudit(adminRoute), GinHandler(adminRoute.handler))
 	}
 }
 
-// HealthHandler implementa health check básico
+// HealthHandler implementa o health check com o estado das dependências (storages)
 func (h *Handlers) HealthHandler(c *gin.Context) {
+	status, dependencies := h.checkDependencies(c.Request.Context())
 	response := gin.H{
-		"status":    "healthy",
+		"status":    status,
 		"service":   "Rate Limiter API",
 		"timestamp": time.Now().UTC().Format(time.RFC3339),
-		"version":   "1.0.0",
+		"version":   version.Version,
+	}
+	if dependencies != nil {
+		response["dependencies"] = dependencies
+	}
+
+	c.JSON(healthStatusCode(status), response)
+}
+
+// LivenessHandler responde enquanto o processo atende requisições, sem consultar
+// dependências: uma falha do storage não deve reiniciar a instância, só tirá-la
+// do balanceamento (/readyz)
+func (h *Handlers) LivenessHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":         "alive",
+		"timestamp":      time.Now().UTC().Format(time.RFC3339),
+		"uptime_seconds": int64(time.Since(h.startTime).Seconds()),
+	})
+}
+
+// ReadyHandler implementa o readiness check baseado no estado do storage
+// e nas etapas de inicialização pendentes (ex: carga da configuração)
+func (h *Handlers) ReadyHandler(c *gin.Context) {
+	response := gin.H{
+		"status":    "ready",
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	}
+
+	// Aquecimento: o load balancer só recebe "ready" depois dos checks iniciais
+	if h.readinessGate != nil {
+		warmup := h.readinessGate.Readiness()
+		if warmup.Draining {
+			response["status"] = "draining"
+			c.JSON(http.StatusServiceUnavailable, response)
+			return
+		}
+		if !warmup.Ready {
+			response["status"] = "warming_up"
+			response["warmup"] = warmup
+			c.JSON(http.StatusServiceUnavailable, response)
+			return
+		}
+	}
+
+	if h.healthReporter == nil {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	health := h.healthReporter.HealthStatus()
+	response["storage"] = health
+
+	if !health.Healthy {
+		response["status"] = "not_ready"
+		c.JSON(http.StatusServiceUnavailable, response)
+		return
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -97,6 +550,121 @@ func (h *Handlers) ExampleHandler(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// CheckHandler expõe apenas a decisão do rate limiter (integrações leves)
+// Requisições negadas são respondidas pelo middleware; em HEAD não há corpo
+func (h *Handlers) CheckHandler(c *gin.Context) {
+	if c.Request.Method == http.MethodHead {
+		c.Status(http.StatusOK)
+		return
+	}
+
+	result, decided := middleware.GetRateLimitResult(c)
+	if !decided {
+		// Pré-verificação (ex: allowlist) ou fail-open: a requisição não foi limitada
+		c.JSON(http.StatusOK, gin.H{"allowed": true})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"allowed":      result.Allowed,
+		"limit":        result.Limit,
+		"remaining":    result.Remaining,
+		"reset_time":   result.ResetTime.Unix(),
+		"limiter_type": result.LimiterType,
+	})
+}
+
+// MeLimitsHandler mostra ao cliente os próprios limites, o uso atual e os resets,
+// identificando-o pelo token apresentado. Fica atrás do rate limiting, então a
+// consulta conta na cota como qualquer requisição
+func (h *Handlers) MeLimitsHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	token := middleware.GetAPIToken(c)
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "unauthorized",
+			"message": "An API token is required (API_KEY, X-Api-Token or Api-Token header)",
+		})
+		return
+	}
+
+	clientLimits, err := h.service.ClientLimits(ctx, token)
+	if err != nil {
+		h.logger.WithContext(ctx).Error("Failed to get client limits", err, map[string]interface{}{
+			"token": h.maskToken(token),
+		})
+		h.respondError(c, http.StatusInternalServerError, "internal_server_error", "Failed to retrieve rate limits")
+		return
+	}
+
+	limits := make([]gin.H, len(clientLimits))
+	for i, limit := range clientLimits {
+		limits[i] = clientLimit(limit)
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"token":     h.maskToken(token),
+		"limits":    limits,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// clientLimit descreve uma regra e o uso atual dela para o próprio cliente
+// Sem status no storage, o cliente ainda não consumiu a cota
+func clientLimit(applied domain.ClientLimit) gin.H {
+	rule, status := applied.Rule, applied.Status
+	limit := gin.H{
+		"scope":       applied.Scope,
+		"kind":        ruleKind(rule),
+		"description": rule.Description,
+		"limit":       rule.Limit,
+		"window":      rule.Window,
+		"current":     0,
+		"remaining":   rule.Limit,
+		"is_blocked":  false,
+	}
+	switch {
+	case rule.RefillRate > 0:
+		limit["refill_rate"] = rule.RefillRate
+	case rule.LeakRate > 0:
+		limit["leak_rate"] = rule.LeakRate
+	case rule.ResetSchedule != "":
+		limit["reset_schedule"] = rule.ResetSchedule
+	}
+	if rule.Disabled {
+		limit["disabled"] = true
+	}
+	if rule.DocsURL != "" {
+		limit["docs_url"] = rule.DocsURL
+	}
+
+	if status == nil {
+		return limit
+	}
+
+	usage := statusResponse(status)
+	for _, field := range []string{"current", "remaining", "reset_time", "is_blocked", "blocked_until", "credit"} {
+		if value, ok := usage[field]; ok {
+			limit[field] = value
+		}
+	}
+	return limit
+}
+
+// ruleKind nomeia o tipo de limite aplicado pela regra
+func ruleKind(rule *domain.RateLimitRule) string {
+	switch {
+	case rule.RefillRate > 0:
+		return "token_bucket"
+	case rule.LeakRate > 0:
+		return "leaky_bucket"
+	case rule.ResetSchedule != "":
+		return "quota"
+	default:
+		return "window"
+	}
+}
+
 // MetricsHandler implementa endpoint de métricas do sistema
 func (h *Handlers) MetricsHandler(c *gin.Context) {
 	ctx := c.Request.Context()
@@ -133,7 +701,7 @@ func (h *Handlers) MetricsHandler(c *gin.Context) {
 }
 
 // AdminStatusHandler implementa endpoint de status administrativo
-func (h *Handlers) AdminStatusHandler(c *gin.Context) {
+func (h *Handlers) AdminStatusHandler(c *Exchange) {
     ctx := c.Request.Context()
     
     // Extrair parâmetros da query
@@ -142,15 +710,17 @@ func (h *Handlers) AdminStatusHandler(c *gin.Context) {
 
     // Validação de parâmetros (evitar tocar no logger antes para não quebrar testes de validação)
     if key == "" {
-        c.JSON(http.StatusBadRequest, gin.H{
-            "error": "key parameter is required",
+        c.JSON(http.StatusBadRequest, H{
+            "error":   "key parameter is required",
+            "message": "key parameter is required",
         })
 		return
 	}
 
     if typeParam == "" {
-        c.JSON(http.StatusBadRequest, gin.H{
-            "error": "type parameter is required",
+        c.JSON(http.StatusBadRequest, H{
+            "error":   "type parameter is required",
+            "message": "type parameter is required",
         })
 		return
 	}
@@ -163,12 +733,37 @@ func (h *Handlers) AdminStatusHandler(c *gin.Context) {
 	case "token":
 		limiterType = domain.TokenLimiter
     default:
-        c.JSON(http.StatusBadRequest, gin.H{
-            "error": "type must be 'ip' or 'token'",
+        c.JSON(http.StatusBadRequest, H{
+            "error":   "type must be 'ip' or 'token'",
+            "message": "type must be 'ip' or 'token'",
         })
 		return
 	}
 
+	// Várias chaves (?key=a&key=b): resposta em lote
+	if keys := c.Request.Query["key"]; len(keys) > 1 {
+		queries := make([]AdminStatusQuery, 0, len(keys))
+		for _, batchKey := range keys {
+			queries = append(queries, AdminStatusQuery{Key: strings.TrimSpace(batchKey), Type: typeParam})
+		}
+		h.respondBatchStatus(c, queries)
+		return
+	}
+
+	// Histórico das últimas janelas (?history=true)
+	withHistory := false
+	if value := c.Query("history"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, H{
+				"error":   "invalid_request",
+				"message": "history must be a boolean",
+			})
+			return
+		}
+		withHistory = parsed
+	}
+
     // Log apenas após validação bem-sucedida
     if h.logger != nil {
         logger := h.logger.WithContext(ctx)
@@ -189,23 +784,76 @@ func (h *Handlers) AdminStatusHandler(c *gin.Context) {
 			})
 		}
 
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_server_error",
-			"message": "Failed to retrieve rate limiter status",
-		})
+		h.respondError(c, http.StatusInternalServerError, "internal_server_error", "Failed to retrieve rate limiter status")
 		return
 	}
 
+	// Chave sem contagem (nunca vista, expirada ou resetada)
+	if status == nil {
+		h.respondError(c, http.StatusNotFound, "not_found", "No rate limit data for this key")
+		return
+	}
+
+	response := statusResponse(status)
+	response["timestamp"] = time.Now().UTC().Format(time.RFC3339)
+
+	if withHistory {
+		history, err := h.service.WindowHistory(ctx, key, limiterType)
+		if errors.Is(err, domain.ErrHistoryUnsupported) {
+			c.JSON(http.StatusNotImplemented, H{
+				"error":   "not_implemented",
+				"message": "The storage backend for this key does not keep window history",
+			})
+			return
+		}
+		if err != nil {
+			if h.logger != nil {
+				h.logger.WithContext(ctx).Error("Failed to get window history", err, map[string]interface{}{
+					"key":  h.maskToken(key),
+					"type": typeParam,
+				})
+			}
+			h.respondError(c, http.StatusInternalServerError, "internal_server_error", "Failed to retrieve window history")
+			return
+		}
+		response["history"] = historyResponse(history)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// historyResponse converte o histórico de janelas, da mais recente à mais
+// antiga, indicando as que passaram do limite
+func historyResponse(history []domain.WindowCount) []H {
+	windows := make([]H, 0, len(history))
+	for _, window := range history {
+		windows = append(windows, H{
+			"window_start": window.Start.Unix(),
+			"count":        window.Count,
+			"limit":        window.Limit,
+			"exceeded":     window.Limit > 0 && window.Count > window.Limit,
+		})
+	}
+	return windows
+}
+
+// statusResponse converte o status de uma chave no corpo de /admin/status
+func statusResponse(status *domain.RateLimitStatus) H {
+	// Cotas agendadas possuem reset absoluto
+	resetTime := status.LastReset.Add(time.Duration(status.Window) * time.Second)
+	if status.ResetAt != nil {
+		resetTime = *status.ResetAt
+	}
+
 	// Preparar resposta
-	response := gin.H{
+	response := H{
 		"key":          status.Key,
 		"limit":        status.Limit,
 		"current":      status.Count,
-		"remaining":    max(0, status.Limit-status.Count),
-		"reset_time":   status.LastReset.Add(time.Duration(status.Window)*time.Second).Unix(),
+		"remaining":    max(0, status.EffectiveLimit()-status.Count),
+		"reset_time":   resetTime.Unix(),
 		"is_blocked":   status.IsBlocked,
 		"limiter_type": string(status.Type),
-		"timestamp":    time.Now().UTC().Format(time.RFC3339),
 	}
 
 	// Adicionar blocked_until se presente
@@ -213,7 +861,136 @@ func (h *Handlers) AdminStatusHandler(c *gin.Context) {
 		response["blocked_until"] = status.BlockedUntil.Unix()
 	}
 
-	c.JSON(http.StatusOK, response)
+	// Crédito herdado do período anterior (rollover)
+	if status.Credit > 0 {
+		response["credit"] = status.Credit
+	}
+
+	return response
+}
+
+// Limites das consultas de status em lote
+const (
+	maxBatchStatusKeys = 100
+	batchStatusWorkers = 8 // consultas simultâneas ao storage
+)
+
+// AdminStatusQuery identifica uma chave em uma consulta de status em lote
+type AdminStatusQuery struct {
+	Key  string `json:"key" binding:"required"`
+	Type string `json:"type" binding:"required"`
+}
+
+// AdminBatchStatusRequest representa o corpo de POST /admin/status
+type AdminBatchStatusRequest struct {
+	Queries []AdminStatusQuery `json:"queries" binding:"required,min=1,max=100,dive"`
+}
+
+// AdminBatchStatusHandler consulta o status de várias chaves, de tipos
+// diferentes, em uma única requisição
+func (h *Handlers) AdminBatchStatusHandler(c *Exchange) {
+	var req AdminBatchStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "validation_error",
+			"message": "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	h.respondBatchStatus(c, req.Queries)
+}
+
+// respondBatchStatus consulta as chaves em paralelo e responde na ordem recebida
+// Falhas de uma chave aparecem no item, sem derrubar o lote
+func (h *Handlers) respondBatchStatus(c *Exchange, queries []AdminStatusQuery) {
+	if len(queries) > maxBatchStatusKeys {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "validation_error",
+			"message": fmt.Sprintf("at most %d keys per request", maxBatchStatusKeys),
+		})
+		return
+	}
+
+	limiterTypes := make([]domain.LimiterType, len(queries))
+	for i, query := range queries {
+		limiterType, ok := parseLimiterType(query.Type)
+		if !ok || query.Key == "" {
+			c.JSON(http.StatusBadRequest, H{
+				"error":   "validation_error",
+				"message": fmt.Sprintf("queries[%d]: key is required and type must be 'ip' or 'token'", i),
+			})
+			return
+		}
+		limiterTypes[i] = limiterType
+	}
+
+	ctx := c.Request.Context()
+	statuses := make([]H, len(queries))
+	failed := 0
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, batchStatusWorkers)
+
+	for i := range queries {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+
+			status, err := h.service.GetStatus(ctx, queries[i].Key, limiterTypes[i])
+			if err != nil {
+				statuses[i] = H{
+					"key":          queries[i].Key,
+					"limiter_type": string(limiterTypes[i]),
+					"error":        "internal_server_error",
+					"message":      "Failed to retrieve rate limiter status",
+				}
+				mutex.Lock()
+				failed++
+				mutex.Unlock()
+				return
+			}
+			if status == nil {
+				statuses[i] = H{
+					"key":          queries[i].Key,
+					"limiter_type": string(limiterTypes[i]),
+					"error":        "not_found",
+					"message":      "No rate limit data for this key",
+				}
+				return
+			}
+			statuses[i] = statusResponse(status)
+		}(i)
+	}
+	wg.Wait()
+
+	if failed > 0 && h.logger != nil {
+		h.logger.WithContext(ctx).Error("Failed to get rate limiter status for batch", nil, map[string]interface{}{
+			"keys":   len(queries),
+			"failed": failed,
+		})
+	}
+
+	c.JSON(http.StatusOK, H{
+		"count":     len(statuses),
+		"failed":    failed,
+		"statuses":  statuses,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// parseLimiterType interpreta o tipo de limiter ("ip" ou "token")
+func parseLimiterType(value string) (domain.LimiterType, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "ip":
+		return domain.IPLimiter, true
+	case "token":
+		return domain.TokenLimiter, true
+	default:
+		return "", false
+	}
 }
 
 // AdminResetRequest representa o corpo da requisição para reset
@@ -223,13 +1000,13 @@ type AdminResetRequest struct {
 }
 
 // AdminResetHandler implementa endpoint de reset administrativo
-func (h *Handlers) AdminResetHandler(c *gin.Context) {
+func (h *Handlers) AdminResetHandler(c *Exchange) {
 	ctx := c.Request.Context()
 
 	// Parse do JSON
 	var req AdminResetRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
+		c.JSON(http.StatusBadRequest, H{
 			"error":   "validation_error",
 			"message": "Invalid request body: " + err.Error(),
 		})
@@ -250,7 +1027,7 @@ func (h *Handlers) AdminResetHandler(c *gin.Context) {
 	case "token":
 		limiterType = domain.TokenLimiter
 	default:
-		c.JSON(http.StatusBadRequest, gin.H{
+		c.JSON(http.StatusBadRequest, H{
 			"error":   "validation_error",
 			"message": "type must be 'ip' or 'token'",
 		})
@@ -268,10 +1045,7 @@ func (h *Handlers) AdminResetHandler(c *gin.Context) {
 			})
 		}
 
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_server_error",
-			"message": "Failed to reset rate limiter",
-		})
+		h.respondError(c, http.StatusInternalServerError, "internal_server_error", "Failed to reset rate limiter")
 		return
 	}
 
@@ -283,7 +1057,7 @@ func (h *Handlers) AdminResetHandler(c *gin.Context) {
 		})
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	c.JSON(http.StatusOK, H{
 		"status":    "success",
 		"message":   "Rate limiter reset successfully",
 		"key":       h.maskToken(req.Key),
@@ -292,6 +1066,1970 @@ func (h *Handlers) AdminResetHandler(c *gin.Context) {
 	})
 }
 
+// maxManualBlock limita a duração de um bloqueio manual
+const maxManualBlock = 7 * 24 * time.Hour
+
+// AdminBlockRequest representa o corpo do bloqueio manual; sem duration_seconds
+// vale o BlockDuration da regra
+type AdminBlockRequest struct {
+	Key             string `json:"key" binding:"required"`
+	Type            string `json:"type" binding:"required"`
+	DurationSeconds int    `json:"duration_seconds"`
+}
+
+// AdminBlockHandler bloqueia uma chave manualmente (ex: abuso identificado no dashboard)
+func (h *Handlers) AdminBlockHandler(c *Exchange) {
+	ctx := c.Request.Context()
+
+	var req AdminBlockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "validation_error",
+			"message": "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	req.Key = strings.TrimSpace(req.Key)
+	limiterType, ok := parseLimiterType(req.Type)
+	if !ok {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "validation_error",
+			"message": "type must be 'ip' or 'token'",
+		})
+		return
+	}
+	duration := time.Duration(req.DurationSeconds) * time.Second
+	if duration < 0 || duration > maxManualBlock {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "validation_error",
+			"message": fmt.Sprintf("duration_seconds must be between 0 and %d", int(maxManualBlock.Seconds())),
+		})
+		return
+	}
+
+	blockedUntil, err := h.service.BlockKey(ctx, req.Key, limiterType, duration)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.WithContext(ctx).Error("Failed to block key", err, map[string]interface{}{
+				"key":  h.maskToken(req.Key),
+				"type": string(limiterType),
+			})
+		}
+		h.respondError(c, http.StatusInternalServerError, "internal_server_error", "Failed to block key")
+		return
+	}
+
+	c.JSON(http.StatusOK, H{
+		"status":        "success",
+		"key":           h.maskToken(req.Key),
+		"type":          string(limiterType),
+		"blocked_until": blockedUntil.UTC().Format(time.RFC3339),
+		"timestamp":     time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// AdminBulkResetRequest representa o corpo do reset em massa: um glob ("*" e "?")
+// ou um prefixo dos identificadores
+type AdminBulkResetRequest struct {
+	Type    string `json:"type" binding:"required"`
+	Pattern string `json:"pattern"`
+	Prefix  string `json:"prefix"`
+}
+
+// AdminBulkResetHandler limpa de uma vez as chaves do tipo que casam com o padrão
+// (ex: "192.168.1.*" ou "*" para todos os tokens)
+func (h *Handlers) AdminBulkResetHandler(c *Exchange) {
+	ctx := c.Request.Context()
+
+	var req AdminBulkResetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "validation_error",
+			"message": "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	limiterType := domain.LimiterType(strings.TrimSpace(strings.ToLower(req.Type)))
+	if limiterType != domain.IPLimiter && limiterType != domain.TokenLimiter {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "validation_error",
+			"message": "type must be 'ip' or 'token'",
+		})
+		return
+	}
+
+	pattern := strings.TrimSpace(req.Pattern)
+	prefix := strings.TrimSpace(req.Prefix)
+	switch {
+	case (pattern == "") == (prefix == ""):
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "validation_error",
+			"message": "exactly one of pattern or prefix is required",
+		})
+		return
+	case prefix != "":
+		if strings.ContainsAny(prefix, "*?") {
+			c.JSON(http.StatusBadRequest, H{
+				"error":   "validation_error",
+				"message": "prefix must not contain glob characters; use pattern instead",
+			})
+			return
+		}
+		pattern = prefix + "*"
+	}
+
+	removed, err := h.service.ResetPattern(ctx, pattern, limiterType)
+	if errors.Is(err, domain.ErrInvalidPattern) {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "validation_error",
+			"message": err.Error(),
+		})
+		return
+	}
+	if errors.Is(err, domain.ErrPatternResetUnsupported) {
+		c.JSON(http.StatusNotImplemented, H{
+			"error":   "not_implemented",
+			"message": "The storage backends do not support pattern resets",
+		})
+		return
+	}
+	if err != nil {
+		h.logger.WithContext(ctx).Error("Failed to reset rate limit keys by pattern", err, map[string]interface{}{
+			"type":    limiterType,
+			"removed": removed,
+		})
+		h.respondError(c, http.StatusInternalServerError, "internal_server_error", "Failed to reset rate limit keys")
+		return
+	}
+
+	c.JSON(http.StatusOK, H{
+		"status":    "success",
+		"type":      limiterType,
+		"pattern":   pattern,
+		"removed":   removed,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// AdminOverrideRequest representa o corpo da requisição de override temporário
+type AdminOverrideRequest struct {
+	Key       string    `json:"key" binding:"required"`
+	Type      string    `json:"type" binding:"required"`
+	Limit     int       `json:"limit" binding:"required"`
+	ExpiresAt time.Time `json:"expires_at" binding:"required"`
+}
+
+// AdminOverrideHandler aplica um limite temporário a uma chave (ex: testes de carga planejados)
+func (h *Handlers) AdminOverrideHandler(c *Exchange) {
+	ctx := c.Request.Context()
+
+	var req AdminOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "validation_error",
+			"message": "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	req.Key = strings.TrimSpace(req.Key)
+	req.Type = strings.TrimSpace(strings.ToLower(req.Type))
+
+	if req.Key == "" {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "validation_error",
+			"message": "key is required",
+		})
+		return
+	}
+
+	var limiterType domain.LimiterType
+	switch req.Type {
+	case "ip":
+		limiterType = domain.IPLimiter
+	case "token":
+		limiterType = domain.TokenLimiter
+	default:
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "validation_error",
+			"message": "type must be 'ip' or 'token'",
+		})
+		return
+	}
+
+	if req.Limit <= 0 {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "validation_error",
+			"message": "limit must be greater than 0",
+		})
+		return
+	}
+
+	if !req.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "validation_error",
+			"message": "expires_at must be in the future",
+		})
+		return
+	}
+
+	override := domain.LimitOverride{
+		Key:       req.Key,
+		Type:      limiterType,
+		Limit:     req.Limit,
+		ExpiresAt: req.ExpiresAt,
+	}
+
+	if err := h.service.SetOverride(ctx, override); err != nil {
+		if h.logger != nil {
+			logger := h.logger.WithContext(ctx)
+			logger.Error("Failed to apply rate limit override", err, map[string]interface{}{
+				"key":  h.maskToken(req.Key),
+				"type": req.Type,
+			})
+		}
+
+		h.respondError(c, http.StatusInternalServerError, "internal_server_error", "Failed to apply override")
+		return
+	}
+
+	c.JSON(http.StatusOK, H{
+		"status":     "success",
+		"message":    "Override applied successfully",
+		"key":        h.maskToken(req.Key),
+		"type":       req.Type,
+		"limit":      req.Limit,
+		"expires_at": req.ExpiresAt.UTC().Format(time.RFC3339),
+		"timestamp":  time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// AdminInstancesHandler lista as réplicas registradas
+// config_consistent indica se todas carregaram a mesma configuração
+func (h *Handlers) AdminInstancesHandler(c *Exchange) {
+	if h.fleet == nil {
+		c.JSON(http.StatusNotImplemented, H{
+			"error":   "not_implemented",
+			"message": "Instance registry is not enabled",
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	instances, err := h.fleet.ListInstances(ctx)
+	if err != nil {
+		if h.logger != nil {
+			h.logger.WithContext(ctx).Error("Failed to list instances", err, nil)
+		}
+
+		h.respondError(c, http.StatusInternalServerError, "internal_server_error", "Failed to list instances")
+		return
+	}
+
+	now := time.Now()
+	self := h.fleet.Self()
+	configHashes := make(map[string]struct{})
+	items := make([]H, 0, len(instances))
+	for _, instance := range instances {
+		configHashes[instance.ConfigHash] = struct{}{}
+		items = append(items, H{
+			"id":             instance.ID,
+			"version":        instance.Version,
+			"storage_type":   instance.StorageType,
+			"config_hash":    instance.ConfigHash,
+			"started_at":     instance.StartedAt.UTC().Format(time.RFC3339),
+			"last_seen":      instance.LastSeen.UTC().Format(time.RFC3339),
+			"uptime_seconds": int64(instance.Uptime(now).Seconds()),
+			"self":           instance.ID == self.ID,
+		})
+	}
+
+	c.JSON(http.StatusOK, H{
+		"instances":         items,
+		"count":             len(items),
+		"config_consistent": len(configHashes) <= 1,
+		"timestamp":         now.UTC().Format(time.RFC3339),
+	})
+}
+
+// AdminMaintenanceRequest representa o corpo da requisição de janela de manutenção
+type AdminMaintenanceRequest struct {
+	ID       string     `json:"id"`
+	Start    *time.Time `json:"start"`
+	End      time.Time  `json:"end" binding:"required"`
+	Type     string     `json:"type"`
+	Keys     []string   `json:"keys"`
+	Factor   float64    `json:"factor"`
+	Disabled bool       `json:"disabled"`
+	Reason   string     `json:"reason"`
+}
+
+// AdminListMaintenanceHandler lista as janelas de manutenção registradas
+func (h *Handlers) AdminListMaintenanceHandler(c *Exchange) {
+	if !h.requireMaintenance(c) {
+		return
+	}
+
+	now := time.Now()
+	windows := h.maintenance.List()
+	items := make([]H, 0, len(windows))
+	for _, window := range windows {
+		items = append(items, maintenanceResponse(window, now))
+	}
+
+	c.JSON(http.StatusOK, H{
+		"windows":   items,
+		"count":     len(items),
+		"timestamp": now.UTC().Format(time.RFC3339),
+	})
+}
+
+// AdminCreateMaintenanceHandler declara uma janela que relaxa ou desativa regras
+// start omitido = imediatamente
+func (h *Handlers) AdminCreateMaintenanceHandler(c *Exchange) {
+	if !h.requireMaintenance(c) {
+		return
+	}
+
+	var req AdminMaintenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "validation_error",
+			"message": "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	now := time.Now()
+	start := now
+	if req.Start != nil {
+		start = *req.Start
+	}
+
+	ctx := c.Request.Context()
+	window, err := h.maintenance.Add(ctx, domain.MaintenanceWindow{
+		ID:       req.ID,
+		Start:    start,
+		End:      req.End,
+		Type:     domain.LimiterType(strings.TrimSpace(strings.ToLower(req.Type))),
+		Keys:     req.Keys,
+		Factor:   req.Factor,
+		Disabled: req.Disabled,
+		Reason:   req.Reason,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "validation_error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	response := maintenanceResponse(window, now)
+	response["status"] = "success"
+	c.JSON(http.StatusCreated, response)
+}
+
+// AdminDeleteMaintenanceHandler cancela uma janela e restaura os limites
+func (h *Handlers) AdminDeleteMaintenanceHandler(c *Exchange) {
+	if !h.requireMaintenance(c) {
+		return
+	}
+
+	id := c.Param("id")
+	if !h.maintenance.Remove(c.Request.Context(), id) {
+		c.JSON(http.StatusNotFound, H{
+			"error":   "not_found",
+			"message": "Maintenance window not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, H{
+		"status":    "success",
+		"message":   "Maintenance window removed",
+		"id":        id,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// requireMaintenance responde 501 quando as janelas de manutenção não estão habilitadas
+func (h *Handlers) requireMaintenance(c *Exchange) bool {
+	if h.maintenance != nil {
+		return true
+	}
+
+	c.JSON(http.StatusNotImplemented, H{
+		"error":   "not_implemented",
+		"message": "Maintenance windows are not enabled",
+	})
+	return false
+}
+
+// maintenanceResponse formata uma janela de manutenção
+func maintenanceResponse(window domain.MaintenanceWindow, now time.Time) H {
+	return H{
+		"id":       window.ID,
+		"start":    window.Start.UTC().Format(time.RFC3339),
+		"end":      window.End.UTC().Format(time.RFC3339),
+		"type":     window.Type,
+		"keys":     window.Keys,
+		"factor":   window.Factor,
+		"disabled": window.Disabled,
+		"reason":   window.Reason,
+		"active":   window.IsActive(now),
+	}
+}
+
+// AdminCanaryRequest representa o corpo da requisição de rollout canário
+// key vazia aplica a nova versão a todas as chaves do tipo
+type AdminCanaryRequest struct {
+	Type    string `json:"type" binding:"required"`
+	Key     string `json:"key"`
+	Limit   int    `json:"limit" binding:"required"`
+	Percent int    `json:"percent"`
+}
+
+// AdminRolloutRequest identifica um rollout (ex: "ip:*", "token:premium")
+type AdminRolloutRequest struct {
+	ID string `json:"id" binding:"required"`
+}
+
+// AdminListRolloutsHandler lista os rollouts com as decisões por versão
+func (h *Handlers) AdminListRolloutsHandler(c *Exchange) {
+	if !h.requireRollouts(c) {
+		return
+	}
+
+	snapshots := h.rollouts.List()
+	items := make([]H, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		item := rolloutResponse(snapshot.RuleRollout)
+		item["versions"] = H{
+			domain.RuleVersionStable: versionStatsResponse(snapshot.Stable),
+			domain.RuleVersionCanary: versionStatsResponse(snapshot.Canary),
+		}
+		items = append(items, item)
+	}
+
+	c.JSON(http.StatusOK, H{
+		"rollouts":  items,
+		"count":     len(items),
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// AdminStartCanaryHandler inicia (ou ajusta o percentual de) um rollout canário
+func (h *Handlers) AdminStartCanaryHandler(c *Exchange) {
+	if !h.requireRollouts(c) {
+		return
+	}
+
+	var req AdminCanaryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "validation_error",
+			"message": "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	started, err := h.rollouts.Start(domain.RuleRollout{
+		Type:    domain.LimiterType(strings.TrimSpace(strings.ToLower(req.Type))),
+		Key:     req.Key,
+		Limit:   req.Limit,
+		Percent: req.Percent,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "validation_error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	response := rolloutResponse(started)
+	response["status"] = "success"
+	c.JSON(http.StatusOK, response)
+}
+
+// AdminPromoteRolloutHandler conclui o rollout aplicando a nova versão a todo o tráfego
+func (h *Handlers) AdminPromoteRolloutHandler(c *Exchange) {
+	if !h.requireRollouts(c) {
+		return
+	}
+
+	var req AdminRolloutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "validation_error",
+			"message": "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	promoted, err := h.rollouts.Promote(req.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, H{
+			"error":   "not_found",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	response := rolloutResponse(promoted)
+	response["status"] = "success"
+	c.JSON(http.StatusOK, response)
+}
+
+// AdminRollbackRolloutHandler descarta o rollout e volta todo o tráfego à versão estável
+func (h *Handlers) AdminRollbackRolloutHandler(c *Exchange) {
+	if !h.requireRollouts(c) {
+		return
+	}
+
+	var req AdminRolloutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "validation_error",
+			"message": "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	if !h.rollouts.Abort(req.ID) {
+		c.JSON(http.StatusNotFound, H{
+			"error":   "not_found",
+			"message": "Rule rollout not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, H{
+		"status":    "success",
+		"message":   "Rule rollout rolled back",
+		"id":        req.ID,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// AdminStagedConfigHandler mostra as configurações ativa, staged e anterior
+func (h *Handlers) AdminStagedConfigHandler(c *Exchange) {
+	if !h.requireConfigStager(c) {
+		return
+	}
+
+	status := h.configStager.Status()
+	response := H{
+		"active":    configVersionResponse(status.Active),
+		"staged":    nil,
+		"previous":  nil,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	}
+	if status.Staged != nil {
+		response["staged"] = configVersionResponse(*status.Staged)
+	}
+	if status.Previous != nil {
+		response["previous"] = configVersionResponse(*status.Previous)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// AdminStageConfigHandler valida e guarda uma configuração candidata sem aplicá-la
+// Campos desconhecidos são rejeitados: um erro de digitação não deve virar um campo ignorado
+func (h *Handlers) AdminStageConfigHandler(c *Exchange) {
+	if !h.requireConfigStager(c) {
+		return
+	}
+
+	var candidate config.StagedConfig
+	decoder := json.NewDecoder(c.Request.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&candidate); err != nil {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "validation_error",
+			"message": "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	staged, err := h.configStager.Stage(candidate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "validation_error",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	response := configVersionResponse(staged)
+	response["status"] = "staged"
+	response["active_fingerprint"] = h.configStager.Status().Active.Fingerprint
+	c.JSON(http.StatusOK, response)
+}
+
+// AdminDiscardConfigHandler descarta a configuração staged
+func (h *Handlers) AdminDiscardConfigHandler(c *Exchange) {
+	if !h.requireConfigStager(c) {
+		return
+	}
+
+	if !h.configStager.Discard() {
+		c.JSON(http.StatusNotFound, H{
+			"error":   "not_found",
+			"message": "No staged config",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, H{
+		"status":    "success",
+		"message":   "Staged config discarded",
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// AdminPromoteConfigHandler ativa a configuração staged de uma vez
+func (h *Handlers) AdminPromoteConfigHandler(c *Exchange) {
+	if !h.requireConfigStager(c) {
+		return
+	}
+
+	promoted, err := h.configStager.Promote()
+	if err != nil {
+		c.JSON(http.StatusConflict, H{
+			"error":   "conflict",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	response := configVersionResponse(promoted)
+	response["status"] = "promoted"
+	c.JSON(http.StatusOK, response)
+}
+
+// AdminRollbackConfigHandler restaura a configuração anterior à última promoção
+func (h *Handlers) AdminRollbackConfigHandler(c *Exchange) {
+	if !h.requireConfigStager(c) {
+		return
+	}
+
+	restored, err := h.configStager.Rollback()
+	if err != nil {
+		c.JSON(http.StatusConflict, H{
+			"error":   "conflict",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	response := configVersionResponse(restored)
+	response["status"] = "rolled_back"
+	c.JSON(http.StatusOK, response)
+}
+
+// AdminGetRuleHandler retorna o documento de uma regra com sua ETag
+func (h *Handlers) AdminGetRuleHandler(c *Exchange) {
+	if !h.requireRuleEditor(c) {
+		return
+	}
+
+	document, etag, err := h.ruleEditor.Rule(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, H{
+			"error":   "not_found",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	c.Header("ETag", etag)
+	c.Data(http.StatusOK, "application/json; charset=utf-8", document)
+}
+
+// AdminPatchRuleHandler aplica um JSON Patch (RFC 6902) a uma regra
+// O If-Match é obrigatório: edições concorrentes não se sobrescrevem
+func (h *Handlers) AdminPatchRuleHandler(c *Exchange) {
+	if !h.requireRuleEditor(c) {
+		return
+	}
+
+	contentType := c.ContentType()
+	if contentType != "application/json-patch+json" && contentType != "application/json" {
+		c.JSON(http.StatusUnsupportedMediaType, H{
+			"error":   "unsupported_media_type",
+			"message": "Content-Type must be application/json-patch+json",
+		})
+		return
+	}
+
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch == "" {
+		c.JSON(http.StatusPreconditionRequired, H{
+			"error":   "precondition_required",
+			"message": "If-Match header with the rule ETag is required",
+		})
+		return
+	}
+
+	patch, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "validation_error",
+			"message": "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	document, etag, err := h.ruleEditor.PatchRule(c.Param("id"), ifMatch, patch, adminActor(c))
+	if err != nil {
+		respondRuleError(c, err)
+		return
+	}
+
+	c.Header("ETag", etag)
+	c.Data(http.StatusOK, "application/json; charset=utf-8", document)
+}
+
+// AdminCreateRuleRequest representa o corpo da criação de uma regra
+type AdminCreateRuleRequest struct {
+	ID   string          `json:"id" binding:"required"` // "token:<token>" ou "ip:<ip>"
+	Rule json.RawMessage `json:"rule" binding:"required"`
+}
+
+// AdminCreateRuleHandler cria a regra de um token ou IP, aplicada sem restart
+func (h *Handlers) AdminCreateRuleHandler(c *Exchange) {
+	if !h.requireRuleEditor(c) {
+		return
+	}
+
+	var req AdminCreateRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "validation_error",
+			"message": "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	document, etag, err := h.ruleEditor.CreateRule(req.ID, req.Rule, adminActor(c))
+	if err != nil {
+		respondRuleError(c, err)
+		return
+	}
+
+	c.Header("ETag", etag)
+	c.Header("Location", "/admin/rules/"+req.ID)
+	c.Data(http.StatusCreated, "application/json; charset=utf-8", document)
+}
+
+// AdminReplaceRuleHandler substitui o documento inteiro de uma regra
+// Como no PATCH, o If-Match é obrigatório
+func (h *Handlers) AdminReplaceRuleHandler(c *Exchange) {
+	if !h.requireRuleEditor(c) {
+		return
+	}
+
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch == "" {
+		c.JSON(http.StatusPreconditionRequired, H{
+			"error":   "precondition_required",
+			"message": "If-Match header with the rule ETag is required",
+		})
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "validation_error",
+			"message": "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	document, etag, err := h.ruleEditor.ReplaceRule(c.Param("id"), ifMatch, body, adminActor(c))
+	if err != nil {
+		respondRuleError(c, err)
+		return
+	}
+
+	c.Header("ETag", etag)
+	c.Data(http.StatusOK, "application/json; charset=utf-8", document)
+}
+
+// AdminDeleteRuleHandler exclui uma regra de token ou IP (soft-delete)
+// O histórico é mantido e a regra pode ser restaurada; If-Match é obrigatório
+func (h *Handlers) AdminDeleteRuleHandler(c *Exchange) {
+	if !h.requireRuleEditor(c) {
+		return
+	}
+
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch == "" {
+		c.JSON(http.StatusPreconditionRequired, H{
+			"error":   "precondition_required",
+			"message": "If-Match header with the rule ETag is required",
+		})
+		return
+	}
+
+	revision, err := h.ruleEditor.DeleteRule(c.Param("id"), ifMatch, adminActor(c))
+	if err != nil {
+		respondRuleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, H{
+		"status":  "deleted",
+		"rule_id": c.Param("id"),
+		"version": revision.Version,
+		"actor":   revision.Actor,
+		"at":      revision.At.Format(time.RFC3339),
+	})
+}
+
+// AdminRuleHistoryHandler lista as versões de uma regra, da mais recente para a mais antiga
+func (h *Handlers) AdminRuleHistoryHandler(c *Exchange) {
+	if !h.requireRuleEditor(c) {
+		return
+	}
+
+	revisions, err := h.ruleEditor.RuleHistory(c.Param("id"))
+	if err != nil {
+		respondRuleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, H{
+		"rule_id":   c.Param("id"),
+		"count":     len(revisions),
+		"revisions": revisions,
+	})
+}
+
+// AdminRestoreRuleRequest representa o corpo da restauração de uma versão
+type AdminRestoreRuleRequest struct {
+	Version int `json:"version" binding:"required,min=1"`
+}
+
+// AdminRestoreRuleHandler reativa uma versão anterior da regra, inclusive de uma regra excluída
+func (h *Handlers) AdminRestoreRuleHandler(c *Exchange) {
+	if !h.requireRuleEditor(c) {
+		return
+	}
+
+	var req AdminRestoreRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "validation_error",
+			"message": "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+
+	document, etag, err := h.ruleEditor.RestoreRule(c.Param("id"), req.Version, adminActor(c))
+	if err != nil {
+		respondRuleError(c, err)
+		return
+	}
+
+	c.Header("ETag", etag)
+	c.Data(http.StatusOK, "application/json; charset=utf-8", document)
+}
+
+// respondRuleError traduz os erros da edição de regras em respostas HTTP
+func respondRuleError(c *Exchange, err error) {
+	status, code := http.StatusInternalServerError, "internal_server_error"
+	switch {
+	case errors.Is(err, staging.ErrRuleNotFound), errors.Is(err, staging.ErrRevisionNotFound):
+		status, code = http.StatusNotFound, "not_found"
+	case errors.Is(err, staging.ErrRuleModified):
+		status, code = http.StatusPreconditionFailed, "precondition_failed"
+	case errors.Is(err, staging.ErrPatchTestFailed), errors.Is(err, staging.ErrRuleExists):
+		status, code = http.StatusConflict, "conflict"
+	case errors.Is(err, staging.ErrInvalidPatch):
+		status, code = http.StatusBadRequest, "validation_error"
+	case errors.Is(err, staging.ErrInvalidRule):
+		status, code = http.StatusUnprocessableEntity, "validation_error"
+	}
+	c.JSON(status, H{
+		"error":   code,
+		"message": err.Error(),
+	})
+}
+
+// adminActor retorna o autor da alteração informado em X-Admin-Actor
+func adminActor(c *Exchange) string {
+	if actor := strings.TrimSpace(c.GetHeader(AdminActorHeader)); actor != "" {
+		return actor
+	}
+	return "unknown"
+}
+
+// requireRuleEditor responde 501 quando a edição de regras não está habilitada
+func (h *Handlers) requireRuleEditor(c *Exchange) bool {
+	if h.ruleEditor != nil {
+		return true
+	}
+
+	c.JSON(http.StatusNotImplemented, H{
+		"error":   "not_implemented",
+		"message": "Rule editing is not enabled",
+	})
+	return false
+}
+
+// requireConfigStager responde 501 quando o stage de configuração não está habilitado
+func (h *Handlers) requireConfigStager(c *Exchange) bool {
+	if h.configStager != nil {
+		return true
+	}
+
+	c.JSON(http.StatusNotImplemented, H{
+		"error":   "not_implemented",
+		"message": "Config staging is not enabled",
+	})
+	return false
+}
+
+// configVersionResponse resume uma versão da configuração (tokens apenas contados)
+func configVersionResponse(version staging.Version) H {
+	return H{
+		"fingerprint":         version.Fingerprint,
+		"since":               version.Since.UTC().Format(time.RFC3339),
+		"default_ip_limit":    version.Config.DefaultIPLimit,
+		"default_token_limit": version.Config.DefaultTokenLimit,
+		"window":              version.Config.Window,
+		"block_duration":      version.Config.BlockDuration,
+		"token_configs":       len(version.Config.TokenConfigs),
+	}
+}
+
+// requireRollouts responde 501 quando os rollouts de regras não estão habilitados
+func (h *Handlers) requireRollouts(c *Exchange) bool {
+	if h.rollouts != nil {
+		return true
+	}
+
+	c.JSON(http.StatusNotImplemented, H{
+		"error":   "not_implemented",
+		"message": "Rule rollouts are not enabled",
+	})
+	return false
+}
+
+// rolloutResponse formata um rollout
+func rolloutResponse(ruleRollout domain.RuleRollout) H {
+	return H{
+		"id":         ruleRollout.ID,
+		"type":       ruleRollout.Type,
+		"key":        ruleRollout.Key,
+		"limit":      ruleRollout.Limit,
+		"percent":    ruleRollout.Percent,
+		"promoted":   ruleRollout.Promoted,
+		"created_at": ruleRollout.CreatedAt.UTC().Format(time.RFC3339),
+		"updated_at": ruleRollout.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// versionStatsResponse formata as decisões de uma versão com a taxa de bloqueio
+func versionStatsResponse(stats rollout.VersionStats) H {
+	denyRate := 0.0
+	if total := stats.Allowed + stats.Denied; total > 0 {
+		denyRate = float64(stats.Denied) / float64(total)
+	}
+
+	return H{
+		"allowed":   stats.Allowed,
+		"denied":    stats.Denied,
+		"deny_rate": denyRate,
+	}
+}
+
+// AdminShadowHandler reporta quantas requisições mudariam de resultado sob a config staged
+func (h *Handlers) AdminShadowHandler(c *Exchange) {
+	if !h.requireShadow(c) {
+		return
+	}
+
+	stats := h.shadow.Stats()
+	changeRate := 0.0
+	if stats.Evaluated > 0 {
+		changeRate = float64(stats.Changed()) / float64(stats.Evaluated)
+	}
+
+	c.JSON(http.StatusOK, H{
+		"evaluated":   stats.Evaluated,
+		"agreed":      stats.Agreed,
+		"changed":     stats.Changed(),
+		"change_rate": changeRate,
+		"would_deny":  stats.WouldDeny,
+		"would_allow": stats.WouldAllow,
+		"errors":      stats.Errors,
+		"dropped":     stats.Dropped,
+		"since":       stats.Since.Format(time.RFC3339),
+		"timestamp":   time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// AdminShadowResetHandler zera os contadores da avaliação em shadow
+func (h *Handlers) AdminShadowResetHandler(c *Exchange) {
+	if !h.requireShadow(c) {
+		return
+	}
+
+	h.shadow.Reset()
+	c.JSON(http.StatusOK, H{
+		"status":    "success",
+		"message":   "Shadow evaluation counters reset",
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// requireShadow responde 501 quando a avaliação em shadow não está habilitada
+func (h *Handlers) requireShadow(c *Exchange) bool {
+	if h.shadow != nil {
+		return true
+	}
+
+	c.JSON(http.StatusNotImplemented, H{
+		"error":   "not_implemented",
+		"message": "Shadow evaluation is not enabled (set SHADOW_CONFIG_FILE)",
+	})
+	return false
+}
+
+// defaultCapacityTarget é a taxa de rejeição aceita por padrão em /admin/capacity, em %
+const defaultCapacityTarget = 1.0
+
+// AdminCapacityHandler sugere, por regra, limites que manteriam as rejeições abaixo do alvo
+// Query: target (percentual de rejeições aceito, padrão 1)
+func (h *Handlers) AdminCapacityHandler(c *Exchange) {
+	if !h.requireCapacity(c) {
+		return
+	}
+
+	target := defaultCapacityTarget
+	if value := c.Query("target"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed <= 0 || parsed >= 100 {
+			c.JSON(http.StatusBadRequest, H{
+				"error":   "invalid_request",
+				"message": "target must be a percentage greater than 0 and lower than 100",
+			})
+			return
+		}
+		target = parsed
+	}
+
+	report := h.capacity.Report(target)
+	rules := make([]H, 0, len(report.Rules))
+	for _, rule := range report.Rules {
+		rules = append(rules, H{
+			"rule":            rule.Rule,
+			"current_limit":   rule.CurrentLimit,
+			"requests":        rule.Requests,
+			"key_windows":     rule.KeyWindows,
+			"reject_percent":  rule.RejectPercent,
+			"suggested_limit": rule.SuggestedLimit,
+			"per_key_window": H{
+				"p50": rule.P50,
+				"p95": rule.P95,
+				"p99": rule.P99,
+				"max": rule.Max,
+			},
+		})
+	}
+
+	c.JSON(http.StatusOK, H{
+		"target_percent":   report.TargetPercent,
+		"interval_seconds": int(report.Interval.Seconds()),
+		"windows":          report.Windows,
+		"dropped_requests": report.DroppedRequests,
+		"since":            report.Since.UTC().Format(time.RFC3339),
+		"rules":            rules,
+		"timestamp":        time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// AdminCapacityResetHandler descarta o histórico do planejamento de capacidade
+func (h *Handlers) AdminCapacityResetHandler(c *Exchange) {
+	if !h.requireCapacity(c) {
+		return
+	}
+
+	h.capacity.Reset()
+	c.JSON(http.StatusOK, H{
+		"status":    "success",
+		"message":   "Capacity planning history reset",
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// requireCapacity responde 501 quando o planejamento de capacidade não está habilitado
+func (h *Handlers) requireCapacity(c *Exchange) bool {
+	if h.capacity != nil {
+		return true
+	}
+
+	c.JSON(http.StatusNotImplemented, H{
+		"error":   "not_implemented",
+		"message": "Capacity planning is not enabled (set CAPACITY_PLANNING=true)",
+	})
+	return false
+}
+
+// AdminLearningHandler lista os limites por chave propostos pelo modo de aprendizado
+func (h *Handlers) AdminLearningHandler(c *Exchange) {
+	if !h.requireLearning(c) {
+		return
+	}
+
+	report := h.learning.Report()
+	proposals := make([]H, 0, len(report.Proposals))
+	for _, proposal := range report.Proposals {
+		proposals = append(proposals, H{
+			"type":           proposal.Type,
+			"key":            proposal.Key,
+			"windows":        proposal.Windows,
+			"mean":           proposal.Mean,
+			"stddev":         proposal.StdDev,
+			"max":            proposal.Max,
+			"current_limit":  proposal.CurrentLimit,
+			"learned_limit":  proposal.LearnedLimit,
+			"proposed_limit": proposal.ProposedLimit,
+			"capped":         proposal.Capped,
+		})
+	}
+
+	response := H{
+		"interval_seconds":        int(report.Interval.Seconds()),
+		"training_period_seconds": int(report.TrainingPeriod.Seconds()),
+		"auto_apply":              report.AutoApply,
+		"since":                   report.Since.UTC().Format(time.RFC3339),
+		"tracked_keys":            report.TrackedKeys,
+		"training_keys":           report.TrainingKeys,
+		"dropped_requests":        report.DroppedRequests,
+		"proposals":               proposals,
+		"timestamp":               time.Now().UTC().Format(time.RFC3339),
+	}
+	if !report.LastApplied.IsZero() {
+		response["last_applied"] = report.LastApplied.UTC().Format(time.RFC3339)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// AdminLearningApplyHandler aplica as propostas atuais como overrides, após revisão
+func (h *Handlers) AdminLearningApplyHandler(c *Exchange) {
+	if !h.requireLearning(c) {
+		return
+	}
+
+	applied, err := h.learning.Apply(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to apply learned limits", err, nil)
+		h.respondError(c, http.StatusInternalServerError, "internal_error", "Failed to apply learned limits")
+		return
+	}
+
+	c.JSON(http.StatusOK, H{
+		"status":    "success",
+		"applied":   applied,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// AdminLearningResetHandler descarta o aprendizado e recomeça o treinamento
+func (h *Handlers) AdminLearningResetHandler(c *Exchange) {
+	if !h.requireLearning(c) {
+		return
+	}
+
+	h.learning.Reset()
+	c.JSON(http.StatusOK, H{
+		"status":    "success",
+		"message":   "Learning mode restarted training",
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// defaultDrainTimeout e maxDrainTimeout limitam a espera de /admin/drain, em segundos
+const (
+	defaultDrainTimeout = 30
+	maxDrainTimeout     = 300
+)
+
+// AdminDrainHandler tira a instância da readiness, espera as requisições em
+// andamento e envia o estado em memória, para o desligamento não perder nada
+// Query: timeout (segundos de espera, padrão 30)
+func (h *Handlers) AdminDrainHandler(c *Exchange) {
+	if h.drainer == nil {
+		c.JSON(http.StatusNotImplemented, H{
+			"error":   "not_implemented",
+			"message": "Draining is not enabled",
+		})
+		return
+	}
+
+	timeout, ok := boundedQueryInt(c, "timeout", defaultDrainTimeout, maxDrainTimeout)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), time.Duration(timeout)*time.Second)
+	defer cancel()
+	result := h.drainer.Drain(ctx)
+
+	response := H{
+		"status":      "drained",
+		"in_flight":   result.InFlight,
+		"flushed":     result.Flushed,
+		"duration_ms": result.Duration.Milliseconds(),
+		"timestamp":   time.Now().UTC().Format(time.RFC3339),
+	}
+	if result.Flushed == nil {
+		response["flushed"] = []string{}
+	}
+	if !result.Complete() {
+		response["status"] = "incomplete"
+		response["error"] = "drain_incomplete"
+		response["flush_errors"] = result.FlushErrors
+		c.JSON(http.StatusServiceUnavailable, response)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// requireLearning responde 501 quando o modo de aprendizado não está habilitado
+func (h *Handlers) requireLearning(c *Exchange) bool {
+	if h.learning != nil {
+		return true
+	}
+
+	c.JSON(http.StatusNotImplemented, H{
+		"error":   "not_implemented",
+		"message": "Learning mode is not enabled (set LEARNING_MODE=propose or apply)",
+	})
+	return false
+}
+
+// maxBlockReportRange limita o período de um relatório de bloqueios
+const maxBlockReportRange = 31 * 24 * time.Hour
+
+// AdminBlockReportHandler gera o relatório de bloqueios em JSON ou CSV (format=csv)
+// Período padrão: últimas 24h; from e to em RFC3339
+func (h *Handlers) AdminBlockReportHandler(c *Exchange) {
+	if h.blockReports == nil {
+		c.JSON(http.StatusNotImplemented, H{
+			"error":   "not_implemented",
+			"message": "Block reports are not enabled",
+		})
+		return
+	}
+
+	to, ok := parseReportTime(c, "to", time.Now())
+	if !ok {
+		return
+	}
+	from, ok := parseReportTime(c, "from", to.Add(-24*time.Hour))
+	if !ok {
+		return
+	}
+
+	if !from.Before(to) || to.Sub(from) > maxBlockReportRange {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "invalid_request",
+			"message": "Parameter 'from' must be before 'to' and the range must not exceed 31 days",
+		})
+		return
+	}
+
+	format := strings.ToLower(c.DefaultQuery("format", "json"))
+	if format != "json" && format != "csv" {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "invalid_request",
+			"message": "Parameter 'format' must be 'json' or 'csv'",
+		})
+		return
+	}
+
+	records := h.blockReports.Query(from, to)
+	for i := range records {
+		if records[i].Type == domain.TokenLimiter {
+			records[i].Key = h.maskToken(records[i].Key)
+		}
+	}
+
+	if format == "csv" {
+		var body bytes.Buffer
+		if err := reports.WriteBlocksCSV(&body, records); err != nil {
+			h.logger.WithContext(c.Request.Context()).Error("Failed to generate block report", err, nil)
+			h.respondError(c, http.StatusInternalServerError, "internal_error", "Failed to generate block report")
+			return
+		}
+
+		filename := fmt.Sprintf("blocks-%s-%s.csv", from.Format("20060102T150405Z"), to.Format("20060102T150405Z"))
+		c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", body.Bytes())
+		return
+	}
+
+	blocks := make([]H, 0, len(records))
+	for _, record := range records {
+		blocks = append(blocks, H{
+			"key":                    record.Key,
+			"type":                   record.Type,
+			"rule":                   record.Rule,
+			"limit":                  record.Limit,
+			"request_count":          record.RequestCount,
+			"rejected_requests":      record.Rejected,
+			"blocked_at":             record.BlockedAt.Format(time.RFC3339),
+			"blocked_until":          record.BlockedUntil().UTC().Format(time.RFC3339),
+			"block_duration_seconds": record.Duration,
+		})
+	}
+
+	c.JSON(http.StatusOK, H{
+		"from":   from.Format(time.RFC3339),
+		"to":     to.Format(time.RFC3339),
+		"count":  len(blocks),
+		"blocks": blocks,
+	})
+}
+
+// maxAnalyticsRange limita o período de uma consulta de analytics
+const maxAnalyticsRange = 7 * 24 * time.Hour
+
+// AdminAnalyticsHandler retorna as decisões agregadas por minuto (padrão: última hora)
+// O minuto em andamento ainda não é incluído
+func (h *Handlers) AdminAnalyticsHandler(c *Exchange) {
+	if h.analytics == nil {
+		c.JSON(http.StatusNotImplemented, H{
+			"error":   "not_implemented",
+			"message": "Analytics are not enabled (set ANALYTICS_RETENTION_HOURS)",
+		})
+		return
+	}
+
+	to, ok := parseReportTime(c, "to", time.Now())
+	if !ok {
+		return
+	}
+	from, ok := parseReportTime(c, "from", to.Add(-time.Hour))
+	if !ok {
+		return
+	}
+	if !from.Before(to) || to.Sub(from) > maxAnalyticsRange {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "invalid_request",
+			"message": "Parameter 'from' must be before 'to' and the range must not exceed 7 days",
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	buckets, err := h.analytics.Buckets(ctx, from, to)
+	if err != nil {
+		h.logger.WithContext(ctx).Error("Failed to read analytics", err, nil)
+		h.respondError(c, http.StatusInternalServerError, "internal_error", "Failed to read analytics")
+		return
+	}
+
+	var totalAllowed, totalDenied uint64
+	response := make([]H, 0, len(buckets))
+	for _, bucket := range buckets {
+		totalAllowed += bucket.Allowed
+		totalDenied += bucket.Denied
+		response = append(response, H{
+			"minute":      bucket.Minute.UTC().Format(time.RFC3339),
+			"allowed":     bucket.Allowed,
+			"denied":      bucket.Denied,
+			"unique_keys": bucket.UniqueKeys,
+		})
+	}
+
+	c.JSON(http.StatusOK, H{
+		"from":    from.Format(time.RFC3339),
+		"to":      to.Format(time.RFC3339),
+		"allowed": totalAllowed,
+		"denied":  totalDenied,
+		"buckets": response,
+	})
+}
+
+// Limites da leitura de /admin/events/blocks
+const (
+	defaultBlockEventCount = 50
+	maxBlockEventCount     = 1000
+)
+
+// AdminBlockEventsHandler retorna as entradas mais recentes do stream de bloqueios
+// Query: count (padrão 50, máximo 1000)
+func (h *Handlers) AdminBlockEventsHandler(c *Exchange) {
+	if h.blockEvents == nil {
+		c.JSON(http.StatusNotImplemented, H{
+			"error":   "not_implemented",
+			"message": "Block event stream is not enabled (set BLOCK_EVENTS_STREAM)",
+		})
+		return
+	}
+
+	count := defaultBlockEventCount
+	if value := c.Query("count"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 || parsed > maxBlockEventCount {
+			c.JSON(http.StatusBadRequest, H{
+				"error":   "invalid_request",
+				"message": fmt.Sprintf("count must be between 1 and %d", maxBlockEventCount),
+			})
+			return
+		}
+		count = parsed
+	}
+
+	ctx := c.Request.Context()
+	entries, err := h.blockEvents.Recent(ctx, count)
+	if err != nil {
+		h.logger.WithContext(ctx).Error("Failed to read block events", err, nil)
+		h.respondError(c, http.StatusInternalServerError, "internal_error", "Failed to read block events")
+		return
+	}
+
+	response := make([]H, 0, len(entries))
+	for _, entry := range entries {
+		item := H{
+			"id":        entry.ID,
+			"type":      entry.Event.Type,
+			"timestamp": entry.Event.Timestamp.UTC().Format(time.RFC3339Nano),
+			"data":      entry.Event.Data,
+		}
+		if entry.Event.RequestID != "" {
+			item["request_id"] = entry.Event.RequestID
+		}
+		response = append(response, item)
+	}
+
+	c.JSON(http.StatusOK, H{
+		"count":     len(response),
+		"events":    response,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// Limites da consulta de /admin/stats
+const (
+	defaultStatsSeconds = 60
+	defaultStatsTop     = 10
+	maxStatsTop         = 100
+)
+
+// AdminStatsHandler retorna as decisões recentes desta instância por tipo de limiter
+// e as chaves mais ativas. Query: seconds (padrão 60, até a retenção), top (padrão 10,
+// máximo 100) e sort (requests, denied ou blocked)
+func (h *Handlers) AdminStatsHandler(c *Exchange) {
+	if h.stats == nil {
+		c.JSON(http.StatusNotImplemented, H{
+			"error":   "not_implemented",
+			"message": "Stats are not enabled (set STATS_RETENTION_MINUTES)",
+		})
+		return
+	}
+
+	maxSeconds := int(h.stats.Retention().Seconds())
+	seconds := defaultStatsSeconds
+	if seconds > maxSeconds {
+		seconds = maxSeconds
+	}
+	if value := c.Query("seconds"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 || parsed > maxSeconds {
+			c.JSON(http.StatusBadRequest, H{
+				"error":   "invalid_request",
+				"message": fmt.Sprintf("seconds must be between 1 and %d", maxSeconds),
+			})
+			return
+		}
+		seconds = parsed
+	}
+
+	top := defaultStatsTop
+	if value := c.Query("top"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 || parsed > maxStatsTop {
+			c.JSON(http.StatusBadRequest, H{
+				"error":   "invalid_request",
+				"message": fmt.Sprintf("top must be between 1 and %d", maxStatsTop),
+			})
+			return
+		}
+		top = parsed
+	}
+
+	order, ok := stats.ParseOrder(strings.ToLower(c.Query("sort")))
+	if !ok {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "invalid_request",
+			"message": "sort must be 'requests', 'denied' or 'blocked'",
+		})
+		return
+	}
+
+	snapshot := h.stats.Snapshot(time.Duration(seconds)*time.Second, top, order)
+	topKeys := make([]stats.KeyStats, 0, len(snapshot.TopKeys))
+	for _, key := range snapshot.TopKeys {
+		if key.Type == domain.TokenLimiter {
+			key.Key = h.maskToken(key.Key)
+		}
+		topKeys = append(topKeys, key)
+	}
+
+	c.JSON(http.StatusOK, H{
+		"from":           snapshot.From.UTC().Format(time.RFC3339),
+		"to":             snapshot.To.UTC().Format(time.RFC3339),
+		"window_seconds": seconds,
+		"sort":           order,
+		"totals":         snapshot.Totals,
+		"by_type":        snapshot.ByType,
+		"top_keys":       topKeys,
+		"truncated":      snapshot.Truncated,
+	})
+}
+
+// Limites da página de /admin/keys
+const (
+	defaultKeysLimit = 100
+	maxKeysLimit     = 1000
+)
+
+// AdminKeysHandler lista as chaves do tipo com estado no storage padrão (contagem,
+// restante e bloqueio), em páginas. Query: type (ip ou token), limit (padrão 100,
+// máximo 1000) e page (o next_page da resposta anterior; vazio = início)
+func (h *Handlers) AdminKeysHandler(c *Exchange) {
+	ctx := c.Request.Context()
+
+	limiterType := domain.LimiterType(strings.ToLower(strings.TrimSpace(c.Query("type"))))
+	if limiterType != domain.IPLimiter && limiterType != domain.TokenLimiter {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "invalid_request",
+			"message": "type must be 'ip' or 'token'",
+		})
+		return
+	}
+
+	limit := defaultKeysLimit
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 || parsed > maxKeysLimit {
+			c.JSON(http.StatusBadRequest, H{
+				"error":   "invalid_request",
+				"message": fmt.Sprintf("limit must be between 1 and %d", maxKeysLimit),
+			})
+			return
+		}
+		limit = parsed
+	}
+
+	keys, next, err := h.service.ListKeys(ctx, limiterType, c.Query("page"), limit)
+	if errors.Is(err, domain.ErrListingUnsupported) {
+		c.JSON(http.StatusNotImplemented, H{
+			"error":   "not_implemented",
+			"message": "The default storage backend does not support listing keys",
+		})
+		return
+	}
+	if errors.Is(err, domain.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "invalid_request",
+			"message": "page must be the next_page value of a previous response",
+		})
+		return
+	}
+	if err != nil {
+		h.logger.WithContext(ctx).Error("Failed to list rate limit keys", err, map[string]interface{}{
+			"type": limiterType,
+		})
+		h.respondError(c, http.StatusInternalServerError, "internal_server_error", "Failed to list rate limit keys")
+		return
+	}
+
+	for i := range keys {
+		if keys[i].Type == domain.TokenLimiter {
+			keys[i].Key = h.maskToken(keys[i].Key)
+		}
+	}
+
+	c.JSON(http.StatusOK, H{
+		"type":      limiterType,
+		"keys":      keys,
+		"count":     len(keys),
+		"next_page": next,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// stateExportVersion é a versão do documento de /admin/export aceita por /admin/import
+const stateExportVersion = 1
+
+// AdminExportHandler exporta os contadores e bloqueios ativos do storage padrão,
+// com os identificadores em claro, para carregá-los em outra instância ou storage
+func (h *Handlers) AdminExportHandler(c *Exchange) {
+	ctx := c.Request.Context()
+
+	entries, err := h.service.ExportState(ctx)
+	if errors.Is(err, domain.ErrListingUnsupported) {
+		c.JSON(http.StatusNotImplemented, H{
+			"error":   "not_implemented",
+			"message": "The default storage backend does not support listing keys",
+		})
+		return
+	}
+	if err != nil {
+		h.logger.WithContext(ctx).Error("Failed to export rate limit state", err, nil)
+		h.respondError(c, http.StatusInternalServerError, "internal_server_error", "Failed to export rate limit state")
+		return
+	}
+
+	c.JSON(http.StatusOK, H{
+		"version":     stateExportVersion,
+		"exported_at": time.Now().UTC().Format(time.RFC3339),
+		"count":       len(entries),
+		"entries":     entries,
+	})
+}
+
+// AdminImportRequest representa o corpo da importação: o documento de /admin/export
+type AdminImportRequest struct {
+	Version int                 `json:"version"`
+	Entries []domain.StateEntry `json:"entries" binding:"required"`
+}
+
+// AdminImportHandler carrega no storage padrão o estado exportado por outra instância
+func (h *Handlers) AdminImportHandler(c *Exchange) {
+	ctx := c.Request.Context()
+
+	var req AdminImportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "validation_error",
+			"message": "Invalid request body: " + err.Error(),
+		})
+		return
+	}
+	if req.Version != 0 && req.Version != stateExportVersion {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "validation_error",
+			"message": fmt.Sprintf("unsupported export version %d", req.Version),
+		})
+		return
+	}
+
+	imported, err := h.service.ImportState(ctx, req.Entries)
+	if errors.Is(err, domain.ErrInvalidStateEntry) {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "validation_error",
+			"message": err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		h.logger.WithContext(ctx).Error("Failed to import rate limit state", err, map[string]interface{}{
+			"imported": imported,
+		})
+		h.respondError(c, http.StatusInternalServerError, "internal_server_error", "Failed to import rate limit state")
+		return
+	}
+
+	c.JSON(http.StatusOK, H{
+		"status":    "success",
+		"imported":  imported,
+		"skipped":   len(req.Entries) - imported,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// AdminDebugKeyHandler retorna o registro da chave exatamente como está no storage
+// junto com o status que a API reporta, para diagnosticar divergências entre os dois
+func (h *Handlers) AdminDebugKeyHandler(c *Exchange) {
+	ctx := c.Request.Context()
+
+	key := strings.TrimSpace(c.Query("key"))
+	limiterType := domain.LimiterType(strings.ToLower(strings.TrimSpace(c.Query("type"))))
+	if key == "" || (limiterType != domain.IPLimiter && limiterType != domain.TokenLimiter) {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "invalid_request",
+			"message": "key is required and type must be 'ip' or 'token'",
+		})
+		return
+	}
+
+	record, err := h.service.InspectKey(ctx, key, limiterType)
+	if errors.Is(err, domain.ErrInspectionUnsupported) {
+		c.JSON(http.StatusNotImplemented, H{
+			"error":   "not_implemented",
+			"message": "The storage backend for this key does not support raw inspection",
+		})
+		return
+	}
+	if err != nil {
+		h.logger.WithContext(ctx).Error("Failed to inspect rate limit key", err, map[string]interface{}{
+			"key":  h.maskToken(key),
+			"type": limiterType,
+		})
+		h.respondError(c, http.StatusInternalServerError, "internal_server_error", "Failed to inspect rate limit key")
+		return
+	}
+
+	stored := H{
+		"backend":     record.Backend,
+		"storage_key": record.StorageKey,
+		"exists":      record.Exists,
+		"raw":         record.Raw,
+		"decoded":     record.Status,
+	}
+	if record.Raw != "" && json.Valid([]byte(record.Raw)) {
+		stored["raw_json"] = json.RawMessage(record.Raw)
+	}
+	if record.DecodeError != "" {
+		stored["decode_error"] = record.DecodeError
+	}
+	if record.TTL != nil {
+		stored["ttl_ms"] = record.TTL.Milliseconds()
+	}
+	if record.BlockedUntil != nil {
+		stored["block_entry"] = H{"blocked_until": record.BlockedUntil.UTC().Format(time.RFC3339Nano)}
+	}
+
+	// O que os endpoints de status reportam a partir do mesmo registro
+	reported := H{}
+	if status, err := h.service.GetStatus(ctx, key, limiterType); err != nil {
+		reported["error"] = err.Error()
+	} else {
+		reported["status"] = status
+	}
+
+	c.JSON(http.StatusOK, H{
+		"key":       key,
+		"type":      limiterType,
+		"stored":    stored,
+		"reported":  reported,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// AdminObserveHandler mede a taxa real de uma chave nos próximos segundos e a
+// compara com o limite configurado: "este cliente está acima do limite agora?"
+func (h *Handlers) AdminObserveHandler(c *Exchange) {
+	if h.observer == nil {
+		c.JSON(http.StatusNotImplemented, H{
+			"error":   "not_implemented",
+			"message": "Live observation is not enabled",
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	key := strings.TrimSpace(c.Query("key"))
+	limiterType := domain.LimiterType(strings.ToLower(strings.TrimSpace(c.Query("type"))))
+	if key == "" || (limiterType != domain.IPLimiter && limiterType != domain.TokenLimiter) {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "invalid_request",
+			"message": "key is required and type must be 'ip' or 'token'",
+		})
+		return
+	}
+
+	seconds := defaultObserveSeconds
+	if value := c.Query("seconds"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxObserveSeconds {
+			c.JSON(http.StatusBadRequest, H{
+				"error":   "invalid_request",
+				"message": fmt.Sprintf("seconds must be an integer between 1 and %d", maxObserveSeconds),
+			})
+			return
+		}
+		seconds = parsed
+	}
+
+	rule, err := h.service.GetConfig(ctx, key, limiterType)
+	if err != nil {
+		h.logger.WithContext(ctx).Error("Failed to get rate limit config", err, map[string]interface{}{
+			"key":  h.maskToken(key),
+			"type": limiterType,
+		})
+		h.respondError(c, http.StatusInternalServerError, "internal_server_error", "Failed to retrieve rate limit config")
+		return
+	}
+
+	sample, err := h.observer.Sample(ctx, key, limiterType, time.Duration(seconds)*time.Second)
+	if errors.Is(err, observe.ErrTooManyWatches) {
+		h.respondError(c, http.StatusTooManyRequests, "too_many_observations", "Too many concurrent observations, try again later")
+		return
+	}
+	if err != nil {
+		h.logger.WithContext(ctx).Error("Failed to observe rate limit key", err, map[string]interface{}{
+			"key":  h.maskToken(key),
+			"type": limiterType,
+		})
+		h.respondError(c, http.StatusInternalServerError, "internal_server_error", "Failed to observe rate limit key")
+		return
+	}
+
+	limitRPS := 0.0
+	if rule.Window > 0 {
+		limitRPS = float64(rule.Limit) / float64(rule.Window)
+	}
+
+	response := H{
+		"key":  key,
+		"type": limiterType,
+		"observed": H{
+			"seconds":  sample.Duration.Seconds(),
+			"requests": sample.Requests,
+			"rps":      sample.RPS,
+		},
+		"configured": H{
+			"limit":  rule.Limit,
+			"window": rule.Window,
+			"rps":    limitRPS,
+		},
+		"over_limit": limitRPS > 0 && sample.RPS > limitRPS,
+		"timestamp":  time.Now().UTC().Format(time.RFC3339),
+	}
+
+	// O contador armazenado inclui o tráfego das outras réplicas (storage compartilhado)
+	if status, err := h.service.GetStatus(ctx, key, limiterType); err == nil {
+		response["stored"] = H{
+			"count":      status.Count,
+			"limit":      status.EffectiveLimit(),
+			"is_blocked": status.IsBlocked,
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// AdminDebugDecisionsHandler grava as próximas decisões completas de uma chave
+// (regra aplicada, contadores lidos, retornos do storage e tempos de cada etapa)
+// para responder "por que fui limitado" sem habilitar logs de debug globais
+func (h *Handlers) AdminDebugDecisionsHandler(c *Exchange) {
+	if h.decisionTraces == nil {
+		c.JSON(http.StatusNotImplemented, H{
+			"error":   "not_implemented",
+			"message": "Decision tracing is not enabled",
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	key := strings.TrimSpace(c.Query("key"))
+	limiterType := domain.LimiterType(strings.ToLower(strings.TrimSpace(c.Query("type"))))
+	if key == "" || (limiterType != domain.IPLimiter && limiterType != domain.TokenLimiter) {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "invalid_request",
+			"message": "key is required and type must be 'ip' or 'token'",
+		})
+		return
+	}
+
+	count, ok := boundedQueryInt(c, "count", defaultTraceCount, maxTraceCount)
+	if !ok {
+		return
+	}
+	seconds, ok := boundedQueryInt(c, "seconds", defaultTraceSeconds, maxTraceSeconds)
+	if !ok {
+		return
+	}
+
+	traces, err := h.decisionTraces.Record(ctx, key, limiterType, count, time.Duration(seconds)*time.Second)
+	if errors.Is(err, decisiontrace.ErrTooManyWatches) {
+		h.respondError(c, http.StatusTooManyRequests, "too_many_traces", "Too many concurrent decision traces, try again later")
+		return
+	}
+	if err != nil {
+		h.logger.WithContext(ctx).Error("Failed to trace rate limit decisions", err, map[string]interface{}{
+			"key":  h.maskToken(key),
+			"type": limiterType,
+		})
+		h.respondError(c, http.StatusInternalServerError, "internal_server_error", "Failed to trace rate limit decisions")
+		return
+	}
+
+	c.JSON(http.StatusOK, H{
+		"key":       key,
+		"type":      limiterType,
+		"requested": count,
+		"recorded":  len(traces),
+		"complete":  len(traces) == count,
+		"decisions": traces,
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// boundedQueryInt lê um parâmetro inteiro opcional entre 1 e max; responde 400 se inválido
+func boundedQueryInt(c *Exchange, name string, fallback, max int) (int, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return fallback, true
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 1 || parsed > max {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "invalid_request",
+			"message": fmt.Sprintf("%s must be an integer between 1 and %d", name, max),
+		})
+		return 0, false
+	}
+	return parsed, true
+}
+
+// errorWriter é a resposta dos handlers, tanto o Exchange quanto o gin.Context
+type errorWriter interface {
+	Data(status int, contentType string, data []byte)
+}
+
+// respondError responde um erro (429, 5xx) pelo serializer configurado no
+// middleware, para que handlers e middleware usem o mesmo envelope
+func (h *Handlers) respondError(c errorWriter, status int, code, message string) {
+	serializer := h.middlewareConfig.Serializer
+	if serializer == nil {
+		serializer = middleware.JSONSerializer{}
+	}
+
+	var body bytes.Buffer
+	contentType, err := serializer.Serialize(&body, &middleware.ErrorResponse{Status: status, Error: code, Message: message})
+	if err != nil {
+		body.Reset()
+		contentType, _ = middleware.JSONSerializer{}.Serialize(&body, &middleware.ErrorResponse{Error: code, Message: message})
+	}
+	c.Data(status, contentType, body.Bytes())
+}
+
+// parseReportTime lê um parâmetro RFC3339 opcional; responde 400 se inválido
+func parseReportTime(c *Exchange, name string, fallback time.Time) (time.Time, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return fallback.UTC(), true
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, H{
+			"error":   "invalid_request",
+			"message": "Parameter '" + name + "' must be an RFC3339 timestamp",
+		})
+		return time.Time{}, false
+	}
+	return parsed.UTC(), true
+}
+
 // maskToken mascara tokens para logs de segurança
 func (h *Handlers) maskToken(token string) string {
 	if token == "" {
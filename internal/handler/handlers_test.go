@@ -4,16 +4,37 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
+	"rate-limiter/internal/analytics"
+	"rate-limiter/internal/capacity"
+	"rate-limiter/internal/cluster"
+	"rate-limiter/internal/decisiontrace"
 	"rate-limiter/internal/domain"
+	"rate-limiter/internal/drain"
+	"rate-limiter/internal/events"
+	"rate-limiter/internal/learning"
+	"rate-limiter/internal/maintenance"
+	"rate-limiter/internal/middleware"
+	"rate-limiter/internal/observe"
+	"rate-limiter/internal/reports"
+	"rate-limiter/internal/rollout"
+	"rate-limiter/internal/shadow"
+	"rate-limiter/internal/staging"
+	"rate-limiter/internal/stats"
+	"rate-limiter/internal/storage"
 )
 
 // MockRateLimiterService é um mock do RateLimiterService para testes
@@ -34,9 +55,12 @@ func (m *MockRateLimiterService) IsAllowed(ctx context.Context, key string, limi
 	return args.Bool(0), args.Error(1)
 }
 
-func (m *MockRateLimiterService) GetConfig(key string, limiterType domain.LimiterType) *domain.RateLimitRule {
-	args := m.Called(key, limiterType)
-	return args.Get(0).(*domain.RateLimitRule)
+func (m *MockRateLimiterService) GetConfig(ctx context.Context, key string, limiterType domain.LimiterType) (*domain.RateLimitRule, error) {
+	args := m.Called(ctx, key, limiterType)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RateLimitRule), args.Error(1)
 }
 
 func (m *MockRateLimiterService) GetStatus(ctx context.Context, key string, limiterType domain.LimiterType) (*domain.RateLimitStatus, error) {
@@ -52,6 +76,66 @@ func (m *MockRateLimiterService) Reset(ctx context.Context, key string, limiterT
 	return args.Error(0)
 }
 
+func (m *MockRateLimiterService) SetOverride(ctx context.Context, override domain.LimitOverride) error {
+	args := m.Called(ctx, override)
+	return args.Error(0)
+}
+
+func (m *MockRateLimiterService) InspectKey(ctx context.Context, key string, limiterType domain.LimiterType) (*domain.StorageRecord, error) {
+	args := m.Called(ctx, key, limiterType)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.StorageRecord), args.Error(1)
+}
+
+func (m *MockRateLimiterService) WindowHistory(ctx context.Context, key string, limiterType domain.LimiterType) ([]domain.WindowCount, error) {
+	args := m.Called(ctx, key, limiterType)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.WindowCount), args.Error(1)
+}
+
+func (m *MockRateLimiterService) ListKeys(ctx context.Context, limiterType domain.LimiterType, cursor string, count int) ([]domain.TrackedKey, string, error) {
+	args := m.Called(ctx, limiterType, cursor, count)
+	if args.Get(0) == nil {
+		return nil, args.String(1), args.Error(2)
+	}
+	return args.Get(0).([]domain.TrackedKey), args.String(1), args.Error(2)
+}
+
+func (m *MockRateLimiterService) BlockKey(ctx context.Context, key string, limiterType domain.LimiterType, duration time.Duration) (time.Time, error) {
+	args := m.Called(ctx, key, limiterType, duration)
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+func (m *MockRateLimiterService) ResetPattern(ctx context.Context, pattern string, limiterType domain.LimiterType) (int, error) {
+	args := m.Called(ctx, pattern, limiterType)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRateLimiterService) ExportState(ctx context.Context) ([]domain.StateEntry, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.StateEntry), args.Error(1)
+}
+
+func (m *MockRateLimiterService) ImportState(ctx context.Context, entries []domain.StateEntry) (int, error) {
+	args := m.Called(ctx, entries)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRateLimiterService) ClientLimits(ctx context.Context, token string) ([]domain.ClientLimit, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.ClientLimit), args.Error(1)
+}
+
 // MockLogger é um mock do Logger para testes
 type MockLogger struct {
 	mock.Mock
@@ -111,6 +195,203 @@ func TestHealthHandler(t *testing.T) {
 	assert.NotEmpty(t, response["timestamp"])
 }
 
+// unhealthyStorage é um storage cujo health check falha
+type unhealthyStorage struct {
+	domain.RateLimiterStorage
+}
+
+func (unhealthyStorage) Health(ctx context.Context) error {
+	return errors.New("connection refused")
+}
+
+// TestHealthHandler_Dependencies testa o estado do /health conforme os storages
+func TestHealthHandler_Dependencies(t *testing.T) {
+	tests := []struct {
+		name           string
+		primaryDown    bool
+		secondaryDown  bool
+		failureMode    middleware.FailureMode
+		expectedStatus int
+		expectedBody   string
+	}{
+		{name: "Should be healthy when all storages are up", expectedStatus: http.StatusOK, expectedBody: "healthy"},
+		{name: "Should be degraded when a secondary storage is down", secondaryDown: true, expectedStatus: http.StatusOK, expectedBody: "degraded"},
+		{name: "Should be unhealthy when the default storage is down", primaryDown: true, expectedStatus: http.StatusServiceUnavailable, expectedBody: "unhealthy"},
+		{name: "Should be degraded when the default storage is down and failing open", primaryDown: true, failureMode: middleware.FailOpen, expectedStatus: http.StatusOK, expectedBody: "degraded"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var primary, secondary domain.RateLimiterStorage = storage.NewMemoryStorage(nil), storage.NewMemoryStorage(nil)
+			if tt.primaryDown {
+				primary = unhealthyStorage{}
+			}
+			if tt.secondaryDown {
+				secondary = unhealthyStorage{}
+			}
+			registry := storage.NewRegistry("redis", primary)
+			require.NoError(t, registry.Register("memory", secondary))
+
+			handlers := NewHandlers(nil, nil)
+			handlers.SetMiddlewareConfig(middleware.Config{FailureMode: tt.failureMode})
+			handlers.SetStorageRegistry(registry)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/health", handlers.HealthHandler)
+
+			// Act
+			req := httptest.NewRequest("GET", "/health", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var response struct {
+				Status       string             `json:"status"`
+				Dependencies []dependencyHealth `json:"dependencies"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedBody, response.Status)
+			require.Len(t, response.Dependencies, 2)
+			assert.Equal(t, "redis", response.Dependencies[0].Name)
+			assert.True(t, response.Dependencies[0].Critical)
+			assert.Equal(t, "memory", response.Dependencies[1].Name)
+			if tt.primaryDown {
+				assert.Equal(t, "down", response.Dependencies[0].Status)
+				assert.Equal(t, "connection refused", response.Dependencies[0].Error)
+			}
+		})
+	}
+}
+
+// fakeHealthReporter é um reporter de saúde fixo para testes
+type fakeHealthReporter struct {
+	health domain.StorageHealth
+}
+
+func (f *fakeHealthReporter) IsHealthy() bool {
+	return f.health.Healthy
+}
+
+func (f *fakeHealthReporter) HealthStatus() domain.StorageHealth {
+	return f.health
+}
+
+// TestReadyHandler testa o readiness check baseado no estado do storage
+func TestReadyHandler(t *testing.T) {
+	tests := []struct {
+		name           string
+		reporter       domain.StorageHealthReporter
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "Should be ready without health reporter",
+			reporter:       nil,
+			expectedStatus: http.StatusOK,
+			expectedBody:   "ready",
+		},
+		{
+			name:           "Should be ready when storage is healthy",
+			reporter:       &fakeHealthReporter{health: domain.StorageHealth{Healthy: true}},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "ready",
+		},
+		{
+			name:           "Should not be ready when storage is degraded",
+			reporter:       &fakeHealthReporter{health: domain.StorageHealth{Healthy: false, LastError: "connection refused"}},
+			expectedStatus: http.StatusServiceUnavailable,
+			expectedBody:   "not_ready",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handlers := NewHandlers(nil, nil)
+			handlers.SetHealthReporter(tt.reporter)
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/ready", handlers.ReadyHandler)
+
+			// Act
+			req := httptest.NewRequest("GET", "/ready", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+
+			var response map[string]interface{}
+			err := json.Unmarshal(w.Body.Bytes(), &response)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedBody, response["status"])
+		})
+	}
+}
+
+// TestLivenessAndReadiness testa que /livez ignora o storage e /readyz segue a readiness
+func TestLivenessAndReadiness(t *testing.T) {
+	// Arrange
+	handlers := NewHandlers(nil, nil)
+	handlers.SetHealthReporter(&fakeHealthReporter{health: domain.StorageHealth{Healthy: false, LastError: "connection refused"}})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handlers.SetupPublicRoutes(router, "")
+
+	// Act
+	live := httptest.NewRecorder()
+	router.ServeHTTP(live, httptest.NewRequest("GET", "/livez", nil))
+	ready := httptest.NewRecorder()
+	router.ServeHTTP(ready, httptest.NewRequest("GET", "/readyz", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, live.Code)
+	assert.Contains(t, live.Body.String(), `"status":"alive"`)
+	assert.Equal(t, http.StatusServiceUnavailable, ready.Code)
+	assert.Contains(t, ready.Body.String(), `"status":"not_ready"`)
+}
+
+// TestReadyHandler_WarmupGate testa a readiness presa até o aquecimento do storage
+func TestReadyHandler_WarmupGate(t *testing.T) {
+	reporter := &fakeHealthReporter{health: domain.StorageHealth{Healthy: true, ConsecutiveSuccesses: 1}}
+	gate := storage.NewReadinessGate(reporter, 3)
+
+	handlers := NewHandlers(nil, nil)
+	handlers.SetHealthReporter(reporter)
+	handlers.SetReadinessGate(gate)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/ready", handlers.ReadyHandler)
+
+	req := httptest.NewRequest("GET", "/ready", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "warming_up", response["status"])
+	assert.Equal(t, float64(1), response["warmup"].(map[string]interface{})["healthyChecks"])
+
+	reporter.health.ConsecutiveSuccesses = 3
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/ready", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	gate.Drain()
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/ready", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"draining"`)
+}
+
 // TestExampleHandler testa o endpoint de exemplo (protegido por rate limiter)
 func TestExampleHandler(t *testing.T) {
 	// Arrange
@@ -145,6 +426,54 @@ func TestExampleHandler(t *testing.T) {
 	mockLogger.AssertExpectations(t)
 }
 
+// TestCheckHandler testa o endpoint de decisão, incluindo HEAD sem corpo
+func TestCheckHandler(t *testing.T) {
+	mockService := new(MockRateLimiterService)
+	mockLogger := new(MockLogger)
+	mockLogger.On("WithContext", mock.Anything).Return(mockLogger)
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Maybe()
+	mockLogger.On("Info", mock.Anything, mock.Anything).Maybe()
+
+	allowed := &domain.RateLimitResult{Allowed: true, Limit: 10, Remaining: 7, ResetTime: time.Now().Add(time.Minute), LimiterType: domain.IPLimiter}
+	denied := &domain.RateLimitResult{Allowed: false, Limit: 10, Remaining: 0, ResetTime: time.Now().Add(time.Minute), LimiterType: domain.IPLimiter}
+	mockService.On("CheckLimit", mock.Anything, "10.0.0.1", "").Return(allowed, nil)
+	mockService.On("CheckLimit", mock.Anything, "10.0.0.2", "").Return(denied, nil)
+
+	router := setupTestRouter(NewHandlers(mockService, mockLogger))
+
+	serve := func(method, ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/check", nil)
+		req.Header.Set("X-Forwarded-For", ip)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// GET: corpo compacto com a decisão
+	w := serve(http.MethodGet, "10.0.0.1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, true, response["allowed"])
+	assert.Equal(t, float64(7), response["remaining"])
+
+	// HEAD: apenas status e headers
+	w = serve(http.MethodHead, "10.0.0.1")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "7", w.Header().Get("X-RateLimit-Remaining"))
+	assert.Empty(t, w.Body.String())
+
+	w = serve(http.MethodHead, "10.0.0.2")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+	assert.Empty(t, w.Body.String())
+
+	// GET negado mantém o corpo 429 padrão
+	w = serve(http.MethodGet, "10.0.0.2")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Contains(t, w.Body.String(), "rate_limit_exceeded")
+}
+
 // TestAdminStatusHandler testa o endpoint de status administrativo
 func TestAdminStatusHandler(t *testing.T) {
 	tests := []struct {
@@ -194,6 +523,17 @@ func TestAdminStatusHandler(t *testing.T) {
 			expectedStatus: http.StatusOK,
 			expectedFields: []string{"key", "limit", "current", "remaining", "reset_time", "is_blocked", "limiter_type"},
 		},
+		{
+			name:        "Should return not found for key without data",
+			queryParams: "?key=192.168.1.9&type=ip",
+			mockSetup: func(service *MockRateLimiterService, logger *MockLogger) {
+				service.On("GetStatus", mock.Anything, "192.168.1.9", domain.IPLimiter).Return(nil, nil)
+				logger.On("WithContext", mock.Anything).Return(logger)
+				logger.On("Debug", mock.AnythingOfType("string"), mock.Anything).Maybe()
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedFields: []string{"error", "message"},
+		},
 	}
 
 	for _, tt := range tests {
@@ -228,6 +568,77 @@ func TestAdminStatusHandler(t *testing.T) {
 	}
 }
 
+// TestAdminStatusHandler_History testa o histórico de janelas em ?history=true
+func TestAdminStatusHandler_History(t *testing.T) {
+	// Arrange
+	mockService := new(MockRateLimiterService)
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	status := &domain.RateLimitStatus{Key: "rate_limit:ip:192.168.1.1", Type: domain.IPLimiter, Count: 3, Limit: 10, Window: 60, LastReset: start}
+	mockService.On("GetStatus", mock.Anything, mock.Anything, mock.Anything).Return(status, nil)
+	mockService.On("WindowHistory", mock.Anything, "192.168.1.1", domain.IPLimiter).Return([]domain.WindowCount{
+		{Start: start, Count: 3, Limit: 10},
+		{Start: start.Add(-time.Minute), Count: 25, Limit: 10},
+	}, nil)
+	mockService.On("WindowHistory", mock.Anything, "premium", domain.TokenLimiter).Return(nil, domain.ErrHistoryUnsupported)
+	router := setupTestRouter(NewHandlers(mockService, nil))
+
+	// Act
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/status?key=192.168.1.1&type=ip&history=true", nil))
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		History []map[string]interface{} `json:"history"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.History, 2)
+	assert.Equal(t, float64(start.Unix()), response.History[0]["window_start"])
+	assert.Equal(t, false, response.History[0]["exceeded"])
+	assert.Equal(t, float64(25), response.History[1]["count"])
+	assert.Equal(t, true, response.History[1]["exceeded"])
+
+	// Storage sem histórico
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/status?key=premium&type=token&history=true", nil))
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+
+	// Valor inválido
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/status?key=premium&type=token&history=maybe", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// plainSerializer gera corpos de erro em texto puro
+type plainSerializer struct{}
+
+func (plainSerializer) Serialize(w io.Writer, response *middleware.ErrorResponse) (string, error) {
+	_, err := fmt.Fprintf(w, "%d %s: %s", response.Status, response.Error, response.Message)
+	return "text/plain; charset=utf-8", err
+}
+
+// TestHandlers_ResponseSerializer testa o envelope configurado no middleware nos erros dos handlers
+func TestHandlers_ResponseSerializer(t *testing.T) {
+	// Arrange
+	mockService := new(MockRateLimiterService)
+	mockLogger := new(MockLogger)
+	mockService.On("GetStatus", mock.Anything, "192.168.1.1", domain.IPLimiter).Return(nil, errors.New("storage down"))
+	mockLogger.On("WithContext", mock.Anything).Return(mockLogger)
+	mockLogger.On("Debug", mock.AnythingOfType("string"), mock.Anything).Maybe()
+	mockLogger.On("Error", mock.AnythingOfType("string"), mock.Anything, mock.Anything).Maybe()
+	handlers := NewHandlers(mockService, mockLogger)
+	handlers.SetMiddlewareConfig(middleware.Config{Serializer: plainSerializer{}})
+
+	// Act
+	w := httptest.NewRecorder()
+	setupTestRouter(handlers).ServeHTTP(w, httptest.NewRequest("GET", "/admin/status?key=192.168.1.1&type=ip", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Equal(t, "500 internal_server_error: Failed to retrieve rate limiter status", w.Body.String())
+}
+
 // TestAdminStatusHandler_ValidationErrors testa validação de parâmetros
 func TestAdminStatusHandler_ValidationErrors(t *testing.T) {
 	tests := []struct {
@@ -281,6 +692,62 @@ func TestAdminStatusHandler_ValidationErrors(t *testing.T) {
 	}
 }
 
+// TestAdminBatchStatusHandler testa as consultas de status em lote (GET com várias chaves e POST)
+func TestAdminBatchStatusHandler(t *testing.T) {
+	// Arrange
+	mockService := new(MockRateLimiterService)
+	mockLogger := new(MockLogger)
+	ipStatus := &domain.RateLimitStatus{Key: "10.0.0.1", Type: domain.IPLimiter, Count: 3, Limit: 10, Window: 60, LastReset: time.Now()}
+	tokenStatus := &domain.RateLimitStatus{Key: "premium_token", Type: domain.TokenLimiter, Count: 10, Limit: 1000, Window: 60, LastReset: time.Now()}
+	mockService.On("GetStatus", mock.Anything, "10.0.0.1", domain.IPLimiter).Return(ipStatus, nil)
+	mockService.On("GetStatus", mock.Anything, "10.0.0.2", domain.IPLimiter).Return(nil, assert.AnError)
+	mockService.On("GetStatus", mock.Anything, "premium_token", domain.TokenLimiter).Return(tokenStatus, nil)
+	mockLogger.On("WithContext", mock.Anything).Return(mockLogger)
+	mockLogger.On("Error", "Failed to get rate limiter status for batch", nil, mock.Anything)
+
+	handlers := NewHandlers(mockService, mockLogger)
+	router := setupTestRouter(handlers)
+
+	decode := func(w *httptest.ResponseRecorder) map[string]interface{} {
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	// Act: GET com várias chaves do mesmo tipo
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/status?type=ip&key=10.0.0.1&key=10.0.0.2", nil))
+
+	// Assert: a falha de uma chave fica no item, na ordem recebida
+	require.Equal(t, http.StatusOK, w.Code)
+	response := decode(w)
+	assert.Equal(t, float64(2), response["count"])
+	assert.Equal(t, float64(1), response["failed"])
+	statuses := response["statuses"].([]interface{})
+	assert.Equal(t, float64(7), statuses[0].(map[string]interface{})["remaining"])
+	assert.Equal(t, "internal_server_error", statuses[1].(map[string]interface{})["error"])
+
+	// Act: POST com tipos diferentes
+	body := `{"queries":[{"key":"premium_token","type":"token"},{"key":"10.0.0.1","type":"ip"}]}`
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/admin/status", strings.NewReader(body)))
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	statuses = decode(w)["statuses"].([]interface{})
+	assert.Equal(t, "premium_token", statuses[0].(map[string]interface{})["key"])
+	assert.Equal(t, "ip", statuses[1].(map[string]interface{})["limiter_type"])
+
+	// Corpos inválidos
+	for _, invalid := range []string{`{"queries":[]}`, `{"queries":[{"key":"10.0.0.1","type":"user"}]}`, `{"queries":[{"type":"ip"}]}`} {
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("POST", "/admin/status", strings.NewReader(invalid)))
+		assert.Equal(t, http.StatusBadRequest, w.Code, invalid)
+	}
+
+	mockService.AssertExpectations(t)
+}
+
 // TestAdminResetHandler testa o endpoint de reset administrativo
 func TestAdminResetHandler(t *testing.T) {
 	tests := []struct {
@@ -351,6 +818,1439 @@ func TestAdminResetHandler(t *testing.T) {
 	}
 }
 
+// TestAdminOverrideHandler testa o endpoint de override temporário
+func TestAdminOverrideHandler(t *testing.T) {
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	tests := []struct {
+		name           string
+		requestBody    map[string]interface{}
+		mockSetup      func(*MockRateLimiterService)
+		expectedStatus int
+	}{
+		{
+			name: "Should apply token override",
+			requestBody: map[string]interface{}{
+				"key":        "premium_token",
+				"type":       "token",
+				"limit":      5000,
+				"expires_at": expiresAt.Format(time.RFC3339),
+			},
+			mockSetup: func(service *MockRateLimiterService) {
+				service.On("SetOverride", mock.Anything, domain.LimitOverride{
+					Key:       "premium_token",
+					Type:      domain.TokenLimiter,
+					Limit:     5000,
+					ExpiresAt: expiresAt,
+				}).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Should reject invalid type",
+			requestBody: map[string]interface{}{
+				"key":        "192.168.1.1",
+				"type":       "user",
+				"limit":      50,
+				"expires_at": expiresAt.Format(time.RFC3339),
+			},
+			mockSetup:      func(service *MockRateLimiterService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Should reject blank key",
+			requestBody: map[string]interface{}{
+				"key":        "   ",
+				"type":       "ip",
+				"limit":      50,
+				"expires_at": expiresAt.Format(time.RFC3339),
+			},
+			mockSetup:      func(service *MockRateLimiterService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Should reject negative limit",
+			requestBody: map[string]interface{}{
+				"key":        "192.168.1.1",
+				"type":       "ip",
+				"limit":      -1,
+				"expires_at": expiresAt.Format(time.RFC3339),
+			},
+			mockSetup:      func(service *MockRateLimiterService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Should reject past expiration",
+			requestBody: map[string]interface{}{
+				"key":        "192.168.1.1",
+				"type":       "ip",
+				"limit":      50,
+				"expires_at": time.Now().Add(-time.Hour).Format(time.RFC3339),
+			},
+			mockSetup:      func(service *MockRateLimiterService) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockService := new(MockRateLimiterService)
+			tt.mockSetup(mockService)
+
+			handlers := NewHandlers(mockService, nil)
+			router := setupTestRouter(handlers)
+
+			bodyBytes, _ := json.Marshal(tt.requestBody)
+
+			// Act
+			req := httptest.NewRequest("POST", "/admin/override", bytes.NewBuffer(bodyBytes))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+// fakeFleet simula o registro de instâncias
+type fakeFleet struct {
+	instances []cluster.Instance
+}
+
+func (f fakeFleet) Self() cluster.Instance { return f.instances[0] }
+
+func (f fakeFleet) ListInstances(ctx context.Context) ([]cluster.Instance, error) {
+	return f.instances, nil
+}
+
+// TestAdminInstancesHandler testa a visão da frota
+func TestAdminInstancesHandler(t *testing.T) {
+	startedAt := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name               string
+		fleet              FleetProvider
+		expectedStatus     int
+		expectedCount      float64
+		expectedConsistent bool
+	}{
+		{
+			name: "Should list instances with consistent config",
+			fleet: fakeFleet{instances: []cluster.Instance{
+				{ID: "api-1", Version: "1.0.0", StorageType: "redis", ConfigHash: "abc", StartedAt: startedAt, LastSeen: time.Now()},
+				{ID: "api-2", Version: "1.0.0", StorageType: "redis", ConfigHash: "abc", StartedAt: startedAt, LastSeen: time.Now()},
+			}},
+			expectedStatus:     http.StatusOK,
+			expectedCount:      2,
+			expectedConsistent: true,
+		},
+		{
+			name: "Should flag replicas with different config",
+			fleet: fakeFleet{instances: []cluster.Instance{
+				{ID: "api-1", ConfigHash: "abc", StartedAt: startedAt},
+				{ID: "api-2", ConfigHash: "def", StartedAt: startedAt},
+			}},
+			expectedStatus:     http.StatusOK,
+			expectedCount:      2,
+			expectedConsistent: false,
+		},
+		{
+			name:           "Should return 501 when registry is disabled",
+			expectedStatus: http.StatusNotImplemented,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handlers := NewHandlers(new(MockRateLimiterService), nil)
+			if tt.fleet != nil {
+				handlers.SetFleet(tt.fleet)
+			}
+			router := setupTestRouter(handlers)
+
+			// Act
+			req := httptest.NewRequest("GET", "/admin/instances", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.expectedCount, response["count"])
+			assert.Equal(t, tt.expectedConsistent, response["config_consistent"])
+
+			first := response["instances"].([]interface{})[0].(map[string]interface{})
+			assert.Equal(t, true, first["self"])
+			assert.InDelta(t, 3600, first["uptime_seconds"], 5)
+		})
+	}
+}
+
+// TestAdminMaintenanceHandlers testa o ciclo de vida de uma janela de manutenção
+func TestAdminMaintenanceHandlers(t *testing.T) {
+	// Arrange
+	handlers := NewHandlers(new(MockRateLimiterService), nil)
+	handlers.SetMaintenance(maintenance.NewManager(nil, nil))
+	router := setupTestRouter(handlers)
+	end := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+
+	// Act: criação
+	body := `{"id": "deploy", "end": "` + end + `", "type": "IP", "factor": 2, "reason": "Rolling deploy"}`
+	req := httptest.NewRequest("POST", "/admin/maintenance", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "deploy", created["id"])
+	assert.Equal(t, "ip", created["type"])
+	assert.Equal(t, true, created["active"])
+
+	// Act: listagem
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/maintenance", nil))
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	var listed map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	assert.Equal(t, float64(1), listed["count"])
+
+	// Act: remoção
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("DELETE", "/admin/maintenance/deploy", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("DELETE", "/admin/maintenance/deploy", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAdminMaintenanceHandlers_Errors(t *testing.T) {
+	end := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+
+	tests := []struct {
+		name           string
+		enabled        bool
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "Should reject window without effect",
+			enabled:        true,
+			body:           `{"end": "` + end + `"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Should reject missing end",
+			enabled:        true,
+			body:           `{"factor": 2}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Should return 501 when maintenance is disabled",
+			body:           `{"end": "` + end + `", "disabled": true}`,
+			expectedStatus: http.StatusNotImplemented,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handlers := NewHandlers(new(MockRateLimiterService), nil)
+			if tt.enabled {
+				handlers.SetMaintenance(maintenance.NewManager(nil, nil))
+			}
+			router := setupTestRouter(handlers)
+
+			req := httptest.NewRequest("POST", "/admin/maintenance", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+// TestAdminRuleRolloutHandlers testa canário, métricas por versão e promoção
+func TestAdminRuleRolloutHandlers(t *testing.T) {
+	// Arrange
+	manager := rollout.NewManager(nil)
+	handlers := NewHandlers(new(MockRateLimiterService), nil)
+	handlers.SetRuleRollouts(manager)
+	router := setupTestRouter(handlers)
+
+	post := func(path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", path, bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Act: canário em 10% do tráfego de tokens
+	w := post("/admin/rules/canary", `{"type": "token", "limit": 200, "percent": 10}`)
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	var started map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &started))
+	assert.Equal(t, "token:*", started["id"])
+	assert.Equal(t, float64(10), started["percent"])
+
+	// Act: métricas por versão
+	manager.RecordDecision("token:*", domain.RuleVersionCanary, false)
+	manager.RecordDecision("token:*", domain.RuleVersionCanary, true)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/rules/rollouts", nil))
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	var listed map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	first := listed["rollouts"].([]interface{})[0].(map[string]interface{})
+	canary := first["versions"].(map[string]interface{})["canary"].(map[string]interface{})
+	assert.Equal(t, float64(1), canary["denied"])
+	assert.Equal(t, 0.5, canary["deny_rate"])
+
+	// Act & Assert: promoção
+	w = post("/admin/rules/promote", `{"id": "token:*"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var promoted map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &promoted))
+	assert.Equal(t, true, promoted["promoted"])
+	assert.Equal(t, float64(100), promoted["percent"])
+
+	// Act & Assert: rollback e IDs desconhecidos
+	assert.Equal(t, http.StatusOK, post("/admin/rules/rollback", `{"id": "token:*"}`).Code)
+	assert.Equal(t, http.StatusNotFound, post("/admin/rules/rollback", `{"id": "token:*"}`).Code)
+	assert.Equal(t, http.StatusNotFound, post("/admin/rules/promote", `{"id": "ip:*"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, post("/admin/rules/canary", `{"type": "ip", "limit": 10, "percent": 150}`).Code)
+}
+
+func TestAdminRuleRolloutHandlers_Disabled(t *testing.T) {
+	handlers := NewHandlers(new(MockRateLimiterService), nil)
+	router := setupTestRouter(handlers)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/rules/rollouts", nil))
+
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
+
+// fakeShadow simula o avaliador em shadow
+type fakeShadow struct {
+	stats  shadow.Stats
+	resets int
+}
+
+func (f *fakeShadow) Stats() shadow.Stats { return f.stats }
+
+func (f *fakeShadow) Reset() { f.resets++ }
+
+// TestAdminShadowHandler testa o relatório da avaliação em shadow
+func TestAdminShadowHandler(t *testing.T) {
+	// Arrange
+	reporter := &fakeShadow{stats: shadow.Stats{Evaluated: 200, Agreed: 190, WouldDeny: 8, WouldAllow: 2, Since: time.Now()}}
+	handlers := NewHandlers(new(MockRateLimiterService), nil)
+	handlers.SetShadow(reporter)
+	router := setupTestRouter(handlers)
+
+	// Act
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/shadow", nil))
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(10), response["changed"])
+	assert.Equal(t, 0.05, response["change_rate"])
+	assert.Equal(t, float64(8), response["would_deny"])
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/admin/shadow/reset", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, reporter.resets)
+
+	// Desabilitado
+	w = httptest.NewRecorder()
+	setupTestRouter(NewHandlers(new(MockRateLimiterService), nil)).ServeHTTP(w, httptest.NewRequest("GET", "/admin/shadow", nil))
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
+
+type fakeCapacity struct {
+	target float64
+	resets int
+}
+
+func (f *fakeCapacity) Report(targetPercent float64) capacity.Report {
+	f.target = targetPercent
+	return capacity.Report{
+		TargetPercent: targetPercent,
+		Interval:      time.Minute,
+		Windows:       30,
+		Since:         time.Now(),
+		Rules: []capacity.RuleReport{
+			{Rule: "ip:*", CurrentLimit: 10, Requests: 1000, KeyWindows: 90, RejectPercent: 4, SuggestedLimit: 14, P50: 8, P95: 13, P99: 16, Max: 20},
+		},
+	}
+}
+
+func (f *fakeCapacity) Reset() { f.resets++ }
+
+// TestAdminCapacityHandler testa o relatório de capacidade e a validação do alvo
+func TestAdminCapacityHandler(t *testing.T) {
+	// Arrange
+	reporter := &fakeCapacity{}
+	handlers := NewHandlers(new(MockRateLimiterService), nil)
+	handlers.SetCapacity(reporter)
+	router := setupTestRouter(handlers)
+
+	// Act
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/capacity?target=0.5", nil))
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 0.5, reporter.target)
+	var response struct {
+		TargetPercent   float64 `json:"target_percent"`
+		IntervalSeconds int     `json:"interval_seconds"`
+		Rules           []struct {
+			Rule           string `json:"rule"`
+			SuggestedLimit int    `json:"suggested_limit"`
+			PerKeyWindow   struct {
+				P99 int64 `json:"p99"`
+			} `json:"per_key_window"`
+		} `json:"rules"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 0.5, response.TargetPercent)
+	assert.Equal(t, 60, response.IntervalSeconds)
+	require.Len(t, response.Rules, 1)
+	assert.Equal(t, "ip:*", response.Rules[0].Rule)
+	assert.Equal(t, 14, response.Rules[0].SuggestedLimit)
+	assert.Equal(t, int64(16), response.Rules[0].PerKeyWindow.P99)
+
+	for _, target := range []string{"0", "100", "abc"} {
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/capacity?target="+target, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, target)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/admin/capacity/reset", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, reporter.resets)
+
+	// Desabilitado
+	w = httptest.NewRecorder()
+	setupTestRouter(NewHandlers(new(MockRateLimiterService), nil)).ServeHTTP(w, httptest.NewRequest("GET", "/admin/capacity", nil))
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
+
+type fakeLearning struct {
+	applied int
+	resets  int
+}
+
+func (f *fakeLearning) Report() learning.Report {
+	return learning.Report{
+		Interval:       time.Minute,
+		TrainingPeriod: 24 * time.Hour,
+		Since:          time.Now(),
+		TrackedKeys:    3,
+		TrainingKeys:   2,
+		Proposals: []learning.Proposal{
+			{Type: domain.IPLimiter, Key: "192.168.1.1", Windows: 1440, Mean: 4, StdDev: 1, Max: 9, CurrentLimit: 10, LearnedLimit: 11, ProposedLimit: 10, Capped: true},
+		},
+	}
+}
+
+func (f *fakeLearning) Apply(ctx context.Context) (int, error) {
+	f.applied++
+	return 1, nil
+}
+
+func (f *fakeLearning) Reset() { f.resets++ }
+
+func TestAdminLearningHandler(t *testing.T) {
+	// Arrange
+	learner := &fakeLearning{}
+	handlers := NewHandlers(new(MockRateLimiterService), nil)
+	handlers.SetLearning(learner)
+	router := setupTestRouter(handlers)
+
+	// Act
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/learning", nil))
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		TrainingPeriodSeconds int  `json:"training_period_seconds"`
+		TrainingKeys          int  `json:"training_keys"`
+		AutoApply             bool `json:"auto_apply"`
+		Proposals             []struct {
+			Key           string `json:"key"`
+			CurrentLimit  int    `json:"current_limit"`
+			LearnedLimit  int    `json:"learned_limit"`
+			ProposedLimit int    `json:"proposed_limit"`
+			Capped        bool   `json:"capped"`
+		} `json:"proposals"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 86400, response.TrainingPeriodSeconds)
+	assert.Equal(t, 2, response.TrainingKeys)
+	assert.False(t, response.AutoApply)
+	require.Len(t, response.Proposals, 1)
+	assert.Equal(t, "192.168.1.1", response.Proposals[0].Key)
+	assert.Equal(t, 11, response.Proposals[0].LearnedLimit)
+	assert.Equal(t, 10, response.Proposals[0].ProposedLimit)
+	assert.True(t, response.Proposals[0].Capped)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/admin/learning/apply", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"applied":1`)
+	assert.Equal(t, 1, learner.applied)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/admin/learning/reset", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, learner.resets)
+
+	// Desabilitado
+	w = httptest.NewRecorder()
+	setupTestRouter(NewHandlers(new(MockRateLimiterService), nil)).ServeHTTP(w, httptest.NewRequest("GET", "/admin/learning", nil))
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
+
+// fakeDrainer devolve um resultado fixo e registra as drenagens
+type fakeDrainer struct {
+	result drain.Result
+	calls  int
+}
+
+func (f *fakeDrainer) Drain(ctx context.Context) drain.Result {
+	f.calls++
+	return f.result
+}
+
+func TestAdminDrainHandler(t *testing.T) {
+	// Arrange
+	drainer := &fakeDrainer{result: drain.Result{Flushed: []string{"hybrid"}, Duration: 40 * time.Millisecond}}
+	handlers := NewHandlers(new(MockRateLimiterService), nil)
+	handlers.SetDrainer(drainer)
+	router := setupTestRouter(handlers)
+
+	// Act
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/admin/drain?timeout=10", nil))
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"drained"`)
+	assert.Contains(t, w.Body.String(), `"flushed":["hybrid"]`)
+	assert.Equal(t, 1, drainer.calls)
+
+	// Requisições ainda em andamento ao fim do prazo
+	drainer.result = drain.Result{InFlight: 2, FlushErrors: map[string]string{"hybrid": "redis unavailable"}}
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/admin/drain", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), `"error":"drain_incomplete"`)
+	assert.Contains(t, w.Body.String(), `"in_flight":2`)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/admin/drain?timeout=0", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Desabilitado
+	w = httptest.NewRecorder()
+	setupTestRouter(NewHandlers(new(MockRateLimiterService), nil)).ServeHTTP(w, httptest.NewRequest("POST", "/admin/drain", nil))
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
+
+// TestAdminBlockReportHandler testa o relatório de bloqueios em JSON e CSV
+func TestAdminBlockReportHandler(t *testing.T) {
+	// Arrange
+	blockedAt := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	blockLog := reports.NewBlockLog(10)
+	blockLog.RecordBlock(domain.BlockRecord{Key: "premium_token_abc123", Type: domain.TokenLimiter, Rule: "Premium", Limit: 100, RequestCount: 101, BlockedAt: blockedAt, Duration: 180})
+	blockLog.RecordBlock(domain.BlockRecord{Key: "192.168.1.1", Type: domain.IPLimiter, Rule: "Default IP limit", Limit: 10, RequestCount: 11, BlockedAt: blockedAt.Add(48 * time.Hour), Duration: 180})
+	handlers := NewHandlers(new(MockRateLimiterService), nil)
+	handlers.SetBlockReports(blockLog)
+	router := setupTestRouter(handlers)
+
+	// Act: JSON
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/reports/blocks?from=2024-01-01T00:00:00Z&to=2024-01-02T00:00:00Z", nil))
+
+	// Assert: apenas o bloqueio do período, com token mascarado
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Count  int                      `json:"count"`
+		Blocks []map[string]interface{} `json:"blocks"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Equal(t, 1, response.Count)
+	assert.Equal(t, "premium_***", response.Blocks[0]["key"])
+	assert.Equal(t, float64(101), response.Blocks[0]["request_count"])
+	assert.Equal(t, "2024-01-01T10:03:00Z", response.Blocks[0]["blocked_until"])
+
+	// Act: CSV
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/reports/blocks?format=csv&from=2024-01-01T00:00:00Z&to=2024-01-05T00:00:00Z", nil))
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "blocks-20240101T000000Z-20240105T000000Z.csv")
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "2024-01-03T10:00:00Z,2024-01-03T10:03:00Z,ip,192.168.1.1,Default IP limit,10,11,0,180", lines[2])
+}
+
+// fakeBlockEvents é um BlockEventReader em memória para testes
+type fakeBlockEvents struct {
+	entries []events.StreamEntry
+	count   int
+}
+
+func (f *fakeBlockEvents) Recent(ctx context.Context, count int) ([]events.StreamEntry, error) {
+	f.count = count
+	if count < len(f.entries) {
+		return f.entries[:count], nil
+	}
+	return f.entries, nil
+}
+
+// TestAdminBlockEventsHandler testa a leitura do stream de bloqueios e a validação do count
+func TestAdminBlockEventsHandler(t *testing.T) {
+	// Arrange
+	reader := &fakeBlockEvents{entries: []events.StreamEntry{
+		{ID: "1700000000001-0", Event: events.Event{Type: "rate_limit.unblocked", Timestamp: time.Date(2024, 1, 1, 10, 1, 0, 0, time.UTC), Data: map[string]interface{}{"key": "192.168.1.1", "reason": "reset"}}},
+		{ID: "1700000000000-0", Event: events.Event{Type: "rate_limit.blocked", Timestamp: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), RequestID: "req-1", Data: map[string]interface{}{"key": "192.168.1.1"}}},
+	}}
+	handlers := NewHandlers(new(MockRateLimiterService), nil)
+	handlers.SetBlockEvents(reader)
+	router := setupTestRouter(handlers)
+
+	// Act
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/events/blocks", nil))
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 50, reader.count)
+	var response struct {
+		Count  int                      `json:"count"`
+		Events []map[string]interface{} `json:"events"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Equal(t, 2, response.Count)
+	assert.Equal(t, "rate_limit.unblocked", response.Events[0]["type"])
+	assert.Equal(t, "1700000000001-0", response.Events[0]["id"])
+	assert.Equal(t, "req-1", response.Events[1]["request_id"])
+	assert.Equal(t, "2024-01-01T10:00:00Z", response.Events[1]["timestamp"])
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/events/blocks?count=1", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, reader.count)
+
+	for _, count := range []string{"0", "1001", "abc"} {
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/events/blocks?count="+count, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, count)
+	}
+
+	// Desabilitado
+	w = httptest.NewRecorder()
+	setupTestRouter(NewHandlers(new(MockRateLimiterService), nil)).ServeHTTP(w, httptest.NewRequest("GET", "/admin/events/blocks", nil))
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
+
+// TestAdminBlockReportHandler_InvalidRequests testa a validação dos parâmetros
+func TestAdminBlockReportHandler_InvalidRequests(t *testing.T) {
+	handlers := NewHandlers(new(MockRateLimiterService), nil)
+	handlers.SetBlockReports(reports.NewBlockLog(10))
+	router := setupTestRouter(handlers)
+
+	for _, query := range []string{
+		"from=yesterday",
+		"from=2024-01-02T00:00:00Z&to=2024-01-01T00:00:00Z",
+		"from=2024-01-01T00:00:00Z&to=2024-03-01T00:00:00Z",
+		"format=xml",
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/reports/blocks?"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+
+	// Desabilitado
+	w := httptest.NewRecorder()
+	setupTestRouter(NewHandlers(new(MockRateLimiterService), nil)).ServeHTTP(w, httptest.NewRequest("GET", "/admin/reports/blocks", nil))
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
+
+// TestAdminDebugKeyHandler testa a inspeção do registro bruto da chave
+func TestAdminDebugKeyHandler(t *testing.T) {
+	// Arrange
+	mockService := new(MockRateLimiterService)
+	ttl := 42 * time.Second
+	mockService.On("InspectKey", mock.Anything, "192.168.1.1", domain.IPLimiter).Return(&domain.StorageRecord{
+		Backend:     "redis",
+		StorageKey:  "rate_limit:ip:192.168.1.1",
+		Exists:      true,
+		Raw:         `{"count":11,"lastReset":1704103200000}`,
+		DecodeError: "parsing time \"1704103200000\" as RFC3339",
+		TTL:         &ttl,
+	}, nil)
+	mockService.On("GetStatus", mock.Anything, "192.168.1.1", domain.IPLimiter).Return(nil, errors.New("failed to get status"))
+	mockService.On("InspectKey", mock.Anything, "premium", domain.TokenLimiter).Return(nil, domain.ErrInspectionUnsupported)
+
+	mockLogger := new(MockLogger)
+	router := setupTestRouter(NewHandlers(mockService, mockLogger))
+
+	// Act
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/debug/key?key=192.168.1.1&type=ip", nil))
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Stored   map[string]interface{} `json:"stored"`
+		Reported map[string]interface{} `json:"reported"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(42000), response.Stored["ttl_ms"])
+	assert.Equal(t, float64(11), response.Stored["raw_json"].(map[string]interface{})["count"])
+	assert.Contains(t, response.Stored["decode_error"], "parsing time")
+	assert.Equal(t, "failed to get status", response.Reported["error"])
+
+	// Storage sem suporte a inspeção
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/debug/key?key=premium&type=token", nil))
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+
+	// Parâmetros inválidos
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/debug/key?key=premium&type=user", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// fakeAnalytics simula os agregados por minuto
+type fakeAnalytics struct {
+	buckets []analytics.Bucket
+}
+
+func (f *fakeAnalytics) Buckets(ctx context.Context, from, to time.Time) ([]analytics.Bucket, error) {
+	return f.buckets, nil
+}
+
+// TestAdminAnalyticsHandler testa a série por minuto com totais
+func TestAdminAnalyticsHandler(t *testing.T) {
+	// Arrange
+	minute := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	handlers := NewHandlers(new(MockRateLimiterService), nil)
+	handlers.SetAnalytics(&fakeAnalytics{buckets: []analytics.Bucket{
+		{Minute: minute, Allowed: 90, Denied: 10, UniqueKeys: 12},
+		{Minute: minute.Add(time.Minute), Allowed: 40, Denied: 0, UniqueKeys: 5},
+	}})
+	router := setupTestRouter(handlers)
+
+	// Act
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/analytics?from=2024-01-01T10:00:00Z&to=2024-01-01T10:02:00Z", nil))
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(130), response["allowed"])
+	assert.Equal(t, float64(10), response["denied"])
+	buckets := response["buckets"].([]interface{})
+	require.Len(t, buckets, 2)
+	assert.Equal(t, "2024-01-01T10:00:00Z", buckets[0].(map[string]interface{})["minute"])
+	assert.Equal(t, float64(12), buckets[0].(map[string]interface{})["unique_keys"])
+
+	// Período inválido
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/analytics?from=2024-01-01T00:00:00Z&to=2024-02-01T00:00:00Z", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Desabilitado
+	w = httptest.NewRecorder()
+	setupTestRouter(NewHandlers(new(MockRateLimiterService), nil)).ServeHTTP(w, httptest.NewRequest("GET", "/admin/analytics", nil))
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
+
+func TestAdminStatsHandler(t *testing.T) {
+	// Arrange
+	collector := stats.NewCollector(stats.Config{Retention: 5 * time.Minute})
+	for i := 0; i < 3; i++ {
+		collector.ObserveDecision("tk_1234567890abcdef", domain.TokenLimiter, true)
+	}
+	collector.ObserveDecision("192.168.1.1", domain.IPLimiter, false)
+	collector.RecordBlock(domain.BlockRecord{Key: "192.168.1.1", Type: domain.IPLimiter})
+	handlers := NewHandlers(new(MockRateLimiterService), nil)
+	handlers.SetStats(collector)
+	router := setupTestRouter(handlers)
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	// Act
+	w := serve("/admin/stats?seconds=300&sort=denied")
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(300), response["window_seconds"])
+	assert.Equal(t, map[string]interface{}{"allowed": float64(3), "denied": float64(1), "blocked": float64(1)}, response["totals"])
+	byType := response["by_type"].(map[string]interface{})
+	assert.Equal(t, float64(3), byType["token"].(map[string]interface{})["allowed"])
+	assert.Equal(t, []interface{}{map[string]interface{}{
+		"key": "192.168.1.1", "type": "ip", "allowed": float64(0), "denied": float64(1), "blocked": float64(1),
+	}}, response["top_keys"])
+
+	// Tokens saem mascarados
+	w = serve("/admin/stats")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"key":"tk_12345***"`)
+	assert.NotContains(t, w.Body.String(), "tk_1234567890abcdef")
+
+	// Parâmetros inválidos
+	assert.Equal(t, http.StatusBadRequest, serve("/admin/stats?seconds=301").Code)
+	assert.Equal(t, http.StatusBadRequest, serve("/admin/stats?top=0").Code)
+	assert.Equal(t, http.StatusBadRequest, serve("/admin/stats?sort=latency").Code)
+
+	// Desabilitado
+	w = httptest.NewRecorder()
+	setupTestRouter(NewHandlers(new(MockRateLimiterService), nil)).ServeHTTP(w, httptest.NewRequest("GET", "/admin/stats", nil))
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
+
+func TestAdminKeysHandler(t *testing.T) {
+	// Arrange
+	mockService := new(MockRateLimiterService)
+	mockService.On("ListKeys", mock.Anything, domain.TokenLimiter, "", 100).Return([]domain.TrackedKey{
+		{Key: "tk_1234567890abcdef", Type: domain.TokenLimiter, Count: 3, Limit: 10, Remaining: 7},
+	}, "42", nil)
+	mockService.On("ListKeys", mock.Anything, domain.IPLimiter, "bogus", 2).Return(nil, "", fmt.Errorf("failed to list keys: %w", domain.ErrInvalidCursor))
+	mockService.On("ListKeys", mock.Anything, domain.IPLimiter, "", 100).Return(nil, "", domain.ErrListingUnsupported)
+	router := setupTestRouter(NewHandlers(mockService, nil))
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	// Act
+	w := serve("/admin/keys?type=token")
+
+	// Assert: tokens saem mascarados, com o cursor da próxima página
+	require.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "42", response["next_page"])
+	assert.Equal(t, float64(1), response["count"])
+	assert.Equal(t, []interface{}{map[string]interface{}{
+		"key": "tk_12345***", "type": "token", "count": float64(3), "limit": float64(10), "remaining": float64(7), "isBlocked": false,
+	}}, response["keys"])
+
+	// Parâmetros inválidos e storage sem suporte
+	assert.Equal(t, http.StatusBadRequest, serve("/admin/keys").Code)
+	assert.Equal(t, http.StatusBadRequest, serve("/admin/keys?type=ip&limit=1001").Code)
+	assert.Equal(t, http.StatusBadRequest, serve("/admin/keys?type=ip&limit=2&page=bogus").Code)
+	assert.Equal(t, http.StatusNotImplemented, serve("/admin/keys?type=ip").Code)
+	mockService.AssertExpectations(t)
+}
+
+// TestAdminBulkResetHandler testa o reset em massa por glob ou prefixo
+func TestAdminBulkResetHandler(t *testing.T) {
+	// Arrange
+	mockService := new(MockRateLimiterService)
+	mockService.On("ResetPattern", mock.Anything, "192.168.1.*", domain.IPLimiter).Return(12, nil)
+	mockService.On("ResetPattern", mock.Anything, "*", domain.TokenLimiter).Return(0, domain.ErrPatternResetUnsupported)
+	mockService.On("ResetPattern", mock.Anything, "abc*", domain.TokenLimiter).
+		Return(0, fmt.Errorf("%w: identifiers are encrypted, only * is supported", domain.ErrInvalidPattern))
+	router := setupTestRouter(NewHandlers(mockService, new(MockLogger)))
+
+	reset := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/admin/reset/bulk", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Act
+	w := reset(`{"type": "ip", "prefix": "192.168.1."}`)
+
+	// Assert: o prefixo vira o glob "<prefixo>*"
+	require.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "192.168.1.*", response["pattern"])
+	assert.Equal(t, float64(12), response["removed"])
+
+	// Corpo inválido, padrão inválido e storage sem suporte
+	assert.Equal(t, http.StatusBadRequest, reset(`{"type": "ip"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, reset(`{"type": "ip", "pattern": "*", "prefix": "10."}`).Code)
+	assert.Equal(t, http.StatusBadRequest, reset(`{"type": "user", "pattern": "*"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, reset(`{"type": "ip", "prefix": "10.*"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, reset(`{"type": "token", "pattern": "abc*"}`).Code)
+	assert.Equal(t, http.StatusNotImplemented, reset(`{"type": "token", "pattern": "*"}`).Code)
+	mockService.AssertExpectations(t)
+}
+
+// TestAdminBlockHandler testa o bloqueio manual de uma chave
+func TestAdminBlockHandler(t *testing.T) {
+	// Arrange
+	blockedUntil := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	mockService := new(MockRateLimiterService)
+	mockService.On("BlockKey", mock.Anything, "192.168.1.1", domain.IPLimiter, 10*time.Minute).Return(blockedUntil, nil)
+	mockService.On("BlockKey", mock.Anything, "10.0.0.1", domain.IPLimiter, time.Duration(0)).Return(time.Time{}, errors.New("storage down"))
+	mockLogger := new(MockLogger)
+	mockLogger.On("WithContext", mock.Anything).Return(mockLogger)
+	mockLogger.On("Error", "Failed to block key", mock.Anything, mock.Anything).Return()
+	router := setupTestRouter(NewHandlers(mockService, mockLogger))
+
+	block := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/admin/block", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Act
+	w := block(`{"key": "192.168.1.1", "type": "ip", "duration_seconds": 600}`)
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "2030-01-01T00:00:00Z", response["blocked_until"])
+
+	assert.Equal(t, http.StatusBadRequest, block(`{"key": "192.168.1.1", "type": "user"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, block(`{"key": "192.168.1.1", "type": "ip", "duration_seconds": -1}`).Code)
+	assert.Equal(t, http.StatusBadRequest, block(`{"type": "ip"}`).Code)
+	assert.Equal(t, http.StatusInternalServerError, block(`{"key": "10.0.0.1", "type": "ip"}`).Code)
+	mockService.AssertExpectations(t)
+}
+
+// TestAdminExportImportHandlers testa a exportação e a importação do estado
+func TestAdminExportImportHandlers(t *testing.T) {
+	// Arrange
+	blockedUntil := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	entries := []domain.StateEntry{
+		{Key: "premium_token_123", Type: domain.TokenLimiter, Count: 5, Limit: 1000, Window: 60, LastReset: blockedUntil, BlockedUntil: &blockedUntil},
+	}
+	mockService := new(MockRateLimiterService)
+	mockService.On("ExportState", mock.Anything).Return(entries, nil)
+	mockService.On("ImportState", mock.Anything, entries).Return(0, nil)
+	router := setupTestRouter(NewHandlers(mockService, new(MockLogger)))
+
+	// Act
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/export", nil))
+
+	// Assert: o documento exportado é aceito como está pela importação
+	require.Equal(t, http.StatusOK, w.Code)
+	var exported map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &exported))
+	assert.Equal(t, float64(1), exported["version"])
+	assert.Equal(t, float64(1), exported["count"])
+
+	importState := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/admin/import", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	w = importState(w.Body.String())
+	require.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(0), response["imported"])
+	assert.Equal(t, float64(1), response["skipped"])
+
+	assert.Equal(t, http.StatusBadRequest, importState(`{}`).Code)
+	assert.Equal(t, http.StatusBadRequest, importState(`{"version": 2, "entries": []}`).Code)
+	mockService.AssertExpectations(t)
+}
+
+// TestMeLimitsHandler testa a consulta dos próprios limites pelo token do cliente
+func TestMeLimitsHandler(t *testing.T) {
+	// Arrange
+	mockService := new(MockRateLimiterService)
+	mockLogger := new(MockLogger)
+	mockLogger.On("WithContext", mock.Anything).Return(mockLogger)
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Maybe()
+	mockLogger.On("Info", mock.Anything, mock.Anything).Maybe()
+	mockLogger.On("Warn", mock.Anything, mock.Anything).Maybe()
+
+	resetAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	allowed := &domain.RateLimitResult{Allowed: true, Limit: 1000, Remaining: 749, ResetTime: resetAt, LimiterType: domain.TokenLimiter}
+	denied := &domain.RateLimitResult{Allowed: false, Limit: 100, Remaining: 0, ResetTime: resetAt, LimiterType: domain.TokenLimiter}
+	mockService.On("CheckLimit", mock.Anything, mock.Anything, "premium_token_123").Return(allowed, nil)
+	mockService.On("CheckLimit", mock.Anything, mock.Anything, "new_token").Return(allowed, nil)
+	mockService.On("CheckLimit", mock.Anything, mock.Anything, "spent_token").Return(denied, nil)
+	mockService.On("CheckLimit", mock.Anything, mock.Anything, "").Return(allowed, nil)
+	mockService.On("ClientLimits", mock.Anything, "premium_token_123").Return([]domain.ClientLimit{
+		{
+			Scope:  "token",
+			Rule:   &domain.RateLimitRule{Limit: 1000, Window: 60, Description: "Premium", ResetSchedule: "0 0 * * *"},
+			Status: &domain.RateLimitStatus{Key: "rate_limit:token:premium_token_123", Count: 250, Limit: 1000, ResetAt: &resetAt},
+		},
+		{
+			Scope:  "project:checkout",
+			Rule:   &domain.RateLimitRule{Limit: 5000, Window: 60, Description: "Shared project limit"},
+			Status: &domain.RateLimitStatus{Count: 4000, Limit: 5000, LastReset: time.Now()},
+		},
+	}, nil)
+	mockService.On("ClientLimits", mock.Anything, "new_token").Return([]domain.ClientLimit{
+		{Scope: "token", Rule: &domain.RateLimitRule{Limit: 100, Window: 60, RefillRate: 2, Description: "Default token limit"}},
+	}, nil)
+	router := setupTestRouter(NewHandlers(mockService, mockLogger))
+
+	serve := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/me/limits", nil)
+		if token != "" {
+			req.Header.Set("API_KEY", token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Act
+	w := serve("premium_token_123")
+
+	// Assert: uso e reset da cota, sem expor o token completo
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "premium_token_123")
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "premium_***", response["token"])
+	assert.Equal(t, "749", w.Header().Get("X-RateLimit-Remaining"))
+	limits := response["limits"].([]interface{})
+	require.Len(t, limits, 2)
+	limit := limits[0].(map[string]interface{})
+	assert.Equal(t, "token", limit["scope"])
+	assert.Equal(t, "quota", limit["kind"])
+	assert.Equal(t, "0 0 * * *", limit["reset_schedule"])
+	assert.Equal(t, float64(250), limit["current"])
+	assert.Equal(t, float64(750), limit["remaining"])
+	assert.Equal(t, float64(resetAt.Unix()), limit["reset_time"])
+
+	// Nível da hierarquia acima do token
+	limit = limits[1].(map[string]interface{})
+	assert.Equal(t, "project:checkout", limit["scope"])
+	assert.Equal(t, "window", limit["kind"])
+	assert.Equal(t, float64(4000), limit["current"])
+	assert.Equal(t, float64(1000), limit["remaining"])
+
+	// Token sem uso: cota cheia
+	w = serve("new_token")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	limit = response["limits"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "token_bucket", limit["kind"])
+	assert.Equal(t, float64(2), limit["refill_rate"])
+	assert.Equal(t, float64(100), limit["remaining"])
+
+	// A consulta conta na cota: esgotada, o limiter responde antes do handler
+	w = serve("spent_token")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	mockService.AssertNotCalled(t, "ClientLimits", mock.Anything, "spent_token")
+
+	// Sem token
+	assert.Equal(t, http.StatusUnauthorized, serve("").Code)
+}
+
+// fakeObserver devolve uma amostra fixa e registra a duração pedida
+type fakeObserver struct {
+	sample   observe.Sample
+	err      error
+	duration time.Duration
+}
+
+func (f *fakeObserver) Sample(ctx context.Context, key string, limiterType domain.LimiterType, duration time.Duration) (observe.Sample, error) {
+	f.duration = duration
+	return f.sample, f.err
+}
+
+// TestAdminObserveHandler testa a comparação entre a taxa observada e a configurada
+func TestAdminObserveHandler(t *testing.T) {
+	// Arrange
+	mockService := new(MockRateLimiterService)
+	mockService.On("GetConfig", mock.Anything, "192.168.1.1", domain.IPLimiter).Return(&domain.RateLimitRule{Limit: 10, Window: 60}, nil)
+	mockService.On("GetStatus", mock.Anything, "192.168.1.1", domain.IPLimiter).Return(&domain.RateLimitStatus{Count: 10, Limit: 10, IsBlocked: true}, nil)
+	observer := &fakeObserver{sample: observe.Sample{Requests: 20, Duration: 10 * time.Second, RPS: 2}}
+	handlers := NewHandlers(mockService, new(MockLogger))
+	handlers.SetObserver(observer)
+	router := setupTestRouter(handlers)
+
+	// Act
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/observe?key=192.168.1.1&type=ip&seconds=10", nil))
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 10*time.Second, observer.duration)
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(2), response["observed"].(map[string]interface{})["rps"])
+	assert.InDelta(t, 10.0/60, response["configured"].(map[string]interface{})["rps"], 0.0001)
+	assert.Equal(t, true, response["over_limit"])
+	assert.Equal(t, true, response["stored"].(map[string]interface{})["is_blocked"])
+
+	// Duração acima do máximo
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/observe?key=192.168.1.1&type=ip&seconds=60", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Limite de amostragens simultâneas
+	observer.err = observe.ErrTooManyWatches
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/observe?key=192.168.1.1&type=ip", nil))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, 5*time.Second, observer.duration)
+
+	// Desabilitado
+	w = httptest.NewRecorder()
+	setupTestRouter(NewHandlers(new(MockRateLimiterService), nil)).ServeHTTP(w, httptest.NewRequest("GET", "/admin/observe?key=192.168.1.1&type=ip", nil))
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
+
+// fakeDecisionRecorder devolve decisões fixas e registra a gravação pedida
+type fakeDecisionRecorder struct {
+	traces  []domain.DecisionTrace
+	err     error
+	count   int
+	timeout time.Duration
+}
+
+func (f *fakeDecisionRecorder) Record(ctx context.Context, key string, limiterType domain.LimiterType, count int, timeout time.Duration) ([]domain.DecisionTrace, error) {
+	f.count = count
+	f.timeout = timeout
+	return f.traces, f.err
+}
+
+// TestAdminDebugDecisionsHandler testa a gravação das próximas decisões de uma chave
+func TestAdminDebugDecisionsHandler(t *testing.T) {
+	// Arrange
+	recorder := &fakeDecisionRecorder{traces: []domain.DecisionTrace{{
+		Key:        "192.168.1.1",
+		Type:       domain.IPLimiter,
+		StorageKey: "rate_limit:ip:192.168.1.1",
+		Rule:       &domain.RateLimitRule{Limit: 10, Window: 60},
+		Steps: []domain.DecisionStep{
+			{Name: "is_blocked", Result: map[string]interface{}{"blocked": false}},
+			{Name: "increment", Result: map[string]interface{}{"count": 11}},
+		},
+	}}}
+	handlers := NewHandlers(new(MockRateLimiterService), new(MockLogger))
+	handlers.SetDecisionRecorder(recorder)
+	router := setupTestRouter(handlers)
+
+	// Act
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/debug/decisions?key=192.168.1.1&type=ip&count=2&seconds=3", nil))
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 2, recorder.count)
+	assert.Equal(t, 3*time.Second, recorder.timeout)
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(1), response["recorded"])
+	assert.Equal(t, false, response["complete"])
+	decision := response["decisions"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "rate_limit:ip:192.168.1.1", decision["storageKey"])
+	assert.Equal(t, "increment", decision["steps"].([]interface{})[1].(map[string]interface{})["name"])
+
+	// Quantidade acima do máximo
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/debug/decisions?key=192.168.1.1&type=ip&count=1000", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Limite de gravações simultâneas
+	recorder.err = decisiontrace.ErrTooManyWatches
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/debug/decisions?key=192.168.1.1&type=ip", nil))
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, 10, recorder.count)
+	assert.Equal(t, 10*time.Second, recorder.timeout)
+
+	// Desabilitado
+	w = httptest.NewRecorder()
+	setupTestRouter(NewHandlers(new(MockRateLimiterService), nil)).ServeHTTP(w, httptest.NewRequest("GET", "/admin/debug/decisions?key=192.168.1.1&type=ip", nil))
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
+
+// TestAdminConfigStagingHandlers testa o fluxo stage → promote → rollback
+func TestAdminConfigStagingHandlers(t *testing.T) {
+	// Arrange
+	handlers := NewHandlers(new(MockRateLimiterService), nil)
+	handlers.SetConfigStager(staging.NewManager(&domain.RateLimitConfig{
+		DefaultIPLimit: 10, DefaultTokenLimit: 100, Window: 60, BlockDuration: 180,
+	}, nil))
+	router := setupTestRouter(handlers)
+	post := func(path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("POST", path, strings.NewReader(body)))
+		return w
+	}
+
+	// Campo desconhecido e valor inválido são rejeitados
+	assert.Equal(t, http.StatusBadRequest, post("/admin/config/stage", `{"defaultIPLimt": 20}`).Code)
+	assert.Equal(t, http.StatusBadRequest, post("/admin/config/stage", `{"window": 0}`).Code)
+	assert.Equal(t, http.StatusConflict, post("/admin/config/promote", "").Code)
+
+	// Act
+	w := post("/admin/config/stage", `{"defaultIpLimit": 20}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var staged map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &staged))
+	assert.Equal(t, float64(20), staged["default_ip_limit"])
+	assert.NotEqual(t, staged["fingerprint"], staged["active_fingerprint"])
+
+	w = post("/admin/config/promote", "")
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	var promoted map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &promoted))
+	assert.Equal(t, staged["fingerprint"], promoted["fingerprint"])
+
+	w = post("/admin/config/rollback", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var restored map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &restored))
+	assert.Equal(t, staged["active_fingerprint"], restored["fingerprint"])
+	assert.Equal(t, float64(10), restored["default_ip_limit"])
+
+	// Desabilitado
+	w = httptest.NewRecorder()
+	setupTestRouter(NewHandlers(new(MockRateLimiterService), nil)).ServeHTTP(w, httptest.NewRequest("POST", "/admin/config/promote", nil))
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
+
+// TestAdminPatchRuleHandler testa o JSON Patch de regras com If-Match
+func TestAdminPatchRuleHandler(t *testing.T) {
+	// Arrange
+	handlers := NewHandlers(new(MockRateLimiterService), nil)
+	handlers.SetRuleEditor(staging.NewManager(&domain.RateLimitConfig{
+		DefaultIPLimit: 10, DefaultTokenLimit: 100, Window: 60, BlockDuration: 180,
+		TokenConfigs: map[string]domain.TokenConfig{"premium_token": {Token: "premium_token", Limit: 1000}},
+	}, nil))
+	router := setupTestRouter(handlers)
+	patch := func(ifMatch, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("PATCH", "/admin/rules/token:premium_token", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json-patch+json")
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/rules/token:premium_token", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	// Act
+	w = patch(etag, `[{"op":"replace","path":"/limit","value":2000}]`)
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+	var rule map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rule))
+	assert.Equal(t, float64(2000), rule["limit"])
+
+	// Edição concorrente com a ETag antiga, sem If-Match e com regra inválida
+	assert.Equal(t, http.StatusPreconditionFailed, patch(etag, `[{"op":"replace","path":"/limit","value":3000}]`).Code)
+	assert.Equal(t, http.StatusPreconditionRequired, patch("", `[]`).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, patch("*", `[{"op":"replace","path":"/limit","value":-1}]`).Code)
+	assert.Equal(t, http.StatusConflict, patch("*", `[{"op":"test","path":"/limit","value":1}]`).Code)
+
+	// As rotas de rollouts continuam atendidas
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/rules/rollouts", nil))
+	assert.Equal(t, http.StatusNotImplemented, w.Code)
+}
+
+// TestAdminRuleHistoryHandlers testa a exclusão, o histórico e a restauração de regras
+func TestAdminRuleHistoryHandlers(t *testing.T) {
+	// Arrange
+	handlers := NewHandlers(new(MockRateLimiterService), nil)
+	handlers.SetRuleEditor(staging.NewManager(&domain.RateLimitConfig{
+		DefaultIPLimit: 10, DefaultTokenLimit: 100, Window: 60, BlockDuration: 180,
+		TokenConfigs: map[string]domain.TokenConfig{"premium_token": {Token: "premium_token", Limit: 1000}},
+	}, nil))
+	router := setupTestRouter(handlers)
+	send := func(method, path, ifMatch, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(AdminActorHeader, "alice")
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Act
+	assert.Equal(t, http.StatusPreconditionRequired, send("DELETE", "/admin/rules/token:premium_token", "", "").Code)
+	w := send("DELETE", "/admin/rules/token:premium_token", "*", "")
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusNotFound, send("GET", "/admin/rules/token:premium_token", "", "").Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, send("DELETE", "/admin/rules/ip:*", "*", "").Code)
+
+	w = send("GET", "/admin/rules/token:premium_token/history", "", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var history struct {
+		Count     int `json:"count"`
+		Revisions []struct {
+			Version int    `json:"version"`
+			Action  string `json:"action"`
+			Actor   string `json:"actor"`
+		} `json:"revisions"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &history))
+	require.Equal(t, 2, history.Count)
+	assert.Equal(t, "delete", history.Revisions[0].Action)
+	assert.Equal(t, "alice", history.Revisions[0].Actor)
+	assert.Equal(t, "initial", history.Revisions[1].Action)
+
+	// Restauração da versão original
+	w = send("POST", "/admin/rules/token:premium_token/restore", "", `{"version":1}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotEmpty(t, w.Header().Get("ETag"))
+	assert.Equal(t, http.StatusOK, send("GET", "/admin/rules/token:premium_token", "", "").Code)
+
+	assert.Equal(t, http.StatusNotFound, send("POST", "/admin/rules/token:premium_token/restore", "", `{"version":9}`).Code)
+	assert.Equal(t, http.StatusBadRequest, send("POST", "/admin/rules/token:premium_token/restore", "", `{}`).Code)
+	assert.Equal(t, http.StatusNotFound, send("GET", "/admin/rules/token:missing/history", "", "").Code)
+}
+
+// TestAdminCreateRuleHandler testa a criação e a substituição de regras em tempo de execução
+func TestAdminCreateRuleHandler(t *testing.T) {
+	// Arrange
+	handlers := NewHandlers(new(MockRateLimiterService), nil)
+	handlers.SetRuleEditor(staging.NewManager(&domain.RateLimitConfig{
+		DefaultIPLimit: 10, DefaultTokenLimit: 100, Window: 60, BlockDuration: 180,
+		TokenConfigs: map[string]domain.TokenConfig{},
+	}, nil))
+	router := setupTestRouter(handlers)
+	send := func(method, path, ifMatch, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Act
+	w := send("POST", "/admin/rules", "", `{"id":"token:partner_token","rule":{"token":"partner_token","limit":500}}`)
+
+	// Assert
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "/admin/rules/token:partner_token", w.Header().Get("Location"))
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	assert.Equal(t, http.StatusConflict, send("POST", "/admin/rules", "", `{"id":"token:partner_token","rule":{"token":"partner_token","limit":500}}`).Code)
+	assert.Equal(t, http.StatusBadRequest, send("POST", "/admin/rules", "", `{"id":"ip:10.0.0.5"}`).Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, send("POST", "/admin/rules", "", `{"id":"ip:10.0.0.5","rule":{"ip":"10.0.0.5","limit":-1}}`).Code)
+
+	// Substituição com If-Match
+	assert.Equal(t, http.StatusPreconditionRequired, send("PUT", "/admin/rules/token:partner_token", "", `{}`).Code)
+	w = send("PUT", "/admin/rules/token:partner_token", etag, `{"token":"partner_token","limit":800}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	var rule map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &rule))
+	assert.Equal(t, float64(800), rule["limit"])
+	assert.Equal(t, http.StatusPreconditionFailed, send("PUT", "/admin/rules/token:partner_token", etag, `{"token":"partner_token","limit":900}`).Code)
+}
+
+// TestAdminRoutesHandler testa o inventário de rotas protegidas e desprotegidas
+func TestAdminRoutesHandler(t *testing.T) {
+	// Arrange
+	mockService := new(MockRateLimiterService)
+	mockService.On("GetConfig", mock.Anything, "*", domain.IPLimiter).Return(&domain.RateLimitRule{Limit: 10, Window: 60, BlockDuration: 180}, nil)
+	mockService.On("GetConfig", mock.Anything, "*", domain.TokenLimiter).Return(&domain.RateLimitRule{Limit: 100, Window: 60, BlockDuration: 180}, nil)
+	router := setupTestRouter(NewHandlers(mockService, new(MockLogger)))
+	// Rota adicionada depois do setup, fora do middleware
+	router.GET("/debug/new", func(c *gin.Context) {})
+
+	// Act
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/admin/routes", nil))
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Routes []struct {
+			Method     string                 `json:"method"`
+			Path       string                 `json:"path"`
+			Protection string                 `json:"protection"`
+			Rule       map[string]interface{} `json:"rule"`
+		} `json:"routes"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	protection := make(map[string]string)
+	for _, route := range response.Routes {
+		protection[route.Method+" "+route.Path] = route.Protection
+		if route.Method == "GET" && route.Path == "/check" {
+			assert.Equal(t, float64(10), route.Rule["ip"].(map[string]interface{})["limit"])
+			assert.Equal(t, float64(100), route.Rule["token"].(map[string]interface{})["limit"])
+			assert.Equal(t, "closed", route.Rule["failure_mode"])
+		}
+	}
+	assert.Equal(t, RouteRateLimited, protection["GET /"])
+	assert.Equal(t, RouteRateLimited, protection["HEAD /check"])
+	assert.Equal(t, RouteUnprotected, protection["GET /health"])
+	assert.Equal(t, RouteUnprotected, protection["GET /admin/routes"])
+	assert.Equal(t, RouteUnprotected, protection["GET /debug/new"])
+}
+
 // TestMetricsHandler testa o endpoint de métricas
 func TestMetricsHandler(t *testing.T) {
 	// Arrange
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/middleware"
+)
+
+// Estados do /health: degraded continua respondendo 200, pois a instância ainda
+// atende; unhealthy responde 503 para o load balancer retirá-la
+const (
+	healthHealthy   = "healthy"
+	healthDegraded  = "degraded"
+	healthUnhealthy = "unhealthy"
+)
+
+// healthCheckTimeout limita a verificação de cada dependência no /health
+const healthCheckTimeout = 2 * time.Second
+
+// StorageRegistry expõe os storages nomeados verificados pelo /health
+type StorageRegistry interface {
+	Storages() map[string]domain.RateLimiterStorage
+	DefaultName() string
+}
+
+// dependencyHealth é o resultado da verificação de uma dependência
+type dependencyHealth struct {
+	Name      string  `json:"name"`
+	Type      string  `json:"type"`
+	Status    string  `json:"status"`   // "up" ou "down"
+	Critical  bool    `json:"critical"` // Storage padrão: sem ele as decisões falham
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// checkDependencies verifica os storages em paralelo e resume o estado da instância:
+// um storage secundário fora deixa a instância degraded; o padrão fora a deixa
+// unhealthy, ou degraded com FAILURE_MODE=open (o tráfego segue sem limites)
+func (h *Handlers) checkDependencies(ctx context.Context) (string, []dependencyHealth) {
+	if h.storages == nil {
+		return healthHealthy, nil
+	}
+
+	defaultName := h.storages.DefaultName()
+	storages := h.storages.Storages()
+	dependencies := make([]dependencyHealth, 0, len(storages))
+	for name := range storages {
+		dependencies = append(dependencies, dependencyHealth{
+			Name:     name,
+			Type:     "storage",
+			Critical: name == defaultName,
+		})
+	}
+	sort.Slice(dependencies, func(i, j int) bool {
+		if dependencies[i].Critical != dependencies[j].Critical {
+			return dependencies[i].Critical
+		}
+		return dependencies[i].Name < dependencies[j].Name
+	})
+
+	var wg sync.WaitGroup
+	for i := range dependencies {
+		wg.Add(1)
+		go func(dependency *dependencyHealth, storage domain.RateLimiterStorage) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+			defer cancel()
+
+			start := time.Now()
+			err := storage.Health(checkCtx)
+			dependency.LatencyMs = float64(time.Since(start).Microseconds()) / 1000
+			dependency.Status = "up"
+			if err != nil {
+				dependency.Status = "down"
+				dependency.Error = err.Error()
+			}
+		}(&dependencies[i], storages[dependencies[i].Name])
+	}
+	wg.Wait()
+
+	status := healthHealthy
+	for _, dependency := range dependencies {
+		if dependency.Status == "up" {
+			continue
+		}
+		if dependency.Critical && h.middlewareConfig.FailureMode != middleware.FailOpen {
+			return healthUnhealthy, dependencies
+		}
+		status = healthDegraded
+	}
+	return status, dependencies
+}
+
+// healthStatusCode retorna o status HTTP do estado para checks de load balancer
+func healthStatusCode(status string) int {
+	if status == healthUnhealthy {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusOK
+}
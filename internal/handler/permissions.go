@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"rate-limiter/internal/adminauth"
+	"rate-limiter/internal/domain"
+)
+
+// maxPermissionBodyBytes limita o corpo lido para descobrir as chaves da operação
+const maxPermissionBodyBytes = 1 << 20
+
+// adminTarget é uma chave alcançada por uma rota administrativa
+type adminTarget struct {
+	Type string
+	Key  string
+}
+
+// adminTargets extrai as chaves da requisição; rotas sem extrator são globais e
+// ficam fora do alcance de credenciais restritas a tenants
+type adminTargets func(r *http.Request, params map[string]string) ([]adminTarget, error)
+
+// queryTarget lê a chave de ?key=&type=
+func queryTarget(r *http.Request, _ map[string]string) ([]adminTarget, error) {
+	query := r.URL.Query()
+	return []adminTarget{{Type: query.Get("type"), Key: query.Get("key")}}, nil
+}
+
+// bodyTarget lê a chave do corpo JSON {"key": ..., "type": ...}
+func bodyTarget(r *http.Request, _ map[string]string) ([]adminTarget, error) {
+	var body AdminResetRequest
+	if err := decodePermissionBody(r, &body); err != nil {
+		return nil, err
+	}
+	return []adminTarget{{Type: body.Type, Key: body.Key}}, nil
+}
+
+// batchTarget lê as chaves de {"queries": [...]}
+func batchTarget(r *http.Request, _ map[string]string) ([]adminTarget, error) {
+	var body AdminBatchStatusRequest
+	if err := decodePermissionBody(r, &body); err != nil {
+		return nil, err
+	}
+	targets := make([]adminTarget, 0, len(body.Queries))
+	for _, query := range body.Queries {
+		targets = append(targets, adminTarget{Type: query.Type, Key: query.Key})
+	}
+	return targets, nil
+}
+
+// ruleTarget lê a chave do id da regra ("tipo:chave")
+func ruleTarget(_ *http.Request, params map[string]string) ([]adminTarget, error) {
+	limiterType, key, _ := strings.Cut(params["id"], ":")
+	return []adminTarget{{Type: limiterType, Key: key}}, nil
+}
+
+// ruleBodyTarget lê a chave do id da regra no corpo da criação
+func ruleBodyTarget(r *http.Request, _ map[string]string) ([]adminTarget, error) {
+	var body AdminCreateRuleRequest
+	if err := decodePermissionBody(r, &body); err != nil {
+		return nil, err
+	}
+	return ruleTarget(r, map[string]string{"id": body.ID})
+}
+
+// decodePermissionBody decodifica o corpo e o devolve à requisição para o handler
+func decodePermissionBody(r *http.Request, value interface{}) error {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxPermissionBodyBytes))
+	if err != nil {
+		return fmt.Errorf("failed to read request body: %w", err)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	// Corpo inválido fica para a validação do handler, sem chaves a conferir
+	_ = json.Unmarshal(body, value)
+	return nil
+}
+
+// permitAdmin confere o papel e, para credenciais restritas, o tenant de cada
+// chave da operação. Sem identidade (API aberta) tudo é permitido. Em caso de
+// falha responde 403 e retorna false
+func (h *Handlers) permitAdmin(w http.ResponseWriter, r *http.Request, adminRoute route, params map[string]string) bool {
+	principal := adminauth.PrincipalFrom(r.Context())
+	if principal == nil {
+		return true
+	}
+
+	reason := ""
+	if !principal.Role.Allows(adminRoute.role) {
+		reason = fmt.Sprintf("role %s cannot %s %s", principal.Role, adminRoute.method, adminRoute.path)
+	} else if principal.Scoped() {
+		var err error
+		if reason, err = h.tenantViolation(r, adminRoute, params, principal); err != nil {
+			h.logger.WithContext(r.Context()).Error("Failed to resolve admin tenant", err, map[string]interface{}{
+				"principal": principal.Name,
+				"path":      r.URL.Path,
+			})
+			writeRouteError(w, http.StatusServiceUnavailable, "service_unavailable", "Unable to resolve the key tenant")
+			return false
+		}
+	}
+	if reason == "" {
+		return true
+	}
+
+	h.logger.WithContext(r.Context()).Warn("Admin permission denied", map[string]interface{}{
+		"principal": principal.Name,
+		"role":      principal.Role,
+		"method":    r.Method,
+		"path":      r.URL.Path,
+		"reason":    reason,
+	})
+	// Tentativas negadas de alterar estado também ficam na auditoria
+	h.finishAudit(h.startAudit(r, adminRoute, params), http.StatusForbidden)
+	writeRouteError(w, http.StatusForbidden, "forbidden", adminauth.ErrForbidden.Error()+": "+reason)
+	return false
+}
+
+// adminPermissions é o middleware de permissões de uma rota administrativa no Gin
+func (h *Handlers) adminPermissions(adminRoute route) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		params := make(map[string]string, len(c.Params))
+		for _, param := range c.Params {
+			params[param.Key] = param.Value
+		}
+		if !h.permitAdmin(c.Writer, c.Request, adminRoute, params) {
+			c.Abort()
+		}
+	}
+}
+
+// tenantViolation retorna o motivo da negação quando alguma chave da operação está
+// fora dos tenants da credencial. O tenant de um token é a organização do seu projeto
+func (h *Handlers) tenantViolation(r *http.Request, adminRoute route, params map[string]string, principal *adminauth.Principal) (string, error) {
+	if adminRoute.targets == nil {
+		return fmt.Sprintf("%s %s is not scoped to a tenant", adminRoute.method, adminRoute.path), nil
+	}
+
+	targets, err := adminRoute.targets(r, params)
+	if err != nil {
+		return "", err
+	}
+	for _, target := range targets {
+		key := strings.TrimSpace(target.Key)
+		if domain.LimiterType(strings.ToLower(strings.TrimSpace(target.Type))) != domain.TokenLimiter || key == "" {
+			return "tenant-scoped credentials only reach token keys", nil
+		}
+
+		rule, err := h.service.GetConfig(r.Context(), key, domain.TokenLimiter)
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve token rule: %w", err)
+		}
+		if !principal.InTenant(ruleOrganization(rule)) {
+			return fmt.Sprintf("token %s is outside the credential tenants", h.maskToken(key)), nil
+		}
+	}
+	return "", nil
+}
+
+// ruleOrganization retorna a organização da hierarquia da regra (vazia = nenhuma)
+func ruleOrganization(rule *domain.RateLimitRule) string {
+	for _, parent := range rule.Parents {
+		if parent.Level == domain.LimitLevelOrganization {
+			return parent.Name
+		}
+	}
+	return ""
+}
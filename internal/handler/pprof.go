@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/pprof"
+
+	"rate-limiter/internal/adminauth"
+)
+
+// profilingRoutes expõe o net/http/pprof (goroutines, heap, CPU, trace), só para administradores
+func profilingRoutes() []route {
+	return []route{
+		{http.MethodGet, "/debug/pprof/", pprofHandler, adminauth.RoleAdmin, nil},
+		{http.MethodGet, "/debug/pprof/:profile", pprofHandler, adminauth.RoleAdmin, nil},
+		{http.MethodPost, "/debug/pprof/symbol", pprofHandler, adminauth.RoleAdmin, nil},
+	}
+}
+
+// pprofHandler encaminha ao net/http/pprof com o caminho que ele espera (/debug/pprof/...).
+// Perfis com ?seconds= precisam de um WriteTimeout maior que a duração pedida
+func pprofHandler(c *Exchange) {
+	profile := c.Param("profile")
+	target := "/debug/pprof/" + profile
+	if len(c.Request.Query) > 0 {
+		target += "?" + c.Request.Query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(c.Request.Context(), c.Request.Method, target, c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, H{"error": "bad_request", "message": "Invalid profile request"})
+		return
+	}
+	req.Header = c.Request.Header
+
+	w := &exchangeWriter{response: c.Response}
+	switch profile {
+	case "cmdline":
+		pprof.Cmdline(w, req)
+	case "profile":
+		pprof.Profile(w, req)
+	case "symbol":
+		pprof.Symbol(w, req)
+	case "trace":
+		pprof.Trace(w, req)
+	default:
+		// Index também serve os perfis nomeados (goroutine, heap, allocs, block, mutex...)
+		pprof.Index(w, req)
+	}
+}
+
+// exchangeWriter acumula a saída de um http.Handler na resposta do exchange
+type exchangeWriter struct {
+	response    *Response
+	wroteHeader bool
+}
+
+func (w *exchangeWriter) Header() http.Header {
+	return w.response.Header
+}
+
+func (w *exchangeWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	w.response.Status = status
+}
+
+func (w *exchangeWriter) Write(data []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	w.response.Body = append(w.response.Body, data...)
+	return len(data), nil
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/middleware"
+)
+
+// Proteção de uma rota no inventário
+const (
+	RouteRateLimited = "rate_limited"
+	RouteUnprotected = "unprotected"
+)
+
+// RouteInfo é uma rota registrada no servidor
+type RouteInfo struct {
+	Method string
+	Path   string
+}
+
+// routeInventory lista as rotas registradas e marca as que passam pelo rate limiting
+type routeInventory struct {
+	list      func() []RouteInfo
+	protected map[RouteInfo]bool
+}
+
+// newRouteInventory lê as rotas do router no momento da consulta: rotas
+// registradas depois do SetupRoutes (ex: pelo main) também aparecem
+func newRouteInventory(router *gin.Engine) *routeInventory {
+	return &routeInventory{
+		list: func() []RouteInfo {
+			routes := router.Routes()
+			infos := make([]RouteInfo, 0, len(routes))
+			for _, route := range routes {
+				infos = append(infos, RouteInfo{Method: route.Method, Path: route.Path})
+			}
+			return infos
+		},
+		protected: make(map[RouteInfo]bool),
+	}
+}
+
+// markProtected marca como protegidas as rotas registradas por register.
+// Qualquer rota fora desse bloco é reportada como "unprotected"
+func (i *routeInventory) markProtected(register func()) {
+	existing := make(map[RouteInfo]bool)
+	for _, route := range i.list() {
+		existing[route] = true
+	}
+
+	register()
+
+	for _, route := range i.list() {
+		if !existing[route] {
+			i.protected[route] = true
+		}
+	}
+}
+
+// AdminRoutesHandler lista as rotas registradas com a regra de rate limit efetiva,
+// para auditar se algum endpoint novo ficou fora do middleware
+func (h *Handlers) AdminRoutesHandler(c *Exchange) {
+	if h.routes == nil {
+		c.JSON(http.StatusNotImplemented, H{
+			"error":   "not_implemented",
+			"message": "Route inventory is only available when routes are set up by the server",
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	rule, err := h.effectiveRule(c)
+	if err != nil {
+		h.logger.WithContext(ctx).Error("Failed to resolve effective rate limit rule", err, nil)
+		c.JSON(http.StatusInternalServerError, H{
+			"error":   "internal_server_error",
+			"message": "Failed to resolve effective rate limit rule",
+		})
+		return
+	}
+
+	routes := h.routes.list()
+	sort.Slice(routes, func(a, b int) bool {
+		if routes[a].Path != routes[b].Path {
+			return routes[a].Path < routes[b].Path
+		}
+		return routes[a].Method < routes[b].Method
+	})
+
+	items := make([]H, 0, len(routes))
+	unprotected := 0
+	for _, route := range routes {
+		item := H{"method": route.Method, "path": route.Path}
+		if h.routes.protected[route] {
+			item["protection"] = RouteRateLimited
+			item["rule"] = rule
+		} else {
+			item["protection"] = RouteUnprotected
+			unprotected++
+		}
+		items = append(items, item)
+	}
+
+	c.JSON(http.StatusOK, H{
+		"routes":      items,
+		"count":       len(items),
+		"unprotected": unprotected,
+		"timestamp":   time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// effectiveRule resume a regra aplicada pelo middleware a clientes sem configuração específica
+func (h *Handlers) effectiveRule(c *Exchange) (H, error) {
+	rule := H{}
+	for _, limiterType := range []domain.LimiterType{domain.IPLimiter, domain.TokenLimiter} {
+		config, err := h.service.GetConfig(c.Request.Context(), "*", limiterType)
+		if err != nil {
+			return nil, err
+		}
+		rule[string(limiterType)] = H{
+			"limit":          config.Limit,
+			"window":         config.Window,
+			"block_duration": config.BlockDuration,
+		}
+	}
+
+	failureMode := h.middlewareConfig.FailureMode
+	if failureMode == "" {
+		failureMode = middleware.FailClosed
+	}
+	rule["failure_mode"] = failureMode
+	rule["prechecks"] = len(h.middlewareConfig.PreChecks)
+	return rule, nil
+}
@@ -42,7 +42,7 @@ func TestHandlers_Basic(t *testing.T) {
 		// Arrange
 		handlers := NewHandlers(nil, nil)
 		router := gin.New()
-		router.GET("/admin/status", handlers.AdminStatusHandler)
+		router.GET("/admin/status", GinHandler(handlers.AdminStatusHandler))
 
 		testCases := []struct {
 			name           string
@@ -92,7 +92,7 @@ func TestHandlers_Basic(t *testing.T) {
 		// Arrange
 		handlers := NewHandlers(nil, nil)
 		router := gin.New()
-		router.POST("/admin/reset", handlers.AdminResetHandler)
+		router.POST("/admin/reset", GinHandler(handlers.AdminResetHandler))
 
 		testCases := []struct {
 			name           string
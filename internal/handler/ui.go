@@ -0,0 +1,35 @@
+package handler
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// dashboardHTML é o dashboard administrativo: uma página estática que consome
+// a API JSON do /admin com a credencial informada pelo operador
+//
+//go:embed ui/index.html
+var dashboardHTML []byte
+
+// dashboardCSP restringe a página aos próprios scripts inline e à mesma origem
+const dashboardCSP = "default-src 'none'; script-src 'unsafe-inline'; style-src 'unsafe-inline'; connect-src 'self'; base-uri 'none'; form-action 'none'; frame-ancestors 'none'"
+
+// DashboardHandler serve o dashboard sem autenticação: a página não contém dados,
+// e cada chamada que ela faz à API administrativa envia a credencial do operador
+func DashboardHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		writeRouteError(w, http.StatusMethodNotAllowed, "method_not_allowed", "Method not allowed")
+		return
+	}
+	header := w.Header()
+	header.Set("Content-Type", "text/html; charset=utf-8")
+	header.Set("Content-Security-Policy", dashboardCSP)
+	header.Set("X-Frame-Options", "DENY")
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(dashboardHTML)
+	}
+}
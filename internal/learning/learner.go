@@ -0,0 +1,427 @@
+package learning
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"rate-limiter/internal/domain"
+)
+
+// DefaultMaxKeys limita as chaves acompanhadas
+const DefaultMaxKeys = 10000
+
+// deviations é quantos desvios padrão acima da média a taxa normal ainda cobre
+const deviations = 3
+
+// Config define o treinamento e os limites aceitos na aplicação automática
+type Config struct {
+	Interval       time.Duration // Duração de cada janela; zero usa a janela da configuração ativa
+	TrainingPeriod time.Duration // Tempo que cada chave é observada antes de receber uma proposta
+	Headroom       float64       // Multiplicador sobre a taxa normal aprendida
+	MinFactor      float64       // Piso da proposta, em fração do limite da regra
+	MaxFactor      float64       // Teto da proposta, em fração do limite da regra
+	AutoApply      bool          // Aplica as propostas como overrides a cada período de treinamento
+	MaxKeys        int           // Chaves novas acima disso são descartadas
+}
+
+// DefaultConfig retorna um dia de treinamento, 50% de folga e propostas que
+// nunca afrouxam a regra configurada nem a reduzem abaixo de um quarto
+func DefaultConfig() Config {
+	return Config{
+		TrainingPeriod: 24 * time.Hour,
+		Headroom:       1.5,
+		MinFactor:      0.25,
+		MaxFactor:      1,
+		MaxKeys:        DefaultMaxKeys,
+	}
+}
+
+// OverrideApplier aplica limites por chave (implementado pelo service)
+type OverrideApplier interface {
+	SetOverride(ctx context.Context, override domain.LimitOverride) error
+}
+
+// keyStats acompanha as requisições por janela de uma chave (média e variância de Welford)
+type keyStats struct {
+	limiterType domain.LimiterType
+	key         string
+	current     atomic.Int64
+	firstSeen   time.Time
+	idle        time.Duration // tempo sem requisições desde a última
+	windows     uint64
+	mean        float64
+	m2          float64
+	max         int64
+}
+
+// Proposal é o limite proposto para uma chave
+type Proposal struct {
+	Type          domain.LimiterType
+	Key           string
+	Windows       uint64  // Janelas observadas desde o primeiro acesso (inclusive ociosas)
+	Mean          float64 // Requisições por janela, em média
+	StdDev        float64
+	Max           int64
+	CurrentLimit  int // Limite da regra na configuração ativa
+	LearnedLimit  int // Taxa normal (média + 3 desvios) com a folga, sem os limites
+	ProposedLimit int // LearnedLimit dentro de [MinFactor, MaxFactor] do limite atual
+	Capped        bool
+}
+
+// Report é o estado do aprendizado
+type Report struct {
+	Interval        time.Duration
+	TrainingPeriod  time.Duration
+	AutoApply       bool
+	Since           time.Time
+	LastApplied     time.Time // zero enquanto nada foi aplicado
+	TrackedKeys     int
+	TrainingKeys    int    // Chaves ainda em treinamento, sem proposta
+	DroppedRequests uint64 // Requisições de chaves acima de MaxKeys
+	Proposals       []Proposal
+}
+
+// Learner aprende a taxa normal de cada chave durante um período de treinamento
+// e propõe limites por chave, para revisão ou aplicação automática como overrides.
+// Implementa domain.TrafficObserver: o custo por requisição é um incremento atômico
+type Learner struct {
+	config Config
+	source domain.ConfigSource
+	logger domain.Logger
+
+	mutex sync.RWMutex
+	keys  map[string]*keyStats
+
+	statsMutex  sync.Mutex
+	since       time.Time
+	lastApplied time.Time
+	applier     OverrideApplier
+	dropped     atomic.Uint64
+
+	now func() time.Time
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+	started  atomic.Bool
+}
+
+// NewLearner cria o aprendizado sobre a configuração ativa; valores zerados em
+// config usam DefaultConfig
+func NewLearner(config Config, source domain.ConfigSource, logger domain.Logger) *Learner {
+	defaults := DefaultConfig()
+	if config.Interval <= 0 {
+		config.Interval = time.Duration(source.ActiveConfig().Window) * time.Second
+	}
+	if config.TrainingPeriod <= 0 {
+		config.TrainingPeriod = defaults.TrainingPeriod
+	}
+	if config.Headroom <= 0 {
+		config.Headroom = defaults.Headroom
+	}
+	if config.MinFactor <= 0 {
+		config.MinFactor = defaults.MinFactor
+	}
+	if config.MaxFactor <= 0 {
+		config.MaxFactor = defaults.MaxFactor
+	}
+	if config.MaxKeys <= 0 {
+		config.MaxKeys = defaults.MaxKeys
+	}
+
+	return &Learner{
+		config: config,
+		source: source,
+		logger: logger,
+		keys:   make(map[string]*keyStats),
+		since:  time.Now(),
+		now:    time.Now,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// SetApplier define quem aplica as propostas (o service, criado depois do learner)
+func (l *Learner) SetApplier(applier OverrideApplier) {
+	l.statsMutex.Lock()
+	defer l.statsMutex.Unlock()
+	l.applier = applier
+}
+
+// ObserveRequest implementa domain.TrafficObserver
+func (l *Learner) ObserveRequest(key string, limiterType domain.LimiterType) {
+	id := string(limiterType) + ":" + key
+
+	l.mutex.RLock()
+	tracked, exists := l.keys[id]
+	l.mutex.RUnlock()
+
+	if !exists {
+		l.mutex.Lock()
+		tracked, exists = l.keys[id]
+		if !exists {
+			if len(l.keys) >= l.config.MaxKeys {
+				l.mutex.Unlock()
+				l.dropped.Add(1)
+				return
+			}
+			tracked = &keyStats{limiterType: limiterType, key: key, firstSeen: l.now()}
+			l.keys[id] = tracked
+		}
+		l.mutex.Unlock()
+	}
+
+	tracked.current.Add(1)
+}
+
+// Start inicia o fechamento periódico das janelas
+func (l *Learner) Start() {
+	if l.started.CompareAndSwap(false, true) {
+		go l.run()
+	}
+}
+
+// Stop encerra o aprendizado; overrides já aplicados expiram sozinhos
+func (l *Learner) Stop() {
+	l.stopOnce.Do(func() {
+		close(l.stop)
+	})
+	if l.started.Load() {
+		<-l.done
+	}
+}
+
+func (l *Learner) run() {
+	defer close(l.done)
+
+	ticker := time.NewTicker(l.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			l.Tick()
+		}
+	}
+}
+
+// Tick fecha a janela atual: cada chave conhecida soma a contagem da janela
+// (zero se ociosa) às suas estatísticas, e chaves ociosas por um período de
+// treinamento inteiro saem do acompanhamento. Com AutoApply, as propostas são
+// aplicadas quando o período de treinamento se completa e a cada novo período
+func (l *Learner) Tick() {
+	l.statsMutex.Lock()
+	defer l.statsMutex.Unlock()
+
+	l.mutex.Lock()
+	for id, tracked := range l.keys {
+		count := tracked.current.Swap(0)
+		if count > 0 {
+			tracked.idle = 0
+		} else {
+			tracked.idle += l.config.Interval
+			if tracked.idle >= l.config.TrainingPeriod {
+				delete(l.keys, id)
+				continue
+			}
+		}
+		tracked.add(count)
+	}
+	l.mutex.Unlock()
+
+	if !l.config.AutoApply || l.applier == nil {
+		return
+	}
+	last := l.lastApplied
+	if last.IsZero() {
+		last = l.since
+	}
+	if l.now().Sub(last) >= l.config.TrainingPeriod {
+		l.applyLocked(context.Background())
+	}
+}
+
+// Apply aplica as propostas atuais como overrides, após revisão manual.
+// Retorna quantas chaves receberam um limite
+func (l *Learner) Apply(ctx context.Context) (int, error) {
+	l.statsMutex.Lock()
+	defer l.statsMutex.Unlock()
+
+	if l.applier == nil {
+		return 0, fmt.Errorf("learning applier is not configured")
+	}
+	return l.applyLocked(ctx), nil
+}
+
+// applyLocked aplica as propostas que mudam o limite atual. Os overrides valem
+// por dois períodos de treinamento: sem nova aplicação, a regra volta a valer
+func (l *Learner) applyLocked(ctx context.Context) int {
+	now := l.now()
+	l.lastApplied = now
+
+	applied := 0
+	for _, proposal := range l.proposalsLocked() {
+		if proposal.ProposedLimit == proposal.CurrentLimit {
+			continue
+		}
+		err := l.applier.SetOverride(ctx, domain.LimitOverride{
+			Key:       proposal.Key,
+			Type:      proposal.Type,
+			Limit:     proposal.ProposedLimit,
+			ExpiresAt: now.Add(2 * l.config.TrainingPeriod),
+		})
+		if err != nil {
+			if l.logger != nil {
+				l.logger.Error("Failed to apply learned limit", err, map[string]interface{}{
+					"limiter_type": proposal.Type,
+					"limit":        proposal.ProposedLimit,
+				})
+			}
+			continue
+		}
+		applied++
+	}
+
+	if l.logger != nil {
+		l.logger.Info("Learned limits applied", map[string]interface{}{
+			"applied": applied,
+		})
+	}
+	return applied
+}
+
+// Reset descarta o aprendizado e recomeça o treinamento de todas as chaves
+func (l *Learner) Reset() {
+	l.statsMutex.Lock()
+	defer l.statsMutex.Unlock()
+
+	l.mutex.Lock()
+	l.keys = make(map[string]*keyStats)
+	l.mutex.Unlock()
+
+	l.since = l.now()
+	l.lastApplied = time.Time{}
+	l.dropped.Store(0)
+}
+
+// Report retorna as propostas das chaves que completaram o treinamento
+func (l *Learner) Report() Report {
+	l.statsMutex.Lock()
+	defer l.statsMutex.Unlock()
+
+	proposals := l.proposalsLocked()
+
+	l.mutex.RLock()
+	tracked := len(l.keys)
+	l.mutex.RUnlock()
+
+	return Report{
+		Interval:        l.config.Interval,
+		TrainingPeriod:  l.config.TrainingPeriod,
+		AutoApply:       l.config.AutoApply,
+		Since:           l.since,
+		LastApplied:     l.lastApplied,
+		TrackedKeys:     tracked,
+		TrainingKeys:    tracked - len(proposals),
+		DroppedRequests: l.dropped.Load(),
+		Proposals:       proposals,
+	}
+}
+
+// proposalsLocked monta as propostas, das chaves mais restringidas para as menos
+func (l *Learner) proposalsLocked() []Proposal {
+	cfg := l.source.ActiveConfig()
+	trainedBefore := l.now().Add(-l.config.TrainingPeriod)
+
+	l.mutex.RLock()
+	proposals := make([]Proposal, 0, len(l.keys))
+	for _, tracked := range l.keys {
+		if tracked.firstSeen.After(trainedBefore) || tracked.windows == 0 {
+			continue
+		}
+		currentLimit := ruleLimit(cfg, tracked.key, tracked.limiterType)
+		if currentLimit <= 0 {
+			continue
+		}
+		proposals = append(proposals, l.propose(tracked, currentLimit))
+	}
+	l.mutex.RUnlock()
+
+	sort.Slice(proposals, func(i, j int) bool {
+		a, b := proposals[i], proposals[j]
+		ratioA := float64(a.ProposedLimit) / float64(a.CurrentLimit)
+		ratioB := float64(b.ProposedLimit) / float64(b.CurrentLimit)
+		if ratioA != ratioB {
+			return ratioA < ratioB
+		}
+		return string(a.Type)+":"+a.Key < string(b.Type)+":"+b.Key
+	})
+	return proposals
+}
+
+// propose calcula o limite aprendido e o limita à faixa aceita
+func (l *Learner) propose(tracked *keyStats, currentLimit int) Proposal {
+	stdDev := math.Sqrt(tracked.m2 / float64(tracked.windows))
+	learned := int(math.Ceil((tracked.mean + deviations*stdDev) * l.config.Headroom))
+	if learned < 1 {
+		learned = 1
+	}
+
+	floor := int(math.Ceil(float64(currentLimit) * l.config.MinFactor))
+	ceiling := int(math.Floor(float64(currentLimit) * l.config.MaxFactor))
+	if floor < 1 {
+		floor = 1
+	}
+	if ceiling < floor {
+		ceiling = floor
+	}
+
+	proposed := learned
+	if proposed < floor {
+		proposed = floor
+	} else if proposed > ceiling {
+		proposed = ceiling
+	}
+
+	return Proposal{
+		Type:          tracked.limiterType,
+		Key:           tracked.key,
+		Windows:       tracked.windows,
+		Mean:          tracked.mean,
+		StdDev:        stdDev,
+		Max:           tracked.max,
+		CurrentLimit:  currentLimit,
+		LearnedLimit:  learned,
+		ProposedLimit: proposed,
+		Capped:        proposed != learned,
+	}
+}
+
+// add acumula a contagem de uma janela (algoritmo de Welford)
+func (s *keyStats) add(count int64) {
+	s.windows++
+	delta := float64(count) - s.mean
+	s.mean += delta / float64(s.windows)
+	s.m2 += delta * (float64(count) - s.mean)
+	if count > s.max {
+		s.max = count
+	}
+}
+
+// ruleLimit retorna o limite da regra que se aplica à chave na configuração ativa
+func ruleLimit(cfg *domain.RateLimitConfig, key string, limiterType domain.LimiterType) int {
+	if limiterType == domain.TokenLimiter {
+		if tokenConfig, exists := cfg.TokenConfigs[key]; exists {
+			return tokenConfig.Limit
+		}
+		return cfg.DefaultTokenLimit
+	}
+	return cfg.DefaultIPLimit
+}
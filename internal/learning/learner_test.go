@@ -0,0 +1,146 @@
+package learning
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/domain"
+)
+
+type staticConfig struct{ config *domain.RateLimitConfig }
+
+func (s staticConfig) ActiveConfig() *domain.RateLimitConfig { return s.config }
+
+// recordingApplier guarda os overrides aplicados
+type recordingApplier struct{ overrides []domain.LimitOverride }
+
+func (r *recordingApplier) SetOverride(ctx context.Context, override domain.LimitOverride) error {
+	r.overrides = append(r.overrides, override)
+	return nil
+}
+
+// newTestLearner cria um learner com janelas de 1 minuto, 10 minutos de
+// treinamento e relógio controlado pelo teste
+func newTestLearner(autoApply bool) (*Learner, *time.Time) {
+	source := staticConfig{config: &domain.RateLimitConfig{
+		DefaultIPLimit:    100,
+		DefaultTokenLimit: 100,
+		Window:            60,
+		TokenConfigs: map[string]domain.TokenConfig{
+			"premium_token": {Token: "premium_token", Limit: 5},
+		},
+	}}
+	learner := NewLearner(Config{
+		Interval:       time.Minute,
+		TrainingPeriod: 10 * time.Minute,
+		MinFactor:      0.1,
+		AutoApply:      autoApply,
+	}, source, nil)
+
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	learner.now = func() time.Time { return now }
+	learner.since = now
+	return learner, &now
+}
+
+// observeWindows envia count requisições da chave em cada uma de n janelas
+func observeWindows(learner *Learner, now *time.Time, key string, limiterType domain.LimiterType, count, n int) {
+	for i := 0; i < n; i++ {
+		for j := 0; j < count; j++ {
+			learner.ObserveRequest(key, limiterType)
+		}
+		*now = now.Add(time.Minute)
+		learner.Tick()
+	}
+}
+
+func TestLearner_ProposesLimitsAfterTraining(t *testing.T) {
+	// Arrange
+	learner, now := newTestLearner(false)
+	observeWindows(learner, now, "10.0.0.1", domain.IPLimiter, 10, 10)
+	observeWindows(learner, now, "10.0.0.2", domain.IPLimiter, 10, 1) // ainda em treinamento
+
+	// Act
+	report := learner.Report()
+
+	// Assert
+	assert.Equal(t, 2, report.TrackedKeys)
+	assert.Equal(t, 1, report.TrainingKeys)
+	require.Len(t, report.Proposals, 1)
+
+	assert.Equal(t, Proposal{
+		Type:          domain.IPLimiter,
+		Key:           "10.0.0.1",
+		Windows:       11,
+		Mean:          report.Proposals[0].Mean,
+		StdDev:        report.Proposals[0].StdDev,
+		Max:           10,
+		CurrentLimit:  100,
+		LearnedLimit:  report.Proposals[0].LearnedLimit,
+		ProposedLimit: report.Proposals[0].LearnedLimit,
+	}, report.Proposals[0])
+	assert.InDelta(t, 9.09, report.Proposals[0].Mean, 0.01)
+	assert.Equal(t, 27, report.Proposals[0].LearnedLimit) // (9.09 + 3 * 2.87) * 1.5
+}
+
+// TestLearner_CapsProposalsToRuleLimit testa o teto padrão (MaxFactor=1):
+// a proposta nunca afrouxa o limite configurado
+func TestLearner_CapsProposalsToRuleLimit(t *testing.T) {
+	// Arrange
+	learner, now := newTestLearner(false)
+	observeWindows(learner, now, "premium_token", domain.TokenLimiter, 10, 10)
+
+	// Act
+	report := learner.Report()
+
+	// Assert
+	require.Len(t, report.Proposals, 1)
+	assert.Equal(t, 15, report.Proposals[0].LearnedLimit)
+	assert.Equal(t, 5, report.Proposals[0].ProposedLimit)
+	assert.True(t, report.Proposals[0].Capped)
+}
+
+func TestLearner_AutoApplyAfterTrainingPeriod(t *testing.T) {
+	// Arrange
+	learner, now := newTestLearner(true)
+	applier := &recordingApplier{}
+	learner.SetApplier(applier)
+
+	// Act
+	observeWindows(learner, now, "10.0.0.1", domain.IPLimiter, 10, 9)
+	beforeTraining := len(applier.overrides)
+	observeWindows(learner, now, "10.0.0.1", domain.IPLimiter, 10, 1)
+	observeWindows(learner, now, "premium_token", domain.TokenLimiter, 10, 1)
+
+	// Assert
+	assert.Equal(t, 0, beforeTraining)
+	require.Len(t, applier.overrides, 1)
+	assert.Equal(t, "10.0.0.1", applier.overrides[0].Key)
+	assert.Equal(t, 15, applier.overrides[0].Limit) // sem variação: 10 * 1.5
+	assert.Equal(t, now.Add(-time.Minute).Add(20*time.Minute), applier.overrides[0].ExpiresAt)
+	assert.False(t, learner.Report().LastApplied.IsZero())
+}
+
+func TestLearner_ApplyWithoutApplier(t *testing.T) {
+	learner, _ := newTestLearner(false)
+
+	_, err := learner.Apply(context.Background())
+
+	assert.EqualError(t, err, "learning applier is not configured")
+}
+
+func TestLearner_ForgetsKeysIdleForATrainingPeriod(t *testing.T) {
+	// Arrange
+	learner, now := newTestLearner(false)
+	observeWindows(learner, now, "10.0.0.1", domain.IPLimiter, 10, 1)
+
+	// Act
+	observeWindows(learner, now, "10.0.0.1", domain.IPLimiter, 0, 10)
+
+	// Assert
+	assert.Equal(t, 0, learner.Report().TrackedKeys)
+}
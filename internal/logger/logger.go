@@ -2,6 +2,7 @@ package logger
 
 import (
 	"context"
+	"io"
 	"os"
 	"strings"
 
@@ -14,6 +15,7 @@ import (
 type StructuredLogger struct {
 	logger *logrus.Logger
 	fields logrus.Fields
+	ctx    context.Context // campos da requisição, extraídos apenas ao registrar
 }
 
 // contextKey define chaves para contexto
@@ -28,6 +30,12 @@ const (
 
 // NewLogger cria uma nova instância do logger estruturado
 func NewLogger(level, format string) domain.Logger {
+	return NewLoggerWithOutput(level, format, os.Stdout)
+}
+
+// NewLoggerWithOutput cria o logger estruturado escrevendo em out
+// Útil para canais de log dedicados (ex: log de segurança em arquivo próprio)
+func NewLoggerWithOutput(level, format string, out io.Writer) domain.Logger {
 	logger := logrus.New()
 
 	// Configura o nível de log
@@ -56,7 +64,7 @@ func NewLogger(level, format string) domain.Logger {
 	}
 
 	// Define saída
-	logger.SetOutput(os.Stdout)
+	logger.SetOutput(out)
 
 	return &StructuredLogger{
 		logger: logger,
@@ -91,33 +99,50 @@ func (l *StructuredLogger) Error(msg string, err error, fields map[string]interf
 }
 
 // WithContext cria um novo logger com contexto da requisição
+// Os campos do contexto são extraídos só quando uma mensagem é registrada:
+// no caminho quente, a maioria das requisições não registra nada
 func (l *StructuredLogger) WithContext(ctx context.Context) domain.Logger {
-	contextFields := l.extractContextFields(ctx)
-	
-	// Mescla campos do contexto com campos existentes
-	mergedFields := make(logrus.Fields)
-	for k, v := range l.fields {
-		mergedFields[k] = v
-	}
-	for k, v := range contextFields {
-		mergedFields[k] = v
+	fields := l.fields
+	if l.ctx != nil {
+		// Mantém os campos do contexto anterior, sobrescritos pelos do novo
+		fields = l.mergedFields()
 	}
 
 	return &StructuredLogger{
 		logger: l.logger,
-		fields: mergedFields,
+		fields: fields,
+		ctx:    ctx,
+	}
+}
+
+// DebugEnabled informa se mensagens de debug são registradas (ver domain.DebugEnabled)
+func (l *StructuredLogger) DebugEnabled() bool {
+	return l.logger.IsLevelEnabled(logrus.DebugLevel)
+}
+
+// mergedFields retorna os campos do logger mesclados aos do contexto
+func (l *StructuredLogger) mergedFields() logrus.Fields {
+	merged := make(logrus.Fields, len(l.fields)+4)
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+	if l.ctx != nil {
+		for k, v := range l.extractContextFields(l.ctx) {
+			merged[k] = v
+		}
 	}
+	return merged
 }
 
 // logWithFields registra uma mensagem com campos específicos
 func (l *StructuredLogger) logWithFields(level logrus.Level, msg string, fields map[string]interface{}) {
-	// Mescla todos os campos
-	allFields := make(logrus.Fields)
-	
-	// Adiciona campos do logger
-	for k, v := range l.fields {
-		allFields[k] = v
+	// Mensagens abaixo do nível configurado não montam campos
+	if !l.logger.IsLevelEnabled(level) {
+		return
 	}
+
+	// Mescla campos do logger e do contexto
+	allFields := l.mergedFields()
 	
 	// Adiciona campos da mensagem
 	if fields != nil {
@@ -198,6 +223,7 @@ func (l *StructuredLogger) WithFields(fields map[string]interface{}) domain.Logg
 	return &StructuredLogger{
 		logger: l.logger,
 		fields: newFields,
+		ctx:    l.ctx,
 	}
 }
 
@@ -258,14 +284,37 @@ func (l *StructuredLogger) LogStorageEvent(operation string, key string, success
 }
 
 // ContextWithRequestInfo adiciona informações da requisição ao contexto
+// Os quatro valores ocupam um único nó do contexto (uma alocação por requisição)
 func ContextWithRequestInfo(ctx context.Context, requestID, ip, token, userAgent string) context.Context {
-	ctx = context.WithValue(ctx, RequestIDKey, requestID)
-	ctx = context.WithValue(ctx, IPKey, ip)
-	if token != "" {
-		ctx = context.WithValue(ctx, TokenKey, token)
+	return &requestInfoContext{Context: ctx, requestID: requestID, ip: ip, token: token, userAgent: userAgent}
+}
+
+// requestInfoContext responde às chaves de ContextWithRequestInfo e delega as demais
+type requestInfoContext struct {
+	context.Context
+	requestID, ip, token, userAgent string
+}
+
+// Value implementa context.Context; token vazio não é registrado, como em context.WithValue
+func (c *requestInfoContext) Value(key interface{}) interface{} {
+	switch key {
+	case RequestIDKey:
+		return c.requestID
+	case IPKey:
+		return c.ip
+	case TokenKey:
+		if c.token != "" {
+			return c.token
+		}
+	case UserAgentKey:
+		return c.userAgent
 	}
-	ctx = context.WithValue(ctx, UserAgentKey, userAgent)
-	return ctx
+	return c.Context.Value(key)
+}
+
+// ContextWithRequestID adiciona apenas o request ID ao contexto
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, RequestIDKey, requestID)
 }
 
 // GetRequestID extrai o request ID do contexto
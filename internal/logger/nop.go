@@ -0,0 +1,31 @@
+package logger
+
+import (
+	"context"
+
+	"rate-limiter/internal/domain"
+)
+
+// nopLogger descarta todas as mensagens
+type nopLogger struct{}
+
+// NewNopLogger cria um logger que não registra nada
+// Útil para componentes auxiliares (ex: avaliação em shadow) que não devem duplicar logs
+func NewNopLogger() domain.Logger {
+	return nopLogger{}
+}
+
+func (nopLogger) Debug(msg string, fields map[string]interface{}) {}
+
+func (nopLogger) Info(msg string, fields map[string]interface{}) {}
+
+func (nopLogger) Warn(msg string, fields map[string]interface{}) {}
+
+func (nopLogger) Error(msg string, err error, fields map[string]interface{}) {}
+
+// DebugEnabled evita montar campos que seriam descartados (ver domain.DebugEnabled)
+func (nopLogger) DebugEnabled() bool { return false }
+
+func (l nopLogger) WithContext(ctx context.Context) domain.Logger {
+	return l
+}
@@ -0,0 +1,269 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/events"
+)
+
+// Tipos de eventos emitidos nas transições das janelas
+const (
+	EventStarted = "maintenance.started"
+	EventEnded   = "maintenance.ended"
+)
+
+// ErrInvalidWindow indica uma janela de manutenção mal definida
+var ErrInvalidWindow = errors.New("invalid maintenance window")
+
+// Manager mantém as janelas de manutenção e anuncia início e fim
+// Os limites são relaxados consultando o horário a cada resolução de regra,
+// então a restauração é exata mesmo entre as verificações periódicas
+type Manager struct {
+	publisher events.Publisher
+	logger    domain.Logger
+
+	mutex     sync.RWMutex
+	windows   map[string]domain.MaintenanceWindow
+	announced map[string]struct{} // janelas cujo início já foi anunciado
+
+	now func() time.Time
+
+	started  atomic.Bool
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// NewManager cria o gerenciador de janelas de manutenção
+func NewManager(publisher events.Publisher, logger domain.Logger) *Manager {
+	return &Manager{
+		publisher: publisher,
+		logger:    logger,
+		windows:   make(map[string]domain.MaintenanceWindow),
+		announced: make(map[string]struct{}),
+		now:       time.Now,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// Add valida e registra uma janela (ID gerado quando vazio)
+// Uma janela com o mesmo ID é substituída
+func (m *Manager) Add(ctx context.Context, window domain.MaintenanceWindow) (domain.MaintenanceWindow, error) {
+	window.ID = strings.TrimSpace(window.ID)
+	if window.ID == "" {
+		window.ID = uuid.New().String()
+	}
+	if err := validateWindow(window, m.now()); err != nil {
+		return domain.MaintenanceWindow{}, err
+	}
+
+	m.mutex.Lock()
+	m.windows[window.ID] = window
+	m.mutex.Unlock()
+
+	if m.logger != nil {
+		m.logger.Info("Maintenance window scheduled", map[string]interface{}{
+			"window_id": window.ID,
+			"start":     window.Start.UTC().Format(time.RFC3339),
+			"end":       window.End.UTC().Format(time.RFC3339),
+			"factor":    window.Factor,
+			"disabled":  window.Disabled,
+		})
+	}
+
+	m.Check(ctx)
+	return window, nil
+}
+
+// Remove cancela uma janela, anunciando o fim se ela já estava em vigor
+func (m *Manager) Remove(ctx context.Context, id string) bool {
+	m.mutex.Lock()
+	window, exists := m.windows[id]
+	_, wasAnnounced := m.announced[id]
+	delete(m.windows, id)
+	delete(m.announced, id)
+	m.mutex.Unlock()
+
+	if exists && wasAnnounced {
+		m.publish(ctx, EventEnded, window, "cancelled")
+	}
+	return exists
+}
+
+// List retorna as janelas registradas ordenadas pelo início
+func (m *Manager) List() []domain.MaintenanceWindow {
+	m.mutex.RLock()
+	windows := make([]domain.MaintenanceWindow, 0, len(m.windows))
+	for _, window := range m.windows {
+		windows = append(windows, window)
+	}
+	m.mutex.RUnlock()
+
+	sort.Slice(windows, func(i, j int) bool {
+		if windows[i].Start.Equal(windows[j].Start) {
+			return windows[i].ID < windows[j].ID
+		}
+		return windows[i].Start.Before(windows[j].Start)
+	})
+	return windows
+}
+
+// ActiveMaintenance implementa domain.MaintenanceProvider
+// Com janelas sobrepostas, a desativação prevalece e depois o maior fator
+func (m *Manager) ActiveMaintenance(key string, limiterType domain.LimiterType) (domain.MaintenanceWindow, bool) {
+	now := m.now()
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	var selected domain.MaintenanceWindow
+	found := false
+	for _, window := range m.windows {
+		if !window.IsActive(now) || !window.Matches(key, limiterType) {
+			continue
+		}
+		if !found || moreRelaxed(window, selected) {
+			selected = window
+			found = true
+		}
+	}
+	return selected, found
+}
+
+// Start inicia a verificação periódica das transições
+func (m *Manager) Start(interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	if m.started.CompareAndSwap(false, true) {
+		go m.run(interval)
+	}
+}
+
+// Stop encerra a verificação periódica
+func (m *Manager) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.stop)
+	})
+	if m.started.Load() {
+		<-m.done
+	}
+}
+
+// run é o loop de verificação
+func (m *Manager) run(interval time.Duration) {
+	defer close(m.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+			m.Check(context.Background())
+		}
+	}
+}
+
+// Check anuncia janelas que começaram e remove as encerradas
+func (m *Manager) Check(ctx context.Context) {
+	now := m.now()
+	var started, ended []domain.MaintenanceWindow
+
+	m.mutex.Lock()
+	for id, window := range m.windows {
+		_, wasAnnounced := m.announced[id]
+		switch {
+		case !now.Before(window.End):
+			delete(m.windows, id)
+			delete(m.announced, id)
+			if wasAnnounced {
+				ended = append(ended, window)
+			}
+		case window.IsActive(now) && !wasAnnounced:
+			m.announced[id] = struct{}{}
+			started = append(started, window)
+		}
+	}
+	m.mutex.Unlock()
+
+	for _, window := range started {
+		m.publish(ctx, EventStarted, window, "")
+	}
+	for _, window := range ended {
+		m.publish(ctx, EventEnded, window, "expired")
+	}
+}
+
+// publish emite o evento de transição
+func (m *Manager) publish(ctx context.Context, eventType string, window domain.MaintenanceWindow, cause string) {
+	if m.logger != nil {
+		m.logger.Info("Maintenance window transition", map[string]interface{}{
+			"window_id": window.ID,
+			"event":     eventType,
+		})
+	}
+	if m.publisher == nil {
+		return
+	}
+
+	data := map[string]interface{}{
+		"window_id": window.ID,
+		"start":     window.Start.UTC().Format(time.RFC3339),
+		"end":       window.End.UTC().Format(time.RFC3339),
+		"type":      string(window.Type),
+		"keys":      window.Keys,
+		"factor":    window.Factor,
+		"disabled":  window.Disabled,
+		"reason":    window.Reason,
+	}
+	if cause != "" {
+		data["cause"] = cause
+	}
+	m.publisher.Publish(ctx, eventType, data)
+}
+
+// moreRelaxed informa se a janela a relaxa mais os limites que b
+func moreRelaxed(a, b domain.MaintenanceWindow) bool {
+	if a.Disabled != b.Disabled {
+		return a.Disabled
+	}
+	return a.Factor > b.Factor
+}
+
+// validateWindow verifica intervalo, escopo e efeito da janela
+func validateWindow(window domain.MaintenanceWindow, now time.Time) error {
+	if window.Start.IsZero() || window.End.IsZero() {
+		return fmt.Errorf("%w: start and end are required", ErrInvalidWindow)
+	}
+	if !window.End.After(window.Start) {
+		return fmt.Errorf("%w: end must be after start", ErrInvalidWindow)
+	}
+	if !window.End.After(now) {
+		return fmt.Errorf("%w: end must be in the future", ErrInvalidWindow)
+	}
+
+	switch window.Type {
+	case "", domain.IPLimiter, domain.TokenLimiter:
+	default:
+		return fmt.Errorf("%w: type must be 'ip', 'token' or empty", ErrInvalidWindow)
+	}
+
+	if !window.Disabled && window.Factor <= 0 {
+		return fmt.Errorf("%w: factor must be greater than 0 unless disabled", ErrInvalidWindow)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package maintenance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/events"
+)
+
+// newTestManager cria um manager com relógio controlado e captura dos eventos
+func newTestManager(now *time.Time) (*Manager, *[]events.Event) {
+	bus := events.NewBus()
+	received := &[]events.Event{}
+	bus.Subscribe(func(event events.Event) { *received = append(*received, event) })
+
+	manager := NewManager(bus, nil)
+	manager.now = func() time.Time { return *now }
+	return manager, received
+}
+
+func TestManager_TransitionsEmitEvents(t *testing.T) {
+	// Arrange
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	manager, received := newTestManager(&now)
+
+	window, err := manager.Add(context.Background(), domain.MaintenanceWindow{
+		ID:     "deploy",
+		Start:  now.Add(time.Minute),
+		End:    now.Add(time.Hour),
+		Factor: 2,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "deploy", window.ID)
+
+	// Antes do início: sem efeito e sem eventos
+	_, active := manager.ActiveMaintenance("10.0.0.1", domain.IPLimiter)
+	assert.False(t, active)
+	assert.Empty(t, *received)
+
+	// Act: início
+	now = now.Add(2 * time.Minute)
+	manager.Check(context.Background())
+	manager.Check(context.Background())
+
+	// Assert
+	require.Len(t, *received, 1)
+	assert.Equal(t, EventStarted, (*received)[0].Type)
+	activeWindow, active := manager.ActiveMaintenance("10.0.0.1", domain.IPLimiter)
+	assert.True(t, active)
+	assert.Equal(t, 2.0, activeWindow.Factor)
+
+	// Act: fim
+	now = now.Add(time.Hour)
+	manager.Check(context.Background())
+
+	// Assert
+	require.Len(t, *received, 2)
+	assert.Equal(t, EventEnded, (*received)[1].Type)
+	assert.Equal(t, "expired", (*received)[1].Data["cause"])
+	assert.Empty(t, manager.List())
+}
+
+func TestManager_ActiveMaintenanceMatchesScope(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	manager, _ := newTestManager(&now)
+
+	_, err := manager.Add(context.Background(), domain.MaintenanceWindow{
+		Start: now, End: now.Add(time.Hour), Type: domain.TokenLimiter, Keys: []string{"partner"}, Factor: 3,
+	})
+	require.NoError(t, err)
+	_, err = manager.Add(context.Background(), domain.MaintenanceWindow{
+		Start: now, End: now.Add(time.Hour), Type: domain.TokenLimiter, Keys: []string{"partner"}, Disabled: true,
+	})
+	require.NoError(t, err)
+
+	window, active := manager.ActiveMaintenance("partner", domain.TokenLimiter)
+	assert.True(t, active)
+	assert.True(t, window.Disabled)
+
+	_, active = manager.ActiveMaintenance("other", domain.TokenLimiter)
+	assert.False(t, active)
+	_, active = manager.ActiveMaintenance("partner", domain.IPLimiter)
+	assert.False(t, active)
+}
+
+func TestManager_RemoveAnnouncesCancellation(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	manager, received := newTestManager(&now)
+
+	_, err := manager.Add(context.Background(), domain.MaintenanceWindow{ID: "migration", Start: now, End: now.Add(time.Hour), Disabled: true})
+	require.NoError(t, err)
+	require.Len(t, *received, 1)
+
+	assert.True(t, manager.Remove(context.Background(), "migration"))
+	assert.False(t, manager.Remove(context.Background(), "migration"))
+
+	require.Len(t, *received, 2)
+	assert.Equal(t, EventEnded, (*received)[1].Type)
+	assert.Equal(t, "cancelled", (*received)[1].Data["cause"])
+}
+
+func TestManager_AddValidation(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	manager, _ := newTestManager(&now)
+
+	tests := []struct {
+		name   string
+		window domain.MaintenanceWindow
+	}{
+		{name: "Missing end", window: domain.MaintenanceWindow{Start: now, Factor: 2}},
+		{name: "End before start", window: domain.MaintenanceWindow{Start: now, End: now.Add(-time.Minute), Factor: 2}},
+		{name: "Already over", window: domain.MaintenanceWindow{Start: now.Add(-2 * time.Hour), End: now.Add(-time.Hour), Factor: 2}},
+		{name: "No effect", window: domain.MaintenanceWindow{Start: now, End: now.Add(time.Hour)}},
+		{name: "Unknown type", window: domain.MaintenanceWindow{Start: now, End: now.Add(time.Hour), Type: "user", Factor: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := manager.Add(context.Background(), tt.window)
+			assert.ErrorIs(t, err, ErrInvalidWindow)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"rate-limiter/internal/middleware"
+)
+
+// DecisionFailureSource expõe as falhas de decisão do middleware (ver middleware.Counters)
+type DecisionFailureSource interface {
+	Failures() []middleware.FailureCount
+}
+
+// TarpitSource expõe as requisições retidas pelo tarpit; opcional para a fonte do
+// DecisionCollector (middleware.Counters a implementa)
+type TarpitSource interface {
+	Tarpits() []middleware.TarpitCount
+}
+
+// ChallengeSource expõe os desfechos do desafio; opcional para a fonte do
+// DecisionCollector (middleware.Counters a implementa)
+type ChallengeSource interface {
+	Challenges() []middleware.ChallengeCount
+}
+
+// DecisionCollector exporta as falhas de decisão por motivo (timeout, error) e
+// pelo desfecho aplicado pela política de falha (degraded, rejected)
+type DecisionCollector struct {
+	source DecisionFailureSource
+
+	failures   *prometheus.Desc
+	tarpits    *prometheus.Desc
+	challenges *prometheus.Desc
+}
+
+// NewDecisionCollector cria o collector sobre os contadores do middleware
+func NewDecisionCollector(source DecisionFailureSource) *DecisionCollector {
+	return &DecisionCollector{
+		source: source,
+		failures: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "decision", "failures_total"),
+			"Rate limit decisions that failed, by reason and failure mode outcome.",
+			[]string{"reason", "outcome"}, nil,
+		),
+		tarpits: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "decision", "tarpit_total"),
+			"Suspicious requests handled by the tarpit, by decision and outcome (delayed, skipped).",
+			[]string{"decision", "outcome"}, nil,
+		),
+		challenges: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "decision", "challenge_total"),
+			"Throttled requests handled by the challenge, by outcome (issued, passed, invalid).",
+			[]string{"outcome"}, nil,
+		),
+	}
+}
+
+// Describe implementa prometheus.Collector
+func (c *DecisionCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.failures
+	ch <- c.tarpits
+	ch <- c.challenges
+}
+
+// Collect implementa prometheus.Collector
+func (c *DecisionCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, failure := range c.source.Failures() {
+		ch <- prometheus.MustNewConstMetric(c.failures, prometheus.CounterValue, float64(failure.Count), failure.Reason, failure.Outcome)
+	}
+
+	if source, ok := c.source.(TarpitSource); ok {
+		for _, tarpit := range source.Tarpits() {
+			ch <- prometheus.MustNewConstMetric(c.tarpits, prometheus.CounterValue, float64(tarpit.Count), tarpit.Decision, tarpit.Outcome)
+		}
+	}
+
+	if source, ok := c.source.(ChallengeSource); ok {
+		for _, challenge := range source.Challenges() {
+			ch <- prometheus.MustNewConstMetric(c.challenges, prometheus.CounterValue, float64(challenge.Count), challenge.Outcome)
+		}
+	}
+}
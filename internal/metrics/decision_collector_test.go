@@ -0,0 +1,66 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/middleware"
+)
+
+type fakeDecisionFailures []middleware.FailureCount
+
+func (f fakeDecisionFailures) Failures() []middleware.FailureCount {
+	return f
+}
+
+func TestDecisionCollector(t *testing.T) {
+	// Arrange
+	source := fakeDecisionFailures{
+		{Reason: middleware.FailureReasonTimeout, Outcome: middleware.FailureOutcomeDegraded, Count: 3},
+		{Reason: middleware.FailureReasonError, Outcome: middleware.FailureOutcomeRejected, Count: 1},
+	}
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(NewDecisionCollector(source)))
+
+	// Act & Assert
+	expected := `
+# HELP rate_limiter_decision_failures_total Rate limit decisions that failed, by reason and failure mode outcome.
+# TYPE rate_limiter_decision_failures_total counter
+rate_limiter_decision_failures_total{outcome="degraded",reason="timeout"} 3
+rate_limiter_decision_failures_total{outcome="rejected",reason="error"} 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected)))
+}
+
+func TestDecisionCollector_Tarpit(t *testing.T) {
+	// Arrange
+	counters := &middleware.Counters{}
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(NewDecisionCollector(counters)))
+
+	// Act
+	count, err := testutil.GatherAndCount(registry, "rate_limiter_decision_tarpit_total")
+
+	// Assert: uma série por decisão e desfecho
+	require.NoError(t, err)
+	assert.Equal(t, 4, count)
+}
+
+func TestDecisionCollector_Challenge(t *testing.T) {
+	// Arrange
+	counters := &middleware.Counters{}
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(NewDecisionCollector(counters)))
+
+	// Act
+	count, err := testutil.GatherAndCount(registry, "rate_limiter_decision_challenge_total")
+
+	// Assert: uma série por desfecho
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+}
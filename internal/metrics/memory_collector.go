@@ -0,0 +1,107 @@
+package metrics
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"rate-limiter/internal/storage"
+)
+
+const namespace = "rate_limiter"
+
+// MemoryCollector exporta os internos do MemoryStorage no formato Prometheus
+// Permite enxergar problemas de capacidade do modo memória antes de um OOM
+type MemoryCollector struct {
+	storages map[string]*storage.MemoryStorage
+
+	dataEntries      *prometheus.Desc
+	blockEntries     *prometheus.Desc
+	cleanupRemovals  *prometheus.Desc
+	evictions        *prometheus.Desc
+	lruEvictions     *prometheus.Desc
+	lockAcquisitions *prometheus.Desc
+	lockWait         *prometheus.Desc
+}
+
+// NewMemoryCollector cria o collector para os storages em memória nomeados
+func NewMemoryCollector(storages map[string]*storage.MemoryStorage) *MemoryCollector {
+	labels := []string{"storage"}
+
+	return &MemoryCollector{
+		storages: storages,
+		dataEntries: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "memory", "data_entries"),
+			"Number of rate limit entries held in the memory storage.",
+			labels, nil,
+		),
+		blockEntries: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "memory", "block_entries"),
+			"Number of blocked keys held in the memory storage.",
+			labels, nil,
+		),
+		cleanupRemovals: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "memory", "cleanup_removals_total"),
+			"Entries removed by the periodic cleanup, by map.",
+			append(labels, "map"), nil,
+		),
+		evictions: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "memory", "evictions_total"),
+			"Entries evicted before the periodic cleanup (TTL expiry).",
+			labels, nil,
+		),
+		lruEvictions: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "memory", "lru_evictions_total"),
+			"Least recently used keys evicted to stay within MEMORY_MAX_KEYS.",
+			labels, nil,
+		),
+		lockAcquisitions: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "memory", "lock_acquisitions_total"),
+			"Lock acquisitions on the memory storage, by shard.",
+			append(labels, "shard"), nil,
+		),
+		lockWait: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "memory", "lock_wait_seconds_total"),
+			"Cumulative time spent waiting for memory storage locks, by shard.",
+			append(labels, "shard"), nil,
+		),
+	}
+}
+
+// Describe implementa prometheus.Collector
+func (c *MemoryCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.dataEntries
+	ch <- c.blockEntries
+	ch <- c.cleanupRemovals
+	ch <- c.evictions
+	ch <- c.lruEvictions
+	ch <- c.lockAcquisitions
+	ch <- c.lockWait
+}
+
+// Collect implementa prometheus.Collector
+func (c *MemoryCollector) Collect(ch chan<- prometheus.Metric) {
+	names := make([]string, 0, len(c.storages))
+	for name := range c.storages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		snapshot := c.storages[name].MetricsSnapshot()
+
+		ch <- prometheus.MustNewConstMetric(c.dataEntries, prometheus.GaugeValue, float64(snapshot.DataEntries), name)
+		ch <- prometheus.MustNewConstMetric(c.blockEntries, prometheus.GaugeValue, float64(snapshot.BlockEntries), name)
+		ch <- prometheus.MustNewConstMetric(c.cleanupRemovals, prometheus.CounterValue, float64(snapshot.CleanupRemovedData), name, "data")
+		ch <- prometheus.MustNewConstMetric(c.cleanupRemovals, prometheus.CounterValue, float64(snapshot.CleanupRemovedBlocks), name, "blocks")
+		ch <- prometheus.MustNewConstMetric(c.evictions, prometheus.CounterValue, float64(snapshot.Evictions), name)
+		ch <- prometheus.MustNewConstMetric(c.lruEvictions, prometheus.CounterValue, float64(snapshot.LRUEvictions), name)
+
+		for shard, locks := range snapshot.ShardLocks {
+			label := strconv.Itoa(shard)
+			ch <- prometheus.MustNewConstMetric(c.lockAcquisitions, prometheus.CounterValue, float64(locks.Acquisitions), name, label)
+			ch <- prometheus.MustNewConstMetric(c.lockWait, prometheus.CounterValue, locks.Wait.Seconds(), name, label)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/storage"
+)
+
+func TestMemoryCollector(t *testing.T) {
+	// Arrange
+	memoryStorage := storage.NewMemoryStorage(nil)
+	defer memoryStorage.Close()
+
+	ctx := context.Background()
+	_, _, err := memoryStorage.Increment(ctx, "rate_limit:ip:10.0.0.1", 10, time.Minute)
+	require.NoError(t, err)
+	_, _, err = memoryStorage.Increment(ctx, "rate_limit:ip:10.0.0.2", 10, time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, memoryStorage.Block(ctx, "rate_limit:ip:10.0.0.2", time.Minute))
+
+	collector := NewMemoryCollector(map[string]*storage.MemoryStorage{"memory": memoryStorage})
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(collector))
+
+	// Act & Assert
+	expected := `
+# HELP rate_limiter_memory_block_entries Number of blocked keys held in the memory storage.
+# TYPE rate_limiter_memory_block_entries gauge
+rate_limiter_memory_block_entries{storage="memory"} 1
+# HELP rate_limiter_memory_data_entries Number of rate limit entries held in the memory storage.
+# TYPE rate_limiter_memory_data_entries gauge
+rate_limiter_memory_data_entries{storage="memory"} 2
+`
+	err = testutil.GatherAndCompare(registry, strings.NewReader(expected),
+		"rate_limiter_memory_data_entries", "rate_limiter_memory_block_entries")
+	assert.NoError(t, err)
+
+	count, err := testutil.GatherAndCount(registry, "rate_limiter_memory_lock_acquisitions_total", "rate_limiter_memory_cleanup_removals_total")
+	require.NoError(t, err)
+	shards := len(memoryStorage.MetricsSnapshot().ShardLocks)
+	assert.Equal(t, shards+2, count, "one lock series per shard and one cleanup series per map")
+	assert.Greater(t, memoryStorage.MetricsSnapshot().LockAcquisitions, uint64(0))
+}
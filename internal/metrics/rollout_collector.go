@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/rollout"
+)
+
+// RolloutSource lista os rollouts com as decisões por versão
+type RolloutSource interface {
+	List() []rollout.Snapshot
+}
+
+// RolloutCollector exporta as decisões por versão de cada rollout canário
+// Permite comparar a taxa de bloqueio da nova versão com a estável antes de promover
+type RolloutCollector struct {
+	source RolloutSource
+
+	decisions *prometheus.Desc
+	percent   *prometheus.Desc
+}
+
+// NewRolloutCollector cria o collector sobre os rollouts ativos
+func NewRolloutCollector(source RolloutSource) *RolloutCollector {
+	return &RolloutCollector{
+		source: source,
+		decisions: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "rollout", "decisions_total"),
+			"Rate limit decisions per rule rollout, version and outcome.",
+			[]string{"rollout", "version", "decision"}, nil,
+		),
+		percent: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "rollout", "canary_percent"),
+			"Percentage of traffic routed to the new rule version.",
+			[]string{"rollout"}, nil,
+		),
+	}
+}
+
+// Describe implementa prometheus.Collector
+func (c *RolloutCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.decisions
+	ch <- c.percent
+}
+
+// Collect implementa prometheus.Collector
+func (c *RolloutCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, snapshot := range c.source.List() {
+		ch <- prometheus.MustNewConstMetric(c.percent, prometheus.GaugeValue, float64(snapshot.Percent), snapshot.ID)
+
+		versions := []struct {
+			name  string
+			stats rollout.VersionStats
+		}{
+			{name: domain.RuleVersionStable, stats: snapshot.Stable},
+			{name: domain.RuleVersionCanary, stats: snapshot.Canary},
+		}
+		for _, version := range versions {
+			ch <- prometheus.MustNewConstMetric(c.decisions, prometheus.CounterValue, float64(version.stats.Allowed), snapshot.ID, version.name, "allowed")
+			ch <- prometheus.MustNewConstMetric(c.decisions, prometheus.CounterValue, float64(version.stats.Denied), snapshot.ID, version.name, "denied")
+		}
+	}
+}
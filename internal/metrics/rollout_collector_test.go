@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/rollout"
+)
+
+func TestRolloutCollector(t *testing.T) {
+	// Arrange
+	manager := rollout.NewManager(nil)
+	_, err := manager.Start(domain.RuleRollout{Type: domain.IPLimiter, Limit: 20, Percent: 10})
+	require.NoError(t, err)
+	manager.RecordDecision("ip:*", domain.RuleVersionStable, true)
+	manager.RecordDecision("ip:*", domain.RuleVersionCanary, false)
+	manager.RecordDecision("ip:*", domain.RuleVersionCanary, false)
+
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(NewRolloutCollector(manager)))
+
+	// Act & Assert
+	expected := `
+# HELP rate_limiter_rollout_canary_percent Percentage of traffic routed to the new rule version.
+# TYPE rate_limiter_rollout_canary_percent gauge
+rate_limiter_rollout_canary_percent{rollout="ip:*"} 10
+# HELP rate_limiter_rollout_decisions_total Rate limit decisions per rule rollout, version and outcome.
+# TYPE rate_limiter_rollout_decisions_total counter
+rate_limiter_rollout_decisions_total{decision="allowed",rollout="ip:*",version="canary"} 0
+rate_limiter_rollout_decisions_total{decision="allowed",rollout="ip:*",version="stable"} 1
+rate_limiter_rollout_decisions_total{decision="denied",rollout="ip:*",version="canary"} 2
+rate_limiter_rollout_decisions_total{decision="denied",rollout="ip:*",version="stable"} 0
+`
+	assert.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(expected)))
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BodyLimit limita o corpo das requisições a maxBytes (0 = sem limite).
+// Content-Length acima do limite é recusado com 413 antes de qualquer leitura;
+// corpos sem Content-Length (chunked) falham na leitura ao ultrapassar o limite
+// e a conexão é encerrada
+func BodyLimit(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if maxBytes <= 0 || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > maxBytes {
+			// O corpo não é lido: a conexão não pode ser reaproveitada
+			c.Header("Connection", "close")
+			abortWithJSON(c, http.StatusRequestEntityTooLarge, gin.H{
+				"error":   "request_too_large",
+				"message": fmt.Sprintf("Request body exceeds %d bytes", maxBytes),
+			})
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		c.Next()
+	}
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBodyLimit(t *testing.T) {
+	// Arrange: handler que lê o corpo inteiro
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(BodyLimit(16))
+	router.POST("/admin/reset", func(c *gin.Context) {
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"size": len(body)})
+	})
+
+	post := func(body io.Reader, contentLength int64) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/admin/reset", body)
+		req.ContentLength = contentLength
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Act
+	small := post(strings.NewReader(`{"key":"a"}`), 11)
+	declared := post(strings.NewReader(strings.Repeat("x", 32)), 32)
+	chunked := post(strings.NewReader(strings.Repeat("x", 32)), -1)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, small.Code)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, declared.Code)
+	assert.Contains(t, declared.Body.String(), "request_too_large")
+	assert.Equal(t, "close", declared.Header().Get("Connection"))
+	assert.Equal(t, http.StatusBadRequest, chunked.Code)
+	assert.Contains(t, chunked.Body.String(), "request body too large")
+}
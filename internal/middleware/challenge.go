@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Headers e cookie do desafio
+const (
+	// ChallengeHeader aponta, na resposta, a página de verificação do desafio
+	ChallengeHeader = "X-RateLimit-Challenge"
+	// ChallengePassHeader traz o comprovante de desafio resolvido
+	ChallengePassHeader = "X-RateLimit-Challenge-Pass"
+	// ChallengePassCookie traz o comprovante em navegadores, no lugar do header
+	ChallengePassCookie = "rl_challenge_pass"
+)
+
+// Challenge troca a recusa de um cliente limitado por um desafio (ex: CAPTCHA).
+// Navegadores são redirecionados à página de verificação; os demais clientes
+// recebem o 429 com a URL do desafio. Resolvido o desafio, a página emite um
+// comprovante assinado (ver IssueChallengePass) que libera o IP do cliente até
+// expirar, mesmo com a chave bloqueada. As requisições permitidas continuam contando
+type Challenge struct {
+	// URL é a página de verificação; recebe o destino original em return_to (vazia = desabilitado)
+	URL string
+
+	// Secret assina os comprovantes (HMAC-SHA256); é compartilhado com a página de verificação
+	Secret string
+}
+
+// Enabled informa se o desafio está ligado
+func (c Challenge) Enabled() bool {
+	return c.URL != "" && c.Secret != ""
+}
+
+// IssueChallengePass emite o comprovante de desafio resolvido pelo cliente com o
+// IP subject, válido até expires. O formato, para emissores em outras linguagens:
+//
+//	<expires unix>.<hex(HMAC-SHA256(secret, subject + "\n" + expires unix))>
+func IssueChallengePass(secret, subject string, expires time.Time) string {
+	unix := strconv.FormatInt(expires.Unix(), 10)
+	return unix + "." + challengeSignature(secret, subject, unix)
+}
+
+// challengeSignature assina o IP do cliente e a validade do comprovante
+func challengeSignature(secret, subject, expires string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(subject + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verify informa se pass é um comprovante válido e não expirado para o IP subject
+func (c Challenge) verify(pass, subject string, now time.Time) bool {
+	expires, signature, ok := strings.Cut(pass, ".")
+	if !ok {
+		return false
+	}
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || !now.Before(time.Unix(unix, 0)) {
+		return false
+	}
+	return hmac.Equal([]byte(strings.ToLower(signature)), []byte(challengeSignature(c.Secret, subject, expires)))
+}
+
+// challengePass retorna o comprovante da requisição: o header ou, sem ele, o cookie
+func challengePass(c *gin.Context) string {
+	if pass := c.GetHeader(ChallengePassHeader); pass != "" {
+		return pass
+	}
+	pass, _ := c.Cookie(ChallengePassCookie)
+	return pass
+}
+
+// challengeURL monta a URL da página de verificação com o destino original
+func (c Challenge) challengeURL(returnTo string) string {
+	separator := "?"
+	if strings.Contains(c.URL, "?") {
+		separator = "&"
+	}
+	return c.URL + separator + "return_to=" + url.QueryEscape(returnTo)
+}
+
+// acceptsHTML informa se a requisição veio de um navegador navegando para a página
+func acceptsHTML(c *gin.Context) bool {
+	method := c.Request.Method
+	return (method == http.MethodGet || method == http.MethodHead) &&
+		strings.Contains(c.GetHeader("Accept"), "text/html")
+}
+
+// applyChallenge decide a recusa quando o desafio está ligado. Retorna true se o
+// comprovante do cliente liberou a requisição; senão responde com o desafio no
+// lugar de response (o 429) e retorna false
+func (m *RateLimiterMiddleware) applyChallenge(c *gin.Context, clientIP string, response *ErrorResponse) bool {
+	if pass := challengePass(c); pass != "" {
+		if m.challenge.verify(pass, clientIP, m.now()) {
+			m.counters.recordChallenge(ChallengeOutcomePassed)
+			return true
+		}
+		m.counters.recordChallenge(ChallengeOutcomeInvalid)
+	}
+	m.counters.recordChallenge(ChallengeOutcomeIssued)
+
+	target := m.challenge.challengeURL(c.Request.URL.RequestURI())
+	c.Header(ChallengeHeader, target)
+	if acceptsHTML(c) {
+		c.Redirect(http.StatusSeeOther, target)
+		c.Abort()
+		return false
+	}
+
+	response.Error = "challenge_required"
+	response.ChallengeURL = target
+	abortWithResponse(c, m.serializer, response)
+	return false
+}
@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/domain"
+)
+
+const testChallengeSecret = "0123456789abcdef0123456789abcdef"
+
+func TestChallenge_Verify(t *testing.T) {
+	challenge := Challenge{URL: "/verify", Secret: testChallengeSecret}
+	now := time.Now()
+	pass := IssueChallengePass(testChallengeSecret, "192.168.1.1", now.Add(time.Minute))
+
+	tests := []struct {
+		name     string
+		pass     string
+		subject  string
+		now      time.Time
+		expected bool
+	}{
+		{"valid pass", pass, "192.168.1.1", now, true},
+		{"another client", pass, "192.168.1.2", now, false},
+		{"expired", pass, "192.168.1.1", now.Add(time.Minute), false},
+		{"another secret", IssueChallengePass("another-secret", "192.168.1.1", now.Add(time.Minute)), "192.168.1.1", now, false},
+		{"tampered expiry", "9999999999" + pass[len(pass)-65:], "192.168.1.1", now, false},
+		{"malformed", "not-a-pass", "192.168.1.1", now, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, challenge.verify(tt.pass, tt.subject, tt.now))
+		})
+	}
+}
+
+func TestRateLimiterMiddleware_Challenge(t *testing.T) {
+	// Arrange: cliente bloqueado, desafio ligado
+	mockService := new(MockRateLimiterService)
+	mockLogger := new(MockLogger)
+	blockedUntil := time.Now().Add(3 * time.Minute)
+	mockService.On("CheckLimit", mock.Anything, "192.168.1.1", "").Return(&domain.RateLimitResult{
+		Allowed:      false,
+		Limit:        10,
+		Remaining:    0,
+		ResetTime:    time.Now().Add(time.Minute),
+		BlockedUntil: &blockedUntil,
+		LimiterType:  domain.IPLimiter,
+	}, nil)
+	mockLogger.On("WithContext", mock.Anything).Return(mockLogger)
+	mockLogger.On("Debug", mock.AnythingOfType("string"), mock.Anything).Maybe()
+	mockLogger.On("Info", mock.AnythingOfType("string"), mock.Anything)
+
+	counters := &Counters{}
+	var decision string
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(NewRateLimiterMiddleware(mockService, mockLogger, WithConfig(Config{
+		Counters:  counters,
+		Challenge: Challenge{URL: "https://verify.example.com/challenge", Secret: testChallengeSecret},
+	})))
+	router.GET("/test", func(c *gin.Context) {
+		decision = GetDecision(c)
+		c.Status(http.StatusOK)
+	})
+	serve := func(setup func(req *http.Request)) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/test?page=2", nil)
+		req.Header.Set("X-Forwarded-For", "192.168.1.1")
+		setup(req)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	pass := IssueChallengePass(testChallengeSecret, "192.168.1.1", time.Now().Add(time.Minute))
+	target := "https://verify.example.com/challenge?return_to=%2Ftest%3Fpage%3D2"
+
+	// Act
+	api := serve(func(req *http.Request) {})
+	browser := serve(func(req *http.Request) { req.Header.Set("Accept", "text/html,application/xhtml+xml") })
+	withHeader := serve(func(req *http.Request) { req.Header.Set(ChallengePassHeader, pass) })
+	withCookie := serve(func(req *http.Request) { req.AddCookie(&http.Cookie{Name: ChallengePassCookie, Value: pass}) })
+	otherClient := serve(func(req *http.Request) {
+		req.Header.Set(ChallengePassHeader, IssueChallengePass(testChallengeSecret, "10.0.0.1", time.Now().Add(time.Minute)))
+	})
+
+	// Assert: clientes de API recebem o 429 com a URL do desafio
+	assert.Equal(t, http.StatusTooManyRequests, api.Code)
+	assert.Equal(t, target, api.Header().Get(ChallengeHeader))
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(api.Body.Bytes(), &body))
+	assert.Equal(t, "challenge_required", body["error"])
+	assert.Equal(t, target, body["challenge_url"])
+
+	// Navegadores são redirecionados
+	assert.Equal(t, http.StatusSeeOther, browser.Code)
+	assert.Equal(t, target, browser.Header().Get("Location"))
+
+	// O comprovante libera o cliente, por header ou cookie, apenas para o próprio IP
+	assert.Equal(t, http.StatusOK, withHeader.Code)
+	assert.Equal(t, http.StatusOK, withCookie.Code)
+	assert.Equal(t, DecisionChallengePassed, decision)
+	assert.Equal(t, http.StatusTooManyRequests, otherClient.Code)
+
+	assert.Equal(t, []ChallengeCount{
+		{Outcome: ChallengeOutcomeIssued, Count: 3},
+		{Outcome: ChallengeOutcomePassed, Count: 2},
+		{Outcome: ChallengeOutcomeInvalid, Count: 1},
+	}, counters.Challenges())
+}
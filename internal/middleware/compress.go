@@ -0,0 +1,325 @@
+package middleware
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultCompressionMinSize é o tamanho mínimo da resposta comprimida quando
+// CompressionConfig.MinSize é 0; abaixo disso o custo supera o ganho
+const DefaultCompressionMinSize = 1024
+
+// Encoder é uma codificação de Content-Encoding oferecida na negociação
+// com o Accept-Encoding do cliente (ex: gzip, ou brotli via biblioteca externa)
+type Encoder struct {
+	Name      string                           // valor do Content-Encoding (ex: "gzip", "br")
+	NewWriter func(w io.Writer) io.WriteCloser // compressor que escreve em w
+}
+
+// GzipEncoder comprime com gzip no nível informado (0 = gzip.DefaultCompression).
+// Os compressores são reaproveitados entre respostas
+func GzipEncoder(level int) Encoder {
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	pool := &sync.Pool{New: func() interface{} {
+		writer, err := gzip.NewWriterLevel(io.Discard, level)
+		if err != nil {
+			writer = gzip.NewWriter(io.Discard)
+		}
+		return writer
+	}}
+
+	return Encoder{
+		Name: "gzip",
+		NewWriter: func(w io.Writer) io.WriteCloser {
+			writer := pool.Get().(*gzip.Writer)
+			writer.Reset(w)
+			return &pooledGzipWriter{Writer: writer, pool: pool}
+		},
+	}
+}
+
+// pooledGzipWriter devolve o compressor ao pool no Close
+type pooledGzipWriter struct {
+	*gzip.Writer
+	pool *sync.Pool
+}
+
+func (w *pooledGzipWriter) Close() error {
+	err := w.Writer.Close()
+	w.pool.Put(w.Writer)
+	return err
+}
+
+// CompressionConfig configura a compressão de respostas
+type CompressionConfig struct {
+	// MinSize é o tamanho mínimo em bytes para comprimir (0 = DefaultCompressionMinSize)
+	MinSize int
+	// Encoders em ordem de preferência do servidor (vazio = gzip no nível padrão)
+	Encoders []Encoder
+}
+
+// Compression comprime as respostas do grupo de rotas conforme o Accept-Encoding
+func Compression(config CompressionConfig) gin.HandlerFunc {
+	compressor := newCompressor(config)
+	return func(c *gin.Context) {
+		encoder, ok := compressor.negotiate(c.Request, c.Writer.Header())
+		if !ok {
+			c.Next()
+			return
+		}
+
+		writer := &ginCompressWriter{ResponseWriter: c.Writer}
+		writer.compressWriter = compressor.wrap(c.Writer, encoder)
+		c.Writer = writer
+		defer func() {
+			writer.Close()
+			c.Writer = writer.ResponseWriter
+		}()
+		c.Next()
+	}
+}
+
+// CompressHandler é a versão net/http do Compression (ex: para o AdminHTTPHandler)
+func CompressHandler(config CompressionConfig, next http.Handler) http.Handler {
+	compressor := newCompressor(config)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoder, ok := compressor.negotiate(r, w.Header())
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		writer := compressor.wrap(w, encoder)
+		defer writer.Close()
+		next.ServeHTTP(writer, r)
+	})
+}
+
+// compressor guarda a configuração normalizada
+type compressor struct {
+	minSize  int
+	encoders []Encoder
+}
+
+func newCompressor(config CompressionConfig) *compressor {
+	c := &compressor{minSize: config.MinSize, encoders: config.Encoders}
+	if c.minSize <= 0 {
+		c.minSize = DefaultCompressionMinSize
+	}
+	if len(c.encoders) == 0 {
+		c.encoders = []Encoder{GzipEncoder(0)}
+	}
+	return c
+}
+
+// negotiate escolhe o encoder pelo Accept-Encoding (maior q; empate pela ordem do servidor).
+// Respostas que podem ser comprimidas variam pelo Accept-Encoding para os caches
+func (c *compressor) negotiate(r *http.Request, header http.Header) (Encoder, bool) {
+	if r.Method == http.MethodHead {
+		return Encoder{}, false
+	}
+	header.Add("Vary", "Accept-Encoding")
+
+	accepted := parseAcceptEncoding(r.Header.Get("Accept-Encoding"))
+	var best Encoder
+	bestQuality := 0.0
+	for _, encoder := range c.encoders {
+		quality, ok := accepted[encoder.Name]
+		if !ok {
+			quality, ok = accepted["*"]
+		}
+		if ok && quality > bestQuality {
+			best, bestQuality = encoder, quality
+		}
+	}
+	return best, bestQuality > 0
+}
+
+// parseAcceptEncoding retorna a qualidade (q) de cada codificação aceita
+func parseAcceptEncoding(value string) map[string]float64 {
+	accepted := make(map[string]float64)
+	for _, part := range strings.Split(value, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		accepted[name] = quality
+	}
+	return accepted
+}
+
+// wrap cria o writer que decide pela compressão ao atingir minSize
+func (c *compressor) wrap(w http.ResponseWriter, encoder Encoder) *compressWriter {
+	return &compressWriter{ResponseWriter: w, encoder: encoder, minSize: c.minSize, status: http.StatusOK}
+}
+
+// compressWriter acumula o início da resposta até minSize bytes: respostas
+// menores seguem sem compressão e as maiores são comprimidas por inteiro
+type compressWriter struct {
+	http.ResponseWriter
+	encoder     Encoder
+	minSize     int
+	status      int
+	wroteHeader bool
+	decided     bool
+	buffer      []byte
+	compressor  io.WriteCloser
+}
+
+func (w *compressWriter) WriteHeader(status int) {
+	if w.decided || w.wroteHeader {
+		return
+	}
+	w.status = status
+	w.wroteHeader = true
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if !w.decided {
+		w.buffer = append(w.buffer, data...)
+		if len(w.buffer) < w.minSize {
+			return len(data), nil
+		}
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+		return len(data), nil
+	}
+	if w.compressor != nil {
+		return w.compressor.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// decide envia o status e os headers e escreve o que estava acumulado,
+// comprimido ou não
+func (w *compressWriter) decide(compress bool) error {
+	w.decided = true
+	header := w.Header()
+	// Respostas já codificadas ou sem corpo não são comprimidas
+	if header.Get("Content-Encoding") != "" || !bodyAllowed(w.status) {
+		compress = false
+	}
+
+	if compress {
+		header.Set("Content-Encoding", w.encoder.Name)
+		header.Del("Content-Length")
+		w.ResponseWriter.WriteHeader(w.status)
+		w.compressor = w.encoder.NewWriter(w.ResponseWriter)
+		_, err := w.compressor.Write(w.buffer)
+		w.buffer = nil
+		return err
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+	if len(w.buffer) == 0 {
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(w.buffer)
+	w.buffer = nil
+	return err
+}
+
+// Flush envia o que estiver acumulado; respostas em streaming que ainda não
+// atingiram minSize seguem sem compressão
+func (w *compressWriter) Flush() {
+	if !w.decided {
+		w.decide(false)
+	}
+	if flusher, ok := w.compressor.(interface{ Flush() error }); ok {
+		flusher.Flush()
+	}
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Close finaliza a resposta: respostas curtas saem sem compressão
+func (w *compressWriter) Close() error {
+	if !w.decided {
+		if !w.wroteHeader && len(w.buffer) == 0 {
+			// Nada foi escrito: o chamador ainda responde pelo status padrão
+			w.decided = true
+			return nil
+		}
+		return w.decide(false)
+	}
+	if w.compressor != nil {
+		return w.compressor.Close()
+	}
+	return nil
+}
+
+// bodyAllowed informa se o status admite corpo na resposta
+func bodyAllowed(status int) bool {
+	return status >= http.StatusOK && status != http.StatusNoContent && status != http.StatusNotModified
+}
+
+// ginCompressWriter expõe o compressWriter como gin.ResponseWriter
+type ginCompressWriter struct {
+	gin.ResponseWriter
+	*compressWriter
+}
+
+func (w *ginCompressWriter) Header() http.Header {
+	return w.ResponseWriter.Header()
+}
+
+func (w *ginCompressWriter) WriteHeader(status int) {
+	w.compressWriter.WriteHeader(status)
+}
+
+// WriteHeaderNow envia os headers sem corpo (ex: 204 do Gin): sem compressão
+func (w *ginCompressWriter) WriteHeaderNow() {
+	if !w.decided {
+		w.decide(false)
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *ginCompressWriter) Write(data []byte) (int, error) {
+	return w.compressWriter.Write(data)
+}
+
+func (w *ginCompressWriter) WriteString(s string) (int, error) {
+	return w.compressWriter.Write([]byte(s))
+}
+
+func (w *ginCompressWriter) Status() int {
+	if !w.decided {
+		return w.status
+	}
+	return w.ResponseWriter.Status()
+}
+
+func (w *ginCompressWriter) Written() bool {
+	return w.wroteHeader || w.ResponseWriter.Written()
+}
+
+func (w *ginCompressWriter) Flush() {
+	w.compressWriter.Flush()
+}
+
+func (w *ginCompressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return w.ResponseWriter.Hijack()
+}
@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nopEncoder simula uma codificação externa (ex: brotli) sem transformar o corpo
+func nopEncoder(name string) Encoder {
+	return Encoder{Name: name, NewWriter: func(w io.Writer) io.WriteCloser {
+		return nopWriteCloser{w}
+	}}
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+func TestCompression_Gin(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	large := strings.Repeat("blocked ", 500)
+	router := gin.New()
+	router.Use(Compression(CompressionConfig{MinSize: 256}))
+	router.GET("/large", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"report": large}) })
+	router.GET("/small", func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"ok": true}) })
+	router.GET("/empty", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	request := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Act
+	compressed := request("/large", "br;q=1.0, gzip;q=0.8")
+	plain := request("/large", "")
+	refused := request("/large", "gzip;q=0")
+	small := request("/small", "gzip")
+	empty := request("/empty", "gzip")
+
+	// Assert
+	assert.Equal(t, http.StatusOK, compressed.Code)
+	assert.Equal(t, "gzip", compressed.Header().Get("Content-Encoding"))
+	assert.Equal(t, "Accept-Encoding", compressed.Header().Get("Vary"))
+	reader, err := gzip.NewReader(compressed.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), large)
+	assert.Less(t, compressed.Body.Len(), len(body))
+
+	assert.Empty(t, plain.Header().Get("Content-Encoding"))
+	assert.Contains(t, plain.Body.String(), large)
+	assert.Empty(t, refused.Header().Get("Content-Encoding"))
+
+	assert.Empty(t, small.Header().Get("Content-Encoding"))
+	assert.JSONEq(t, `{"ok":true}`, small.Body.String())
+
+	assert.Equal(t, http.StatusNoContent, empty.Code)
+	assert.Empty(t, empty.Header().Get("Content-Encoding"))
+}
+
+func TestCompressHandler_Negotiation(t *testing.T) {
+	// Arrange: o servidor prefere br; o cliente decide pelo q
+	handler := CompressHandler(CompressionConfig{
+		MinSize:  1,
+		Encoders: []Encoder{nopEncoder("br"), GzipEncoder(gzip.BestSpeed)},
+	}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"created":true}`)
+	}))
+
+	tests := []struct {
+		acceptEncoding string
+		expected       string
+	}{
+		{"gzip, br", "br"},
+		{"gzip, br;q=0.5", "gzip"},
+		{"*", "br"},
+		{"identity", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.acceptEncoding, func(t *testing.T) {
+			// Act
+			req := httptest.NewRequest("POST", "/admin/rules", nil)
+			req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, http.StatusCreated, w.Code)
+			assert.Equal(t, tt.expected, w.Header().Get("Content-Encoding"))
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package middleware
+
+import "sync/atomic"
+
+// Motivos das falhas de decisão contadas em Counters
+const (
+	FailureReasonTimeout = "timeout" // o service não respondeu dentro do timeout da decisão
+	FailureReasonError   = "error"   // o service retornou erro
+)
+
+// Desfechos aplicados pela política de falha
+const (
+	FailureOutcomeDegraded = "degraded" // fail-open: a requisição seguiu sem rate limiting
+	FailureOutcomeRejected = "rejected" // fail-closed: a requisição foi recusada
+)
+
+// Desfechos do tarpit contados em Counters
+const (
+	TarpitOutcomeDelayed = "delayed" // a resposta foi retida
+	TarpitOutcomeSkipped = "skipped" // sem vaga em Tarpit.MaxConcurrent, respondida na hora
+)
+
+// Desfechos do desafio contados em Counters
+const (
+	ChallengeOutcomeIssued  = "issued"  // o desafio substituiu o 429
+	ChallengeOutcomePassed  = "passed"  // o comprovante liberou o cliente bloqueado
+	ChallengeOutcomeInvalid = "invalid" // comprovante com assinatura errada, de outro IP ou expirado
+)
+
+// Counters acumula as falhas de decisão do middleware por motivo e desfecho, as
+// requisições do tarpit e os desafios, para exportação em métricas. Seguro para uso concorrente;
+// o valor zero está pronto
+type Counters struct {
+	timeoutDegraded atomic.Int64
+	timeoutRejected atomic.Int64
+	errorDegraded   atomic.Int64
+	errorRejected   atomic.Int64
+
+	tarpitAllowedDelayed   atomic.Int64
+	tarpitAllowedSkipped   atomic.Int64
+	tarpitThrottledDelayed atomic.Int64
+	tarpitThrottledSkipped atomic.Int64
+
+	challengeIssued  atomic.Int64
+	challengePassed  atomic.Int64
+	challengeInvalid atomic.Int64
+}
+
+// ChallengeCount é o total de desafios com um desfecho
+type ChallengeCount struct {
+	Outcome string
+	Count   int64
+}
+
+// TarpitCount é o total de requisições suspeitas de uma decisão com um desfecho
+type TarpitCount struct {
+	Decision string // DecisionAllowed ou DecisionThrottled
+	Outcome  string
+	Count    int64
+}
+
+// FailureCount é o total de falhas de um motivo com um desfecho
+type FailureCount struct {
+	Reason  string
+	Outcome string
+	Count   int64
+}
+
+// Failures retorna os totais de todas as combinações de motivo e desfecho
+func (c *Counters) Failures() []FailureCount {
+	return []FailureCount{
+		{Reason: FailureReasonTimeout, Outcome: FailureOutcomeDegraded, Count: c.timeoutDegraded.Load()},
+		{Reason: FailureReasonTimeout, Outcome: FailureOutcomeRejected, Count: c.timeoutRejected.Load()},
+		{Reason: FailureReasonError, Outcome: FailureOutcomeDegraded, Count: c.errorDegraded.Load()},
+		{Reason: FailureReasonError, Outcome: FailureOutcomeRejected, Count: c.errorRejected.Load()},
+	}
+}
+
+// Tarpits retorna os totais do tarpit por decisão e desfecho
+func (c *Counters) Tarpits() []TarpitCount {
+	return []TarpitCount{
+		{Decision: DecisionAllowed, Outcome: TarpitOutcomeDelayed, Count: c.tarpitAllowedDelayed.Load()},
+		{Decision: DecisionAllowed, Outcome: TarpitOutcomeSkipped, Count: c.tarpitAllowedSkipped.Load()},
+		{Decision: DecisionThrottled, Outcome: TarpitOutcomeDelayed, Count: c.tarpitThrottledDelayed.Load()},
+		{Decision: DecisionThrottled, Outcome: TarpitOutcomeSkipped, Count: c.tarpitThrottledSkipped.Load()},
+	}
+}
+
+// recordTarpit contabiliza uma requisição suspeita; sem Counters configurado não faz nada
+func (c *Counters) recordTarpit(allowed, delayed bool) {
+	if c == nil {
+		return
+	}
+
+	switch {
+	case allowed && delayed:
+		c.tarpitAllowedDelayed.Add(1)
+	case allowed:
+		c.tarpitAllowedSkipped.Add(1)
+	case delayed:
+		c.tarpitThrottledDelayed.Add(1)
+	default:
+		c.tarpitThrottledSkipped.Add(1)
+	}
+}
+
+// Challenges retorna os totais do desafio por desfecho
+func (c *Counters) Challenges() []ChallengeCount {
+	return []ChallengeCount{
+		{Outcome: ChallengeOutcomeIssued, Count: c.challengeIssued.Load()},
+		{Outcome: ChallengeOutcomePassed, Count: c.challengePassed.Load()},
+		{Outcome: ChallengeOutcomeInvalid, Count: c.challengeInvalid.Load()},
+	}
+}
+
+// recordChallenge contabiliza um desfecho do desafio; sem Counters configurado não faz nada
+func (c *Counters) recordChallenge(outcome string) {
+	if c == nil {
+		return
+	}
+
+	switch outcome {
+	case ChallengeOutcomeIssued:
+		c.challengeIssued.Add(1)
+	case ChallengeOutcomePassed:
+		c.challengePassed.Add(1)
+	case ChallengeOutcomeInvalid:
+		c.challengeInvalid.Add(1)
+	}
+}
+
+// recordFailure contabiliza uma falha; sem Counters configurado não faz nada
+func (c *Counters) recordFailure(timedOut, degraded bool) {
+	if c == nil {
+		return
+	}
+
+	switch {
+	case timedOut && degraded:
+		c.timeoutDegraded.Add(1)
+	case timedOut:
+		c.timeoutRejected.Add(1)
+	case degraded:
+		c.errorDegraded.Add(1)
+	default:
+		c.errorRejected.Add(1)
+	}
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Nomes canônicos dos headers lidos e escritos em toda requisição. O acesso
+// direto ao http.Header evita canonicalizar (e alocar) o nome a cada chamada
+var (
+	headerAPIKey       = http.CanonicalHeaderKey("API_KEY")
+	headerXAPIToken    = http.CanonicalHeaderKey("X-Api-Token")
+	headerAPIToken     = http.CanonicalHeaderKey("Api-Token")
+	headerForwardedFor = http.CanonicalHeaderKey("X-Forwarded-For")
+	headerRequestID    = http.CanonicalHeaderKey(RequestIDHeader)
+	headerLimit        = http.CanonicalHeaderKey("X-RateLimit-Limit")
+	headerRemaining    = http.CanonicalHeaderKey("X-RateLimit-Remaining")
+	headerReset        = http.CanonicalHeaderKey("X-RateLimit-Reset")
+	headerLimiterType  = http.CanonicalHeaderKey("X-RateLimit-Type")
+	headerScope        = http.CanonicalHeaderKey("X-RateLimit-Scope")
+	headerRetryAfter   = http.CanonicalHeaderKey("Retry-After")
+)
+
+// requestHeader lê o primeiro valor do header de nome já canônico
+func requestHeader(c *gin.Context, canonicalKey string) string {
+	if values := c.Request.Header[canonicalKey]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// setHeader define o header de resposta de nome já canônico
+func setHeader(c *gin.Context, canonicalKey, value string) {
+	c.Writer.Header()[canonicalKey] = []string{value}
+}
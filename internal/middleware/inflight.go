@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// inFlightPollInterval é o intervalo entre as verificações de Wait
+const inFlightPollInterval = 10 * time.Millisecond
+
+// InFlight conta as requisições em andamento, para a drenagem esperar que
+// terminem antes do desligamento
+type InFlight struct {
+	count atomic.Int64
+}
+
+// NewInFlight cria o contador
+func NewInFlight() *InFlight {
+	return &InFlight{}
+}
+
+// Middleware conta a requisição enquanto os handlers seguintes executam
+func (f *InFlight) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		f.count.Add(1)
+		defer f.count.Add(-1)
+		c.Next()
+	}
+}
+
+// Count retorna quantas requisições estão em andamento
+func (f *InFlight) Count() int64 {
+	return f.count.Load()
+}
+
+// Wait aguarda até não haver requisições em andamento ou o contexto terminar
+func (f *InFlight) Wait(ctx context.Context) error {
+	ticker := time.NewTicker(inFlightPollInterval)
+	defer ticker.Stop()
+
+	for f.count.Load() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
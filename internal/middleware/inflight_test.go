@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInFlight_WaitsForRunningRequests(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	tracker := NewInFlight()
+	release := make(chan struct{})
+	router := gin.New()
+	router.Use(tracker.Middleware())
+	router.GET("/slow", func(c *gin.Context) {
+		<-release
+		c.Status(http.StatusOK)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/slow", nil))
+		close(done)
+	}()
+	require.Eventually(t, func() bool { return tracker.Count() == 1 }, time.Second, time.Millisecond)
+
+	// Act
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	timeoutErr := tracker.Wait(ctx)
+	cancel()
+	close(release)
+	<-done
+	waitErr := tracker.Wait(context.Background())
+
+	// Assert
+	assert.ErrorIs(t, timeoutErr, context.DeadlineExceeded)
+	assert.NoError(t, waitErr)
+	assert.Equal(t, int64(0), tracker.Count())
+}
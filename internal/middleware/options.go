@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Option configura o middleware criado por NewRateLimiterMiddleware
+type Option func(*RateLimiterMiddleware)
+
+// KeyExtractor identifica o cliente da requisição: IP e token de API
+// (vazio quando a requisição não traz token)
+type KeyExtractor func(c *gin.Context) (clientIP, apiToken string)
+
+// SkipFunc retorna true quando a requisição deve seguir sem rate limiting
+type SkipFunc func(c *gin.Context) bool
+
+// DefaultKeyExtractor usa X-Forwarded-For, X-Real-IP e RemoteAddr para o IP e
+// os headers API_KEY, X-Api-Token e Api-Token para o token
+func DefaultKeyExtractor(c *gin.Context) (string, string) {
+	return GetClientIP(c), GetAPIToken(c)
+}
+
+// WithConfig aplica as configurações agrupadas em Config
+func WithConfig(config Config) Option {
+	return func(m *RateLimiterMiddleware) {
+		m.preChecks = append(m.preChecks, config.PreChecks...)
+		if config.FailureMode != "" {
+			m.failureMode = config.FailureMode
+		}
+		if config.Timeout > 0 {
+			m.timeout = config.Timeout
+		}
+		if config.Counters != nil {
+			m.counters = config.Counters
+		}
+		if config.RejectionCache.Enabled() {
+			m.rejectionCache = config.RejectionCache
+		}
+		if config.Serializer != nil {
+			m.serializer = config.Serializer
+		}
+		if config.Tarpit.Enabled() {
+			m.tarpit = &tarpitState{config: config.Tarpit}
+		}
+		if config.Challenge.Enabled() {
+			challenge := config.Challenge
+			m.challenge = &challenge
+		}
+	}
+}
+
+// WithPreChecks acrescenta estágios avaliados em ordem antes de qualquer acesso ao storage
+func WithPreChecks(preChecks ...PreCheck) Option {
+	return func(m *RateLimiterMiddleware) {
+		m.preChecks = append(m.preChecks, preChecks...)
+	}
+}
+
+// WithSkipFunc dispensa do rate limiting as requisições para as quais skip
+// retorna true (ex: rotas internas); avaliado junto com as pré-verificações
+func WithSkipFunc(skip SkipFunc) Option {
+	return WithPreChecks(func(c *gin.Context, clientIP, apiToken string) bool {
+		return skip(c)
+	})
+}
+
+// WithKeyExtractor substitui a identificação do cliente (ex: IP vindo de um
+// header próprio do load balancer, token em um cookie)
+func WithKeyExtractor(extractor KeyExtractor) Option {
+	return func(m *RateLimiterMiddleware) {
+		m.extractKeys = extractor
+	}
+}
+
+// WithHeaders habilita (padrão) ou omite os headers X-RateLimit-* e os headers
+// extras do token nas respostas; o Retry-After do 429 é mantido
+func WithHeaders(enabled bool) Option {
+	return func(m *RateLimiterMiddleware) {
+		m.headers = enabled
+	}
+}
+
+// WithFailureMode define a política quando o service falha ou excede o timeout
+func WithFailureMode(mode FailureMode) Option {
+	return func(m *RateLimiterMiddleware) {
+		m.failureMode = mode
+	}
+}
+
+// WithTimeout limita a espera pela decisão do service
+func WithTimeout(timeout time.Duration) Option {
+	return func(m *RateLimiterMiddleware) {
+		m.timeout = timeout
+	}
+}
+
+// WithCounters recebe as falhas de decisão por motivo e desfecho (ver Counters)
+func WithCounters(counters *Counters) Option {
+	return func(m *RateLimiterMiddleware) {
+		m.counters = counters
+	}
+}
+
+// WithRejectionCache marca o 429 como cacheável por CDNs e proxies (ver RejectionCache)
+func WithRejectionCache(cache RejectionCache) Option {
+	return func(m *RateLimiterMiddleware) {
+		m.rejectionCache = cache
+	}
+}
+
+// WithTarpit atrasa as respostas de clientes suspeitos em vez de respondê-las na hora (ver Tarpit)
+func WithTarpit(tarpit Tarpit) Option {
+	return func(m *RateLimiterMiddleware) {
+		m.tarpit = nil
+		if tarpit.Enabled() {
+			m.tarpit = &tarpitState{config: tarpit}
+		}
+	}
+}
+
+// WithChallenge troca a recusa por um desafio como um CAPTCHA (ver Challenge)
+func WithChallenge(challenge Challenge) Option {
+	return func(m *RateLimiterMiddleware) {
+		m.challenge = nil
+		if challenge.Enabled() {
+			m.challenge = &challenge
+		}
+	}
+}
+
+// WithResponseSerializer troca o envelope dos corpos de erro (429, 5xx)
+func WithResponseSerializer(serializer ResponseSerializer) Option {
+	return func(m *RateLimiterMiddleware) {
+		m.serializer = serializer
+	}
+}
+
+// WithClock substitui o relógio usado no cálculo do Retry-After (testes)
+func WithClock(now func() time.Time) Option {
+	return func(m *RateLimiterMiddleware) {
+		m.now = now
+	}
+}
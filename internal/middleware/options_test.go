@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"rate-limiter/internal/domain"
+)
+
+func TestOptions_KeyExtractorAndSkip(t *testing.T) {
+	// Arrange: IP vindo de um header do load balancer; /test?internal=1 dispensado
+	mockService := new(MockRateLimiterService)
+	mockLogger := new(MockLogger)
+	mockService.On("CheckLimit", mock.Anything, "10.0.0.7", "cookie-token").Return(&domain.RateLimitResult{
+		Allowed:     true,
+		Limit:       10,
+		Remaining:   9,
+		ResetTime:   time.Now().Add(time.Minute),
+		LimiterType: domain.TokenLimiter,
+	}, nil).Once()
+	mockLogger.On("WithContext", mock.Anything).Return(mockLogger)
+	mockLogger.On("Debug", mock.AnythingOfType("string"), mock.Anything).Maybe()
+
+	router := setupTestRouter(NewRateLimiterMiddleware(mockService, mockLogger,
+		WithKeyExtractor(func(c *gin.Context) (string, string) {
+			token, _ := c.Cookie("session")
+			return c.GetHeader("X-LB-Client"), token
+		}),
+		WithSkipFunc(func(c *gin.Context) bool {
+			return c.Query("internal") == "1"
+		}),
+	))
+
+	// Act
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("X-LB-Client", "10.0.0.7")
+	req.AddCookie(&http.Cookie{Name: "session", Value: "cookie-token"})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	skipped := httptest.NewRecorder()
+	router.ServeHTTP(skipped, httptest.NewRequest("GET", "/test?internal=1", nil))
+
+	// Assert: a requisição dispensada não consulta o service
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "9", w.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, http.StatusOK, skipped.Code)
+	assert.Empty(t, skipped.Header().Get("X-RateLimit-Limit"))
+	mockService.AssertExpectations(t)
+}
+
+func TestOptions_HeadersAndClock(t *testing.T) {
+	// Arrange: relógio fixo a 30s do fim do bloqueio, headers informativos desabilitados
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	blockedUntil := now.Add(30 * time.Second)
+	mockService := new(MockRateLimiterService)
+	mockLogger := new(MockLogger)
+	mockService.On("CheckLimit", mock.Anything, "192.168.1.1", "").Return(&domain.RateLimitResult{
+		Allowed:      false,
+		Limit:        10,
+		ResetTime:    blockedUntil,
+		BlockedUntil: &blockedUntil,
+		LimiterType:  domain.IPLimiter,
+		Headers:      map[string]string{"X-Plan": "free"},
+	}, nil)
+	mockLogger.On("WithContext", mock.Anything).Return(mockLogger)
+	mockLogger.On("Debug", mock.AnythingOfType("string"), mock.Anything).Maybe()
+	mockLogger.On("Info", mock.AnythingOfType("string"), mock.Anything).Maybe()
+
+	router := setupTestRouter(NewRateLimiterMiddleware(mockService, mockLogger,
+		WithHeaders(false),
+		WithClock(func() time.Time { return now }),
+	))
+
+	// Act
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("X-Forwarded-For", "192.168.1.1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "30", w.Header().Get("Retry-After"))
+	assert.Empty(t, w.Header().Get("X-RateLimit-Limit"))
+	assert.Empty(t, w.Header().Get("X-Plan"))
+}
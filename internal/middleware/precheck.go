@@ -0,0 +1,124 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PreCheck é um estágio executado antes da verificação de rate limit
+// Retorna true quando a requisição deve seguir sem passar pelo rate limiter,
+// evitando qualquer acesso ao storage
+type PreCheck func(c *gin.Context, clientIP, apiToken string) bool
+
+// ipSet agrupa IPs exatos e faixas CIDR
+type ipSet struct {
+	ips      map[string]struct{}
+	networks []*net.IPNet
+}
+
+// newIPSet interpreta entradas de IP ou CIDR; label identifica a origem nos erros
+func newIPSet(entries []string, label string) (ipSet, error) {
+	set := ipSet{ips: make(map[string]struct{})}
+
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if strings.Contains(entry, "/") {
+			_, network, err := net.ParseCIDR(entry)
+			if err != nil {
+				return ipSet{}, fmt.Errorf("invalid %s CIDR %s: %w", label, entry, err)
+			}
+			set.networks = append(set.networks, network)
+			continue
+		}
+
+		ip := net.ParseIP(entry)
+		if ip == nil {
+			return ipSet{}, fmt.Errorf("invalid %s IP: %s", label, entry)
+		}
+		set.ips[ip.String()] = struct{}{}
+	}
+
+	return set, nil
+}
+
+// isEmpty informa se o conjunto não possui entradas
+func (s ipSet) isEmpty() bool {
+	return len(s.ips) == 0 && len(s.networks) == 0
+}
+
+// contains verifica se o IP pertence ao conjunto
+func (s ipSet) contains(value string) bool {
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return false
+	}
+
+	if _, ok := s.ips[ip.String()]; ok {
+		return true
+	}
+
+	for _, network := range s.networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Allowlist contém as identidades que não são limitadas
+type Allowlist struct {
+	ips    ipSet
+	tokens map[string]struct{}
+}
+
+// NewAllowlist cria uma allowlist a partir de IPs/CIDRs e tokens
+func NewAllowlist(ips, tokens []string) (*Allowlist, error) {
+	set, err := newIPSet(ips, "allowlist")
+	if err != nil {
+		return nil, err
+	}
+
+	allowlist := &Allowlist{
+		ips:    set,
+		tokens: make(map[string]struct{}),
+	}
+
+	for _, token := range tokens {
+		if token = strings.TrimSpace(token); token != "" {
+			allowlist.tokens[token] = struct{}{}
+		}
+	}
+
+	return allowlist, nil
+}
+
+// IsEmpty informa se a allowlist não possui entradas
+func (a *Allowlist) IsEmpty() bool {
+	return a.ips.isEmpty() && len(a.tokens) == 0
+}
+
+// Contains verifica se o IP ou o token estão na allowlist
+func (a *Allowlist) Contains(clientIP, apiToken string) bool {
+	if apiToken != "" {
+		if _, ok := a.tokens[apiToken]; ok {
+			return true
+		}
+	}
+
+	return a.ips.contains(clientIP)
+}
+
+// PreCheck retorna o estágio de pré-verificação da allowlist
+func (a *Allowlist) PreCheck() PreCheck {
+	return func(c *gin.Context, clientIP, apiToken string) bool {
+		return a.Contains(clientIP, apiToken)
+	}
+}
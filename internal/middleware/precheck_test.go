@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAllowlist_Contains testa a correspondência de IPs, CIDRs e tokens
+func TestAllowlist_Contains(t *testing.T) {
+	allowlist, err := NewAllowlist([]string{"10.0.0.1", "192.168.0.0/16"}, []string{"internal-token"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		ip       string
+		token    string
+		expected bool
+	}{
+		{name: "Should match exact IP", ip: "10.0.0.1", expected: true},
+		{name: "Should match CIDR range", ip: "192.168.10.20", expected: true},
+		{name: "Should match token", ip: "203.0.113.1", token: "internal-token", expected: true},
+		{name: "Should not match unknown IP", ip: "203.0.113.1", expected: false},
+		{name: "Should not match invalid IP", ip: "not-an-ip", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, allowlist.Contains(tt.ip, tt.token))
+		})
+	}
+}
+
+// TestNewAllowlist_InvalidEntries testa a validação das entradas
+func TestNewAllowlist_InvalidEntries(t *testing.T) {
+	_, err := NewAllowlist([]string{"10.0.0.0/33"}, nil)
+	assert.Error(t, err)
+
+	_, err = NewAllowlist([]string{"invalid"}, nil)
+	assert.Error(t, err)
+
+	allowlist, err := NewAllowlist(nil, nil)
+	require.NoError(t, err)
+	assert.True(t, allowlist.IsEmpty())
+}
+
+// TestRateLimiterMiddleware_PreCheckSkipsService testa que identidades da allowlist não acessam o service
+func TestRateLimiterMiddleware_PreCheckSkipsService(t *testing.T) {
+	// Arrange
+	mockService := new(MockRateLimiterService)
+	mockLogger := new(MockLogger)
+	mockLogger.On("Debug", mock.AnythingOfType("string"), mock.Anything).Maybe()
+
+	allowlist, err := NewAllowlist([]string{"10.0.0.0/8"}, nil)
+	require.NoError(t, err)
+
+	middleware := NewRateLimiterMiddleware(mockService, mockLogger, WithPreChecks(allowlist.PreCheck()))
+	router := setupTestRouter(middleware)
+
+	// Act
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("X-Forwarded-For", "10.1.2.3")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("X-RateLimit-Limit"))
+	mockService.AssertNotCalled(t, "CheckLimit", mock.Anything, mock.Anything, mock.Anything)
+}
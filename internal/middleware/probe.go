@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProbeFilter reconhece health checks de infraestrutura (load balancers, kubelet)
+// Probes não consomem cota nem aparecem no access log
+type ProbeFilter struct {
+	userAgents []string
+	sources    ipSet
+}
+
+// NewProbeFilter cria o filtro a partir de trechos de user-agent e faixas de origem
+// Com os dois configurados, a requisição precisa corresponder a ambos
+func NewProbeFilter(userAgents, sourceRanges []string) (*ProbeFilter, error) {
+	sources, err := newIPSet(sourceRanges, "probe source")
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &ProbeFilter{sources: sources}
+	for _, pattern := range userAgents {
+		if pattern = strings.TrimSpace(pattern); pattern != "" {
+			filter.userAgents = append(filter.userAgents, strings.ToLower(pattern))
+		}
+	}
+	return filter, nil
+}
+
+// IsEmpty informa se o filtro não possui critérios
+func (p *ProbeFilter) IsEmpty() bool {
+	return len(p.userAgents) == 0 && p.sources.isEmpty()
+}
+
+// IsProbe verifica se a requisição é um health check de infraestrutura
+// A origem é o peer da conexão (RemoteAddr), não headers de proxy que o cliente controla
+func (p *ProbeFilter) IsProbe(r *http.Request) bool {
+	if p.IsEmpty() {
+		return false
+	}
+
+	if len(p.userAgents) > 0 && !p.matchesUserAgent(r.UserAgent()) {
+		return false
+	}
+
+	if !p.sources.isEmpty() {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		if !p.sources.contains(host) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchesUserAgent verifica se o user-agent contém algum dos trechos (sem diferenciar maiúsculas)
+func (p *ProbeFilter) matchesUserAgent(userAgent string) bool {
+	userAgent = strings.ToLower(userAgent)
+	for _, pattern := range p.userAgents {
+		if strings.Contains(userAgent, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// PreCheck retorna o estágio que dispensa probes do rate limiter
+func (p *ProbeFilter) PreCheck() PreCheck {
+	return func(c *gin.Context, clientIP, apiToken string) bool {
+		return p.IsProbe(c.Request)
+	}
+}
+
+// SkipLogging envolve o middleware de access log para ignorar probes
+func (p *ProbeFilter) SkipLogging(logger gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if p.IsProbe(c.Request) {
+			c.Next()
+			return
+		}
+		logger(c)
+	}
+}
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestProbeFilter_IsProbe testa a correspondência por user-agent e origem
+func TestProbeFilter_IsProbe(t *testing.T) {
+	tests := []struct {
+		name       string
+		userAgents []string
+		sources    []string
+		userAgent  string
+		remoteAddr string
+		expected   bool
+	}{
+		{name: "Should match user-agent ignoring case", userAgents: []string{"ELB-HealthChecker"}, userAgent: "elb-healthchecker/2.0", remoteAddr: "203.0.113.1:1234", expected: true},
+		{name: "Should not match other user-agent", userAgents: []string{"ELB-HealthChecker"}, userAgent: "curl/8.0", remoteAddr: "203.0.113.1:1234", expected: false},
+		{name: "Should match source range", sources: []string{"10.0.0.0/8"}, userAgent: "curl/8.0", remoteAddr: "10.1.2.3:1234", expected: true},
+		{name: "Should require both when both configured", userAgents: []string{"kube-probe"}, sources: []string{"10.0.0.0/8"}, userAgent: "kube-probe/1.29", remoteAddr: "203.0.113.1:1234", expected: false},
+		{name: "Should match both when both configured", userAgents: []string{"kube-probe"}, sources: []string{"10.0.0.0/8"}, userAgent: "kube-probe/1.29", remoteAddr: "10.1.2.3:1234", expected: true},
+		{name: "Should never match when empty", userAgent: "ELB-HealthChecker/2.0", remoteAddr: "10.1.2.3:1234", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := NewProbeFilter(tt.userAgents, tt.sources)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("User-Agent", tt.userAgent)
+			req.RemoteAddr = tt.remoteAddr
+
+			assert.Equal(t, tt.expected, filter.IsProbe(req))
+		})
+	}
+}
+
+// TestProbeFilter_IgnoresForwardedFor testa que a origem vem da conexão, não de headers
+func TestProbeFilter_IgnoresForwardedFor(t *testing.T) {
+	filter, err := NewProbeFilter(nil, []string{"10.0.0.0/8"})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "203.0.113.1:1234"
+	req.Header.Set("X-Forwarded-For", "10.1.2.3")
+
+	assert.False(t, filter.IsProbe(req))
+}
+
+// TestNewProbeFilter_InvalidSource testa a validação das faixas de origem
+func TestNewProbeFilter_InvalidSource(t *testing.T) {
+	_, err := NewProbeFilter(nil, []string{"10.0.0.0/33"})
+	assert.Error(t, err)
+}
+
+// TestProbeFilter_SkipsRateLimiterAndLogging testa que probes não consomem cota nem geram access log
+func TestProbeFilter_SkipsRateLimiterAndLogging(t *testing.T) {
+	mockService := new(MockRateLimiterService)
+	mockLogger := new(MockLogger)
+	mockLogger.On("Debug", mock.AnythingOfType("string"), mock.Anything).Maybe()
+
+	filter, err := NewProbeFilter([]string{"ELB-HealthChecker"}, nil)
+	require.NoError(t, err)
+
+	logged := 0
+	accessLog := func(c *gin.Context) {
+		c.Next()
+		logged++
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(filter.SkipLogging(accessLog))
+	router.Use(NewRateLimiterMiddleware(mockService, mockLogger, WithPreChecks(filter.PreCheck())))
+	router.GET("/test", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("User-Agent", "ELB-HealthChecker/2.0")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 0, logged)
+	mockService.AssertNotCalled(t, "CheckLimit", mock.Anything, mock.Anything, mock.Anything)
+}
@@ -2,6 +2,9 @@ package middleware
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
 	"net"
 	"net/http"
 	"strconv"
@@ -9,26 +12,118 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/uuid"
 
 	"rate-limiter/internal/domain"
+	"rate-limiter/internal/logger"
 )
 
 // RateLimiterMiddleware implementa o middleware de rate limiting
 // Injetável no servidor web conforme requisito fc_rate_limiter
 type RateLimiterMiddleware struct {
-	service domain.RateLimiterService
-	logger  domain.Logger
+	service        domain.RateLimiterService
+	logger         domain.Logger
+	preChecks      []PreCheck
+	failureMode    FailureMode
+	timeout        time.Duration
+	counters       *Counters
+	extractKeys    KeyExtractor
+	headers        bool
+	now            func() time.Time
+	rejectionCache RejectionCache
+	serializer     ResponseSerializer
+	tarpit         *tarpitState // nil = desabilitado
+	challenge      *Challenge   // nil = desabilitado
+}
+
+// resultContextKey guarda a decisão do rate limiter no contexto do Gin
+const resultContextKey = "rate_limit_result"
+
+// decisionContextKey guarda o desfecho do rate limiter (ver GetDecision)
+const decisionContextKey = "rate_limit_decision"
+
+// Desfechos do rate limiter para a requisição, retornados por GetDecision
+const (
+	DecisionAllowed   = "allowed"
+	DecisionThrottled = "throttled"
+	DecisionBypassed  = "bypassed" // pré-verificação (allowlist, probes)
+	DecisionDegraded  = "degraded" // fail-open: o rate limiter não decidiu
+
+	DecisionChallengePassed = "challenge_passed" // recusada, mas liberada pelo comprovante do desafio
+)
+
+// DefaultDecisionTimeout limita a espera pela decisão do service; ao expirar,
+// vale a política de FailureMode
+const DefaultDecisionTimeout = 50 * time.Millisecond
+
+// DefaultBlockMessage é a mensagem do 429 quando a regra não define uma própria
+const DefaultBlockMessage = "you have reached the maximum number of requests or actions allowed within a certain time frame"
+
+// FailureMode define o comportamento quando o rate limiter não consegue decidir
+type FailureMode string
+
+const (
+	// FailClosed rejeita a requisição quando o rate limiter falha (padrão)
+	FailClosed FailureMode = "closed"
+	// FailOpen deixa a requisição passar quando o rate limiter falha
+	FailOpen FailureMode = "open"
+)
+
+// Config agrupa as configurações opcionais do middleware (ver WithConfig)
+type Config struct {
+	// PreChecks são avaliados em ordem antes de qualquer acesso ao storage
+	PreChecks []PreCheck
+
+	// FailureMode define a política quando o service retorna erro ou excede o timeout
+	FailureMode FailureMode
+
+	// Timeout limita a espera pela decisão do service (0 = DefaultDecisionTimeout)
+	Timeout time.Duration
+
+	// Counters recebe as falhas de decisão por motivo e desfecho (opcional, para métricas)
+	Counters *Counters
+
+	// RejectionCache marca o 429 como cacheável por CDNs (TTL zero = desabilitado)
+	RejectionCache RejectionCache
+
+	// Serializer gera os corpos de erro do middleware e dos handlers (nil = JSONSerializer)
+	Serializer ResponseSerializer
+
+	// Tarpit atrasa as respostas de clientes suspeitos (MaxDelay zero = desabilitado)
+	Tarpit Tarpit
+
+	// Challenge troca a recusa por um desafio como um CAPTCHA (URL vazia = desabilitado)
+	Challenge Challenge
 }
 
 // NewRateLimiterMiddleware cria uma nova instância do middleware
+// Sem opções: fail-closed, DefaultDecisionTimeout, DefaultKeyExtractor e headers habilitados
 func NewRateLimiterMiddleware(
 	service domain.RateLimiterService,
 	logger domain.Logger,
+	opts ...Option,
 ) gin.HandlerFunc {
 	middleware := &RateLimiterMiddleware{
-		service: service,
-		logger:  logger,
+		service:     service,
+		logger:      logger,
+		failureMode: FailClosed,
+		timeout:     DefaultDecisionTimeout,
+		extractKeys: DefaultKeyExtractor,
+		headers:     true,
+		now:         time.Now,
+		serializer:  JSONSerializer{},
+	}
+	for _, opt := range opts {
+		opt(middleware)
+	}
+
+	if middleware.failureMode == "" {
+		middleware.failureMode = FailClosed
+	}
+	if middleware.timeout <= 0 {
+		middleware.timeout = DefaultDecisionTimeout
+	}
+	if middleware.serializer == nil {
+		middleware.serializer = JSONSerializer{}
 	}
 	
 	return middleware.Handle
@@ -36,55 +131,114 @@ func NewRateLimiterMiddleware(
 
 // Handle é o handler principal do middleware
 func (m *RateLimiterMiddleware) Handle(c *gin.Context) {
-	// Criar contexto com timeout para operações
-	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	// Extrair IP e Token da requisição
+	clientIP, apiToken := m.extractKeys(c)
+
+	// Pré-verificações (ex.: allowlist) dispensam o acesso ao storage
+	for _, preCheck := range m.preChecks {
+		if preCheck(c, clientIP, apiToken) {
+			m.logger.Debug("Request bypassed rate limiter by pre-check", map[string]interface{}{
+				"client_ip": clientIP,
+				"api_token": m.maskToken(apiToken),
+				"path":      c.Request.URL.Path,
+			})
+			c.Set(decisionContextKey, DecisionBypassed)
+			c.Next()
+			return
+		}
+	}
+
+	// Trace do cliente: a verificação e os handlers entram no trace existente
+	continueTrace(c)
+
+	// Criar contexto com timeout para a decisão
+	ctx, cancel := context.WithTimeout(c.Request.Context(), m.timeout)
 	defer cancel()
 
 	// Gerar Request ID se não existir
-	requestID := m.getRequestID(c)
+	requestID := ensureRequestID(c)
 	
 	// Adicionar informações ao contexto
-	ctx = m.enrichContext(ctx, c, requestID)
+	ctx = logger.ContextWithRequestInfo(ctx, requestID, clientIP, apiToken, c.GetHeader("User-Agent"))
 	
 	// Obter logger com contexto
-	logger := m.logger.WithContext(ctx)
-
-	// Extrair IP e Token da requisição
-	clientIP := m.extractClientIP(c)
-	apiToken := m.extractAPIToken(c)
-
-	logger.Debug("Rate limiter middleware initiated", map[string]interface{}{
-		"client_ip":   clientIP,
-		"api_token":   m.maskToken(apiToken),
-		"user_agent":  c.GetHeader("User-Agent"),
-		"method":      c.Request.Method,
-		"path":        c.Request.URL.Path,
-		"request_id":  requestID,
-	})
+	log := m.logger.WithContext(ctx)
+
+	// Campos de debug só são montados quando registrados (caminho quente)
+	debug := domain.DebugEnabled(log)
+	if debug {
+		log.Debug("Rate limiter middleware initiated", map[string]interface{}{
+			"client_ip":   clientIP,
+			"api_token":   m.maskToken(apiToken),
+			"user_agent":  c.GetHeader("User-Agent"),
+			"method":      c.Request.Method,
+			"path":        c.Request.URL.Path,
+			"request_id":  requestID,
+		})
+	}
 
 	// Verificar rate limit usando o service
-	result, err := m.service.CheckLimit(ctx, clientIP, apiToken)
+	spanCtx, span := startCheckSpan(ctx, c)
+	result, err := m.service.CheckLimit(spanCtx, clientIP, apiToken)
+	endCheckSpan(span, result, err)
 	if err != nil {
-		logger.Error("Rate limiter service error", err, map[string]interface{}{
-			"client_ip":  clientIP,
-			"api_token":  m.maskToken(apiToken),
-			"request_id": requestID,
+		// Timeout da decisão, e não cancelamento pelo cliente
+		timedOut := errors.Is(ctx.Err(), context.DeadlineExceeded) && c.Request.Context().Err() == nil
+		m.counters.recordFailure(timedOut, m.failureMode == FailOpen)
+
+		log.Error("Rate limiter service error", err, map[string]interface{}{
+			"client_ip":    clientIP,
+			"api_token":    m.maskToken(apiToken),
+			"request_id":   requestID,
+			"failure_mode": m.failureMode,
+			"timed_out":    timedOut,
 		})
+
+		// Fail-open: a requisição segue sem rate limiting
+		if m.failureMode == FailOpen {
+			c.Header("X-RateLimit-Status", "degraded")
+			c.Set(decisionContextKey, DecisionDegraded)
+			c.Next()
+			return
+		}
+
+		// Timeout ou storage degradado: indisponibilidade temporária
+		if timedOut {
+			abortWithResponse(c, m.serializer, &ErrorResponse{
+				Status:    http.StatusServiceUnavailable,
+				Error:     "service_unavailable",
+				Message:   "Rate limiter decision timed out",
+				RequestID: requestID,
+			})
+			return
+		}
+		if errors.Is(err, domain.ErrStorageUnavailable) {
+			abortWithResponse(c, m.serializer, &ErrorResponse{
+				Status:    http.StatusServiceUnavailable,
+				Error:     "service_unavailable",
+				Message:   "Rate limiter storage is temporarily unavailable",
+				RequestID: requestID,
+			})
+			return
+		}
 		
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal server error",
-			"message": "Unable to process rate limit check",
+		abortWithResponse(c, m.serializer, &ErrorResponse{
+			Status:    http.StatusInternalServerError,
+			Error:     "internal server error",
+			Message:   "Unable to process rate limit check",
+			RequestID: requestID,
 		})
-		c.Abort()
 		return
 	}
 
-	// Adicionar headers de rate limiting sempre
+	// Adicionar headers de rate limiting (exceto com WithHeaders(false))
 	m.setRateLimitHeaders(c, result)
+	c.Set(resultContextKey, result)
 
 	// Verificar se a requisição foi permitida
 	if !result.Allowed {
-		logger.Info("Request rate limited", map[string]interface{}{
+		c.Set(decisionContextKey, DecisionThrottled)
+		log.Info("Request rate limited", map[string]interface{}{
 			"client_ip":     clientIP,
 			"api_token":     m.maskToken(apiToken),
 			"limiter_type":  result.LimiterType,
@@ -95,53 +249,130 @@ func (m *RateLimiterMiddleware) Handle(c *gin.Context) {
 		})
 
 		// Resposta HTTP 429 conforme fc_rate_limiter
-		response := gin.H{
-			"error":   "rate_limit_exceeded",
-			"message": "you have reached the maximum number of requests or actions allowed within a certain time frame",
-			"details": gin.H{
-				"limit":       result.Limit,
-				"remaining":   result.Remaining,
-				"reset_time":  result.ResetTime.Unix(),
-				"limiter_type": result.LimiterType,
+		// A regra pode substituir a mensagem padrão e apontar uma página de upgrade/documentação
+		message := result.Message
+		if message == "" {
+			message = DefaultBlockMessage
+		}
+		response := &ErrorResponse{
+			Status:  http.StatusTooManyRequests,
+			Error:   "rate_limit_exceeded",
+			Message: message,
+			Details: &RateLimitDetails{
+				Limit:       result.Limit,
+				Remaining:   result.Remaining,
+				ResetTime:   result.ResetTime.Unix(),
+				LimiterType: result.LimiterType,
+				Scope:       result.Scope,
 			},
+			// Permite ao cliente citar a decisão exata ao contestar um bloqueio
+			RequestID: requestID,
 		}
 
 		// Adicionar blocked_until se presente
 		if result.BlockedUntil != nil {
-			response["details"].(gin.H)["blocked_until"] = result.BlockedUntil.Unix()
+			response.Details.BlockedUntil = result.BlockedUntil.Unix()
 		}
 
-		c.JSON(http.StatusTooManyRequests, response)
-		c.Abort()
+		if result.DocsURL != "" {
+			response.DocsURL = result.DocsURL
+			c.Header("Link", fmt.Sprintf("<%s>; rel=\"help\"", result.DocsURL))
+		}
+
+		// Desafio: o comprovante libera o cliente; sem ele, o desafio substitui o 429
+		if m.challenge != nil {
+			if !m.applyChallenge(c, clientIP, response) {
+				return
+			}
+			log.Info("Challenge pass lifted rate limit", map[string]interface{}{
+				"client_ip":    clientIP,
+				"api_token":    m.maskToken(apiToken),
+				"limiter_type": result.LimiterType,
+				"request_id":   requestID,
+			})
+			c.Set(decisionContextKey, DecisionChallengePassed)
+			c.Next()
+			return
+		}
+
+		m.setRejectionCacheHeaders(c, result)
+		if !m.applyTarpit(c, result) {
+			c.AbortWithStatus(statusClientClosedRequest)
+			return
+		}
+		abortWithResponse(c, m.serializer, response)
 		return
 	}
 
 	// Requisição permitida - continuar pipeline
-	logger.Debug("Request allowed by rate limiter", map[string]interface{}{
-		"client_ip":    clientIP,
-		"api_token":    m.maskToken(apiToken),
-		"limiter_type": result.LimiterType,
-		"limit":        result.Limit,
-		"remaining":    result.Remaining,
-		"request_id":   requestID,
-	})
+	if debug {
+		log.Debug("Request allowed by rate limiter", map[string]interface{}{
+			"client_ip":    clientIP,
+			"api_token":    m.maskToken(apiToken),
+			"limiter_type": result.LimiterType,
+			"limit":        result.Limit,
+			"remaining":    result.Remaining,
+			"request_id":   requestID,
+		})
+	}
+
+	// Leaky bucket: a requisição segue apenas quando sai da fila
+	if result.ReleaseAt != nil && !m.waitRelease(c, *result.ReleaseAt) {
+		log.Debug("Client gave up while queued in leaky bucket", map[string]interface{}{
+			"client_ip":  clientIP,
+			"api_token":  m.maskToken(apiToken),
+			"request_id": requestID,
+		})
+		c.AbortWithStatus(statusClientClosedRequest)
+		return
+	}
 
+	// Faixa suspeita: a requisição segue, mas só depois do atraso do tarpit
+	if result.ReleaseAt == nil && !m.applyTarpit(c, result) {
+		c.AbortWithStatus(statusClientClosedRequest)
+		return
+	}
+
+	c.Set(decisionContextKey, DecisionAllowed)
 	c.Next()
 }
 
+// statusClientClosedRequest registra no access log a requisição abandonada pelo
+// cliente antes da resposta (convenção do nginx)
+const statusClientClosedRequest = 499
+
+// waitRelease aguarda até releaseAt; retorna false se o cliente desistir antes
+func (m *RateLimiterMiddleware) waitRelease(c *gin.Context, releaseAt time.Time) bool {
+	return m.wait(c, releaseAt.Sub(m.now()))
+}
+
+// wait segura a requisição por wait; retorna false se o cliente desistir antes
+func (m *RateLimiterMiddleware) wait(c *gin.Context, wait time.Duration) bool {
+	if wait <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-c.Request.Context().Done():
+		return false
+	}
+}
+
 // extractClientIP extrai o IP do cliente considerando proxies e load balancers
 func (m *RateLimiterMiddleware) extractClientIP(c *gin.Context) string {
 	// Prioridade: X-Forwarded-For > X-Real-IP > RemoteAddr
 	
 	// X-Forwarded-For pode conter múltiplos IPs separados por vírgula
 	// O primeiro é o IP original do cliente
-	if xff := c.GetHeader("X-Forwarded-For"); xff != "" {
-		ips := strings.Split(xff, ",")
-		if len(ips) > 0 {
-			clientIP := strings.TrimSpace(ips[0])
-			if clientIP != "" {
-				return clientIP
-			}
+	if xff := requestHeader(c, headerForwardedFor); xff != "" {
+		first, _, _ := strings.Cut(xff, ",")
+		if clientIP := strings.TrimSpace(first); clientIP != "" {
+			return clientIP
 		}
 	}
 
@@ -162,15 +393,15 @@ func (m *RateLimiterMiddleware) extractClientIP(c *gin.Context) string {
 // extractAPIToken extrai o token de API dos headers
 func (m *RateLimiterMiddleware) extractAPIToken(c *gin.Context) string {
     // Prioridade: API_KEY (especificação) > X-Api-Token > Api-Token
-    if token := c.GetHeader("API_KEY"); token != "" {
+    if token := requestHeader(c, headerAPIKey); token != "" {
         return strings.TrimSpace(token)
     }
 
-    if token := c.GetHeader("X-Api-Token"); token != "" {
+    if token := requestHeader(c, headerXAPIToken); token != "" {
         return strings.TrimSpace(token)
     }
 
-    if token := c.GetHeader("Api-Token"); token != "" {
+    if token := requestHeader(c, headerAPIToken); token != "" {
         return strings.TrimSpace(token)
     }
 
@@ -179,53 +410,39 @@ func (m *RateLimiterMiddleware) extractAPIToken(c *gin.Context) string {
 
 // setRateLimitHeaders define headers informativos de rate limiting
 func (m *RateLimiterMiddleware) setRateLimitHeaders(c *gin.Context, result *domain.RateLimitResult) {
-	c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
-	c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
-	c.Header("X-RateLimit-Reset", strconv.FormatInt(result.ResetTime.Unix(), 10))
-	c.Header("X-RateLimit-Type", string(result.LimiterType))
+	if m.headers {
+		// Headers extras do token (ex: X-Plan); os de rate limit são escritos depois e prevalecem
+		for name, value := range result.Headers {
+			c.Header(name, value)
+		}
 
-	// Adicionar Retry-After para requisições bloqueadas
+		setHeader(c, headerLimit, strconv.Itoa(result.Limit))
+		setHeader(c, headerRemaining, strconv.Itoa(result.Remaining))
+		setHeader(c, headerReset, strconv.FormatInt(result.ResetTime.Unix(), 10))
+		setHeader(c, headerLimiterType, string(result.LimiterType))
+		if result.Scope != "" {
+			setHeader(c, headerScope, result.Scope)
+		}
+	}
+
+	// Adicionar Retry-After para requisições bloqueadas (arredondado para cima:
+	// no token bucket a próxima ficha pode chegar em menos de um segundo)
 	if !result.Allowed && result.BlockedUntil != nil {
-		retryAfter := int(time.Until(*result.BlockedUntil).Seconds())
+		retryAfter := int(math.Ceil(result.BlockedUntil.Sub(m.now()).Seconds()))
 		if retryAfter > 0 {
-			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			setHeader(c, headerRetryAfter, strconv.Itoa(retryAfter))
 		}
 	}
 }
 
-// getRequestID obtém ou gera um Request ID para tracking
-func (m *RateLimiterMiddleware) getRequestID(c *gin.Context) string {
-	// Verifica se já existe no header
-	if requestID := c.GetHeader("X-Request-ID"); requestID != "" {
-		return requestID
+// abortWithJSON encerra a requisição com o corpo JSON
+// Em HEAD a decisão vai apenas no status e nos headers, sem corpo
+func abortWithJSON(c *gin.Context, status int, body gin.H) {
+	if c.Request.Method == http.MethodHead {
+		c.AbortWithStatus(status)
+		return
 	}
-
-	// Gera novo UUID
-	requestID := uuid.New().String()
-	c.Header("X-Request-ID", requestID)
-	return requestID
-}
-
-// enrichContext adiciona informações relevantes ao contexto
-func (m *RateLimiterMiddleware) enrichContext(ctx context.Context, c *gin.Context, requestID string) context.Context {
-	// Adiciona informações da requisição ao contexto para logging
-	type contextKey string
-	
-	const (
-		requestIDKey contextKey = "request_id"
-		clientIPKey  contextKey = "client_ip"
-		userAgentKey contextKey = "user_agent"
-		methodKey    contextKey = "method"
-		pathKey      contextKey = "path"
-	)
-
-	ctx = context.WithValue(ctx, requestIDKey, requestID)
-	ctx = context.WithValue(ctx, clientIPKey, m.extractClientIP(c))
-	ctx = context.WithValue(ctx, userAgentKey, c.GetHeader("User-Agent"))
-	ctx = context.WithValue(ctx, methodKey, c.Request.Method)
-	ctx = context.WithValue(ctx, pathKey, c.Request.URL.Path)
-
-	return ctx
+	c.AbortWithStatusJSON(status, body)
 }
 
 // maskToken mascara o token para logs de segurança
@@ -241,6 +458,23 @@ func (m *RateLimiterMiddleware) maskToken(token string) string {
 	return token[:8] + "***"
 }
 
+// GetRateLimitResult retorna a decisão do rate limiter para a requisição
+// ok=false quando o rate limiter não decidiu (pré-verificação ou fail-open)
+func GetRateLimitResult(c *gin.Context) (*domain.RateLimitResult, bool) {
+	if value, exists := c.Get(resultContextKey); exists {
+		if result, ok := value.(*domain.RateLimitResult); ok {
+			return result, true
+		}
+	}
+	return nil, false
+}
+
+// GetDecision retorna o desfecho do rate limiter para a requisição
+// Vazio quando a requisição não passou pelo middleware
+func GetDecision(c *gin.Context) string {
+	return c.GetString(decisionContextKey)
+}
+
 // GetClientIP é uma função utilitária exportada para uso externo
 func GetClientIP(c *gin.Context) string {
 	middleware := &RateLimiterMiddleware{}
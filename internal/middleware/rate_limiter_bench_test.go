@@ -0,0 +1,104 @@
+package middleware
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/logger"
+)
+
+// Benchmarks do middleware isolado do storage, com o logger de produção em
+// nível info. Compare versões com:
+//
+//	go test -run '^$' -bench RateLimiterMiddleware -benchmem ./internal/middleware > new.txt
+//	benchstat old.txt new.txt
+
+// fixedDecisionService devolve sempre a mesma decisão, sem acessar storage
+type fixedDecisionService struct {
+	domain.RateLimiterService
+	result *domain.RateLimitResult
+}
+
+func (s *fixedDecisionService) CheckLimit(ctx context.Context, ip, token string) (*domain.RateLimitResult, error) {
+	return s.result, nil
+}
+
+// newDecisionRequest monta o router com a decisão fixa e retorna a função que
+// executa uma requisição reaproveitando o mesmo recorder
+func newDecisionRequest(result *domain.RateLimitResult) func() {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(NewRateLimiterMiddleware(&fixedDecisionService{result: result}, logger.NewLoggerWithOutput("info", "json", io.Discard)))
+	router.GET("/test", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("X-Forwarded-For", "10.0.0.1")
+	req.Header.Set(RequestIDHeader, "bench-request")
+	w := httptest.NewRecorder()
+
+	return func() {
+		for name := range w.Header() {
+			delete(w.Header(), name)
+		}
+		w.Body.Reset()
+		router.ServeHTTP(w, req)
+	}
+}
+
+func benchmarkMiddleware(b *testing.B, result *domain.RateLimitResult) {
+	serve := newDecisionRequest(result)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		serve()
+	}
+}
+
+// BenchmarkRateLimiterMiddleware_Allowed mede o caminho da requisição permitida
+func BenchmarkRateLimiterMiddleware_Allowed(b *testing.B) {
+	benchmarkMiddleware(b, &domain.RateLimitResult{
+		Allowed:     true,
+		Limit:       100,
+		Remaining:   99,
+		ResetTime:   time.Now().Add(time.Minute),
+		LimiterType: domain.IPLimiter,
+	})
+}
+
+// BenchmarkRateLimiterMiddleware_Throttled mede o caminho do 429
+func BenchmarkRateLimiterMiddleware_Throttled(b *testing.B) {
+	blockedUntil := time.Now().Add(time.Minute)
+	benchmarkMiddleware(b, &domain.RateLimitResult{
+		Allowed:      false,
+		Limit:        100,
+		ResetTime:    blockedUntil,
+		BlockedUntil: &blockedUntil,
+		LimiterType:  domain.IPLimiter,
+	})
+}
+
+// TestRateLimiterMiddleware_AllowedAllocations protege a requisição permitida
+// contra regressões de alocação. As restantes são do gin, do timeout da decisão,
+// do contexto de log, do span da verificação (mesmo sem exporter) e dos headers X-RateLimit-*
+func TestRateLimiterMiddleware_AllowedAllocations(t *testing.T) {
+	serve := newDecisionRequest(&domain.RateLimitResult{
+		Allowed:     true,
+		Limit:       100,
+		Remaining:   99,
+		ResetTime:   time.Now().Add(time.Minute),
+		LimiterType: domain.IPLimiter,
+	})
+
+	allocs := testing.AllocsPerRun(200, serve)
+
+	assert.LessOrEqual(t, allocs, float64(20))
+}
@@ -32,9 +32,12 @@ func (m *MockRateLimiterService) IsAllowed(ctx context.Context, key string, limi
 	return args.Bool(0), args.Error(1)
 }
 
-func (m *MockRateLimiterService) GetConfig(key string, limiterType domain.LimiterType) *domain.RateLimitRule {
-	args := m.Called(key, limiterType)
-	return args.Get(0).(*domain.RateLimitRule)
+func (m *MockRateLimiterService) GetConfig(ctx context.Context, key string, limiterType domain.LimiterType) (*domain.RateLimitRule, error) {
+	args := m.Called(ctx, key, limiterType)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RateLimitRule), args.Error(1)
 }
 
 func (m *MockRateLimiterService) GetStatus(ctx context.Context, key string, limiterType domain.LimiterType) (*domain.RateLimitStatus, error) {
@@ -50,6 +53,66 @@ func (m *MockRateLimiterService) Reset(ctx context.Context, key string, limiterT
 	return args.Error(0)
 }
 
+func (m *MockRateLimiterService) SetOverride(ctx context.Context, override domain.LimitOverride) error {
+	args := m.Called(ctx, override)
+	return args.Error(0)
+}
+
+func (m *MockRateLimiterService) InspectKey(ctx context.Context, key string, limiterType domain.LimiterType) (*domain.StorageRecord, error) {
+	args := m.Called(ctx, key, limiterType)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.StorageRecord), args.Error(1)
+}
+
+func (m *MockRateLimiterService) WindowHistory(ctx context.Context, key string, limiterType domain.LimiterType) ([]domain.WindowCount, error) {
+	args := m.Called(ctx, key, limiterType)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.WindowCount), args.Error(1)
+}
+
+func (m *MockRateLimiterService) ListKeys(ctx context.Context, limiterType domain.LimiterType, cursor string, count int) ([]domain.TrackedKey, string, error) {
+	args := m.Called(ctx, limiterType, cursor, count)
+	if args.Get(0) == nil {
+		return nil, args.String(1), args.Error(2)
+	}
+	return args.Get(0).([]domain.TrackedKey), args.String(1), args.Error(2)
+}
+
+func (m *MockRateLimiterService) BlockKey(ctx context.Context, key string, limiterType domain.LimiterType, duration time.Duration) (time.Time, error) {
+	args := m.Called(ctx, key, limiterType, duration)
+	return args.Get(0).(time.Time), args.Error(1)
+}
+
+func (m *MockRateLimiterService) ResetPattern(ctx context.Context, pattern string, limiterType domain.LimiterType) (int, error) {
+	args := m.Called(ctx, pattern, limiterType)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRateLimiterService) ExportState(ctx context.Context) ([]domain.StateEntry, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.StateEntry), args.Error(1)
+}
+
+func (m *MockRateLimiterService) ImportState(ctx context.Context, entries []domain.StateEntry) (int, error) {
+	args := m.Called(ctx, entries)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRateLimiterService) ClientLimits(ctx context.Context, token string) ([]domain.ClientLimit, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.ClientLimit), args.Error(1)
+}
+
 // MockLogger é um mock do Logger para testes
 type MockLogger struct {
 	mock.Mock
@@ -152,6 +215,7 @@ func TestRateLimiterMiddleware_BlockedRequest(t *testing.T) {
 	// Act
 	req := httptest.NewRequest("GET", "/test", nil)
 	req.Header.Set("X-Forwarded-For", "192.168.1.100")
+	req.Header.Set("X-Request-ID", "req-blocked-123")
 
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
@@ -161,6 +225,82 @@ func TestRateLimiterMiddleware_BlockedRequest(t *testing.T) {
 	assert.Contains(t, w.Body.String(), "you have reached the maximum number of requests or actions allowed within a certain time frame")
 	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
 	assert.NotEmpty(t, w.Header().Get("X-RateLimit-Reset"))
+	assert.Equal(t, "req-blocked-123", w.Header().Get("X-Request-ID"))
+	assert.Contains(t, w.Body.String(), `"request_id":"req-blocked-123"`)
+
+	mockService.AssertExpectations(t)
+}
+
+// TestRateLimiterMiddleware_BlockedRequest_RuleMessage testa mensagem e documentação da regra no 429
+func TestRateLimiterMiddleware_BlockedRequest_RuleMessage(t *testing.T) {
+	mockService := new(MockRateLimiterService)
+	mockLogger := new(MockLogger)
+
+	middleware := NewRateLimiterMiddleware(mockService, mockLogger)
+	router := setupTestRouter(middleware)
+
+	result := &domain.RateLimitResult{
+		Allowed:     false,
+		Limit:       100,
+		Remaining:   0,
+		ResetTime:   time.Now().Add(time.Minute),
+		LimiterType: domain.TokenLimiter,
+		Message:     "Search API quota exceeded, upgrade your plan",
+		DocsURL:     "https://example.com/search/pricing",
+	}
+
+	mockService.On("CheckLimit", mock.Anything, "192.168.1.100", "search-token").Return(result, nil)
+	mockLogger.On("WithContext", mock.Anything).Return(mockLogger)
+	mockLogger.On("Debug", mock.AnythingOfType("string"), mock.Anything).Maybe()
+	mockLogger.On("Info", mock.AnythingOfType("string"), mock.Anything).Maybe()
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("X-Forwarded-For", "192.168.1.100")
+	req.Header.Set("API_KEY", "search-token")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Contains(t, w.Body.String(), `"message":"Search API quota exceeded, upgrade your plan"`)
+	assert.Contains(t, w.Body.String(), `"docs_url":"https://example.com/search/pricing"`)
+	assert.NotContains(t, w.Body.String(), DefaultBlockMessage)
+	assert.Equal(t, `<https://example.com/search/pricing>; rel="help"`, w.Header().Get("Link"))
+
+	mockService.AssertExpectations(t)
+}
+
+// TestRateLimiterMiddleware_TokenHeaders testa os headers extras do token nas respostas
+func TestRateLimiterMiddleware_TokenHeaders(t *testing.T) {
+	mockService := new(MockRateLimiterService)
+	mockLogger := new(MockLogger)
+
+	middleware := NewRateLimiterMiddleware(mockService, mockLogger)
+	router := setupTestRouter(middleware)
+
+	headers := map[string]string{"X-Plan": "enterprise", "X-Quota-Policy": "https://example.com/quota"}
+	allowed := &domain.RateLimitResult{Allowed: true, Limit: 100, Remaining: 99, ResetTime: time.Now().Add(time.Minute), LimiterType: domain.TokenLimiter, Headers: headers}
+	denied := &domain.RateLimitResult{Allowed: false, Limit: 100, Remaining: 0, ResetTime: time.Now().Add(time.Minute), LimiterType: domain.TokenLimiter, Headers: headers}
+
+	mockService.On("CheckLimit", mock.Anything, "192.168.1.100", "enterprise-token").Return(allowed, nil).Once()
+	mockService.On("CheckLimit", mock.Anything, "192.168.1.100", "enterprise-token").Return(denied, nil).Once()
+	mockLogger.On("WithContext", mock.Anything).Return(mockLogger)
+	mockLogger.On("Debug", mock.AnythingOfType("string"), mock.Anything).Maybe()
+	mockLogger.On("Info", mock.AnythingOfType("string"), mock.Anything).Maybe()
+
+	for _, expectedStatus := range []int{http.StatusOK, http.StatusTooManyRequests} {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("X-Forwarded-For", "192.168.1.100")
+		req.Header.Set("API_KEY", "enterprise-token")
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, expectedStatus, w.Code)
+		assert.Equal(t, "enterprise", w.Header().Get("X-Plan"))
+		assert.Equal(t, "https://example.com/quota", w.Header().Get("X-Quota-Policy"))
+		assert.Equal(t, "100", w.Header().Get("X-RateLimit-Limit"))
+	}
 
 	mockService.AssertExpectations(t)
 }
@@ -336,6 +476,221 @@ func TestRateLimiterMiddleware_ServiceError(t *testing.T) {
 	mockLogger.AssertExpectations(t)
 }
 
+// TestRateLimiterMiddleware_FailureModes testa as políticas fail-open e fail-closed
+func TestRateLimiterMiddleware_FailureModes(t *testing.T) {
+	tests := []struct {
+		name           string
+		failureMode    FailureMode
+		serviceErr     error
+		expectedStatus int
+	}{
+		{
+			name:           "Fail-open should let request through",
+			failureMode:    FailOpen,
+			serviceErr:     assert.AnError,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Fail-closed should return 503 when storage is unavailable",
+			failureMode:    FailClosed,
+			serviceErr:     domain.ErrStorageUnavailable,
+			expectedStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name:           "Fail-closed should return 500 on unexpected errors",
+			failureMode:    FailClosed,
+			serviceErr:     assert.AnError,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockService := new(MockRateLimiterService)
+			mockLogger := new(MockLogger)
+
+			middleware := NewRateLimiterMiddleware(mockService, mockLogger, WithFailureMode(tt.failureMode))
+			router := setupTestRouter(middleware)
+
+			mockService.On("CheckLimit", mock.Anything, "192.168.1.1", "").Return(nil, tt.serviceErr)
+			mockLogger.On("WithContext", mock.Anything).Return(mockLogger)
+			mockLogger.On("Debug", mock.AnythingOfType("string"), mock.Anything).Maybe()
+			mockLogger.On("Error", mock.AnythingOfType("string"), tt.serviceErr, mock.Anything).Once()
+
+			// Act
+			req := httptest.NewRequest("GET", "/test", nil)
+			req.Header.Set("X-Forwarded-For", "192.168.1.1")
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.failureMode == FailOpen {
+				assert.Equal(t, "degraded", w.Header().Get("X-RateLimit-Status"))
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+// TestRateLimiterMiddleware_Timeout testa o timeout da decisão com as políticas de falha
+func TestRateLimiterMiddleware_Timeout(t *testing.T) {
+	tests := []struct {
+		name           string
+		failureMode    FailureMode
+		expectedStatus int
+		expected       FailureCount
+	}{
+		{
+			name:           "Fail-open should let request through",
+			failureMode:    FailOpen,
+			expectedStatus: http.StatusOK,
+			expected:       FailureCount{Reason: FailureReasonTimeout, Outcome: FailureOutcomeDegraded, Count: 1},
+		},
+		{
+			name:           "Fail-closed should return 503",
+			failureMode:    FailClosed,
+			expectedStatus: http.StatusServiceUnavailable,
+			expected:       FailureCount{Reason: FailureReasonTimeout, Outcome: FailureOutcomeRejected, Count: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange: o service só responde quando o contexto expira
+			mockService := new(MockRateLimiterService)
+			mockLogger := new(MockLogger)
+			counters := &Counters{}
+
+			middleware := NewRateLimiterMiddleware(mockService, mockLogger,
+				WithFailureMode(tt.failureMode),
+				WithTimeout(10*time.Millisecond),
+				WithCounters(counters),
+			)
+			router := setupTestRouter(middleware)
+
+			var deadline time.Time
+			mockService.On("CheckLimit", mock.Anything, "192.168.1.1", "").Run(func(args mock.Arguments) {
+				ctx := args.Get(0).(context.Context)
+				deadline, _ = ctx.Deadline()
+				<-ctx.Done()
+			}).Return(nil, context.DeadlineExceeded)
+			mockLogger.On("WithContext", mock.Anything).Return(mockLogger)
+			mockLogger.On("Debug", mock.AnythingOfType("string"), mock.Anything).Maybe()
+			mockLogger.On("Error", "Rate limiter service error", context.DeadlineExceeded, mock.MatchedBy(func(fields map[string]interface{}) bool {
+				return fields["timed_out"] == true
+			})).Once()
+
+			// Act
+			start := time.Now()
+			req := httptest.NewRequest("GET", "/test", nil)
+			req.Header.Set("X-Forwarded-For", "192.168.1.1")
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.WithinDuration(t, start.Add(10*time.Millisecond), deadline, 5*time.Millisecond)
+			assert.Contains(t, counters.Failures(), tt.expected)
+			if tt.failureMode == FailClosed {
+				assert.Contains(t, w.Body.String(), "Rate limiter decision timed out")
+			}
+			mockService.AssertExpectations(t)
+			mockLogger.AssertExpectations(t)
+		})
+	}
+}
+
+// TestRateLimiterMiddleware_Decision testa o desfecho exposto por GetDecision
+func TestRateLimiterMiddleware_Decision(t *testing.T) {
+	tests := []struct {
+		name     string
+		allowed  bool
+		expected string
+	}{
+		{name: "Allowed request", allowed: true, expected: DecisionAllowed},
+		{name: "Throttled request", allowed: false, expected: DecisionThrottled},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockService := new(MockRateLimiterService)
+			mockLogger := new(MockLogger)
+			mockService.On("CheckLimit", mock.Anything, "192.168.1.1", "").Return(&domain.RateLimitResult{
+				Allowed:     tt.allowed,
+				Limit:       10,
+				ResetTime:   time.Now().Add(time.Minute),
+				LimiterType: domain.IPLimiter,
+			}, nil)
+			mockLogger.On("WithContext", mock.Anything).Return(mockLogger)
+			mockLogger.On("Debug", mock.AnythingOfType("string"), mock.Anything).Maybe()
+			mockLogger.On("Info", mock.AnythingOfType("string"), mock.Anything).Maybe()
+
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			var decision string
+			router.Use(func(c *gin.Context) {
+				c.Next()
+				decision = GetDecision(c)
+			})
+			router.Use(NewRateLimiterMiddleware(mockService, mockLogger))
+			router.GET("/test", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			// Act
+			req := httptest.NewRequest("GET", "/test", nil)
+			req.Header.Set("X-Forwarded-For", "192.168.1.1")
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			// Assert
+			assert.Equal(t, tt.expected, decision)
+		})
+	}
+}
+
+func TestRateLimiterMiddleware_LeakyBucketQueue(t *testing.T) {
+	// Arrange: a primeira decisão coloca a requisição na fila por 50ms, a segunda por 1s
+	mockService := new(MockRateLimiterService)
+	mockLogger := new(MockLogger)
+	queued := func(wait time.Duration) *domain.RateLimitResult {
+		return &domain.RateLimitResult{
+			Allowed:     true,
+			Limit:       10,
+			Remaining:   8,
+			ResetTime:   time.Now().Add(2 * wait),
+			LimiterType: domain.IPLimiter,
+			ReleaseAt:   timePtr(time.Now().Add(wait)),
+		}
+	}
+	mockService.On("CheckLimit", mock.Anything, "192.168.1.1", "").Return(queued(50*time.Millisecond), nil).Once()
+	mockService.On("CheckLimit", mock.Anything, "192.168.1.1", "").Return(queued(time.Second), nil).Once()
+	mockLogger.On("WithContext", mock.Anything).Return(mockLogger)
+	mockLogger.On("Debug", mock.AnythingOfType("string"), mock.Anything).Maybe()
+
+	router := setupTestRouter(NewRateLimiterMiddleware(mockService, mockLogger))
+
+	// Act
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("X-Forwarded-For", "192.168.1.1")
+	w := httptest.NewRecorder()
+	start := time.Now()
+	router.ServeHTTP(w, req)
+	waited := time.Since(start)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	abandoned := httptest.NewRecorder()
+	router.ServeHTTP(abandoned, req.WithContext(ctx))
+
+	// Assert: a requisição só chega ao handler ao sair da fila
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.GreaterOrEqual(t, waited, 40*time.Millisecond)
+	assert.Equal(t, statusClientClosedRequest, abandoned.Code)
+}
+
 // Helper functions
 func timePtr(t time.Time) *time.Time {
 	return &t
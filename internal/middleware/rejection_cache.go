@@ -0,0 +1,58 @@
+package middleware
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"rate-limiter/internal/domain"
+)
+
+// tokenHeaders são os headers de onde DefaultKeyExtractor lê o token de API
+var tokenHeaders = []string{"API_KEY", "X-Api-Token", "Api-Token"}
+
+// RejectionCache permite que CDNs e proxies guardem o 429 por um TTL curto e
+// absorvam as novas tentativas do cliente bloqueado sem repassá-las à origem
+type RejectionCache struct {
+	// TTL máximo do 429 em cache (0 = desabilitado); nunca passa do Retry-After
+	TTL time.Duration
+
+	// Headers específicos de CDN que recebem o mesmo max-age
+	// (ex: CDN-Cache-Control, Surrogate-Control, Cloudflare-CDN-Cache-Control)
+	Headers []string
+}
+
+// Enabled informa se o 429 deve ser marcado como cacheável
+func (r RejectionCache) Enabled() bool {
+	return r.TTL > 0
+}
+
+// setRejectionCacheHeaders marca o 429 como cacheável até o fim do bloqueio ou
+// da janela, limitado ao TTL configurado. A resposta varia pelo token, então os
+// headers de token entram no Vary; a chave de cache por IP fica a cargo da CDN
+func (m *RateLimiterMiddleware) setRejectionCacheHeaders(c *gin.Context, result *domain.RateLimitResult) {
+	if !m.rejectionCache.Enabled() {
+		return
+	}
+
+	until := result.ResetTime
+	if result.BlockedUntil != nil {
+		until = *result.BlockedUntil
+	}
+	ttl := until.Sub(m.now())
+	if ttl > m.rejectionCache.TTL {
+		ttl = m.rejectionCache.TTL
+	}
+	maxAge := int(ttl / time.Second)
+	if maxAge < 1 {
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age="+strconv.Itoa(maxAge))
+	for _, name := range m.rejectionCache.Headers {
+		c.Header(name, "max-age="+strconv.Itoa(maxAge))
+	}
+	c.Writer.Header().Add("Vary", strings.Join(tokenHeaders, ", "))
+}
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"rate-limiter/internal/domain"
+)
+
+func TestRejectionCache(t *testing.T) {
+	// Arrange: TTL de 10s; 192.168.1.1 bloqueado por 30s, 192.168.1.2 por 4s
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	longBlock := now.Add(30 * time.Second)
+	shortBlock := now.Add(4 * time.Second)
+	mockService := new(MockRateLimiterService)
+	mockLogger := new(MockLogger)
+	mockService.On("CheckLimit", mock.Anything, "192.168.1.1", "").Return(&domain.RateLimitResult{
+		Allowed:      false,
+		Limit:        10,
+		ResetTime:    longBlock,
+		BlockedUntil: &longBlock,
+		LimiterType:  domain.IPLimiter,
+	}, nil)
+	mockService.On("CheckLimit", mock.Anything, "192.168.1.2", "").Return(&domain.RateLimitResult{
+		Allowed:      false,
+		Limit:        10,
+		ResetTime:    shortBlock,
+		BlockedUntil: &shortBlock,
+		LimiterType:  domain.IPLimiter,
+	}, nil)
+	mockService.On("CheckLimit", mock.Anything, "192.168.1.3", "").Return(&domain.RateLimitResult{
+		Allowed:     true,
+		Limit:       10,
+		Remaining:   9,
+		ResetTime:   now.Add(time.Minute),
+		LimiterType: domain.IPLimiter,
+	}, nil)
+	mockLogger.On("WithContext", mock.Anything).Return(mockLogger)
+	mockLogger.On("Debug", mock.AnythingOfType("string"), mock.Anything).Maybe()
+	mockLogger.On("Info", mock.AnythingOfType("string"), mock.Anything).Maybe()
+
+	router := setupTestRouter(NewRateLimiterMiddleware(mockService, mockLogger,
+		WithRejectionCache(RejectionCache{TTL: 10 * time.Second, Headers: []string{"CDN-Cache-Control"}}),
+		WithClock(func() time.Time { return now }),
+	))
+
+	request := func(ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("X-Forwarded-For", ip)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Act
+	long := request("192.168.1.1")
+	short := request("192.168.1.2")
+	allowed := request("192.168.1.3")
+
+	// Assert: o TTL nunca passa do Retry-After e respostas permitidas não são marcadas
+	assert.Equal(t, http.StatusTooManyRequests, long.Code)
+	assert.Equal(t, "public, max-age=10", long.Header().Get("Cache-Control"))
+	assert.Equal(t, "max-age=10", long.Header().Get("CDN-Cache-Control"))
+	assert.Contains(t, long.Header().Get("Vary"), "API_KEY")
+	assert.Equal(t, "30", long.Header().Get("Retry-After"))
+
+	assert.Equal(t, "public, max-age=4", short.Header().Get("Cache-Control"))
+	assert.Equal(t, "max-age=4", short.Header().Get("CDN-Cache-Control"))
+
+	assert.Equal(t, http.StatusOK, allowed.Code)
+	assert.Empty(t, allowed.Header().Get("Cache-Control"))
+	assert.Empty(t, allowed.Header().Get("CDN-Cache-Control"))
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"rate-limiter/internal/logger"
+)
+
+// RequestIDHeader é o header usado para correlacionar requisições
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDContextKey é a chave do request ID no gin.Context
+const requestIDContextKey = "request_id"
+
+// maxRequestIDLength limita IDs recebidos para não poluir logs e respostas
+const maxRequestIDLength = 128
+
+// RequestID garante um request ID em toda requisição
+// Reaproveita o X-Request-ID recebido quando válido, devolve-o na resposta
+// e o propaga no contexto para logs, eventos e auditoria
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := ensureRequestID(c)
+		c.Request = c.Request.WithContext(logger.ContextWithRequestID(c.Request.Context(), requestID))
+		c.Next()
+	}
+}
+
+// GetRequestID retorna o request ID associado à requisição
+func GetRequestID(c *gin.Context) string {
+	if value, exists := c.Get(requestIDContextKey); exists {
+		if requestID, ok := value.(string); ok {
+			return requestID
+		}
+	}
+	return ""
+}
+
+// ensureRequestID obtém o request ID da requisição, gerando um novo se necessário
+func ensureRequestID(c *gin.Context) string {
+	if requestID := GetRequestID(c); requestID != "" {
+		return requestID
+	}
+
+	requestID := requestHeader(c, headerRequestID)
+	if !isValidRequestID(requestID) {
+		requestID = uuid.New().String()
+	}
+
+	c.Set(requestIDContextKey, requestID)
+	setHeader(c, headerRequestID, requestID)
+	return requestID
+}
+
+// isValidRequestID aceita apenas IDs curtos com caracteres imprimíveis
+func isValidRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] < 0x21 || requestID[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
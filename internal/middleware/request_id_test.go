@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"rate-limiter/internal/logger"
+)
+
+// TestRequestID testa a propagação do X-Request-ID
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     string
+		expectSame bool
+	}{
+		{name: "Should reuse incoming request ID", header: "abc-123", expectSame: true},
+		{name: "Should generate request ID when missing", header: ""},
+		{name: "Should replace request ID with control characters", header: "abc\x01def"},
+		{name: "Should replace oversized request ID", header: strings.Repeat("a", maxRequestIDLength+1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(RequestID())
+
+			var fromGin, fromContext string
+			router.GET("/test", func(c *gin.Context) {
+				fromGin = GetRequestID(c)
+				fromContext = logger.GetRequestID(c.Request.Context())
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest("GET", "/test", nil)
+			if tt.header != "" {
+				req.Header.Set(RequestIDHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			requestID := w.Header().Get(RequestIDHeader)
+			assert.NotEmpty(t, requestID)
+			assert.Equal(t, requestID, fromGin)
+			assert.Equal(t, requestID, fromContext)
+			if tt.expectSame {
+				assert.Equal(t, tt.header, requestID)
+			} else {
+				assert.NotEqual(t, tt.header, requestID)
+			}
+		})
+	}
+}
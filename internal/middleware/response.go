@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"rate-limiter/internal/domain"
+)
+
+// ErrorResponse é uma resposta de erro (429, 5xx) antes da serialização.
+// Structs serializam sem as alocações de gin.H aninhados; os campos seguem a
+// ordem alfabética que o gin.H produzia
+type ErrorResponse struct {
+	Status       int               `json:"-"`
+	ChallengeURL string            `json:"challenge_url,omitempty"` // apenas com o desafio ligado
+	Details      *RateLimitDetails `json:"details,omitempty"`       // apenas no 429 do rate limiter
+	DocsURL      string            `json:"docs_url,omitempty"`
+	Error        string            `json:"error"`
+	Message      string            `json:"message"`
+	RequestID    string            `json:"request_id,omitempty"`
+}
+
+// RateLimitDetails detalha a decisão no corpo do 429
+type RateLimitDetails struct {
+	BlockedUntil int64              `json:"blocked_until,omitempty"`
+	Limit        int                `json:"limit"`
+	LimiterType  domain.LimiterType `json:"limiter_type"`
+	Remaining    int                `json:"remaining"`
+	ResetTime    int64              `json:"reset_time"`
+	Scope        string             `json:"scope,omitempty"` // hierarquia: nível que negou
+}
+
+// ResponseSerializer gera o corpo das respostas de erro do middleware e dos
+// handlers. Substituí-lo troca o envelope de todas elas de uma vez (ex:
+// application/problem+json ou o formato padrão da empresa)
+type ResponseSerializer interface {
+	// Serialize escreve o corpo em w e retorna o content type
+	Serialize(w io.Writer, response *ErrorResponse) (contentType string, err error)
+}
+
+// JSONSerializer é o serializer padrão: o próprio ErrorResponse em JSON
+type JSONSerializer struct{}
+
+// Serialize implementa ResponseSerializer
+func (JSONSerializer) Serialize(w io.Writer, response *ErrorResponse) (string, error) {
+	data, err := json.Marshal(response)
+	if err != nil {
+		return "", err
+	}
+	_, err = w.Write(data)
+	return "application/json; charset=utf-8", err
+}
+
+// responseBuffers reaproveita os buffers de serialização entre respostas
+var responseBuffers = sync.Pool{
+	New: func() interface{} { return new(bytes.Buffer) },
+}
+
+// abortWithResponse encerra a requisição serializando response em um buffer do pool
+// Em HEAD a decisão vai apenas no status e nos headers, sem corpo
+func abortWithResponse(c *gin.Context, serializer ResponseSerializer, response *ErrorResponse) {
+	if c.Request.Method == http.MethodHead {
+		c.AbortWithStatus(response.Status)
+		return
+	}
+
+	buffer := responseBuffers.Get().(*bytes.Buffer)
+	buffer.Reset()
+	defer responseBuffers.Put(buffer)
+
+	contentType, err := serializer.Serialize(buffer, response)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.Data(response.Status, contentType, buffer.Bytes())
+	c.Abort()
+}
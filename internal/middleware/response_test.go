@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/domain"
+)
+
+// problemSerializer gera corpos no formato application/problem+json (RFC 7807)
+type problemSerializer struct{}
+
+func (problemSerializer) Serialize(w io.Writer, response *ErrorResponse) (string, error) {
+	problem := map[string]interface{}{
+		"type":   "https://errors.example.com/" + response.Error,
+		"title":  response.Message,
+		"status": response.Status,
+	}
+	if response.Details != nil {
+		problem["retry_at"] = response.Details.BlockedUntil
+	}
+	return "application/problem+json", json.NewEncoder(w).Encode(problem)
+}
+
+func TestJSONSerializer(t *testing.T) {
+	// Arrange
+	response := &ErrorResponse{
+		Status:    http.StatusTooManyRequests,
+		Error:     "rate_limit_exceeded",
+		Message:   "slow down",
+		RequestID: "req-1",
+		Details:   &RateLimitDetails{Limit: 10, LimiterType: domain.IPLimiter, ResetTime: 1704103200},
+	}
+	recorder := httptest.NewRecorder()
+
+	// Act
+	contentType, err := JSONSerializer{}.Serialize(recorder.Body, response)
+
+	// Assert: mesmo corpo do gin.H usado antes do serializer
+	require.NoError(t, err)
+	assert.Equal(t, "application/json; charset=utf-8", contentType)
+	assert.Equal(t, `{"details":{"limit":10,"limiter_type":"ip","remaining":0,"reset_time":1704103200},"error":"rate_limit_exceeded","message":"slow down","request_id":"req-1"}`, recorder.Body.String())
+}
+
+func TestOptions_ResponseSerializer(t *testing.T) {
+	// Arrange
+	blockedUntil := time.Now().Add(time.Minute)
+	mockService := new(MockRateLimiterService)
+	mockLogger := new(MockLogger)
+	mockService.On("CheckLimit", mock.Anything, "10.0.0.1", "").Return(&domain.RateLimitResult{
+		Allowed:      false,
+		Limit:        10,
+		ResetTime:    blockedUntil,
+		BlockedUntil: &blockedUntil,
+		LimiterType:  domain.IPLimiter,
+	}, nil)
+	mockService.On("CheckLimit", mock.Anything, "10.0.0.2", "").Return(nil, errors.New("boom"))
+	mockLogger.On("WithContext", mock.Anything).Return(mockLogger)
+	mockLogger.On("Debug", mock.AnythingOfType("string"), mock.Anything).Maybe()
+	mockLogger.On("Info", mock.AnythingOfType("string"), mock.Anything).Maybe()
+	mockLogger.On("Error", mock.AnythingOfType("string"), mock.Anything, mock.Anything).Maybe()
+
+	router := setupTestRouter(NewRateLimiterMiddleware(mockService, mockLogger,
+		WithConfig(Config{Serializer: problemSerializer{}})))
+
+	for _, tc := range []struct {
+		ip     string
+		status int
+		title  string
+	}{
+		{"10.0.0.1", http.StatusTooManyRequests, DefaultBlockMessage},
+		{"10.0.0.2", http.StatusInternalServerError, "Unable to process rate limit check"},
+	} {
+		// Act
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("X-Forwarded-For", tc.ip)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		// Assert
+		assert.Equal(t, tc.status, w.Code)
+		assert.Equal(t, "application/problem+json", w.Header().Get("Content-Type"))
+		var problem map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+		assert.Equal(t, tc.title, problem["title"])
+		assert.Equal(t, float64(tc.status), problem["status"])
+		if tc.status == http.StatusTooManyRequests {
+			assert.Equal(t, float64(blockedUntil.Unix()), problem["retry_at"])
+			assert.NotEmpty(t, w.Header().Get("Retry-After"))
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"rate-limiter/internal/domain"
+)
+
+// Tarpit atrasa as respostas de clientes suspeitos em vez de respondê-las na hora:
+// scrapers que repetem a requisição assim que recebem o 429 passam a esperar por
+// cada tentativa. Clientes bem-comportados, abaixo da faixa, não são afetados
+type Tarpit struct {
+	// MaxDelay é o atraso de cada requisição recusada e o teto da faixa suspeita
+	// (0 = desabilitado)
+	MaxDelay time.Duration
+
+	// BandPercent é o % do limite a partir do qual as requisições permitidas também
+	// são atrasadas, proporcionalmente ao avanço até o limite (0 = só as recusadas)
+	BandPercent int
+
+	// MaxConcurrent limita as requisições retidas ao mesmo tempo; acima disso elas
+	// respondem sem atraso, para o tarpit não esgotar conexões e goroutines (0 = sem limite)
+	MaxConcurrent int
+}
+
+// Enabled informa se o tarpit está ligado
+func (t Tarpit) Enabled() bool {
+	return t.MaxDelay > 0
+}
+
+// delay retorna o atraso da decisão (0 = responder na hora). Recusas recebem o
+// atraso máximo; permitidas na faixa, uma fração dele que cresce até o limite
+func (t Tarpit) delay(result *domain.RateLimitResult) time.Duration {
+	if !result.Allowed {
+		return t.MaxDelay
+	}
+	if t.BandPercent <= 0 || result.Limit <= 0 {
+		return 0
+	}
+
+	// Primeira contagem da faixa, arredondada para cima como o aviso de soft limit
+	start := (result.Limit*t.BandPercent + 99) / 100
+	used := result.Limit - result.Remaining
+	if used < start {
+		return 0
+	}
+	return t.MaxDelay * time.Duration(used-start+1) / time.Duration(result.Limit-start+1)
+}
+
+// tarpitState guarda a configuração e as requisições retidas no momento
+type tarpitState struct {
+	config Tarpit
+	held   atomic.Int64
+}
+
+// acquire reserva uma vaga de retenção; false quando MaxConcurrent já foi atingido
+func (t *tarpitState) acquire() bool {
+	if t.held.Add(1) > int64(t.config.MaxConcurrent) && t.config.MaxConcurrent > 0 {
+		t.held.Add(-1)
+		return false
+	}
+	return true
+}
+
+func (t *tarpitState) release() {
+	t.held.Add(-1)
+}
+
+// applyTarpit retém a requisição suspeita antes da resposta. Retorna false se o
+// cliente desistir durante a espera
+func (m *RateLimiterMiddleware) applyTarpit(c *gin.Context, result *domain.RateLimitResult) bool {
+	if m.tarpit == nil {
+		return true
+	}
+	delay := m.tarpit.config.delay(result)
+	if delay <= 0 {
+		return true
+	}
+	if !m.tarpit.acquire() {
+		m.counters.recordTarpit(result.Allowed, false)
+		return true
+	}
+	defer m.tarpit.release()
+
+	m.counters.recordTarpit(result.Allowed, true)
+	return m.wait(c, delay)
+}
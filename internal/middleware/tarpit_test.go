@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"rate-limiter/internal/domain"
+)
+
+func TestTarpit_Delay(t *testing.T) {
+	tarpit := Tarpit{MaxDelay: time.Second, BandPercent: 80}
+
+	tests := []struct {
+		name     string
+		result   domain.RateLimitResult
+		expected time.Duration
+	}{
+		{"below the band", domain.RateLimitResult{Allowed: true, Limit: 10, Remaining: 3}, 0},
+		{"band start", domain.RateLimitResult{Allowed: true, Limit: 10, Remaining: 2}, time.Second / 3},
+		{"last allowed request", domain.RateLimitResult{Allowed: true, Limit: 10, Remaining: 0}, time.Second},
+		{"denied", domain.RateLimitResult{Allowed: false, Limit: 10, Remaining: 0}, time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tarpit.delay(&tt.result))
+		})
+	}
+
+	// Sem faixa, só as recusas são atrasadas
+	deniedOnly := Tarpit{MaxDelay: time.Second}
+	assert.Zero(t, deniedOnly.delay(&domain.RateLimitResult{Allowed: true, Limit: 10, Remaining: 0}))
+}
+
+func TestRateLimiterMiddleware_Tarpit(t *testing.T) {
+	// Arrange: recusas retidas por 50ms, no máximo uma por vez
+	mockService := new(MockRateLimiterService)
+	mockLogger := new(MockLogger)
+	denied := &domain.RateLimitResult{
+		Allowed:     false,
+		Limit:       10,
+		Remaining:   0,
+		ResetTime:   time.Now().Add(time.Minute),
+		LimiterType: domain.IPLimiter,
+	}
+	allowed := &domain.RateLimitResult{
+		Allowed:     true,
+		Limit:       10,
+		Remaining:   9,
+		ResetTime:   time.Now().Add(time.Minute),
+		LimiterType: domain.IPLimiter,
+	}
+	mockService.On("CheckLimit", mock.Anything, "192.168.1.1", "").Return(denied, nil)
+	mockService.On("CheckLimit", mock.Anything, "192.168.1.2", "").Return(allowed, nil)
+	mockLogger.On("WithContext", mock.Anything).Return(mockLogger)
+	mockLogger.On("Debug", mock.AnythingOfType("string"), mock.Anything).Maybe()
+	mockLogger.On("Info", "Request rate limited", mock.Anything)
+
+	counters := &Counters{}
+	router := setupTestRouter(NewRateLimiterMiddleware(mockService, mockLogger, WithConfig(Config{
+		Counters: counters,
+		Tarpit:   Tarpit{MaxDelay: 50 * time.Millisecond, BandPercent: 80, MaxConcurrent: 1},
+	})))
+	serve := func(ctx context.Context, ip string) (*httptest.ResponseRecorder, time.Duration) {
+		req := httptest.NewRequest("GET", "/test", nil).WithContext(ctx)
+		req.Header.Set("X-Forwarded-For", ip)
+		w := httptest.NewRecorder()
+		start := time.Now()
+		router.ServeHTTP(w, req)
+		return w, time.Since(start)
+	}
+
+	// Act: duas recusas simultâneas disputam a única vaga
+	var wg sync.WaitGroup
+	elapsed := make([]time.Duration, 2)
+	codes := make([]int, 2)
+	for i := range elapsed {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			w, took := serve(context.Background(), "192.168.1.1")
+			codes[i], elapsed[i] = w.Code, took
+		}(i)
+	}
+	wg.Wait()
+
+	fast, took := serve(context.Background(), "192.168.1.2")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	abandoned, _ := serve(ctx, "192.168.1.1")
+
+	// Assert
+	assert.Equal(t, []int{http.StatusTooManyRequests, http.StatusTooManyRequests}, codes)
+	slowest, quickest := elapsed[0], elapsed[1]
+	if quickest > slowest {
+		slowest, quickest = quickest, slowest
+	}
+	assert.GreaterOrEqual(t, slowest, 40*time.Millisecond)
+	assert.Less(t, quickest, 40*time.Millisecond, "requests beyond MaxConcurrent are answered right away")
+
+	assert.Equal(t, http.StatusOK, fast.Code)
+	assert.Less(t, took, 40*time.Millisecond, "clients below the band are not delayed")
+	assert.Equal(t, statusClientClosedRequest, abandoned.Code)
+
+	assert.Contains(t, counters.Tarpits(), TarpitCount{Decision: DecisionThrottled, Outcome: TarpitOutcomeDelayed, Count: 2})
+	assert.Contains(t, counters.Tarpits(), TarpitCount{Decision: DecisionThrottled, Outcome: TarpitOutcomeSkipped, Count: 1})
+}
@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"rate-limiter/internal/domain"
+)
+
+// TracerName identifica os spans emitidos pelo middleware
+const TracerName = "rate-limiter/internal/middleware"
+
+// continueTrace continua o trace do cliente (traceparent) quando nenhum
+// middleware anterior (ex: otelgin) abriu um span para a requisição. Usa o
+// propagator global, registrado por tracing.Setup; os handlers seguintes
+// herdam o contexto
+func continueTrace(c *gin.Context) {
+	ctx := c.Request.Context()
+	if trace.SpanContextFromContext(ctx).IsValid() {
+		return
+	}
+	extracted := otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(c.Request.Header))
+	if trace.SpanContextFromContext(extracted).IsValid() {
+		c.Request = c.Request.WithContext(extracted)
+	}
+}
+
+// startCheckSpan abre o span da verificação de rate limit, encerrado antes dos
+// handlers: mede só a decisão. A chave não é registrada por conter tokens
+func startCheckSpan(ctx context.Context, c *gin.Context) (context.Context, trace.Span) {
+	ctx, span := otel.Tracer(TracerName).Start(ctx, "rate_limiter.check")
+	if span.IsRecording() {
+		span.SetAttributes(attribute.String("http.route", c.FullPath()))
+	}
+	return ctx, span
+}
+
+// endCheckSpan registra a decisão e encerra o span
+func endCheckSpan(span trace.Span, result *domain.RateLimitResult, err error) {
+	if span.IsRecording() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		if result != nil {
+			span.SetAttributes(
+				attribute.String("rate_limit.key_type", string(result.LimiterType)),
+				attribute.Bool("rate_limit.allowed", result.Allowed),
+				attribute.Int("rate_limit.limit", result.Limit),
+				attribute.Int("rate_limit.remaining", result.Remaining),
+			)
+		}
+	}
+	span.End()
+}
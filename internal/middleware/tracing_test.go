@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+
+	"rate-limiter/internal/domain"
+)
+
+// useSpanRecorder instala um tracer provider que grava os spans e o propagator
+// W3C durante o teste. Ao fim, instala um provider noop: o provider global
+// original passa a delegar ao do teste na primeira troca e continuaria gravando
+// (e alocando) nos testes de alocação seguintes
+func useSpanRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	propagator := otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(tracenoop.NewTracerProvider())
+		otel.SetTextMapPropagator(propagator)
+	})
+	return recorder
+}
+
+func TestRateLimiterMiddleware_CheckSpan(t *testing.T) {
+	// Arrange: cliente com trace em andamento (traceparent)
+	recorder := useSpanRecorder(t)
+	mockService := new(MockRateLimiterService)
+	mockLogger := new(MockLogger)
+	var serviceSpan trace.SpanContext
+	mockService.On("CheckLimit", mock.Anything, "192.168.1.1", "").Run(func(args mock.Arguments) {
+		serviceSpan = trace.SpanContextFromContext(args.Get(0).(context.Context))
+	}).Return(&domain.RateLimitResult{
+		Allowed:     true,
+		Limit:       10,
+		Remaining:   9,
+		ResetTime:   time.Now().Add(time.Minute),
+		LimiterType: domain.IPLimiter,
+	}, nil)
+	mockLogger.On("WithContext", mock.Anything).Return(mockLogger)
+	mockLogger.On("Debug", mock.AnythingOfType("string"), mock.Anything).Maybe()
+
+	var handlerSpan trace.SpanContext
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(NewRateLimiterMiddleware(mockService, mockLogger))
+	router.GET("/test", func(c *gin.Context) {
+		handlerSpan = trace.SpanContextFromContext(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("X-Forwarded-For", "192.168.1.1")
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	// Act
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert: o span da verificação entra no trace do cliente e é pai do service
+	require.Equal(t, http.StatusOK, w.Code)
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	check := spans[0]
+	assert.Equal(t, "rate_limiter.check", check.Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", check.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", check.Parent().SpanID().String())
+	assert.Equal(t, check.SpanContext().SpanID(), serviceSpan.SpanID())
+	assert.Subset(t, check.Attributes(), []attribute.KeyValue{
+		attribute.String("http.route", "/test"),
+		attribute.String("rate_limit.key_type", "ip"),
+		attribute.Bool("rate_limit.allowed", true),
+		attribute.Int("rate_limit.limit", 10),
+		attribute.Int("rate_limit.remaining", 9),
+	})
+
+	// Os handlers seguem no trace do cliente, fora do span da verificação
+	assert.Equal(t, check.SpanContext().TraceID(), handlerSpan.TraceID())
+	assert.Equal(t, check.Parent().SpanID(), handlerSpan.SpanID())
+}
@@ -0,0 +1,132 @@
+package observe
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"rate-limiter/internal/domain"
+)
+
+// DefaultMaxWatches limita as amostragens simultâneas
+const DefaultMaxWatches = 32
+
+// ErrTooManyWatches indica que o limite de amostragens simultâneas foi atingido
+var ErrTooManyWatches = errors.New("too many concurrent observations")
+
+// Sample é o resultado de uma amostragem da taxa de uma chave
+type Sample struct {
+	Requests int64
+	Duration time.Duration
+	RPS      float64
+}
+
+// watch conta as requisições de uma chave enquanto houver amostragens ativas
+type watch struct {
+	count   atomic.Int64
+	waiters int
+}
+
+// Observer mede a taxa real de requisições de chaves específicas sob demanda.
+// Implementa domain.TrafficObserver; sem amostragens ativas o custo por
+// requisição é uma leitura atômica
+type Observer struct {
+	maxWatches int
+	active     atomic.Int32
+
+	mutex   sync.RWMutex
+	watches map[string]*watch
+}
+
+// NewObserver cria um observador com limite de amostragens simultâneas
+func NewObserver(maxWatches int) *Observer {
+	if maxWatches <= 0 {
+		maxWatches = DefaultMaxWatches
+	}
+	return &Observer{
+		maxWatches: maxWatches,
+		watches:    make(map[string]*watch),
+	}
+}
+
+// ObserveRequest implementa domain.TrafficObserver
+func (o *Observer) ObserveRequest(key string, limiterType domain.LimiterType) {
+	if o.active.Load() == 0 {
+		return
+	}
+
+	o.mutex.RLock()
+	w, exists := o.watches[watchID(key, limiterType)]
+	o.mutex.RUnlock()
+
+	if exists {
+		w.count.Add(1)
+	}
+}
+
+// Sample conta as requisições da chave durante a duração informada.
+// Amostragens concorrentes da mesma chave compartilham o contador
+func (o *Observer) Sample(ctx context.Context, key string, limiterType domain.LimiterType, duration time.Duration) (Sample, error) {
+	id := watchID(key, limiterType)
+	w, start, err := o.acquire(id)
+	if err != nil {
+		return Sample{}, err
+	}
+	defer o.release(id)
+
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		return Sample{}, ctx.Err()
+	}
+
+	requests := w.count.Load() - start
+	return Sample{
+		Requests: requests,
+		Duration: duration,
+		RPS:      float64(requests) / duration.Seconds(),
+	}, nil
+}
+
+// acquire registra uma amostragem e retorna o contador e seu valor inicial
+func (o *Observer) acquire(id string) (*watch, int64, error) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	w, exists := o.watches[id]
+	if !exists {
+		if len(o.watches) >= o.maxWatches {
+			return nil, 0, ErrTooManyWatches
+		}
+		w = &watch{}
+		o.watches[id] = w
+		o.active.Add(1)
+	}
+	w.waiters++
+	return w, w.count.Load(), nil
+}
+
+// release remove a chave quando a última amostragem termina
+func (o *Observer) release(id string) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	w, exists := o.watches[id]
+	if !exists {
+		return
+	}
+	w.waiters--
+	if w.waiters == 0 {
+		delete(o.watches, id)
+		o.active.Add(-1)
+	}
+}
+
+func watchID(key string, limiterType domain.LimiterType) string {
+	return string(limiterType) + ":" + key
+}
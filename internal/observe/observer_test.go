@@ -0,0 +1,65 @@
+package observe
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/domain"
+)
+
+// observeDuring dispara n requisições da chave assim que a amostragem começa
+func observeDuring(observer *Observer, key string, limiterType domain.LimiterType, n int) {
+	go func() {
+		for observer.active.Load() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		for i := 0; i < n; i++ {
+			observer.ObserveRequest(key, limiterType)
+		}
+	}()
+}
+
+func TestObserver_SampleCountsOnlyTheWatchedKey(t *testing.T) {
+	// Arrange
+	observer := NewObserver(0)
+	observer.ObserveRequest("10.0.0.1", domain.IPLimiter) // antes da amostragem: ignorada
+	observeDuring(observer, "10.0.0.1", domain.IPLimiter, 30)
+	observeDuring(observer, "10.0.0.2", domain.IPLimiter, 50)
+	observeDuring(observer, "10.0.0.1", domain.TokenLimiter, 70)
+
+	// Act
+	sample, err := observer.Sample(context.Background(), "10.0.0.1", domain.IPLimiter, 200*time.Millisecond)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int64(30), sample.Requests)
+	assert.Equal(t, 200*time.Millisecond, sample.Duration)
+	assert.InDelta(t, 150.0, sample.RPS, 0.001)
+	assert.Empty(t, observer.watches)
+	assert.Equal(t, int32(0), observer.active.Load())
+}
+
+func TestObserver_RejectsWhenWatchLimitReached(t *testing.T) {
+	// Arrange
+	observer := NewObserver(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := observer.Sample(ctx, "a", domain.IPLimiter, time.Minute)
+		done <- err
+	}()
+	require.Eventually(t, func() bool { return observer.active.Load() == 1 }, time.Second, time.Millisecond)
+
+	// Act
+	_, err := observer.Sample(context.Background(), "b", domain.IPLimiter, time.Millisecond)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrTooManyWatches)
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+	assert.Empty(t, observer.watches)
+}
@@ -0,0 +1,75 @@
+// Package proxy encaminha as requisições que passaram pelo rate limiter para um
+// upstream (modo reverse proxy), com os headers de correlação para os logs do backend
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/logger"
+	"rate-limiter/internal/middleware"
+)
+
+// DecisionHeader leva ao upstream o desfecho do rate limiter (middleware.GetDecision)
+const DecisionHeader = "X-RateLimit-Decision"
+
+// propagator propaga o contexto de trace W3C (traceparent, tracestate) e o baggage
+var propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// New cria o handler que encaminha a requisição ao upstream. O upstream recebe:
+//   - X-Request-ID com o ID usado nos logs e eventos do rate limiter
+//   - X-RateLimit-Decision (allowed, bypassed, degraded ou challenge_passed); o valor enviado pelo cliente é descartado
+//   - traceparent/tracestate/baggage do span ativo ou, sem ele, os recebidos do cliente
+//
+// Deve ser registrado depois do middleware de rate limiting
+func New(upstream *url.URL, log domain.Logger) gin.HandlerFunc {
+	proxy := httputil.NewSingleHostReverseProxy(upstream)
+	proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		log.WithContext(r.Context()).Error("Upstream request failed", err, map[string]interface{}{
+			"upstream": upstream.Host,
+			"method":   r.Method,
+			"path":     r.URL.Path,
+		})
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusBadGateway)
+		json.NewEncoder(w).Encode(map[string]string{
+			"error":      "bad_gateway",
+			"message":    "Upstream request failed",
+			"request_id": logger.GetRequestID(r.Context()),
+		})
+	}
+
+	return func(c *gin.Context) {
+		header := c.Request.Header
+
+		header.Del(DecisionHeader)
+		if decision := middleware.GetDecision(c); decision != "" {
+			header.Set(DecisionHeader, decision)
+		}
+		if requestID := middleware.GetRequestID(c); requestID != "" {
+			header.Set(middleware.RequestIDHeader, requestID)
+		}
+
+		ctx := c.Request.Context()
+		if !trace.SpanContextFromContext(ctx).IsValid() {
+			ctx = propagator.Extract(ctx, propagation.HeaderCarrier(header))
+		}
+		propagator.Inject(ctx, propagation.HeaderCarrier(header))
+
+		proxy.ServeHTTP(responseWriter{c.Writer, c.Writer}, c.Request)
+	}
+}
+
+// responseWriter expõe ao ReverseProxy apenas a escrita e o flush do writer do Gin:
+// o CloseNotify do Gin entra em pânico quando o writer subjacente não o implementa
+type responseWriter struct {
+	http.ResponseWriter
+	http.Flusher
+}
@@ -0,0 +1,110 @@
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/logger"
+	"rate-limiter/internal/middleware"
+)
+
+// setupProxyRouter cria o router do modo proxy; a pré-verificação dispensa o service
+func setupProxyRouter(t *testing.T, upstreamURL string) *gin.Engine {
+	upstream, err := url.Parse(upstreamURL)
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.RequestID())
+	bypass := func(c *gin.Context, clientIP, apiToken string) bool { return true }
+	router.NoRoute(
+		middleware.NewRateLimiterMiddleware(nil, logger.NewNopLogger(), middleware.WithPreChecks(bypass)),
+		New(upstream, logger.NewNopLogger()),
+	)
+	return router
+}
+
+func TestProxy_ForwardsCorrelationHeaders(t *testing.T) {
+	// Arrange
+	var received http.Header
+	var path string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		path = r.URL.Path
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer upstream.Close()
+	router := setupProxyRouter(t, upstream.URL)
+
+	traceparent := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	req := httptest.NewRequest("POST", "/orders/42", nil)
+	req.Header.Set("X-Request-ID", "req-123")
+	req.Header.Set("traceparent", traceparent)
+	req.Header.Set(DecisionHeader, "allowed")
+
+	// Act
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert: a decisão enviada pelo cliente é substituída pela do middleware
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "/orders/42", path)
+	assert.Equal(t, "req-123", received.Get("X-Request-ID"))
+	assert.Equal(t, middleware.DecisionBypassed, received.Get(DecisionHeader))
+	assert.Equal(t, traceparent, received.Get("traceparent"))
+}
+
+func TestProxy_WithoutDecisionDropsClientHeader(t *testing.T) {
+	// Arrange: handler do proxy sem o middleware de rate limiting
+	var received http.Header
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+	}))
+	defer upstream.Close()
+	target, err := url.Parse(upstream.URL)
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.NoRoute(New(target, logger.NewNopLogger()))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set(DecisionHeader, "allowed")
+
+	// Act
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, received.Get(DecisionHeader))
+}
+
+func TestProxy_UpstreamDown(t *testing.T) {
+	// Arrange
+	upstream := httptest.NewServer(http.NotFoundHandler())
+	upstreamURL := upstream.URL
+	upstream.Close()
+	router := setupProxyRouter(t, upstreamURL)
+
+	req := httptest.NewRequest("GET", "/orders", nil)
+	req.Header.Set("X-Request-ID", "req-456")
+
+	// Act
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	// Assert
+	require.Equal(t, http.StatusBadGateway, w.Code)
+	var body map[string]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "bad_gateway", body["error"])
+	assert.Equal(t, "req-456", body["request_id"])
+}
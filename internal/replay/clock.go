@@ -0,0 +1,34 @@
+package replay
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock é o relógio simulado do replay: só anda quando Set recebe um instante
+// posterior, então a mesma entrada sempre produz as mesmas decisões
+type Clock struct {
+	mutex sync.RWMutex
+	now   time.Time
+}
+
+// NewClock cria o relógio parado em start
+func NewClock(start time.Time) *Clock {
+	return &Clock{now: start}
+}
+
+// Now retorna o instante simulado
+func (c *Clock) Now() time.Time {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.now
+}
+
+// Set avança o relógio até at; instantes anteriores são ignorados
+func (c *Clock) Set(at time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if at.After(c.now) {
+		c.now = at
+	}
+}
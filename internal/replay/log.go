@@ -0,0 +1,182 @@
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Entry é uma requisição do log de tráfego gravado
+type Entry struct {
+	Timestamp time.Time
+	IP        string
+	Token     string
+	Path      string
+}
+
+// jsonEntry aceita o timestamp como RFC 3339 ou segundos Unix
+type jsonEntry struct {
+	Timestamp json.RawMessage `json:"timestamp"`
+	IP        string          `json:"ip"`
+	Token     string          `json:"token"`
+	Path      string          `json:"path"`
+}
+
+// LoadFile lê o log pelo formato da extensão: .csv, .json (array) ou .jsonl/.ndjson
+func LoadFile(path string) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open traffic log: %w", err)
+	}
+	defer file.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return ReadCSV(file)
+	case ".json", ".jsonl", ".ndjson":
+		return ReadJSON(file)
+	default:
+		return nil, fmt.Errorf("unsupported traffic log format %q: use .csv, .json, .jsonl or .ndjson", filepath.Ext(path))
+	}
+}
+
+// ReadCSV lê um log CSV com cabeçalho. As colunas são localizadas pelo nome
+// (timestamp, ip, token, path); token e path são opcionais
+func ReadCSV(r io.Reader) ([]Entry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["timestamp"]; !ok {
+		return nil, fmt.Errorf("CSV header must contain a timestamp column")
+	}
+	_, hasIP := columns["ip"]
+	_, hasToken := columns["token"]
+	if !hasIP && !hasToken {
+		return nil, fmt.Errorf("CSV header must contain an ip or token column")
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var entries []Entry
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV line %d: %w", line, err)
+		}
+
+		entry, err := newEntry(field(record, "timestamp"), field(record, "ip"), field(record, "token"), field(record, "path"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid CSV line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// ReadJSON lê um array JSON de requisições ou uma requisição JSON por linha
+func ReadJSON(r io.Reader) ([]Entry, error) {
+	buffered := bufio.NewReader(r)
+	first, err := peekNonSpace(buffered)
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read JSON traffic log: %w", err)
+	}
+
+	decoder := json.NewDecoder(buffered)
+	var records []jsonEntry
+	if first == '[' {
+		if err := decoder.Decode(&records); err != nil {
+			return nil, fmt.Errorf("failed to decode JSON traffic log: %w", err)
+		}
+	} else {
+		for {
+			var record jsonEntry
+			if err := decoder.Decode(&record); errors.Is(err, io.EOF) {
+				break
+			} else if err != nil {
+				return nil, fmt.Errorf("failed to decode JSON record %d: %w", len(records)+1, err)
+			}
+			records = append(records, record)
+		}
+	}
+
+	entries := make([]Entry, 0, len(records))
+	for i, record := range records {
+		timestamp := string(bytes.Trim(record.Timestamp, `"`))
+		entry, err := newEntry(timestamp, record.IP, record.Token, record.Path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON record %d: %w", i+1, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// newEntry valida e monta uma requisição do log
+func newEntry(timestamp, ip, token, path string) (Entry, error) {
+	at, err := parseTimestamp(timestamp)
+	if err != nil {
+		return Entry{}, err
+	}
+	if ip == "" && token == "" {
+		return Entry{}, fmt.Errorf("ip or token is required")
+	}
+	return Entry{Timestamp: at, IP: ip, Token: token, Path: path}, nil
+}
+
+// parseTimestamp aceita RFC 3339 ou segundos Unix (com fração opcional)
+func parseTimestamp(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, fmt.Errorf("timestamp is required")
+	}
+	if at, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return at, nil
+	}
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q: use RFC 3339 or Unix seconds", value)
+	}
+	whole := int64(seconds)
+	return time.Unix(whole, int64((seconds-float64(whole))*float64(time.Second))).UTC(), nil
+}
+
+// peekNonSpace retorna o primeiro caractere não branco sem consumi-lo
+func peekNonSpace(r *bufio.Reader) (byte, error) {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if b != ' ' && b != '\t' && b != '\r' && b != '\n' {
+			return b, r.UnreadByte()
+		}
+	}
+}
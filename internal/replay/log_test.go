@@ -0,0 +1,93 @@
+package replay
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadCSV(t *testing.T) {
+	// Arrange
+	log := "path,timestamp,ip,token\n" +
+		"/api,2024-03-01T12:00:00Z,10.0.0.1,\n" +
+		"/orders,1709294401.5,10.0.0.2,premium_token_123\n"
+
+	// Act
+	entries, err := ReadCSV(strings.NewReader(log))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{
+		{Timestamp: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), IP: "10.0.0.1", Path: "/api"},
+		{Timestamp: time.Date(2024, 3, 1, 12, 0, 1, 500000000, time.UTC), IP: "10.0.0.2", Token: "premium_token_123", Path: "/orders"},
+	}, entries)
+}
+
+func TestReadCSV_InvalidLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		log      string
+		errorMsg string
+	}{
+		{
+			name:     "Missing timestamp column",
+			log:      "ip,path\n10.0.0.1,/api\n",
+			errorMsg: "CSV header must contain a timestamp column",
+		},
+		{
+			name:     "Invalid timestamp",
+			log:      "timestamp,ip\nyesterday,10.0.0.1\n",
+			errorMsg: `invalid CSV line 2: invalid timestamp "yesterday": use RFC 3339 or Unix seconds`,
+		},
+		{
+			name:     "Missing identity",
+			log:      "timestamp,ip,token\n2024-03-01T12:00:00Z,,\n",
+			errorMsg: "invalid CSV line 2: ip or token is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadCSV(strings.NewReader(tt.log))
+
+			assert.EqualError(t, err, tt.errorMsg)
+		})
+	}
+}
+
+func TestReadJSON(t *testing.T) {
+	expected := []Entry{
+		{Timestamp: time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), IP: "10.0.0.1", Path: "/api"},
+		{Timestamp: time.Date(2024, 3, 1, 12, 0, 1, 0, time.UTC), Token: "premium_token_123"},
+	}
+
+	tests := []struct {
+		name string
+		log  string
+	}{
+		{
+			name: "Array",
+			log: `[{"timestamp":"2024-03-01T12:00:00Z","ip":"10.0.0.1","path":"/api"},
+				{"timestamp":1709294401,"token":"premium_token_123"}]`,
+		},
+		{
+			name: "One record per line",
+			log: `{"timestamp":"2024-03-01T12:00:00Z","ip":"10.0.0.1","path":"/api"}
+{"timestamp":1709294401,"token":"premium_token_123"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			entries, err := ReadJSON(strings.NewReader(tt.log))
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, expected, entries)
+		})
+	}
+}
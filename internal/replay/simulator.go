@@ -0,0 +1,187 @@
+package replay
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/service"
+	"rate-limiter/internal/storage"
+)
+
+// Decision é o resultado de uma requisição do log sob a configuração simulada
+type Decision struct {
+	Timestamp   time.Time          `json:"timestamp"`
+	IP          string             `json:"ip,omitempty"`
+	Token       string             `json:"token,omitempty"` // mascarado
+	Path        string             `json:"path,omitempty"`
+	LimiterType domain.LimiterType `json:"limiter_type,omitempty"`
+	Allowed     bool               `json:"allowed"`
+	Limit       int                `json:"limit"`
+	Remaining   int                `json:"remaining"`
+	Error       string             `json:"error,omitempty"`
+}
+
+// KeySummary resume as decisões de uma chave (IP ou token)
+type KeySummary struct {
+	Type            domain.LimiterType `json:"type"`
+	Key             string             `json:"key"` // tokens mascarados
+	Requests        int                `json:"requests"`
+	Rejected        int                `json:"rejected"`
+	FirstRejectedAt *time.Time         `json:"first_rejected_at,omitempty"`
+}
+
+// Report é o resultado do replay
+type Report struct {
+	Requests  int          `json:"requests"`
+	Allowed   int          `json:"allowed"`
+	Rejected  int          `json:"rejected"`
+	Errors    int          `json:"errors"`
+	Start     time.Time    `json:"start"`
+	End       time.Time    `json:"end"`
+	Keys      []KeySummary `json:"keys"` // mais rejeitadas primeiro
+	Decisions []Decision   `json:"decisions,omitempty"`
+}
+
+// Simulator reproduz um log de tráfego contra uma configuração, com storage em
+// memória isolado e relógio simulado: o resultado não depende do tempo real
+type Simulator struct {
+	clock   *Clock
+	storage *storage.MemoryStorage
+	service domain.RateLimiterService
+}
+
+// NewSimulator cria o simulador para a configuração e o algoritmo informados.
+// Regras fixadas em outros backends (ex: redis) usam o mesmo storage simulado
+func NewSimulator(config *domain.RateLimitConfig, algorithm storage.Algorithm, logger domain.Logger) (*Simulator, error) {
+	created, err := storage.NewStorageFactory().CreateStorage(&storage.StorageConfig{
+		Type:      storage.MemoryStorageType,
+		Algorithm: algorithm,
+	}, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create simulated storage: %w", err)
+	}
+
+	clock := NewClock(time.Time{})
+	memory := created.(*storage.MemoryStorage)
+	memory.SetClock(clock.Now)
+
+	return &Simulator{
+		clock:   clock,
+		storage: memory,
+		service: service.NewRateLimiterService(memory, config, logger,
+			service.WithClock(clock.Now),
+			service.WithStorages(map[string]domain.RateLimiterStorage{
+				string(storage.MemoryStorageType): memory,
+				string(storage.RedisStorageType):  memory,
+				string(storage.HybridStorageType): memory,
+				string(storage.TieredStorageType): memory,
+				string(storage.EtcdStorageType):   memory,
+			}),
+		),
+	}, nil
+}
+
+// Run envia as requisições ao serviço em ordem de timestamp, avançando o relógio
+// simulado até cada uma antes da decisão
+func (s *Simulator) Run(ctx context.Context, entries []Entry) (*Report, error) {
+	ordered := make([]Entry, len(entries))
+	copy(ordered, entries)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Timestamp.Before(ordered[j].Timestamp)
+	})
+
+	report := &Report{Decisions: make([]Decision, 0, len(ordered))}
+	summaries := make(map[string]*KeySummary)
+
+	for _, entry := range ordered {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		s.clock.Set(entry.Timestamp)
+
+		decision := Decision{
+			Timestamp: entry.Timestamp,
+			IP:        entry.IP,
+			Token:     maskKey(entry.Token, domain.TokenLimiter),
+			Path:      entry.Path,
+		}
+		result, err := s.service.CheckLimit(ctx, entry.IP, entry.Token)
+		report.Requests++
+		if err != nil {
+			decision.Error = err.Error()
+			report.Errors++
+			report.Decisions = append(report.Decisions, decision)
+			continue
+		}
+
+		decision.LimiterType = result.LimiterType
+		decision.Allowed = result.Allowed
+		decision.Limit = result.Limit
+		decision.Remaining = result.Remaining
+		report.Decisions = append(report.Decisions, decision)
+
+		key := entry.IP
+		if result.LimiterType == domain.TokenLimiter {
+			key = entry.Token
+		}
+		id := string(result.LimiterType) + ":" + key
+		summary, exists := summaries[id]
+		if !exists {
+			summary = &KeySummary{Type: result.LimiterType, Key: maskKey(key, result.LimiterType)}
+			summaries[id] = summary
+		}
+		summary.Requests++
+
+		if result.Allowed {
+			report.Allowed++
+			continue
+		}
+		report.Rejected++
+		summary.Rejected++
+		if summary.FirstRejectedAt == nil {
+			at := entry.Timestamp
+			summary.FirstRejectedAt = &at
+		}
+	}
+
+	if len(ordered) > 0 {
+		report.Start = ordered[0].Timestamp
+		report.End = ordered[len(ordered)-1].Timestamp
+	}
+
+	report.Keys = make([]KeySummary, 0, len(summaries))
+	for _, summary := range summaries {
+		report.Keys = append(report.Keys, *summary)
+	}
+	sort.Slice(report.Keys, func(i, j int) bool {
+		a, b := report.Keys[i], report.Keys[j]
+		if a.Rejected != b.Rejected {
+			return a.Rejected > b.Rejected
+		}
+		if a.Requests != b.Requests {
+			return a.Requests > b.Requests
+		}
+		return string(a.Type)+":"+a.Key < string(b.Type)+":"+b.Key
+	})
+
+	return report, nil
+}
+
+// Close libera o storage simulado
+func (s *Simulator) Close() error {
+	return s.storage.Close()
+}
+
+// maskKey mascara tokens antes de incluí-los no relatório (mesma regra dos logs)
+func maskKey(key string, limiterType domain.LimiterType) string {
+	if limiterType != domain.TokenLimiter || key == "" {
+		return key
+	}
+	if len(key) <= 8 {
+		return key + "***"
+	}
+	return key[:8] + "***"
+}
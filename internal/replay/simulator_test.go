@@ -0,0 +1,96 @@
+package replay
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/logger"
+	"rate-limiter/internal/storage"
+)
+
+// replayConfig permite 2 requisições por IP a cada 60s, com bloqueio de 120s
+func replayConfig() *domain.RateLimitConfig {
+	return &domain.RateLimitConfig{
+		DefaultIPLimit:    2,
+		DefaultTokenLimit: 100,
+		Window:            60,
+		BlockDuration:     120,
+		TokenConfigs: map[string]domain.TokenConfig{
+			"premium_token_123": {Token: "premium_token_123", Limit: 1},
+		},
+	}
+}
+
+// replayTraffic é um trecho de tráfego histórico (o relógio real nunca é consultado)
+func replayTraffic() []Entry {
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	at := func(seconds int) time.Time { return start.Add(time.Duration(seconds) * time.Second) }
+	return []Entry{
+		{Timestamp: at(200), IP: "10.0.0.1", Path: "/api"}, // fora de ordem: bloqueio já expirou
+		{Timestamp: at(0), IP: "10.0.0.1", Path: "/api"},
+		{Timestamp: at(1), IP: "10.0.0.1", Path: "/api"},
+		{Timestamp: at(2), IP: "10.0.0.1", Path: "/api"},  // acima do limite: bloqueia até 122s
+		{Timestamp: at(61), IP: "10.0.0.1", Path: "/api"}, // nova janela, ainda bloqueado
+		{Timestamp: at(5), IP: "10.0.0.2", Token: "premium_token_123", Path: "/orders"},
+		{Timestamp: at(6), IP: "10.0.0.2", Token: "premium_token_123", Path: "/orders"},
+	}
+}
+
+func TestSimulator_Run(t *testing.T) {
+	// Arrange
+	simulator, err := NewSimulator(replayConfig(), storage.AlgorithmFixedWindow, logger.NewLogger("error", "text"))
+	require.NoError(t, err)
+	defer simulator.Close()
+
+	// Act
+	report, err := simulator.Run(context.Background(), replayTraffic())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 7, report.Requests)
+	assert.Equal(t, 4, report.Allowed)
+	assert.Equal(t, 3, report.Rejected)
+	assert.Equal(t, 0, report.Errors)
+
+	allowed := make([]bool, len(report.Decisions))
+	for i, decision := range report.Decisions {
+		allowed[i] = decision.Allowed
+	}
+	assert.Equal(t, []bool{true, true, false, true, false, false, true}, allowed)
+	assert.Equal(t, "premium_***", report.Decisions[3].Token)
+	assert.Equal(t, domain.TokenLimiter, report.Decisions[4].LimiterType)
+
+	require.Len(t, report.Keys, 2)
+	assert.Equal(t, KeySummary{
+		Type:            domain.IPLimiter,
+		Key:             "10.0.0.1",
+		Requests:        5,
+		Rejected:        2,
+		FirstRejectedAt: &report.Decisions[2].Timestamp,
+	}, report.Keys[0])
+	assert.Equal(t, "premium_***", report.Keys[1].Key)
+	assert.Equal(t, time.Date(2024, 3, 1, 12, 3, 20, 0, time.UTC), report.End)
+}
+
+func TestSimulator_RunIsDeterministic(t *testing.T) {
+	// Arrange
+	run := func() *Report {
+		simulator, err := NewSimulator(replayConfig(), storage.AlgorithmSlidingLog, logger.NewLogger("error", "text"))
+		require.NoError(t, err)
+		defer simulator.Close()
+		report, err := simulator.Run(context.Background(), replayTraffic())
+		require.NoError(t, err)
+		return report
+	}
+
+	// Act
+	first, second := run(), run()
+
+	// Assert
+	assert.Equal(t, first, second)
+}
@@ -0,0 +1,145 @@
+package reports
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"rate-limiter/internal/domain"
+)
+
+// DefaultBlockLogCapacity limita os bloqueios mantidos para relatórios
+const DefaultBlockLogCapacity = 10000
+
+// BlockCSVHeader são as colunas do relatório CSV de bloqueios
+var BlockCSVHeader = []string{
+	"blocked_at", "blocked_until", "type", "key", "rule", "limit",
+	"request_count", "rejected_requests", "block_duration_seconds",
+}
+
+// BlockLog guarda os bloqueios mais recentes em memória (buffer circular)
+// Quando cheio, os registros mais antigos são descartados
+type BlockLog struct {
+	mutex   sync.Mutex
+	records []*domain.BlockRecord
+	next    int
+	size    int
+	active  map[string]*domain.BlockRecord // último bloqueio por chave
+
+	now func() time.Time
+}
+
+// NewBlockLog cria o registro de bloqueios; capacity <= 0 usa DefaultBlockLogCapacity
+func NewBlockLog(capacity int) *BlockLog {
+	if capacity <= 0 {
+		capacity = DefaultBlockLogCapacity
+	}
+	return &BlockLog{
+		records: make([]*domain.BlockRecord, capacity),
+		active:  make(map[string]*domain.BlockRecord),
+		now:     time.Now,
+	}
+}
+
+// RecordBlock implementa domain.BlockRecorder
+func (l *BlockLog) RecordBlock(record domain.BlockRecord) {
+	if record.BlockedAt.IsZero() {
+		record.BlockedAt = l.now()
+	}
+	record.BlockedAt = record.BlockedAt.UTC()
+	stored := &record
+	id := recordID(record.Key, record.Type)
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if evicted := l.records[l.next]; evicted != nil {
+		evictedID := recordID(evicted.Key, evicted.Type)
+		if l.active[evictedID] == evicted {
+			delete(l.active, evictedID)
+		}
+	}
+
+	l.records[l.next] = stored
+	l.next = (l.next + 1) % len(l.records)
+	if l.size < len(l.records) {
+		l.size++
+	}
+	l.active[id] = stored
+}
+
+// RecordRejected implementa domain.BlockRecorder
+// A recusa é atribuída ao bloqueio em vigor da chave, se houver
+func (l *BlockLog) RecordRejected(key string, limiterType domain.LimiterType) {
+	now := l.now()
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if record, exists := l.active[recordID(key, limiterType)]; exists && now.Before(record.BlockedUntil()) {
+		record.Rejected++
+	}
+}
+
+// Query retorna os bloqueios iniciados em [from, to), do mais antigo ao mais recente
+func (l *BlockLog) Query(from, to time.Time) []domain.BlockRecord {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	result := make([]domain.BlockRecord, 0)
+	start := (l.next - l.size + len(l.records)) % len(l.records)
+	for i := 0; i < l.size; i++ {
+		record := l.records[(start+i)%len(l.records)]
+		if record.BlockedAt.Before(from) || !record.BlockedAt.Before(to) {
+			continue
+		}
+		result = append(result, *record)
+	}
+	return result
+}
+
+// WriteBlocksCSV escreve o relatório de bloqueios em CSV (com cabeçalho)
+func WriteBlocksCSV(w io.Writer, records []domain.BlockRecord) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(BlockCSVHeader); err != nil {
+		return fmt.Errorf("failed to write report header: %w", err)
+	}
+
+	for _, record := range records {
+		row := []string{
+			record.BlockedAt.Format(time.RFC3339),
+			record.BlockedUntil().UTC().Format(time.RFC3339),
+			string(record.Type),
+			csvSafe(record.Key),
+			csvSafe(record.Rule),
+			strconv.Itoa(record.Limit),
+			strconv.Itoa(record.RequestCount),
+			strconv.Itoa(record.Rejected),
+			strconv.Itoa(record.Duration),
+		}
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write report row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvSafe neutraliza fórmulas em campos vindos do cliente (ex: tokens)
+// para que o relatório possa ser aberto com segurança em planilhas
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// recordID identifica a chave no índice de bloqueios ativos
+func recordID(key string, limiterType domain.LimiterType) string {
+	return fmt.Sprintf("%s:%s", limiterType, key)
+}
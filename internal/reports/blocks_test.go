@@ -0,0 +1,74 @@
+package reports
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/domain"
+)
+
+func TestBlockLog_CountsRejectedDuringBlock(t *testing.T) {
+	// Arrange
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	blockLog := NewBlockLog(10)
+	blockLog.now = func() time.Time { return now }
+	blockLog.RecordBlock(domain.BlockRecord{Key: "10.0.0.1", Type: domain.IPLimiter, Limit: 10, RequestCount: 11, Duration: 60})
+
+	// Act
+	blockLog.RecordRejected("10.0.0.1", domain.IPLimiter)
+	blockLog.RecordRejected("10.0.0.1", domain.IPLimiter)
+	blockLog.RecordRejected("10.0.0.1", domain.TokenLimiter) // outro tipo, outra chave
+	now = now.Add(2 * time.Minute)
+	blockLog.RecordRejected("10.0.0.1", domain.IPLimiter) // bloqueio já expirou
+
+	// Assert
+	records := blockLog.Query(now.Add(-time.Hour), now)
+	require.Len(t, records, 1)
+	assert.Equal(t, 2, records[0].Rejected)
+	assert.Equal(t, now.Add(-2*time.Minute), records[0].BlockedAt)
+}
+
+func TestBlockLog_DropsOldestWhenFull(t *testing.T) {
+	// Arrange
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	blockLog := NewBlockLog(3)
+
+	// Act
+	for i := 0; i < 5; i++ {
+		blockLog.RecordBlock(domain.BlockRecord{
+			Key: fmt.Sprintf("10.0.0.%d", i), Type: domain.IPLimiter, BlockedAt: start.Add(time.Duration(i) * time.Minute), Duration: 60,
+		})
+	}
+
+	// Assert: os três mais recentes, em ordem cronológica
+	records := blockLog.Query(start, start.Add(time.Hour))
+	require.Len(t, records, 3)
+	assert.Equal(t, "10.0.0.2", records[0].Key)
+	assert.Equal(t, "10.0.0.4", records[2].Key)
+	assert.Len(t, blockLog.active, 3)
+
+	// Período filtrado: [from, to)
+	records = blockLog.Query(start.Add(3*time.Minute), start.Add(4*time.Minute))
+	require.Len(t, records, 1)
+	assert.Equal(t, "10.0.0.3", records[0].Key)
+}
+
+func TestWriteBlocksCSV_NeutralizesFormulas(t *testing.T) {
+	var body bytes.Buffer
+	err := WriteBlocksCSV(&body, []domain.BlockRecord{{
+		Key: "=HYPERLINK(\"http://evil\")", Type: domain.TokenLimiter, Rule: "Token limit", Limit: 5,
+		RequestCount: 6, BlockedAt: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), Duration: 30,
+	}})
+
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(body.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, strings.Join(BlockCSVHeader, ","), lines[0])
+	assert.Contains(t, lines[1], `"'=HYPERLINK(""http://evil"")"`)
+}
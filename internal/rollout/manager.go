@@ -0,0 +1,251 @@
+package rollout
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"rate-limiter/internal/domain"
+)
+
+// wildcardKey identifica rollouts que cobrem todas as chaves de um tipo
+const wildcardKey = "*"
+
+var (
+	// ErrInvalidRollout indica um rollout mal definido
+	ErrInvalidRollout = errors.New("invalid rule rollout")
+	// ErrRolloutNotFound indica que não há rollout com o ID informado
+	ErrRolloutNotFound = errors.New("rule rollout not found")
+)
+
+// VersionStats contabiliza as decisões de uma versão da regra
+type VersionStats struct {
+	Allowed uint64 `json:"allowed"`
+	Denied  uint64 `json:"denied"`
+}
+
+// Snapshot é o estado de um rollout com as decisões por versão
+type Snapshot struct {
+	domain.RuleRollout
+	Stable VersionStats `json:"stable"`
+	Canary VersionStats `json:"canary"`
+}
+
+// counters guarda as decisões de uma versão sem lock no caminho quente
+type counters struct {
+	allowed atomic.Uint64
+	denied  atomic.Uint64
+}
+
+func (c *counters) snapshot() VersionStats {
+	return VersionStats{Allowed: c.allowed.Load(), Denied: c.denied.Load()}
+}
+
+// entry é um rollout registrado
+type entry struct {
+	rollout domain.RuleRollout
+	stable  counters
+	canary  counters
+}
+
+// Manager mantém os rollouts canário de regras
+// Um rollout de chave específica prevalece sobre o rollout do tipo inteiro
+type Manager struct {
+	logger domain.Logger
+
+	mutex    sync.RWMutex
+	rollouts map[string]*entry
+
+	now func() time.Time
+}
+
+// NewManager cria o gerenciador de rollouts
+func NewManager(logger domain.Logger) *Manager {
+	return &Manager{
+		logger:   logger,
+		rollouts: make(map[string]*entry),
+		now:      time.Now,
+	}
+}
+
+// RolloutID monta o ID do rollout a partir do escopo (chave vazia = tipo inteiro)
+func RolloutID(limiterType domain.LimiterType, key string) string {
+	if key == "" {
+		key = wildcardKey
+	}
+	return fmt.Sprintf("%s:%s", limiterType, key)
+}
+
+// Start inicia ou ajusta o rollout de uma nova versão da regra
+// Mudar o limite reinicia as métricas; mudar apenas o percentual as preserva
+func (m *Manager) Start(rollout domain.RuleRollout) (domain.RuleRollout, error) {
+	rollout.Key = strings.TrimSpace(rollout.Key)
+	if err := validateRollout(rollout); err != nil {
+		return domain.RuleRollout{}, err
+	}
+
+	now := m.now()
+	rollout.ID = RolloutID(rollout.Type, rollout.Key)
+	rollout.Promoted = false
+	rollout.CreatedAt = now
+	rollout.UpdatedAt = now
+
+	m.mutex.Lock()
+	if existing, exists := m.rollouts[rollout.ID]; exists && existing.rollout.Limit == rollout.Limit {
+		rollout.CreatedAt = existing.rollout.CreatedAt
+		existing.rollout = rollout
+	} else {
+		m.rollouts[rollout.ID] = &entry{rollout: rollout}
+	}
+	m.mutex.Unlock()
+
+	m.log("Rule rollout started", rollout)
+	return rollout, nil
+}
+
+// Promote conclui o rollout: a nova versão passa a valer para todo o tráfego
+func (m *Manager) Promote(id string) (domain.RuleRollout, error) {
+	m.mutex.Lock()
+	existing, exists := m.rollouts[id]
+	if !exists {
+		m.mutex.Unlock()
+		return domain.RuleRollout{}, fmt.Errorf("%w: %s", ErrRolloutNotFound, id)
+	}
+	existing.rollout.Percent = 100
+	existing.rollout.Promoted = true
+	existing.rollout.UpdatedAt = m.now()
+	rollout := existing.rollout
+	m.mutex.Unlock()
+
+	m.log("Rule rollout promoted", rollout)
+	return rollout, nil
+}
+
+// Abort remove o rollout e devolve todo o tráfego à versão estável
+func (m *Manager) Abort(id string) bool {
+	m.mutex.Lock()
+	existing, exists := m.rollouts[id]
+	delete(m.rollouts, id)
+	m.mutex.Unlock()
+
+	if exists {
+		m.log("Rule rollout aborted", existing.rollout)
+	}
+	return exists
+}
+
+// List retorna os rollouts com as decisões por versão, ordenados por ID
+func (m *Manager) List() []Snapshot {
+	m.mutex.RLock()
+	snapshots := make([]Snapshot, 0, len(m.rollouts))
+	for _, existing := range m.rollouts {
+		snapshots = append(snapshots, Snapshot{
+			RuleRollout: existing.rollout,
+			Stable:      existing.stable.snapshot(),
+			Canary:      existing.canary.snapshot(),
+		})
+	}
+	m.mutex.RUnlock()
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].ID < snapshots[j].ID
+	})
+	return snapshots
+}
+
+// ResolveRollout implementa domain.RuleRolloutProvider
+func (m *Manager) ResolveRollout(key string, limiterType domain.LimiterType) (domain.RuleRollout, string, bool) {
+	m.mutex.RLock()
+	existing, exists := m.rollouts[RolloutID(limiterType, key)]
+	if !exists {
+		existing, exists = m.rollouts[RolloutID(limiterType, "")]
+	}
+	var rollout domain.RuleRollout
+	if exists {
+		rollout = existing.rollout
+	}
+	m.mutex.RUnlock()
+
+	if !exists {
+		return domain.RuleRollout{}, "", false
+	}
+
+	if rollout.Promoted || inCanary(rollout.ID, key, rollout.Percent) {
+		return rollout, domain.RuleVersionCanary, true
+	}
+	return rollout, domain.RuleVersionStable, true
+}
+
+// RecordDecision implementa domain.RuleRolloutProvider
+func (m *Manager) RecordDecision(rolloutID, version string, allowed bool) {
+	m.mutex.RLock()
+	existing, exists := m.rollouts[rolloutID]
+	m.mutex.RUnlock()
+	if !exists {
+		return
+	}
+
+	target := &existing.stable
+	if version == domain.RuleVersionCanary {
+		target = &existing.canary
+	}
+	if allowed {
+		target.allowed.Add(1)
+	} else {
+		target.denied.Add(1)
+	}
+}
+
+// log registra mudanças de estado do rollout
+func (m *Manager) log(msg string, rollout domain.RuleRollout) {
+	if m.logger == nil {
+		return
+	}
+	m.logger.Info(msg, map[string]interface{}{
+		"rollout_id": rollout.ID,
+		"limit":      rollout.Limit,
+		"percent":    rollout.Percent,
+		"promoted":   rollout.Promoted,
+	})
+}
+
+// inCanary decide de forma determinística se a chave recebe a nova versão
+// O ID do rollout entra no hash para que rollouts diferentes não peguem sempre os mesmos clientes
+func inCanary(rolloutID, key string, percent int) bool {
+	if percent <= 0 {
+		return false
+	}
+	if percent >= 100 {
+		return true
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(rolloutID))
+	hash.Write([]byte{0})
+	hash.Write([]byte(key))
+	return int(hash.Sum32()%100) < percent
+}
+
+// validateRollout verifica escopo, limite e percentual
+func validateRollout(rollout domain.RuleRollout) error {
+	switch rollout.Type {
+	case domain.IPLimiter, domain.TokenLimiter:
+	default:
+		return fmt.Errorf("%w: type must be 'ip' or 'token'", ErrInvalidRollout)
+	}
+	if rollout.Key == wildcardKey {
+		return fmt.Errorf("%w: use an empty key to target every key", ErrInvalidRollout)
+	}
+	if rollout.Limit <= 0 {
+		return fmt.Errorf("%w: limit must be greater than 0", ErrInvalidRollout)
+	}
+	if rollout.Percent < 0 || rollout.Percent > 100 {
+		return fmt.Errorf("%w: percent must be between 0 and 100", ErrInvalidRollout)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package rollout
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/domain"
+)
+
+func TestManager_SplitsTrafficByPercent(t *testing.T) {
+	// Arrange
+	manager := NewManager(nil)
+	rollout, err := manager.Start(domain.RuleRollout{Type: domain.IPLimiter, Limit: 20, Percent: 25})
+	require.NoError(t, err)
+	assert.Equal(t, "ip:*", rollout.ID)
+
+	// Act
+	canary := 0
+	for i := 0; i < 1000; i++ {
+		key := fmt.Sprintf("10.0.%d.%d", i/256, i%256)
+		_, version, found := manager.ResolveRollout(key, domain.IPLimiter)
+		require.True(t, found)
+		if version == domain.RuleVersionCanary {
+			canary++
+		}
+	}
+
+	// Assert: ~25% do tráfego na nova versão
+	assert.InDelta(t, 250, canary, 60)
+
+	// Mesma chave sempre na mesma versão
+	_, first, _ := manager.ResolveRollout("10.0.0.1", domain.IPLimiter)
+	_, second, _ := manager.ResolveRollout("10.0.0.1", domain.IPLimiter)
+	assert.Equal(t, first, second)
+
+	// Tipo fora do escopo não é afetado
+	_, _, found := manager.ResolveRollout("premium", domain.TokenLimiter)
+	assert.False(t, found)
+}
+
+func TestManager_KeyRolloutTakesPrecedence(t *testing.T) {
+	manager := NewManager(nil)
+	_, err := manager.Start(domain.RuleRollout{Type: domain.TokenLimiter, Limit: 50, Percent: 0})
+	require.NoError(t, err)
+	_, err = manager.Start(domain.RuleRollout{Type: domain.TokenLimiter, Key: "partner", Limit: 500, Percent: 100})
+	require.NoError(t, err)
+
+	rollout, version, found := manager.ResolveRollout("partner", domain.TokenLimiter)
+	assert.True(t, found)
+	assert.Equal(t, "token:partner", rollout.ID)
+	assert.Equal(t, domain.RuleVersionCanary, version)
+
+	rollout, version, _ = manager.ResolveRollout("other", domain.TokenLimiter)
+	assert.Equal(t, "token:*", rollout.ID)
+	assert.Equal(t, domain.RuleVersionStable, version)
+}
+
+func TestManager_PromoteAndAbort(t *testing.T) {
+	// Arrange
+	manager := NewManager(nil)
+	_, err := manager.Start(domain.RuleRollout{Type: domain.IPLimiter, Limit: 20, Percent: 0})
+	require.NoError(t, err)
+	manager.RecordDecision("ip:*", domain.RuleVersionStable, false)
+	manager.RecordDecision("ip:*", domain.RuleVersionCanary, true)
+
+	// Act
+	promoted, err := manager.Promote("ip:*")
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, promoted.Promoted)
+	assert.Equal(t, 100, promoted.Percent)
+	_, version, _ := manager.ResolveRollout("10.0.0.1", domain.IPLimiter)
+	assert.Equal(t, domain.RuleVersionCanary, version)
+
+	snapshots := manager.List()
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, VersionStats{Denied: 1}, snapshots[0].Stable)
+	assert.Equal(t, VersionStats{Allowed: 1}, snapshots[0].Canary)
+
+	_, err = manager.Promote("token:*")
+	assert.ErrorIs(t, err, ErrRolloutNotFound)
+
+	assert.True(t, manager.Abort("ip:*"))
+	assert.False(t, manager.Abort("ip:*"))
+	_, _, found := manager.ResolveRollout("10.0.0.1", domain.IPLimiter)
+	assert.False(t, found)
+}
+
+func TestManager_StartResetsStatsWhenLimitChanges(t *testing.T) {
+	manager := NewManager(nil)
+	_, err := manager.Start(domain.RuleRollout{Type: domain.IPLimiter, Limit: 20, Percent: 10})
+	require.NoError(t, err)
+	manager.RecordDecision("ip:*", domain.RuleVersionCanary, false)
+
+	// Apenas o percentual muda: métricas preservadas
+	_, err = manager.Start(domain.RuleRollout{Type: domain.IPLimiter, Limit: 20, Percent: 50})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1), manager.List()[0].Canary.Denied)
+
+	// Nova versão da regra: métricas reiniciadas
+	_, err = manager.Start(domain.RuleRollout{Type: domain.IPLimiter, Limit: 30, Percent: 50})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), manager.List()[0].Canary.Denied)
+}
+
+func TestManager_StartValidation(t *testing.T) {
+	manager := NewManager(nil)
+
+	tests := []struct {
+		name    string
+		rollout domain.RuleRollout
+	}{
+		{name: "Unknown type", rollout: domain.RuleRollout{Type: "user", Limit: 10, Percent: 10}},
+		{name: "Invalid limit", rollout: domain.RuleRollout{Type: domain.IPLimiter, Limit: 0, Percent: 10}},
+		{name: "Percent out of range", rollout: domain.RuleRollout{Type: domain.IPLimiter, Limit: 10, Percent: 101}},
+		{name: "Wildcard key", rollout: domain.RuleRollout{Type: domain.IPLimiter, Key: "*", Limit: 10, Percent: 10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := manager.Start(tt.rollout)
+			assert.ErrorIs(t, err, ErrInvalidRollout)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse_InvalidExpressions(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"a * * * *",
+		"10-5 * * * *",
+	}
+
+	for _, expr := range tests {
+		t.Run(expr, func(t *testing.T) {
+			_, err := Parse(expr)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestSchedule_Next(t *testing.T) {
+	base := time.Date(2025, 1, 15, 10, 30, 45, 0, time.UTC) // quarta-feira
+
+	tests := []struct {
+		name     string
+		expr     string
+		from     time.Time
+		expected time.Time
+	}{
+		{
+			name:     "Daily at midnight UTC",
+			expr:     "@daily",
+			from:     base,
+			expected: time.Date(2025, 1, 16, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Hourly",
+			expr:     "@hourly",
+			from:     base,
+			expected: time.Date(2025, 1, 15, 11, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Every 15 minutes",
+			expr:     "*/15 * * * *",
+			from:     base,
+			expected: time.Date(2025, 1, 15, 10, 45, 0, 0, time.UTC),
+		},
+		{
+			name:     "Monthly rolls over year",
+			expr:     "@monthly",
+			from:     time.Date(2025, 12, 20, 0, 0, 0, 0, time.UTC),
+			expected: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Weekly on Sunday (7)",
+			expr:     "0 0 * * 7",
+			from:     base,
+			expected: time.Date(2025, 1, 19, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Weekdays at 09:00",
+			expr:     "0 9 * * 1-5",
+			from:     time.Date(2025, 1, 17, 10, 0, 0, 0, time.UTC), // sexta-feira
+			expected: time.Date(2025, 1, 20, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Day of month or day of week",
+			expr:     "0 0 1 * 1",
+			from:     base,
+			expected: time.Date(2025, 1, 20, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Strictly after current instant",
+			expr:     "30 10 * * *",
+			from:     time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC),
+			expected: time.Date(2025, 1, 16, 10, 30, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := Parse(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, schedule.Next(tt.from))
+		})
+	}
+}
@@ -0,0 +1,210 @@
+// Package security classifica os eventos de enforcement por severidade e os
+// grava em um canal de log próprio, para que regras de SIEM alertem apenas nos
+// padrões graves sem filtrar o log da aplicação
+package security
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/events"
+	"rate-limiter/internal/service"
+)
+
+// Severity é o nível de gravidade de um evento de segurança
+type Severity int
+
+const (
+	SeverityLow Severity = iota + 1
+	SeverityMedium
+	SeverityHigh
+	SeverityCritical
+)
+
+// String retorna o nome da severidade usado no campo "severity" do log
+func (s Severity) String() string {
+	switch s {
+	case SeverityLow:
+		return "low"
+	case SeverityMedium:
+		return "medium"
+	case SeverityHigh:
+		return "high"
+	case SeverityCritical:
+		return "critical"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseSeverity interpreta o nome de uma severidade; vazio equivale a "low"
+func ParseSeverity(value string) (Severity, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "low":
+		return SeverityLow, nil
+	case "medium":
+		return SeverityMedium, nil
+	case "high":
+		return SeverityHigh, nil
+	case "critical":
+		return SeverityCritical, nil
+	default:
+		return 0, fmt.Errorf("invalid severity: %s", value)
+	}
+}
+
+// Categorias dos eventos de segurança (campo "category" do log)
+const (
+	CategorySoftLimit     = "soft_limit_warning"
+	CategoryBlock         = "block"
+	CategoryRepeatedBlock = "repeated_block"
+	CategoryAdminOverride = "admin_override"
+)
+
+const (
+	// DefaultRepeatThreshold é o número de bloqueios da mesma chave que caracteriza repetição
+	DefaultRepeatThreshold = 3
+	// DefaultRepeatWindow é o período em que os bloqueios repetidos são contados
+	DefaultRepeatWindow = 10 * time.Minute
+)
+
+// Config contém as configurações do log de segurança
+type Config struct {
+	MinSeverity     Severity      // Eventos abaixo são descartados (0 = SeverityLow)
+	RepeatThreshold int           // Bloqueios na janela para repeated_block (0 = DefaultRepeatThreshold)
+	RepeatWindow    time.Duration // Janela dos bloqueios repetidos (0 = DefaultRepeatWindow)
+}
+
+// Log classifica os eventos publicados pelo serviço e os grava no logger de segurança:
+//
+//	rate_limit.soft_limit              -> soft_limit_warning (low)
+//	rate_limit.blocked                 -> block (medium)
+//	rate_limit.blocked repetido        -> repeated_block (high)
+//	rate_limit.override / .unblocked   -> admin_override (medium)
+//
+// Cada entrada leva channel=security, category e severity; o nível do log
+// acompanha a severidade (info, warning, error)
+type Log struct {
+	logger domain.Logger
+	config Config
+	now    func() time.Time
+
+	mutex     sync.Mutex
+	blocks    map[string][]time.Time // chave -> bloqueios recentes, do mais antigo ao mais novo
+	lastSweep time.Time
+}
+
+// NewLog cria o log de segurança escrevendo em logger
+func NewLog(logger domain.Logger, config Config) *Log {
+	if config.MinSeverity == 0 {
+		config.MinSeverity = SeverityLow
+	}
+	if config.RepeatThreshold <= 0 {
+		config.RepeatThreshold = DefaultRepeatThreshold
+	}
+	if config.RepeatWindow <= 0 {
+		config.RepeatWindow = DefaultRepeatWindow
+	}
+
+	return &Log{
+		logger: logger,
+		config: config,
+		now:    time.Now,
+		blocks: make(map[string][]time.Time),
+	}
+}
+
+// Handle classifica e grava o evento; use como Handler do Bus
+// Eventos de outros tipos são ignorados
+func (l *Log) Handle(event events.Event) {
+	category, severity, ok := l.classify(event)
+	if !ok || severity < l.config.MinSeverity {
+		return
+	}
+
+	fields := map[string]interface{}{
+		"channel":    "security",
+		"category":   category,
+		"severity":   severity.String(),
+		"event_type": event.Type,
+	}
+	if event.RequestID != "" {
+		fields["request_id"] = event.RequestID
+	}
+	for key, value := range event.Data {
+		fields[key] = value
+	}
+
+	switch {
+	case severity >= SeverityHigh:
+		l.logger.Error("Security event", nil, fields)
+	case severity == SeverityMedium:
+		l.logger.Warn("Security event", fields)
+	default:
+		l.logger.Info("Security event", fields)
+	}
+}
+
+// classify retorna a categoria e a severidade do evento
+func (l *Log) classify(event events.Event) (string, Severity, bool) {
+	switch event.Type {
+	case service.EventKeySoftLimit:
+		return CategorySoftLimit, SeverityLow, true
+	case service.EventKeyBlocked:
+		if l.recordBlock(event) {
+			return CategoryRepeatedBlock, SeverityHigh, true
+		}
+		return CategoryBlock, SeverityMedium, true
+	case service.EventKeyOverride, service.EventKeyUnblocked:
+		return CategoryAdminOverride, SeverityMedium, true
+	default:
+		return "", 0, false
+	}
+}
+
+// recordBlock registra o bloqueio e informa se a chave atingiu o limiar de repetição
+// Tokens chegam mascarados: tokens com o mesmo prefixo são contados juntos
+func (l *Log) recordBlock(event events.Event) bool {
+	key := fmt.Sprintf("%v:%v", event.Data["limiter_type"], event.Data["key"])
+	now := l.now()
+	cutoff := now.Add(-l.config.RepeatWindow)
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.sweep(now, cutoff)
+
+	recent := pruneBefore(l.blocks[key], cutoff)
+	recent = append(recent, now)
+	if len(recent) > l.config.RepeatThreshold {
+		recent = recent[len(recent)-l.config.RepeatThreshold:]
+	}
+	l.blocks[key] = recent
+
+	return len(recent) >= l.config.RepeatThreshold
+}
+
+// sweep remove, no máximo uma vez por janela, as chaves sem bloqueios recentes
+func (l *Log) sweep(now, cutoff time.Time) {
+	if now.Sub(l.lastSweep) < l.config.RepeatWindow {
+		return
+	}
+	l.lastSweep = now
+
+	for key, times := range l.blocks {
+		if len(pruneBefore(times, cutoff)) == 0 {
+			delete(l.blocks, key)
+		}
+	}
+}
+
+// pruneBefore descarta os instantes anteriores a cutoff
+func pruneBefore(times []time.Time, cutoff time.Time) []time.Time {
+	for len(times) > 0 && times[0].Before(cutoff) {
+		times = times[1:]
+	}
+	return times
+}
@@ -0,0 +1,127 @@
+package security
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/events"
+	"rate-limiter/internal/logger"
+	"rate-limiter/internal/service"
+)
+
+// newTestLog cria o log de segurança escrevendo JSON em um buffer, com relógio controlado
+func newTestLog(config Config) (*Log, *bytes.Buffer, *time.Time) {
+	var buffer bytes.Buffer
+	log := NewLog(logger.NewLoggerWithOutput("info", "json", &buffer), config)
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	log.now = func() time.Time { return now }
+	return log, &buffer, &now
+}
+
+// entries decodifica as linhas JSON gravadas no buffer
+func entries(t *testing.T, buffer *bytes.Buffer) []map[string]interface{} {
+	var result []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		result = append(result, entry)
+	}
+	return result
+}
+
+func blocked(key string) events.Event {
+	return events.Event{
+		Type: service.EventKeyBlocked,
+		Data: map[string]interface{}{"key": key, "limiter_type": "ip", "limit": 10},
+	}
+}
+
+func TestLog_ClassifiesEvents(t *testing.T) {
+	// Arrange
+	log, buffer, _ := newTestLog(Config{})
+
+	// Act
+	log.Handle(events.Event{Type: service.EventKeySoftLimit, RequestID: "req-1", Data: map[string]interface{}{"key": "10.0.0.1", "limiter_type": "ip"}})
+	log.Handle(blocked("10.0.0.1"))
+	log.Handle(events.Event{Type: service.EventKeyOverride, Data: map[string]interface{}{"key": "premium_***", "limiter_type": "token"}})
+	log.Handle(events.Event{Type: "maintenance.started"})
+
+	// Assert: eventos fora do enforcement não entram no canal
+	logged := entries(t, buffer)
+	require.Len(t, logged, 3)
+
+	assert.Equal(t, "security", logged[0]["channel"])
+	assert.Equal(t, CategorySoftLimit, logged[0]["category"])
+	assert.Equal(t, "low", logged[0]["severity"])
+	assert.Equal(t, "info", logged[0]["level"])
+	assert.Equal(t, "req-1", logged[0]["request_id"])
+
+	assert.Equal(t, CategoryBlock, logged[1]["category"])
+	assert.Equal(t, "medium", logged[1]["severity"])
+	assert.Equal(t, "warning", logged[1]["level"])
+	assert.Equal(t, "10.0.0.1", logged[1]["key"])
+
+	assert.Equal(t, CategoryAdminOverride, logged[2]["category"])
+	assert.Equal(t, "premium_***", logged[2]["key"])
+}
+
+func TestLog_RepeatedBlocks(t *testing.T) {
+	// Arrange
+	log, buffer, now := newTestLog(Config{RepeatThreshold: 3, RepeatWindow: 10 * time.Minute})
+
+	// Act: três bloqueios da mesma chave na janela, um de outra chave e um após a janela
+	log.Handle(blocked("10.0.0.1"))
+	*now = now.Add(4 * time.Minute)
+	log.Handle(blocked("10.0.0.1"))
+	log.Handle(blocked("10.0.0.2"))
+	*now = now.Add(4 * time.Minute)
+	log.Handle(blocked("10.0.0.1"))
+	*now = now.Add(9 * time.Minute)
+	log.Handle(blocked("10.0.0.1"))
+
+	// Assert
+	logged := entries(t, buffer)
+	require.Len(t, logged, 5)
+	categories := make([]interface{}, 0, len(logged))
+	for _, entry := range logged {
+		categories = append(categories, entry["category"])
+	}
+	assert.Equal(t, []interface{}{CategoryBlock, CategoryBlock, CategoryBlock, CategoryRepeatedBlock, CategoryBlock}, categories)
+	assert.Equal(t, "high", logged[3]["severity"])
+	assert.Equal(t, "error", logged[3]["level"])
+}
+
+func TestLog_MinSeverity(t *testing.T) {
+	// Arrange
+	log, buffer, _ := newTestLog(Config{MinSeverity: SeverityHigh, RepeatThreshold: 2})
+
+	// Act
+	log.Handle(events.Event{Type: service.EventKeySoftLimit, Data: map[string]interface{}{"key": "10.0.0.1"}})
+	log.Handle(blocked("10.0.0.1"))
+	log.Handle(blocked("10.0.0.1"))
+
+	// Assert: apenas o bloqueio repetido passa do filtro
+	logged := entries(t, buffer)
+	require.Len(t, logged, 1)
+	assert.Equal(t, CategoryRepeatedBlock, logged[0]["category"])
+}
+
+func TestParseSeverity(t *testing.T) {
+	for value, expected := range map[string]Severity{"": SeverityLow, "low": SeverityLow, "Medium": SeverityMedium, "high": SeverityHigh, "critical": SeverityCritical} {
+		severity, err := ParseSeverity(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, expected, severity, value)
+	}
+
+	_, err := ParseSeverity("urgent")
+	assert.Error(t, err)
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"rate-limiter/internal/domain"
+)
+
+// singleRoundTrip informa se a regra é uma janela fixa simples, que o storage
+// verifica em uma única operação (sem hierarquia, baldes ou reset absoluto)
+func singleRoundTrip(rule *domain.RateLimitRule) bool {
+	return len(rule.Parents) == 0 &&
+		!(rule.RefillRate > 0 && rule.Limit > 0) &&
+		!(rule.LeakRate > 0 && rule.Limit > 0) &&
+		rule.ResetSchedule == "" &&
+		!rule.AlignWindow
+}
+
+// checkAtomic verifica o bloqueio, conta a requisição e bloqueia a chave em uma
+// única chamada ao storage, sem a janela entre IsBlocked, Increment e Block em que
+// requisições concorrentes passavam pela chave recém-estourada. Retorna
+// domain.ErrCheckUnsupported para o chamador seguir o fluxo em três etapas
+func (s *RateLimiterService) checkAtomic(ctx context.Context, checker domain.AtomicLimitChecker, storageKey, key string, limiterType domain.LimiterType, rule *domain.RateLimitRule, debug bool) (*domain.RateLimitResult, error) {
+	trace := s.decisionTraceFrom(ctx)
+	stepStart := trace.begin()
+	blockDuration := time.Duration(rule.BlockDuration) * time.Second
+	check, err := checker.CheckAndIncrement(ctx, storageKey, rule.Limit, time.Duration(rule.Window)*time.Second, blockDuration)
+	if errors.Is(err, domain.ErrCheckUnsupported) {
+		return nil, err
+	}
+	if trace != nil {
+		fields := map[string]interface{}{"limit": rule.Limit}
+		if check != nil {
+			fields["blocked"] = check.Blocked
+			fields["count"] = check.Count
+			fields["blocked_until"] = check.BlockedUntil
+		}
+		trace.step("check_and_increment", stepStart, fields, err)
+	}
+	if err != nil {
+		s.logger.Error("Failed to check rate limit", err, map[string]interface{}{
+			"storage_key": storageKey,
+			"limit":       rule.Limit,
+		})
+		return nil, fmt.Errorf("failed to check rate limit: %w", err)
+	}
+
+	if check.Blocked {
+		return s.rejectBlocked(rule, key, storageKey, limiterType, check.BlockedUntil), nil
+	}
+	if check.Count > rule.Limit {
+		return s.rejectExceeded(ctx, rule, key, storageKey, limiterType, check.Count, check.WindowStart, s.now().Add(blockDuration)), nil
+	}
+	return s.allowCounted(ctx, rule, key, storageKey, limiterType, check.Count, check.WindowStart, debug), nil
+}
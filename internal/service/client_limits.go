@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/storage"
+)
+
+// defaultTokenDescription descreve a regra de um token sem configuração própria,
+// sem repetir o token recebido
+const defaultTokenDescription = "Default token limit"
+
+// ClientLimits monta os limites que contam as requisições do token: o contador
+// do token, ou o da família com quem ele divide a cota, e os níveis da hierarquia
+// acima dele. Um token desconhecido recebe a regra padrão na mesma forma de um
+// token configurado com ela, sem revelar se o token existe
+func (s *RateLimiterService) ClientLimits(ctx context.Context, token string) ([]domain.ClientLimit, error) {
+	rule, err := s.GetConfig(ctx, token, domain.TokenLimiter)
+	if err != nil {
+		return nil, err
+	}
+	_, exists, err := s.lookupTokenConfig(ctx, token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve token config: %w", err)
+	}
+	if !exists {
+		rule.Description = strings.Replace(rule.Description, "Default token limit for "+token, defaultTokenDescription, 1)
+	}
+	// Overrides e janelas de manutenção citam a chave: o token não volta em claro
+	rule.Description = strings.ReplaceAll(rule.Description, token, s.maskToken(token))
+
+	store := s.storageFor(rule)
+	scope := string(domain.TokenLimiter)
+	if rule.Family != "" {
+		scope = "family"
+	}
+	status, err := s.clientStatus(ctx, store, s.counterKey(rule, s.buildStorageKey(token, domain.TokenLimiter)))
+	if err != nil {
+		return nil, err
+	}
+	limits := []domain.ClientLimit{{Scope: scope, Rule: rule, Status: status}}
+
+	// Os níveis contam em janela fixa, com a janela da regra do token
+	for _, parent := range rule.Parents {
+		status, err := s.clientStatus(ctx, store, storage.BuildLevelKey(parent.Level, parent.Name, s.keyOptions...))
+		if err != nil {
+			return nil, err
+		}
+		limits = append(limits, domain.ClientLimit{
+			Scope: parent.Level + ":" + parent.Name,
+			Rule: &domain.RateLimitRule{
+				Type:        domain.TokenLimiter,
+				Limit:       parent.Limit,
+				Window:      rule.Window,
+				Description: fmt.Sprintf("Shared %s limit", parent.Level),
+				Disabled:    rule.Disabled,
+			},
+			Status: status,
+		})
+	}
+	return limits, nil
+}
+
+// clientStatus lê o uso de um contador do cliente
+func (s *RateLimiterService) clientStatus(ctx context.Context, store domain.RateLimiterStorage, storageKey string) (*domain.RateLimitStatus, error) {
+	status, err := store.Get(ctx, storageKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status: %w", err)
+	}
+	if status != nil {
+		status.Type = domain.TokenLimiter
+	}
+	return status, nil
+}
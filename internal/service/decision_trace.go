@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"rate-limiter/internal/domain"
+)
+
+// decisionTraceKey guarda no contexto a decisão em gravação
+type decisionTraceKey struct{}
+
+// decisionTrace acumula as etapas de uma decisão de chave em depuração.
+// Os métodos aceitam receptor nil: sem gravação nada é montado
+type decisionTrace struct {
+	trace domain.DecisionTrace
+}
+
+// startDecisionTrace inicia a gravação quando a chave está em depuração
+func (s *RateLimiterService) startDecisionTrace(ctx context.Context, key string, limiterType domain.LimiterType) (context.Context, *decisionTrace) {
+	if s.tracer == nil || !s.tracer.Tracing(key, limiterType) {
+		return ctx, nil
+	}
+
+	trace := &decisionTrace{trace: domain.DecisionTrace{
+		Key:       key,
+		Type:      limiterType,
+		StartedAt: time.Now(),
+	}}
+	return context.WithValue(ctx, decisionTraceKey{}, trace), trace
+}
+
+// decisionTraceFrom retorna a decisão em gravação no contexto, se houver
+func (s *RateLimiterService) decisionTraceFrom(ctx context.Context) *decisionTrace {
+	if s.tracer == nil {
+		return nil
+	}
+	trace, _ := ctx.Value(decisionTraceKey{}).(*decisionTrace)
+	return trace
+}
+
+// begin marca o início de uma etapa
+func (t *decisionTrace) begin() time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// step registra uma etapa iniciada em start
+func (t *decisionTrace) step(name string, start time.Time, result map[string]interface{}, err error) {
+	if t == nil {
+		return
+	}
+
+	step := domain.DecisionStep{
+		Name:           name,
+		Result:         result,
+		DurationMicros: time.Since(start).Microseconds(),
+	}
+	if err != nil {
+		step.Error = err.Error()
+	}
+	t.trace.Steps = append(t.trace.Steps, step)
+}
+
+// rule registra a regra aplicada e a chave de storage
+func (t *decisionTrace) rule(rule *domain.RateLimitRule, storageKey string) {
+	if t == nil {
+		return
+	}
+	copied := *rule
+	t.trace.Rule = &copied
+	t.trace.StorageKey = storageKey
+}
+
+// finish encerra a gravação com o resultado da decisão
+func (t *decisionTrace) finish(result *domain.RateLimitResult, err error) domain.DecisionTrace {
+	trace := t.trace
+	trace.DurationMicros = time.Since(trace.StartedAt).Microseconds()
+	if err != nil {
+		trace.Error = err.Error()
+	}
+	if result != nil {
+		trace.Allowed = result.Allowed
+		trace.Remaining = result.Remaining
+		trace.BlockedUntil = result.BlockedUntil
+		trace.ReleaseAt = result.ReleaseAt
+	}
+	return trace
+}
+
+// storageStatusFields resume o estado retornado pelo storage para a gravação
+func storageStatusFields(status *domain.RateLimitStatus) map[string]interface{} {
+	if status == nil {
+		return nil
+	}
+	return map[string]interface{}{
+		"count":         status.Count,
+		"blocked":       status.IsBlocked,
+		"blocked_until": status.BlockedUntil,
+		"reset_at":      status.ResetAt,
+		"release_at":    status.ReleaseAt,
+	}
+}
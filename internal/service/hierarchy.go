@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/storage"
+)
+
+// limitParents monta os níveis acima do token: o projeto e, se houver, a organização
+func limitParents(config *domain.RateLimitConfig, project string) []domain.LimitLevel {
+	projectConfig, ok := config.Projects[project]
+	if !ok {
+		return nil
+	}
+
+	parents := []domain.LimitLevel{{Level: domain.LimitLevelProject, Name: project, Limit: projectConfig.Limit}}
+	if orgConfig, ok := config.Organizations[projectConfig.Organization]; ok && projectConfig.Organization != "" {
+		parents = append(parents, domain.LimitLevel{Level: domain.LimitLevelOrganization, Name: projectConfig.Organization, Limit: orgConfig.Limit})
+	}
+	return parents
+}
+
+// checkHierarchy conta a requisição no token e em todos os níveis acima dele em
+// uma única operação atômica: ou todos os contadores sobem, ou nenhum. Estourar o
+// limite do token bloqueia a chave por BlockDuration; estourar o de um nível acima
+// só nega a requisição até o fim da janela daquele nível, já que outros tokens
+// também consomem dele. O resultado reporta o nível mais restrito em Scope
+func (s *RateLimiterService) checkHierarchy(ctx context.Context, store domain.RateLimiterStorage, storageKey, key string, limiterType domain.LimiterType, rule *domain.RateLimitRule) (*domain.RateLimitResult, error) {
+	counters := make([]domain.HierarchyCounter, 0, len(rule.Parents)+1)
+	counters = append(counters, domain.HierarchyCounter{Key: storageKey, Limit: rule.Limit})
+	scopes := []string{string(domain.TokenLimiter)}
+	for _, parent := range rule.Parents {
+		counters = append(counters, domain.HierarchyCounter{
+			Key:   storage.BuildLevelKey(parent.Level, parent.Name, s.keyOptions...),
+			Limit: parent.Limit,
+		})
+		scopes = append(scopes, parent.Level+":"+parent.Name)
+	}
+
+	trace := s.decisionTraceFrom(ctx)
+	stepStart := trace.begin()
+	var levels *domain.HierarchyResult
+	incrementer, ok := store.(domain.HierarchicalIncrementer)
+	err := domain.ErrHierarchyUnsupported
+	if ok {
+		levels, err = incrementer.IncrementHierarchy(ctx, counters, time.Duration(rule.Window)*time.Second)
+	}
+	if trace != nil {
+		fields := map[string]interface{}{"levels": len(counters)}
+		if levels != nil {
+			fields["counts"] = levels.Counts
+			fields["exceeded"] = levels.Exceeded
+		}
+		trace.step("increment_hierarchy", stepStart, fields, err)
+	}
+	if err != nil {
+		s.logger.Error("Failed to increment limit hierarchy", err, map[string]interface{}{
+			"storage_key": storageKey,
+			"levels":      len(counters),
+		})
+		return nil, fmt.Errorf("failed to increment limit hierarchy: %w", err)
+	}
+
+	// Nível mais restrito: o estourado ou, se todos couberam, o com menos sobra
+	constrained := levels.Exceeded
+	if constrained < 0 {
+		constrained = 0
+		for i, counter := range counters {
+			if counter.Limit-levels.Counts[i] < counters[constrained].Limit-levels.Counts[constrained] {
+				constrained = i
+			}
+		}
+	}
+
+	result := &domain.RateLimitResult{
+		Allowed:     levels.Exceeded < 0,
+		Limit:       counters[constrained].Limit,
+		Remaining:   counters[constrained].Limit - levels.Counts[constrained],
+		ResetTime:   levels.ResetAt[constrained],
+		LimiterType: limiterType,
+		Headers:     rule.Headers,
+		Scope:       scopes[constrained],
+	}
+	s.recordRolloutDecision(rule, result.Allowed)
+
+	if result.Allowed {
+		if domain.DebugEnabled(s.logger) {
+			s.logger.Debug("Request allowed", s.familyFields(rule, key, map[string]interface{}{
+				"storage_key": storageKey,
+				"scope":       result.Scope,
+				"limit":       result.Limit,
+				"remaining":   result.Remaining,
+			}))
+		}
+		return result, nil
+	}
+
+	result.Remaining = 0
+	result.Message = rule.BlockMessage
+	result.DocsURL = rule.DocsURL
+
+	if levels.Exceeded > 0 {
+		result.BlockedUntil = &result.ResetTime
+		s.logger.Info("Hierarchical limit exceeded, request rejected", s.familyFields(rule, key, map[string]interface{}{
+			"storage_key": storageKey,
+			"scope":       result.Scope,
+			"limit":       result.Limit,
+			"reset_time":  result.ResetTime,
+		}))
+		for _, recorder := range s.blocks {
+			recorder.RecordRejected(key, limiterType)
+		}
+		return result, nil
+	}
+
+	blockDuration := time.Duration(rule.BlockDuration) * time.Second
+	if err := store.Block(ctx, storageKey, blockDuration); err != nil {
+		s.logger.Error("Failed to block key", err, map[string]interface{}{
+			"storage_key":    storageKey,
+			"block_duration": blockDuration,
+		})
+		// Não retorna erro aqui para não impedir a resposta HTTP 429
+	}
+
+	blockTime := s.now().Add(blockDuration)
+	result.BlockedUntil = &blockTime
+	s.logger.Info("Rate limit exceeded, key blocked", s.familyFields(rule, key, map[string]interface{}{
+		"storage_key":   storageKey,
+		"scope":         result.Scope,
+		"current_count": levels.Counts[0],
+		"limit":         rule.Limit,
+		"blocked_until": blockTime,
+	}))
+	recordBlockMetric(ctx, limiterType)
+	for _, recorder := range s.blocks {
+		recorder.RecordBlock(domain.BlockRecord{
+			Key:          key,
+			Type:         limiterType,
+			Rule:         rule.Description,
+			Limit:        rule.Limit,
+			RequestCount: levels.Counts[0],
+			BlockedAt:    blockTime.Add(-blockDuration),
+			Duration:     rule.BlockDuration,
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"rate-limiter/internal/domain"
+)
+
+// identity é uma das identidades extraídas da requisição
+type identity struct {
+	limiterType domain.LimiterType
+	key         string
+}
+
+// WithAllIdentities avalia e contabiliza também as identidades que não aplicam o
+// limite (ex: o IP de uma requisição limitada pelo token), para que analytics e
+// status enxerguem todas as dimensões. Apenas a identidade principal bloqueia
+func WithAllIdentities() Option {
+	return func(s *RateLimiterService) {
+		s.allIdentities = true
+	}
+}
+
+// secondaryIdentities retorna as identidades da requisição que não aplicam o limite
+func (s *RateLimiterService) secondaryIdentities(ip, token string) []identity {
+	if strings.TrimSpace(token) == "" || ip == "" {
+		return nil
+	}
+	return []identity{{limiterType: domain.IPLimiter, key: ip}}
+}
+
+// recordIdentity contabiliza uma identidade que não aplica o limite: incrementa a
+// sua janela e informa aos observers se ela teria sido permitida, sem bloquear a
+// chave. Falhas são apenas registradas, a decisão principal já foi tomada
+func (s *RateLimiterService) recordIdentity(ctx context.Context, id identity) {
+	for _, observer := range s.traffic {
+		observer.ObserveRequest(id.key, id.limiterType)
+	}
+
+	storageKey := s.buildStorageKey(id.key, id.limiterType)
+	rule, err := s.GetConfig(ctx, id.key, id.limiterType)
+	if err != nil {
+		s.logger.Error("Failed to resolve rate limit rule for secondary identity", err, map[string]interface{}{
+			"storage_key": storageKey,
+		})
+		return
+	}
+
+	// Baldes não são consumidos: a fila e as fichas pertencem à própria identidade
+	if rule.Disabled || rule.RefillRate > 0 || rule.LeakRate > 0 {
+		return
+	}
+
+	// O uso é contado mesmo com a identidade bloqueada pelo próprio tráfego
+	storage := s.storageFor(rule)
+	blocked, _, err := storage.IsBlocked(ctx, storageKey)
+	count := 0
+	if err == nil {
+		count, _, err = s.increment(ctx, storage, storageKey, rule)
+	}
+	if err != nil {
+		s.logger.Error("Failed to record secondary identity", err, map[string]interface{}{
+			"storage_key": storageKey,
+		})
+		return
+	}
+
+	allowed := !blocked && count <= rule.Limit
+	for _, observer := range s.decisions {
+		observer.ObserveDecision(id.key, id.limiterType, allowed)
+	}
+	if !allowed && domain.DebugEnabled(s.logger) {
+		s.logger.Debug("Secondary identity over limit", map[string]interface{}{
+			"storage_key": storageKey,
+			"limit":       rule.Limit,
+		})
+	}
+}
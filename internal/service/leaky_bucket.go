@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"rate-limiter/internal/domain"
+)
+
+// leak decide a requisição pelo leaky bucket da regra: fila de rule.Limit
+// requisições escoada a rule.LeakRate por segundo. A requisição aceita sai com
+// ReleaseAt, o instante em que deixa a fila; no transbordo é negada com
+// BlockedUntil na próxima vaga, sem bloquear a chave por BlockDuration
+func (s *RateLimiterService) leak(ctx context.Context, storage domain.RateLimiterStorage, storageKey, key string, limiterType domain.LimiterType, rule *domain.RateLimitRule) (*domain.RateLimitResult, error) {
+	trace := s.decisionTraceFrom(ctx)
+	stepStart := trace.begin()
+	status, err := storage.Leak(ctx, storageKey, domain.LeakyBucket{Capacity: rule.Limit, LeakRate: rule.LeakRate})
+	if trace != nil {
+		trace.step("leak", stepStart, storageStatusFields(status), err)
+	}
+	if err != nil {
+		s.logger.Error("Failed to enqueue request in leaky bucket", err, map[string]interface{}{
+			"storage_key": storageKey,
+			"capacity":    rule.Limit,
+			"leak_rate":   rule.LeakRate,
+		})
+		return nil, fmt.Errorf("failed to enqueue request in leaky bucket: %w", err)
+	}
+
+	allowed := !status.IsBlocked
+	s.recordRolloutDecision(rule, allowed)
+
+	result := &domain.RateLimitResult{
+		Allowed:     allowed,
+		Limit:       rule.Limit,
+		Remaining:   rule.Limit - status.Count,
+		ResetTime:   *status.ResetAt,
+		LimiterType: limiterType,
+		Headers:     rule.Headers,
+		ReleaseAt:   status.ReleaseAt,
+	}
+
+	if !allowed {
+		result.Remaining = 0
+		result.BlockedUntil = status.BlockedUntil
+		result.Message = rule.BlockMessage
+		result.DocsURL = rule.DocsURL
+
+		s.logger.Info("Leaky bucket overflow, request rejected", map[string]interface{}{
+			"storage_key": storageKey,
+			"capacity":    rule.Limit,
+			"leak_rate":   rule.LeakRate,
+			"next_slot":   status.BlockedUntil,
+		})
+		for _, recorder := range s.blocks {
+			recorder.RecordRejected(key, limiterType)
+		}
+		return result, nil
+	}
+
+	s.logger.Debug("Request queued in leaky bucket", map[string]interface{}{
+		"storage_key": storageKey,
+		"capacity":    rule.Limit,
+		"queued":      status.Count,
+		"release_at":  status.ReleaseAt,
+	})
+	return result, nil
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"rate-limiter/internal/domain"
+)
+
+// MeterName identifica as métricas emitidas pelo service
+const MeterName = "rate-limiter/internal/service"
+
+// decisionMetrics são os instrumentos criados no MeterProvider global vigente.
+// Sem provider configurado as medições não têm custo
+type decisionMetrics struct {
+	provider  metric.MeterProvider
+	decisions metric.Int64Counter
+	remaining metric.Int64Histogram
+	blocks    metric.Int64Counter
+
+	// Atributos montados uma única vez por combinação; a chave nunca é registrada
+	mutex   sync.RWMutex
+	outcome map[decisionOutcome][]metric.AddOption
+	byType  map[domain.LimiterType]typeOptions
+}
+
+// decisionOutcome são as dimensões do contador de decisões
+type decisionOutcome struct {
+	limiterType domain.LimiterType
+	allowed     bool
+}
+
+// typeOptions são os atributos das métricas dimensionadas apenas pelo tipo de limiter
+type typeOptions struct {
+	add    []metric.AddOption
+	record []metric.RecordOption
+}
+
+var currentDecisionMetrics atomic.Pointer[decisionMetrics]
+
+// loadDecisionMetrics retorna os instrumentos do provider global, recriados quando
+// ele é trocado (ex: tracing.SetupMetrics ou testes)
+func loadDecisionMetrics() *decisionMetrics {
+	provider := otel.GetMeterProvider()
+	if current := currentDecisionMetrics.Load(); current != nil && current.provider == provider {
+		return current
+	}
+
+	meter := provider.Meter(MeterName)
+	decisions, err := meter.Int64Counter(
+		"rate_limiter.decisions",
+		metric.WithUnit("{decision}"),
+		metric.WithDescription("Rate limit decisions, by key type and outcome."),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+	remaining, err := meter.Int64Histogram(
+		"rate_limiter.remaining",
+		metric.WithUnit("{request}"),
+		metric.WithDescription("Requests left in the window after each decision."),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+	blocks, err := meter.Int64Counter(
+		"rate_limiter.blocks",
+		metric.WithUnit("{block}"),
+		metric.WithDescription("Keys blocked for exceeding the limit, by key type."),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	loaded := &decisionMetrics{
+		provider:  provider,
+		decisions: decisions,
+		remaining: remaining,
+		blocks:    blocks,
+		outcome:   make(map[decisionOutcome][]metric.AddOption),
+		byType:    make(map[domain.LimiterType]typeOptions),
+	}
+	currentDecisionMetrics.Store(loaded)
+	return loaded
+}
+
+// outcomeOptions retorna os atributos do contador de decisões
+func (m *decisionMetrics) outcomeOptions(outcome decisionOutcome) []metric.AddOption {
+	m.mutex.RLock()
+	options, ok := m.outcome[outcome]
+	m.mutex.RUnlock()
+	if ok {
+		return options
+	}
+
+	options = []metric.AddOption{metric.WithAttributeSet(attribute.NewSet(
+		attribute.String("rate_limit.key_type", string(outcome.limiterType)),
+		attribute.Bool("rate_limit.allowed", outcome.allowed),
+	))}
+	m.mutex.Lock()
+	m.outcome[outcome] = options
+	m.mutex.Unlock()
+	return options
+}
+
+// typeOptions retorna os atributos das métricas dimensionadas pelo tipo de limiter
+func (m *decisionMetrics) typeOptions(limiterType domain.LimiterType) typeOptions {
+	m.mutex.RLock()
+	options, ok := m.byType[limiterType]
+	m.mutex.RUnlock()
+	if ok {
+		return options
+	}
+
+	set := attribute.NewSet(attribute.String("rate_limit.key_type", string(limiterType)))
+	options = typeOptions{
+		add:    []metric.AddOption{metric.WithAttributeSet(set)},
+		record: []metric.RecordOption{metric.WithAttributeSet(set)},
+	}
+	m.mutex.Lock()
+	m.byType[limiterType] = options
+	m.mutex.Unlock()
+	return options
+}
+
+// recordDecision registra a decisão aplicada e a cota restante
+func recordDecision(ctx context.Context, limiterType domain.LimiterType, result *domain.RateLimitResult) {
+	metrics := loadDecisionMetrics()
+	if metrics.decisions != nil {
+		metrics.decisions.Add(ctx, 1, metrics.outcomeOptions(decisionOutcome{limiterType, result.Allowed})...)
+	}
+	if metrics.remaining != nil {
+		metrics.remaining.Record(ctx, int64(result.Remaining), metrics.typeOptions(limiterType).record...)
+	}
+}
+
+// recordBlockMetric registra um bloqueio aplicado por exceder o limite
+func recordBlockMetric(ctx context.Context, limiterType domain.LimiterType) {
+	if metrics := loadDecisionMetrics(); metrics.blocks != nil {
+		metrics.blocks.Add(ctx, 1, metrics.typeOptions(limiterType).add...)
+	}
+}
@@ -2,19 +2,224 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"strings"
+	"sync"
 	"time"
 
 	"rate-limiter/internal/domain"
+	"rate-limiter/internal/events"
+	"rate-limiter/internal/schedule"
+	"rate-limiter/internal/storage"
 )
 
+// Eventos de enforcement publicados via WithEventPublisher
+const (
+	EventKeyBlocked   = "rate_limit.blocked"
+	EventKeyUnblocked = "rate_limit.unblocked"
+	EventKeySoftLimit = "rate_limit.soft_limit"
+	EventKeyOverride  = "rate_limit.override"
+)
+
+// EventKeyUsageThreshold é enviado ao webhook do dono do token via WithUsageNotifier
+const EventKeyUsageThreshold = "rate_limit.usage_threshold"
+
+// defaultWebhookThreshold é o % do limite que dispara o webhook quando o token não define
+const defaultWebhookThreshold = 90
+
+// overrideLoadTimeout limita a leitura dos overrides compartilhados (início e invalidações)
+const overrideLoadTimeout = 2 * time.Second
+
 // RateLimiterService implementa a lógica de negócio do rate limiting
 // Separada do middleware conforme requisito fc_rate_limiter
 type RateLimiterService struct {
-	storage domain.RateLimiterStorage
-	config  *domain.RateLimitConfig
-	logger  domain.Logger
+	storage        domain.RateLimiterStorage
+	storages       map[string]domain.RateLimiterStorage // backends nomeados por regra
+	config         *domain.RateLimitConfig
+	logger         domain.Logger
+	healthReporter domain.StorageHealthReporter
+	schedules      sync.Map // expressão cron -> *schedule.Schedule
+
+	instanceCounter domain.InstanceCounter // particionamento de limites entre réplicas
+	tokenProvider   domain.TokenConfigProvider // fonte dinâmica de tokens (ex: banco)
+	configSource    domain.ConfigSource        // configuração trocável em execução (stage/promote)
+	maintenance     domain.MaintenanceProvider // janelas de manutenção declaradas
+	rollouts        domain.RuleRolloutProvider // rollouts canário de novas versões de regra
+	shadow          domain.ShadowObserver      // avaliação de configuração staged sem aplicar
+	traffic         []domain.TrafficObserver   // detecção de anomalias e amostragem de tráfego
+	blocks          []domain.BlockRecorder     // histórico de bloqueios (relatórios) e estatísticas
+	decisions       []domain.DecisionObserver  // analytics agregados por minuto e estatísticas
+	tracer          domain.DecisionTracer      // gravação de decisões sob demanda (/admin/debug/decisions)
+	invalidation    domain.InvalidationBus     // propagação de resets entre instâncias
+	events          events.Publisher           // eventos de enforcement (bloqueios, avisos, overrides)
+	notifier        events.Notifier            // webhooks de uso definidos pelos tokens
+	softLimit       int                        // % do limite que gera o aviso de soft limit (0 = desabilitado)
+	allIdentities   bool                       // contabiliza também as identidades que não aplicam o limite
+	keyOptions      []storage.KeyOption        // formato das chaves de storage (ex: hash tags)
+	now             func() time.Time           // relógio de resets, bloqueios e overrides
+
+	overridesMutex sync.RWMutex
+	overrides      map[string]domain.LimitOverride // chave de storage -> override
+	overrideStore  domain.OverrideStore            // overrides compartilhados entre as réplicas
+}
+
+// Option configura recursos opcionais do serviço
+type Option func(*RateLimiterService)
+
+// WithClock substitui o relógio do serviço (simulação e replay de tráfego)
+func WithClock(now func() time.Time) Option {
+	return func(s *RateLimiterService) {
+		s.now = now
+	}
+}
+
+// WithHealthReporter faz o serviço evitar o storage enquanto ele estiver degradado
+func WithHealthReporter(reporter domain.StorageHealthReporter) Option {
+	return func(s *RateLimiterService) {
+		s.healthReporter = reporter
+	}
+}
+
+// WithStorages registra backends nomeados que as regras podem fixar via campo Storage
+func WithStorages(storages map[string]domain.RateLimiterStorage) Option {
+	return func(s *RateLimiterService) {
+		s.storages = storages
+	}
+}
+
+// WithLimitPartitioning divide os limites de storages locais pelo número de instâncias vivas
+// Assim deploys só com memória aproximam um limite global entre réplicas
+func WithLimitPartitioning(counter domain.InstanceCounter) Option {
+	return func(s *RateLimiterService) {
+		s.instanceCounter = counter
+	}
+}
+
+// WithTokenConfigProvider resolve configurações de token por uma fonte dinâmica
+// em vez do mapa estático carregado do tokens.json
+func WithTokenConfigProvider(provider domain.TokenConfigProvider) Option {
+	return func(s *RateLimiterService) {
+		s.tokenProvider = provider
+	}
+}
+
+// WithConfigSource lê a configuração ativa de uma fonte trocável em execução
+// em vez da configuração fixa passada ao construtor
+func WithConfigSource(source domain.ConfigSource) Option {
+	return func(s *RateLimiterService) {
+		s.configSource = source
+	}
+}
+
+// WithMaintenance relaxa ou desativa regras durante janelas de manutenção
+func WithMaintenance(provider domain.MaintenanceProvider) Option {
+	return func(s *RateLimiterService) {
+		s.maintenance = provider
+	}
+}
+
+// WithRuleRollouts aplica novas versões de regra a uma fração do tráfego (canário)
+func WithRuleRollouts(provider domain.RuleRolloutProvider) Option {
+	return func(s *RateLimiterService) {
+		s.rollouts = provider
+	}
+}
+
+// WithShadowObserver envia cada decisão para comparação com uma configuração staged
+func WithShadowObserver(observer domain.ShadowObserver) Option {
+	return func(s *RateLimiterService) {
+		s.shadow = observer
+	}
+}
+
+// WithTrafficObserver envia cada requisição verificada para análise de tráfego
+// (detecção de picos, amostragem ao vivo). Pode ser usada mais de uma vez
+func WithTrafficObserver(observer domain.TrafficObserver) Option {
+	return func(s *RateLimiterService) {
+		s.traffic = append(s.traffic, observer)
+	}
+}
+
+// WithBlockRecorder registra cada bloqueio aplicado (relatórios de compliance e suporte).
+// Pode ser usada mais de uma vez
+func WithBlockRecorder(recorder domain.BlockRecorder) Option {
+	return func(s *RateLimiterService) {
+		s.blocks = append(s.blocks, recorder)
+	}
+}
+
+// WithDecisionObserver envia o resultado de cada verificação para analytics e estatísticas.
+// Pode ser usada mais de uma vez
+func WithDecisionObserver(observer domain.DecisionObserver) Option {
+	return func(s *RateLimiterService) {
+		s.decisions = append(s.decisions, observer)
+	}
+}
+
+// WithDecisionTracer grava o passo a passo das decisões das chaves em depuração
+func WithDecisionTracer(tracer domain.DecisionTracer) Option {
+	return func(s *RateLimiterService) {
+		s.tracer = tracer
+	}
+}
+
+// WithEventPublisher publica um evento a cada bloqueio aplicado, a cada reset
+// que remove um bloqueio e a cada override administrativo (ex: para um Redis
+// Stream consumido externamente ou para o log de segurança)
+func WithEventPublisher(publisher events.Publisher) Option {
+	return func(s *RateLimiterService) {
+		s.events = publisher
+	}
+}
+
+// WithUsageNotifier envia EventKeyUsageThreshold ao webhook do token quando o uso
+// cruza o limiar configurado nele, uma vez por janela
+func WithUsageNotifier(notifier events.Notifier) Option {
+	return func(s *RateLimiterService) {
+		s.notifier = notifier
+	}
+}
+
+// WithSoftLimitWarning publica EventKeySoftLimit quando uma chave atinge percent% do
+// limite na janela, uma vez por janela. Requer WithEventPublisher
+func WithSoftLimitWarning(percent int) Option {
+	return func(s *RateLimiterService) {
+		s.softLimit = percent
+	}
+}
+
+// WithEncryptedKeys cifra o IP ou token nas chaves de storage (dados em repouso)
+func WithEncryptedKeys(cipher *storage.Cipher) Option {
+	return func(s *RateLimiterService) {
+		s.keyOptions = append(s.keyOptions, storage.WithEncryptedIdentifier(cipher))
+	}
+}
+
+// WithHashTaggedKeys envolve o identificador das chaves de storage em {hash tag},
+// agrupando as chaves de cada identidade no mesmo slot do Redis Cluster
+func WithHashTaggedKeys() Option {
+	return func(s *RateLimiterService) {
+		s.keyOptions = append(s.keyOptions, storage.WithHashTag())
+	}
+}
+
+// WithInvalidation propaga resets administrativos para as demais instâncias e
+// descarta o estado local (storages locais, cache de tokens) quando recebe um
+func WithInvalidation(bus domain.InvalidationBus) Option {
+	return func(s *RateLimiterService) {
+		s.invalidation = bus
+	}
+}
+
+// WithOverrideStore compartilha os overrides pelo store: são carregados na criação
+// do serviço e recarregados quando outra réplica anuncia um override pelo barramento
+// de invalidação (WithInvalidation)
+func WithOverrideStore(store domain.OverrideStore) Option {
+	return func(s *RateLimiterService) {
+		s.overrideStore = store
+	}
 }
 
 // NewRateLimiterService cria uma nova instância do serviço
@@ -22,32 +227,145 @@ func NewRateLimiterService(
 	storage domain.RateLimiterStorage,
 	config *domain.RateLimitConfig,
 	logger domain.Logger,
+	opts ...Option,
 ) domain.RateLimiterService {
-	return &RateLimiterService{
-		storage: storage,
-		config:  config,
-		logger:  logger,
+	service := &RateLimiterService{
+		storage:   storage,
+		config:    config,
+		logger:    logger,
+		overrides: make(map[string]domain.LimitOverride),
+		now:       time.Now,
 	}
+
+	for _, opt := range opts {
+		opt(service)
+	}
+
+	if service.invalidation != nil {
+		service.invalidation.Subscribe(service.invalidateLocal)
+	}
+	if service.overrideStore != nil {
+		service.loadOverrides()
+	}
+
+	return service
 }
 
 // CheckLimit implementa a lógica principal de verificação de rate limit
 // Detecta automaticamente se deve limitar por IP ou Token
 func (s *RateLimiterService) CheckLimit(ctx context.Context, ip, token string) (*domain.RateLimitResult, error) {
+	limiterType, key := s.detectLimiterType(ip, token)
+
+	// O tráfego é observado antes da decisão: picos aparecem mesmo abaixo do limite
+	for _, observer := range s.traffic {
+		observer.ObserveRequest(key, limiterType)
+	}
+
+	ctx, span := startDecisionSpan(ctx, limiterType)
+	ctx, trace := s.startDecisionTrace(ctx, key, limiterType)
+	result, err := s.checkLimit(ctx, ip, token)
+	endDecisionSpan(span, result, err)
+	if trace != nil {
+		s.tracer.RecordTrace(trace.finish(result, err))
+	}
+	if err != nil {
+		return result, err
+	}
+
+	recordDecision(ctx, limiterType, result)
+	for _, observer := range s.decisions {
+		observer.ObserveDecision(key, limiterType, result.Allowed)
+	}
+	if s.shadow != nil {
+		s.shadow.Observe(ctx, ip, token, result)
+	}
+	if s.allIdentities {
+		for _, id := range s.secondaryIdentities(ip, token) {
+			s.recordIdentity(ctx, id)
+		}
+	}
+	return result, nil
+}
+
+// checkLimit decide e aplica o rate limit sob a configuração ativa
+func (s *RateLimiterService) checkLimit(ctx context.Context, ip, token string) (*domain.RateLimitResult, error) {
 	// Detecta o tipo de limitação automaticamente
 	limiterType, key := s.detectLimiterType(ip, token)
 	
-	s.logger.Debug("Rate limit check initiated", map[string]interface{}{
-		"ip":           ip,
-		"token":        s.maskToken(token),
-		"limiter_type": limiterType,
-		"key":          key,
-	})
+	// Campos de debug só são montados quando registrados (caminho quente)
+	debug := domain.DebugEnabled(s.logger)
+	if debug {
+		s.logger.Debug("Rate limit check initiated", map[string]interface{}{
+			"ip":           ip,
+			"token":        s.maskToken(token),
+			"limiter_type": limiterType,
+			"key":          key,
+		})
+	}
+
+	// Storage degradado: falha rápido sem aguardar timeouts por requisição
+	if s.healthReporter != nil && !s.healthReporter.IsHealthy() {
+		return nil, domain.ErrStorageUnavailable
+	}
 
 	// Monta a chave de storage
 	storageKey := s.buildStorageKey(key, limiterType)
 
+	// Obtém a configuração e o backend da chave
+	trace := s.decisionTraceFrom(ctx)
+	stepStart := trace.begin()
+	rule, err := s.GetConfig(ctx, key, limiterType)
+	if err == nil {
+		storageKey = s.counterKey(rule, storageKey)
+	}
+	if trace != nil {
+		trace.step("resolve_rule", stepStart, nil, err)
+		if err == nil {
+			trace.rule(rule, storageKey)
+		}
+	}
+	if err != nil {
+		s.logger.Error("Failed to resolve rate limit rule", err, map[string]interface{}{
+			"storage_key": storageKey,
+		})
+		return nil, err
+	}
+	storage := s.storageFor(rule)
+
+	// Rate limiting suspenso (janela de manutenção): não consulta o storage
+	if rule.Disabled {
+		s.logger.Debug("Rate limiting disabled for rule", map[string]interface{}{
+			"storage_key": storageKey,
+			"rule":        rule.Description,
+		})
+
+		return &domain.RateLimitResult{
+			Allowed:     true,
+			Limit:       rule.Limit,
+			Remaining:   rule.Limit,
+			ResetTime:   s.now().Add(time.Duration(rule.Window) * time.Second),
+			LimiterType: limiterType,
+			Headers:     rule.Headers,
+		}, nil
+	}
+
+	// Janela fixa simples: bloqueio, contagem e novo bloqueio em uma única operação
+	if checker, ok := storage.(domain.AtomicLimitChecker); ok && singleRoundTrip(rule) {
+		result, err := s.checkAtomic(ctx, checker, storageKey, key, limiterType, rule, debug)
+		if !errors.Is(err, domain.ErrCheckUnsupported) {
+			return result, err
+		}
+	}
+
 	// Verifica se a chave está bloqueada
-	isBlocked, blockedUntil, err := s.storage.IsBlocked(ctx, storageKey)
+	stepStart = trace.begin()
+	isBlocked, blockedUntil, err := storage.IsBlocked(ctx, storageKey)
+	if trace != nil {
+		trace.step("is_blocked", stepStart, map[string]interface{}{
+			"blocked":       isBlocked,
+			"blocked_until": blockedUntil,
+		}, err)
+	}
 	if err != nil {
 		s.logger.Error("Failed to check blocked status", err, map[string]interface{}{
 			"storage_key": storageKey,
@@ -57,32 +375,34 @@ func (s *RateLimiterService) CheckLimit(ctx context.Context, ip, token string) (
 
 	// Se está bloqueada, retorna negação
 	if isBlocked {
-		s.logger.Info("Request blocked", map[string]interface{}{
-			"storage_key":   storageKey,
-			"blocked_until": blockedUntil,
-		})
+		return s.rejectBlocked(rule, key, storageKey, limiterType, blockedUntil), nil
+	}
 
-		rule := s.GetConfig(key, limiterType)
-		return &domain.RateLimitResult{
-			Allowed:      false,
-			Limit:        rule.Limit,
-			Remaining:    0,
-			ResetTime:    time.Now().Add(time.Duration(rule.Window) * time.Second),
-			BlockedUntil: blockedUntil,
-			LimiterType:  limiterType,
-		}, nil
+	// Hierarquia org → projeto → token: todos os níveis contam na mesma operação
+	if len(rule.Parents) > 0 {
+		return s.checkHierarchy(ctx, storage, storageKey, key, limiterType, rule)
 	}
 
-	// Obtém a configuração para a chave
-	rule := s.GetConfig(key, limiterType)
+	// Token bucket: a negação não bloqueia a chave, o cliente volta na próxima ficha
+	if rule.RefillRate > 0 && rule.Limit > 0 {
+		return s.takeToken(ctx, storage, storageKey, key, limiterType, rule)
+	}
+
+	// Leaky bucket: a requisição aguarda a sua vez na fila; só o transbordo é negado
+	if rule.LeakRate > 0 && rule.Limit > 0 {
+		return s.leak(ctx, storage, storageKey, key, limiterType, rule)
+	}
 
 	// Incrementa o contador e verifica limite
-	currentCount, resetTime, err := s.storage.Increment(
-		ctx,
-		storageKey,
-		rule.Limit,
-		time.Duration(rule.Window)*time.Second,
-	)
+	stepStart = trace.begin()
+	currentCount, resetTime, err := s.increment(ctx, storage, storageKey, rule)
+	if trace != nil {
+		trace.step("increment", stepStart, map[string]interface{}{
+			"count":      currentCount,
+			"limit":      rule.Limit,
+			"reset_time": resetTime,
+		}, err)
+	}
 	if err != nil {
 		s.logger.Error("Failed to increment counter", err, map[string]interface{}{
 			"storage_key": storageKey,
@@ -91,12 +411,6 @@ func (s *RateLimiterService) CheckLimit(ctx context.Context, ip, token string) (
 		return nil, fmt.Errorf("failed to increment counter: %w", err)
 	}
 
-    // Calcula remaining
-    remaining := rule.Limit - currentCount
-    if remaining < 0 {
-        remaining = 0
-    }
-
     // Verifica se excedeu o limite
     // Importante: permitir até o limite inclusivo (ex.: 10ª requisição ainda é permitida)
     allowed := currentCount <= rule.Limit
@@ -104,7 +418,14 @@ func (s *RateLimiterService) CheckLimit(ctx context.Context, ip, token string) (
 	// Se excedeu o limite, bloqueia por X minutos
 	if !allowed {
 		blockDuration := time.Duration(rule.BlockDuration) * time.Second
-		if err := s.storage.Block(ctx, storageKey, blockDuration); err != nil {
+		stepStart = trace.begin()
+		err := storage.Block(ctx, storageKey, blockDuration)
+		if trace != nil {
+			trace.step("block", stepStart, map[string]interface{}{
+				"block_duration_seconds": rule.BlockDuration,
+			}, err)
+		}
+		if err != nil {
 			s.logger.Error("Failed to block key", err, map[string]interface{}{
 				"storage_key":    storageKey,
 				"block_duration": blockDuration,
@@ -112,31 +433,124 @@ func (s *RateLimiterService) CheckLimit(ctx context.Context, ip, token string) (
 			// Não retorna erro aqui para não impedir a resposta HTTP 429
 		}
 
-		blockTime := time.Now().Add(blockDuration)
-		s.logger.Info("Rate limit exceeded, key blocked", map[string]interface{}{
-			"storage_key":    storageKey,
-			"current_count":  currentCount,
-			"limit":          rule.Limit,
-			"blocked_until":  blockTime,
-		})
+		return s.rejectExceeded(ctx, rule, key, storageKey, limiterType, currentCount, resetTime, s.now().Add(blockDuration)), nil
+	}
 
-		return &domain.RateLimitResult{
-			Allowed:      false,
-			Limit:        rule.Limit,
-			Remaining:    0,
-			ResetTime:    resetTime,
-			BlockedUntil: &blockTime,
-			LimiterType:  limiterType,
-		}, nil
+	return s.allowCounted(ctx, rule, key, storageKey, limiterType, currentCount, resetTime, debug), nil
+}
+
+// rejectBlocked nega a requisição de uma chave que já estava bloqueada
+func (s *RateLimiterService) rejectBlocked(rule *domain.RateLimitRule, key, storageKey string, limiterType domain.LimiterType, blockedUntil *time.Time) *domain.RateLimitResult {
+	s.logger.Info("Request blocked", s.familyFields(rule, key, map[string]interface{}{
+		"storage_key":   storageKey,
+		"blocked_until": blockedUntil,
+	}))
+	s.recordRolloutDecision(rule, false)
+	for _, recorder := range s.blocks {
+		recorder.RecordRejected(key, limiterType)
 	}
 
-	// Requisição permitida
-	s.logger.Debug("Request allowed", map[string]interface{}{
+	return &domain.RateLimitResult{
+		Allowed:      false,
+		Limit:        rule.Limit,
+		Remaining:    0,
+		ResetTime:    s.now().Add(time.Duration(rule.Window) * time.Second),
+		BlockedUntil: blockedUntil,
+		LimiterType:  limiterType,
+		Message:      rule.BlockMessage,
+		DocsURL:      rule.DocsURL,
+		Headers:      rule.Headers,
+	}
+}
+
+// rejectExceeded registra o bloqueio aplicado à chave que passou do limite e nega a requisição
+func (s *RateLimiterService) rejectExceeded(ctx context.Context, rule *domain.RateLimitRule, key, storageKey string, limiterType domain.LimiterType, currentCount int, resetTime, blockTime time.Time) *domain.RateLimitResult {
+	blockDuration := time.Duration(rule.BlockDuration) * time.Second
+	s.logger.Info("Rate limit exceeded, key blocked", s.familyFields(rule, key, map[string]interface{}{
 		"storage_key":   storageKey,
 		"current_count": currentCount,
 		"limit":         rule.Limit,
-		"remaining":     remaining,
-	})
+		"blocked_until": blockTime,
+	}))
+	s.recordRolloutDecision(rule, false)
+	recordBlockMetric(ctx, limiterType)
+	for _, recorder := range s.blocks {
+		recorder.RecordBlock(domain.BlockRecord{
+			Key:          key,
+			Type:         limiterType,
+			Rule:         rule.Description,
+			Limit:        rule.Limit,
+			RequestCount: currentCount,
+			BlockedAt:    blockTime.Add(-blockDuration),
+			Duration:     rule.BlockDuration,
+		})
+	}
+	if s.events != nil {
+		s.events.Publish(ctx, EventKeyBlocked, map[string]interface{}{
+			"key":                    maskEventKey(key, limiterType),
+			"limiter_type":           string(limiterType),
+			"rule":                   rule.Description,
+			"limit":                  rule.Limit,
+			"request_count":          currentCount,
+			"blocked_until":          blockTime.UTC().Format(time.RFC3339),
+			"block_duration_seconds": rule.BlockDuration,
+		})
+	}
+
+	return &domain.RateLimitResult{
+		Allowed:      false,
+		Limit:        rule.Limit,
+		Remaining:    0,
+		ResetTime:    resetTime,
+		BlockedUntil: &blockTime,
+		LimiterType:  limiterType,
+		Message:      rule.BlockMessage,
+		DocsURL:      rule.DocsURL,
+		Headers:      rule.Headers,
+	}
+}
+
+// allowCounted permite a requisição já contada e publica os avisos de uso
+func (s *RateLimiterService) allowCounted(ctx context.Context, rule *domain.RateLimitRule, key, storageKey string, limiterType domain.LimiterType, currentCount int, resetTime time.Time, debug bool) *domain.RateLimitResult {
+	remaining := rule.Limit - currentCount
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	// Aviso único por janela: apenas o incremento que atinge o limiar publica
+	if s.events != nil && s.softLimit > 0 && currentCount == softLimitThreshold(rule.Limit, s.softLimit) {
+		s.events.Publish(ctx, EventKeySoftLimit, map[string]interface{}{
+			"key":           maskEventKey(key, limiterType),
+			"limiter_type":  string(limiterType),
+			"rule":          rule.Description,
+			"limit":         rule.Limit,
+			"request_count": currentCount,
+			"percent":       s.softLimit,
+		})
+	}
+
+	// Webhook do dono do token: apenas o incremento que cruza o limiar notifica
+	if s.notifier != nil && rule.WebhookURL != "" && currentCount == softLimitThreshold(rule.Limit, rule.WebhookThreshold) {
+		s.notifier.Notify(ctx, rule.WebhookURL, EventKeyUsageThreshold, map[string]interface{}{
+			"token":         s.maskToken(key),
+			"rule":          rule.Description,
+			"limit":         rule.Limit,
+			"request_count": currentCount,
+			"percent":       rule.WebhookThreshold,
+			"window":        rule.Window,
+		})
+	}
+
+	// Requisição permitida
+	if debug {
+		s.logger.Debug("Request allowed", s.familyFields(rule, key, map[string]interface{}{
+			"storage_key":   storageKey,
+			"current_count": currentCount,
+			"limit":         rule.Limit,
+			"remaining":     remaining,
+		}))
+	}
+	s.recordRolloutDecision(rule, true)
 
 	return &domain.RateLimitResult{
 		Allowed:     true,
@@ -144,14 +558,22 @@ func (s *RateLimiterService) CheckLimit(ctx context.Context, ip, token string) (
 		Remaining:   remaining,
 		ResetTime:   resetTime,
 		LimiterType: limiterType,
-	}, nil
+		Headers:     rule.Headers,
+	}
 }
 
 // IsAllowed verifica se uma chave específica está permitida (não bloqueada)
 func (s *RateLimiterService) IsAllowed(ctx context.Context, key string, limiterType domain.LimiterType) (bool, error) {
 	storageKey := s.buildStorageKey(key, limiterType)
 	
-	isBlocked, _, err := s.storage.IsBlocked(ctx, storageKey)
+	rule, err := s.GetConfig(ctx, key, limiterType)
+	if err != nil {
+		return false, err
+	}
+	storageKey = s.counterKey(rule, storageKey)
+
+	storage := s.storageFor(rule)
+	isBlocked, _, err := storage.IsBlocked(ctx, storageKey)
 	if err != nil {
 		return false, fmt.Errorf("failed to check if key is allowed: %w", err)
 	}
@@ -160,48 +582,237 @@ func (s *RateLimiterService) IsAllowed(ctx context.Context, key string, limiterT
 }
 
 // GetConfig retorna a configuração apropriada para uma chave
-func (s *RateLimiterService) GetConfig(key string, limiterType domain.LimiterType) *domain.RateLimitRule {
+// Falha apenas quando a fonte dinâmica de tokens não consegue responder
+func (s *RateLimiterService) GetConfig(ctx context.Context, key string, limiterType domain.LimiterType) (*domain.RateLimitRule, error) {
 	var limit int
 	var description string
+	var resetSchedule string
+	var storageName string
+	var blockMessage, docsURL string
+	var headers map[string]string
+	var refillRate, leakRate float64
+	var webhookURL string
+	var family string
+	var parents []domain.LimitLevel
+	webhookThreshold := 0
+	capacity := 0
+	config := s.activeConfig()
+	rolloverPercent := config.QuotaRolloverPercent
 
 	switch limiterType {
 	case domain.IPLimiter:
-		limit = s.config.DefaultIPLimit
-		description = fmt.Sprintf("Default IP limit for %s", key)
+		limit = config.DefaultIPLimit
+		description = "Default IP limit for " + key
+		resetSchedule = config.IPResetSchedule
+		storageName = config.IPStorage
+		blockMessage, docsURL = config.IPBlockMessage, config.IPDocsURL
+		refillRate = config.IPRefillRate
+		leakRate = config.IPLeakRate
+
+		// Verifica se há regra específica para o IP (criada via /admin/rules)
+		if ipConfig, exists := config.IPConfigs[key]; exists {
+			limit = ipConfig.Limit
+			if ipConfig.Description != "" {
+				description = ipConfig.Description
+			}
+		}
 
 	case domain.TokenLimiter:
+		resetSchedule = config.TokenResetSchedule
+		storageName = config.TokenStorage
+		blockMessage, docsURL = config.TokenBlockMessage, config.TokenDocsURL
+		refillRate = config.TokenRefillRate
+		leakRate = config.TokenLeakRate
+
 		// Verifica se há configuração específica para o token
-		if tokenConfig, exists := s.config.TokenConfigs[key]; exists {
+		tokenConfig, exists, err := s.lookupTokenConfig(ctx, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve token config: %w", err)
+		}
+		if exists {
 			limit = tokenConfig.Limit
 			description = tokenConfig.Description
+			if tokenConfig.ResetSchedule != "" {
+				resetSchedule = tokenConfig.ResetSchedule
+			}
+			if tokenConfig.RolloverPercent != nil {
+				rolloverPercent = *tokenConfig.RolloverPercent
+			}
+			if tokenConfig.Storage != "" {
+				storageName = tokenConfig.Storage
+			}
+			if tokenConfig.BlockMessage != "" {
+				blockMessage = tokenConfig.BlockMessage
+			}
+			if tokenConfig.DocsURL != "" {
+				docsURL = tokenConfig.DocsURL
+			}
+			headers = tokenConfig.Headers
+			// O algoritmo definido pelo token substitui o padrão do tipo
+			if tokenConfig.RefillRate > 0 || tokenConfig.LeakRate > 0 {
+				refillRate, leakRate = tokenConfig.RefillRate, tokenConfig.LeakRate
+			}
+			capacity = tokenConfig.Capacity
+			family = tokenConfig.Family
+			if tokenConfig.Project != "" {
+				// A hierarquia conta em janela fixa: o balde e o reset agendado não se aplicam
+				parents = limitParents(config, tokenConfig.Project)
+				resetSchedule, refillRate, leakRate = "", 0, 0
+			}
+			if tokenConfig.WebhookURL != "" {
+				webhookURL, webhookThreshold = tokenConfig.WebhookURL, tokenConfig.WebhookThreshold
+				if webhookThreshold == 0 {
+					webhookThreshold = defaultWebhookThreshold
+				}
+			}
 		} else {
 			// Usa limite padrão para tokens
-			limit = s.config.DefaultTokenLimit
-			description = fmt.Sprintf("Default token limit for %s", key)
+			limit = config.DefaultTokenLimit
+			description = "Default token limit for " + key
 		}
 
 	default:
 		// Fallback para IP se tipo desconhecido
-		limit = s.config.DefaultIPLimit
+		limit = config.DefaultIPLimit
 		description = fmt.Sprintf("Fallback IP limit for %s", key)
+		blockMessage, docsURL = config.IPBlockMessage, config.IPDocsURL
+	}
+
+	// Token/leaky bucket: o limite passa a ser a capacidade do balde
+	if (refillRate > 0 || leakRate > 0) && capacity > 0 {
+		limit = capacity
+	}
+
+	// Rollout canário: a nova versão da regra vale para a fração sorteada do tráfego
+	var rolloutID, version string
+	if s.rollouts != nil {
+		if rollout, selected, ok := s.rollouts.ResolveRollout(key, limiterType); ok {
+			rolloutID = rollout.ID
+			version = selected
+			if selected == domain.RuleVersionCanary {
+				limit = rollout.Limit
+				description = fmt.Sprintf("%s (rollout %s, %s)", description, rollout.ID, selected)
+			}
+		}
+	}
+
+	// Override temporário tem precedência sobre a regra configurada
+	if override, ok := s.activeOverride(key, limiterType); ok {
+		limit = override.Limit
+		description = fmt.Sprintf("Temporary override for %s until %s", key, override.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+
+	// Janela de manutenção multiplica o limite ou suspende o rate limiting
+	disabled := false
+	if s.maintenance != nil {
+		if window, ok := s.maintenance.ActiveMaintenance(key, limiterType); ok {
+			if window.Disabled {
+				disabled = true
+			} else {
+				limit = int(math.Ceil(float64(limit) * window.Factor))
+			}
+			description = fmt.Sprintf("%s (maintenance window %s)", description, window.ID)
+		}
 	}
 
-	return &domain.RateLimitRule{
-		ID:            fmt.Sprintf("%s:%s", limiterType, key),
+	rule := &domain.RateLimitRule{
+		ID:            string(limiterType) + ":" + key,
 		Type:          limiterType,
 		Key:           key,
 		Limit:         limit,
-		Window:        s.config.Window,
-		BlockDuration: s.config.BlockDuration,
+		Window:        config.Window,
+		BlockDuration: config.BlockDuration,
 		Description:   description,
+		ResetSchedule: resetSchedule,
+		AlignWindow:   config.AlignWindows,
+		RolloverPercent: rolloverPercent,
+		Storage:       storageName,
+		Disabled:      disabled,
+		Rollout:       rolloutID,
+		Version:       version,
+		BlockMessage:  blockMessage,
+		DocsURL:       docsURL,
+		Headers:       headers,
+		RefillRate:    refillRate,
+		LeakRate:      leakRate,
+		WebhookURL:    webhookURL,
+		WebhookThreshold: webhookThreshold,
+		Family:        family,
+		Parents:       parents,
 	}
+
+	// Com o limite dividido entre as réplicas, a vazão do balde também é dividida
+	if partitioned := s.partitionLimit(rule); partitioned != rule.Limit {
+		if rule.Limit > 0 {
+			rule.RefillRate = rule.RefillRate * float64(partitioned) / float64(rule.Limit)
+			rule.LeakRate = rule.LeakRate * float64(partitioned) / float64(rule.Limit)
+		}
+		for i := range rule.Parents {
+			rule.Parents[i].Limit = (rule.Parents[i].Limit*partitioned + rule.Limit - 1) / rule.Limit
+		}
+		rule.Limit = partitioned
+	}
+	return rule, nil
+}
+
+// activeConfig retorna a configuração vigente
+func (s *RateLimiterService) activeConfig() *domain.RateLimitConfig {
+	if s.configSource != nil {
+		return s.configSource.ActiveConfig()
+	}
+	return s.config
+}
+
+// recordRolloutDecision contabiliza a decisão na versão da regra em rollout
+func (s *RateLimiterService) recordRolloutDecision(rule *domain.RateLimitRule, allowed bool) {
+	if s.rollouts == nil || rule.Rollout == "" {
+		return
+	}
+	s.rollouts.RecordDecision(rule.Rollout, rule.Version, allowed)
+}
+
+// lookupTokenConfig busca a configuração do token na fonte dinâmica ou no mapa estático
+func (s *RateLimiterService) lookupTokenConfig(ctx context.Context, token string) (domain.TokenConfig, bool, error) {
+	if s.tokenProvider != nil {
+		return s.tokenProvider.TokenConfig(ctx, token)
+	}
+
+	tokenConfig, exists := s.activeConfig().TokenConfigs[token]
+	return tokenConfig, exists, nil
+}
+
+// partitionLimit divide o limite entre as instâncias vivas quando o storage é local
+// Arredonda para cima para que nenhuma réplica fique com limite zero
+func (s *RateLimiterService) partitionLimit(rule *domain.RateLimitRule) int {
+	if s.instanceCounter == nil {
+		return rule.Limit
+	}
+
+	local, ok := s.storageFor(rule).(domain.LocalStorage)
+	if !ok || !local.IsLocal() {
+		return rule.Limit
+	}
+
+	instances := s.instanceCounter.LiveInstances()
+	if instances <= 1 {
+		return rule.Limit
+	}
+
+	return (rule.Limit + instances - 1) / instances
 }
 
 // GetStatus retorna o status atual de uma chave
 func (s *RateLimiterService) GetStatus(ctx context.Context, key string, limiterType domain.LimiterType) (*domain.RateLimitStatus, error) {
 	storageKey := s.buildStorageKey(key, limiterType)
 	
-	status, err := s.storage.Get(ctx, storageKey)
+	rule, err := s.GetConfig(ctx, key, limiterType)
+	if err != nil {
+		return nil, err
+	}
+	storageKey = s.counterKey(rule, storageKey)
+
+	storage := s.storageFor(rule)
+	status, err := storage.Get(ctx, storageKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get status: %w", err)
 	}
@@ -218,19 +829,454 @@ func (s *RateLimiterService) GetStatus(ctx context.Context, key string, limiterT
 func (s *RateLimiterService) Reset(ctx context.Context, key string, limiterType domain.LimiterType) error {
 	storageKey := s.buildStorageKey(key, limiterType)
 	
-	if err := s.storage.Reset(ctx, storageKey); err != nil {
+	rule, err := s.GetConfig(ctx, key, limiterType)
+	if err != nil {
+		return err
+	}
+	storageKey = s.counterKey(rule, storageKey)
+
+	storage := s.storageFor(rule)
+
+	// Só resets de chaves bloqueadas geram evento de desbloqueio
+	wasBlocked := false
+	if s.events != nil {
+		blocked, _, err := storage.IsBlocked(ctx, storageKey)
+		wasBlocked = err == nil && blocked
+	}
+
+	if err := storage.Reset(ctx, storageKey); err != nil {
 		return fmt.Errorf("failed to reset key: %w", err)
 	}
+
+	if wasBlocked {
+		s.events.Publish(ctx, EventKeyUnblocked, map[string]interface{}{
+			"key":          maskEventKey(key, limiterType),
+			"limiter_type": string(limiterType),
+			"reason":       "reset",
+		})
+	}
 	
 	s.logger.Info("Rate limit reset", map[string]interface{}{
 		"key":          key,
 		"limiter_type": limiterType,
 		"storage_key":  storageKey,
 	})
+
+	// O reset já foi aplicado: falha na propagação não desfaz a operação
+	if s.invalidation != nil {
+		invalidation := domain.Invalidation{Key: key, Type: limiterType, Reason: "reset"}
+		if err := s.invalidation.Broadcast(ctx, invalidation); err != nil {
+			s.logger.Warn("Failed to broadcast reset to other instances", map[string]interface{}{
+				"storage_key": storageKey,
+				"error":       err.Error(),
+			})
+		}
+	}
 	
 	return nil
 }
 
+// BlockKey bloqueia a chave manualmente no storage usado pela regra (ex: abuso
+// identificado por um operador); duration zero usa o BlockDuration da regra
+func (s *RateLimiterService) BlockKey(ctx context.Context, key string, limiterType domain.LimiterType, duration time.Duration) (time.Time, error) {
+	rule, err := s.GetConfig(ctx, key, limiterType)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if duration <= 0 {
+		duration = time.Duration(rule.BlockDuration) * time.Second
+	}
+	if duration <= 0 {
+		return time.Time{}, fmt.Errorf("block duration must be greater than 0")
+	}
+	storageKey := s.counterKey(rule, s.buildStorageKey(key, limiterType))
+
+	if err := s.storageFor(rule).Block(ctx, storageKey, duration); err != nil {
+		return time.Time{}, fmt.Errorf("failed to block key: %w", err)
+	}
+	blockedUntil := s.now().Add(duration)
+
+	if s.events != nil {
+		s.events.Publish(ctx, EventKeyBlocked, map[string]interface{}{
+			"key":                    maskEventKey(key, limiterType),
+			"limiter_type":           string(limiterType),
+			"rule":                   rule.Description,
+			"blocked_until":          blockedUntil.UTC().Format(time.RFC3339),
+			"block_duration_seconds": int(duration.Seconds()),
+			"reason":                 "manual",
+		})
+	}
+
+	s.logger.Info("Rate limit key blocked manually", map[string]interface{}{
+		"key":           maskEventKey(key, limiterType),
+		"limiter_type":  limiterType,
+		"blocked_until": blockedUntil,
+	})
+	return blockedUntil, nil
+}
+
+// maskEventKey mascara tokens antes de publicá-los em eventos (mesma regra dos logs)
+func maskEventKey(key string, limiterType domain.LimiterType) string {
+	if limiterType != domain.TokenLimiter || key == "" {
+		return key
+	}
+	if len(key) <= 8 {
+		return key + "***"
+	}
+	return key[:8] + "***"
+}
+
+// softLimitThreshold retorna a contagem que dispara o aviso (arredondada para cima)
+func softLimitThreshold(limit, percent int) int {
+	return (limit*percent + 99) / 100
+}
+
+// invalidateLocal descarta o estado que esta instância mantém sozinha para a chave
+// Storages compartilhados (Redis) já refletem a operação de origem e não são tocados
+func (s *RateLimiterService) invalidateLocal(invalidation domain.Invalidation) {
+	// Override novo em outra réplica: os contadores continuam valendo
+	if invalidation.Reason == "override" {
+		s.loadOverrides()
+		return
+	}
+	if invalidation.Pattern {
+		s.invalidateLocalPattern(invalidation)
+		return
+	}
+
+	storageKey := s.buildStorageKey(invalidation.Key, invalidation.Type)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	// Tokens de uma família guardam o estado no contador compartilhado
+	if invalidation.Type == domain.TokenLimiter {
+		if tokenConfig, exists, err := s.lookupTokenConfig(ctx, invalidation.Key); err == nil && exists && tokenConfig.Family != "" {
+			storageKey = storage.BuildFamilyKey(tokenConfig.Family, s.keyOptions...)
+		}
+	}
+
+	for _, storage := range s.localStorages() {
+		if err := storage.Reset(ctx, storageKey); err != nil {
+			s.logger.Warn("Failed to invalidate local rate limit state", map[string]interface{}{
+				"storage_key": storageKey,
+				"error":       err.Error(),
+			})
+		}
+	}
+
+	if invalidation.Type == domain.TokenLimiter {
+		if cache, ok := s.tokenProvider.(interface{ Invalidate(token string) }); ok {
+			cache.Invalidate(invalidation.Key)
+		}
+	}
+
+	s.logger.Debug("Local rate limit state invalidated", map[string]interface{}{
+		"storage_key": storageKey,
+		"reason":      invalidation.Reason,
+		"origin":      invalidation.Origin,
+	})
+}
+
+// invalidateLocalPattern descarta o estado local das chaves que casam com o glob
+// de um reset em massa feito em outra instância
+func (s *RateLimiterService) invalidateLocalPattern(invalidation domain.Invalidation) {
+	keyPattern, err := storage.BuildKeyPattern(invalidation.Type, invalidation.Key, s.keyOptions...)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	for _, local := range s.localStorages() {
+		resetter, ok := local.(domain.PatternResetter)
+		if !ok {
+			continue
+		}
+		if _, err := resetter.ResetPattern(ctx, keyPattern); err != nil {
+			s.logger.Warn("Failed to invalidate local rate limit state", map[string]interface{}{
+				"pattern": keyPattern,
+				"error":   err.Error(),
+			})
+		}
+	}
+}
+
+// localStorages retorna os storages cujo estado é restrito à instância, sem repetição
+func (s *RateLimiterService) localStorages() []domain.RateLimiterStorage {
+	candidates := []domain.RateLimiterStorage{s.storage}
+	for _, storage := range s.storages {
+		candidates = append(candidates, storage)
+	}
+
+	var result []domain.RateLimiterStorage
+	for _, candidate := range candidates {
+		local, ok := candidate.(domain.LocalStorage)
+		if !ok || !local.IsLocal() || containsStorage(result, candidate) {
+			continue
+		}
+		result = append(result, candidate)
+	}
+	return result
+}
+
+// containsStorage informa se o storage já está na lista
+func containsStorage(storages []domain.RateLimiterStorage, target domain.RateLimiterStorage) bool {
+	for _, storage := range storages {
+		if storage == target {
+			return true
+		}
+	}
+	return false
+}
+
+// InspectKey retorna o registro bruto da chave no storage usado pela regra
+func (s *RateLimiterService) InspectKey(ctx context.Context, key string, limiterType domain.LimiterType) (*domain.StorageRecord, error) {
+	storageKey := s.buildStorageKey(key, limiterType)
+
+	rule, err := s.GetConfig(ctx, key, limiterType)
+	if err != nil {
+		return nil, err
+	}
+	storageKey = s.counterKey(rule, storageKey)
+
+	inspector, ok := s.storageFor(rule).(domain.StorageInspector)
+	if !ok {
+		return nil, domain.ErrInspectionUnsupported
+	}
+
+	record, err := inspector.Inspect(ctx, storageKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect key: %w", err)
+	}
+	return record, nil
+}
+
+// WindowHistory retorna as contagens das últimas janelas da chave no storage usado pela regra
+func (s *RateLimiterService) WindowHistory(ctx context.Context, key string, limiterType domain.LimiterType) ([]domain.WindowCount, error) {
+	storageKey := s.buildStorageKey(key, limiterType)
+
+	rule, err := s.GetConfig(ctx, key, limiterType)
+	if err != nil {
+		return nil, err
+	}
+	storageKey = s.counterKey(rule, storageKey)
+
+	reader, ok := s.storageFor(rule).(domain.WindowHistoryReader)
+	if !ok {
+		return nil, domain.ErrHistoryUnsupported
+	}
+
+	history, err := reader.WindowHistory(ctx, storageKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read window history: %w", err)
+	}
+	return history, nil
+}
+
+// ListKeys retorna uma página das chaves do tipo com estado no storage padrão e o
+// cursor da próxima página. Chaves de storages nomeados e de famílias não entram
+func (s *RateLimiterService) ListKeys(ctx context.Context, limiterType domain.LimiterType, cursor string, count int) ([]domain.TrackedKey, string, error) {
+	lister, ok := s.storage.(domain.KeyLister)
+	if !ok {
+		return nil, "", domain.ErrListingUnsupported
+	}
+
+	statuses, next, err := lister.ListKeys(ctx, storage.KeyPrefix(limiterType), cursor, count)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list keys: %w", err)
+	}
+
+	keys := make([]domain.TrackedKey, 0, len(statuses))
+	for _, status := range statuses {
+		identifier, err := storage.ParseKey(limiterType, status.Key, s.keyOptions...)
+		if err != nil {
+			s.logger.Debug("Skipping unreadable storage key", map[string]interface{}{
+				"storage_key": status.Key,
+				"error":       err.Error(),
+			})
+			continue
+		}
+
+		remaining := status.EffectiveLimit() - status.Count
+		if remaining < 0 {
+			remaining = 0
+		}
+		keys = append(keys, domain.TrackedKey{
+			Key:          identifier,
+			Type:         limiterType,
+			Count:        status.Count,
+			Limit:        status.EffectiveLimit(),
+			Remaining:    remaining,
+			IsBlocked:    status.IsBlocked,
+			BlockedUntil: status.BlockedUntil,
+			ResetAt:      status.ResetAt,
+		})
+	}
+	return keys, next, nil
+}
+
+// ResetPattern limpa as chaves do tipo cujo identificador casa com o glob em todos
+// os storages que suportam o reset por padrão. Contadores de famílias não entram
+func (s *RateLimiterService) ResetPattern(ctx context.Context, pattern string, limiterType domain.LimiterType) (int, error) {
+	keyPattern, err := storage.BuildKeyPattern(limiterType, pattern, s.keyOptions...)
+	if err != nil {
+		return 0, err
+	}
+
+	candidates := []domain.RateLimiterStorage{s.storage}
+	for _, named := range s.storages {
+		candidates = append(candidates, named)
+	}
+
+	var storages []domain.RateLimiterStorage
+	for _, candidate := range candidates {
+		if _, ok := candidate.(domain.PatternResetter); ok && !containsStorage(storages, candidate) {
+			storages = append(storages, candidate)
+		}
+	}
+	if len(storages) == 0 {
+		return 0, domain.ErrPatternResetUnsupported
+	}
+
+	removed := 0
+	for _, candidate := range storages {
+		count, err := candidate.(domain.PatternResetter).ResetPattern(ctx, keyPattern)
+		removed += count
+		if err != nil {
+			return removed, fmt.Errorf("failed to reset keys: %w", err)
+		}
+	}
+
+	s.logger.Info("Rate limit pattern reset", map[string]interface{}{
+		"pattern":      pattern,
+		"limiter_type": limiterType,
+		"removed":      removed,
+	})
+
+	// O reset já foi aplicado: falha na propagação não desfaz a operação
+	if s.invalidation != nil {
+		invalidation := domain.Invalidation{Key: pattern, Type: limiterType, Reason: "bulk_reset", Pattern: true}
+		if err := s.invalidation.Broadcast(ctx, invalidation); err != nil {
+			s.logger.Warn("Failed to broadcast reset to other instances", map[string]interface{}{
+				"pattern": pattern,
+				"error":   err.Error(),
+			})
+		}
+	}
+
+	return removed, nil
+}
+
+// SetOverride aplica um limite temporário a uma chave até a expiração. Com um
+// OverrideStore, o override é gravado nele e anunciado às demais réplicas
+func (s *RateLimiterService) SetOverride(ctx context.Context, override domain.LimitOverride) error {
+	if strings.TrimSpace(override.Key) == "" {
+		return fmt.Errorf("override key is required")
+	}
+	if override.Type != domain.IPLimiter && override.Type != domain.TokenLimiter {
+		return fmt.Errorf("override type must be 'ip' or 'token'")
+	}
+	if override.Limit <= 0 {
+		return fmt.Errorf("override limit must be greater than 0")
+	}
+	if !override.ExpiresAt.After(s.now()) {
+		return fmt.Errorf("override expiration must be in the future")
+	}
+
+	storageKey := s.buildStorageKey(override.Key, override.Type)
+
+	if s.overrideStore != nil {
+		if err := s.overrideStore.SaveOverride(ctx, storageKey, override); err != nil {
+			return fmt.Errorf("failed to store override: %w", err)
+		}
+	}
+
+	s.overridesMutex.Lock()
+	s.overrides[storageKey] = override
+	s.overridesMutex.Unlock()
+
+	if s.overrideStore != nil && s.invalidation != nil {
+		invalidation := domain.Invalidation{Key: override.Key, Type: override.Type, Reason: "override"}
+		if err := s.invalidation.Broadcast(ctx, invalidation); err != nil {
+			// O override já vale aqui e está no store; as demais réplicas o carregam ao reiniciar
+			s.logger.Warn("Failed to broadcast override to other instances", map[string]interface{}{
+				"key":          s.maskToken(override.Key),
+				"limiter_type": override.Type,
+				"error":        err.Error(),
+			})
+		}
+	}
+
+	s.logger.Info("Rate limit override applied", map[string]interface{}{
+		"key":          s.maskToken(override.Key),
+		"limiter_type": override.Type,
+		"limit":        override.Limit,
+		"expires_at":   override.ExpiresAt,
+	})
+
+	if s.events != nil {
+		s.events.Publish(ctx, EventKeyOverride, map[string]interface{}{
+			"key":          maskEventKey(override.Key, override.Type),
+			"limiter_type": string(override.Type),
+			"limit":        override.Limit,
+			"expires_at":   override.ExpiresAt.UTC().Format(time.RFC3339),
+		})
+	}
+
+	return nil
+}
+
+// loadOverrides substitui os overrides locais pelos do store compartilhado; em caso
+// de falha, os atuais são mantidos
+func (s *RateLimiterService) loadOverrides() {
+	ctx, cancel := context.WithTimeout(context.Background(), overrideLoadTimeout)
+	defer cancel()
+
+	overrides, err := s.overrideStore.ListOverrides(ctx)
+	if err != nil {
+		s.logger.Warn("Failed to load shared overrides", map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	s.overridesMutex.Lock()
+	s.overrides = overrides
+	s.overridesMutex.Unlock()
+}
+
+// activeOverride retorna o override vigente para a chave, descartando os expirados
+func (s *RateLimiterService) activeOverride(key string, limiterType domain.LimiterType) (domain.LimitOverride, bool) {
+	var storageKey string
+	var override domain.LimitOverride
+	var exists bool
+
+	// Sem overrides (o caso comum), a chave de storage nem é montada
+	s.overridesMutex.RLock()
+	if len(s.overrides) > 0 {
+		storageKey = s.buildStorageKey(key, limiterType)
+		override, exists = s.overrides[storageKey]
+	}
+	s.overridesMutex.RUnlock()
+
+	if !exists {
+		return domain.LimitOverride{}, false
+	}
+
+	if s.now().Before(override.ExpiresAt) {
+		return override, true
+	}
+
+	// Expirado: volta a valer a regra configurada
+	s.overridesMutex.Lock()
+	if current, ok := s.overrides[storageKey]; ok && !s.now().Before(current.ExpiresAt) {
+		delete(s.overrides, storageKey)
+	}
+	s.overridesMutex.Unlock()
+
+	return domain.LimitOverride{}, false
+}
+
 // detectLimiterType detecta automaticamente o tipo baseado nos parâmetros
 // Prioriza token se fornecido, senão usa IP
 func (s *RateLimiterService) detectLimiterType(ip, token string) (domain.LimiterType, string) {
@@ -246,9 +1292,99 @@ func (s *RateLimiterService) detectLimiterType(ip, token string) (domain.Limiter
 	return domain.IPLimiter, ip
 }
 
+// increment incrementa o contador conforme o tipo de janela da regra
+// Retorna a contagem atual e o instante de referência do reset
+// Para cotas com rollover, o limite da regra é ajustado com o crédito acumulado
+func (s *RateLimiterService) increment(ctx context.Context, storage domain.RateLimiterStorage, storageKey string, rule *domain.RateLimitRule) (int, time.Time, error) {
+	period := domain.QuotaPeriod{Limit: rule.Limit}
+
+	switch {
+	case rule.ResetSchedule != "":
+		// Cota agendada: reset no próximo disparo da expressão cron
+		cron, err := s.parseSchedule(rule.ResetSchedule)
+		if err != nil {
+			return 0, time.Time{}, err
+		}
+		period.ResetAt = cron.Next(s.now())
+		period.RolloverPercent = rule.RolloverPercent
+
+	case rule.AlignWindow:
+		// Janela alinhada ao relógio: todas as instâncias calculam o mesmo reset
+		period.ResetAt = alignedWindowReset(s.now(), time.Duration(rule.Window)*time.Second)
+
+	default:
+		return storage.Increment(ctx, storageKey, rule.Limit, time.Duration(rule.Window)*time.Second)
+	}
+
+	status, err := storage.IncrementQuota(ctx, storageKey, period)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	rule.Limit = status.EffectiveLimit()
+	return status.Count, *status.ResetAt, nil
+}
+
+// alignedWindowReset retorna o fim da janela fixa que contém now
+// Janelas que dividem o dia (ex: 60s, 1h) começam em fronteiras do relógio (:00)
+func alignedWindowReset(now time.Time, window time.Duration) time.Time {
+	return now.Truncate(window).Add(window)
+}
+
+// storageFor retorna o backend fixado pela regra ou o storage padrão
+func (s *RateLimiterService) storageFor(rule *domain.RateLimitRule) domain.RateLimiterStorage {
+	if rule.Storage == "" {
+		return s.storage
+	}
+
+	if storage, exists := s.storages[rule.Storage]; exists {
+		return storage
+	}
+
+	s.logger.Warn("Unknown storage backend for rule, using default", map[string]interface{}{
+		"rule_id": rule.ID,
+		"storage": rule.Storage,
+	})
+	return s.storage
+}
+
+// parseSchedule interpreta e mantém em cache as expressões cron das regras
+func (s *RateLimiterService) parseSchedule(expr string) (*schedule.Schedule, error) {
+	if cached, ok := s.schedules.Load(expr); ok {
+		return cached.(*schedule.Schedule), nil
+	}
+
+	parsed, err := schedule.Parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid reset schedule: %w", err)
+	}
+
+	s.schedules.Store(expr, parsed)
+	return parsed, nil
+}
+
 // buildStorageKey constrói a chave de storage no formato padrão
 func (s *RateLimiterService) buildStorageKey(key string, limiterType domain.LimiterType) string {
-	return fmt.Sprintf("rate_limit:%s:%s", limiterType, key)
+	return storage.BuildKey(limiterType, key, s.keyOptions...)
+}
+
+// counterKey retorna a chave do contador da regra: a da família, quando o token
+// divide a cota com outros, ou a chave da própria identidade
+func (s *RateLimiterService) counterKey(rule *domain.RateLimitRule, storageKey string) string {
+	if rule.Family == "" {
+		return storageKey
+	}
+	return storage.BuildFamilyKey(rule.Family, s.keyOptions...)
+}
+
+// familyFields identifica nos logs o token de uma família, já que a storage_key
+// é a do contador compartilhado
+func (s *RateLimiterService) familyFields(rule *domain.RateLimitRule, key string, fields map[string]interface{}) map[string]interface{} {
+	if rule.Family != "" {
+		fields["family"] = rule.Family
+		fields["token"] = s.maskToken(key)
+	}
+	return fields
 }
 
 // maskToken mascara o token para logs de segurança
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/logger"
+	"rate-limiter/internal/storage"
+)
+
+// Benchmarks do caminho de decisão do service sobre o MemoryStorage, com o
+// logger de produção em nível info. Compare versões com:
+//
+//	go test -run '^$' -bench RateLimiterService -benchmem ./internal/service > new.txt
+//	benchstat old.txt new.txt
+
+func newBenchmarkService(tb testing.TB) domain.RateLimiterService {
+	tb.Helper()
+
+	log := logger.NewLoggerWithOutput("info", "json", io.Discard)
+	memory := storage.NewMemoryStorage(log)
+	tb.Cleanup(func() { memory.Close() })
+
+	config := createTestConfig()
+	config.DefaultIPLimit = 1 << 30
+	config.TokenConfigs["premium_token"] = domain.TokenConfig{Token: "premium_token", Limit: 1 << 30}
+	return NewRateLimiterService(memory, config, log)
+}
+
+// BenchmarkRateLimiterService_CheckLimit_IP mede a decisão permitida por IP
+func BenchmarkRateLimiterService_CheckLimit_IP(b *testing.B) {
+	service := newBenchmarkService(b)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := service.CheckLimit(ctx, "10.0.0.1", ""); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkRateLimiterService_CheckLimit_Token mede a decisão permitida por token configurado
+func BenchmarkRateLimiterService_CheckLimit_Token(b *testing.B) {
+	service := newBenchmarkService(b)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := service.CheckLimit(ctx, "10.0.0.1", "premium_token"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// TestRateLimiterService_CheckLimit_Allocations protege o caminho de decisão
+// contra regressões de alocação. As restantes são o resultado, a regra, as chaves
+// e os spans do OpenTelemetry (mesmo sem exporter)
+func TestRateLimiterService_CheckLimit_Allocations(t *testing.T) {
+	service := newBenchmarkService(t)
+	ctx := context.Background()
+
+	allocs := testing.AllocsPerRun(200, func() {
+		if _, err := service.CheckLimit(ctx, "10.0.0.1", ""); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	assert.LessOrEqual(t, allocs, float64(16))
+}
@@ -2,14 +2,27 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
-
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	tracenoop "go.opentelemetry.io/otel/trace/noop"
+
+	"rate-limiter/internal/cluster"
 	"rate-limiter/internal/domain"
+	"rate-limiter/internal/events"
+	"rate-limiter/internal/storage"
 )
 
 // MockStorage é um mock do RateLimiterStorage para testes
@@ -35,6 +48,30 @@ func (m *MockStorage) Increment(ctx context.Context, key string, limit int, wind
 	return args.Int(0), args.Get(1).(time.Time), args.Error(2)
 }
 
+func (m *MockStorage) IncrementQuota(ctx context.Context, key string, period domain.QuotaPeriod) (*domain.RateLimitStatus, error) {
+	args := m.Called(ctx, key, period)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RateLimitStatus), args.Error(1)
+}
+
+func (m *MockStorage) TakeToken(ctx context.Context, key string, bucket domain.TokenBucket) (*domain.RateLimitStatus, error) {
+	args := m.Called(ctx, key, bucket)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RateLimitStatus), args.Error(1)
+}
+
+func (m *MockStorage) Leak(ctx context.Context, key string, bucket domain.LeakyBucket) (*domain.RateLimitStatus, error) {
+	args := m.Called(ctx, key, bucket)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.RateLimitStatus), args.Error(1)
+}
+
 func (m *MockStorage) IsBlocked(ctx context.Context, key string) (bool, *time.Time, error) {
 	args := m.Called(ctx, key)
 	var blockTime *time.Time
@@ -109,6 +146,9 @@ func createTestConfig() *domain.RateLimitConfig {
 				Description: "Token básico com limite reduzido",
 			},
 		},
+		IPConfigs: map[string]domain.IPConfig{
+			"10.0.0.5": {IP: "10.0.0.5", Limit: 30, Description: "Parceiro com IP fixo"},
+		},
 	}
 }
 
@@ -359,6 +399,12 @@ func TestRateLimiterService_GetConfig(t *testing.T) {
 			limiterType:   domain.IPLimiter,
 			expectedLimit: 10,
 		},
+		{
+			name:          "Should get specific IP config",
+			key:           "10.0.0.5",
+			limiterType:   domain.IPLimiter,
+			expectedLimit: 30,
+		},
 		{
 			name:          "Should get premium token config",
 			key:           "premium_token",
@@ -389,7 +435,8 @@ func TestRateLimiterService_GetConfig(t *testing.T) {
 			service := NewRateLimiterService(mockStorage, config, mockLogger)
 
 			// Act
-			rule := service.GetConfig(tt.key, tt.limiterType)
+			rule, err := service.GetConfig(context.Background(), tt.key, tt.limiterType)
+			require.NoError(t, err)
 
 			// Assert
 			assert.NotNil(t, rule)
@@ -427,6 +474,25 @@ func TestRateLimiterService_Reset(t *testing.T) {
 	mockLogger.AssertExpectations(t)
 }
 
+// TestRateLimiterService_Reset_HashTaggedKeys testa o formato de chave para Redis Cluster
+func TestRateLimiterService_Reset_HashTaggedKeys(t *testing.T) {
+	// Arrange
+	mockStorage := new(MockStorage)
+	mockLogger := new(MockLogger)
+	service := NewRateLimiterService(mockStorage, createTestConfig(), mockLogger, WithHashTaggedKeys())
+	ctx := context.Background()
+
+	mockStorage.On("Reset", ctx, "rate_limit:token:{abc123}").Return(nil)
+	mockLogger.On("Info", mock.AnythingOfType("string"), mock.AnythingOfType("map[string]interface {}")).Once()
+
+	// Act
+	err := service.Reset(ctx, "abc123", domain.TokenLimiter)
+
+	// Assert
+	assert.NoError(t, err)
+	mockStorage.AssertExpectations(t)
+}
+
 // TestRateLimiterService_GetStatus testa obtenção de status
 func TestRateLimiterService_GetStatus(t *testing.T) {
 	// Arrange
@@ -586,4 +652,1479 @@ func TestRateLimiterService_DetectLimiterType(t *testing.T) {
 			mockStorage.AssertExpectations(t)
 		})
 	}
-} 
\ No newline at end of file
+} 
+
+// TestRateLimiterService_CheckLimit_ScheduledReset testa cotas com reset agendado (cron)
+func TestRateLimiterService_CheckLimit_ScheduledReset(t *testing.T) {
+	// Arrange
+	mockStorage := new(MockStorage)
+	mockLogger := new(MockLogger)
+	config := createTestConfig()
+	config.TokenConfigs["daily_token"] = domain.TokenConfig{
+		Token:         "daily_token",
+		Limit:         1000,
+		ResetSchedule: "@daily",
+	}
+
+	service := NewRateLimiterService(mockStorage, config, mockLogger)
+	ctx := context.Background()
+	expectedKey := "rate_limit:token:daily_token"
+
+	now := time.Now().UTC()
+	midnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+
+	mockStorage.On("IsBlocked", ctx, expectedKey).Return(false, nil, nil)
+	mockStorage.On("IncrementQuota", ctx, expectedKey, mock.MatchedBy(func(period domain.QuotaPeriod) bool {
+		return period.Limit == 1000 && period.ResetAt.Equal(midnight)
+	})).Return(&domain.RateLimitStatus{Count: 10, Limit: 1000, ResetAt: &midnight}, nil)
+	mockLogger.On("Debug", mock.AnythingOfType("string"), mock.Anything).Maybe()
+
+	// Act
+	result, err := service.CheckLimit(ctx, "192.168.1.1", "daily_token")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 990, result.Remaining)
+	assert.Equal(t, midnight, result.ResetTime)
+	mockStorage.AssertExpectations(t)
+	mockStorage.AssertNotCalled(t, "Increment", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRateLimiterService_CheckLimit_TokenBucket(t *testing.T) {
+	// Arrange: rajada de 10, 2 fichas por segundo
+	mockStorage := new(MockStorage)
+	mockLogger := new(MockLogger)
+	config := createTestConfig()
+	config.TokenConfigs["burst_token"] = domain.TokenConfig{
+		Token:      "burst_token",
+		Limit:      100,
+		Capacity:   10,
+		RefillRate: 2,
+	}
+
+	service := NewRateLimiterService(mockStorage, config, mockLogger)
+	ctx := context.Background()
+	expectedKey := "rate_limit:token:burst_token"
+	bucket := domain.TokenBucket{Capacity: 10, RefillRate: 2}
+	full := time.Now().Add(1500 * time.Millisecond)
+	nextToken := time.Now().Add(300 * time.Millisecond)
+
+	mockStorage.On("IsBlocked", ctx, expectedKey).Return(false, nil, nil)
+	mockStorage.On("TakeToken", ctx, expectedKey, bucket).
+		Return(&domain.RateLimitStatus{Count: 3, Limit: 10, ResetAt: &full}, nil).Once()
+	mockStorage.On("TakeToken", ctx, expectedKey, bucket).
+		Return(&domain.RateLimitStatus{Count: 11, Limit: 10, ResetAt: &full, IsBlocked: true, BlockedUntil: &nextToken}, nil).Once()
+	mockLogger.On("Debug", mock.AnythingOfType("string"), mock.Anything).Maybe()
+	mockLogger.On("Info", mock.AnythingOfType("string"), mock.Anything).Maybe()
+
+	// Act
+	allowed, err := service.CheckLimit(ctx, "192.168.1.1", "burst_token")
+	require.NoError(t, err)
+	rejected, err := service.CheckLimit(ctx, "192.168.1.1", "burst_token")
+	require.NoError(t, err)
+
+	// Assert: a negação aponta a próxima ficha e não bloqueia a chave
+	assert.True(t, allowed.Allowed)
+	assert.Equal(t, 10, allowed.Limit)
+	assert.Equal(t, 7, allowed.Remaining)
+	assert.Equal(t, full, allowed.ResetTime)
+
+	assert.False(t, rejected.Allowed)
+	assert.Equal(t, 0, rejected.Remaining)
+	assert.Equal(t, &nextToken, rejected.BlockedUntil)
+	mockStorage.AssertExpectations(t)
+	mockStorage.AssertNotCalled(t, "Block", mock.Anything, mock.Anything, mock.Anything)
+	mockStorage.AssertNotCalled(t, "Increment", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRateLimiterService_CheckLimit_LeakyBucket(t *testing.T) {
+	// Arrange: fila de 5, escoada a 10 requisições por segundo
+	mockStorage := new(MockStorage)
+	mockLogger := new(MockLogger)
+	config := createTestConfig()
+	config.TokenConfigs["smooth_token"] = domain.TokenConfig{
+		Token:    "smooth_token",
+		Limit:    100,
+		Capacity: 5,
+		LeakRate: 10,
+	}
+
+	service := NewRateLimiterService(mockStorage, config, mockLogger)
+	ctx := context.Background()
+	expectedKey := "rate_limit:token:smooth_token"
+	bucket := domain.LeakyBucket{Capacity: 5, LeakRate: 10}
+	empty := time.Now().Add(300 * time.Millisecond)
+	releaseAt := time.Now().Add(200 * time.Millisecond)
+	nextSlot := time.Now().Add(100 * time.Millisecond)
+
+	mockStorage.On("IsBlocked", ctx, expectedKey).Return(false, nil, nil)
+	mockStorage.On("Leak", ctx, expectedKey, bucket).
+		Return(&domain.RateLimitStatus{Count: 3, Limit: 5, ResetAt: &empty, ReleaseAt: &releaseAt}, nil).Once()
+	mockStorage.On("Leak", ctx, expectedKey, bucket).
+		Return(&domain.RateLimitStatus{Count: 6, Limit: 5, ResetAt: &empty, IsBlocked: true, BlockedUntil: &nextSlot}, nil).Once()
+	mockLogger.On("Debug", mock.AnythingOfType("string"), mock.Anything).Maybe()
+	mockLogger.On("Info", mock.AnythingOfType("string"), mock.Anything).Maybe()
+
+	// Act
+	queued, err := service.CheckLimit(ctx, "192.168.1.1", "smooth_token")
+	require.NoError(t, err)
+	overflow, err := service.CheckLimit(ctx, "192.168.1.1", "smooth_token")
+	require.NoError(t, err)
+
+	// Assert: a requisição na fila leva o instante de saída; o transbordo não bloqueia a chave
+	assert.True(t, queued.Allowed)
+	assert.Equal(t, 5, queued.Limit)
+	assert.Equal(t, 2, queued.Remaining)
+	assert.Equal(t, &releaseAt, queued.ReleaseAt)
+
+	assert.False(t, overflow.Allowed)
+	assert.Equal(t, 0, overflow.Remaining)
+	assert.Nil(t, overflow.ReleaseAt)
+	assert.Equal(t, &nextSlot, overflow.BlockedUntil)
+	mockStorage.AssertExpectations(t)
+	mockStorage.AssertNotCalled(t, "Block", mock.Anything, mock.Anything, mock.Anything)
+	mockStorage.AssertNotCalled(t, "TakeToken", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRateLimiterService_CheckLimit_AlignedWindow(t *testing.T) {
+	// Arrange
+	mockStorage := new(MockStorage)
+	mockLogger := new(MockLogger)
+	config := createTestConfig()
+	config.AlignWindows = true
+	config.QuotaRolloverPercent = 50
+
+	service := NewRateLimiterService(mockStorage, config, mockLogger)
+	ctx := context.Background()
+	expectedKey := "rate_limit:ip:192.168.1.1"
+
+	before := time.Now()
+	var resetAt time.Time
+	status := &domain.RateLimitStatus{Count: 3, Limit: 10}
+	mockStorage.On("IsBlocked", ctx, expectedKey).Return(false, nil, nil)
+	mockStorage.On("IncrementQuota", ctx, expectedKey, mock.MatchedBy(func(period domain.QuotaPeriod) bool {
+		return period.Limit == 10 && period.RolloverPercent == 0
+	})).Run(func(args mock.Arguments) {
+		resetAt = args.Get(2).(domain.QuotaPeriod).ResetAt
+		status.ResetAt = &resetAt
+	}).Return(status, nil)
+	mockLogger.On("Debug", mock.AnythingOfType("string"), mock.Anything).Maybe()
+
+	// Act
+	result, err := service.CheckLimit(ctx, "192.168.1.1", "")
+
+	// Assert - reset no próximo :00, no máximo uma janela à frente
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 7, result.Remaining)
+	assert.Equal(t, 0, resetAt.Second())
+	assert.Equal(t, 0, resetAt.Nanosecond())
+	assert.True(t, resetAt.After(before))
+	assert.False(t, resetAt.After(before.Add(time.Minute)))
+	assert.Equal(t, resetAt, result.ResetTime)
+	mockStorage.AssertNotCalled(t, "Increment", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAlignedWindowReset(t *testing.T) {
+	now := time.Date(2024, 1, 1, 10, 17, 42, 500, time.UTC)
+
+	assert.Equal(t, time.Date(2024, 1, 1, 10, 18, 0, 0, time.UTC), alignedWindowReset(now, time.Minute))
+	assert.Equal(t, time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC), alignedWindowReset(now, time.Hour))
+	assert.Equal(t, time.Date(2024, 1, 1, 10, 17, 45, 0, time.UTC), alignedWindowReset(now, 15*time.Second))
+
+	// Exatamente na fronteira, a janela atual termina na próxima fronteira
+	boundary := time.Date(2024, 1, 1, 10, 18, 0, 0, time.UTC)
+	assert.Equal(t, boundary.Add(time.Minute), alignedWindowReset(boundary, time.Minute))
+}
+
+func TestRateLimiterService_CheckLimit_QuotaRollover(t *testing.T) {
+	// Arrange
+	mockStorage := new(MockStorage)
+	mockLogger := new(MockLogger)
+	config := createTestConfig()
+	config.QuotaRolloverPercent = 25
+	config.TokenConfigs["monthly_token"] = domain.TokenConfig{
+		Token:         "monthly_token",
+		Limit:         100,
+		ResetSchedule: "@monthly",
+	}
+
+	service := NewRateLimiterService(mockStorage, config, mockLogger)
+	ctx := context.Background()
+	expectedKey := "rate_limit:token:monthly_token"
+	resetAt := time.Now().Add(24 * time.Hour)
+
+	mockStorage.On("IsBlocked", ctx, expectedKey).Return(false, nil, nil)
+	mockStorage.On("IncrementQuota", ctx, expectedKey, mock.MatchedBy(func(period domain.QuotaPeriod) bool {
+		return period.Limit == 100 && period.RolloverPercent == 25
+	})).Return(&domain.RateLimitStatus{Count: 110, Limit: 100, Credit: 20, ResetAt: &resetAt}, nil)
+	mockLogger.On("Debug", mock.AnythingOfType("string"), mock.Anything).Maybe()
+
+	// Act
+	result, err := service.CheckLimit(ctx, "192.168.1.1", "monthly_token")
+
+	// Assert - o crédito amplia o limite efetivo
+	assert.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 120, result.Limit)
+	assert.Equal(t, 10, result.Remaining)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestRateLimiterService_SetOverride(t *testing.T) {
+	// Arrange
+	mockStorage := new(MockStorage)
+	mockLogger := new(MockLogger)
+	mockLogger.On("Info", mock.AnythingOfType("string"), mock.Anything).Maybe()
+	service := NewRateLimiterService(mockStorage, createTestConfig(), mockLogger)
+	ctx := context.Background()
+
+	// Act
+	err := service.SetOverride(ctx, domain.LimitOverride{
+		Key:       "basic_token",
+		Type:      domain.TokenLimiter,
+		Limit:     5000,
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+
+	// Assert - override tem precedência sobre o tokens.json
+	assert.NoError(t, err)
+	assert.Equal(t, 5000, mustGetConfig(t, service, "basic_token", domain.TokenLimiter).Limit)
+	assert.Equal(t, 10, mustGetConfig(t, service, "192.168.1.1", domain.IPLimiter).Limit)
+
+	// Override expirado volta para a regra configurada
+	impl := service.(*RateLimiterService)
+	impl.overrides["rate_limit:token:basic_token"] = domain.LimitOverride{
+		Key:       "basic_token",
+		Type:      domain.TokenLimiter,
+		Limit:     5000,
+		ExpiresAt: time.Now().Add(-time.Second),
+	}
+	assert.Equal(t, 50, mustGetConfig(t, service, "basic_token", domain.TokenLimiter).Limit)
+	assert.Empty(t, impl.overrides)
+
+	// Validações
+	assert.Error(t, service.SetOverride(ctx, domain.LimitOverride{Key: "k", Type: domain.IPLimiter, Limit: 0, ExpiresAt: time.Now().Add(time.Hour)}))
+	assert.Error(t, service.SetOverride(ctx, domain.LimitOverride{Key: "k", Type: domain.IPLimiter, Limit: 1, ExpiresAt: time.Now().Add(-time.Hour)}))
+	assert.Error(t, service.SetOverride(ctx, domain.LimitOverride{Key: "k", Type: domain.IPLimiter, Limit: -1, ExpiresAt: time.Now().Add(time.Hour)}))
+	assert.Error(t, service.SetOverride(ctx, domain.LimitOverride{Key: " ", Type: domain.IPLimiter, Limit: 1, ExpiresAt: time.Now().Add(time.Hour)}))
+	assert.Error(t, service.SetOverride(ctx, domain.LimitOverride{Key: "k", Type: "user", Limit: 1, ExpiresAt: time.Now().Add(time.Hour)}))
+	assert.Empty(t, impl.overrides)
+}
+
+// TestRateLimiterService_SetOverride_SharedAcrossInstances testa o override gravado no store compartilhado
+func TestRateLimiterService_SetOverride_SharedAcrossInstances(t *testing.T) {
+	// Arrange: duas réplicas com o mesmo store de overrides e barramento de invalidação
+	ctx := context.Background()
+	store := cluster.NewMemoryOverrideStore()
+	bus := cluster.NewLocalInvalidationBus("instance-a")
+	mockLogger := new(MockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Maybe()
+
+	instanceA := NewRateLimiterService(new(MockStorage), createTestConfig(), mockLogger, WithOverrideStore(store), WithInvalidation(bus))
+	instanceB := NewRateLimiterService(new(MockStorage), createTestConfig(), mockLogger, WithOverrideStore(store), WithInvalidation(bus))
+
+	// Act
+	err := instanceA.SetOverride(ctx, domain.LimitOverride{
+		Key:       "basic_token",
+		Type:      domain.TokenLimiter,
+		Limit:     5000,
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+
+	// Assert: a outra réplica aplica o override sem tocar nos contadores
+	require.NoError(t, err)
+	assert.Equal(t, 5000, mustGetConfig(t, instanceB, "basic_token", domain.TokenLimiter).Limit)
+
+	// Uma réplica iniciada depois (ou reiniciada) carrega o override do store
+	restarted := NewRateLimiterService(new(MockStorage), createTestConfig(), mockLogger, WithOverrideStore(store))
+	assert.Equal(t, 5000, mustGetConfig(t, restarted, "basic_token", domain.TokenLimiter).Limit)
+	assert.Equal(t, 10, mustGetConfig(t, restarted, "192.168.1.1", domain.IPLimiter).Limit)
+}
+
+func TestRateLimiterService_CheckLimit_NamedStorage(t *testing.T) {
+	// Arrange
+	defaultStorage := new(MockStorage)
+	localStorage := new(MockStorage)
+	mockLogger := new(MockLogger)
+	config := createTestConfig()
+	config.IPStorage = "memory"
+
+	service := NewRateLimiterService(defaultStorage, config, mockLogger, WithStorages(map[string]domain.RateLimiterStorage{
+		"memory": localStorage,
+	}))
+	ctx := context.Background()
+	resetTime := time.Now().Add(time.Minute)
+
+	localStorage.On("IsBlocked", ctx, "rate_limit:ip:192.168.1.1").Return(false, nil, nil)
+	localStorage.On("Increment", ctx, "rate_limit:ip:192.168.1.1", 10, 60*time.Second).Return(1, resetTime, nil)
+	defaultStorage.On("IsBlocked", ctx, "rate_limit:token:premium_token").Return(false, nil, nil)
+	defaultStorage.On("Increment", ctx, "rate_limit:token:premium_token", 1000, 60*time.Second).Return(1, resetTime, nil)
+	mockLogger.On("Debug", mock.AnythingOfType("string"), mock.Anything).Maybe()
+
+	// Act
+	ipResult, ipErr := service.CheckLimit(ctx, "192.168.1.1", "")
+	tokenResult, tokenErr := service.CheckLimit(ctx, "192.168.1.1", "premium_token")
+
+	// Assert - IP usa o backend fixado, token usa o padrão
+	assert.NoError(t, ipErr)
+	assert.NoError(t, tokenErr)
+	assert.True(t, ipResult.Allowed)
+	assert.True(t, tokenResult.Allowed)
+	localStorage.AssertExpectations(t)
+	defaultStorage.AssertExpectations(t)
+}
+
+// localMockStorage simula um storage restrito à instância (ex: memória)
+type localMockStorage struct {
+	*MockStorage
+}
+
+func (localMockStorage) IsLocal() bool { return true }
+
+// fixedInstanceCounter simula o número de réplicas vivas
+type fixedInstanceCounter int
+
+func (c fixedInstanceCounter) LiveInstances() int { return int(c) }
+
+func TestRateLimiterService_GetConfig_LimitPartitioning(t *testing.T) {
+	mockLogger := new(MockLogger)
+	config := createTestConfig()
+	config.TokenStorage = "redis"
+
+	service := NewRateLimiterService(
+		localMockStorage{new(MockStorage)},
+		config,
+		mockLogger,
+		WithStorages(map[string]domain.RateLimiterStorage{"redis": new(MockStorage)}),
+		WithLimitPartitioning(fixedInstanceCounter(3)),
+	)
+
+	// Storage local: limite dividido (arredondado para cima)
+	assert.Equal(t, 4, mustGetConfig(t, service, "192.168.1.1", domain.IPLimiter).Limit)
+
+	// Storage global: limite mantido
+	assert.Equal(t, 1000, mustGetConfig(t, service, "premium_token", domain.TokenLimiter).Limit)
+
+	// Instância única: limite mantido
+	single := NewRateLimiterService(localMockStorage{new(MockStorage)}, createTestConfig(), mockLogger, WithLimitPartitioning(fixedInstanceCounter(1)))
+	assert.Equal(t, 10, mustGetConfig(t, single, "192.168.1.1", domain.IPLimiter).Limit)
+}
+
+// staticTokenProvider simula uma fonte dinâmica de tokens
+type staticTokenProvider map[string]domain.TokenConfig
+
+func (p staticTokenProvider) TokenConfig(ctx context.Context, token string) (domain.TokenConfig, bool, error) {
+	config, exists := p[token]
+	return config, exists, nil
+}
+
+// failingTokenProvider simula uma fonte dinâmica indisponível
+type failingTokenProvider struct{}
+
+func (failingTokenProvider) TokenConfig(ctx context.Context, token string) (domain.TokenConfig, bool, error) {
+	return domain.TokenConfig{}, false, errors.New("connection refused")
+}
+
+// mustGetConfig resolve a regra falhando o teste em caso de erro
+func mustGetConfig(t *testing.T, service domain.RateLimiterService, key string, limiterType domain.LimiterType) *domain.RateLimitRule {
+	t.Helper()
+	rule, err := service.GetConfig(context.Background(), key, limiterType)
+	require.NoError(t, err)
+	return rule
+}
+
+func TestRateLimiterService_GetConfig_TokenConfigProvider(t *testing.T) {
+	service := NewRateLimiterService(new(MockStorage), createTestConfig(), new(MockLogger), WithTokenConfigProvider(staticTokenProvider{
+		"billing_token": {Token: "billing_token", Limit: 250, Description: "From billing"},
+	}))
+
+	// Fonte dinâmica substitui o mapa estático do tokens.json
+	rule := mustGetConfig(t, service, "billing_token", domain.TokenLimiter)
+	assert.Equal(t, 250, rule.Limit)
+	assert.Equal(t, "From billing", rule.Description)
+
+	// Token ausente na fonte usa o limite padrão
+	assert.Equal(t, 100, mustGetConfig(t, service, "premium_token", domain.TokenLimiter).Limit)
+}
+
+// swappableConfig simula uma fonte de configuração trocada em execução
+type swappableConfig struct {
+	active *domain.RateLimitConfig
+}
+
+func (s *swappableConfig) ActiveConfig() *domain.RateLimitConfig { return s.active }
+
+func TestRateLimiterService_GetConfig_ConfigSource(t *testing.T) {
+	source := &swappableConfig{active: createTestConfig()}
+	service := NewRateLimiterService(new(MockStorage), createTestConfig(), new(MockLogger), WithConfigSource(source))
+	assert.Equal(t, 10, mustGetConfig(t, service, "192.168.1.1", domain.IPLimiter).Limit)
+
+	// A troca vale a partir da próxima verificação
+	promoted := createTestConfig()
+	promoted.DefaultIPLimit = 25
+	promoted.TokenConfigs = map[string]domain.TokenConfig{}
+	source.active = promoted
+
+	assert.Equal(t, 25, mustGetConfig(t, service, "192.168.1.1", domain.IPLimiter).Limit)
+	assert.Equal(t, 100, mustGetConfig(t, service, "premium_token", domain.TokenLimiter).Limit)
+}
+
+func TestRateLimiterService_CheckLimit_TokenConfigProviderError(t *testing.T) {
+	// Arrange
+	mockStorage := new(MockStorage)
+	mockLogger := new(MockLogger)
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Return()
+	mockLogger.On("Error", "Failed to resolve rate limit rule", mock.Anything, mock.Anything).Return()
+	service := NewRateLimiterService(mockStorage, createTestConfig(), mockLogger, WithTokenConfigProvider(failingTokenProvider{}))
+
+	// Act
+	result, err := service.CheckLimit(context.Background(), "192.168.1.1", "billing_token")
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorContains(t, err, "failed to resolve token config")
+	mockStorage.AssertNotCalled(t, "IsBlocked", mock.Anything, mock.Anything)
+
+	// IP não depende da fonte dinâmica
+	_, err = service.GetConfig(context.Background(), "192.168.1.1", domain.IPLimiter)
+	assert.NoError(t, err)
+}
+
+// fixedMaintenance simula uma janela de manutenção sempre ativa
+type fixedMaintenance domain.MaintenanceWindow
+
+func (m fixedMaintenance) ActiveMaintenance(key string, limiterType domain.LimiterType) (domain.MaintenanceWindow, bool) {
+	window := domain.MaintenanceWindow(m)
+	return window, window.Matches(key, limiterType)
+}
+
+func TestRateLimiterService_GetConfig_Maintenance(t *testing.T) {
+	relaxed := NewRateLimiterService(new(MockStorage), createTestConfig(), new(MockLogger), WithMaintenance(fixedMaintenance{
+		ID: "deploy", Type: domain.IPLimiter, Factor: 2.5,
+	}))
+
+	// Limite multiplicado (arredondado para cima) apenas no escopo da janela
+	rule := mustGetConfig(t, relaxed, "192.168.1.1", domain.IPLimiter)
+	assert.Equal(t, 25, rule.Limit)
+	assert.False(t, rule.Disabled)
+	assert.Contains(t, rule.Description, "maintenance window deploy")
+	assert.Equal(t, 1000, mustGetConfig(t, relaxed, "premium_token", domain.TokenLimiter).Limit)
+}
+
+func TestRateLimiterService_CheckLimit_MaintenanceDisabled(t *testing.T) {
+	// Arrange
+	mockStorage := new(MockStorage)
+	mockLogger := new(MockLogger)
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Return()
+	service := NewRateLimiterService(mockStorage, createTestConfig(), mockLogger, WithMaintenance(fixedMaintenance{
+		ID: "migration", Disabled: true,
+	}))
+
+	// Act
+	result, err := service.CheckLimit(context.Background(), "192.168.1.1", "")
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 10, result.Remaining)
+	mockStorage.AssertNotCalled(t, "IsBlocked", mock.Anything, mock.Anything)
+	mockStorage.AssertNotCalled(t, "Increment", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// fakeRollouts simula um rollout com versão fixa e registra as decisões
+type fakeRollouts struct {
+	rollout   domain.RuleRollout
+	version   string
+	decisions []string
+}
+
+func (f *fakeRollouts) ResolveRollout(key string, limiterType domain.LimiterType) (domain.RuleRollout, string, bool) {
+	return f.rollout, f.version, limiterType == f.rollout.Type
+}
+
+func (f *fakeRollouts) RecordDecision(rolloutID, version string, allowed bool) {
+	f.decisions = append(f.decisions, fmt.Sprintf("%s/%s/%t", rolloutID, version, allowed))
+}
+
+func TestRateLimiterService_CheckLimit_RuleRollout(t *testing.T) {
+	tests := []struct {
+		name          string
+		version       string
+		expectedLimit int
+	}{
+		{name: "Canary version uses the new limit", version: domain.RuleVersionCanary, expectedLimit: 20},
+		{name: "Stable version keeps the configured limit", version: domain.RuleVersionStable, expectedLimit: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			mockStorage := new(MockStorage)
+			mockLogger := new(MockLogger)
+			rollouts := &fakeRollouts{
+				rollout: domain.RuleRollout{ID: "ip:*", Type: domain.IPLimiter, Limit: 20, Percent: 50},
+				version: tt.version,
+			}
+			service := NewRateLimiterService(mockStorage, createTestConfig(), mockLogger, WithRuleRollouts(rollouts))
+
+			mockStorage.On("IsBlocked", ctx, "rate_limit:ip:192.168.1.1").Return(false, (*time.Time)(nil), nil)
+			mockStorage.On("Increment", ctx, "rate_limit:ip:192.168.1.1", tt.expectedLimit, 60*time.Second).Return(1, time.Now(), nil)
+			mockLogger.On("Debug", mock.Anything, mock.Anything).Maybe()
+
+			// Act
+			result, err := service.CheckLimit(ctx, "192.168.1.1", "")
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedLimit, result.Limit)
+			assert.Equal(t, []string{"ip:*/" + tt.version + "/true"}, rollouts.decisions)
+			mockStorage.AssertExpectations(t)
+		})
+	}
+}
+
+// unhealthyReporter simula um storage degradado
+type unhealthyReporter struct{}
+
+func (unhealthyReporter) IsHealthy() bool { return false }
+
+func (unhealthyReporter) HealthStatus() domain.StorageHealth {
+	return domain.StorageHealth{Healthy: false, LastError: "connection refused"}
+}
+
+// TestRateLimiterService_CheckLimit_StorageDegraded testa que o storage não é acessado quando degradado
+func TestRateLimiterService_CheckLimit_StorageDegraded(t *testing.T) {
+	// Arrange
+	mockStorage := new(MockStorage)
+	mockLogger := new(MockLogger)
+	mockLogger.On("Debug", mock.AnythingOfType("string"), mock.Anything).Maybe()
+
+	service := NewRateLimiterService(mockStorage, createTestConfig(), mockLogger, WithHealthReporter(unhealthyReporter{}))
+
+	// Act
+	result, err := service.CheckLimit(context.Background(), "192.168.1.1", "")
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrStorageUnavailable)
+	assert.Nil(t, result)
+	mockStorage.AssertNotCalled(t, "IsBlocked", mock.Anything, mock.Anything)
+}
+
+// recordingTraffic registra as requisições observadas
+type recordingTraffic struct {
+	observed []string
+}
+
+func (r *recordingTraffic) ObserveRequest(key string, limiterType domain.LimiterType) {
+	r.observed = append(r.observed, string(limiterType)+":"+key)
+}
+
+func (r *recordingTraffic) ObserveDecision(key string, limiterType domain.LimiterType, allowed bool) {
+	r.observed = append(r.observed, fmt.Sprintf("%s:%s:%t", limiterType, key, allowed))
+}
+
+// TestRateLimiterService_CheckLimit_DecisionObserver testa que apenas decisões concluídas são observadas,
+// por todos os observers registrados
+func TestRateLimiterService_CheckLimit_DecisionObserver(t *testing.T) {
+	// Arrange
+	mockStorage := new(MockStorage)
+	mockLogger := new(MockLogger)
+	mockLogger.On("Debug", mock.AnythingOfType("string"), mock.Anything).Maybe()
+	decisions, stats := &recordingTraffic{}, &recordingTraffic{}
+
+	service := NewRateLimiterService(mockStorage, createTestConfig(), mockLogger, WithDecisionObserver(decisions),
+		WithDecisionObserver(stats), WithMaintenance(fixedMaintenance{ID: "migration", Disabled: true}))
+	degraded := NewRateLimiterService(mockStorage, createTestConfig(), mockLogger, WithDecisionObserver(decisions),
+		WithHealthReporter(unhealthyReporter{}))
+
+	// Act
+	_, err := service.CheckLimit(context.Background(), "192.168.1.1", "")
+	require.NoError(t, err)
+	_, err = degraded.CheckLimit(context.Background(), "192.168.1.1", "")
+	require.Error(t, err)
+
+	// Assert
+	assert.Equal(t, []string{"ip:192.168.1.1:true"}, decisions.observed)
+	assert.Equal(t, decisions.observed, stats.observed)
+}
+
+// TestRateLimiterService_CheckLimit_AllIdentities testa a contabilização do IP
+// das requisições limitadas pelo token, sem bloqueá-lo
+func TestRateLimiterService_CheckLimit_AllIdentities(t *testing.T) {
+	// Arrange: IP limitado a 2 requisições
+	ctx := context.Background()
+	mockLogger := new(MockLogger)
+	mockLogger.On("Debug", mock.AnythingOfType("string"), mock.Anything).Maybe()
+	mockLogger.On("Info", mock.AnythingOfType("string"), mock.Anything).Maybe()
+	config := createTestConfig()
+	config.DefaultIPLimit = 2
+	decisions := &recordingTraffic{}
+	service := NewRateLimiterService(storage.NewMemoryStorage(nil), config, mockLogger,
+		WithDecisionObserver(decisions), WithAllIdentities())
+
+	// Act
+	for i := 0; i < 3; i++ {
+		result, err := service.CheckLimit(ctx, "10.0.0.1", "premium_token")
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+	}
+
+	// Assert: o IP teria sido negado na terceira, mas não foi bloqueado
+	assert.Equal(t, "ip:10.0.0.1:false", decisions.observed[len(decisions.observed)-1])
+	assert.Equal(t, "token:premium_token:true", decisions.observed[len(decisions.observed)-2])
+	status, err := service.GetStatus(ctx, "10.0.0.1", domain.IPLimiter)
+	require.NoError(t, err)
+	assert.Equal(t, 3, status.Count)
+	assert.Nil(t, status.BlockedUntil)
+
+	// Sem o modo, o IP não é contabilizado
+	single := NewRateLimiterService(storage.NewMemoryStorage(nil), config, mockLogger)
+	_, err = single.CheckLimit(ctx, "10.0.0.1", "premium_token")
+	require.NoError(t, err)
+	status, err = single.GetStatus(ctx, "10.0.0.1", domain.IPLimiter)
+	require.NoError(t, err)
+	assert.Nil(t, status)
+}
+
+// TestRateLimiterService_CheckLimit_TrafficObserver testa que o tráfego é observado mesmo sem decisão
+func TestRateLimiterService_CheckLimit_TrafficObserver(t *testing.T) {
+	// Arrange
+	mockStorage := new(MockStorage)
+	mockLogger := new(MockLogger)
+	mockLogger.On("Debug", mock.AnythingOfType("string"), mock.Anything).Maybe()
+	traffic := &recordingTraffic{}
+
+	service := NewRateLimiterService(mockStorage, createTestConfig(), mockLogger,
+		WithHealthReporter(unhealthyReporter{}), WithTrafficObserver(traffic))
+
+	// Act
+	_, _ = service.CheckLimit(context.Background(), "192.168.1.1", "")
+	_, _ = service.CheckLimit(context.Background(), "192.168.1.1", " premium_token ")
+
+	// Assert
+	assert.Equal(t, []string{"ip:192.168.1.1", "token:premium_token"}, traffic.observed)
+}
+
+// recordingBlocks registra os bloqueios e recusas informados pelo serviço
+type recordingBlocks struct {
+	blocks   []domain.BlockRecord
+	rejected []string
+}
+
+func (r *recordingBlocks) RecordBlock(record domain.BlockRecord) {
+	r.blocks = append(r.blocks, record)
+}
+
+func (r *recordingBlocks) RecordRejected(key string, limiterType domain.LimiterType) {
+	r.rejected = append(r.rejected, string(limiterType)+":"+key)
+}
+
+// TestRateLimiterService_CheckLimit_BlockRecorder testa o registro de bloqueios para relatórios
+func TestRateLimiterService_CheckLimit_BlockRecorder(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockStorage := new(MockStorage)
+	mockLogger := new(MockLogger)
+	blocks := &recordingBlocks{}
+	service := NewRateLimiterService(mockStorage, createTestConfig(), mockLogger, WithBlockRecorder(blocks))
+
+	mockStorage.On("IsBlocked", ctx, "rate_limit:ip:192.168.1.1").Return(false, (*time.Time)(nil), nil).Once()
+	mockStorage.On("Increment", ctx, "rate_limit:ip:192.168.1.1", 10, 60*time.Second).Return(11, time.Now(), nil)
+	mockStorage.On("Block", ctx, "rate_limit:ip:192.168.1.1", 180*time.Second).Return(nil)
+	blockedUntil := time.Now().Add(3 * time.Minute)
+	mockStorage.On("IsBlocked", ctx, "rate_limit:ip:192.168.1.1").Return(true, &blockedUntil, nil).Once()
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Maybe()
+	mockLogger.On("Info", mock.Anything, mock.Anything).Maybe()
+
+	// Act
+	first, err := service.CheckLimit(ctx, "192.168.1.1", "")
+	require.NoError(t, err)
+	second, err := service.CheckLimit(ctx, "192.168.1.1", "")
+	require.NoError(t, err)
+
+	// Assert
+	assert.False(t, first.Allowed)
+	assert.False(t, second.Allowed)
+	require.Len(t, blocks.blocks, 1)
+	assert.Equal(t, "192.168.1.1", blocks.blocks[0].Key)
+	assert.Equal(t, 11, blocks.blocks[0].RequestCount)
+	assert.Equal(t, 180, blocks.blocks[0].Duration)
+	assert.Equal(t, []string{"ip:192.168.1.1"}, blocks.rejected)
+	mockStorage.AssertExpectations(t)
+}
+
+// TestRateLimiterService_EventPublisher testa os eventos de bloqueio e de desbloqueio por reset
+func TestRateLimiterService_EventPublisher(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	memory := storage.NewMemoryStorage(nil)
+	defer memory.Close()
+	mockLogger := new(MockLogger)
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Maybe()
+	mockLogger.On("Info", mock.Anything, mock.Anything).Maybe()
+
+	bus := events.NewBus()
+	var published []events.Event
+	bus.Subscribe(func(event events.Event) { published = append(published, event) })
+	service := NewRateLimiterService(memory, createTestConfig(), mockLogger, WithEventPublisher(bus))
+
+	// Act
+	for i := 0; i < 11; i++ {
+		_, err := service.CheckLimit(ctx, "192.168.1.1", "")
+		require.NoError(t, err)
+	}
+	require.NoError(t, service.Reset(ctx, "192.168.1.1", domain.IPLimiter))
+	require.NoError(t, service.Reset(ctx, "192.168.1.1", domain.IPLimiter))
+
+	// Assert: o segundo reset não encontra bloqueio e não gera evento
+	require.Len(t, published, 2)
+	assert.Equal(t, EventKeyBlocked, published[0].Type)
+	assert.Equal(t, "192.168.1.1", published[0].Data["key"])
+	assert.Equal(t, 11, published[0].Data["request_count"])
+	assert.Equal(t, 180, published[0].Data["block_duration_seconds"])
+	assert.Equal(t, EventKeyUnblocked, published[1].Type)
+	assert.Equal(t, "reset", published[1].Data["reason"])
+}
+
+// TestRateLimiterService_SoftLimitAndOverrideEvents testa o aviso único de soft limit e o evento de override
+func TestRateLimiterService_SoftLimitAndOverrideEvents(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	memory := storage.NewMemoryStorage(nil)
+	defer memory.Close()
+	mockLogger := new(MockLogger)
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Maybe()
+	mockLogger.On("Info", mock.Anything, mock.Anything).Maybe()
+
+	bus := events.NewBus()
+	var published []events.Event
+	bus.Subscribe(func(event events.Event) { published = append(published, event) })
+	service := NewRateLimiterService(memory, createTestConfig(), mockLogger, WithEventPublisher(bus), WithSoftLimitWarning(75))
+
+	// Act: limite 10, aviso na 8ª requisição (75% arredondado para cima)
+	for i := 0; i < 10; i++ {
+		_, err := service.CheckLimit(ctx, "192.168.1.1", "")
+		require.NoError(t, err)
+	}
+	require.NoError(t, service.SetOverride(ctx, domain.LimitOverride{
+		Key:       "premium_token_abc",
+		Type:      domain.TokenLimiter,
+		Limit:     500,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}))
+
+	// Assert
+	require.Len(t, published, 2)
+	assert.Equal(t, EventKeySoftLimit, published[0].Type)
+	assert.Equal(t, 8, published[0].Data["request_count"])
+	assert.Equal(t, 75, published[0].Data["percent"])
+	assert.Equal(t, EventKeyOverride, published[1].Type)
+	assert.Equal(t, "premium_***", published[1].Data["key"])
+	assert.Equal(t, 500, published[1].Data["limit"])
+}
+
+// invalidatingTokenProvider simula o cache de tokens e registra as invalidações
+type invalidatingTokenProvider struct {
+	staticTokenProvider
+	invalidated []string
+}
+
+func (p *invalidatingTokenProvider) Invalidate(token string) {
+	p.invalidated = append(p.invalidated, token)
+}
+
+// TestRateLimiterService_Reset_InvalidatesOtherInstances testa a propagação do reset entre instâncias
+func TestRateLimiterService_Reset_InvalidatesOtherInstances(t *testing.T) {
+	// Arrange: duas instâncias com Redis compartilhado e um storage em memória cada
+	ctx := context.Background()
+	bus := cluster.NewLocalInvalidationBus("instance-a")
+	mockLogger := new(MockLogger)
+	mockLogger.On("Info", mock.Anything, mock.Anything).Maybe()
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Maybe()
+
+	shared := new(MockStorage)
+	localA := localMockStorage{new(MockStorage)}
+	localB := localMockStorage{new(MockStorage)}
+	tokensB := &invalidatingTokenProvider{staticTokenProvider: staticTokenProvider{}}
+
+	instanceA := NewRateLimiterService(shared, createTestConfig(), mockLogger,
+		WithStorages(map[string]domain.RateLimiterStorage{"memory": localA}), WithInvalidation(bus))
+	NewRateLimiterService(shared, createTestConfig(), mockLogger,
+		WithStorages(map[string]domain.RateLimiterStorage{"memory": localB}), WithInvalidation(bus), WithTokenConfigProvider(tokensB))
+
+	shared.On("Reset", ctx, "rate_limit:token:premium_token").Return(nil).Once()
+	localA.On("Reset", mock.Anything, "rate_limit:token:premium_token").Return(nil).Once()
+	localB.On("Reset", mock.Anything, "rate_limit:token:premium_token").Return(nil).Once()
+
+	// Act
+	err := instanceA.Reset(ctx, "premium_token", domain.TokenLimiter)
+
+	// Assert: storage compartilhado resetado uma vez; estado local descartado em todas as instâncias
+	require.NoError(t, err)
+	shared.AssertExpectations(t)
+	localA.AssertExpectations(t)
+	localB.AssertExpectations(t)
+	assert.Equal(t, []string{"premium_token"}, tokensB.invalidated)
+}
+
+// TestRateLimiterService_InspectKey testa a inspeção do registro bruto no storage da regra
+func TestRateLimiterService_InspectKey(t *testing.T) {
+	ctx := context.Background()
+	mockLogger := new(MockLogger)
+	memory := storage.NewMemoryStorage(nil)
+	require.NoError(t, memory.Block(ctx, "rate_limit:ip:192.168.1.1", time.Minute))
+
+	service := NewRateLimiterService(memory, createTestConfig(), mockLogger)
+	record, err := service.InspectKey(ctx, "192.168.1.1", domain.IPLimiter)
+	require.NoError(t, err)
+	assert.True(t, record.Exists)
+	assert.Equal(t, "rate_limit:ip:192.168.1.1", record.StorageKey)
+	assert.NotNil(t, record.BlockedUntil)
+
+	// Storage sem suporte a inspeção
+	unsupported := NewRateLimiterService(new(MockStorage), createTestConfig(), mockLogger)
+	_, err = unsupported.InspectKey(ctx, "192.168.1.1", domain.IPLimiter)
+	assert.ErrorIs(t, err, domain.ErrInspectionUnsupported)
+}
+
+// TestRateLimiterService_ListKeys testa a listagem das chaves do storage padrão
+func TestRateLimiterService_ListKeys(t *testing.T) {
+	ctx := context.Background()
+	mockLogger := new(MockLogger)
+	memory := storage.NewMemoryStorage(nil)
+	service := NewRateLimiterService(memory, createTestConfig(), mockLogger, WithHashTaggedKeys())
+
+	for i := 0; i < 3; i++ {
+		_, _, err := memory.Increment(ctx, "rate_limit:ip:{192.168.1.1}", 10, time.Minute)
+		require.NoError(t, err)
+	}
+	require.NoError(t, memory.Block(ctx, "rate_limit:ip:{192.168.1.2}", time.Minute))
+
+	keys, next, err := service.ListKeys(ctx, domain.IPLimiter, "", 10)
+	require.NoError(t, err)
+	assert.Empty(t, next)
+	require.Len(t, keys, 2)
+	assert.Equal(t, domain.TrackedKey{Key: "192.168.1.1", Type: domain.IPLimiter, Count: 3, Limit: 10, Remaining: 7}, keys[0])
+	assert.Equal(t, "192.168.1.2", keys[1].Key)
+	assert.True(t, keys[1].IsBlocked)
+
+	// Storage sem suporte a listagem
+	unsupported := NewRateLimiterService(new(MockStorage), createTestConfig(), mockLogger)
+	_, _, err = unsupported.ListKeys(ctx, domain.IPLimiter, "", 10)
+	assert.ErrorIs(t, err, domain.ErrListingUnsupported)
+}
+
+// TestRateLimiterService_ResetPattern testa o reset em massa no storage padrão e nos nomeados
+func TestRateLimiterService_ResetPattern(t *testing.T) {
+	ctx := context.Background()
+	mockLogger := new(MockLogger)
+	mockLogger.On("Info", "Rate limit pattern reset", mock.Anything).Return()
+	memory := storage.NewMemoryStorage(nil)
+	named := storage.NewMemoryStorage(nil)
+	service := NewRateLimiterService(memory, createTestConfig(), mockLogger,
+		WithHashTaggedKeys(), WithStorages(map[string]domain.RateLimiterStorage{"memory": named}))
+
+	for _, ip := range []string{"192.168.1.1", "192.168.1.2", "10.0.0.1"} {
+		_, _, err := memory.Increment(ctx, "rate_limit:ip:{"+ip+"}", 10, time.Minute)
+		require.NoError(t, err)
+	}
+	require.NoError(t, named.Block(ctx, "rate_limit:ip:{192.168.1.3}", time.Minute))
+
+	removed, err := service.ResetPattern(ctx, "192.168.1.*", domain.IPLimiter)
+	require.NoError(t, err)
+	assert.Equal(t, 3, removed)
+	status, err := memory.Get(ctx, "rate_limit:ip:{10.0.0.1}")
+	require.NoError(t, err)
+	assert.NotNil(t, status)
+
+	_, err = service.ResetPattern(ctx, "10.0.0.[1]", domain.IPLimiter)
+	assert.ErrorIs(t, err, domain.ErrInvalidPattern)
+
+	// Storage sem suporte ao reset por padrão
+	unsupported := NewRateLimiterService(new(MockStorage), createTestConfig(), mockLogger)
+	_, err = unsupported.ResetPattern(ctx, "*", domain.IPLimiter)
+	assert.ErrorIs(t, err, domain.ErrPatternResetUnsupported)
+}
+
+// TestRateLimiterService_BlockKey testa o bloqueio manual com a duração da regra e explícita
+func TestRateLimiterService_BlockKey(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockLogger := new(MockLogger)
+	mockLogger.On("Info", "Rate limit key blocked manually", mock.Anything).Return()
+	memory := storage.NewMemoryStorage(nil)
+	service := NewRateLimiterService(memory, createTestConfig(), mockLogger)
+
+	// Act
+	ruleUntil, ruleErr := service.BlockKey(ctx, "192.168.1.1", domain.IPLimiter, 0)
+	explicitUntil, explicitErr := service.BlockKey(ctx, "premium_token", domain.TokenLimiter, 10*time.Second)
+
+	// Assert: sem duração vale o BlockDuration da regra (180s)
+	require.NoError(t, ruleErr)
+	require.NoError(t, explicitErr)
+	assert.WithinDuration(t, time.Now().Add(180*time.Second), ruleUntil, 5*time.Second)
+	assert.WithinDuration(t, time.Now().Add(10*time.Second), explicitUntil, 5*time.Second)
+	for _, key := range []string{"rate_limit:ip:192.168.1.1", "rate_limit:token:premium_token"} {
+		blocked, _, err := memory.IsBlocked(ctx, key)
+		require.NoError(t, err)
+		assert.True(t, blocked, key)
+	}
+}
+
+// TestRateLimiterService_ExportImportState testa a migração de contadores e bloqueios
+// entre instâncias com opções de chave diferentes
+func TestRateLimiterService_ExportImportState(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockLogger := new(MockLogger)
+	mockLogger.On("Info", "Rate limit state imported", mock.Anything).Return()
+	source := storage.NewMemoryStorage(nil)
+	for i := 0; i < 4; i++ {
+		_, _, err := source.Increment(ctx, "rate_limit:ip:192.168.1.1", 10, time.Minute)
+		require.NoError(t, err)
+	}
+	require.NoError(t, source.Block(ctx, "rate_limit:token:abuser_token", time.Hour))
+	exporter := NewRateLimiterService(source, createTestConfig(), mockLogger)
+
+	target := storage.NewMemoryStorage(nil)
+	importer := NewRateLimiterService(target, createTestConfig(), mockLogger, WithHashTaggedKeys())
+
+	// Act
+	entries, err := exporter.ExportState(ctx)
+	require.NoError(t, err)
+	expired := domain.StateEntry{Key: "10.0.0.1", Type: domain.IPLimiter, Count: 3, Window: 60, LastReset: time.Now().Add(-time.Hour)}
+	imported, err := importer.ImportState(ctx, append(entries, expired))
+
+	// Assert: a entrada vencida é ignorada, e as chaves seguem as opções do destino
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, 2, imported)
+	status, err := target.Get(ctx, "rate_limit:ip:{192.168.1.1}")
+	require.NoError(t, err)
+	require.NotNil(t, status)
+	assert.Equal(t, 4, status.Count)
+	blocked, _, err := target.IsBlocked(ctx, "rate_limit:token:{abuser_token}")
+	require.NoError(t, err)
+	assert.True(t, blocked)
+
+	_, err = importer.ImportState(ctx, []domain.StateEntry{{Key: "x", Type: "user"}})
+	assert.ErrorIs(t, err, domain.ErrInvalidStateEntry)
+}
+
+// TestRateLimiterService_WindowHistory testa o histórico de janelas no storage da regra
+func TestRateLimiterService_WindowHistory(t *testing.T) {
+	ctx := context.Background()
+	mockLogger := new(MockLogger)
+	memory := storage.NewMemoryStorage(nil)
+	memory.SetWindowHistory(5)
+	_, _, err := memory.Increment(ctx, "rate_limit:ip:192.168.1.1", 10, time.Minute)
+	require.NoError(t, err)
+
+	service := NewRateLimiterService(memory, createTestConfig(), mockLogger)
+	history, err := service.WindowHistory(ctx, "192.168.1.1", domain.IPLimiter)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, 1, history[0].Count)
+	assert.Equal(t, 10, history[0].Limit)
+
+	// Storage sem histórico
+	unsupported := NewRateLimiterService(new(MockStorage), createTestConfig(), mockLogger)
+	_, err = unsupported.WindowHistory(ctx, "192.168.1.1", domain.IPLimiter)
+	assert.ErrorIs(t, err, domain.ErrHistoryUnsupported)
+}
+
+// TestRateLimiterService_BlockMessage testa mensagem e documentação por regra no bloqueio
+func TestRateLimiterService_BlockMessage(t *testing.T) {
+	ctx := context.Background()
+	mockLogger := new(MockLogger)
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Maybe()
+	mockLogger.On("Info", mock.Anything, mock.Anything).Maybe()
+
+	config := createTestConfig()
+	config.IPBlockMessage = "Too many requests from your network"
+	config.IPDocsURL = "https://example.com/docs/limits"
+	config.TokenDocsURL = "https://example.com/docs/api-plans"
+	config.TokenConfigs["premium_token"] = domain.TokenConfig{
+		Token:        "premium_token",
+		Limit:        1,
+		BlockMessage: "Upgrade to the enterprise plan",
+	}
+
+	service := NewRateLimiterService(storage.NewMemoryStorage(nil), config, mockLogger)
+
+	// Padrão do tipo
+	rule, err := service.GetConfig(ctx, "192.168.1.1", domain.IPLimiter)
+	require.NoError(t, err)
+	assert.Equal(t, "Too many requests from your network", rule.BlockMessage)
+	assert.Equal(t, "https://example.com/docs/limits", rule.DocsURL)
+
+	// Token sobrescreve a mensagem e herda a documentação do tipo
+	for i := 0; i < 2; i++ {
+		_, err = service.CheckLimit(ctx, "192.168.1.1", "premium_token")
+		require.NoError(t, err)
+	}
+	result, err := service.CheckLimit(ctx, "192.168.1.1", "premium_token")
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, "Upgrade to the enterprise plan", result.Message)
+	assert.Equal(t, "https://example.com/docs/api-plans", result.DocsURL)
+}
+
+// TestRateLimiterService_TokenHeaders testa a propagação dos headers extras do token
+func TestRateLimiterService_TokenHeaders(t *testing.T) {
+	ctx := context.Background()
+	mockLogger := new(MockLogger)
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Maybe()
+
+	config := createTestConfig()
+	config.TokenConfigs["premium_token"] = domain.TokenConfig{
+		Token:   "premium_token",
+		Limit:   1000,
+		Headers: map[string]string{"X-Plan": "enterprise"},
+	}
+
+	service := NewRateLimiterService(storage.NewMemoryStorage(nil), config, mockLogger)
+
+	result, err := service.CheckLimit(ctx, "192.168.1.1", "premium_token")
+	require.NoError(t, err)
+	assert.Equal(t, "enterprise", result.Headers["X-Plan"])
+
+	// Limites por IP não herdam headers de tokens
+	result, err = service.CheckLimit(ctx, "192.168.1.1", "")
+	require.NoError(t, err)
+	assert.Empty(t, result.Headers)
+}
+
+// fakeDecisionTracer grava todas as decisões da chave em depuração
+type fakeDecisionTracer struct {
+	key    string
+	traces []domain.DecisionTrace
+}
+
+func (f *fakeDecisionTracer) Tracing(key string, limiterType domain.LimiterType) bool {
+	return key == f.key
+}
+
+func (f *fakeDecisionTracer) RecordTrace(trace domain.DecisionTrace) {
+	f.traces = append(f.traces, trace)
+}
+
+func TestRateLimiterService_CheckLimit_DecisionTrace(t *testing.T) {
+	// Arrange: limite de 1 requisição, apenas 192.168.1.1 em depuração
+	mockStorage := new(MockStorage)
+	mockLogger := new(MockLogger)
+	config := createTestConfig()
+	config.DefaultIPLimit = 1
+	tracer := &fakeDecisionTracer{key: "192.168.1.1"}
+
+	service := NewRateLimiterService(mockStorage, config, mockLogger, WithDecisionTracer(tracer))
+	ctx := context.Background()
+	expectedKey := "rate_limit:ip:192.168.1.1"
+	resetTime := time.Now().Add(time.Minute)
+
+	mockStorage.On("IsBlocked", mock.Anything, mock.Anything).Return(false, nil, nil)
+	mockStorage.On("Increment", mock.Anything, mock.Anything, 1, time.Minute).Return(2, resetTime, nil)
+	mockStorage.On("Block", mock.Anything, mock.Anything, 3*time.Minute).Return(nil)
+	mockLogger.On("Debug", mock.AnythingOfType("string"), mock.Anything).Maybe()
+	mockLogger.On("Info", mock.AnythingOfType("string"), mock.Anything).Maybe()
+
+	// Act
+	_, err := service.CheckLimit(ctx, "192.168.1.1", "")
+	require.NoError(t, err)
+	_, err = service.CheckLimit(ctx, "192.168.1.2", "")
+	require.NoError(t, err)
+
+	// Assert: só a chave em depuração é gravada, com cada etapa da decisão
+	require.Len(t, tracer.traces, 1)
+	trace := tracer.traces[0]
+	assert.Equal(t, "192.168.1.1", trace.Key)
+	assert.Equal(t, domain.IPLimiter, trace.Type)
+	assert.Equal(t, expectedKey, trace.StorageKey)
+	require.NotNil(t, trace.Rule)
+	assert.Equal(t, 1, trace.Rule.Limit)
+	assert.False(t, trace.Allowed)
+	assert.NotNil(t, trace.BlockedUntil)
+
+	names := make([]string, 0, len(trace.Steps))
+	for _, step := range trace.Steps {
+		names = append(names, step.Name)
+	}
+	assert.Equal(t, []string{"resolve_rule", "is_blocked", "increment", "block"}, names)
+	assert.Equal(t, 2, trace.Steps[2].Result["count"])
+	assert.Equal(t, false, trace.Steps[1].Result["blocked"])
+}
+
+// fakeNotifier registra as notificações enviadas aos webhooks dos clientes
+type fakeNotifier struct {
+	urls []string
+	data []map[string]interface{}
+}
+
+func (f *fakeNotifier) Notify(ctx context.Context, url, eventType string, data map[string]interface{}) {
+	f.urls = append(f.urls, url)
+	f.data = append(f.data, data)
+}
+
+func TestRateLimiterService_CheckLimit_UsageWebhook(t *testing.T) {
+	// Arrange: limite 10, webhook a 50% do uso
+	mockStorage := new(MockStorage)
+	mockLogger := new(MockLogger)
+	config := createTestConfig()
+	config.TokenConfigs["hooked_token"] = domain.TokenConfig{
+		Token:            "hooked_token",
+		Limit:            10,
+		WebhookURL:       "https://hooks.example.com/usage",
+		WebhookThreshold: 50,
+	}
+	notifier := &fakeNotifier{}
+
+	service := NewRateLimiterService(mockStorage, config, mockLogger, WithUsageNotifier(notifier))
+	ctx := context.Background()
+	expectedKey := "rate_limit:token:hooked_token"
+	resetTime := time.Now().Add(time.Minute)
+
+	mockStorage.On("IsBlocked", ctx, expectedKey).Return(false, nil, nil)
+	for count := 4; count <= 6; count++ {
+		mockStorage.On("Increment", ctx, expectedKey, 10, time.Minute).Return(count, resetTime, nil).Once()
+	}
+	mockLogger.On("Debug", mock.AnythingOfType("string"), mock.Anything).Maybe()
+
+	// Act
+	for i := 0; i < 3; i++ {
+		_, err := service.CheckLimit(ctx, "192.168.1.1", "hooked_token")
+		require.NoError(t, err)
+	}
+
+	// Assert: apenas a requisição que cruza o limiar notifica
+	require.Len(t, notifier.urls, 1)
+	assert.Equal(t, "https://hooks.example.com/usage", notifier.urls[0])
+	assert.Equal(t, 5, notifier.data[0]["request_count"])
+	assert.Equal(t, 50, notifier.data[0]["percent"])
+	assert.Equal(t, "hooked_t***", notifier.data[0]["token"])
+}
+
+func TestRateLimiterService_GetConfig_DefaultWebhookThreshold(t *testing.T) {
+	// Arrange
+	config := createTestConfig()
+	config.TokenConfigs["hooked_token"] = domain.TokenConfig{Token: "hooked_token", Limit: 10, WebhookURL: "https://hooks.example.com/usage"}
+	service := NewRateLimiterService(new(MockStorage), config, new(MockLogger))
+
+	// Act
+	rule, err := service.GetConfig(context.Background(), "hooked_token", domain.TokenLimiter)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "https://hooks.example.com/usage", rule.WebhookURL)
+	assert.Equal(t, 90, rule.WebhookThreshold)
+}
+
+// TestRateLimiterService_CheckLimit_TokenFamily testa o contador único de uma família de tokens
+func TestRateLimiterService_CheckLimit_TokenFamily(t *testing.T) {
+	// Arrange: duas chaves de integração do mesmo contrato, limite 3 no total
+	ctx := context.Background()
+	mockLogger := new(MockLogger)
+	config := createTestConfig()
+	config.TokenConfigs["acme-web"] = domain.TokenConfig{Token: "acme-web", Limit: 3, Family: "acme"}
+	config.TokenConfigs["acme-mobile"] = domain.TokenConfig{Token: "acme-mobile", Limit: 3, Family: "acme"}
+	blocks := &recordingBlocks{}
+	memory := storage.NewMemoryStorage(nil)
+	service := NewRateLimiterService(memory, config, mockLogger, WithBlockRecorder(blocks))
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Maybe()
+	mockLogger.On("Info", mock.Anything, mock.Anything).Maybe()
+
+	// Act
+	var allowed []bool
+	for _, token := range []string{"acme-web", "acme-mobile", "acme-web", "acme-mobile"} {
+		result, err := service.CheckLimit(ctx, "192.168.1.1", token)
+		require.NoError(t, err)
+		allowed = append(allowed, result.Allowed)
+	}
+	webStatus, err := service.GetStatus(ctx, "acme-web", domain.TokenLimiter)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, []bool{true, true, true, false}, allowed)
+	require.NotNil(t, webStatus)
+	assert.True(t, webStatus.IsBlocked, "the block applies to the whole family")
+	require.Len(t, blocks.blocks, 1)
+	assert.Equal(t, "acme-mobile", blocks.blocks[0].Key, "reports keep the individual token")
+	mockLogger.AssertCalled(t, "Info", "Rate limit exceeded, key blocked", mock.MatchedBy(func(fields map[string]interface{}) bool {
+		return fields["family"] == "acme" && fields["storage_key"] == "rate_limit:family:acme"
+	}))
+}
+
+// TestRateLimiterService_CheckLimit_Hierarchy testa o orçamento do projeto dividido entre tokens
+func TestRateLimiterService_CheckLimit_Hierarchy(t *testing.T) {
+	// Arrange: dois tokens de limite 3 em um projeto de limite 4, organização de limite 100
+	ctx := context.Background()
+	mockLogger := new(MockLogger)
+	config := createTestConfig()
+	config.TokenConfigs["checkout-web"] = domain.TokenConfig{Token: "checkout-web", Limit: 3, Project: "checkout"}
+	config.TokenConfigs["checkout-app"] = domain.TokenConfig{Token: "checkout-app", Limit: 3, Project: "checkout"}
+	config.Projects = map[string]domain.ProjectConfig{"checkout": {Organization: "acme", Limit: 4}}
+	config.Organizations = map[string]domain.OrganizationConfig{"acme": {Limit: 100}}
+	blocks := &recordingBlocks{}
+	service := NewRateLimiterService(storage.NewMemoryStorage(nil), config, mockLogger, WithBlockRecorder(blocks))
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Maybe()
+	mockLogger.On("Info", mock.Anything, mock.Anything).Maybe()
+
+	// Act
+	var results []*domain.RateLimitResult
+	for _, token := range []string{"checkout-web", "checkout-web", "checkout-web", "checkout-app", "checkout-app"} {
+		result, err := service.CheckLimit(ctx, "192.168.1.1", token)
+		require.NoError(t, err)
+		results = append(results, result)
+	}
+	webAgain, err := service.CheckLimit(ctx, "192.168.1.1", "checkout-web")
+	require.NoError(t, err)
+
+	// Assert
+	assert.True(t, results[0].Allowed)
+	assert.Equal(t, "token", results[0].Scope, "the token has the least room left")
+	assert.Equal(t, 2, results[0].Remaining)
+	assert.True(t, results[3].Allowed)
+	assert.Equal(t, "project:checkout", results[3].Scope)
+	assert.Equal(t, 0, results[3].Remaining)
+
+	denied := results[4]
+	assert.False(t, denied.Allowed)
+	assert.Equal(t, "project:checkout", denied.Scope)
+	assert.Equal(t, 4, denied.Limit)
+	require.NotNil(t, denied.BlockedUntil)
+	assert.Equal(t, []string{"token:checkout-app"}, blocks.rejected, "an exhausted project rejects without blocking the token")
+
+	assert.False(t, webAgain.Allowed)
+	assert.Equal(t, "token", webAgain.Scope, "the token's own limit is checked first")
+	require.Len(t, blocks.blocks, 1)
+	assert.Equal(t, "checkout-web", blocks.blocks[0].Key)
+}
+
+// TestRateLimiterService_ClientLimits testa os limites listados em /me/limits:
+// família, níveis da hierarquia e a regra padrão de um token desconhecido
+func TestRateLimiterService_ClientLimits(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockLogger := new(MockLogger)
+	config := createTestConfig()
+	config.TokenConfigs["acme-web"] = domain.TokenConfig{Token: "acme-web", Limit: 3, Family: "acme"}
+	config.TokenConfigs["checkout-web"] = domain.TokenConfig{Token: "checkout-web", Limit: 3, Project: "checkout"}
+	config.Projects = map[string]domain.ProjectConfig{"checkout": {Organization: "acme", Limit: 4}}
+	config.Organizations = map[string]domain.OrganizationConfig{"acme": {Limit: 100}}
+	service := NewRateLimiterService(storage.NewMemoryStorage(nil), config, mockLogger)
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Maybe()
+	mockLogger.On("Info", mock.Anything, mock.Anything).Maybe()
+	for _, token := range []string{"acme-web", "checkout-web", "checkout-web"} {
+		_, err := service.CheckLimit(ctx, "192.168.1.1", token)
+		require.NoError(t, err)
+	}
+
+	// Act
+	family, err := service.ClientLimits(ctx, "acme-web")
+	require.NoError(t, err)
+	hierarchy, err := service.ClientLimits(ctx, "checkout-web")
+	require.NoError(t, err)
+	unknown, err := service.ClientLimits(ctx, "unknown-secret-token")
+	require.NoError(t, err)
+
+	// Assert
+	require.Len(t, family, 1)
+	assert.Equal(t, "family", family[0].Scope)
+	require.NotNil(t, family[0].Status)
+	assert.Equal(t, 1, family[0].Status.Count)
+
+	require.Len(t, hierarchy, 3)
+	assert.Equal(t, []string{"token", "project:checkout", "organization:acme"},
+		[]string{hierarchy[0].Scope, hierarchy[1].Scope, hierarchy[2].Scope})
+	assert.Equal(t, 4, hierarchy[1].Rule.Limit)
+	assert.Equal(t, 100, hierarchy[2].Rule.Limit)
+	for _, limit := range hierarchy {
+		require.NotNil(t, limit.Status, limit.Scope)
+		assert.Equal(t, 2, limit.Status.Count, limit.Scope)
+	}
+
+	require.Len(t, unknown, 1)
+	assert.Equal(t, "token", unknown[0].Scope)
+	assert.Equal(t, "Default token limit", unknown[0].Rule.Description)
+	assert.Equal(t, config.DefaultTokenLimit, unknown[0].Rule.Limit)
+	assert.Nil(t, unknown[0].Status)
+}
+
+// atomicMockStorage simula um storage que verifica o limite em uma única operação
+type atomicMockStorage struct {
+	*MockStorage
+}
+
+func (m atomicMockStorage) CheckAndIncrement(ctx context.Context, key string, limit int, window, blockDuration time.Duration) (*domain.LimitCheck, error) {
+	args := m.Called(ctx, key, limit, window, blockDuration)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.LimitCheck), args.Error(1)
+}
+
+// TestRateLimiterService_CheckLimit_SingleRoundTrip testa que a janela fixa usa uma
+// única chamada ao storage, sem IsBlocked, Increment e Block separados
+func TestRateLimiterService_CheckLimit_SingleRoundTrip(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockStorage := atomicMockStorage{new(MockStorage)}
+	mockLogger := new(MockLogger)
+	blocks := &recordingBlocks{}
+	service := NewRateLimiterService(mockStorage, createTestConfig(), mockLogger, WithBlockRecorder(blocks))
+	windowStart := time.Now()
+	blockedUntil := time.Now().Add(3 * time.Minute)
+
+	mockStorage.On("CheckAndIncrement", mock.Anything, "rate_limit:ip:192.168.1.1", 10, time.Minute, 3*time.Minute).
+		Return(&domain.LimitCheck{Count: 4, WindowStart: windowStart}, nil)
+	mockStorage.On("CheckAndIncrement", mock.Anything, "rate_limit:ip:192.168.1.2", 10, time.Minute, 3*time.Minute).
+		Return(&domain.LimitCheck{Count: 11, WindowStart: windowStart, BlockedUntil: &blockedUntil}, nil)
+	mockStorage.On("CheckAndIncrement", mock.Anything, "rate_limit:ip:192.168.1.3", 10, time.Minute, 3*time.Minute).
+		Return(&domain.LimitCheck{Blocked: true, BlockedUntil: &blockedUntil}, nil)
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Maybe()
+	mockLogger.On("Info", mock.Anything, mock.Anything).Maybe()
+
+	// Act
+	allowed, err := service.CheckLimit(ctx, "192.168.1.1", "")
+	require.NoError(t, err)
+	exceeded, err := service.CheckLimit(ctx, "192.168.1.2", "")
+	require.NoError(t, err)
+	blocked, err := service.CheckLimit(ctx, "192.168.1.3", "")
+	require.NoError(t, err)
+
+	// Assert
+	assert.True(t, allowed.Allowed)
+	assert.Equal(t, 6, allowed.Remaining)
+	assert.Equal(t, windowStart, allowed.ResetTime)
+
+	assert.False(t, exceeded.Allowed)
+	require.NotNil(t, exceeded.BlockedUntil)
+	require.Len(t, blocks.blocks, 1)
+	assert.Equal(t, "192.168.1.2", blocks.blocks[0].Key)
+	assert.Equal(t, 11, blocks.blocks[0].RequestCount)
+
+	assert.False(t, blocked.Allowed)
+	assert.Equal(t, &blockedUntil, blocked.BlockedUntil)
+	assert.Equal(t, []string{"ip:192.168.1.3"}, blocks.rejected)
+
+	mockStorage.AssertNotCalled(t, "IsBlocked", mock.Anything, mock.Anything)
+	mockStorage.AssertNotCalled(t, "Increment", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockStorage.AssertNotCalled(t, "Block", mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestRateLimiterService_CheckLimit_SingleRoundTripUnsupported testa o fluxo em
+// três etapas quando o storage não verifica o algoritmo configurado atomicamente
+func TestRateLimiterService_CheckLimit_SingleRoundTripUnsupported(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	mockStorage := atomicMockStorage{new(MockStorage)}
+	mockLogger := new(MockLogger)
+	service := NewRateLimiterService(mockStorage, createTestConfig(), mockLogger)
+
+	mockStorage.On("CheckAndIncrement", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, domain.ErrCheckUnsupported)
+	mockStorage.On("IsBlocked", mock.Anything, "rate_limit:ip:192.168.1.1").Return(false, nil, nil)
+	mockStorage.On("Increment", mock.Anything, "rate_limit:ip:192.168.1.1", 10, time.Minute).Return(1, time.Now(), nil)
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Maybe()
+
+	// Act
+	result, err := service.CheckLimit(ctx, "192.168.1.1", "")
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 9, result.Remaining)
+	mockStorage.AssertExpectations(t)
+}
+
+// TestRateLimiterService_DecisionSpan testa o span da decisão, pai dos spans de storage
+func TestRateLimiterService_DecisionSpan(t *testing.T) {
+	// Arrange
+	// Ao fim, noop: o provider global original passaria a delegar ao do teste
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(tracenoop.NewTracerProvider())
+
+	memory := storage.NewMemoryStorage(nil)
+	defer memory.Close()
+	mockLogger := new(MockLogger)
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Maybe()
+	service := NewRateLimiterService(memory, createTestConfig(), mockLogger)
+
+	// Act
+	_, err := service.CheckLimit(context.Background(), "192.168.1.1", "")
+	require.NoError(t, err)
+
+	// Assert
+	spans := recorder.Ended()
+	require.NotEmpty(t, spans)
+	decision := spans[len(spans)-1]
+	assert.Equal(t, "rate_limiter.decision", decision.Name())
+	assert.Subset(t, decision.Attributes(), []attribute.KeyValue{
+		attribute.String("rate_limit.key_type", "ip"),
+		attribute.Bool("rate_limit.allowed", true),
+		attribute.Int("rate_limit.limit", 10),
+		attribute.Int("rate_limit.remaining", 9),
+	})
+	for _, span := range spans[:len(spans)-1] {
+		assert.Equal(t, decision.SpanContext().SpanID(), span.Parent().SpanID(), span.Name())
+		assert.Contains(t, span.Attributes(), attribute.String("rate_limit.key_type", "ip"))
+	}
+}
+
+// TestRateLimiterService_DecisionMetrics testa as métricas de decisões, cota restante e bloqueios
+func TestRateLimiterService_DecisionMetrics(t *testing.T) {
+	// Arrange
+	// Ao fim, noop: o provider global original passaria a delegar ao do teste
+	reader := sdkmetric.NewManualReader()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	defer otel.SetMeterProvider(metricnoop.NewMeterProvider())
+
+	memory := storage.NewMemoryStorage(nil)
+	defer memory.Close()
+	mockLogger := new(MockLogger)
+	mockLogger.On("Debug", mock.Anything, mock.Anything).Maybe()
+	mockLogger.On("Info", mock.Anything, mock.Anything).Maybe()
+	service := NewRateLimiterService(memory, createTestConfig(), mockLogger)
+
+	// Act: o limite de IP é 10; a 11ª requisição bloqueia a chave
+	for i := 0; i < 11; i++ {
+		_, err := service.CheckLimit(context.Background(), "192.168.1.1", "")
+		require.NoError(t, err)
+	}
+
+	var collected metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &collected))
+
+	// Assert
+	metrics := make(map[string]metricdata.Aggregation)
+	for _, scope := range collected.ScopeMetrics {
+		if scope.Scope.Name == MeterName {
+			for _, m := range scope.Metrics {
+				metrics[m.Name] = m.Data
+			}
+		}
+	}
+
+	decisions := make(map[bool]int64)
+	for _, point := range metrics["rate_limiter.decisions"].(metricdata.Sum[int64]).DataPoints {
+		keyType, _ := point.Attributes.Value("rate_limit.key_type")
+		allowed, _ := point.Attributes.Value("rate_limit.allowed")
+		assert.Equal(t, "ip", keyType.AsString())
+		decisions[allowed.AsBool()] = point.Value
+	}
+	assert.Equal(t, map[bool]int64{true: 10, false: 1}, decisions)
+
+	remaining := metrics["rate_limiter.remaining"].(metricdata.Histogram[int64]).DataPoints
+	require.Len(t, remaining, 1)
+	assert.Equal(t, uint64(11), remaining[0].Count)
+	minimum, _ := remaining[0].Min.Value()
+	maximum, _ := remaining[0].Max.Value()
+	assert.Equal(t, int64(0), minimum)
+	assert.Equal(t, int64(9), maximum)
+
+	blocks := metrics["rate_limiter.blocks"].(metricdata.Sum[int64]).DataPoints
+	require.Len(t, blocks, 1)
+	assert.Equal(t, int64(1), blocks[0].Value)
+}
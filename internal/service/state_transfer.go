@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/storage"
+)
+
+// exportPageSize é o tamanho das páginas lidas do storage na exportação
+const exportPageSize = 1000
+
+// ExportState percorre as chaves de IP e de token do storage padrão e retorna o
+// estado de cada uma com o identificador em claro. Como em ListKeys, chaves de
+// storages nomeados e de famílias não entram
+func (s *RateLimiterService) ExportState(ctx context.Context) ([]domain.StateEntry, error) {
+	lister, ok := s.storage.(domain.KeyLister)
+	if !ok {
+		return nil, domain.ErrListingUnsupported
+	}
+
+	entries := make([]domain.StateEntry, 0)
+	for _, limiterType := range []domain.LimiterType{domain.IPLimiter, domain.TokenLimiter} {
+		cursor := ""
+		for {
+			statuses, next, err := lister.ListKeys(ctx, storage.KeyPrefix(limiterType), cursor, exportPageSize)
+			if err != nil {
+				return nil, fmt.Errorf("failed to export keys: %w", err)
+			}
+			for _, status := range statuses {
+				identifier, err := storage.ParseKey(limiterType, status.Key, s.keyOptions...)
+				if err != nil {
+					s.logger.Debug("Skipping unreadable storage key", map[string]interface{}{
+						"storage_key": status.Key,
+						"error":       err.Error(),
+					})
+					continue
+				}
+				entry := domain.StateEntry{
+					Key:       identifier,
+					Type:      limiterType,
+					Count:     status.Count,
+					Limit:     status.Limit,
+					Window:    status.Window,
+					Credit:    status.Credit,
+					LastReset: status.LastReset,
+					ResetAt:   status.ResetAt,
+				}
+				if status.IsBlocked {
+					entry.BlockedUntil = status.BlockedUntil
+				}
+				entries = append(entries, entry)
+			}
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+	}
+	return entries, nil
+}
+
+// ImportState grava as entradas no storage padrão com as opções de chave desta
+// instância. Janelas e bloqueios já vencidos são ignorados, e os vigentes expiram
+// no mesmo instante da origem. As entradas são validadas antes de qualquer escrita
+func (s *RateLimiterService) ImportState(ctx context.Context, entries []domain.StateEntry) (int, error) {
+	for i, entry := range entries {
+		if entry.Key == "" {
+			return 0, fmt.Errorf("%w: entry %d has no key", domain.ErrInvalidStateEntry, i)
+		}
+		if entry.Type != domain.IPLimiter && entry.Type != domain.TokenLimiter {
+			return 0, fmt.Errorf("%w: entry %d has type %q", domain.ErrInvalidStateEntry, i, entry.Type)
+		}
+		if entry.Count < 0 || entry.Window < 0 {
+			return 0, fmt.Errorf("%w: entry %d has a negative count or window", domain.ErrInvalidStateEntry, i)
+		}
+	}
+
+	now := s.now()
+	imported := 0
+	for _, entry := range entries {
+		storageKey := s.buildStorageKey(entry.Key, entry.Type)
+
+		// A janela termina no reset agendado ou ao fim de Window segundos
+		windowEnd := entry.LastReset.Add(time.Duration(entry.Window) * time.Second)
+		if entry.ResetAt != nil {
+			windowEnd = *entry.ResetAt
+		}
+		blocked := entry.BlockedUntil != nil && entry.BlockedUntil.After(now)
+		if !windowEnd.After(now) && !blocked {
+			continue
+		}
+
+		if windowEnd.After(now) {
+			expiresAt := windowEnd
+			if blocked && entry.BlockedUntil.After(expiresAt) {
+				expiresAt = *entry.BlockedUntil
+			}
+			status := &domain.RateLimitStatus{
+				Key:       storageKey,
+				Type:      entry.Type,
+				Count:     entry.Count,
+				Limit:     entry.Limit,
+				Window:    entry.Window,
+				Credit:    entry.Credit,
+				LastReset: entry.LastReset,
+				ResetAt:   entry.ResetAt,
+			}
+			if err := s.storage.Set(ctx, storageKey, status, expiresAt.Sub(now)); err != nil {
+				return imported, fmt.Errorf("failed to import key: %w", err)
+			}
+		}
+		if blocked {
+			if err := s.storage.Block(ctx, storageKey, entry.BlockedUntil.Sub(now)); err != nil {
+				return imported, fmt.Errorf("failed to import block: %w", err)
+			}
+		}
+		imported++
+	}
+
+	s.logger.Info("Rate limit state imported", map[string]interface{}{
+		"entries":  len(entries),
+		"imported": imported,
+	})
+	return imported, nil
+}
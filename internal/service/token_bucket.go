@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"rate-limiter/internal/domain"
+)
+
+// takeToken decide a requisição pelo token bucket da regra: capacidade rule.Limit,
+// reposta a rule.RefillRate fichas por segundo. Sem ficha a requisição é negada
+// com BlockedUntil na próxima ficha, sem bloquear a chave por BlockDuration
+func (s *RateLimiterService) takeToken(ctx context.Context, storage domain.RateLimiterStorage, storageKey, key string, limiterType domain.LimiterType, rule *domain.RateLimitRule) (*domain.RateLimitResult, error) {
+	trace := s.decisionTraceFrom(ctx)
+	stepStart := trace.begin()
+	status, err := storage.TakeToken(ctx, storageKey, domain.TokenBucket{Capacity: rule.Limit, RefillRate: rule.RefillRate})
+	if trace != nil {
+		trace.step("take_token", stepStart, storageStatusFields(status), err)
+	}
+	if err != nil {
+		s.logger.Error("Failed to take token", err, map[string]interface{}{
+			"storage_key": storageKey,
+			"capacity":    rule.Limit,
+			"refill_rate": rule.RefillRate,
+		})
+		return nil, fmt.Errorf("failed to take token: %w", err)
+	}
+
+	allowed := !status.IsBlocked
+	s.recordRolloutDecision(rule, allowed)
+
+	result := &domain.RateLimitResult{
+		Allowed:     allowed,
+		Limit:       rule.Limit,
+		Remaining:   rule.Limit - status.Count,
+		ResetTime:   *status.ResetAt,
+		LimiterType: limiterType,
+		Headers:     rule.Headers,
+	}
+
+	if !allowed {
+		result.Remaining = 0
+		result.BlockedUntil = status.BlockedUntil
+		result.Message = rule.BlockMessage
+		result.DocsURL = rule.DocsURL
+
+		s.logger.Info("Token bucket empty, request rejected", map[string]interface{}{
+			"storage_key": storageKey,
+			"capacity":    rule.Limit,
+			"refill_rate": rule.RefillRate,
+			"next_token":  status.BlockedUntil,
+		})
+		for _, recorder := range s.blocks {
+			recorder.RecordRejected(key, limiterType)
+		}
+		return result, nil
+	}
+
+	s.logger.Debug("Request allowed", map[string]interface{}{
+		"storage_key": storageKey,
+		"capacity":    rule.Limit,
+		"remaining":   result.Remaining,
+	})
+	return result, nil
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"rate-limiter/internal/domain"
+)
+
+// TracerName identifica os spans emitidos pelo service
+const TracerName = "rate-limiter/internal/service"
+
+// startDecisionSpan abre o span da decisão, pai dos spans de storage. A chave não
+// é registrada por conter tokens; apenas o tipo de limiter é anexado. Sem
+// TracerProvider o span é inválido e o contexto original segue para o storage
+func startDecisionSpan(ctx context.Context, limiterType domain.LimiterType) (context.Context, trace.Span) {
+	spanCtx, span := otel.Tracer(TracerName).Start(ctx, "rate_limiter.decision")
+	if !span.SpanContext().IsValid() {
+		return ctx, span
+	}
+	if span.IsRecording() {
+		span.SetAttributes(attribute.String("rate_limit.key_type", string(limiterType)))
+	}
+	return spanCtx, span
+}
+
+// endDecisionSpan registra o resultado da decisão e encerra o span
+func endDecisionSpan(span trace.Span, result *domain.RateLimitResult, err error) {
+	if span.IsRecording() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		if result != nil {
+			span.SetAttributes(
+				attribute.Bool("rate_limit.allowed", result.Allowed),
+				attribute.Int("rate_limit.limit", result.Limit),
+				attribute.Int("rate_limit.remaining", result.Remaining),
+			)
+			if result.Scope != "" {
+				span.SetAttributes(attribute.String("rate_limit.scope", result.Scope))
+			}
+		}
+	}
+	span.End()
+}
@@ -0,0 +1,131 @@
+package shadow
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"rate-limiter/internal/domain"
+)
+
+// DefaultMaxInFlight limita as avaliações simultâneas em shadow
+const DefaultMaxInFlight = 64
+
+// Stats resume a comparação entre a configuração ativa e a staged
+type Stats struct {
+	Evaluated  uint64    `json:"evaluated"`
+	Agreed     uint64    `json:"agreed"`
+	WouldDeny  uint64    `json:"would_deny"`  // permitidas hoje, negadas na staged
+	WouldAllow uint64    `json:"would_allow"` // negadas hoje, permitidas na staged
+	Errors     uint64    `json:"errors"`
+	Dropped    uint64    `json:"dropped"` // descartadas por excesso de avaliações em curso
+	Since      time.Time `json:"since"`
+}
+
+// Changed retorna quantas requisições mudariam de resultado
+func (s Stats) Changed() uint64 {
+	return s.WouldDeny + s.WouldAllow
+}
+
+// Evaluator avalia cada requisição também sob a configuração staged, sem aplicar
+// A avaliação roda em background com concorrência limitada: nunca atrasa a
+// resposta e, sob pico, descarta avaliações em vez de acumular goroutines
+type Evaluator struct {
+	shadow  domain.RateLimiterService
+	timeout time.Duration
+	slots   chan struct{}
+
+	evaluated  atomic.Uint64
+	agreed     atomic.Uint64
+	wouldDeny  atomic.Uint64
+	wouldAllow atomic.Uint64
+	errors     atomic.Uint64
+	dropped    atomic.Uint64
+	since      atomic.Int64
+
+	now func() time.Time
+}
+
+// NewEvaluator cria o avaliador sobre um serviço configurado com a config staged
+// O serviço em shadow deve usar contadores isolados (ex: storage.PrefixedStorage)
+func NewEvaluator(shadow domain.RateLimiterService, maxInFlight int) *Evaluator {
+	if maxInFlight <= 0 {
+		maxInFlight = DefaultMaxInFlight
+	}
+
+	evaluator := &Evaluator{
+		shadow:  shadow,
+		timeout: time.Second,
+		slots:   make(chan struct{}, maxInFlight),
+		now:     time.Now,
+	}
+	evaluator.since.Store(evaluator.now().UnixNano())
+	return evaluator
+}
+
+// Observe implementa domain.ShadowObserver
+func (e *Evaluator) Observe(ctx context.Context, ip, token string, active *domain.RateLimitResult) {
+	if active == nil {
+		return
+	}
+
+	select {
+	case e.slots <- struct{}{}:
+	default:
+		e.dropped.Add(1)
+		return
+	}
+
+	// Mantém os valores do contexto (request ID) sem herdar o cancelamento da requisição
+	shadowCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), e.timeout)
+	allowed := active.Allowed
+
+	go func() {
+		defer func() { <-e.slots }()
+		defer cancel()
+		e.evaluate(shadowCtx, ip, token, allowed)
+	}()
+}
+
+// Stats retorna os contadores acumulados desde o último Reset
+func (e *Evaluator) Stats() Stats {
+	return Stats{
+		Evaluated:  e.evaluated.Load(),
+		Agreed:     e.agreed.Load(),
+		WouldDeny:  e.wouldDeny.Load(),
+		WouldAllow: e.wouldAllow.Load(),
+		Errors:     e.errors.Load(),
+		Dropped:    e.dropped.Load(),
+		Since:      time.Unix(0, e.since.Load()).UTC(),
+	}
+}
+
+// Reset zera os contadores (ex: ao trocar a configuração staged)
+func (e *Evaluator) Reset() {
+	e.evaluated.Store(0)
+	e.agreed.Store(0)
+	e.wouldDeny.Store(0)
+	e.wouldAllow.Store(0)
+	e.errors.Store(0)
+	e.dropped.Store(0)
+	e.since.Store(e.now().UnixNano())
+}
+
+// evaluate executa a decisão em shadow e compara com a aplicada
+func (e *Evaluator) evaluate(ctx context.Context, ip, token string, activeAllowed bool) {
+	result, err := e.shadow.CheckLimit(ctx, ip, token)
+	if err != nil || result == nil {
+		e.errors.Add(1)
+		return
+	}
+
+	e.evaluated.Add(1)
+	switch {
+	case result.Allowed == activeAllowed:
+		e.agreed.Add(1)
+	case activeAllowed:
+		e.wouldDeny.Add(1)
+	default:
+		e.wouldAllow.Add(1)
+	}
+}
@@ -0,0 +1,98 @@
+package shadow
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/logger"
+	"rate-limiter/internal/service"
+	"rate-limiter/internal/storage"
+)
+
+// newShadowService cria um serviço com a configuração staged sobre contadores isolados
+func newShadowService(inner domain.RateLimiterStorage, ipLimit int) domain.RateLimiterService {
+	staged := &domain.RateLimitConfig{DefaultIPLimit: ipLimit, DefaultTokenLimit: 100, Window: 60, BlockDuration: 180}
+	return service.NewRateLimiterService(storage.NewPrefixedStorage(inner, "shadow:"), staged, logger.NewNopLogger())
+}
+
+func TestEvaluator_CountsOutcomeChanges(t *testing.T) {
+	// Arrange: ativa permite 5 por minuto, staged apenas 2
+	inner := storage.NewMemoryStorage(logger.NewNopLogger())
+	defer inner.Close()
+
+	evaluator := NewEvaluator(newShadowService(inner, 2), 1)
+	active := &domain.RateLimitConfig{DefaultIPLimit: 5, DefaultTokenLimit: 100, Window: 60, BlockDuration: 180}
+	enforced := service.NewRateLimiterService(inner, active, logger.NewNopLogger(), service.WithShadowObserver(evaluator))
+
+	// Act
+	for i := 0; i < 5; i++ {
+		result, err := enforced.CheckLimit(context.Background(), "10.0.0.1", "")
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+
+		// Uma avaliação por vez (maxInFlight=1) mantém a ordem determinística
+		require.Eventually(t, func() bool {
+			stats := evaluator.Stats()
+			return stats.Evaluated+stats.Dropped == uint64(i+1)
+		}, time.Second, time.Millisecond)
+	}
+
+	// Assert: as 3 últimas seriam negadas sob a staged
+	stats := evaluator.Stats()
+	assert.Equal(t, uint64(0), stats.Dropped)
+	assert.Equal(t, uint64(5), stats.Evaluated)
+	assert.Equal(t, uint64(2), stats.Agreed)
+	assert.Equal(t, uint64(3), stats.WouldDeny)
+	assert.Equal(t, uint64(3), stats.Changed())
+
+	// Contadores aplicados não foram afetados pela avaliação em shadow
+	status, err := inner.Get(context.Background(), "rate_limit:ip:10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, 5, status.Count)
+
+	evaluator.Reset()
+	assert.Equal(t, uint64(0), evaluator.Stats().Evaluated)
+}
+
+// failingService simula um erro na avaliação em shadow
+type failingService struct {
+	domain.RateLimiterService
+}
+
+func (failingService) CheckLimit(ctx context.Context, ip, token string) (*domain.RateLimitResult, error) {
+	return nil, errors.New("storage unavailable")
+}
+
+// blockingService segura as avaliações até o canal ser fechado
+type blockingService struct {
+	domain.RateLimiterService
+	release chan struct{}
+}
+
+func (b blockingService) CheckLimit(ctx context.Context, ip, token string) (*domain.RateLimitResult, error) {
+	<-b.release
+	return &domain.RateLimitResult{Allowed: true}, nil
+}
+
+func TestEvaluator_ErrorsAndDrops(t *testing.T) {
+	// Erro na staged não afeta a requisição, apenas é contabilizado
+	failing := NewEvaluator(failingService{}, 1)
+	failing.Observe(context.Background(), "10.0.0.1", "", &domain.RateLimitResult{Allowed: true})
+	require.Eventually(t, func() bool { return failing.Stats().Errors == 1 }, time.Second, time.Millisecond)
+
+	// Sem vaga para avaliar: descarta em vez de bloquear
+	release := make(chan struct{})
+	busy := NewEvaluator(blockingService{release: release}, 1)
+	busy.Observe(context.Background(), "10.0.0.1", "", &domain.RateLimitResult{Allowed: true})
+	busy.Observe(context.Background(), "10.0.0.2", "", &domain.RateLimitResult{Allowed: true})
+	assert.Equal(t, uint64(1), busy.Stats().Dropped)
+
+	close(release)
+	require.Eventually(t, func() bool { return busy.Stats().Agreed == 1 }, time.Second, time.Millisecond)
+}
@@ -0,0 +1,220 @@
+package staging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"rate-limiter/internal/domain"
+)
+
+// maxRuleRevisions limita as revisões mantidas por regra; as mais antigas são descartadas
+const maxRuleRevisions = 50
+
+// Ações registradas no histórico de uma regra
+const (
+	RuleActionInitial = "initial" // Versão carregada da configuração, antes da primeira edição
+	RuleActionCreate  = "create"
+	RuleActionUpdate  = "update"
+	RuleActionDelete  = "delete"
+	RuleActionRestore = "restore"
+)
+
+// initialActor identifica a versão que veio da configuração carregada
+const initialActor = "config"
+
+// ErrRevisionNotFound indica que a regra não tem a revisão informada
+var ErrRevisionNotFound = errors.New("rule revision not found")
+
+// RuleRevision é uma versão de uma regra no histórico
+type RuleRevision struct {
+	Version      int             `json:"version"`
+	Action       string          `json:"action"`
+	Actor        string          `json:"actor"`
+	At           time.Time       `json:"at"`
+	Document     json.RawMessage `json:"document,omitempty"` // Vazio em exclusões
+	ETag         string          `json:"etag,omitempty"`
+	RestoredFrom int             `json:"restored_from,omitempty"`
+}
+
+// RuleHistory retorna as revisões da regra, da mais recente para a mais antiga.
+// Regras excluídas continuam com histórico; regras nunca editadas retornam
+// apenas a versão atual
+func (m *Manager) RuleHistory(id string) ([]RuleRevision, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	revisions := m.history[id]
+	if len(revisions) == 0 {
+		document, err := ruleDocument(m.active.Config, id)
+		if err != nil {
+			return nil, err
+		}
+		return []RuleRevision{m.initialRevision(document)}, nil
+	}
+
+	result := make([]RuleRevision, len(revisions))
+	for i, revision := range revisions {
+		result[len(revisions)-1-i] = revision
+	}
+	return result, nil
+}
+
+// DeleteRule remove a regra de token ou IP da configuração ativa (soft-delete): o
+// histórico é mantido e uma revisão anterior pode ser restaurada.
+// Os limites padrão ("ip:*", "token:*") não podem ser excluídos
+func (m *Manager) DeleteRule(id, ifMatch, actor string) (RuleRevision, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	document, err := ruleDocument(m.active.Config, id)
+	if err != nil {
+		return RuleRevision{}, err
+	}
+	limiterType, key, _ := strings.Cut(id, ":")
+	if key == wildcardKey {
+		return RuleRevision{}, fmt.Errorf("%w: default rules cannot be deleted", ErrInvalidRule)
+	}
+	if !etagMatches(ifMatch, ruleETag(document)) {
+		return RuleRevision{}, ErrRuleModified
+	}
+
+	result := *m.active.Config
+	if limiterType == string(domain.IPLimiter) {
+		result.IPConfigs = make(map[string]domain.IPConfig, len(m.active.Config.IPConfigs))
+		for ip, ipConfig := range m.active.Config.IPConfigs {
+			if ip != key {
+				result.IPConfigs[ip] = ipConfig
+			}
+		}
+	} else {
+		result.TokenConfigs = make(map[string]domain.TokenConfig, len(m.active.Config.TokenConfigs))
+		for token, tokenConfig := range m.active.Config.TokenConfigs {
+			if token != key {
+				result.TokenConfigs[token] = tokenConfig
+			}
+		}
+	}
+
+	m.activate(&result)
+	revision := m.recordRevision(id, document, RuleRevision{Action: RuleActionDelete, Actor: actor})
+	m.logRuleChange("Rule deleted", id, revision)
+	return revision, nil
+}
+
+// RestoreRule reativa o documento de uma revisão anterior da regra, inclusive
+// de uma regra excluída. A restauração entra no histórico como nova revisão
+func (m *Manager) RestoreRule(id string, version int, actor string) ([]byte, string, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var target *RuleRevision
+	for i := range m.history[id] {
+		if m.history[id][i].Version == version {
+			target = &m.history[id][i]
+			break
+		}
+	}
+	if target == nil {
+		// Regra nunca editada: a versão 1 é a atual, nada a restaurar
+		if current, err := ruleDocument(m.active.Config, id); err == nil && len(m.history[id]) == 0 && version == 1 {
+			return current, ruleETag(current), nil
+		}
+		return nil, "", fmt.Errorf("%w: %s version %d", ErrRevisionNotFound, id, version)
+	}
+	if len(target.Document) == 0 {
+		return nil, "", fmt.Errorf("%w: version %d is a deletion", ErrInvalidRule, version)
+	}
+
+	change, err := ruleChange(id, target.Document)
+	if err != nil {
+		return nil, "", err
+	}
+	result, err := change.Apply(m.active.Config)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidRule, err)
+	}
+
+	// Regra excluída: não há versão atual a preservar
+	previous, _ := ruleDocument(m.active.Config, id)
+	m.activate(result)
+
+	restored, err := ruleDocument(result, id)
+	if err != nil {
+		return nil, "", err
+	}
+	revision := m.recordRevision(id, previous, RuleRevision{
+		Action:       RuleActionRestore,
+		Actor:        actor,
+		Document:     restored,
+		ETag:         ruleETag(restored),
+		RestoredFrom: version,
+	})
+	m.logRuleChange("Rule restored", id, revision)
+	return restored, revision.ETag, nil
+}
+
+// activate troca a configuração ativa; a anterior fica disponível para rollback
+// Deve ser chamado com o write lock
+func (m *Manager) activate(cfg *domain.RateLimitConfig) {
+	previous := m.active
+	m.previous = &previous
+	m.active = m.version(cfg)
+}
+
+// recordRevision acrescenta a revisão ao histórico da regra. Na primeira edição,
+// before (a versão vigente) entra como revisão inicial para poder ser restaurada.
+// Deve ser chamado com o write lock
+func (m *Manager) recordRevision(id string, before []byte, revision RuleRevision) RuleRevision {
+	revisions := m.history[id]
+	if len(revisions) == 0 && before != nil {
+		initial := m.initialRevision(before)
+		if m.previous != nil {
+			initial.At = m.previous.Since
+		}
+		revisions = append(revisions, initial)
+	}
+
+	revision.Version = 1
+	if len(revisions) > 0 {
+		revision.Version = revisions[len(revisions)-1].Version + 1
+	}
+	revision.At = m.now().UTC()
+	if revision.Actor == "" {
+		revision.Actor = "unknown"
+	}
+
+	revisions = append(revisions, revision)
+	if len(revisions) > maxRuleRevisions {
+		revisions = revisions[len(revisions)-maxRuleRevisions:]
+	}
+	m.history[id] = revisions
+	return revision
+}
+
+// initialRevision representa a versão da regra carregada da configuração
+func (m *Manager) initialRevision(document []byte) RuleRevision {
+	return RuleRevision{
+		Version:  1,
+		Action:   RuleActionInitial,
+		Actor:    initialActor,
+		At:       m.active.Since.UTC(),
+		Document: json.RawMessage(bytes.Clone(document)),
+		ETag:     ruleETag(document),
+	}
+}
+
+func (m *Manager) logRuleChange(message, id string, revision RuleRevision) {
+	if m.logger == nil {
+		return
+	}
+	m.logger.Info(message, map[string]interface{}{
+		"rule_id":     maskRuleID(id),
+		"version":     revision.Version,
+		"actor":       revision.Actor,
+		"fingerprint": m.active.Fingerprint,
+	})
+}
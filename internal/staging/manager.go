@@ -0,0 +1,167 @@
+package staging
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"rate-limiter/internal/config"
+	"rate-limiter/internal/domain"
+)
+
+var (
+	// ErrNothingStaged indica que não há configuração staged para promover
+	ErrNothingStaged = errors.New("no staged config")
+	// ErrStagedOutdated indica que a configuração ativa mudou depois do stage
+	ErrStagedOutdated = errors.New("staged config was built on an outdated active config")
+	// ErrNothingToRollback indica que não há configuração anterior para restaurar
+	ErrNothingToRollback = errors.New("no previous config to roll back to")
+)
+
+// Version é uma configuração identificada pela impressão digital
+type Version struct {
+	Config      *domain.RateLimitConfig
+	Fingerprint string
+	Since       time.Time // Momento do stage ou da ativação
+}
+
+// Status resume as versões mantidas pelo gerenciador
+type Status struct {
+	Active   Version
+	Staged   *Version
+	Previous *Version
+}
+
+// Manager mantém a configuração ativa, uma candidata staged e a anterior.
+// Implementa domain.ConfigSource: a promoção troca a configuração de uma vez,
+// e a anterior fica guardada para rollback
+type Manager struct {
+	logger domain.Logger
+
+	mutex        sync.RWMutex
+	active       Version
+	staged       *Version
+	previous     *Version
+	stagedOnBase string                    // impressão digital da ativa no momento do stage
+	history      map[string][]RuleRevision // ID da regra -> revisões, da mais antiga à mais nova
+
+	now func() time.Time
+}
+
+// NewManager cria o gerenciador a partir da configuração carregada na inicialização
+func NewManager(active *domain.RateLimitConfig, logger domain.Logger) *Manager {
+	m := &Manager{logger: logger, now: time.Now, history: make(map[string][]RuleRevision)}
+	m.active = m.version(active)
+	return m
+}
+
+// ActiveConfig implementa domain.ConfigSource
+func (m *Manager) ActiveConfig() *domain.RateLimitConfig {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.active.Config
+}
+
+// Stage valida a candidata sobre a configuração ativa e a guarda sem aplicar.
+// Um novo stage substitui o anterior
+func (m *Manager) Stage(candidate config.StagedConfig) (Version, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	result, err := candidate.Apply(m.active.Config)
+	if err != nil {
+		return Version{}, err
+	}
+
+	staged := m.version(result)
+	m.staged = &staged
+	m.stagedOnBase = m.active.Fingerprint
+
+	m.log("Config staged", staged)
+	return staged, nil
+}
+
+// Discard descarta a configuração staged
+func (m *Manager) Discard() bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	discarded := m.staged != nil
+	m.staged = nil
+	return discarded
+}
+
+// Promote ativa a configuração staged; a ativa passa a ser a anterior
+func (m *Manager) Promote() (Version, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.staged == nil {
+		return Version{}, ErrNothingStaged
+	}
+	if m.stagedOnBase != m.active.Fingerprint {
+		return Version{}, fmt.Errorf("%w: stage again", ErrStagedOutdated)
+	}
+
+	previous := m.active
+	m.previous = &previous
+	m.active = *m.staged
+	m.active.Since = m.now()
+	m.staged = nil
+
+	m.log("Config promoted", m.active)
+	return m.active, nil
+}
+
+// Rollback restaura a configuração anterior à última promoção
+func (m *Manager) Rollback() (Version, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.previous == nil {
+		return Version{}, ErrNothingToRollback
+	}
+
+	rolledBack := m.active
+	m.active = *m.previous
+	m.active.Since = m.now()
+	m.previous = &rolledBack
+
+	m.log("Config rolled back", m.active)
+	return m.active, nil
+}
+
+// Status retorna as versões ativa, staged e anterior
+func (m *Manager) Status() Status {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	status := Status{Active: m.active}
+	if m.staged != nil {
+		staged := *m.staged
+		status.Staged = &staged
+	}
+	if m.previous != nil {
+		previous := *m.previous
+		status.Previous = &previous
+	}
+	return status
+}
+
+func (m *Manager) version(cfg *domain.RateLimitConfig) Version {
+	return Version{Config: cfg, Fingerprint: config.Fingerprint(cfg), Since: m.now()}
+}
+
+func (m *Manager) log(message string, version Version) {
+	if m.logger == nil {
+		return
+	}
+	m.logger.Info(message, map[string]interface{}{
+		"fingerprint":   version.Fingerprint,
+		"ip_limit":      version.Config.DefaultIPLimit,
+		"token_limit":   version.Config.DefaultTokenLimit,
+		"window":        version.Config.Window,
+		"token_configs": len(version.Config.TokenConfigs),
+	})
+}
@@ -0,0 +1,77 @@
+package staging
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/config"
+	"rate-limiter/internal/domain"
+)
+
+func newTestConfig() *domain.RateLimitConfig {
+	return &domain.RateLimitConfig{
+		DefaultIPLimit:    10,
+		DefaultTokenLimit: 100,
+		Window:            60,
+		BlockDuration:     180,
+		TokenConfigs:      map[string]domain.TokenConfig{},
+	}
+}
+
+func intPtr(value int) *int { return &value }
+
+func TestManager_StagePromoteAndRollback(t *testing.T) {
+	// Arrange
+	initial := newTestConfig()
+	manager := NewManager(initial, nil)
+
+	// Act: stage não altera a configuração ativa
+	staged, err := manager.Stage(config.StagedConfig{DefaultIPLimit: intPtr(20)})
+	require.NoError(t, err)
+	assert.Same(t, initial, manager.ActiveConfig())
+	assert.NotEqual(t, manager.Status().Active.Fingerprint, staged.Fingerprint)
+
+	// Act: promote ativa a candidata
+	promoted, err := manager.Promote()
+	require.NoError(t, err)
+	assert.Equal(t, staged.Fingerprint, promoted.Fingerprint)
+	assert.Equal(t, 20, manager.ActiveConfig().DefaultIPLimit)
+	assert.Nil(t, manager.Status().Staged)
+
+	// Act: rollback restaura a anterior
+	_, err = manager.Rollback()
+	require.NoError(t, err)
+	assert.Same(t, initial, manager.ActiveConfig())
+	assert.Equal(t, staged.Fingerprint, manager.Status().Previous.Fingerprint)
+}
+
+func TestManager_RejectsInvalidAndOutdatedStages(t *testing.T) {
+	// Arrange
+	manager := NewManager(newTestConfig(), nil)
+
+	// Validação falha: nada fica staged
+	_, err := manager.Stage(config.StagedConfig{Window: intPtr(0)})
+	assert.Error(t, err)
+	_, err = manager.Promote()
+	assert.ErrorIs(t, err, ErrNothingStaged)
+	_, err = manager.Rollback()
+	assert.ErrorIs(t, err, ErrNothingToRollback)
+
+	// A ativa muda depois do stage: a candidata foi construída sobre outra base
+	_, err = manager.Stage(config.StagedConfig{DefaultIPLimit: intPtr(20)})
+	require.NoError(t, err)
+	_, err = manager.Stage(config.StagedConfig{DefaultIPLimit: intPtr(30)})
+	require.NoError(t, err)
+	_, err = manager.Promote()
+	require.NoError(t, err)
+	_, err = manager.Stage(config.StagedConfig{DefaultTokenLimit: intPtr(200)})
+	require.NoError(t, err)
+	_, err = manager.Rollback()
+	require.NoError(t, err)
+
+	_, err = manager.Promote()
+	assert.ErrorIs(t, err, ErrStagedOutdated)
+	assert.Equal(t, 10, manager.ActiveConfig().DefaultIPLimit)
+}
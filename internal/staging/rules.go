@@ -0,0 +1,244 @@
+package staging
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+
+	"rate-limiter/internal/config"
+	"rate-limiter/internal/domain"
+)
+
+// wildcardKey identifica a regra padrão de um tipo (ex: "ip:*")
+const wildcardKey = "*"
+
+var (
+	// ErrRuleNotFound indica que não há regra com o ID informado
+	ErrRuleNotFound = errors.New("rule not found")
+	// ErrRuleModified indica que a regra mudou desde a versão informada no If-Match
+	ErrRuleModified = errors.New("rule was modified")
+	// ErrInvalidPatch indica um documento JSON Patch mal formado ou que não se aplica
+	ErrInvalidPatch = errors.New("invalid JSON patch")
+	// ErrPatchTestFailed indica que uma operação "test" do patch falhou
+	ErrPatchTestFailed = errors.New("JSON patch test operation failed")
+	// ErrInvalidRule indica que a regra resultante não passou na validação
+	ErrInvalidRule = errors.New("invalid rule")
+	// ErrRuleExists indica que já há regra com o ID informado na criação
+	ErrRuleExists = errors.New("rule already exists")
+)
+
+// defaultRule é o documento editável dos limites padrão ("ip:*", "token:*")
+type defaultRule struct {
+	Limit int `json:"limit"`
+}
+
+// Rule retorna o documento JSON da regra e sua ETag.
+// IDs: "ip:*" e "token:*" (limites padrão), "token:<token>" ou "ip:<ip>"
+func (m *Manager) Rule(id string) ([]byte, string, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	document, err := ruleDocument(m.active.Config, id)
+	if err != nil {
+		return nil, "", err
+	}
+	return document, ruleETag(document), nil
+}
+
+// PatchRule aplica um JSON Patch (RFC 6902) à regra e ativa o resultado.
+// ifMatch deve conter a ETag atual da regra ("*" aceita qualquer versão).
+// A configuração ativa anterior fica disponível para rollback, e a nova
+// versão entra no histórico da regra em nome de actor
+func (m *Manager) PatchRule(id, ifMatch string, patchData []byte, actor string) ([]byte, string, error) {
+	patch, err := jsonpatch.DecodePatch(patchData)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	document, err := ruleDocument(m.active.Config, id)
+	if err != nil {
+		return nil, "", err
+	}
+	if !etagMatches(ifMatch, ruleETag(document)) {
+		return nil, "", ErrRuleModified
+	}
+
+	patched, err := patch.Apply(document)
+	if errors.Is(err, jsonpatch.ErrTestFailed) {
+		return nil, "", fmt.Errorf("%w: %v", ErrPatchTestFailed, err)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidPatch, err)
+	}
+
+	return m.applyRule(id, document, patched, RuleActionUpdate, actor, "Rule patched")
+}
+
+// CreateRule cria a regra de um token ou IP ("token:<token>", "ip:<ip>") a partir
+// do documento completo e ativa o resultado. Os limites padrão sempre existem
+func (m *Manager) CreateRule(id string, document []byte, actor string) ([]byte, string, error) {
+	limiterType, key, ok := strings.Cut(id, ":")
+	if !ok || key == "" || (limiterType != string(domain.IPLimiter) && limiterType != string(domain.TokenLimiter)) {
+		return nil, "", fmt.Errorf("%w: id must be token:<token> or ip:<ip>", ErrInvalidRule)
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, err := ruleDocument(m.active.Config, id); err == nil {
+		return nil, "", fmt.Errorf("%w: %s", ErrRuleExists, id)
+	}
+
+	return m.applyRule(id, nil, document, RuleActionCreate, actor, "Rule created")
+}
+
+// ReplaceRule substitui o documento inteiro da regra e ativa o resultado.
+// ifMatch segue as mesmas regras do PatchRule
+func (m *Manager) ReplaceRule(id, ifMatch string, document []byte, actor string) ([]byte, string, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	current, err := ruleDocument(m.active.Config, id)
+	if err != nil {
+		return nil, "", err
+	}
+	if !etagMatches(ifMatch, ruleETag(current)) {
+		return nil, "", ErrRuleModified
+	}
+
+	return m.applyRule(id, current, document, RuleActionUpdate, actor, "Rule replaced")
+}
+
+// applyRule valida o novo documento da regra, ativa a configuração resultante e
+// registra a revisão; before é a versão vigente (nil na criação).
+// Deve ser chamado com o write lock
+func (m *Manager) applyRule(id string, before, document []byte, action, actor, message string) ([]byte, string, error) {
+	change, err := ruleChange(id, document)
+	if err != nil {
+		return nil, "", err
+	}
+	result, err := change.Apply(m.active.Config)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrInvalidRule, err)
+	}
+
+	m.activate(result)
+
+	updated, err := ruleDocument(result, id)
+	if err != nil {
+		return nil, "", err
+	}
+	revision := m.recordRevision(id, before, RuleRevision{
+		Action:   action,
+		Actor:    actor,
+		Document: updated,
+		ETag:     ruleETag(updated),
+	})
+	m.logRuleChange(message, id, revision)
+	return updated, revision.ETag, nil
+}
+
+// ruleDocument serializa a regra identificada pelo ID
+func ruleDocument(cfg *domain.RateLimitConfig, id string) ([]byte, error) {
+	limiterType, key, ok := strings.Cut(id, ":")
+	if !ok || key == "" {
+		return nil, fmt.Errorf("%w: %s", ErrRuleNotFound, id)
+	}
+
+	var rule interface{}
+	switch {
+	case limiterType == string(domain.IPLimiter) && key == wildcardKey:
+		rule = defaultRule{Limit: cfg.DefaultIPLimit}
+	case limiterType == string(domain.TokenLimiter) && key == wildcardKey:
+		rule = defaultRule{Limit: cfg.DefaultTokenLimit}
+	case limiterType == string(domain.TokenLimiter):
+		tokenConfig, exists := cfg.TokenConfigs[key]
+		if !exists {
+			return nil, fmt.Errorf("%w: %s", ErrRuleNotFound, id)
+		}
+		rule = tokenConfig
+	case limiterType == string(domain.IPLimiter):
+		ipConfig, exists := cfg.IPConfigs[key]
+		if !exists {
+			return nil, fmt.Errorf("%w: %s", ErrRuleNotFound, id)
+		}
+		rule = ipConfig
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrRuleNotFound, id)
+	}
+
+	return json.Marshal(rule)
+}
+
+// ruleChange converte o documento alterado em uma mudança sobre a configuração ativa
+func ruleChange(id string, document []byte) (config.StagedConfig, error) {
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.DisallowUnknownFields()
+
+	limiterType, key, _ := strings.Cut(id, ":")
+	if key == wildcardKey {
+		var rule defaultRule
+		if err := decoder.Decode(&rule); err != nil {
+			return config.StagedConfig{}, fmt.Errorf("%w: %v", ErrInvalidRule, err)
+		}
+		if limiterType == string(domain.IPLimiter) {
+			return config.StagedConfig{DefaultIPLimit: &rule.Limit}, nil
+		}
+		return config.StagedConfig{DefaultTokenLimit: &rule.Limit}, nil
+	}
+
+	if limiterType == string(domain.IPLimiter) {
+		var ipConfig domain.IPConfig
+		if err := decoder.Decode(&ipConfig); err != nil {
+			return config.StagedConfig{}, fmt.Errorf("%w: %v", ErrInvalidRule, err)
+		}
+		if ipConfig.IP != key {
+			return config.StagedConfig{}, fmt.Errorf("%w: ip cannot be changed", ErrInvalidRule)
+		}
+		return config.StagedConfig{IPs: map[string]domain.IPConfig{key: ipConfig}}, nil
+	}
+
+	var tokenConfig domain.TokenConfig
+	if err := decoder.Decode(&tokenConfig); err != nil {
+		return config.StagedConfig{}, fmt.Errorf("%w: %v", ErrInvalidRule, err)
+	}
+	if tokenConfig.Token != key {
+		return config.StagedConfig{}, fmt.Errorf("%w: token cannot be changed", ErrInvalidRule)
+	}
+	return config.StagedConfig{Tokens: map[string]domain.TokenConfig{key: tokenConfig}}, nil
+}
+
+// ruleETag identifica a versão do documento da regra
+func ruleETag(document []byte) string {
+	sum := sha256.Sum256(document)
+	return `"` + hex.EncodeToString(sum[:])[:16] + `"`
+}
+
+// etagMatches avalia o If-Match (lista separada por vírgulas ou "*")
+func etagMatches(ifMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// maskRuleID evita expor tokens completos nos logs
+func maskRuleID(id string) string {
+	limiterType, key, _ := strings.Cut(id, ":")
+	if limiterType != string(domain.TokenLimiter) || key == wildcardKey || len(key) <= 4 {
+		return id
+	}
+	return limiterType + ":" + key[:4] + "***"
+}
@@ -0,0 +1,214 @@
+package staging
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/domain"
+)
+
+func newRuleTestManager() *Manager {
+	cfg := newTestConfig()
+	cfg.TokenConfigs["premium_token"] = domain.TokenConfig{Token: "premium_token", Limit: 1000, Description: "Premium"}
+	return NewManager(cfg, nil)
+}
+
+func TestManager_PatchRule(t *testing.T) {
+	// Arrange
+	manager := newRuleTestManager()
+	_, etag, err := manager.Rule("token:premium_token")
+	require.NoError(t, err)
+
+	// Act
+	document, newETag, err := manager.PatchRule("token:premium_token", etag,
+		[]byte(`[{"op":"test","path":"/limit","value":1000},{"op":"replace","path":"/limit","value":2000}]`), "ops")
+
+	// Assert
+	require.NoError(t, err)
+	assert.NotEqual(t, etag, newETag)
+	var rule domain.TokenConfig
+	require.NoError(t, json.Unmarshal(document, &rule))
+	assert.Equal(t, 2000, rule.Limit)
+	assert.Equal(t, "Premium", rule.Description)
+	assert.Equal(t, 2000, manager.ActiveConfig().TokenConfigs["premium_token"].Limit)
+	assert.Equal(t, 1000, manager.Status().Previous.Config.TokenConfigs["premium_token"].Limit)
+
+	// A ETag antiga não vale mais
+	_, _, err = manager.PatchRule("token:premium_token", etag, []byte(`[{"op":"replace","path":"/limit","value":3000}]`), "ops")
+	assert.ErrorIs(t, err, ErrRuleModified)
+}
+
+func TestManager_PatchRule_DefaultLimit(t *testing.T) {
+	manager := newRuleTestManager()
+
+	document, _, err := manager.PatchRule("ip:*", "*", []byte(`[{"op":"replace","path":"/limit","value":25}]`), "ops")
+
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"limit":25}`, string(document))
+	assert.Equal(t, 25, manager.ActiveConfig().DefaultIPLimit)
+}
+
+func TestManager_PatchRule_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		id    string
+		patch string
+		want  error
+	}{
+		{"unknown rule", "token:missing", `[]`, ErrRuleNotFound},
+		{"malformed patch", "ip:*", `{"op":"replace"}`, ErrInvalidPatch},
+		{"missing path", "ip:*", `[{"op":"replace","path":"/window","value":1}]`, ErrInvalidPatch},
+		{"failed test", "ip:*", `[{"op":"test","path":"/limit","value":99}]`, ErrPatchTestFailed},
+		{"invalid limit", "ip:*", `[{"op":"replace","path":"/limit","value":0}]`, ErrInvalidRule},
+		{"unknown field", "token:premium_token", `[{"op":"add","path":"/limt","value":1}]`, ErrInvalidRule},
+		{"renamed token", "token:premium_token", `[{"op":"replace","path":"/token","value":"other"}]`, ErrInvalidRule},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := newRuleTestManager()
+			active := manager.ActiveConfig()
+
+			_, _, err := manager.PatchRule(tt.id, "*", []byte(tt.patch), "ops")
+
+			assert.ErrorIs(t, err, tt.want)
+			assert.Same(t, active, manager.ActiveConfig())
+		})
+	}
+}
+
+func TestManager_RuleHistory(t *testing.T) {
+	// Arrange
+	manager := newRuleTestManager()
+	_, _, err := manager.PatchRule("token:premium_token", "*", []byte(`[{"op":"replace","path":"/limit","value":2000}]`), "alice")
+	require.NoError(t, err)
+
+	// Act: exclusão mantém o histórico
+	deleted, err := manager.DeleteRule("token:premium_token", "*", "bob")
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, 3, deleted.Version)
+	_, _, err = manager.Rule("token:premium_token")
+	assert.ErrorIs(t, err, ErrRuleNotFound)
+	assert.NotContains(t, manager.ActiveConfig().TokenConfigs, "premium_token")
+
+	history, err := manager.RuleHistory("token:premium_token")
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.Equal(t, RuleActionDelete, history[0].Action)
+	assert.Equal(t, "bob", history[0].Actor)
+	assert.Empty(t, history[0].Document)
+	assert.Equal(t, RuleActionUpdate, history[1].Action)
+	assert.Equal(t, "alice", history[1].Actor)
+	assert.Equal(t, RuleActionInitial, history[2].Action)
+	assert.JSONEq(t, `{"token":"premium_token","limit":1000,"description":"Premium"}`, string(history[2].Document))
+
+	// Act: restaura a versão original
+	document, etag, err := manager.RestoreRule("token:premium_token", 1, "carol")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, history[2].ETag, etag)
+	assert.JSONEq(t, string(history[2].Document), string(document))
+	assert.Equal(t, 1000, manager.ActiveConfig().TokenConfigs["premium_token"].Limit)
+
+	history, err = manager.RuleHistory("token:premium_token")
+	require.NoError(t, err)
+	assert.Equal(t, RuleActionRestore, history[0].Action)
+	assert.Equal(t, 4, history[0].Version)
+	assert.Equal(t, 1, history[0].RestoredFrom)
+}
+
+func TestManager_RuleHistory_Errors(t *testing.T) {
+	manager := newRuleTestManager()
+
+	// Regra nunca editada: apenas a versão atual
+	history, err := manager.RuleHistory("ip:*")
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, RuleActionInitial, history[0].Action)
+
+	_, err = manager.RuleHistory("token:missing")
+	assert.ErrorIs(t, err, ErrRuleNotFound)
+
+	_, err = manager.DeleteRule("ip:*", "*", "ops")
+	assert.ErrorIs(t, err, ErrInvalidRule)
+
+	_, err = manager.DeleteRule("token:premium_token", `"stale"`, "ops")
+	assert.ErrorIs(t, err, ErrRuleModified)
+
+	_, _, err = manager.RestoreRule("token:premium_token", 7, "ops")
+	assert.ErrorIs(t, err, ErrRevisionNotFound)
+
+	_, err = manager.DeleteRule("token:premium_token", "*", "ops")
+	require.NoError(t, err)
+	_, _, err = manager.RestoreRule("token:premium_token", 2, "ops")
+	assert.ErrorIs(t, err, ErrInvalidRule, "a deletion cannot be restored")
+}
+
+func TestManager_CreateAndReplaceRule(t *testing.T) {
+	// Arrange
+	manager := newRuleTestManager()
+
+	// Act: cria uma regra de IP e substitui o documento inteiro
+	created, etag, err := manager.CreateRule("ip:10.0.0.5", []byte(`{"ip":"10.0.0.5","limit":30}`), "alice")
+	require.NoError(t, err)
+	replaced, _, err := manager.ReplaceRule("ip:10.0.0.5", etag, []byte(`{"ip":"10.0.0.5","limit":50,"description":"Partner"}`), "bob")
+	require.NoError(t, err)
+
+	// Assert
+	assert.JSONEq(t, `{"ip":"10.0.0.5","limit":30}`, string(created))
+	assert.JSONEq(t, `{"ip":"10.0.0.5","limit":50,"description":"Partner"}`, string(replaced))
+	assert.Equal(t, 50, manager.ActiveConfig().IPConfigs["10.0.0.5"].Limit)
+
+	history, err := manager.RuleHistory("ip:10.0.0.5")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, RuleActionUpdate, history[0].Action)
+	assert.Equal(t, RuleActionCreate, history[1].Action)
+	assert.Equal(t, 1, history[1].Version)
+
+	// A exclusão remove o IP da configuração ativa
+	_, err = manager.DeleteRule("ip:10.0.0.5", "*", "carol")
+	require.NoError(t, err)
+	assert.NotContains(t, manager.ActiveConfig().IPConfigs, "10.0.0.5")
+}
+
+func TestManager_CreateRule_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		id       string
+		document string
+		want     error
+	}{
+		{"existing token", "token:premium_token", `{"token":"premium_token","limit":10}`, ErrRuleExists},
+		{"default rule", "ip:*", `{"limit":10}`, ErrRuleExists},
+		{"unknown type", "user:alice", `{"limit":10}`, ErrInvalidRule},
+		{"mismatched token", "token:new_token", `{"token":"other","limit":10}`, ErrInvalidRule},
+		{"invalid ip", "ip:not-an-ip", `{"ip":"not-an-ip","limit":10}`, ErrInvalidRule},
+		{"invalid limit", "ip:10.0.0.5", `{"ip":"10.0.0.5","limit":0}`, ErrInvalidRule},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := newRuleTestManager()
+			active := manager.ActiveConfig()
+
+			_, _, err := manager.CreateRule(tt.id, []byte(tt.document), "ops")
+
+			assert.ErrorIs(t, err, tt.want)
+			assert.Same(t, active, manager.ActiveConfig())
+		})
+	}
+
+	// A substituição exige a ETag atual
+	manager := newRuleTestManager()
+	_, _, err := manager.ReplaceRule("token:premium_token", `"stale"`, []byte(`{"token":"premium_token","limit":10}`), "ops")
+	assert.ErrorIs(t, err, ErrRuleModified)
+	_, _, err = manager.ReplaceRule("ip:10.0.0.9", "*", []byte(`{"ip":"10.0.0.9","limit":10}`), "ops")
+	assert.ErrorIs(t, err, ErrRuleNotFound)
+}
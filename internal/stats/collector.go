@@ -0,0 +1,274 @@
+package stats
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"rate-limiter/internal/domain"
+)
+
+// Config define a resolução e o período cobertos pelas estatísticas
+type Config struct {
+	Resolution time.Duration // Duração de cada intervalo contado
+	Retention  time.Duration // Período máximo de uma consulta
+	MaxKeys    int           // Chaves acompanhadas individualmente por intervalo
+}
+
+// DefaultConfig retorna intervalos de 10s mantidos por 15 minutos
+func DefaultConfig() Config {
+	return Config{
+		Resolution: 10 * time.Second,
+		Retention:  15 * time.Minute,
+		MaxKeys:    10000,
+	}
+}
+
+// Counts são as decisões contadas para um tipo de limiter ou uma chave
+type Counts struct {
+	Allowed uint64 `json:"allowed"`
+	Denied  uint64 `json:"denied"`
+	Blocked uint64 `json:"blocked"` // Bloqueios aplicados por exceder o limite
+}
+
+// Requests retorna o total de decisões (permitidas e negadas)
+func (c Counts) Requests() uint64 {
+	return c.Allowed + c.Denied
+}
+
+func (c *Counts) add(other Counts) {
+	c.Allowed += other.Allowed
+	c.Denied += other.Denied
+	c.Blocked += other.Blocked
+}
+
+// KeyStats são as contagens de uma chave no período consultado
+type KeyStats struct {
+	Key  string             `json:"key"`
+	Type domain.LimiterType `json:"type"`
+	Counts
+}
+
+// Order define a ordenação das chaves mais ativas
+type Order string
+
+const (
+	OrderRequests Order = "requests"
+	OrderDenied   Order = "denied"
+	OrderBlocked  Order = "blocked"
+)
+
+// ParseOrder valida a ordenação informada (vazio = OrderRequests)
+func ParseOrder(value string) (Order, bool) {
+	switch Order(value) {
+	case "", OrderRequests:
+		return OrderRequests, true
+	case OrderDenied, OrderBlocked:
+		return Order(value), true
+	}
+	return "", false
+}
+
+// Snapshot são as estatísticas agregadas de [From, To)
+type Snapshot struct {
+	From    time.Time
+	To      time.Time
+	Totals  Counts
+	ByType  map[domain.LimiterType]Counts
+	TopKeys []KeyStats
+	// Chaves descartadas por exceder MaxKeys em algum intervalo; o top pode estar incompleto
+	Truncated bool
+}
+
+type keyID struct {
+	key         string
+	limiterType domain.LimiterType
+}
+
+// interval guarda as contagens de um intervalo de Resolution
+type interval struct {
+	start     time.Time
+	byType    map[domain.LimiterType]*Counts
+	keys      map[keyID]*Counts
+	truncated bool
+}
+
+// Collector conta as decisões em memória, por tipo de limiter e por chave, em
+// intervalos rotativos; intervalos mais antigos que Retention são reaproveitados
+type Collector struct {
+	config Config
+
+	mutex     sync.Mutex
+	intervals []interval
+
+	now func() time.Time
+}
+
+// NewCollector cria o coletor; valores zerados em config usam DefaultConfig
+func NewCollector(config Config) *Collector {
+	defaults := DefaultConfig()
+	if config.Resolution <= 0 {
+		config.Resolution = defaults.Resolution
+	}
+	if config.Retention < config.Resolution {
+		config.Retention = defaults.Retention
+	}
+	if config.MaxKeys <= 0 {
+		config.MaxKeys = defaults.MaxKeys
+	}
+
+	slots := int((config.Retention + config.Resolution - 1) / config.Resolution)
+	return &Collector{
+		config:    config,
+		intervals: make([]interval, slots),
+		now:       time.Now,
+	}
+}
+
+// Retention retorna o período máximo de uma consulta
+func (c *Collector) Retention() time.Duration {
+	return c.config.Retention
+}
+
+// ObserveDecision implementa domain.DecisionObserver
+func (c *Collector) ObserveDecision(key string, limiterType domain.LimiterType, allowed bool) {
+	var counts Counts
+	if allowed {
+		counts.Allowed = 1
+	} else {
+		counts.Denied = 1
+	}
+	c.record(key, limiterType, counts)
+}
+
+// RecordBlock implementa domain.BlockRecorder
+func (c *Collector) RecordBlock(record domain.BlockRecord) {
+	c.record(record.Key, record.Type, Counts{Blocked: 1})
+}
+
+// RecordRejected implementa domain.BlockRecorder; a recusa já é contada em ObserveDecision
+func (c *Collector) RecordRejected(key string, limiterType domain.LimiterType) {}
+
+// record soma as contagens no intervalo corrente
+func (c *Collector) record(key string, limiterType domain.LimiterType, counts Counts) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	current := c.currentLocked(c.now())
+	byType, exists := current.byType[limiterType]
+	if !exists {
+		byType = &Counts{}
+		current.byType[limiterType] = byType
+	}
+	byType.add(counts)
+
+	id := keyID{key: key, limiterType: limiterType}
+	byKey, exists := current.keys[id]
+	if !exists {
+		// Acima do limite a chave ainda conta no total do tipo, mas não no top
+		if len(current.keys) >= c.config.MaxKeys {
+			current.truncated = true
+			return
+		}
+		byKey = &Counts{}
+		current.keys[id] = byKey
+	}
+	byKey.add(counts)
+}
+
+// currentLocked retorna o intervalo de now, reiniciando o slot se ele guardava um intervalo antigo
+func (c *Collector) currentLocked(now time.Time) *interval {
+	start := now.Truncate(c.config.Resolution)
+	slot := &c.intervals[int(start.UnixNano()/int64(c.config.Resolution))%len(c.intervals)]
+	if !slot.start.Equal(start) {
+		slot.start = start
+		slot.truncated = false
+		if slot.byType == nil {
+			slot.byType = make(map[domain.LimiterType]*Counts)
+			slot.keys = make(map[keyID]*Counts)
+		} else {
+			clear(slot.byType)
+			clear(slot.keys)
+		}
+	}
+	return slot
+}
+
+// Snapshot agrega os intervalos da última window (limitada a Retention, incluindo o
+// intervalo em andamento) e retorna as top chaves mais ativas segundo order
+func (c *Collector) Snapshot(window time.Duration, top int, order Order) Snapshot {
+	if window <= 0 || window > c.config.Retention {
+		window = c.config.Retention
+	}
+
+	now := c.now()
+	current := now.Truncate(c.config.Resolution)
+	slots := int((window + c.config.Resolution - 1) / c.config.Resolution)
+	from := current.Add(-time.Duration(slots-1) * c.config.Resolution)
+
+	snapshot := Snapshot{From: from, To: now, ByType: make(map[domain.LimiterType]Counts)}
+	keys := make(map[keyID]*Counts)
+
+	c.mutex.Lock()
+	for i := range c.intervals {
+		slot := &c.intervals[i]
+		if slot.byType == nil || slot.start.Before(from) || slot.start.After(current) {
+			continue
+		}
+		for limiterType, counts := range slot.byType {
+			total := snapshot.ByType[limiterType]
+			total.add(*counts)
+			snapshot.ByType[limiterType] = total
+			snapshot.Totals.add(*counts)
+		}
+		for id, counts := range slot.keys {
+			total, exists := keys[id]
+			if !exists {
+				total = &Counts{}
+				keys[id] = total
+			}
+			total.add(*counts)
+		}
+		snapshot.Truncated = snapshot.Truncated || slot.truncated
+	}
+	c.mutex.Unlock()
+
+	snapshot.TopKeys = topKeys(keys, top, order)
+	return snapshot
+}
+
+// topKeys ordena as chaves pela métrica pedida (empates pela chave) e retorna as top primeiras
+func topKeys(keys map[keyID]*Counts, top int, order Order) []KeyStats {
+	result := make([]KeyStats, 0, len(keys))
+	for id, counts := range keys {
+		result = append(result, KeyStats{Key: id.key, Type: id.limiterType, Counts: *counts})
+	}
+
+	value := func(stats KeyStats) uint64 {
+		switch order {
+		case OrderDenied:
+			return stats.Denied
+		case OrderBlocked:
+			return stats.Blocked
+		}
+		return stats.Requests()
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if vi, vj := value(result[i]), value(result[j]); vi != vj {
+			return vi > vj
+		}
+		if result[i].Key != result[j].Key {
+			return result[i].Key < result[j].Key
+		}
+		return result[i].Type < result[j].Type
+	})
+
+	// Chaves sem nenhuma ocorrência da métrica não entram no top
+	for len(result) > 0 && value(result[len(result)-1]) == 0 {
+		result = result[:len(result)-1]
+	}
+	if top >= 0 && len(result) > top {
+		result = result[:top]
+	}
+	return result
+}
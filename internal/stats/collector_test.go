@@ -0,0 +1,107 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/domain"
+)
+
+func TestCollector_Snapshot(t *testing.T) {
+	// Arrange
+	now := time.Date(2024, 1, 1, 10, 0, 5, 0, time.UTC)
+	collector := NewCollector(Config{Resolution: 10 * time.Second, Retention: time.Minute})
+	collector.now = func() time.Time { return now }
+
+	collector.ObserveDecision("10.0.0.1", domain.IPLimiter, true)
+	collector.ObserveDecision("10.0.0.1", domain.IPLimiter, false)
+	collector.RecordBlock(domain.BlockRecord{Key: "10.0.0.1", Type: domain.IPLimiter})
+
+	now = now.Add(20 * time.Second)
+	for i := 0; i < 3; i++ {
+		collector.ObserveDecision("tk_hot", domain.TokenLimiter, true)
+	}
+	collector.ObserveDecision("10.0.0.2", domain.IPLimiter, true)
+
+	// Act
+	last := collector.Snapshot(15*time.Second, 10, OrderRequests)
+	all := collector.Snapshot(0, 1, OrderRequests)
+	denied := collector.Snapshot(time.Minute, 10, OrderDenied)
+
+	// Assert: a janela curta cobre só os intervalos recentes (incluindo o em andamento)
+	assert.Equal(t, time.Date(2024, 1, 1, 10, 0, 10, 0, time.UTC), last.From)
+	assert.Equal(t, Counts{Allowed: 4}, last.Totals)
+	assert.Equal(t, []KeyStats{
+		{Key: "tk_hot", Type: domain.TokenLimiter, Counts: Counts{Allowed: 3}},
+		{Key: "10.0.0.2", Type: domain.IPLimiter, Counts: Counts{Allowed: 1}},
+	}, last.TopKeys)
+
+	// Janela zerada usa a retenção inteira; top limita as chaves
+	assert.Equal(t, Counts{Allowed: 5, Denied: 1, Blocked: 1}, all.Totals)
+	assert.Equal(t, Counts{Allowed: 2, Denied: 1, Blocked: 1}, all.ByType[domain.IPLimiter])
+	assert.Equal(t, Counts{Allowed: 3}, all.ByType[domain.TokenLimiter])
+	require.Len(t, all.TopKeys, 1)
+	assert.Equal(t, "tk_hot", all.TopKeys[0].Key)
+
+	// Ordenação por negadas omite as chaves sem negações
+	assert.Equal(t, []KeyStats{
+		{Key: "10.0.0.1", Type: domain.IPLimiter, Counts: Counts{Allowed: 1, Denied: 1, Blocked: 1}},
+	}, denied.TopKeys)
+}
+
+func TestCollector_ExpiresOldIntervals(t *testing.T) {
+	// Arrange
+	now := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	collector := NewCollector(Config{Resolution: 10 * time.Second, Retention: 30 * time.Second})
+	collector.now = func() time.Time { return now }
+	collector.ObserveDecision("10.0.0.1", domain.IPLimiter, true)
+
+	// Act: o slot do primeiro intervalo é reaproveitado depois de uma volta completa
+	now = now.Add(30 * time.Second)
+	collector.ObserveDecision("10.0.0.2", domain.IPLimiter, false)
+	snapshot := collector.Snapshot(time.Minute, 10, OrderRequests)
+
+	// Assert
+	assert.Equal(t, Counts{Denied: 1}, snapshot.Totals)
+	require.Len(t, snapshot.TopKeys, 1)
+	assert.Equal(t, "10.0.0.2", snapshot.TopKeys[0].Key)
+}
+
+func TestCollector_CapsKeysPerInterval(t *testing.T) {
+	// Arrange
+	collector := NewCollector(Config{MaxKeys: 2})
+
+	// Act
+	for _, key := range []string{"a", "b", "c"} {
+		collector.ObserveDecision(key, domain.TokenLimiter, true)
+	}
+	snapshot := collector.Snapshot(time.Minute, 10, OrderRequests)
+
+	// Assert: o total conta todas as decisões, o top só as chaves acompanhadas
+	assert.Equal(t, uint64(3), snapshot.Totals.Allowed)
+	assert.Len(t, snapshot.TopKeys, 2)
+	assert.True(t, snapshot.Truncated)
+}
+
+func TestParseOrder(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected Order
+		valid    bool
+	}{
+		{"", OrderRequests, true},
+		{"requests", OrderRequests, true},
+		{"denied", OrderDenied, true},
+		{"blocked", OrderBlocked, true},
+		{"latency", "", false},
+	}
+
+	for _, tt := range tests {
+		order, valid := ParseOrder(tt.value)
+		assert.Equal(t, tt.expected, order, tt.value)
+		assert.Equal(t, tt.valid, valid, tt.value)
+	}
+}
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Algorithm é o algoritmo de contagem aplicado pelo Increment
+type Algorithm string
+
+const (
+	// AlgorithmFixedWindow conta por janela a partir da primeira requisição (padrão).
+	// Na virada da janela, um cliente pode fazer até 2x o limite em sequência
+	AlgorithmFixedWindow Algorithm = "fixed_window"
+	// AlgorithmSlidingLog guarda o instante de cada requisição aceita e conta
+	// apenas as dos últimos window; exato, com memória proporcional ao limite
+	AlgorithmSlidingLog Algorithm = "sliding_log"
+	// AlgorithmSlidingWindow pondera a contagem da janela anterior pela fração
+	// ainda coberta pela janela deslizante; aproximado, com dois contadores por chave
+	AlgorithmSlidingWindow Algorithm = "sliding_window"
+)
+
+// ParseAlgorithm interpreta o nome do algoritmo; vazio equivale a AlgorithmFixedWindow
+func ParseAlgorithm(value string) (Algorithm, error) {
+	switch algorithm := Algorithm(strings.ToLower(strings.TrimSpace(value))); algorithm {
+	case "":
+		return AlgorithmFixedWindow, nil
+	case AlgorithmFixedWindow, AlgorithmSlidingLog, AlgorithmSlidingWindow:
+		return algorithm, nil
+	default:
+		return "", fmt.Errorf("unsupported rate limit algorithm: %s", value)
+	}
+}
+
+// SupportsAlgorithm informa se o tipo de storage implementa o algoritmo.
+// O hybrid sincroniza contadores em lote e o etcd grava o status inteiro a cada
+// transação; ambos só suportam a janela fixa
+func SupportsAlgorithm(storageType StorageType, algorithm Algorithm) bool {
+	if algorithm == "" || algorithm == AlgorithmFixedWindow {
+		return true
+	}
+	switch StorageType(strings.ToLower(string(storageType))) {
+	case MemoryStorageType, RedisStorageType, TieredStorageType:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,238 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"rate-limiter/internal/domain"
+)
+
+const (
+	// DefaultBlockFilterRefresh é o intervalo padrão entre reconstruções do filtro
+	DefaultBlockFilterRefresh = time.Second
+	// DefaultBlockPartitions é o número padrão de partições do índice de bloqueios
+	DefaultBlockPartitions = 16
+
+	// blockFilterFalsePositiveRate é a taxa de consultas ao Redis para chaves não bloqueadas
+	blockFilterFalsePositiveRate = 0.01
+)
+
+// BlockFilterConfig configura o filtro de bloqueios do Redis storage
+type BlockFilterConfig struct {
+	ExpectedKeys    int           // Chaves bloqueadas previstas (dimensiona o filtro)
+	Partitions      int           // Partições do índice (0 = DefaultBlockPartitions)
+	RefreshInterval time.Duration // Intervalo entre reconstruções (0 = DefaultBlockFilterRefresh)
+}
+
+// BlockFilterStats resume o uso do filtro
+type BlockFilterStats struct {
+	Ready     bool   // false = sem filtro válido, toda consulta vai ao Redis
+	Keys      int    // Chaves no filtro
+	FastPath  uint64 // Consultas respondidas localmente como não bloqueadas
+	Fallbacks uint64 // Consultas verificadas no Redis (bloqueios e falsos positivos)
+}
+
+// BlockFilterStorage responde localmente o caso comum de IsBlocked ("não
+// bloqueada") com um filtro de Bloom das chaves bloqueadas, reconstruído
+// periodicamente a partir do índice particionado do Redis. Um positivo do
+// filtro, verdadeiro ou falso, é confirmado no Redis. Bloqueios aplicados por
+// outras instâncias são vistos a partir da reconstrução seguinte; até lá, o
+// contador da janela, ainda acima do limite, continua negando as requisições
+type BlockFilterStorage struct {
+	*RedisStorage
+	logger   domain.Logger
+	expected int
+	interval time.Duration
+
+	mutex      sync.RWMutex
+	filter     *bloomFilter // nil = sem filtro válido
+	keys       int
+	rebuilding bool
+	recent     []string // Bloqueios locais durante a reconstrução, somados ao novo filtro
+
+	fastPath  atomic.Uint64
+	fallbacks atomic.Uint64
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// NewBlockFilterStorage habilita o índice de bloqueios no storage, carrega o
+// filtro e inicia a reconstrução periódica. Se a primeira carga falhar, as
+// consultas vão ao Redis até a próxima reconstrução bem-sucedida
+func NewBlockFilterStorage(inner *RedisStorage, config BlockFilterConfig, logger domain.Logger) *BlockFilterStorage {
+	if config.Partitions <= 0 {
+		config.Partitions = DefaultBlockPartitions
+	}
+	if config.RefreshInterval <= 0 {
+		config.RefreshInterval = DefaultBlockFilterRefresh
+	}
+	inner.SetBlockIndex(config.Partitions)
+
+	storage := &BlockFilterStorage{
+		RedisStorage: inner,
+		logger:       logger,
+		expected:     config.ExpectedKeys,
+		interval:     config.RefreshInterval,
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := storage.Refresh(ctx); err != nil && logger != nil {
+		logger.Error("Failed to load block filter, checking blocks in Redis until the next refresh", err, nil)
+	}
+
+	go storage.run()
+
+	if logger != nil {
+		logger.Info("Block filter enabled", map[string]interface{}{
+			"expected_keys":       config.ExpectedKeys,
+			"partitions":          config.Partitions,
+			"refresh_interval_ms": config.RefreshInterval.Milliseconds(),
+		})
+	}
+
+	return storage
+}
+
+// IsBlocked responde "não bloqueada" sem acessar o Redis quando o filtro
+// garante que a chave não está bloqueada
+func (f *BlockFilterStorage) IsBlocked(ctx context.Context, key string) (bool, *time.Time, error) {
+	f.mutex.RLock()
+	absent := f.filter != nil && !f.filter.mayContain(key)
+	f.mutex.RUnlock()
+
+	if absent {
+		f.fastPath.Add(1)
+		return false, nil, nil
+	}
+	f.fallbacks.Add(1)
+	return f.RedisStorage.IsBlocked(ctx, key)
+}
+
+// Block bloqueia no Redis e marca a chave no filtro local de imediato
+func (f *BlockFilterStorage) Block(ctx context.Context, key string, duration time.Duration) error {
+	if err := f.RedisStorage.Block(ctx, key, duration); err != nil {
+		return err
+	}
+	f.remember(key)
+	return nil
+}
+
+// remember marca no filtro local uma chave bloqueada por esta instância
+func (f *BlockFilterStorage) remember(key string) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	if f.filter != nil {
+		f.filter.add(key)
+		f.keys++
+	}
+	if f.rebuilding {
+		f.recent = append(f.recent, key)
+	}
+}
+
+// Refresh reconstrói o filtro com as chaves bloqueadas de todas as partições
+// O filtro cresce para o dobro das chaves quando elas passam de ExpectedKeys
+func (f *BlockFilterStorage) Refresh(ctx context.Context) error {
+	f.mutex.Lock()
+	f.rebuilding = true
+	f.recent = nil
+	f.mutex.Unlock()
+
+	var keys []string
+	for partition := 0; partition < f.BlockPartitions(); partition++ {
+		blocked, err := f.BlockedKeys(ctx, partition)
+		if err != nil {
+			f.mutex.Lock()
+			f.filter = nil
+			f.rebuilding = false
+			f.recent = nil
+			f.mutex.Unlock()
+			return err
+		}
+		keys = append(keys, blocked...)
+	}
+
+	size := f.expected
+	if 2*len(keys) > size {
+		size = 2 * len(keys)
+	}
+	next := newBloomFilter(size, blockFilterFalsePositiveRate)
+	for _, key := range keys {
+		next.add(key)
+	}
+
+	f.mutex.Lock()
+	for _, key := range f.recent {
+		next.add(key)
+	}
+	f.filter = next
+	f.keys = len(keys) + len(f.recent)
+	f.rebuilding = false
+	f.recent = nil
+	f.mutex.Unlock()
+	return nil
+}
+
+// Stats retorna o estado e os contadores do filtro
+func (f *BlockFilterStorage) Stats() BlockFilterStats {
+	f.mutex.RLock()
+	defer f.mutex.RUnlock()
+
+	return BlockFilterStats{
+		Ready:     f.filter != nil,
+		Keys:      f.keys,
+		FastPath:  f.fastPath.Load(),
+		Fallbacks: f.fallbacks.Load(),
+	}
+}
+
+// Close encerra a reconstrução periódica e fecha o Redis storage
+func (f *BlockFilterStorage) Close() error {
+	f.stopOnce.Do(func() {
+		close(f.stop)
+		<-f.done
+	})
+	return f.RedisStorage.Close()
+}
+
+// run reconstrói o filtro a cada intervalo até o Close
+func (f *BlockFilterStorage) run() {
+	defer close(f.done)
+
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-f.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), f.interval)
+			err := f.Refresh(ctx)
+			cancel()
+			if f.logger == nil {
+				continue
+			}
+			if err != nil {
+				f.logger.Warn("Block filter refresh failed, checking blocks in Redis until the next refresh", map[string]interface{}{
+					"error": err.Error(),
+				})
+			} else if domain.DebugEnabled(f.logger) {
+				stats := f.Stats()
+				f.logger.Debug("Block filter refreshed", map[string]interface{}{
+					"keys":      stats.Keys,
+					"fast_path": stats.FastPath,
+					"fallbacks": stats.Fallbacks,
+				})
+			}
+		}
+	}
+}
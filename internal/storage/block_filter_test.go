@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/logger"
+	"rate-limiter/internal/storage/storagetest"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newBlockFilterStorage cria o filtro sobre um Redis storage próprio, no servidor informado
+func newBlockFilterStorage(t *testing.T, server *miniredis.Miniredis) *BlockFilterStorage {
+	t.Helper()
+
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	inner := NewRedisStorageWithClient(client, logger.NewNopLogger())
+	storage := NewBlockFilterStorage(inner, BlockFilterConfig{ExpectedKeys: 1000, Partitions: 4, RefreshInterval: time.Hour}, logger.NewNopLogger())
+	t.Cleanup(func() { storage.Close() })
+	return storage
+}
+
+func TestBlockFilterStorage_Conformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) domain.RateLimiterStorage {
+		return newBlockFilterStorage(t, miniredis.RunT(t))
+	})
+}
+
+// TestBlockFilterStorage_FastPath testa a resposta local para chaves não bloqueadas
+func TestBlockFilterStorage_FastPath(t *testing.T) {
+	// Arrange
+	storage := newBlockFilterStorage(t, miniredis.RunT(t))
+	ctx := context.Background()
+	require.NoError(t, storage.Block(ctx, "rate_limit:ip:10.0.0.1", time.Minute))
+
+	// Act
+	blocked, blockedUntil, err := storage.IsBlocked(ctx, "rate_limit:ip:10.0.0.1")
+	require.NoError(t, err)
+	free, _, err := storage.IsBlocked(ctx, "rate_limit:ip:10.0.0.2")
+	require.NoError(t, err)
+
+	// Assert
+	assert.True(t, blocked)
+	assert.NotNil(t, blockedUntil)
+	assert.False(t, free)
+	stats := storage.Stats()
+	assert.True(t, stats.Ready)
+	assert.Equal(t, 1, stats.Keys)
+	assert.Equal(t, uint64(1), stats.FastPath)
+	assert.Equal(t, uint64(1), stats.Fallbacks)
+}
+
+// TestBlockFilterStorage_Refresh testa bloqueios de outra instância e resets pelo índice
+func TestBlockFilterStorage_Refresh(t *testing.T) {
+	// Arrange
+	server := miniredis.RunT(t)
+	instanceA := newBlockFilterStorage(t, server)
+	instanceB := newBlockFilterStorage(t, server)
+	ctx := context.Background()
+	key := "rate_limit:token:abc123"
+	require.NoError(t, instanceA.Block(ctx, key, time.Minute))
+
+	// Act
+	beforeRefresh, _, err := instanceB.IsBlocked(ctx, key)
+	require.NoError(t, err)
+	require.NoError(t, instanceB.Refresh(ctx))
+	afterRefresh, _, err := instanceB.IsBlocked(ctx, key)
+	require.NoError(t, err)
+
+	require.NoError(t, instanceB.Reset(ctx, key))
+	require.NoError(t, instanceA.Refresh(ctx))
+
+	// Assert
+	assert.False(t, beforeRefresh, "blocks from other instances are seen after the next refresh")
+	assert.True(t, afterRefresh)
+	assert.Equal(t, 0, instanceA.Stats().Keys)
+}
+
+// TestBlockFilterStorage_ExpiredBlocksLeaveIndex testa a limpeza dos bloqueios vencidos na listagem
+func TestBlockFilterStorage_ExpiredBlocksLeaveIndex(t *testing.T) {
+	// Arrange
+	storage := newBlockFilterStorage(t, miniredis.RunT(t))
+	ctx := context.Background()
+	require.NoError(t, storage.Block(ctx, "rate_limit:ip:10.0.0.1", time.Millisecond))
+	require.NoError(t, storage.Block(ctx, "rate_limit:ip:10.0.0.2", time.Minute))
+	time.Sleep(5 * time.Millisecond)
+
+	// Act
+	require.NoError(t, storage.Refresh(ctx))
+
+	// Assert
+	assert.Equal(t, 1, storage.Stats().Keys)
+}
+
+// TestBlockFilterStorage_RefreshFailure testa a volta às consultas no Redis sem filtro válido
+func TestBlockFilterStorage_RefreshFailure(t *testing.T) {
+	// Arrange
+	server := miniredis.RunT(t)
+	storage := newBlockFilterStorage(t, server)
+	ctx := context.Background()
+	server.Close()
+
+	// Act
+	refreshErr := storage.Refresh(ctx)
+	_, _, err := storage.IsBlocked(ctx, "rate_limit:ip:10.0.0.1")
+
+	// Assert
+	assert.Error(t, refreshErr)
+	assert.Error(t, err, "without a filter the check must reach Redis")
+	assert.False(t, storage.Stats().Ready)
+}
+
+func TestBloomFilter_FalsePositiveRate(t *testing.T) {
+	// Arrange
+	filter := newBloomFilter(10000, 0.01)
+	for i := 0; i < 10000; i++ {
+		filter.add(fmt.Sprintf("rate_limit:ip:10.0.%d.%d", i/256, i%256))
+	}
+
+	// Act
+	falsePositives := 0
+	for i := 0; i < 10000; i++ {
+		if filter.mayContain(fmt.Sprintf("rate_limit:token:%d", i)) {
+			falsePositives++
+		}
+	}
+
+	// Assert
+	for i := 0; i < 10000; i++ {
+		require.True(t, filter.mayContain(fmt.Sprintf("rate_limit:ip:10.0.%d.%d", i/256, i%256)))
+	}
+	assert.Less(t, falsePositives, 200, "false positive rate must stay close to 1%")
+}
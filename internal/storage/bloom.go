@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"hash/fnv"
+	"math"
+)
+
+// bloomFilter é um filtro de Bloom particionado: cada uma das k funções de hash
+// indexa a própria fatia de bits, o que evita que duas funções marquem o mesmo
+// bit para uma chave. Não é seguro para uso concorrente
+type bloomFilter struct {
+	bits          []uint64
+	partitionBits uint64 // bits por fatia
+	hashes        uint64 // fatias (funções de hash)
+}
+
+// newBloomFilter dimensiona o filtro para expected chaves com a taxa de falsos
+// positivos falsePositiveRate
+func newBloomFilter(expected int, falsePositiveRate float64) *bloomFilter {
+	if expected < 1 {
+		expected = 1
+	}
+
+	// m = -n·ln(p)/ln(2)², k = m/n·ln(2)
+	totalBits := math.Ceil(-float64(expected) * math.Log(falsePositiveRate) / (math.Ln2 * math.Ln2))
+	hashes := uint64(math.Max(1, math.Round(totalBits/float64(expected)*math.Ln2)))
+	partitionBits := uint64(math.Ceil(totalBits / float64(hashes)))
+
+	return &bloomFilter{
+		bits:          make([]uint64, (partitionBits*hashes+63)/64),
+		partitionBits: partitionBits,
+		hashes:        hashes,
+	}
+}
+
+// add marca a chave no filtro
+func (b *bloomFilter) add(key string) {
+	h1, h2 := bloomHashes(key)
+	for i := uint64(0); i < b.hashes; i++ {
+		bit := i*b.partitionBits + (h1+i*h2)%b.partitionBits
+		b.bits[bit/64] |= 1 << (bit % 64)
+	}
+}
+
+// mayContain retorna false quando a chave certamente não foi adicionada
+func (b *bloomFilter) mayContain(key string) bool {
+	h1, h2 := bloomHashes(key)
+	for i := uint64(0); i < b.hashes; i++ {
+		bit := i*b.partitionBits + (h1+i*h2)%b.partitionBits
+		if b.bits[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// bloomHashes deriva as k funções de hash de um único FNV-1a de 64 bits
+// (Kirsch-Mitzenmacher: g_i = h1 + i·h2)
+func bloomHashes(key string) (uint64, uint64) {
+	hash := fnv.New64a()
+	hash.Write([]byte(key))
+	sum := hash.Sum64()
+	return sum & math.MaxUint32, sum>>32 | 1
+}
@@ -0,0 +1,269 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"rate-limiter/internal/domain"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// fixedWindow informa se o algoritmo é a janela fixa, o único verificado atomicamente
+func fixedWindow(algorithm Algorithm) bool {
+	return algorithm == "" || algorithm == AlgorithmFixedWindow
+}
+
+// CheckAndIncrement implementa domain.AtomicLimitChecker sob o write lock da partição
+func (m *MemoryStorage) CheckAndIncrement(ctx context.Context, key string, limit int, window, blockDuration time.Duration) (*domain.LimitCheck, error) {
+	if !fixedWindow(m.algorithm) {
+		return nil, domain.ErrCheckUnsupported
+	}
+	ctx, span := startSpan(ctx, MemoryStorageType, "CHECK_AND_INCREMENT", key)
+	defer span.End()
+	m.touch(key)
+
+	start := time.Now()
+
+	shard := m.lock(ctx, key)
+	defer m.unlock(shard)
+
+	now := m.now().UnixNano()
+	record, exists := shard.data.get(key)
+
+	// Bloqueio vigente: nega sem contar, com as mesmas regras do IsBlocked
+	if blockedUntil, ok := shard.blocks[key]; ok && now < blockedUntil {
+		until := fromUnixNano(blockedUntil)
+		m.logStorageOperation(ctx, "CHECK_AND_INCREMENT", key, true, time.Since(start).Seconds()*1000, nil)
+		return &domain.LimitCheck{Blocked: true, BlockedUntil: &until}, nil
+	}
+	if exists && record.blocked && (record.blockedUntil == 0 || now < record.blockedUntil) {
+		check := &domain.LimitCheck{Blocked: true, WindowStart: fromUnixNano(record.lastReset), BlockedUntil: record.status(key).BlockedUntil}
+		m.logStorageOperation(ctx, "CHECK_AND_INCREMENT", key, true, time.Since(start).Seconds()*1000, nil)
+		return check, nil
+	}
+
+	if !exists {
+		record = m.insert(shard, key, memoryRecord{
+			limit:     clampInt32(limit),
+			window:    clampInt32(int(window.Seconds())),
+			lastReset: now,
+		})
+	}
+	if time.Duration(now-record.lastReset) >= window {
+		m.recordWindow(shard, key, record, window)
+		record.count = 0
+		record.lastReset = now
+		record.blocked = false
+		record.blockedUntil = 0
+		delete(shard.blocks, key)
+	}
+
+	count := record.increment()
+	check := &domain.LimitCheck{Count: int(count), WindowStart: fromUnixNano(record.lastReset)}
+	if count > clampInt32(limit) {
+		blockedUntil := now + int64(blockDuration)
+		shard.blocks[key] = blockedUntil
+		record.blocked = true
+		record.blockedUntil = blockedUntil
+		until := fromUnixNano(blockedUntil)
+		check.BlockedUntil = &until
+	}
+
+	m.logStorageOperation(ctx, "CHECK_AND_INCREMENT", key, true, time.Since(start).Seconds()*1000, nil)
+	return check, nil
+}
+
+// checkLimitSource verifica o bloqueio, incrementa a janela fixa e bloqueia a
+// chave, no mesmo formato JSON do incrementSource. ARGV[1] é o limite, ARGV[2] a
+// janela (ms), ARGV[3] o instante atual e ARGV[4] a duração do bloqueio (ms).
+// KEYS[2] e ARGV[5] (opcionais) mantêm o histórico de janelas como no incrementSource.
+// Retorna {já bloqueada (0/1), contagem, início da janela, fim do bloqueio (0 = nenhum)}
+const checkLimitSource = `
+	local key = KEYS[1]
+	local limit = tonumber(ARGV[1])
+	local window = tonumber(ARGV[2])
+	local now = tonumber(ARGV[3])
+	local blockDuration = tonumber(ARGV[4])
+
+	local current = redis.call('GET', key)
+	local data
+	if current then
+		data = cjson.decode(current)
+	else
+		data = {
+			key = key,
+			type = '',
+			count = 0,
+			limit = limit,
+			window = math.floor(window / 1000),
+			lastReset = now,
+			isBlocked = false
+		}
+	end
+
+	-- Bloqueio vigente: nega sem contar. Sem blockedUntil, vale até a janela expirar
+	local blockedUntil = tonumber(data.blockedUntil)
+	if data.isBlocked and (blockedUntil == nil or blockedUntil > now) then
+		return {1, 0, data.lastReset, blockedUntil or 0}
+	end
+
+	local timeSinceReset = now - data.lastReset
+	if timeSinceReset >= window then
+		data.lastReset = now
+		data.count = 0
+		data.isBlocked = false
+		timeSinceReset = 0
+	end
+	data.count = data.count + 1
+
+	local ttl = window - timeSinceReset
+	local blocked = 0
+	if data.count > limit then
+		-- Bloqueia como o Block: o registro sobrevive ao bloqueio por mais um minuto
+		blocked = now + blockDuration
+		data.isBlocked = true
+		data.blockedUntil = blocked
+		if blockDuration + 60000 > ttl then
+			ttl = blockDuration + 60000
+		end
+	end
+	redis.call('SET', key, cjson.encode(data), 'PX', math.ceil(ttl))
+
+	local historySize = tonumber(ARGV[5]) or 0
+	if historySize > 0 and KEYS[2] then
+		local prefix = string.format('%d:', data.lastReset)
+		local entry = prefix .. data.count .. ':' .. limit
+		local head = redis.call('LINDEX', KEYS[2], 0)
+		if head and string.sub(head, 1, #prefix) == prefix then
+			redis.call('LSET', KEYS[2], 0, entry)
+		else
+			redis.call('LPUSH', KEYS[2], entry)
+			redis.call('LTRIM', KEYS[2], 0, historySize - 1)
+		end
+		redis.call('PEXPIRE', KEYS[2], historySize * window)
+	end
+
+	return {0, data.count, data.lastReset, blocked}
+`
+
+var checkLimitScript = redis.NewScript(checkLimitSource)
+
+// CheckAndIncrement implementa domain.AtomicLimitChecker em um único EVALSHA.
+// Só o registro no índice de bloqueios, quando habilitado, é uma chamada à parte
+func (r *RedisStorage) CheckAndIncrement(ctx context.Context, key string, limit int, window, blockDuration time.Duration) (*domain.LimitCheck, error) {
+	if !fixedWindow(r.algorithm) {
+		return nil, domain.ErrCheckUnsupported
+	}
+	ctx, span := startSpan(ctx, RedisStorageType, "CHECK_AND_INCREMENT", key)
+	defer span.End()
+
+	start := time.Now()
+
+	if r.cipher != nil {
+		record, blocked, err := r.checkLimitSealed(ctx, key, limit, window, blockDuration)
+		if err != nil {
+			r.logStorageOperation(ctx, "CHECK_AND_INCREMENT", key, false, time.Since(start).Seconds()*1000, err)
+			return nil, fmt.Errorf("failed to check limit for key %s: %w", key, err)
+		}
+		check := &domain.LimitCheck{Blocked: blocked, WindowStart: time.UnixMilli(record.LastReset)}
+		if !blocked {
+			check.Count = record.Count
+		}
+		if record.IsBlocked && record.BlockedUntil != nil {
+			blockedUntil := time.UnixMilli(*record.BlockedUntil)
+			check.BlockedUntil = &blockedUntil
+			if !blocked {
+				r.indexBlock(ctx, key, blockedUntil)
+			}
+		}
+		r.logStorageOperation(ctx, "CHECK_AND_INCREMENT", key, true, time.Since(start).Seconds()*1000, nil)
+		return check, nil
+	}
+
+	keys := []string{key}
+	args := []interface{}{limit, window.Milliseconds(), time.Now().UnixMilli(), blockDuration.Milliseconds()}
+	if r.historySize > 0 {
+		keys = append(keys, key+windowHistorySuffix)
+		args = append(args, r.historySize)
+	}
+
+	value, err := r.eval(ctx, "check_limit", checkLimitScript, keys, args...)
+	if err != nil {
+		r.logStorageOperation(ctx, "CHECK_AND_INCREMENT", key, false, time.Since(start).Seconds()*1000, err)
+		return nil, fmt.Errorf("failed to check limit for key %s: %w", key, err)
+	}
+
+	values, ok := value.([]interface{})
+	if !ok || len(values) != 4 {
+		err := fmt.Errorf("invalid check result for key %s", key)
+		r.logStorageOperation(ctx, "CHECK_AND_INCREMENT", key, false, time.Since(start).Seconds()*1000, err)
+		return nil, err
+	}
+
+	numbers := make([]int64, len(values))
+	for i, item := range values {
+		if numbers[i], err = strconv.ParseInt(fmt.Sprint(item), 10, 64); err != nil {
+			r.logStorageOperation(ctx, "CHECK_AND_INCREMENT", key, false, time.Since(start).Seconds()*1000, err)
+			return nil, fmt.Errorf("invalid check result for key %s: %w", key, err)
+		}
+	}
+
+	check := &domain.LimitCheck{
+		Blocked:     numbers[0] == 1,
+		Count:       int(numbers[1]),
+		WindowStart: time.UnixMilli(numbers[2]),
+	}
+	if numbers[3] > 0 {
+		blockedUntil := time.UnixMilli(numbers[3])
+		check.BlockedUntil = &blockedUntil
+		if !check.Blocked {
+			r.indexBlock(ctx, key, blockedUntil)
+		}
+	}
+
+	r.logStorageOperation(ctx, "CHECK_AND_INCREMENT", key, true, time.Since(start).Seconds()*1000, nil)
+	return check, nil
+}
+
+// CheckAndIncrement responde pelo cache enquanto ele guarda um bloqueio válido;
+// senão verifica no Redis e guarda o bloqueio vigente ou recém-aplicado
+func (t *TieredStorage) CheckAndIncrement(ctx context.Context, key string, limit int, window, blockDuration time.Duration) (*domain.LimitCheck, error) {
+	if blocked, blockedUntil, ok := t.cached(ctx, key); ok && blocked {
+		t.hits.Add(1)
+		return &domain.LimitCheck{Blocked: true, BlockedUntil: blockedUntil}, nil
+	}
+	t.misses.Add(1)
+
+	check, err := t.RedisStorage.CheckAndIncrement(ctx, key, limit, window, blockDuration)
+	if err != nil {
+		return nil, err
+	}
+	if check.BlockedUntil != nil {
+		t.store(ctx, key, true, check.BlockedUntil)
+	}
+	return check, nil
+}
+
+// CheckAndIncrement verifica no Redis e marca no filtro local a chave recém-bloqueada
+func (f *BlockFilterStorage) CheckAndIncrement(ctx context.Context, key string, limit int, window, blockDuration time.Duration) (*domain.LimitCheck, error) {
+	check, err := f.RedisStorage.CheckAndIncrement(ctx, key, limit, window, blockDuration)
+	if err != nil {
+		return nil, err
+	}
+	if !check.Blocked && check.BlockedUntil != nil {
+		f.remember(key)
+	}
+	return check, nil
+}
+
+// CheckAndIncrement implementa domain.AtomicLimitChecker quando o storage interno o suporta
+func (p *PrefixedStorage) CheckAndIncrement(ctx context.Context, key string, limit int, window, blockDuration time.Duration) (*domain.LimitCheck, error) {
+	checker, ok := p.inner.(domain.AtomicLimitChecker)
+	if !ok {
+		return nil, domain.ErrCheckUnsupported
+	}
+	return checker.CheckAndIncrement(ctx, p.prefix+key, limit, window, blockDuration)
+}
@@ -0,0 +1,187 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/logger"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// atomicChecker é um storage que também verifica o limite em uma única operação
+type atomicChecker interface {
+	domain.RateLimiterStorage
+	domain.AtomicLimitChecker
+}
+
+// checkStorages retorna os backends que verificam o limite atomicamente
+func checkStorages(t *testing.T) map[string]atomicChecker {
+	t.Helper()
+
+	redisStorage := NewRedisStorageWithClient(redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()}), logger.NewNopLogger())
+	tieredRemote := NewRedisStorageWithClient(redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()}), logger.NewNopLogger())
+	tiered := NewTieredStorage(NewMemoryStorage(nil), tieredRemote, TieredConfig{}, nil)
+	memory := NewMemoryStorage(nil)
+	sealed, _ := newSealedStorage(t)
+	t.Cleanup(func() {
+		redisStorage.Close()
+		tiered.Close()
+		memory.Close()
+	})
+
+	return map[string]atomicChecker{
+		"memory":       memory,
+		"redis":        redisStorage,
+		"tiered":       tiered,
+		"redis_sealed": sealed,
+	}
+}
+
+// TestCheckAndIncrement_BlocksOverLimit testa que a requisição acima do limite
+// bloqueia a chave e que as seguintes são negadas sem contar
+func TestCheckAndIncrement_BlocksOverLimit(t *testing.T) {
+	for name, storage := range checkStorages(t) {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			key := "rate_limit:ip:10.0.0.1"
+			for i := 1; i <= 2; i++ {
+				check, err := storage.CheckAndIncrement(ctx, key, 2, time.Minute, time.Minute)
+				require.NoError(t, err)
+				require.Equal(t, i, check.Count)
+				require.Nil(t, check.BlockedUntil)
+			}
+
+			// Act
+			exceeded, err := storage.CheckAndIncrement(ctx, key, 2, time.Minute, time.Minute)
+			require.NoError(t, err)
+			blocked, err := storage.CheckAndIncrement(ctx, key, 2, time.Minute, time.Minute)
+			require.NoError(t, err)
+
+			// Assert
+			assert.False(t, exceeded.Blocked)
+			assert.Equal(t, 3, exceeded.Count)
+			require.NotNil(t, exceeded.BlockedUntil)
+			assert.WithinDuration(t, time.Now().Add(time.Minute), *exceeded.BlockedUntil, 2*time.Second)
+
+			assert.True(t, blocked.Blocked)
+			require.NotNil(t, blocked.BlockedUntil)
+			assert.WithinDuration(t, *exceeded.BlockedUntil, *blocked.BlockedUntil, time.Millisecond)
+
+			isBlocked, _, err := storage.IsBlocked(ctx, key)
+			require.NoError(t, err)
+			assert.True(t, isBlocked)
+			status, err := storage.Get(ctx, key)
+			require.NoError(t, err)
+			assert.Equal(t, 3, status.Count, "blocked requests must not count")
+		})
+	}
+}
+
+// TestCheckAndIncrement_BlockExpires testa que, vencidos o bloqueio e a janela,
+// a chave volta a contar do zero
+func TestCheckAndIncrement_BlockExpires(t *testing.T) {
+	for name, storage := range checkStorages(t) {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			key := "rate_limit:ip:10.0.0.2"
+			for i := 0; i < 2; i++ {
+				_, err := storage.CheckAndIncrement(ctx, key, 1, 50*time.Millisecond, 50*time.Millisecond)
+				require.NoError(t, err)
+			}
+			time.Sleep(60 * time.Millisecond)
+
+			// Act
+			check, err := storage.CheckAndIncrement(ctx, key, 1, 50*time.Millisecond, 50*time.Millisecond)
+
+			// Assert
+			require.NoError(t, err)
+			assert.False(t, check.Blocked)
+			assert.Equal(t, 1, check.Count)
+			assert.Nil(t, check.BlockedUntil)
+		})
+	}
+}
+
+// TestCheckAndIncrement_Concurrent testa que requisições simultâneas não passam
+// do limite e que só uma delas aplica o bloqueio
+func TestCheckAndIncrement_Concurrent(t *testing.T) {
+	for name, storage := range checkStorages(t) {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			var mutex sync.Mutex
+			var allowed, exceeded, blocked int
+
+			// Act
+			var wg sync.WaitGroup
+			for i := 0; i < 40; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					check, err := storage.CheckAndIncrement(ctx, "rate_limit:ip:10.0.0.3", 10, time.Minute, time.Minute)
+					assert.NoError(t, err)
+					if err != nil {
+						return
+					}
+
+					mutex.Lock()
+					defer mutex.Unlock()
+					switch {
+					case check.Blocked:
+						blocked++
+					case check.Count > 10:
+						exceeded++
+					default:
+						allowed++
+					}
+				}()
+			}
+			wg.Wait()
+
+			// Assert
+			assert.Equal(t, 10, allowed)
+			assert.Equal(t, 1, exceeded)
+			assert.Equal(t, 29, blocked)
+		})
+	}
+}
+
+func TestCheckAndIncrement_SlidingUnsupported(t *testing.T) {
+	// Arrange
+	memory := NewMemoryStorage(nil)
+	defer memory.Close()
+	memory.algorithm = AlgorithmSlidingWindow
+
+	// Act
+	_, err := memory.CheckAndIncrement(context.Background(), "rate_limit:ip:10.0.0.4", 10, time.Minute, time.Minute)
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrCheckUnsupported)
+}
+
+func TestPrefixedStorage_CheckAndIncrement(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	memory := NewMemoryStorage(nil)
+	defer memory.Close()
+	prefixed := NewPrefixedStorage(memory, "tenant-a:")
+
+	// Act
+	_, err := prefixed.CheckAndIncrement(ctx, "rate_limit:ip:10.0.0.5", 10, time.Minute, time.Minute)
+	require.NoError(t, err)
+	status, getErr := memory.Get(ctx, "tenant-a:rate_limit:ip:10.0.0.5")
+
+	// Assert
+	require.NoError(t, getErr)
+	require.NotNil(t, status)
+	assert.Equal(t, 1, status.Count)
+}
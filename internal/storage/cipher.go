@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrInvalidCiphertext indica um valor cifrado corrompido ou cifrado com outra chave
+var ErrInvalidCiphertext = errors.New("invalid ciphertext")
+
+// Cipher cifra dados em repouso com AES-GCM. As chaves de cifra e de derivação
+// de nonce são derivadas da chave mestra, que nunca é usada diretamente
+type Cipher struct {
+	aead     cipher.AEAD
+	nonceKey []byte
+}
+
+// NewCipher cria o cifrador a partir de uma chave mestra de 16, 24 ou 32 bytes
+// (AES-128, AES-192 ou AES-256)
+func NewCipher(key []byte) (*Cipher, error) {
+	switch len(key) {
+	case 16, 24, 32:
+	default:
+		return nil, fmt.Errorf("encryption key must have 16, 24 or 32 bytes, got %d", len(key))
+	}
+
+	block, err := aes.NewCipher(deriveKey(key, "rate-limiter/aes-gcm")[:len(key)])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AES cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AES-GCM: %w", err)
+	}
+
+	return &Cipher{
+		aead:     aead,
+		nonceKey: deriveKey(key, "rate-limiter/nonce"),
+	}, nil
+}
+
+// Seal cifra plaintext com nonce aleatório; o nonce precede o texto cifrado
+func (c *Cipher) Seal(plaintext []byte) []byte {
+	nonce := make([]byte, c.aead.NonceSize(), c.aead.NonceSize()+len(plaintext)+c.aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		panic(fmt.Sprintf("crypto/rand failed: %v", err))
+	}
+	return c.aead.Seal(nonce, nonce, plaintext, nil)
+}
+
+// SealDeterministic cifra plaintext com nonce derivado do próprio conteúdo (HMAC):
+// o mesmo texto produz sempre a mesma saída. Usado nos identificadores das
+// chaves de storage, que precisam ser recalculados a cada requisição
+func (c *Cipher) SealDeterministic(plaintext []byte) []byte {
+	mac := hmac.New(sha256.New, c.nonceKey)
+	mac.Write(plaintext)
+	nonce := mac.Sum(nil)[:c.aead.NonceSize()]
+
+	sealed := make([]byte, len(nonce), len(nonce)+len(plaintext)+c.aead.Overhead())
+	copy(sealed, nonce)
+	return c.aead.Seal(sealed, nonce, plaintext, nil)
+}
+
+// Open decifra a saída de Seal ou SealDeterministic
+func (c *Cipher) Open(sealed []byte) ([]byte, error) {
+	if len(sealed) < c.aead.NonceSize()+c.aead.Overhead() {
+		return nil, ErrInvalidCiphertext
+	}
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	return plaintext, nil
+}
+
+// SealIdentifier cifra um identificador (IP ou token) para uso em nomes de chave
+func (c *Cipher) SealIdentifier(identifier string) string {
+	return base64.RawURLEncoding.EncodeToString(c.SealDeterministic([]byte(identifier)))
+}
+
+// OpenIdentifier recupera o identificador cifrado por SealIdentifier
+func (c *Cipher) OpenIdentifier(sealed string) (string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(sealed)
+	if err != nil {
+		return "", ErrInvalidCiphertext
+	}
+	identifier, err := c.Open(data)
+	if err != nil {
+		return "", err
+	}
+	return string(identifier), nil
+}
+
+// deriveKey deriva uma subchave da chave mestra para um propósito
+func deriveKey(master []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, master)
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+// LoadEncryptionKey lê a chave mestra em base64 do valor informado ou, se vazio,
+// do arquivo (ex: segredo montado por um KMS ou secret manager)
+func LoadEncryptionKey(value, file string) ([]byte, error) {
+	if value == "" && file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read encryption key file: %w", err)
+		}
+		value = string(data)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		return nil, fmt.Errorf("encryption key must be base64 encoded: %w", err)
+	}
+	return key, nil
+}
@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCipher_SealOpen(t *testing.T) {
+	// Arrange
+	cipher, err := NewCipher([]byte("0123456789abcdef"))
+	require.NoError(t, err)
+	plaintext := []byte(`{"key":"rate_limit:ip:10.0.0.1","count":3}`)
+
+	// Act
+	first := cipher.Seal(plaintext)
+	second := cipher.Seal(plaintext)
+	opened, err := cipher.Open(first)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, opened)
+	assert.NotEqual(t, first, second) // nonce aleatório
+	assert.NotContains(t, string(first), "10.0.0.1")
+}
+
+func TestCipher_SealDeterministic(t *testing.T) {
+	// Arrange
+	cipher, err := NewCipher([]byte("0123456789abcdef0123456789abcdef"))
+	require.NoError(t, err)
+
+	// Act
+	first := cipher.SealDeterministic([]byte("premium_token"))
+	second := cipher.SealDeterministic([]byte("premium_token"))
+	other := cipher.SealDeterministic([]byte("basic_token"))
+	opened, err := cipher.Open(first)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.NotEqual(t, first, other)
+	assert.Equal(t, "premium_token", string(opened))
+}
+
+func TestCipher_OpenRejectsWrongKeyAndTampering(t *testing.T) {
+	// Arrange
+	cipher, err := NewCipher([]byte("0123456789abcdef"))
+	require.NoError(t, err)
+	other, err := NewCipher([]byte("fedcba9876543210"))
+	require.NoError(t, err)
+	sealed := cipher.Seal([]byte("10.0.0.1"))
+	tampered := append([]byte(nil), sealed...)
+	tampered[len(tampered)-1] ^= 0xff
+
+	// Act
+	_, wrongKeyErr := other.Open(sealed)
+	_, tamperedErr := cipher.Open(tampered)
+	_, shortErr := cipher.Open([]byte("short"))
+	_, encodingErr := cipher.OpenIdentifier("not base64!")
+
+	// Assert
+	assert.ErrorIs(t, wrongKeyErr, ErrInvalidCiphertext)
+	assert.ErrorIs(t, tamperedErr, ErrInvalidCiphertext)
+	assert.ErrorIs(t, shortErr, ErrInvalidCiphertext)
+	assert.ErrorIs(t, encodingErr, ErrInvalidCiphertext)
+}
+
+func TestNewCipher_InvalidKeySize(t *testing.T) {
+	_, err := NewCipher([]byte("too-short"))
+
+	assert.EqualError(t, err, "encryption key must have 16, 24 or 32 bytes, got 9")
+}
+
+func TestLoadEncryptionKey(t *testing.T) {
+	// Arrange
+	key := []byte("0123456789abcdef0123456789abcdef")
+	encoded := base64.StdEncoding.EncodeToString(key)
+	file := filepath.Join(t.TempDir(), "storage-key")
+	require.NoError(t, os.WriteFile(file, []byte(encoded+"\n"), 0o600))
+
+	// Act
+	fromValue, valueErr := LoadEncryptionKey(encoded, "")
+	fromFile, fileErr := LoadEncryptionKey("", file)
+	_, invalidErr := LoadEncryptionKey("not base64!", "")
+	_, missingErr := LoadEncryptionKey("", filepath.Join(t.TempDir(), "missing"))
+
+	// Assert
+	require.NoError(t, valueErr)
+	require.NoError(t, fileErr)
+	assert.Equal(t, key, fromValue)
+	assert.Equal(t, key, fromFile)
+	assert.Error(t, invalidErr)
+	assert.Error(t, missingErr)
+}
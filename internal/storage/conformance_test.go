@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/logger"
+	"rate-limiter/internal/storage/storagetest"
+)
+
+func TestMemoryStorage_Conformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) domain.RateLimiterStorage {
+		storage := NewMemoryStorage(logger.NewNopLogger())
+		t.Cleanup(func() { storage.Close() })
+		return storage
+	})
+}
+
+func TestRedisStorage_Conformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) domain.RateLimiterStorage {
+		storage, _ := newMiniredisStorage(t)
+		return storage
+	})
+}
+
+func TestPrefixedStorage_Conformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) domain.RateLimiterStorage {
+		inner := NewMemoryStorage(logger.NewNopLogger())
+		t.Cleanup(func() { inner.Close() })
+		return NewPrefixedStorage(inner, "tenant_a:")
+	})
+}
+
+func TestHybridStorage_Conformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) domain.RateLimiterStorage {
+		remote, _ := newMiniredisStorage(t)
+		storage := NewHybridStorage(NewMemoryStorage(logger.NewNopLogger()), remote, HybridConfig{SyncInterval: 50 * time.Millisecond}, logger.NewNopLogger())
+		t.Cleanup(func() { storage.Close() })
+		return storage
+	})
+}
@@ -0,0 +1,874 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"rate-limiter/internal/domain"
+)
+
+// EtcdStorageType é o storage sobre um cluster etcd (ex: o do próprio Kubernetes)
+const EtcdStorageType StorageType = "etcd"
+
+const (
+	// DefaultEtcdPrefix isola as chaves do rate limiter das demais chaves do cluster
+	DefaultEtcdPrefix = "/rate-limiter/"
+	// defaultEtcdTimeout limita cada requisição ao gateway do etcd
+	defaultEtcdTimeout = 3 * time.Second
+	// etcdMaxTxnAttempts limita as releituras de uma chave disputada por outras instâncias
+	etcdMaxTxnAttempts = 64
+)
+
+// EtcdConfig contém configurações específicas do etcd
+type EtcdConfig struct {
+	Endpoints []string // URLs dos membros (ex: http://etcd-0.etcd:2379)
+	Username  string   // Vazio = sem autenticação
+	Password  string
+	Prefix    string        // Prefixo das chaves (padrão: DefaultEtcdPrefix)
+	Timeout   time.Duration // Por requisição (padrão: 3s)
+}
+
+// EtcdStorage implementa domain.RateLimiterStorage sobre o gateway JSON da API v3
+// do etcd (/v3/kv/*, /v3/lease/*), sem depender do cliente gRPC.
+//
+// Cada operação lê a chave e grava o novo status em uma transação condicionada à
+// revisão lida; se outra instância alterou a chave no meio, a leitura é refeita.
+// A expiração é controlada pelo instante gravado no status (leases do etcd têm
+// resolução de segundos) e as chaves ficam presas a leases compartilhados, que
+// as removem do cluster depois de expiradas
+type EtcdStorage struct {
+	endpoints []string
+	prefix    string
+	username  string
+	password  string
+	client    *http.Client
+	logger    domain.Logger
+	now       func() time.Time
+
+	endpoint atomic.Int64 // índice do endpoint em uso; avança em falhas de conexão
+
+	mutex  sync.Mutex
+	token  string              // token de autenticação (/v3/auth/authenticate)
+	leases map[int64]etcdLease // leases compartilhados por faixa de TTL, em segundos
+}
+
+// etcdLease é um lease concedido e o instante em que expira
+type etcdLease struct {
+	id        int64
+	expiresAt time.Time
+}
+
+// NewEtcdStorage cria o storage e testa a conexão com o cluster
+func NewEtcdStorage(config EtcdConfig, logger domain.Logger) (*EtcdStorage, error) {
+	if len(config.Endpoints) == 0 {
+		return nil, fmt.Errorf("at least one etcd endpoint is required")
+	}
+	if config.Prefix == "" {
+		config.Prefix = DefaultEtcdPrefix
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaultEtcdTimeout
+	}
+
+	endpoints := make([]string, len(config.Endpoints))
+	for i, endpoint := range config.Endpoints {
+		endpoints[i] = strings.TrimRight(endpoint, "/")
+	}
+
+	storage := &EtcdStorage{
+		endpoints: endpoints,
+		prefix:    config.Prefix,
+		username:  config.Username,
+		password:  config.Password,
+		client:    &http.Client{Timeout: config.Timeout},
+		logger:    logger,
+		now:       time.Now,
+		leases:    make(map[int64]etcdLease),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := storage.Health(ctx); err != nil {
+		return nil, fmt.Errorf("failed to connect to etcd: %w", err)
+	}
+
+	if logger != nil {
+		logger.Info("etcd connection established", map[string]interface{}{
+			"endpoints": endpoints,
+			"prefix":    config.Prefix,
+		})
+	}
+	return storage, nil
+}
+
+// etcdRecord é o status gravado no etcd: o mesmo JSON do Redis, com o instante
+// de expiração e o estado dos baldes
+type etcdRecord struct {
+	redisRecord
+	ExpiresAt int64    `json:"expiresAt,omitempty"` // epoch em milissegundos; 0 = sem expiração
+	Tokens    *float64 `json:"tokens,omitempty"`    // fichas do token bucket
+	Level     *float64 `json:"level,omitempty"`     // nível do leaky bucket
+	UpdatedAt int64    `json:"updatedAt,omitempty"` // última reposição/escoamento dos baldes
+}
+
+// expired informa se o registro já passou da expiração em nowMs
+func (r *etcdRecord) expired(nowMs int64) bool {
+	return r.ExpiresAt > 0 && nowMs >= r.ExpiresAt
+}
+
+// blockedAt informa se o registro tem um bloqueio vigente em nowMs
+func (r *etcdRecord) blockedAt(nowMs int64) bool {
+	return r.BlockedUntil != nil && *r.BlockedUntil > nowMs
+}
+
+// extendTo adia a expiração para expiresAt, sem encurtar uma expiração maior
+func (r *etcdRecord) extendTo(expiresAt int64) {
+	if expiresAt > r.ExpiresAt {
+		r.ExpiresAt = expiresAt
+	}
+}
+
+// etcdInt é um int64 da API v3; o gateway JSON os representa como strings
+type etcdInt int64
+
+func (i etcdInt) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + strconv.FormatInt(int64(i), 10) + `"`), nil
+}
+
+func (i *etcdInt) UnmarshalJSON(data []byte) error {
+	value, err := strconv.ParseInt(strings.Trim(string(data), `"`), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid etcd integer %s: %w", data, err)
+	}
+	*i = etcdInt(value)
+	return nil
+}
+
+// Mensagens da API v3 usadas pelo storage (campos do protobuf, chaves e valores em base64)
+type (
+	etcdKeyValue struct {
+		Key         []byte  `json:"key"`
+		Value       []byte  `json:"value,omitempty"`
+		ModRevision etcdInt `json:"mod_revision,omitempty"`
+		Lease       etcdInt `json:"lease,omitempty"`
+	}
+
+	etcdRangeRequest struct {
+		Key []byte `json:"key"`
+	}
+
+	etcdRangeResponse struct {
+		Kvs []etcdKeyValue `json:"kvs"`
+	}
+
+	etcdPutRequest struct {
+		Key   []byte  `json:"key"`
+		Value []byte  `json:"value"`
+		Lease etcdInt `json:"lease,omitempty"`
+	}
+
+	etcdDeleteRequest struct {
+		Key      []byte `json:"key"`
+		RangeEnd []byte `json:"range_end,omitempty"`
+	}
+
+	etcdCompare struct {
+		Key            []byte  `json:"key"`
+		Target         string  `json:"target"`
+		Result         string  `json:"result"`
+		ModRevision    etcdInt `json:"mod_revision"`
+		CreateRevision etcdInt `json:"create_revision"`
+	}
+
+	etcdRequestOp struct {
+		RequestPut etcdPutRequest `json:"request_put"`
+	}
+
+	etcdTxnRequest struct {
+		Compare []etcdCompare   `json:"compare"`
+		Success []etcdRequestOp `json:"success"`
+	}
+
+	etcdTxnResponse struct {
+		Succeeded bool `json:"succeeded"`
+	}
+
+	etcdLeaseGrantRequest struct {
+		TTL etcdInt `json:"TTL"`
+	}
+
+	etcdLeaseGrantResponse struct {
+		ID  etcdInt `json:"ID"`
+		TTL etcdInt `json:"TTL"`
+	}
+
+	etcdAuthRequest struct {
+		Name     string `json:"name"`
+		Password string `json:"password"`
+	}
+
+	etcdAuthResponse struct {
+		Token string `json:"token"`
+	}
+
+	// etcdError é o corpo de erro do gateway (status gRPC convertido)
+	etcdError struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+)
+
+// errEtcdLeaseNotFound indica que o lease usado na gravação já expirou no cluster
+var errEtcdLeaseNotFound = errors.New("etcd lease not found")
+
+// call envia uma requisição ao gateway, alternando entre os endpoints em falhas
+// de conexão e renovando o token de autenticação expirado
+func (e *EtcdStorage) call(ctx context.Context, path string, request, response interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	reauthenticated := false
+	for attempt := 0; attempt < len(e.endpoints)+1; attempt++ {
+		index := int(e.endpoint.Load()) % len(e.endpoints)
+		status, data, err := e.post(ctx, e.endpoints[index]+path, body)
+		if err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			lastErr = err
+			e.endpoint.CompareAndSwap(int64(index), int64(index+1))
+			continue
+		}
+
+		if status == http.StatusUnauthorized && e.username != "" && !reauthenticated && path != "/v3/auth/authenticate" {
+			reauthenticated = true
+			e.setToken("")
+			if err := e.authenticate(ctx); err != nil {
+				return err
+			}
+			attempt--
+			continue
+		}
+		if status != http.StatusOK {
+			var gatewayErr etcdError
+			if json.Unmarshal(data, &gatewayErr) != nil || gatewayErr.Message == "" {
+				gatewayErr.Message = strings.TrimSpace(string(data))
+			}
+			if strings.Contains(gatewayErr.Message, "requested lease not found") {
+				return errEtcdLeaseNotFound
+			}
+			return fmt.Errorf("etcd %s returned %d: %s", path, status, gatewayErr.Message)
+		}
+
+		if response == nil {
+			return nil
+		}
+		if err := json.Unmarshal(data, response); err != nil {
+			return fmt.Errorf("invalid etcd %s response: %w", path, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("no etcd endpoint available: %w", lastErr)
+}
+
+// post executa o POST com o token de autenticação atual, se houver
+func (e *EtcdStorage) post(ctx context.Context, url string, body []byte) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token := e.getToken(); token != "" {
+		req.Header.Set("Authorization", token)
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+	return resp.StatusCode, data, nil
+}
+
+// authenticate obtém um token com usuário e senha
+func (e *EtcdStorage) authenticate(ctx context.Context) error {
+	var response etcdAuthResponse
+	if err := e.call(ctx, "/v3/auth/authenticate", etcdAuthRequest{Name: e.username, Password: e.password}, &response); err != nil {
+		return fmt.Errorf("failed to authenticate with etcd: %w", err)
+	}
+	e.setToken(response.Token)
+	return nil
+}
+
+func (e *EtcdStorage) getToken() string {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	return e.token
+}
+
+func (e *EtcdStorage) setToken(token string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.token = token
+}
+
+// storageKey aplica o prefixo à chave do rate limiter
+func (e *EtcdStorage) storageKey(key string) []byte {
+	return []byte(e.prefix + key)
+}
+
+// read busca o registro da chave e a revisão usada na transação (0 = ausente).
+// Registros expirados retornam nil, mas mantêm a revisão: a chave ainda existe no etcd
+func (e *EtcdStorage) read(ctx context.Context, key string, nowMs int64) (*etcdRecord, int64, error) {
+	kv, err := e.rangeKey(ctx, key)
+	if err != nil || kv == nil {
+		return nil, 0, err
+	}
+
+	var record etcdRecord
+	if err := json.Unmarshal(kv.Value, &record); err != nil {
+		return nil, 0, fmt.Errorf("failed to unmarshal status for key %s: %w", key, err)
+	}
+	if record.expired(nowMs) {
+		return nil, int64(kv.ModRevision), nil
+	}
+	return &record, int64(kv.ModRevision), nil
+}
+
+// rangeKey busca o valor bruto da chave (nil se ausente)
+func (e *EtcdStorage) rangeKey(ctx context.Context, key string) (*etcdKeyValue, error) {
+	var response etcdRangeResponse
+	if err := e.call(ctx, "/v3/kv/range", etcdRangeRequest{Key: e.storageKey(key)}, &response); err != nil {
+		return nil, err
+	}
+	if len(response.Kvs) == 0 {
+		return nil, nil
+	}
+	return &response.Kvs[0], nil
+}
+
+// put grava o registro se a chave ainda está na revisão lida. Retorna false
+// quando outra escrita venceu a disputa
+func (e *EtcdStorage) put(ctx context.Context, key string, record *etcdRecord, revision int64, now time.Time) (bool, error) {
+	value, err := json.Marshal(record)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal status for key %s: %w", key, err)
+	}
+
+	var lease int64
+	if record.ExpiresAt > 0 {
+		ttl := time.UnixMilli(record.ExpiresAt).Sub(now)
+		if lease, err = e.lease(ctx, ttl, now); err != nil {
+			return false, err
+		}
+	}
+
+	storageKey := e.storageKey(key)
+	compare := etcdCompare{Key: storageKey, Target: "MOD", Result: "EQUAL", ModRevision: etcdInt(revision)}
+	if revision == 0 {
+		// Revisão de criação 0: a chave ainda não existe
+		compare = etcdCompare{Key: storageKey, Target: "CREATE", Result: "EQUAL"}
+	}
+
+	var response etcdTxnResponse
+	err = e.call(ctx, "/v3/kv/txn", etcdTxnRequest{
+		Compare: []etcdCompare{compare},
+		Success: []etcdRequestOp{{RequestPut: etcdPutRequest{Key: storageKey, Value: value, Lease: etcdInt(lease)}}},
+	}, &response)
+	if errors.Is(err, errEtcdLeaseNotFound) {
+		// O lease expirou antes do previsto (ex: relógio adiantado); o próximo é novo
+		e.forgetLease(lease)
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to write key %s: %w", key, err)
+	}
+	return response.Succeeded, nil
+}
+
+// update aplica change ao registro da chave (nil se ausente ou expirado) e grava
+// o resultado, relendo a chave a cada conflito com outra instância
+func (e *EtcdStorage) update(ctx context.Context, key string, change func(record *etcdRecord, nowMs int64) *etcdRecord) (*etcdRecord, error) {
+	for attempt := 0; attempt < etcdMaxTxnAttempts; attempt++ {
+		now := e.now()
+		record, revision, err := e.read(ctx, key, now.UnixMilli())
+		if err != nil {
+			return nil, err
+		}
+
+		record = change(record, now.UnixMilli())
+		written, err := e.put(ctx, key, record, revision, now)
+		if err != nil {
+			return nil, err
+		}
+		if written {
+			return record, nil
+		}
+
+		// Espera aleatória crescente para não repetir a disputa no mesmo instante
+		backoff := time.Duration(rand.Int63n(int64(attempt+1) * int64(time.Millisecond)))
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+	return nil, fmt.Errorf("too many concurrent updates to key %s", key)
+}
+
+// lease retorna um lease que dure pelo menos ttl. Os leases são compartilhados
+// por faixa de TTL (potências de 2 em segundos) e concedidos com o dobro da
+// faixa, então a chave é removida entre ttl e 4*ttl depois da gravação
+func (e *EtcdStorage) lease(ctx context.Context, ttl time.Duration, now time.Time) (int64, error) {
+	seconds := int64(math.Ceil(ttl.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	bucket := int64(1) << bits.Len64(uint64(seconds-1))
+
+	e.mutex.Lock()
+	cached, exists := e.leases[bucket]
+	e.mutex.Unlock()
+	if exists && cached.expiresAt.Sub(now) >= time.Duration(bucket)*time.Second {
+		return cached.id, nil
+	}
+
+	var response etcdLeaseGrantResponse
+	if err := e.call(ctx, "/v3/lease/grant", etcdLeaseGrantRequest{TTL: etcdInt(2 * bucket)}, &response); err != nil {
+		return 0, fmt.Errorf("failed to grant etcd lease: %w", err)
+	}
+
+	// O cluster pode conceder um TTL maior que o pedido (TTL mínimo)
+	granted := etcdLease{id: int64(response.ID), expiresAt: now.Add(time.Duration(response.TTL) * time.Second)}
+	e.mutex.Lock()
+	e.leases[bucket] = granted
+	e.mutex.Unlock()
+	return granted.id, nil
+}
+
+// forgetLease descarta um lease do cache para que o próximo seja concedido de novo
+func (e *EtcdStorage) forgetLease(id int64) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	for bucket, lease := range e.leases {
+		if lease.id == id {
+			delete(e.leases, bucket)
+		}
+	}
+}
+
+// Get recupera o status atual de rate limit para uma chave
+func (e *EtcdStorage) Get(ctx context.Context, key string) (*domain.RateLimitStatus, error) {
+	ctx, span := startSpan(ctx, EtcdStorageType, "GET", key)
+	defer span.End()
+
+	start := time.Now()
+
+	record, _, err := e.read(ctx, key, e.now().UnixMilli())
+	if err != nil {
+		e.logStorageOperation(ctx, "GET", key, false, time.Since(start).Seconds()*1000, err)
+		return nil, fmt.Errorf("failed to get key %s: %w", key, err)
+	}
+
+	e.logStorageOperation(ctx, "GET", key, true, time.Since(start).Seconds()*1000, nil)
+	if record == nil {
+		return nil, nil
+	}
+	return record.status(), nil
+}
+
+// Set define o status de rate limit para uma chave (ttl 0 = sem expiração)
+func (e *EtcdStorage) Set(ctx context.Context, key string, status *domain.RateLimitStatus, ttl time.Duration) error {
+	ctx, span := startSpan(ctx, EtcdStorageType, "SET", key)
+	defer span.End()
+
+	start := time.Now()
+
+	_, err := e.update(ctx, key, func(_ *etcdRecord, nowMs int64) *etcdRecord {
+		record := &etcdRecord{redisRecord: newRedisRecord(status)}
+		if ttl > 0 {
+			record.ExpiresAt = nowMs + ttl.Milliseconds()
+		}
+		return record
+	})
+	if err != nil {
+		e.logStorageOperation(ctx, "SET", key, false, time.Since(start).Seconds()*1000, err)
+		return fmt.Errorf("failed to set key %s: %w", key, err)
+	}
+
+	e.logStorageOperation(ctx, "SET", key, true, time.Since(start).Seconds()*1000, nil)
+	return nil
+}
+
+// Increment incrementa o contador da janela fixa da chave e retorna o novo valor
+func (e *EtcdStorage) Increment(ctx context.Context, key string, limit int, window time.Duration) (int, time.Time, error) {
+	ctx, span := startSpan(ctx, EtcdStorageType, "INCREMENT", key)
+	defer span.End()
+
+	start := time.Now()
+	windowMs := window.Milliseconds()
+
+	record, err := e.update(ctx, key, func(record *etcdRecord, nowMs int64) *etcdRecord {
+		if record == nil {
+			record = &etcdRecord{redisRecord: redisRecord{
+				Key:       key,
+				Limit:     limit,
+				Window:    int(window / time.Second),
+				LastReset: nowMs,
+			}}
+		}
+		if nowMs-record.LastReset >= windowMs {
+			record.LastReset = nowMs
+			record.Count = 0
+			record.IsBlocked = false
+		}
+
+		record.Count++
+		if record.Count > limit {
+			record.IsBlocked = true
+		}
+		// Um bloqueio mais longo que a janela mantém a chave
+		record.extendTo(record.LastReset + windowMs)
+		return record
+	})
+	if err != nil {
+		e.logStorageOperation(ctx, "INCREMENT", key, false, time.Since(start).Seconds()*1000, err)
+		return 0, time.Time{}, fmt.Errorf("failed to increment key %s: %w", key, err)
+	}
+
+	e.logStorageOperation(ctx, "INCREMENT", key, true, time.Since(start).Seconds()*1000, nil)
+	return record.Count, time.UnixMilli(record.LastReset), nil
+}
+
+// IncrementQuota incrementa o contador de uma cota com reset absoluto
+func (e *EtcdStorage) IncrementQuota(ctx context.Context, key string, period domain.QuotaPeriod) (*domain.RateLimitStatus, error) {
+	ctx, span := startSpan(ctx, EtcdStorageType, "INCREMENT_QUOTA", key)
+	defer span.End()
+
+	start := time.Now()
+	resetAtMs := period.ResetAt.UnixMilli()
+
+	record, err := e.update(ctx, key, func(record *etcdRecord, nowMs int64) *etcdRecord {
+		// Inicia um novo período quando não há reset registrado ou ele já passou
+		if record == nil || record.ResetAt == nil || nowMs >= *record.ResetAt {
+			credit := 0
+			if record != nil && record.ResetAt != nil {
+				credit = rolloverCredit(record.status(), period.RolloverPercent)
+			}
+			record = &etcdRecord{redisRecord: redisRecord{
+				Key:       key,
+				Window:    int((resetAtMs - nowMs) / 1000),
+				LastReset: nowMs,
+				ResetAt:   &resetAtMs,
+				Credit:    credit,
+			}}
+		}
+
+		record.Count++
+		record.Limit = period.Limit
+		if record.Count > record.Limit+record.Credit {
+			record.IsBlocked = true
+		}
+		// Expira um ciclo após o período (mantém base para rollover)
+		record.ExpiresAt = 2*(*record.ResetAt) - record.LastReset
+		return record
+	})
+	if err != nil {
+		e.logStorageOperation(ctx, "INCREMENT_QUOTA", key, false, time.Since(start).Seconds()*1000, err)
+		return nil, fmt.Errorf("failed to increment quota for key %s: %w", key, err)
+	}
+
+	status := record.status()
+	status.IsBlocked = status.Count > status.EffectiveLimit()
+	status.Window = int(status.ResetAt.Sub(status.LastReset).Seconds())
+
+	e.logStorageOperation(ctx, "INCREMENT_QUOTA", key, true, time.Since(start).Seconds()*1000, nil)
+	return status, nil
+}
+
+// TakeToken consome atomicamente uma ficha do balde da chave
+func (e *EtcdStorage) TakeToken(ctx context.Context, key string, bucket domain.TokenBucket) (*domain.RateLimitStatus, error) {
+	ctx, span := startSpan(ctx, EtcdStorageType, "TAKE_TOKEN", key)
+	defer span.End()
+
+	if err := validateTokenBucket(bucket); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	fill := int64(math.Ceil(float64(bucket.Capacity) * 1000 / bucket.RefillRate))
+
+	var tokens float64
+	var allowed bool
+	record, err := e.update(ctx, key, func(record *etcdRecord, nowMs int64) *etcdRecord {
+		if record == nil {
+			record = &etcdRecord{redisRecord: redisRecord{Key: key}}
+		}
+
+		// Reposição pelo tempo decorrido desde a última requisição
+		tokens = float64(bucket.Capacity)
+		if record.Tokens != nil {
+			tokens = *record.Tokens
+		}
+		tokens, allowed = refillAndTake(tokens, time.Duration(nowMs-record.UpdatedAt)*time.Millisecond, bucket)
+
+		blocked := record.blockedAt(nowMs)
+		if !blocked {
+			record.BlockedUntil = nil
+		}
+		record.Tokens = &tokens
+		record.UpdatedAt = nowMs
+		record.Count = bucket.Capacity - int(math.Floor(tokens))
+		record.Limit = bucket.Capacity
+		record.Window = int(math.Ceil(float64(fill) / 1000))
+		record.LastReset = nowMs
+		record.IsBlocked = blocked
+		// Preserva a expiração de um bloqueio mais longo que a reposição
+		record.extendTo(nowMs + fill)
+		return record
+	})
+	if err != nil {
+		e.logStorageOperation(ctx, "TAKE_TOKEN", key, false, time.Since(start).Seconds()*1000, err)
+		return nil, fmt.Errorf("failed to take token for key %s: %w", key, err)
+	}
+
+	e.logStorageOperation(ctx, "TAKE_TOKEN", key, true, time.Since(start).Seconds()*1000, nil)
+	return tokenBucketStatus(key, bucket, tokens, allowed, time.UnixMilli(record.UpdatedAt)), nil
+}
+
+// Leak enfileira atomicamente a requisição no leaky bucket da chave
+func (e *EtcdStorage) Leak(ctx context.Context, key string, bucket domain.LeakyBucket) (*domain.RateLimitStatus, error) {
+	ctx, span := startSpan(ctx, EtcdStorageType, "LEAK", key)
+	defer span.End()
+
+	if err := validateLeakyBucket(bucket); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+
+	var ahead float64
+	var allowed bool
+	record, err := e.update(ctx, key, func(record *etcdRecord, nowMs int64) *etcdRecord {
+		if record == nil {
+			record = &etcdRecord{redisRecord: redisRecord{Key: key}}
+		}
+
+		// Escoamento pelo tempo decorrido desde a última requisição
+		level := 0.0
+		if record.Level != nil {
+			level = *record.Level
+		}
+		ahead, allowed = drainAndEnqueue(level, time.Duration(nowMs-record.UpdatedAt)*time.Millisecond, bucket)
+		level = ahead
+		if allowed {
+			level++
+		}
+
+		blocked := record.blockedAt(nowMs)
+		if !blocked {
+			record.BlockedUntil = nil
+		}
+		record.Level = &level
+		record.UpdatedAt = nowMs
+		record.Count = int(math.Ceil(level))
+		record.Limit = bucket.Capacity
+		record.Window = int(math.Ceil(float64(bucket.Capacity) / bucket.LeakRate))
+		record.LastReset = nowMs
+		record.IsBlocked = blocked
+		// Preserva a expiração de um bloqueio mais longo que o escoamento
+		record.extendTo(nowMs + int64(math.Ceil(math.Max(level, 1)*1000/bucket.LeakRate)))
+		return record
+	})
+	if err != nil {
+		e.logStorageOperation(ctx, "LEAK", key, false, time.Since(start).Seconds()*1000, err)
+		return nil, fmt.Errorf("failed to enqueue request for key %s: %w", key, err)
+	}
+
+	e.logStorageOperation(ctx, "LEAK", key, true, time.Since(start).Seconds()*1000, nil)
+	return leakyBucketStatus(key, bucket, ahead, allowed, time.UnixMilli(record.UpdatedAt)), nil
+}
+
+// IsBlocked verifica se uma chave está bloqueada
+func (e *EtcdStorage) IsBlocked(ctx context.Context, key string) (bool, *time.Time, error) {
+	ctx, span := startSpan(ctx, EtcdStorageType, "IS_BLOCKED", key)
+	defer span.End()
+
+	start := time.Now()
+
+	status, err := e.Get(ctx, key)
+	if err != nil {
+		return false, nil, err
+	}
+
+	// Bloqueio expirado não vale mais, mesmo que a chave ainda não tenha expirado
+	if status == nil || (status.BlockedUntil != nil && !e.now().Before(*status.BlockedUntil)) {
+		e.logStorageOperation(ctx, "IS_BLOCKED", key, true, time.Since(start).Seconds()*1000, nil)
+		return false, nil, nil
+	}
+
+	e.logStorageOperation(ctx, "IS_BLOCKED", key, true, time.Since(start).Seconds()*1000, nil)
+	return status.IsBlocked, status.BlockedUntil, nil
+}
+
+// Block bloqueia uma chave por um período específico, mantendo o contador
+func (e *EtcdStorage) Block(ctx context.Context, key string, duration time.Duration) error {
+	ctx, span := startSpan(ctx, EtcdStorageType, "BLOCK", key)
+	defer span.End()
+
+	start := time.Now()
+
+	_, err := e.update(ctx, key, func(record *etcdRecord, nowMs int64) *etcdRecord {
+		if record == nil {
+			record = &etcdRecord{redisRecord: redisRecord{Key: key, LastReset: nowMs}}
+		}
+		blockedUntil := nowMs + duration.Milliseconds()
+		record.IsBlocked = true
+		record.BlockedUntil = &blockedUntil
+		record.extendTo(blockedUntil + time.Minute.Milliseconds())
+		return record
+	})
+	if err != nil {
+		e.logStorageOperation(ctx, "BLOCK", key, false, time.Since(start).Seconds()*1000, err)
+		return fmt.Errorf("failed to block key %s: %w", key, err)
+	}
+
+	e.logStorageOperation(ctx, "BLOCK", key, true, time.Since(start).Seconds()*1000, nil)
+	return nil
+}
+
+// Reset limpa os dados de uma chave
+func (e *EtcdStorage) Reset(ctx context.Context, key string) error {
+	ctx, span := startSpan(ctx, EtcdStorageType, "RESET", key)
+	defer span.End()
+
+	start := time.Now()
+
+	if err := e.call(ctx, "/v3/kv/deleterange", etcdDeleteRequest{Key: e.storageKey(key)}, nil); err != nil {
+		e.logStorageOperation(ctx, "RESET", key, false, time.Since(start).Seconds()*1000, err)
+		return fmt.Errorf("failed to reset key %s: %w", key, err)
+	}
+
+	e.logStorageOperation(ctx, "RESET", key, true, time.Since(start).Seconds()*1000, nil)
+	return nil
+}
+
+// Inspect retorna o valor salvo e o tempo até a expiração sem interpretá-los
+// Falhas de decodificação são reportadas no registro, não como erro
+func (e *EtcdStorage) Inspect(ctx context.Context, key string) (*domain.StorageRecord, error) {
+	ctx, span := startSpan(ctx, EtcdStorageType, "INSPECT", key)
+	defer span.End()
+
+	start := time.Now()
+
+	kv, err := e.rangeKey(ctx, key)
+	if err != nil {
+		e.logStorageOperation(ctx, "INSPECT", key, false, time.Since(start).Seconds()*1000, err)
+		return nil, fmt.Errorf("failed to inspect key %s: %w", key, err)
+	}
+
+	record := &domain.StorageRecord{Backend: string(EtcdStorageType), StorageKey: string(e.storageKey(key))}
+	if kv == nil {
+		e.logStorageOperation(ctx, "INSPECT", key, true, time.Since(start).Seconds()*1000, nil)
+		return record, nil
+	}
+
+	record.Exists = true
+	record.Raw = string(kv.Value)
+
+	var stored etcdRecord
+	if err := json.Unmarshal(kv.Value, &stored); err != nil {
+		record.DecodeError = err.Error()
+	} else {
+		record.Status = stored.status()
+		if stored.ExpiresAt > 0 {
+			ttl := time.UnixMilli(stored.ExpiresAt).Sub(e.now())
+			record.TTL = &ttl
+		}
+	}
+
+	e.logStorageOperation(ctx, "INSPECT", key, true, time.Since(start).Seconds()*1000, nil)
+	return record, nil
+}
+
+// Health verifica se o cluster responde (/v3/maintenance/status)
+func (e *EtcdStorage) Health(ctx context.Context) error {
+	ctx, span := startSpan(ctx, EtcdStorageType, "HEALTH", "")
+	defer span.End()
+
+	start := time.Now()
+
+	if e.username != "" && e.getToken() == "" {
+		if err := e.authenticate(ctx); err != nil {
+			e.logStorageOperation(ctx, "HEALTH", "status", false, time.Since(start).Seconds()*1000, err)
+			return fmt.Errorf("etcd health check failed: %w", err)
+		}
+	}
+
+	if err := e.call(ctx, "/v3/maintenance/status", struct{}{}, nil); err != nil {
+		e.logStorageOperation(ctx, "HEALTH", "status", false, time.Since(start).Seconds()*1000, err)
+		return fmt.Errorf("etcd health check failed: %w", err)
+	}
+
+	e.logStorageOperation(ctx, "HEALTH", "status", true, time.Since(start).Seconds()*1000, nil)
+	return nil
+}
+
+// Close libera as conexões com o cluster. Os leases não são revogados: as chaves
+// continuam valendo para as demais instâncias até expirarem
+func (e *EtcdStorage) Close() error {
+	e.client.CloseIdleConnections()
+	if e.logger != nil {
+		e.logger.Info("etcd connection closed", nil)
+	}
+	return nil
+}
+
+// logStorageOperation registra operações de storage
+func (e *EtcdStorage) logStorageOperation(ctx context.Context, operation, key string, success bool, latency float64, err error) {
+	if !success {
+		recordSpanError(ctx, err)
+	}
+
+	if e.logger == nil {
+		return
+	}
+	if success {
+		// Sucesso é registrado só em debug: evita montar os campos a cada operação
+		if !domain.DebugEnabled(e.logger) {
+			return
+		}
+		e.logger.Debug("Storage operation completed", map[string]interface{}{
+			"operation": operation,
+			"key":       key,
+			"latency":   latency,
+		})
+		return
+	}
+	e.logger.Error("Storage operation failed", err, map[string]interface{}{
+		"operation": operation,
+		"key":       key,
+		"latency":   latency,
+	})
+}
@@ -0,0 +1,246 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/logger"
+	"rate-limiter/internal/storage/storagetest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEtcd simula o gateway JSON da API v3 com as operações usadas pelo storage
+type fakeEtcd struct {
+	mutex    sync.Mutex
+	revision int64
+	kvs      map[string]fakeEtcdValue
+	leases   map[int64]int64 // ID -> TTL
+	grants   int
+	txns     int
+
+	username string
+	password string
+	token    string
+}
+
+type fakeEtcdValue struct {
+	value          []byte
+	createRevision int64
+	modRevision    int64
+	lease          int64
+}
+
+// newFakeEtcd sobe o gateway simulado; username vazio desabilita a autenticação
+func newFakeEtcd(t *testing.T, username, password string) (*fakeEtcd, *httptest.Server) {
+	t.Helper()
+
+	fake := &fakeEtcd{
+		kvs:      make(map[string]fakeEtcdValue),
+		leases:   make(map[int64]int64),
+		username: username,
+		password: password,
+	}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+func (f *fakeEtcd) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	reply := func(status int, body interface{}) {
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(body)
+	}
+
+	if r.URL.Path == "/v3/auth/authenticate" {
+		var request etcdAuthRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		if request.Name != f.username || request.Password != f.password {
+			reply(http.StatusBadRequest, etcdError{Code: 3, Message: "etcdserver: authentication failed, invalid user ID or password"})
+			return
+		}
+		f.token = "token-" + time.Now().Format(time.RFC3339Nano)
+		reply(http.StatusOK, etcdAuthResponse{Token: f.token})
+		return
+	}
+	if f.username != "" && (f.token == "" || r.Header.Get("Authorization") != f.token) {
+		reply(http.StatusUnauthorized, etcdError{Code: 16, Message: "etcdserver: invalid auth token"})
+		return
+	}
+
+	switch r.URL.Path {
+	case "/v3/kv/range":
+		var request etcdRangeRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		var kvs []etcdKeyValue
+		if stored, ok := f.kvs[string(request.Key)]; ok {
+			kvs = append(kvs, etcdKeyValue{Key: request.Key, Value: stored.value, ModRevision: etcdInt(stored.modRevision), Lease: etcdInt(stored.lease)})
+		}
+		reply(http.StatusOK, map[string]interface{}{"kvs": kvs})
+	case "/v3/kv/txn":
+		var request etcdTxnRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		f.txns++
+		for _, compare := range request.Compare {
+			stored := f.kvs[string(compare.Key)]
+			if (compare.Target == "MOD" && stored.modRevision != int64(compare.ModRevision)) ||
+				(compare.Target == "CREATE" && stored.createRevision != int64(compare.CreateRevision)) {
+				reply(http.StatusOK, map[string]interface{}{})
+				return
+			}
+		}
+		for _, op := range request.Success {
+			put := op.RequestPut
+			if _, ok := f.leases[int64(put.Lease)]; put.Lease != 0 && !ok {
+				reply(http.StatusNotFound, etcdError{Code: 5, Message: "etcdserver: requested lease not found"})
+				return
+			}
+			f.revision++
+			stored := f.kvs[string(put.Key)]
+			if stored.createRevision == 0 {
+				stored.createRevision = f.revision
+			}
+			stored.modRevision = f.revision
+			stored.value = put.Value
+			stored.lease = int64(put.Lease)
+			f.kvs[string(put.Key)] = stored
+		}
+		reply(http.StatusOK, etcdTxnResponse{Succeeded: true})
+	case "/v3/kv/deleterange":
+		var request etcdDeleteRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		delete(f.kvs, string(request.Key))
+		reply(http.StatusOK, map[string]interface{}{})
+	case "/v3/lease/grant":
+		var request etcdLeaseGrantRequest
+		json.NewDecoder(r.Body).Decode(&request)
+		f.grants++
+		id := int64(7587000000000000 + f.grants)
+		f.leases[id] = int64(request.TTL)
+		reply(http.StatusOK, etcdLeaseGrantResponse{ID: etcdInt(id), TTL: request.TTL})
+	case "/v3/maintenance/status":
+		reply(http.StatusOK, map[string]interface{}{"version": "3.5.12"})
+	default:
+		reply(http.StatusNotFound, etcdError{Code: 5, Message: "Not Found"})
+	}
+}
+
+// revokeLeases simula a expiração de todos os leases no cluster
+func (f *fakeEtcd) revokeLeases() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.leases = make(map[int64]int64)
+}
+
+func newTestEtcdStorage(t *testing.T, config EtcdConfig) *EtcdStorage {
+	t.Helper()
+
+	storage, err := NewEtcdStorage(config, logger.NewLogger("error", "text"))
+	require.NoError(t, err)
+	t.Cleanup(func() { storage.Close() })
+	return storage
+}
+
+func TestEtcdStorage_Conformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) domain.RateLimiterStorage {
+		_, server := newFakeEtcd(t, "", "")
+		return newTestEtcdStorage(t, EtcdConfig{Endpoints: []string{server.URL}})
+	})
+}
+
+// TestEtcdStorage_SharesLeases testa o prefixo das chaves e a reutilização dos
+// leases por faixa de TTL
+func TestEtcdStorage_SharesLeases(t *testing.T) {
+	// Arrange
+	fake, server := newFakeEtcd(t, "", "")
+	storage := newTestEtcdStorage(t, EtcdConfig{Endpoints: []string{server.URL}})
+	ctx := context.Background()
+
+	// Act
+	for _, key := range []string{"rate_limit:ip:10.0.0.1", "rate_limit:ip:10.0.0.2"} {
+		_, _, err := storage.Increment(ctx, key, 10, time.Minute)
+		require.NoError(t, err)
+	}
+	require.NoError(t, storage.Block(ctx, "rate_limit:ip:10.0.0.1", time.Hour))
+
+	// Assert
+	assert.Contains(t, fake.kvs, "/rate-limiter/rate_limit:ip:10.0.0.1")
+	assert.Equal(t, 2, fake.grants, "one lease for the window and one for the block")
+	assert.Equal(t, fake.kvs["/rate-limiter/rate_limit:ip:10.0.0.1"].lease, int64(7587000000000002))
+	assert.Equal(t, fake.kvs["/rate-limiter/rate_limit:ip:10.0.0.2"].lease, int64(7587000000000001))
+
+	record, err := storage.Inspect(ctx, "rate_limit:ip:10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, "/rate-limiter/rate_limit:ip:10.0.0.1", record.StorageKey)
+	require.NotNil(t, record.TTL)
+	assert.InDelta(t, (time.Hour + time.Minute).Seconds(), record.TTL.Seconds(), 1)
+}
+
+// TestEtcdStorage_RegrantsExpiredLease testa a gravação depois de o cluster
+// expirar o lease em cache
+func TestEtcdStorage_RegrantsExpiredLease(t *testing.T) {
+	// Arrange
+	fake, server := newFakeEtcd(t, "", "")
+	storage := newTestEtcdStorage(t, EtcdConfig{Endpoints: []string{server.URL}})
+	ctx := context.Background()
+	_, _, err := storage.Increment(ctx, "rate_limit:ip:10.0.0.1", 10, time.Minute)
+	require.NoError(t, err)
+
+	// Act
+	fake.revokeLeases()
+	count, _, err := storage.Increment(ctx, "rate_limit:ip:10.0.0.1", 10, time.Minute)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 2, fake.grants)
+}
+
+// TestEtcdStorage_Authentication testa o login e a renovação do token expirado
+func TestEtcdStorage_Authentication(t *testing.T) {
+	// Arrange
+	fake, server := newFakeEtcd(t, "rate-limiter", "secret")
+	storage := newTestEtcdStorage(t, EtcdConfig{Endpoints: []string{server.URL}, Username: "rate-limiter", Password: "secret"})
+	ctx := context.Background()
+
+	// Act
+	fake.mutex.Lock()
+	fake.token = "rotated"
+	fake.mutex.Unlock()
+	count, _, err := storage.Increment(ctx, "rate_limit:token:abc", 10, time.Minute)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	_, err = NewEtcdStorage(EtcdConfig{Endpoints: []string{server.URL}, Username: "rate-limiter", Password: "wrong"}, nil)
+	assert.ErrorContains(t, err, "invalid user ID or password")
+}
+
+// TestEtcdStorage_EndpointFailover testa a troca de endpoint quando um membro não responde
+func TestEtcdStorage_EndpointFailover(t *testing.T) {
+	// Arrange
+	_, server := newFakeEtcd(t, "", "")
+	down := httptest.NewServer(http.NotFoundHandler())
+	downURL := down.URL
+	down.Close()
+
+	// Act
+	storage := newTestEtcdStorage(t, EtcdConfig{Endpoints: []string{downURL, server.URL}})
+	count, _, err := storage.Increment(context.Background(), "rate_limit:ip:10.0.0.1", 10, time.Minute)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, int64(1), storage.endpoint.Load())
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"fmt"
+	"net"
 	"strings"
 
 	"rate-limiter/internal/domain"
@@ -19,14 +20,58 @@ const (
 type StorageConfig struct {
 	Type     StorageType
 	RedisConfig *RedisConfig
+	MemoryConfig *MemoryConfig // Opcional
+	HybridConfig *HybridConfig // Opcional; o tipo hybrid também usa RedisConfig e MemoryConfig
+	TieredConfig *TieredConfig // Opcional; o tipo tiered também usa RedisConfig e MemoryConfig
+	EtcdConfig   *EtcdConfig   // Obrigatório para o tipo etcd
+
+	// Name identifica o storage no Registry (padrão: o próprio tipo)
+	Name string
+
+	// Algorithm é o algoritmo de contagem do Increment (padrão: janela fixa)
+	Algorithm Algorithm
+
+	// HistorySize é quantas janelas recentes de cada chave são guardadas para
+	// /admin/status?history=true (0 = desabilitado; não suportado pelo etcd)
+	HistorySize int
+
+	// BlockFilter responde IsBlocked localmente para chaves não bloqueadas
+	// (nil = desabilitado; apenas o tipo redis, os demais já consultam a memória)
+	BlockFilter *BlockFilterConfig
+
+	// Cipher cifra os status gravados no Redis (nil = JSON em claro; tipos redis,
+	// hybrid e tiered, apenas na janela fixa e sem histórico). O memory não grava
+	// nada fora do processo além do snapshot, cifrado pela MemorySnapshotConfig
+	Cipher *Cipher
 }
 
 // RedisConfig contém configurações específicas do Redis
 type RedisConfig struct {
 	Host     string
 	Port     string
+	Username string // Usuário ACL (Redis 6+); vazio = usuário default
 	Password string
 	Database int
+
+	TLS *RedisTLSConfig // nil = conexão sem TLS
+
+	// Mode é RedisModeStandalone (padrão) ou RedisModeSentinel. No modo sentinel,
+	// Host e Port são ignorados e o master é descoberto pelos sentinels
+	Mode             string
+	MasterName       string
+	SentinelAddrs    []string // host:porta de cada sentinel
+	SentinelUsername string
+	SentinelPassword string
+
+	// RetryPolicy decide as novas tentativas das operações (nil = política padrão,
+	// sem circuito associado)
+	RetryPolicy *RetryPolicy
+}
+
+// MemoryConfig contém configurações específicas do storage em memória
+type MemoryConfig struct {
+	ExpectedKeys int // Chaves pré-alocadas (0 = crescimento sob demanda)
+	MaxEntries   int // Limite de chaves com descarte LRU (0 = sem limite)
 }
 
 // StorageFactory cria instâncias de storage seguindo Strategy Pattern
@@ -42,12 +87,45 @@ func (f *StorageFactory) CreateStorage(config *StorageConfig, logger domain.Logg
 	if config == nil {
 		return nil, fmt.Errorf("storage config cannot be nil")
 	}
+	if err := validateAlgorithm(config); err != nil {
+		return nil, err
+	}
+	if err := ValidateEncryption(config); err != nil {
+		return nil, err
+	}
 
 	switch strings.ToLower(string(config.Type)) {
 	case string(RedisStorageType):
-		return f.createRedisStorage(config.RedisConfig, logger)
+		storage, err := f.createRedisStorage(config.RedisConfig, logger)
+		if err != nil {
+			return nil, err
+		}
+		storage.(*RedisStorage).algorithm = config.Algorithm
+		storage.(*RedisStorage).SetWindowHistory(config.HistorySize)
+		storage.(*RedisStorage).SetCipher(config.Cipher)
+		if config.BlockFilter != nil {
+			return NewBlockFilterStorage(storage.(*RedisStorage), *config.BlockFilter, logger), nil
+		}
+		return storage, nil
 	case string(MemoryStorageType):
-		return f.createMemoryStorage(logger)
+		storage, err := f.createMemoryStorage(config.MemoryConfig, logger)
+		if err != nil {
+			return nil, err
+		}
+		storage.(*MemoryStorage).algorithm = config.Algorithm
+		storage.(*MemoryStorage).SetWindowHistory(config.HistorySize)
+		return storage, nil
+	case string(HybridStorageType):
+		storage, err := f.createHybridStorage(config, logger)
+		if err != nil {
+			return nil, err
+		}
+		storage.(*HybridStorage).SetWindowHistory(config.HistorySize)
+		return storage, nil
+	case string(TieredStorageType):
+		return f.createTieredStorage(config, logger)
+	case string(EtcdStorageType):
+		return f.createEtcdStorage(config.EtcdConfig, logger)
 	default:
 		return nil, fmt.Errorf("unsupported storage type: %s", config.Type)
 	}
@@ -58,6 +136,12 @@ func (f *StorageFactory) createRedisStorage(config *RedisConfig, logger domain.L
 	if config == nil {
 		return nil, fmt.Errorf("Redis config cannot be nil")
 	}
+	if err := validateRedisMode(config); err != nil {
+		return nil, err
+	}
+	if config.Mode == RedisModeSentinel {
+		return f.createRedisSentinelStorage(config, logger)
+	}
 
 	// Validações básicas
 	if config.Host == "" {
@@ -70,10 +154,17 @@ func (f *StorageFactory) createRedisStorage(config *RedisConfig, logger domain.L
 		return nil, fmt.Errorf("Redis database must be between 0 and 15")
 	}
 
-	storage, err := NewRedisStorage(config.Host, config.Port, config.Password, config.Database, logger)
+	options, err := redisOptions(config)
+	if err != nil {
+		return nil, err
+	}
+	storage, err := NewRedisStorageWithOptions(options, logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Redis storage: %w", err)
 	}
+	if config.RetryPolicy != nil {
+		storage.SetRetryPolicy(config.RetryPolicy)
+	}
 
 	if logger != nil {
 		logger.Info("Redis storage created successfully", map[string]interface{}{
@@ -86,9 +177,38 @@ func (f *StorageFactory) createRedisStorage(config *RedisConfig, logger domain.L
 	return storage, nil
 }
 
+// createRedisSentinelStorage cria o Redis storage com o master descoberto pelos sentinels
+func (f *StorageFactory) createRedisSentinelStorage(config *RedisConfig, logger domain.Logger) (domain.RateLimiterStorage, error) {
+	if config.Database < 0 || config.Database > 15 {
+		return nil, fmt.Errorf("Redis database must be between 0 and 15")
+	}
+
+	failover, err := redisFailoverOptions(config)
+	if err != nil {
+		return nil, err
+	}
+	storage, err := NewRedisSentinelStorageWithOptions(failover, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Redis storage: %w", err)
+	}
+	if config.RetryPolicy != nil {
+		storage.SetRetryPolicy(config.RetryPolicy)
+	}
+
+	if logger != nil {
+		logger.Info("Redis storage created successfully", map[string]interface{}{
+			"mode":     config.Mode,
+			"master":   config.MasterName,
+			"database": config.Database,
+		})
+	}
+
+	return storage, nil
+}
+
 // createMemoryStorage cria uma instância de Memory storage
-func (f *StorageFactory) createMemoryStorage(logger domain.Logger) (domain.RateLimiterStorage, error) {
-	storage := NewMemoryStorage(logger)
+func (f *StorageFactory) createMemoryStorage(config *MemoryConfig, logger domain.Logger) (domain.RateLimiterStorage, error) {
+	storage := newConfiguredMemoryStorage(config, logger)
 
 	if logger != nil {
 		logger.Info("Memory storage created successfully", nil)
@@ -97,9 +217,122 @@ func (f *StorageFactory) createMemoryStorage(logger domain.Logger) (domain.RateL
 	return storage, nil
 }
 
+// newConfiguredMemoryStorage cria o MemoryStorage usado sozinho ou como camada local
+func newConfiguredMemoryStorage(config *MemoryConfig, logger domain.Logger) *MemoryStorage {
+	if config == nil {
+		return NewMemoryStorage(logger)
+	}
+	storage := NewMemoryStorageWithCapacity(logger, config.ExpectedKeys)
+	storage.SetMaxEntries(config.MaxEntries)
+	return storage
+}
+
+// createHybridStorage cria o storage em memória sincronizado com o Redis
+func (f *StorageFactory) createHybridStorage(config *StorageConfig, logger domain.Logger) (domain.RateLimiterStorage, error) {
+	remote, err := f.createRedisStorage(config.RedisConfig, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	hybridConfig := HybridConfig{}
+	if config.HybridConfig != nil {
+		hybridConfig = *config.HybridConfig
+	}
+
+	remote.(*RedisStorage).SetCipher(config.Cipher)
+	storage := NewHybridStorage(newConfiguredMemoryStorage(config.MemoryConfig, logger), remote.(*RedisStorage), hybridConfig, logger)
+
+	if logger != nil {
+		logger.Info("Hybrid storage created successfully", nil)
+	}
+
+	return storage, nil
+}
+
+// createTieredStorage cria o cache em memória na frente do Redis storage
+func (f *StorageFactory) createTieredStorage(config *StorageConfig, logger domain.Logger) (domain.RateLimiterStorage, error) {
+	remote, err := f.createRedisStorage(config.RedisConfig, logger)
+	if err != nil {
+		return nil, err
+	}
+	remote.(*RedisStorage).algorithm = config.Algorithm
+	remote.(*RedisStorage).SetWindowHistory(config.HistorySize)
+	remote.(*RedisStorage).SetCipher(config.Cipher)
+
+	tieredConfig := TieredConfig{}
+	if config.TieredConfig != nil {
+		tieredConfig = *config.TieredConfig
+	}
+
+	storage := NewTieredStorage(newConfiguredMemoryStorage(config.MemoryConfig, logger), remote.(*RedisStorage), tieredConfig, logger)
+
+	if logger != nil {
+		logger.Info("Tiered storage created successfully", nil)
+	}
+
+	return storage, nil
+}
+
+// createEtcdStorage cria uma instância de etcd storage
+func (f *StorageFactory) createEtcdStorage(config *EtcdConfig, logger domain.Logger) (domain.RateLimiterStorage, error) {
+	if err := f.validateEtcdConfig(config); err != nil {
+		return nil, err
+	}
+
+	storage, err := NewEtcdStorage(*config, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create etcd storage: %w", err)
+	}
+
+	if logger != nil {
+		logger.Info("etcd storage created successfully", map[string]interface{}{
+			"endpoints": len(config.Endpoints),
+		})
+	}
+
+	return storage, nil
+}
+
+// CreateRegistry cria um storage por configuração e os registra pelo nome
+// A primeira configuração se torna o storage padrão
+func (f *StorageFactory) CreateRegistry(configs []*StorageConfig, logger domain.Logger) (*Registry, error) {
+	if len(configs) == 0 {
+		return nil, fmt.Errorf("at least one storage config is required")
+	}
+
+	var registry *Registry
+	for _, config := range configs {
+		storage, err := f.CreateStorage(config, logger)
+		if err != nil {
+			if registry != nil {
+				registry.Close()
+			}
+			return nil, err
+		}
+
+		name := config.Name
+		if name == "" {
+			name = string(config.Type)
+		}
+
+		if registry == nil {
+			registry = NewRegistry(name, storage)
+			continue
+		}
+
+		if err := registry.Register(name, storage); err != nil {
+			storage.Close()
+			registry.Close()
+			return nil, err
+		}
+	}
+
+	return registry, nil
+}
+
 // GetSupportedTypes retorna os tipos de storage suportados
 func (f *StorageFactory) GetSupportedTypes() []StorageType {
-	return []StorageType{RedisStorageType, MemoryStorageType}
+	return []StorageType{RedisStorageType, MemoryStorageType, HybridStorageType, TieredStorageType, EtcdStorageType}
 }
 
 // ValidateConfig valida uma configuração de storage
@@ -107,6 +340,12 @@ func (f *StorageFactory) ValidateConfig(config *StorageConfig) error {
 	if config == nil {
 		return fmt.Errorf("storage config cannot be nil")
 	}
+	if err := validateAlgorithm(config); err != nil {
+		return err
+	}
+	if err := ValidateEncryption(config); err != nil {
+		return err
+	}
 
 	switch strings.ToLower(string(config.Type)) {
 	case string(RedisStorageType):
@@ -114,6 +353,10 @@ func (f *StorageFactory) ValidateConfig(config *StorageConfig) error {
 	case string(MemoryStorageType):
 		// Memory storage não precisa de configurações específicas
 		return nil
+	case string(HybridStorageType), string(TieredStorageType):
+		return f.validateRedisConfig(config.RedisConfig)
+	case string(EtcdStorageType):
+		return f.validateEtcdConfig(config.EtcdConfig)
 	default:
 		return fmt.Errorf("unsupported storage type: %s", config.Type)
 	}
@@ -125,11 +368,15 @@ func (f *StorageFactory) validateRedisConfig(config *RedisConfig) error {
 		return fmt.Errorf("Redis config cannot be nil")
 	}
 
-	if config.Host == "" {
+	if err := validateRedisMode(config); err != nil {
+		return err
+	}
+
+	if config.Mode != RedisModeSentinel && config.Host == "" {
 		return fmt.Errorf("Redis host cannot be empty")
 	}
 
-	if config.Port == "" {
+	if config.Mode != RedisModeSentinel && config.Port == "" {
 		return fmt.Errorf("Redis port cannot be empty")
 	}
 
@@ -137,6 +384,52 @@ func (f *StorageFactory) validateRedisConfig(config *RedisConfig) error {
 		return fmt.Errorf("Redis database must be between 0 and 15, got: %d", config.Database)
 	}
 
+	if _, err := config.TLS.Load(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateRedisMode valida o modo de conexão e, no modo sentinel, o master e os sentinels
+func validateRedisMode(config *RedisConfig) error {
+	switch config.Mode {
+	case "", RedisModeStandalone:
+		return nil
+	case RedisModeSentinel:
+		if config.MasterName == "" {
+			return fmt.Errorf("Redis sentinel master name cannot be empty")
+		}
+		if len(config.SentinelAddrs) == 0 {
+			return fmt.Errorf("at least one Redis sentinel address is required")
+		}
+		for _, addr := range config.SentinelAddrs {
+			if _, _, err := net.SplitHostPort(addr); err != nil {
+				return fmt.Errorf("Redis sentinel address must be host:port, got: %s", addr)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("Redis mode must be '%s' or '%s', got: %s", RedisModeStandalone, RedisModeSentinel, config.Mode)
+	}
+}
+
+// validateEtcdConfig valida configuração do etcd
+func (f *StorageFactory) validateEtcdConfig(config *EtcdConfig) error {
+	if config == nil {
+		return fmt.Errorf("etcd config cannot be nil")
+	}
+
+	if len(config.Endpoints) == 0 {
+		return fmt.Errorf("at least one etcd endpoint is required")
+	}
+
+	for _, endpoint := range config.Endpoints {
+		if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+			return fmt.Errorf("etcd endpoint must be an http(s) URL, got: %s", endpoint)
+		}
+	}
+
 	return nil
 }
 
@@ -146,7 +439,7 @@ func BuildStorageConfigFromEnv(storageType, redisHost, redisPort, redisPassword
 		Type: StorageType(strings.ToLower(storageType)),
 	}
 
-	if config.Type == RedisStorageType {
+	if config.Type == RedisStorageType || config.Type == HybridStorageType || config.Type == TieredStorageType {
 		config.RedisConfig = &RedisConfig{
 			Host:     redisHost,
 			Port:     redisPort,
@@ -173,4 +466,37 @@ func CreateDefaultRedisStorage(logger domain.Logger) (domain.RateLimiterStorage,
 	}
 
 	return NewRedisStorage(config.Host, config.Port, config.Password, config.Database, logger)
-} 
\ No newline at end of file
+} 
+// validateAlgorithm verifica se o algoritmo é conhecido e suportado pelo tipo de storage
+func validateAlgorithm(config *StorageConfig) error {
+	if _, err := ParseAlgorithm(string(config.Algorithm)); err != nil {
+		return err
+	}
+	if !SupportsAlgorithm(config.Type, config.Algorithm) {
+		return fmt.Errorf("storage type %s does not support the %s algorithm", config.Type, config.Algorithm)
+	}
+	return nil
+}
+
+// ValidateEncryption verifica se o storage consegue cifrar os status gravados:
+// sem os scripts Lua, só a janela fixa sem histórico é suportada, e o etcd grava
+// os status em claro
+func ValidateEncryption(config *StorageConfig) error {
+	if config.Cipher == nil {
+		return nil
+	}
+	switch StorageType(strings.ToLower(string(config.Type))) {
+	case MemoryStorageType:
+		return nil
+	case RedisStorageType, HybridStorageType, TieredStorageType:
+	default:
+		return fmt.Errorf("storage type %s does not support encrypted values", config.Type)
+	}
+	if !fixedWindow(config.Algorithm) {
+		return fmt.Errorf("the %s algorithm does not support encrypted values", config.Algorithm)
+	}
+	if config.HistorySize > 0 {
+		return fmt.Errorf("window history does not support encrypted values")
+	}
+	return nil
+}
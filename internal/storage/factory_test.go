@@ -9,6 +9,7 @@ import (
 	"rate-limiter/internal/logger"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestStorageFactory_CreateStorage(t *testing.T) {
@@ -129,6 +130,9 @@ func TestStorageFactory_CreateStorage(t *testing.T) {
 }
 
 func TestStorageFactory_ValidateConfig(t *testing.T) {
+	testCipher, err := NewCipher(make([]byte, 32))
+	require.NoError(t, err)
+
 	tests := []struct {
 		name        string
 		config      *StorageConfig
@@ -222,6 +226,124 @@ func TestStorageFactory_ValidateConfig(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "Should validate Redis sentinel config without host",
+			config: &StorageConfig{
+				Type: RedisStorageType,
+				RedisConfig: &RedisConfig{
+					Mode:          RedisModeSentinel,
+					MasterName:    "mymaster",
+					SentinelAddrs: []string{"sentinel-0:26379", "sentinel-1:26379"},
+				},
+			},
+			expectError: false,
+		},
+		{
+			name: "Should return error for Redis sentinel without master name",
+			config: &StorageConfig{
+				Type: RedisStorageType,
+				RedisConfig: &RedisConfig{
+					Mode:          RedisModeSentinel,
+					SentinelAddrs: []string{"sentinel-0:26379"},
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "Should return error for Redis sentinel address without port",
+			config: &StorageConfig{
+				Type: HybridStorageType,
+				RedisConfig: &RedisConfig{
+					Mode:          RedisModeSentinel,
+					MasterName:    "mymaster",
+					SentinelAddrs: []string{"sentinel-0"},
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "Should return error for unknown Redis mode",
+			config: &StorageConfig{
+				Type: RedisStorageType,
+				RedisConfig: &RedisConfig{
+					Host: "localhost",
+					Port: "6379",
+					Mode: "cluster",
+				},
+			},
+			expectError: true,
+		},
+		{
+			name: "Should validate etcd config successfully",
+			config: &StorageConfig{
+				Type:       EtcdStorageType,
+				EtcdConfig: &EtcdConfig{Endpoints: []string{"http://etcd-0.etcd:2379", "https://etcd-1.etcd:2379"}},
+			},
+			expectError: false,
+		},
+		{
+			name: "Should return error for etcd without endpoints",
+			config: &StorageConfig{
+				Type:       EtcdStorageType,
+				EtcdConfig: &EtcdConfig{},
+			},
+			expectError: true,
+		},
+		{
+			name: "Should return error for etcd endpoint without scheme",
+			config: &StorageConfig{
+				Type:       EtcdStorageType,
+				EtcdConfig: &EtcdConfig{Endpoints: []string{"etcd-0.etcd:2379"}},
+			},
+			expectError: true,
+		},
+		{
+			name: "Should return error for etcd with sliding log",
+			config: &StorageConfig{
+				Type:       EtcdStorageType,
+				EtcdConfig: &EtcdConfig{Endpoints: []string{"http://etcd-0.etcd:2379"}},
+				Algorithm:  AlgorithmSlidingLog,
+			},
+			expectError: true,
+		},
+		{
+			name: "Should validate encrypted values on the fixed window",
+			config: &StorageConfig{
+				Type:        TieredStorageType,
+				RedisConfig: &RedisConfig{Host: "localhost", Port: "6379"},
+				Cipher:      testCipher,
+			},
+			expectError: false,
+		},
+		{
+			name: "Should return error for encrypted values with the sliding window",
+			config: &StorageConfig{
+				Type:        RedisStorageType,
+				RedisConfig: &RedisConfig{Host: "localhost", Port: "6379"},
+				Algorithm:   AlgorithmSlidingWindow,
+				Cipher:      testCipher,
+			},
+			expectError: true,
+		},
+		{
+			name: "Should return error for encrypted values with window history",
+			config: &StorageConfig{
+				Type:        RedisStorageType,
+				RedisConfig: &RedisConfig{Host: "localhost", Port: "6379"},
+				HistorySize: 5,
+				Cipher:      testCipher,
+			},
+			expectError: true,
+		},
+		{
+			name: "Should return error for encrypted values on etcd",
+			config: &StorageConfig{
+				Type:       EtcdStorageType,
+				EtcdConfig: &EtcdConfig{Endpoints: []string{"http://etcd-0.etcd:2379"}},
+				Cipher:     testCipher,
+			},
+			expectError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -250,9 +372,12 @@ func TestStorageFactory_GetSupportedTypes(t *testing.T) {
 	types := factory.GetSupportedTypes()
 
 	// Assert
-	assert.Len(t, types, 2)
+	assert.Len(t, types, 5)
 	assert.Contains(t, types, RedisStorageType)
 	assert.Contains(t, types, MemoryStorageType)
+	assert.Contains(t, types, HybridStorageType)
+	assert.Contains(t, types, TieredStorageType)
+	assert.Contains(t, types, EtcdStorageType)
 }
 
 func TestBuildStorageConfigFromEnv(t *testing.T) {
@@ -357,8 +482,8 @@ func TestCreateDefaultMemoryStorage(t *testing.T) {
 	// Verify it's actually MemoryStorage
 	memStorage, ok := storage.(*MemoryStorage)
 	assert.True(t, ok)
-	assert.NotNil(t, memStorage.data)
-	assert.NotNil(t, memStorage.blocks)
+	assert.NotNil(t, memStorage.shard("").data.index)
+	assert.NotNil(t, memStorage.shard("").blocks)
 }
 
 func TestCreateDefaultRedisStorage(t *testing.T) {
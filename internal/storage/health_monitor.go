@@ -0,0 +1,233 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"rate-limiter/internal/domain"
+)
+
+// Reconnector é implementado por storages capazes de recriar a conexão
+type Reconnector interface {
+	Reconnect(ctx context.Context) error
+}
+
+// CircuitState é o estado do storage visto como um circuit breaker
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"    // Saudável
+	CircuitOpen     CircuitState = "open"      // Degradado: o serviço falha rápido sem acessar o storage
+	CircuitHalfOpen CircuitState = "half_open" // Recuperado há menos de RecoveryChecks checks
+)
+
+// DefaultRecoveryChecks é quantos checks saudáveis após uma falha fecham o circuito
+const DefaultRecoveryChecks = 3
+
+// CircuitReporter informa o estado do circuito do storage
+type CircuitReporter interface {
+	CircuitState() CircuitState
+}
+
+// HealthMonitor verifica periodicamente a saúde do storage em background
+// Marca o storage como degradado em caso de falha e tenta reconectar com backoff exponencial
+type HealthMonitor struct {
+	storage    domain.RateLimiterStorage
+	logger     domain.Logger
+	interval   time.Duration
+	timeout    time.Duration
+	maxBackoff time.Duration
+
+	mutex          sync.RWMutex
+	health         domain.StorageHealth
+	recoveryChecks int
+	recovering     bool // Saudável de novo, ainda sem RecoveryChecks checks seguidos
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// NewHealthMonitor cria um novo monitor de saúde para o storage
+func NewHealthMonitor(storage domain.RateLimiterStorage, logger domain.Logger, interval, maxBackoff time.Duration) *HealthMonitor {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	if maxBackoff < interval {
+		maxBackoff = interval
+	}
+
+	return &HealthMonitor{
+		storage:    storage,
+		logger:     logger,
+		interval:   interval,
+		timeout:    interval,
+		maxBackoff: maxBackoff,
+		health:     domain.StorageHealth{Healthy: true},
+
+		recoveryChecks: DefaultRecoveryChecks,
+		stop:           make(chan struct{}),
+		done:           make(chan struct{}),
+	}
+}
+
+// SetRecoveryChecks define quantos checks saudáveis seguidos, após uma falha,
+// fecham o circuito (<= 0 = DefaultRecoveryChecks). Deve ser chamado antes do Start
+func (m *HealthMonitor) SetRecoveryChecks(checks int) {
+	if checks <= 0 {
+		checks = DefaultRecoveryChecks
+	}
+	m.recoveryChecks = checks
+}
+
+// Start executa a primeira verificação e inicia o loop em background
+func (m *HealthMonitor) Start() {
+	m.check()
+	go m.run()
+}
+
+// Stop encerra o loop de verificação
+func (m *HealthMonitor) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.stop)
+	})
+	<-m.done
+}
+
+// IsHealthy informa se o storage está saudável
+func (m *HealthMonitor) IsHealthy() bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.health.Healthy
+}
+
+// HealthStatus retorna o último estado observado do storage
+func (m *HealthMonitor) HealthStatus() domain.StorageHealth {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.health
+}
+
+// CircuitState implementa CircuitReporter a partir dos últimos checks
+func (m *HealthMonitor) CircuitState() CircuitState {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	switch {
+	case !m.health.Healthy:
+		return CircuitOpen
+	case m.recovering:
+		return CircuitHalfOpen
+	default:
+		return CircuitClosed
+	}
+}
+
+// run é o loop principal do monitor
+func (m *HealthMonitor) run() {
+	defer close(m.done)
+
+	timer := time.NewTimer(m.interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-timer.C:
+			if m.IsHealthy() {
+				m.check()
+			} else {
+				m.reconnect()
+			}
+			timer.Reset(m.nextDelay())
+		}
+	}
+}
+
+// check executa o health check e atualiza o estado
+func (m *HealthMonitor) check() bool {
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+
+	err := m.storage.Health(ctx)
+	m.record(err)
+	return err == nil
+}
+
+// reconnect tenta restabelecer a conexão com o storage
+func (m *HealthMonitor) reconnect() {
+	if reconnector, ok := m.storage.(Reconnector); ok {
+		ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+		err := reconnector.Reconnect(ctx)
+		cancel()
+
+		if err != nil {
+			m.record(err)
+			return
+		}
+	}
+
+	m.check()
+}
+
+// record registra o resultado de uma verificação e loga transições de estado
+func (m *HealthMonitor) record(err error) {
+	m.mutex.Lock()
+	wasHealthy := m.health.Healthy
+	m.health.LastCheck = time.Now()
+	if err != nil {
+		m.health.Healthy = false
+		m.health.LastError = err.Error()
+		m.health.ConsecutiveFailures++
+		m.health.ConsecutiveSuccesses = 0
+	} else {
+		m.health.Healthy = true
+		m.health.LastError = ""
+		m.health.ConsecutiveFailures = 0
+		m.health.ConsecutiveSuccesses++
+		if !wasHealthy {
+			m.recovering = true
+		}
+		if m.health.ConsecutiveSuccesses >= m.recoveryChecks {
+			m.recovering = false
+		}
+	}
+	health := m.health
+	m.mutex.Unlock()
+
+	if m.logger == nil {
+		return
+	}
+
+	if wasHealthy && !health.Healthy {
+		m.logger.Error("Storage marked as degraded", err, map[string]interface{}{
+			"consecutive_failures": health.ConsecutiveFailures,
+		})
+	} else if !wasHealthy && health.Healthy {
+		m.logger.Info("Storage recovered", nil)
+	} else if !health.Healthy {
+		m.logger.Warn("Storage still degraded", map[string]interface{}{
+			"consecutive_failures": health.ConsecutiveFailures,
+			"error":                health.LastError,
+		})
+	}
+}
+
+// nextDelay calcula o próximo intervalo, aplicando backoff exponencial quando degradado
+func (m *HealthMonitor) nextDelay() time.Duration {
+	status := m.HealthStatus()
+	if status.Healthy {
+		return m.interval
+	}
+
+	delay := m.interval
+	for i := 1; i < status.ConsecutiveFailures && delay < m.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > m.maxBackoff {
+		delay = m.maxBackoff
+	}
+	return delay
+}
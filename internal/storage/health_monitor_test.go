@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"rate-limiter/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// flakyStorage simula um storage cuja saúde pode ser alternada nos testes
+type flakyStorage struct {
+	*MemoryStorage
+	mutex      sync.Mutex
+	healthy    bool
+	reconnects int
+}
+
+func (f *flakyStorage) setHealthy(healthy bool) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.healthy = healthy
+}
+
+func (f *flakyStorage) Health(ctx context.Context) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if !f.healthy {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func (f *flakyStorage) Reconnect(ctx context.Context) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.reconnects++
+	return nil
+}
+
+func TestHealthMonitor_StateTransitions(t *testing.T) {
+	// Arrange
+	testLogger := logger.NewLogger("error", "text")
+	storage := &flakyStorage{MemoryStorage: NewMemoryStorage(testLogger), healthy: true}
+	monitor := NewHealthMonitor(storage, testLogger, 10*time.Millisecond, 40*time.Millisecond)
+
+	// Act & Assert - saudável na inicialização
+	monitor.Start()
+	defer monitor.Stop()
+	assert.True(t, monitor.IsHealthy())
+
+	// Storage falha: monitor marca como degradado
+	storage.setHealthy(false)
+	assert.Eventually(t, func() bool { return !monitor.IsHealthy() }, time.Second, 5*time.Millisecond)
+	assert.NotEmpty(t, monitor.HealthStatus().LastError)
+
+	// Storage volta: monitor reconecta e marca como saudável
+	storage.setHealthy(true)
+	assert.Eventually(t, monitor.IsHealthy, time.Second, 5*time.Millisecond)
+	assert.Equal(t, 0, monitor.HealthStatus().ConsecutiveFailures)
+
+	storage.mutex.Lock()
+	assert.Greater(t, storage.reconnects, 0)
+	storage.mutex.Unlock()
+}
+
+func TestHealthMonitor_NextDelay(t *testing.T) {
+	monitor := NewHealthMonitor(NewMemoryStorage(nil), nil, time.Second, 5*time.Second)
+
+	assert.Equal(t, time.Second, monitor.nextDelay())
+
+	tests := []struct {
+		failures int
+		expected time.Duration
+	}{
+		{failures: 1, expected: time.Second},
+		{failures: 2, expected: 2 * time.Second},
+		{failures: 3, expected: 4 * time.Second},
+		{failures: 10, expected: 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		monitor.health.Healthy = false
+		monitor.health.ConsecutiveFailures = tt.failures
+		assert.Equal(t, tt.expected, monitor.nextDelay())
+	}
+}
+
+// TestHealthMonitor_CircuitState testa o circuito half-open até RecoveryChecks checks saudáveis
+func TestHealthMonitor_CircuitState(t *testing.T) {
+	// Arrange
+	monitor := NewHealthMonitor(NewMemoryStorage(nil), nil, time.Second, 5*time.Second)
+	monitor.SetRecoveryChecks(2)
+
+	// Act & Assert
+	assert.Equal(t, CircuitClosed, monitor.CircuitState())
+
+	monitor.record(errors.New("connection refused"))
+	assert.Equal(t, CircuitOpen, monitor.CircuitState())
+
+	monitor.record(nil)
+	assert.Equal(t, CircuitHalfOpen, monitor.CircuitState())
+
+	monitor.record(nil)
+	assert.Equal(t, CircuitClosed, monitor.CircuitState())
+}
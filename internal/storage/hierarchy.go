@@ -0,0 +1,232 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"rate-limiter/internal/domain"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// BuildLevelKey constrói a chave do contador de um nível da hierarquia de limites
+// (ex: rate_limit:project:checkout)
+func BuildLevelKey(level, name string, opts ...KeyOption) string {
+	return "rate_limit:" + level + ":" + applyKeyOptions(name, opts)
+}
+
+// validateHierarchy rejeita hierarquias vazias ou com limites que nunca permitiriam
+func validateHierarchy(counters []domain.HierarchyCounter, window time.Duration) error {
+	if len(counters) == 0 || window <= 0 {
+		return fmt.Errorf("invalid limit hierarchy: %d levels, window %s", len(counters), window)
+	}
+	for _, counter := range counters {
+		if counter.Limit < 1 {
+			return fmt.Errorf("invalid limit hierarchy: limit %d for key %s", counter.Limit, counter.Key)
+		}
+	}
+	return nil
+}
+
+// IncrementHierarchy implementa domain.HierarchicalIncrementer sob o write lock
+// das partições de todos os níveis
+func (m *MemoryStorage) IncrementHierarchy(ctx context.Context, counters []domain.HierarchyCounter, window time.Duration) (*domain.HierarchyResult, error) {
+	if err := validateHierarchy(counters, window); err != nil {
+		return nil, err
+	}
+	ctx, span := startSpan(ctx, MemoryStorageType, "INCREMENT_HIERARCHY", counters[0].Key)
+	defer span.End()
+
+	start := time.Now()
+
+	keys := make([]string, len(counters))
+	for i, counter := range counters {
+		keys[i] = counter.Key
+	}
+	unlock := m.lockKeys(ctx, keys)
+	defer unlock()
+
+	now := m.now().UnixNano()
+	records := make([]*memoryRecord, len(counters))
+	result := &domain.HierarchyResult{
+		Counts:   make([]int, len(counters)),
+		ResetAt:  make([]time.Time, len(counters)),
+		Exceeded: -1,
+	}
+
+	for i, counter := range counters {
+		shard := m.shard(counter.Key)
+		record, exists := shard.data.get(counter.Key)
+		if exists {
+			m.touch(counter.Key)
+		} else {
+			record = m.insert(shard, counter.Key, memoryRecord{window: clampInt32(int(window.Seconds())), lastReset: now})
+		}
+		if time.Duration(now-record.lastReset) >= window {
+			m.recordWindow(shard, counter.Key, record, window)
+			record.count = 0
+			record.lastReset = now
+			record.blocked = false
+			record.blockedUntil = 0
+			delete(shard.blocks, counter.Key)
+		}
+		record.limit = clampInt32(counter.Limit)
+		records[i] = record
+
+		if result.Exceeded < 0 && record.count >= clampInt32(counter.Limit) {
+			result.Exceeded = i
+		}
+	}
+
+	for i, record := range records {
+		if result.Exceeded < 0 {
+			record.increment()
+		}
+		result.Counts[i] = int(record.count)
+		result.ResetAt[i] = fromUnixNano(record.lastReset).Add(window)
+	}
+
+	m.logStorageOperation(ctx, "INCREMENT_HIERARCHY", counters[0].Key, true, time.Since(start).Seconds()*1000, nil)
+	return result, nil
+}
+
+// hierarchySource confere e incrementa os contadores de todos os níveis, no
+// mesmo formato JSON do incrementSource. KEYS são os contadores do token ao
+// topo, ARGV[1] a janela (ms), ARGV[2] o instante atual e ARGV[3..] os limites.
+// Retorna {primeiro nível sem espaço (0 = nenhum), contagem e início da janela de cada nível}
+const hierarchySource = `
+	local window = tonumber(ARGV[1])
+	local now = tonumber(ARGV[2])
+
+	local records = {}
+	local exceeded = 0
+	for i, key in ipairs(KEYS) do
+		local limit = tonumber(ARGV[i + 2])
+		local current = redis.call('GET', key)
+		local data
+		if current then
+			data = cjson.decode(current)
+		else
+			data = {
+				key = key,
+				type = '',
+				count = 0,
+				window = math.floor(window / 1000),
+				lastReset = now,
+				isBlocked = false
+			}
+		end
+
+		if now - data.lastReset >= window then
+			data.count = 0
+			data.lastReset = now
+			data.isBlocked = false
+		end
+		data.limit = limit
+		records[i] = data
+
+		if exceeded == 0 and data.count >= limit then
+			exceeded = i
+		end
+	end
+
+	local result = {exceeded}
+	for i, data in ipairs(records) do
+		if exceeded == 0 then
+			data.count = data.count + 1
+
+			-- Preserva o TTL de um bloqueio mais longo que a janela
+			local ttl = window - (now - data.lastReset)
+			local current = redis.call('PTTL', KEYS[i])
+			if current > ttl then
+				ttl = current
+			end
+			redis.call('SET', KEYS[i], cjson.encode(data), 'PX', ttl)
+		end
+		table.insert(result, data.count)
+		table.insert(result, data.lastReset)
+	end
+	return result
+`
+
+var hierarchyScript = redis.NewScript(hierarchySource)
+
+// IncrementHierarchy implementa domain.HierarchicalIncrementer em um único script.
+// No Redis Cluster, as chaves dos níveis precisam estar no mesmo slot
+func (r *RedisStorage) IncrementHierarchy(ctx context.Context, counters []domain.HierarchyCounter, window time.Duration) (*domain.HierarchyResult, error) {
+	if err := validateHierarchy(counters, window); err != nil {
+		return nil, err
+	}
+	key := counters[0].Key
+	ctx, span := startSpan(ctx, RedisStorageType, "INCREMENT_HIERARCHY", key)
+	defer span.End()
+
+	start := time.Now()
+
+	if r.cipher != nil {
+		result, err := r.incrementHierarchySealed(ctx, counters, window)
+		if err != nil {
+			r.logStorageOperation(ctx, "INCREMENT_HIERARCHY", key, false, time.Since(start).Seconds()*1000, err)
+			return nil, fmt.Errorf("failed to increment limit hierarchy for key %s: %w", key, err)
+		}
+		r.logStorageOperation(ctx, "INCREMENT_HIERARCHY", key, true, time.Since(start).Seconds()*1000, nil)
+		return result, nil
+	}
+
+	keys := make([]string, len(counters))
+	args := []interface{}{window.Milliseconds(), time.Now().UnixMilli()}
+	for i, counter := range counters {
+		keys[i] = counter.Key
+		args = append(args, counter.Limit)
+	}
+
+	value, err := r.eval(ctx, "hierarchy", hierarchyScript, keys, args...)
+	if err != nil {
+		r.logStorageOperation(ctx, "INCREMENT_HIERARCHY", key, false, time.Since(start).Seconds()*1000, err)
+		return nil, fmt.Errorf("failed to increment limit hierarchy for key %s: %w", key, err)
+	}
+
+	values, ok := value.([]interface{})
+	if !ok || len(values) != 1+2*len(counters) {
+		err := fmt.Errorf("invalid limit hierarchy result for key %s", key)
+		r.logStorageOperation(ctx, "INCREMENT_HIERARCHY", key, false, time.Since(start).Seconds()*1000, err)
+		return nil, err
+	}
+
+	numbers := make([]int64, len(values))
+	for i, item := range values {
+		if numbers[i], err = strconv.ParseInt(fmt.Sprint(item), 10, 64); err != nil {
+			r.logStorageOperation(ctx, "INCREMENT_HIERARCHY", key, false, time.Since(start).Seconds()*1000, err)
+			return nil, fmt.Errorf("invalid limit hierarchy result for key %s: %w", key, err)
+		}
+	}
+
+	result := &domain.HierarchyResult{
+		Counts:   make([]int, len(counters)),
+		ResetAt:  make([]time.Time, len(counters)),
+		Exceeded: int(numbers[0]) - 1,
+	}
+	for i := range counters {
+		result.Counts[i] = int(numbers[1+2*i])
+		result.ResetAt[i] = time.UnixMilli(numbers[2+2*i]).Add(window)
+	}
+
+	r.logStorageOperation(ctx, "INCREMENT_HIERARCHY", key, true, time.Since(start).Seconds()*1000, nil)
+	return result, nil
+}
+
+// IncrementHierarchy implementa domain.HierarchicalIncrementer quando o storage interno o suporta
+func (p *PrefixedStorage) IncrementHierarchy(ctx context.Context, counters []domain.HierarchyCounter, window time.Duration) (*domain.HierarchyResult, error) {
+	incrementer, ok := p.inner.(domain.HierarchicalIncrementer)
+	if !ok {
+		return nil, domain.ErrHierarchyUnsupported
+	}
+
+	prefixed := make([]domain.HierarchyCounter, len(counters))
+	for i, counter := range counters {
+		prefixed[i] = domain.HierarchyCounter{Key: p.prefix + counter.Key, Limit: counter.Limit}
+	}
+	return incrementer.IncrementHierarchy(ctx, prefixed, window)
+}
@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/logger"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hierarchyStorages retorna os backends que incrementam a hierarquia atomicamente
+func hierarchyStorages(t *testing.T) map[string]domain.HierarchicalIncrementer {
+	t.Helper()
+
+	client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	redisStorage := NewRedisStorageWithClient(client, logger.NewNopLogger())
+	memory := NewMemoryStorage(nil)
+	sealed, _ := newSealedStorage(t)
+	t.Cleanup(func() {
+		redisStorage.Close()
+		memory.Close()
+	})
+
+	return map[string]domain.HierarchicalIncrementer{
+		"memory":       memory,
+		"redis":        redisStorage,
+		"redis_sealed": sealed,
+	}
+}
+
+// TestIncrementHierarchy_AllOrNothing testa que um nível sem espaço não deixa nenhum contador subir
+func TestIncrementHierarchy_AllOrNothing(t *testing.T) {
+	for name, storage := range hierarchyStorages(t) {
+		t.Run(name, func(t *testing.T) {
+			// Arrange: dois tokens de limite 5 em um projeto de limite 3
+			ctx := context.Background()
+			project := domain.HierarchyCounter{Key: BuildLevelKey(domain.LimitLevelProject, "checkout"), Limit: 3}
+			tokenA := domain.HierarchyCounter{Key: "rate_limit:token:a", Limit: 5}
+			tokenB := domain.HierarchyCounter{Key: "rate_limit:token:b", Limit: 5}
+			for i := 0; i < 2; i++ {
+				_, err := storage.IncrementHierarchy(ctx, []domain.HierarchyCounter{tokenA, project}, time.Minute)
+				require.NoError(t, err)
+			}
+
+			// Act
+			allowed, err := storage.IncrementHierarchy(ctx, []domain.HierarchyCounter{tokenB, project}, time.Minute)
+			require.NoError(t, err)
+			denied, err := storage.IncrementHierarchy(ctx, []domain.HierarchyCounter{tokenB, project}, time.Minute)
+			require.NoError(t, err)
+
+			// Assert
+			assert.Equal(t, -1, allowed.Exceeded)
+			assert.Equal(t, []int{1, 3}, allowed.Counts)
+			assert.Equal(t, 1, denied.Exceeded, "the project budget is shared by both tokens")
+			assert.Equal(t, []int{1, 3}, denied.Counts, "a denied request must not count at any level")
+			assert.WithinDuration(t, time.Now().Add(time.Minute), denied.ResetAt[1], 2*time.Second)
+		})
+	}
+}
+
+// TestIncrementHierarchy_WindowReset testa o recomeço dos contadores quando a janela acaba
+func TestIncrementHierarchy_WindowReset(t *testing.T) {
+	for name, storage := range hierarchyStorages(t) {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			ctx := context.Background()
+			counters := []domain.HierarchyCounter{
+				{Key: "rate_limit:token:a", Limit: 1},
+				{Key: BuildLevelKey(domain.LimitLevelOrganization, "acme"), Limit: 10},
+			}
+			_, err := storage.IncrementHierarchy(ctx, counters, 50*time.Millisecond)
+			require.NoError(t, err)
+			time.Sleep(60 * time.Millisecond)
+
+			// Act
+			result, err := storage.IncrementHierarchy(ctx, counters, 50*time.Millisecond)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, -1, result.Exceeded)
+			assert.Equal(t, []int{1, 1}, result.Counts)
+		})
+	}
+}
+
+func TestPrefixedStorage_IncrementHierarchy(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	memory := NewMemoryStorage(nil)
+	defer memory.Close()
+	prefixed := NewPrefixedStorage(memory, "tenant-a:")
+
+	// Act
+	_, err := prefixed.IncrementHierarchy(ctx, []domain.HierarchyCounter{{Key: "rate_limit:token:a", Limit: 5}}, time.Minute)
+	require.NoError(t, err)
+	status, getErr := memory.Get(ctx, "tenant-a:rate_limit:token:a")
+
+	// Assert
+	require.NoError(t, getErr)
+	require.NotNil(t, status)
+	assert.Equal(t, 1, status.Count)
+}
@@ -0,0 +1,338 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"rate-limiter/internal/domain"
+)
+
+const (
+	HybridStorageType StorageType = "hybrid"
+
+	// DefaultHybridSyncInterval é o intervalo padrão entre sincronizações com o Redis
+	DefaultHybridSyncInterval = 200 * time.Millisecond
+	// DefaultHybridBatchSize é o número padrão de chaves por round trip
+	DefaultHybridBatchSize = 500
+)
+
+// HybridConfig contém configurações específicas do storage híbrido
+type HybridConfig struct {
+	SyncInterval time.Duration // Intervalo entre sincronizações (0 = DefaultHybridSyncInterval)
+	BatchSize    int           // Chaves por pipeline no Redis (0 = DefaultHybridBatchSize)
+}
+
+// pendingCounter acumula os incrementos locais de uma chave ainda não enviados ao Redis
+type pendingCounter struct {
+	delta  atomic.Int64
+	oldest atomic.Int64 // Unix nanossegundos do incremento mais antigo na fila
+	limit  int
+	window time.Duration
+}
+
+// HybridStorage decide com os contadores em memória e sincroniza os incrementos
+// com o Redis em lotes, em background. Cada sincronização soma os incrementos
+// locais ao contador global e traz de volta o total de todas as instâncias.
+// O erro de precisão fica limitado ao tráfego das outras instâncias durante um
+// intervalo de sincronização, em troca de nenhuma chamada ao Redis por requisição
+type HybridStorage struct {
+	local    *MemoryStorage
+	remote   *RedisStorage
+	logger   domain.Logger
+	interval time.Duration
+	batch    int
+
+	mutex   sync.RWMutex
+	pending map[string]*pendingCounter
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// NewHybridStorage cria o storage híbrido e inicia a sincronização periódica
+func NewHybridStorage(local *MemoryStorage, remote *RedisStorage, config HybridConfig, logger domain.Logger) *HybridStorage {
+	if config.SyncInterval <= 0 {
+		config.SyncInterval = DefaultHybridSyncInterval
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultHybridBatchSize
+	}
+
+	storage := &HybridStorage{
+		local:    local,
+		remote:   remote,
+		logger:   logger,
+		interval: config.SyncInterval,
+		batch:    config.BatchSize,
+		pending:  make(map[string]*pendingCounter),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	go storage.run()
+
+	if logger != nil {
+		logger.Info("Hybrid storage initialized", map[string]interface{}{
+			"sync_interval_ms": config.SyncInterval.Milliseconds(),
+			"batch_size":       config.BatchSize,
+		})
+	}
+
+	return storage
+}
+
+// Get retorna a visão local da chave
+func (h *HybridStorage) Get(ctx context.Context, key string) (*domain.RateLimitStatus, error) {
+	return h.local.Get(ctx, key)
+}
+
+// Set grava o status localmente e no Redis
+func (h *HybridStorage) Set(ctx context.Context, key string, status *domain.RateLimitStatus, ttl time.Duration) error {
+	if err := h.local.Set(ctx, key, status, ttl); err != nil {
+		return err
+	}
+	return h.remote.Set(ctx, key, status, ttl)
+}
+
+// Increment decide pelo contador local e agenda o incremento para a próxima sincronização
+func (h *HybridStorage) Increment(ctx context.Context, key string, limit int, window time.Duration) (int, time.Time, error) {
+	count, lastReset, err := h.local.Increment(ctx, key, limit, window)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+
+	h.addPending(key, 1, limit, window, time.Now().UnixNano())
+	return count, lastReset, nil
+}
+
+// IncrementQuota vai direto ao Redis: cotas têm períodos longos e precisam do total exato
+func (h *HybridStorage) IncrementQuota(ctx context.Context, key string, period domain.QuotaPeriod) (*domain.RateLimitStatus, error) {
+	return h.remote.IncrementQuota(ctx, key, period)
+}
+
+// TakeToken vai direto ao Redis: o balde reposto continuamente não se divide em lotes
+func (h *HybridStorage) TakeToken(ctx context.Context, key string, bucket domain.TokenBucket) (*domain.RateLimitStatus, error) {
+	return h.remote.TakeToken(ctx, key, bucket)
+}
+
+// Leak vai direto ao Redis: a fila do balde precisa ser a mesma em todas as instâncias
+func (h *HybridStorage) Leak(ctx context.Context, key string, bucket domain.LeakyBucket) (*domain.RateLimitStatus, error) {
+	return h.remote.Leak(ctx, key, bucket)
+}
+
+// IsBlocked consulta os bloqueios locais, que incluem os trazidos pela sincronização
+func (h *HybridStorage) IsBlocked(ctx context.Context, key string) (bool, *time.Time, error) {
+	return h.local.IsBlocked(ctx, key)
+}
+
+// Block bloqueia localmente e no Redis, para que as outras instâncias o recebam
+func (h *HybridStorage) Block(ctx context.Context, key string, duration time.Duration) error {
+	if err := h.local.Block(ctx, key, duration); err != nil {
+		return err
+	}
+	return h.remote.Block(ctx, key, duration)
+}
+
+// Reset descarta os incrementos pendentes e limpa a chave nos dois storages
+func (h *HybridStorage) Reset(ctx context.Context, key string) error {
+	h.mutex.Lock()
+	delete(h.pending, key)
+	h.mutex.Unlock()
+
+	if err := h.local.Reset(ctx, key); err != nil {
+		return err
+	}
+	return h.remote.Reset(ctx, key)
+}
+
+// Inspect implementa domain.StorageInspector com o registro durável do Redis
+func (h *HybridStorage) Inspect(ctx context.Context, key string) (*domain.StorageRecord, error) {
+	return h.remote.Inspect(ctx, key)
+}
+
+// Health reflete o Redis: sem ele as decisões continuam locais, mas não sincronizam
+func (h *HybridStorage) Health(ctx context.Context) error {
+	return h.remote.Health(ctx)
+}
+
+// Reconnect implementa Reconnector recriando a conexão com o Redis
+func (h *HybridStorage) Reconnect(ctx context.Context) error {
+	return h.remote.Reconnect(ctx)
+}
+
+// Close encerra a sincronização, envia os incrementos pendentes e fecha os storages
+func (h *HybridStorage) Close() error {
+	h.stopOnce.Do(func() {
+		close(h.stop)
+	})
+	<-h.done
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := h.Sync(ctx); err != nil && h.logger != nil {
+		h.logger.Error("Failed to sync pending counters on close", err, nil)
+	}
+
+	h.local.Close()
+	return h.remote.Close()
+}
+
+// Pending retorna quantos incrementos locais ainda não chegaram ao Redis
+func (h *HybridStorage) Pending() int64 {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	var total int64
+	for _, counter := range h.pending {
+		total += counter.delta.Load()
+	}
+	return total
+}
+
+// Sync envia os incrementos pendentes ao Redis e atualiza os contadores locais
+// com o total global. Em falha, os incrementos voltam para a fila; os que ficarem
+// mais antigos que a própria janela são descartados no próximo Sync
+func (h *HybridStorage) Sync(ctx context.Context) error {
+	deltas, oldest := h.drainPending()
+
+	var firstErr error
+	for start := 0; start < len(deltas); start += h.batch {
+		end := start + h.batch
+		if end > len(deltas) {
+			end = len(deltas)
+		}
+		batch := deltas[start:end]
+
+		snapshots, err := h.remote.MergeCounters(ctx, batch)
+		if err != nil {
+			for _, delta := range batch {
+				h.addPending(delta.Key, int64(delta.Delta), delta.Limit, delta.Window, oldest[delta.Key])
+			}
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to sync %d counters: %w", len(batch), err)
+			}
+			continue
+		}
+
+		for i, snapshot := range snapshots {
+			h.local.mergeCounter(snapshot, h.pendingFor(snapshot.Key), batch[i].Limit, batch[i].Window)
+		}
+	}
+
+	return firstErr
+}
+
+// addPending soma incrementos à fila da chave. O read lock impede que uma
+// sincronização remova a entrada enquanto ela recebe o incremento
+func (h *HybridStorage) addPending(key string, delta int64, limit int, window time.Duration, at int64) {
+	h.mutex.RLock()
+	counter, exists := h.pending[key]
+	if exists {
+		counter.add(delta, at)
+		h.mutex.RUnlock()
+		return
+	}
+	h.mutex.RUnlock()
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	counter, exists = h.pending[key]
+	if !exists {
+		counter = &pendingCounter{limit: limit, window: window}
+		h.pending[key] = counter
+	}
+	counter.add(delta, at)
+}
+
+// add soma os incrementos mantendo o instante do mais antigo
+func (c *pendingCounter) add(delta, at int64) {
+	c.delta.Add(delta)
+	for {
+		oldest := c.oldest.Load()
+		if oldest != 0 && oldest <= at {
+			return
+		}
+		if c.oldest.CompareAndSwap(oldest, at) {
+			return
+		}
+	}
+}
+
+// drainPending zera a fila e retorna os incrementos acumulados por chave, com
+// o instante do mais antigo de cada uma. Incrementos de janelas já encerradas
+// (acumulados durante uma falha do Redis) são descartados
+func (h *HybridStorage) drainPending() ([]CounterDelta, map[string]int64) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	now := time.Now().UnixNano()
+	deltas := make([]CounterDelta, 0, len(h.pending))
+	oldest := make(map[string]int64, len(h.pending))
+	var dropped int64
+	for key, counter := range h.pending {
+		delta := counter.delta.Swap(0)
+		since := counter.oldest.Swap(0)
+		if delta == 0 {
+			// Chave sem tráfego desde a última sincronização sai da fila
+			delete(h.pending, key)
+			continue
+		}
+		if time.Duration(now-since) >= counter.window {
+			dropped += delta
+			continue
+		}
+		deltas = append(deltas, CounterDelta{
+			Key:    key,
+			Delta:  int(delta),
+			Limit:  counter.limit,
+			Window: counter.window,
+		})
+		oldest[key] = since
+	}
+
+	if dropped > 0 && h.logger != nil {
+		h.logger.Warn("Dropped unsynced counters from expired windows", map[string]interface{}{
+			"increments": dropped,
+		})
+	}
+	return deltas, oldest
+}
+
+// pendingFor retorna os incrementos da chave recebidos depois do último drain
+func (h *HybridStorage) pendingFor(key string) int64 {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	if counter, exists := h.pending[key]; exists {
+		return counter.delta.Load()
+	}
+	return 0
+}
+
+func (h *HybridStorage) run() {
+	defer close(h.done)
+
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-h.stop:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), h.interval*5)
+			if err := h.Sync(ctx); err != nil && h.logger != nil {
+				h.logger.Warn("Hybrid storage sync failed, counters kept for the next attempt", map[string]interface{}{
+					"error":   err.Error(),
+					"pending": h.Pending(),
+				})
+			}
+			cancel()
+		}
+	}
+}
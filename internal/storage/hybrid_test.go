@@ -0,0 +1,156 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"rate-limiter/internal/logger"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newHybridInstance cria uma instância híbrida sobre o miniredis compartilhado.
+// O intervalo longo deixa a sincronização sob controle do teste
+func newHybridInstance(t *testing.T, server *miniredis.Miniredis) *HybridStorage {
+	t.Helper()
+
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	remote := NewRedisStorageWithClient(client, logger.NewNopLogger())
+	storage := NewHybridStorage(NewMemoryStorage(logger.NewNopLogger()), remote, HybridConfig{SyncInterval: time.Hour}, logger.NewNopLogger())
+	t.Cleanup(func() { storage.Close() })
+	return storage
+}
+
+// TestHybridStorage_Sync testa a soma dos incrementos de duas instâncias no Redis
+func TestHybridStorage_Sync(t *testing.T) {
+	// Arrange
+	server := miniredis.RunT(t)
+	first := newHybridInstance(t, server)
+	second := newHybridInstance(t, server)
+	ctx := context.Background()
+	key := "rate_limit:ip:10.0.0.1"
+
+	for i := 0; i < 3; i++ {
+		_, _, err := first.Increment(ctx, key, 10, time.Minute)
+		require.NoError(t, err)
+	}
+	for i := 0; i < 2; i++ {
+		_, _, err := second.Increment(ctx, key, 10, time.Minute)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int64(3), first.Pending())
+
+	// Act
+	require.NoError(t, first.Sync(ctx))
+	require.NoError(t, second.Sync(ctx))
+
+	// Assert: a segunda instância recebe o total global
+	assert.Equal(t, int64(0), second.Pending())
+	status, err := second.Get(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, 5, status.Count)
+
+	// A primeira recebe o total na próxima sincronização com tráfego local
+	count, _, err := first.Increment(ctx, key, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 4, count)
+	require.NoError(t, first.Sync(ctx))
+
+	status, err = first.Get(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, 6, status.Count)
+	remote, err := first.remote.Get(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, 6, remote.Count)
+}
+
+// TestHybridStorage_SyncBlocks testa o bloqueio por excesso do total global e por Block em outra instância
+func TestHybridStorage_SyncBlocks(t *testing.T) {
+	// Arrange
+	server := miniredis.RunT(t)
+	first := newHybridInstance(t, server)
+	second := newHybridInstance(t, server)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, _, err := first.Increment(ctx, "rate_limit:ip:10.0.0.1", 3, time.Minute)
+		require.NoError(t, err)
+		_, _, err = second.Increment(ctx, "rate_limit:ip:10.0.0.1", 3, time.Minute)
+		require.NoError(t, err)
+	}
+	_, _, err := second.Increment(ctx, "rate_limit:token:abc123", 10, time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, first.Block(ctx, "rate_limit:token:abc123", time.Minute))
+
+	// Act
+	require.NoError(t, first.Sync(ctx))
+	require.NoError(t, second.Sync(ctx))
+
+	// Assert
+	status, err := second.Get(ctx, "rate_limit:ip:10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, 4, status.Count)
+	assert.True(t, status.IsBlocked, "the global count is above the limit")
+
+	blocked, blockedUntil, err := second.IsBlocked(ctx, "rate_limit:token:abc123")
+	require.NoError(t, err)
+	assert.True(t, blocked, "blocks from other instances must reach the local storage")
+	require.NotNil(t, blockedUntil)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), *blockedUntil, time.Second)
+}
+
+// TestHybridStorage_SyncFailure testa a retenção dos incrementos enquanto o Redis está fora
+func TestHybridStorage_SyncFailure(t *testing.T) {
+	// Arrange
+	server := miniredis.RunT(t)
+	storage := newHybridInstance(t, server)
+	ctx := context.Background()
+	key := "rate_limit:ip:10.0.0.1"
+
+	for i := 0; i < 3; i++ {
+		_, _, err := storage.Increment(ctx, key, 10, time.Minute)
+		require.NoError(t, err)
+	}
+
+	// Act
+	server.Close()
+	err := storage.Sync(ctx)
+
+	// Assert: as decisões continuam locais e os incrementos voltam para a fila
+	assert.Error(t, err)
+	assert.Equal(t, int64(3), storage.Pending())
+	count, _, err := storage.Increment(ctx, key, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 4, count)
+
+	require.NoError(t, server.Restart())
+	require.NoError(t, storage.Sync(ctx))
+	assert.Equal(t, int64(0), storage.Pending())
+	remote, err := storage.remote.Get(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, 4, remote.Count)
+}
+
+// TestHybridStorage_DropsExpiredWindows testa o descarte de incrementos de janelas já encerradas
+func TestHybridStorage_DropsExpiredWindows(t *testing.T) {
+	// Arrange
+	server := miniredis.RunT(t)
+	storage := newHybridInstance(t, server)
+	ctx := context.Background()
+	key := "rate_limit:ip:10.0.0.1"
+
+	_, _, err := storage.Increment(ctx, key, 10, 50*time.Millisecond)
+	require.NoError(t, err)
+	time.Sleep(100 * time.Millisecond)
+
+	// Act
+	require.NoError(t, storage.Sync(ctx))
+
+	// Assert
+	assert.False(t, server.Exists(key))
+	assert.Equal(t, int64(0), storage.Pending())
+}
@@ -0,0 +1,197 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"rate-limiter/internal/domain"
+)
+
+// minScanCount é o COUNT mínimo de cada SCAN: com poucas chaves do prefixo no
+// keyspace, valores pequenos exigiriam muitas idas ao Redis por página
+const minScanCount = 100
+
+// ListKeys implementa domain.KeyLister. A ordem dos mapas é aleatória, então as
+// chaves do prefixo são ordenadas e o cursor é a posição da próxima página (a chave
+// não serve de cursor: exporia o token). Como no SCAN, chaves criadas ou removidas
+// durante a listagem podem deslocar as páginas
+func (m *MemoryStorage) ListKeys(ctx context.Context, prefix, cursor string, count int) ([]*domain.RateLimitStatus, string, error) {
+	ctx, span := startSpan(ctx, MemoryStorageType, "LIST_KEYS", prefix)
+	defer span.End()
+
+	offset, err := parseCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	start := time.Now()
+	now := m.now().UnixNano()
+
+	var keys []string
+	for _, shard := range m.shards {
+		m.rlockShard(ctx, shard)
+		for key := range shard.data.index {
+			if strings.HasPrefix(key, prefix) {
+				keys = append(keys, key)
+			}
+		}
+		// Chaves só com bloqueio (ex: POST /admin/status) também são rastreadas
+		for key, blockedUntil := range shard.blocks {
+			if _, exists := shard.data.get(key); !exists && now < blockedUntil && strings.HasPrefix(key, prefix) {
+				keys = append(keys, key)
+			}
+		}
+		shard.mutex.RUnlock()
+	}
+	sort.Strings(keys)
+
+	next := ""
+	if offset >= uint64(len(keys)) {
+		keys = nil
+	} else {
+		keys = keys[offset:]
+	}
+	if count > 0 && len(keys) > count {
+		keys = keys[:count]
+		next = strconv.FormatUint(offset+uint64(count), 10)
+	}
+
+	statuses := make([]*domain.RateLimitStatus, 0, len(keys))
+	for _, key := range keys {
+		shard := m.rlock(ctx, key)
+		status := &domain.RateLimitStatus{Key: key}
+		record, exists := shard.data.get(key)
+		if exists {
+			status = record.status(key)
+			if status.BlockedUntil != nil && now >= status.BlockedUntil.UnixNano() {
+				status.IsBlocked = false
+			}
+		}
+		if blockedUntil, blocked := shard.blocks[key]; blocked && now < blockedUntil {
+			until := fromUnixNano(blockedUntil)
+			status.IsBlocked = true
+			status.BlockedUntil = &until
+			exists = true
+		}
+		shard.mutex.RUnlock()
+
+		// Removida entre a coleta e a leitura
+		if exists {
+			statuses = append(statuses, status)
+		}
+	}
+
+	m.logStorageOperation(ctx, "LIST_KEYS", prefix, true, time.Since(start).Seconds()*1000, nil)
+	return statuses, next, nil
+}
+
+// ListKeys implementa domain.KeyLister com SCAN; o cursor é o do Redis. SCAN é
+// aproximado: uma página pode passar de count chaves, e chaves criadas ou
+// removidas durante a listagem podem ou não aparecer
+func (r *RedisStorage) ListKeys(ctx context.Context, prefix, cursor string, count int) ([]*domain.RateLimitStatus, string, error) {
+	ctx, span := startSpan(ctx, RedisStorageType, "LIST_KEYS", prefix)
+	defer span.End()
+
+	start := time.Now()
+
+	position, err := parseCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	scanCount := int64(count)
+	if scanCount < minScanCount {
+		scanCount = minScanCount
+	}
+
+	var keys []string
+	for {
+		batch, next, err := r.getClient().Scan(ctx, position, prefix+"*", scanCount).Result()
+		if err != nil {
+			r.logStorageOperation(ctx, "LIST_KEYS", prefix, false, time.Since(start).Seconds()*1000, err)
+			return nil, "", fmt.Errorf("failed to scan keys %s*: %w", prefix, err)
+		}
+		for _, key := range batch {
+			// O log do sliding window log e o histórico de janelas pertencem à chave principal
+			if !strings.HasSuffix(key, slidingLogSuffix) && !strings.HasSuffix(key, windowHistorySuffix) {
+				keys = append(keys, key)
+			}
+		}
+		position = next
+		if position == 0 || len(keys) >= count {
+			break
+		}
+	}
+
+	statuses := make([]*domain.RateLimitStatus, 0, len(keys))
+	if len(keys) > 0 {
+		values, err := r.getClient().MGet(ctx, keys...).Result()
+		if err != nil {
+			r.logStorageOperation(ctx, "LIST_KEYS", prefix, false, time.Since(start).Seconds()*1000, err)
+			return nil, "", fmt.Errorf("failed to read keys %s*: %w", prefix, err)
+		}
+		for i, value := range values {
+			// nil: expirou depois do SCAN ou não é uma string
+			raw, ok := value.(string)
+			if !ok {
+				continue
+			}
+			status, err := r.decodeStatus(keys[i], []byte(raw))
+			if err != nil {
+				continue
+			}
+			status.Key = keys[i]
+			if status.BlockedUntil != nil && !time.Now().Before(*status.BlockedUntil) {
+				status.IsBlocked = false
+			}
+			statuses = append(statuses, status)
+		}
+	}
+
+	next := ""
+	if position != 0 {
+		next = strconv.FormatUint(position, 10)
+	}
+
+	r.logStorageOperation(ctx, "LIST_KEYS", prefix, true, time.Since(start).Seconds()*1000, nil)
+	return statuses, next, nil
+}
+
+// parseCursor lê o cursor numérico da listagem; vazio é o início
+func parseCursor(cursor string) (uint64, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	position, err := strconv.ParseUint(cursor, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", domain.ErrInvalidCursor, cursor)
+	}
+	return position, nil
+}
+
+// ListKeys implementa domain.KeyLister com os contadores duráveis do Redis; os
+// incrementos locais ainda não sincronizados não entram na contagem
+func (h *HybridStorage) ListKeys(ctx context.Context, prefix, cursor string, count int) ([]*domain.RateLimitStatus, string, error) {
+	return h.remote.ListKeys(ctx, prefix, cursor, count)
+}
+
+// ListKeys implementa domain.KeyLister quando o storage interno o suporta,
+// retornando as chaves sem o prefixo da visão
+func (p *PrefixedStorage) ListKeys(ctx context.Context, prefix, cursor string, count int) ([]*domain.RateLimitStatus, string, error) {
+	lister, ok := p.inner.(domain.KeyLister)
+	if !ok {
+		return nil, "", domain.ErrListingUnsupported
+	}
+	statuses, next, err := lister.ListKeys(ctx, p.prefix+prefix, cursor, count)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, status := range statuses {
+		status.Key = strings.TrimPrefix(status.Key, p.prefix)
+	}
+	return statuses, next, nil
+}
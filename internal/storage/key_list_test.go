@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/logger"
+)
+
+func TestKeyLister(t *testing.T) {
+	storages := map[string]func(t *testing.T) domain.RateLimiterStorage{
+		"memory": func(t *testing.T) domain.RateLimiterStorage {
+			return NewMemoryStorage(nil)
+		},
+		"redis": func(t *testing.T) domain.RateLimiterStorage {
+			client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+			return NewRedisStorageWithClient(client, logger.NewNopLogger())
+		},
+	}
+
+	for name, create := range storages {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			storage := create(t)
+			defer storage.Close()
+			ctx := context.Background()
+			for _, ip := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"} {
+				_, _, err := storage.Increment(ctx, BuildKey(domain.IPLimiter, ip), 10, time.Minute)
+				require.NoError(t, err)
+			}
+			require.NoError(t, storage.Block(ctx, BuildKey(domain.IPLimiter, "10.0.0.4"), time.Minute))
+			_, _, err := storage.Increment(ctx, BuildKey(domain.TokenLimiter, "abc123"), 10, time.Minute)
+			require.NoError(t, err)
+			lister := storage.(domain.KeyLister)
+
+			// Act: percorre as páginas de 2 chaves até o cursor vazio
+			statuses := make(map[string]*domain.RateLimitStatus)
+			cursor := ""
+			for pages := 0; pages < 10; pages++ {
+				page, next, err := lister.ListKeys(ctx, KeyPrefix(domain.IPLimiter), cursor, 2)
+				require.NoError(t, err)
+				for _, status := range page {
+					statuses[status.Key] = status
+				}
+				if next == "" {
+					break
+				}
+				cursor = next
+			}
+			_, _, invalidErr := lister.ListKeys(ctx, KeyPrefix(domain.IPLimiter), "not-a-cursor", 2)
+
+			// Assert: só as chaves de IP, incluindo a que tem apenas o bloqueio
+			assert.Len(t, statuses, 4)
+			assert.Equal(t, 1, statuses[BuildKey(domain.IPLimiter, "10.0.0.1")].Count)
+			assert.False(t, statuses[BuildKey(domain.IPLimiter, "10.0.0.1")].IsBlocked)
+			assert.True(t, statuses[BuildKey(domain.IPLimiter, "10.0.0.4")].IsBlocked)
+			assert.ErrorIs(t, invalidErr, domain.ErrInvalidCursor)
+		})
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	// Arrange
+	cipher, err := NewCipher([]byte("0123456789abcdef"))
+	require.NoError(t, err)
+	options := []KeyOption{WithHashTag(), WithEncryptedIdentifier(cipher)}
+
+	// Act
+	identifier, err := ParseKey(domain.TokenLimiter, BuildKey(domain.TokenLimiter, "abc123", options...), options...)
+	_, typeErr := ParseKey(domain.IPLimiter, BuildKey(domain.TokenLimiter, "abc123"))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", identifier)
+	assert.Error(t, typeErr)
+}
@@ -0,0 +1,206 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"rate-limiter/internal/domain"
+)
+
+// leakyBucketState é o nível do leaky bucket de uma chave no MemoryStorage
+type leakyBucketState struct {
+	level   float64
+	updated int64 // Unix nanossegundos
+}
+
+// drainAndEnqueue escoa o balde pelo tempo decorrido e enfileira a requisição se
+// ela couber. Retorna o nível antes da requisição (a fila à frente dela)
+func drainAndEnqueue(level float64, elapsed time.Duration, bucket domain.LeakyBucket) (float64, bool) {
+	if elapsed > 0 {
+		level = math.Max(0, level-elapsed.Seconds()*bucket.LeakRate)
+	}
+	return level, level+1 <= float64(bucket.Capacity)
+}
+
+// leakyBucketStatus monta o status retornado pelo Leak a partir da fila à frente da requisição
+func leakyBucketStatus(key string, bucket domain.LeakyBucket, ahead float64, allowed bool, now time.Time) *domain.RateLimitStatus {
+	perRequest := time.Duration(float64(time.Second) / bucket.LeakRate)
+	level := ahead
+	if allowed {
+		level++
+	}
+	resetAt := now.Add(time.Duration(level * float64(perRequest)))
+
+	status := &domain.RateLimitStatus{
+		Key:       key,
+		Count:     int(math.Ceil(level)),
+		Limit:     bucket.Capacity,
+		Window:    int(math.Ceil(float64(bucket.Capacity) / bucket.LeakRate)),
+		LastReset: now,
+		ResetAt:   &resetAt,
+	}
+	if !allowed {
+		// Cabe uma nova requisição quando o nível baixar a Capacity-1
+		fits := now.Add(time.Duration((ahead + 1 - float64(bucket.Capacity)) * float64(perRequest)))
+		status.Count = bucket.Capacity + 1
+		status.IsBlocked = true
+		status.BlockedUntil = &fits
+		return status
+	}
+	releaseAt := now.Add(time.Duration(ahead * float64(perRequest)))
+	status.ReleaseAt = &releaseAt
+	return status
+}
+
+// validateLeakyBucket rejeita baldes que nunca liberariam uma requisição
+func validateLeakyBucket(bucket domain.LeakyBucket) error {
+	if bucket.Capacity < 1 || bucket.LeakRate <= 0 {
+		return fmt.Errorf("invalid leaky bucket: capacity %d, leak rate %g", bucket.Capacity, bucket.LeakRate)
+	}
+	return nil
+}
+
+// Leak enfileira a requisição no leaky bucket da chave. O registro de status
+// acompanha o balde para Get, Inspect e a limpeza periódica
+func (m *MemoryStorage) Leak(ctx context.Context, key string, bucket domain.LeakyBucket) (*domain.RateLimitStatus, error) {
+	ctx, span := startSpan(ctx, MemoryStorageType, "LEAK", key)
+	defer span.End()
+	m.touch(key)
+
+	if err := validateLeakyBucket(bucket); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+
+	shard := m.lock(ctx, key)
+	defer m.unlock(shard)
+
+	now := m.now()
+	state, exists := shard.leaks[key]
+	if !exists {
+		state = &leakyBucketState{updated: now.UnixNano()}
+		shard.leaks[key] = state
+	}
+	ahead, allowed := drainAndEnqueue(state.level, time.Duration(now.UnixNano()-state.updated), bucket)
+	state.level = ahead
+	if allowed {
+		state.level++
+	}
+	state.updated = now.UnixNano()
+
+	status := leakyBucketStatus(key, bucket, ahead, allowed, now)
+
+	record, exists := shard.data.get(key)
+	if !exists {
+		record = m.insert(shard, key, memoryRecord{})
+	}
+	record.limit = clampInt32(bucket.Capacity)
+	record.window = clampInt32(status.Window)
+	record.lastReset = now.UnixNano()
+	record.count = clampInt32(int(math.Ceil(state.level)))
+
+	m.logStorageOperation(ctx, "LEAK", key, true, time.Since(start).Seconds()*1000, nil)
+	return status, nil
+}
+
+// leakSource escoa o balde gravado no status JSON da chave (campos level e
+// updatedAt) e enfileira a requisição se couber. Retorna {permitida (0/1), fila à frente}
+const leakSource = `
+	local key = KEYS[1]
+	local capacity = tonumber(ARGV[1])
+	local rate = tonumber(ARGV[2]) -- requisições por segundo
+	local now = tonumber(ARGV[3])
+
+	local current = redis.call('GET', key)
+	local data = {}
+	if current then
+		data = cjson.decode(current)
+	else
+		data = { key = key, type = '' }
+	end
+
+	-- Escoamento pelo tempo decorrido desde a última requisição
+	local level = tonumber(data.level) or 0
+	local updated = tonumber(data.updatedAt) or now
+	if now > updated then
+		level = math.max(0, level - (now - updated) * rate / 1000)
+	end
+
+	local ahead = level
+	local allowed = 0
+	if level + 1 <= capacity then
+		level = level + 1
+		allowed = 1
+	end
+
+	-- Tempo até o balde esvaziar (ms); depois disso a chave pode expirar
+	local drain = math.ceil(math.max(level, 1) * 1000 / rate)
+
+	local blocked = type(data.blockedUntil) == 'number' and data.blockedUntil > now
+	if not blocked then
+		data.blockedUntil = nil
+	end
+	data.level = level
+	data.updatedAt = now
+	data.count = math.ceil(level)
+	data.limit = capacity
+	data.window = math.ceil(capacity / rate)
+	data.lastReset = now
+	data.isBlocked = blocked
+
+	-- Preserva o TTL de um bloqueio mais longo que o escoamento
+	local ttl = redis.call('PTTL', key)
+	if ttl < drain then
+		ttl = drain
+	end
+	redis.call('SET', key, cjson.encode(data), 'PX', ttl)
+
+	-- Frações viram inteiros no retorno do Lua: a fila vai como string
+	return {allowed, tostring(ahead)}
+`
+
+var leakScript = redis.NewScript(leakSource)
+
+// Leak enfileira atomicamente a requisição no leaky bucket da chave no Redis
+func (r *RedisStorage) Leak(ctx context.Context, key string, bucket domain.LeakyBucket) (*domain.RateLimitStatus, error) {
+	ctx, span := startSpan(ctx, RedisStorageType, "LEAK", key)
+	defer span.End()
+
+	if err := validateLeakyBucket(bucket); err != nil {
+		return nil, err
+	}
+	if r.cipher != nil {
+		return nil, fmt.Errorf("failed to enqueue request for key %s: %w", key, ErrSealedUnsupported)
+	}
+
+	start := time.Now()
+	now := time.Now()
+
+	result, err := r.eval(ctx, "leak", leakScript, []string{key}, bucket.Capacity, bucket.LeakRate, now.UnixMilli())
+	if err != nil {
+		r.logStorageOperation(ctx, "LEAK", key, false, time.Since(start).Seconds()*1000, err)
+		return nil, fmt.Errorf("failed to enqueue request for key %s: %w", key, err)
+	}
+
+	values, ok := result.([]interface{})
+	if !ok || len(values) < 2 {
+		err := fmt.Errorf("invalid leaky bucket result for key %s", key)
+		r.logStorageOperation(ctx, "LEAK", key, false, time.Since(start).Seconds()*1000, err)
+		return nil, err
+	}
+	ahead, err := strconv.ParseFloat(fmt.Sprint(values[1]), 64)
+	if err != nil {
+		r.logStorageOperation(ctx, "LEAK", key, false, time.Since(start).Seconds()*1000, err)
+		return nil, fmt.Errorf("invalid queue level in result for key %s: %w", key, err)
+	}
+	allowed := fmt.Sprint(values[0]) == "1"
+
+	r.logStorageOperation(ctx, "LEAK", key, true, time.Since(start).Seconds()*1000, nil)
+	return leakyBucketStatus(key, bucket, ahead, allowed, time.UnixMilli(now.UnixMilli())), nil
+}
@@ -2,26 +2,74 @@ package storage
 
 import (
 	"context"
-	"sync"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
 	"time"
 
 	"rate-limiter/internal/domain"
 )
 
-// MemoryStorage implementa a interface domain.RateLimiterStorage usando memória
+// MemoryStorage implementa a interface domain.RateLimiterStorage usando memória.
+// As chaves são divididas em partições com lock próprio (memoryShard)
 type MemoryStorage struct {
-	data   map[string]*domain.RateLimitStatus
-	blocks map[string]time.Time // chave -> bloqueado até
-	mutex  sync.RWMutex
-	logger domain.Logger
+	shards    [memoryShardCount]*memoryShard
+	logger    domain.Logger
+	now       func() time.Time // relógio das janelas, bloqueios e buckets
+	algorithm Algorithm
+
+	// Histórico das últimas historySize janelas fechadas por chave (0 = desabilitado)
+	historySize int
+
+	// Limite de chaves com descarte das usadas há mais tempo (0 = sem limite);
+	// a ordem de uso fica na memoryLRU de cada partição
+	maxEntries int
+	lruEntries atomic.Int64 // Chaves registradas nas memoryLRU das partições
+
+	// Contadores internos expostos via MetricsSnapshot
+	cleanupRemovedData   atomic.Uint64
+	cleanupRemovedBlocks atomic.Uint64
+	evictions            atomic.Uint64
+	lruEvictions         atomic.Uint64
+}
+
+// MemoryMetrics é um retrato dos contadores internos do MemoryStorage
+type MemoryMetrics struct {
+	DataEntries          int
+	BlockEntries         int
+	CleanupRemovedData   uint64
+	CleanupRemovedBlocks uint64
+	Evictions            uint64 // Chaves removidas pelo TTL do Set
+	LRUEvictions         uint64 // Chaves descartadas pelo limite de SetMaxEntries
+	LockAcquisitions     uint64 // Soma de todas as partições
+	LockWait             time.Duration
+	ShardLocks           []MemoryShardLocks // Por partição, na ordem dos índices
+}
+
+// MemoryShardLocks são os contadores de lock de uma partição do MemoryStorage
+type MemoryShardLocks struct {
+	Acquisitions uint64
+	Wait         time.Duration
 }
 
 // NewMemoryStorage cria uma nova instância do MemoryStorage
 func NewMemoryStorage(logger domain.Logger) *MemoryStorage {
+	return NewMemoryStorageWithCapacity(logger, 0)
+}
+
+// NewMemoryStorageWithCapacity cria o MemoryStorage com os mapas pré-alocados para
+// expectedKeys chaves, evitando o crescimento incremental (rehash) sob carga
+func NewMemoryStorageWithCapacity(logger domain.Logger, expectedKeys int) *MemoryStorage {
+	if expectedKeys < 0 {
+		expectedKeys = 0
+	}
+
 	storage := &MemoryStorage{
-		data:   make(map[string]*domain.RateLimitStatus),
-		blocks: make(map[string]time.Time),
 		logger: logger,
+		now:    time.Now,
+	}
+	for i := range storage.shards {
+		storage.shards[i] = newMemoryShard(shardCapacity(expectedKeys))
 	}
 
 	// Inicia goroutine de limpeza
@@ -34,179 +82,370 @@ func NewMemoryStorage(logger domain.Logger) *MemoryStorage {
 	return storage
 }
 
+// SetClock substitui o relógio das janelas, bloqueios e buckets, para simulação
+// e replay de tráfego com tempo controlado. Deve ser chamado antes do primeiro uso
+func (m *MemoryStorage) SetClock(now func() time.Time) {
+	m.now = now
+}
+
 // Get recupera o status atual de rate limit para uma chave
 func (m *MemoryStorage) Get(ctx context.Context, key string) (*domain.RateLimitStatus, error) {
+	ctx, span := startSpan(ctx, MemoryStorageType, "GET", key)
+	defer span.End()
+	m.touch(key)
+
 	start := time.Now()
 	
-	m.mutex.RLock()
-	defer m.mutex.RUnlock()
+	shard := m.rlock(ctx, key)
+	defer shard.mutex.RUnlock()
 
 	// Verifica se a chave existe
-	status, exists := m.data[key]
+	record, exists := shard.data.get(key)
 	if !exists {
-		m.logStorageOperation("GET", key, true, time.Since(start).Seconds()*1000, nil)
+		m.logStorageOperation(ctx, "GET", key, true, time.Since(start).Seconds()*1000, nil)
 		return nil, nil
 	}
 
-	// Cria cópia para evitar modificações concorrentes
-	result := *status
-
-	m.logStorageOperation("GET", key, true, time.Since(start).Seconds()*1000, nil)
-	return &result, nil
+	m.logStorageOperation(ctx, "GET", key, true, time.Since(start).Seconds()*1000, nil)
+	return record.status(key), nil
 }
 
 // Set define o status de rate limit para uma chave
 func (m *MemoryStorage) Set(ctx context.Context, key string, status *domain.RateLimitStatus, ttl time.Duration) error {
+	ctx, span := startSpan(ctx, MemoryStorageType, "SET", key)
+	defer span.End()
+
 	start := time.Now()
 
-	m.mutex.Lock()
-	defer m.mutex.Unlock()
+	shard := m.lock(ctx, key)
+	defer m.unlock(shard)
 
-	// Cria cópia para evitar modificações externas
-	statusCopy := *status
-	m.data[key] = &statusCopy
+	m.insert(shard, key, newMemoryRecord(status))
 
 	// Se TTL for especificado, agenda remoção
 	if ttl > 0 {
 		go func() {
 			time.Sleep(ttl)
-			m.mutex.Lock()
-			delete(m.data, key)
-			delete(m.blocks, key)
-			m.mutex.Unlock()
+			m.lockShard(context.Background(), shard)
+			if _, exists := shard.data.get(key); exists {
+				m.evictions.Add(1)
+			}
+			shard.data.delete(key)
+			delete(shard.blocks, key)
+			m.untrack(key)
+			shard.mutex.Unlock()
 		}()
 	}
 
-	m.logStorageOperation("SET", key, true, time.Since(start).Seconds()*1000, nil)
+	m.logStorageOperation(ctx, "SET", key, true, time.Since(start).Seconds()*1000, nil)
 	return nil
 }
 
-// Increment incrementa o contador para uma chave e retorna o novo valor
+// Increment incrementa o contador para uma chave e retorna o novo valor.
+// Dentro da janela, o incremento é atômico sob o read lock compartilhado;
+// o write lock só é usado para criar a chave, trocar de janela ou bloquear
 func (m *MemoryStorage) Increment(ctx context.Context, key string, limit int, window time.Duration) (int, time.Time, error) {
+	ctx, span := startSpan(ctx, MemoryStorageType, "INCREMENT", key)
+	defer span.End()
+	m.touch(key)
+
 	start := time.Now()
 
-	m.mutex.Lock()
-	defer m.mutex.Unlock()
+	if m.algorithm == AlgorithmSlidingLog {
+		count, oldest := m.incrementSlidingLog(ctx, key, limit, window)
+		m.logStorageOperation(ctx, "INCREMENT", key, true, time.Since(start).Seconds()*1000, nil)
+		return count, oldest, nil
+	}
+	if m.algorithm == AlgorithmSlidingWindow {
+		count, windowStart := m.incrementSlidingWindow(ctx, key, limit, window)
+		m.logStorageOperation(ctx, "INCREMENT", key, true, time.Since(start).Seconds()*1000, nil)
+		return count, windowStart, nil
+	}
+
+	if count, lastReset, ok := m.incrementInWindow(ctx, key, limit, window); ok {
+		m.logStorageOperation(ctx, "INCREMENT", key, true, time.Since(start).Seconds()*1000, nil)
+		return count, lastReset, nil
+	}
+
+	shard := m.lock(ctx, key)
+	defer m.unlock(shard)
+
+	now := m.now().UnixNano()
 
-	now := time.Now()
-	
 	// Busca ou cria status
-	status, exists := m.data[key]
+	record, exists := shard.data.get(key)
 	if !exists {
-		status = &domain.RateLimitStatus{
-			Key:       key,
-			Count:     0,
-			Limit:     limit,
-			Window:    int(window.Seconds()),
-			LastReset: now,
-			IsBlocked: false,
-		}
-		m.data[key] = status
+		record = m.insert(shard, key, memoryRecord{
+			limit:     clampInt32(limit),
+			window:    clampInt32(int(window.Seconds())),
+			lastReset: now,
+		})
 	}
 
-	// Verifica se precisa resetar a janela
-	timeSinceReset := now.Sub(status.LastReset)
+	// Verifica se precisa resetar a janela. Sem read locks ativos, nenhum
+	// incremento concorrente cai na janela anterior
+	timeSinceReset := time.Duration(now - record.lastReset)
 	if timeSinceReset >= window {
-		status.Count = 0
-		status.LastReset = now
-		status.IsBlocked = false
+		m.recordWindow(shard, key, record, window)
+		record.count = 0
+		record.lastReset = now
+		record.blocked = false
+		record.blockedUntil = 0
 		// Remove bloqueio se existir
-		delete(m.blocks, key)
+		delete(shard.blocks, key)
 	}
 
 	// Incrementa contador
-	status.Count++
+	count := record.increment()
 
 	// Verifica se excedeu o limite
-	if status.Count > limit {
-		status.IsBlocked = true
+	if count > clampInt32(limit) {
+		record.blocked = true
 	}
 
-	m.logStorageOperation("INCREMENT", key, true, time.Since(start).Seconds()*1000, nil)
-	return status.Count, status.LastReset, nil
+	m.logStorageOperation(ctx, "INCREMENT", key, true, time.Since(start).Seconds()*1000, nil)
+	return int(count), fromUnixNano(record.lastReset), nil
+}
+
+// incrementInWindow é o caminho rápido do Increment: chave existente, janela
+// deslizante ainda aberta. Retorna ok=false quando o caminho lento é necessário
+func (m *MemoryStorage) incrementInWindow(ctx context.Context, key string, limit int, window time.Duration) (int, time.Time, bool) {
+	shard := m.rlock(ctx, key)
+	defer shard.mutex.RUnlock()
+
+	record, exists := shard.data.get(key)
+	if !exists || record.resetAt != 0 || time.Duration(m.now().UnixNano()-record.lastReset) >= window {
+		return 0, time.Time{}, false
+	}
+
+	// lastReset e blocked só mudam sob o write lock, então a janela não troca até
+	// o RUnlock. O incremento que passaria do limite vai para o caminho lento,
+	// que marca o bloqueio
+	if record.blocked {
+		return int(record.increment()), fromUnixNano(record.lastReset), true
+	}
+	count, ok := record.incrementUpTo(clampInt32(limit))
+	if !ok {
+		return 0, time.Time{}, false
+	}
+	return int(count), fromUnixNano(record.lastReset), true
+}
+
+// mergeCounter substitui o contador local pelo estado global da chave, somando
+// os incrementos locais que ainda não foram sincronizados (usado pelo HybridStorage)
+func (m *MemoryStorage) mergeCounter(snapshot CounterSnapshot, unsynced int64, limit int, window time.Duration) {
+	shard := m.lock(context.Background(), snapshot.Key)
+	defer m.unlock(shard)
+
+	now := m.now().UnixNano()
+
+	record, exists := shard.data.get(snapshot.Key)
+	if !exists {
+		record = m.insert(shard, snapshot.Key, memoryRecord{
+			limit:  clampInt32(limit),
+			window: clampInt32(int(window.Seconds())),
+		})
+	}
+
+	// Outra instância já abriu uma nova janela: a local fecha aqui
+	if exists && record.lastReset != unixNano(snapshot.LastReset) {
+		m.recordWindow(shard, snapshot.Key, record, window)
+	}
+
+	count := int64(snapshot.Count) + unsynced
+	record.count = clampInt32(int(count))
+	record.lastReset = unixNano(snapshot.LastReset)
+
+	// Bloqueios aplicados por outras instâncias passam a valer localmente
+	if snapshot.BlockedUntil != nil && snapshot.BlockedUntil.UnixNano() > now {
+		record.blockedUntil = snapshot.BlockedUntil.UnixNano()
+		shard.blocks[snapshot.Key] = record.blockedUntil
+	}
+	record.blocked = count > int64(limit) || record.blockedUntil > now
+}
+
+// IncrementQuota incrementa o contador de uma cota com reset absoluto
+func (m *MemoryStorage) IncrementQuota(ctx context.Context, key string, period domain.QuotaPeriod) (*domain.RateLimitStatus, error) {
+	ctx, span := startSpan(ctx, MemoryStorageType, "INCREMENT_QUOTA", key)
+	defer span.End()
+	m.touch(key)
+
+	start := time.Now()
+
+	shard := m.lock(ctx, key)
+	defer m.unlock(shard)
+
+	now := m.now()
+
+	// Busca ou cria status; reinicia quando o reset armazenado já passou
+	record, exists := shard.data.get(key)
+	if !exists || record.resetAt == 0 || now.UnixNano() >= record.resetAt {
+		credit := 0
+		if exists && record.resetAt != 0 {
+			credit = rolloverCredit(record.status(key), period.RolloverPercent)
+		}
+
+		record = m.insert(shard, key, memoryRecord{
+			window:    clampInt32(int(period.ResetAt.Sub(now).Seconds())),
+			lastReset: now.UnixNano(),
+			resetAt:   period.ResetAt.UnixNano(),
+			credit:    clampInt32(credit),
+		})
+		delete(shard.blocks, key)
+	}
+
+	// Incrementa contador
+	count := record.increment()
+	record.limit = clampInt32(period.Limit)
+
+	// Verifica se excedeu o limite (incluindo crédito)
+	if int64(count) > record.effectiveLimit() {
+		record.blocked = true
+	}
+
+	m.logStorageOperation(ctx, "INCREMENT_QUOTA", key, true, time.Since(start).Seconds()*1000, nil)
+	return record.status(key), nil
+}
+
+// rolloverCredit calcula o crédito herdado do período anterior
+// O crédito é limitado ao próprio limite para não acumular indefinidamente
+func rolloverCredit(previous *domain.RateLimitStatus, percent int) int {
+	if percent <= 0 {
+		return 0
+	}
+
+	unused := previous.EffectiveLimit() - previous.Count
+	if unused <= 0 {
+		return 0
+	}
+
+	credit := unused * percent / 100
+	if credit > previous.Limit {
+		credit = previous.Limit
+	}
+	return credit
 }
 
 // IsBlocked verifica se uma chave está bloqueada
 func (m *MemoryStorage) IsBlocked(ctx context.Context, key string) (bool, *time.Time, error) {
+	ctx, span := startSpan(ctx, MemoryStorageType, "IS_BLOCKED", key)
+	defer span.End()
+	m.touch(key)
+
 	start := time.Now()
 
-	m.mutex.RLock()
-	defer m.mutex.RUnlock()
-
-	// Verifica bloqueio específico
-	if blockedUntil, exists := m.blocks[key]; exists {
-		if time.Now().Before(blockedUntil) {
-			m.logStorageOperation("IS_BLOCKED", key, true, time.Since(start).Seconds()*1000, nil)
-			return true, &blockedUntil, nil
-		} else {
-			// Bloqueio expirou, remove
-			delete(m.blocks, key)
-		}
+	shard := m.rlock(ctx, key)
+	defer shard.mutex.RUnlock()
+
+	now := m.now().UnixNano()
+
+	// Verifica bloqueio específico (expirados são removidos pela limpeza periódica,
+	// pois aqui só há read lock)
+	if blockedUntil, exists := shard.blocks[key]; exists && now < blockedUntil {
+		until := fromUnixNano(blockedUntil)
+		m.logStorageOperation(ctx, "IS_BLOCKED", key, true, time.Since(start).Seconds()*1000, nil)
+		return true, &until, nil
 	}
 
 	// Verifica status geral
-	status, exists := m.data[key]
-	if !exists {
-		m.logStorageOperation("IS_BLOCKED", key, true, time.Since(start).Seconds()*1000, nil)
+	record, exists := shard.data.get(key)
+	// Bloqueio expirado não vale mais, mesmo que o contador ainda esteja acima do limite
+	if !exists || (record.blockedUntil != 0 && now >= record.blockedUntil) {
+		m.logStorageOperation(ctx, "IS_BLOCKED", key, true, time.Since(start).Seconds()*1000, nil)
 		return false, nil, nil
 	}
 
-	m.logStorageOperation("IS_BLOCKED", key, true, time.Since(start).Seconds()*1000, nil)
-	return status.IsBlocked, status.BlockedUntil, nil
+	m.logStorageOperation(ctx, "IS_BLOCKED", key, true, time.Since(start).Seconds()*1000, nil)
+	return record.blocked, record.status(key).BlockedUntil, nil
 }
 
 // Block bloqueia uma chave por um período específico
 func (m *MemoryStorage) Block(ctx context.Context, key string, duration time.Duration) error {
+	ctx, span := startSpan(ctx, MemoryStorageType, "BLOCK", key)
+	defer span.End()
+	m.touch(key)
+
 	start := time.Now()
 
-	m.mutex.Lock()
-	defer m.mutex.Unlock()
+	shard := m.lock(ctx, key)
+	defer m.unlock(shard)
 
-	blockedUntil := time.Now().Add(duration)
+	now := m.now()
+	blockedUntil := now.Add(duration).UnixNano()
 
 	// Define bloqueio específico
-	m.blocks[key] = blockedUntil
+	shard.blocks[key] = blockedUntil
 
-	// Atualiza status se existir
-	if status, exists := m.data[key]; exists {
-		status.IsBlocked = true
-		status.BlockedUntil = &blockedUntil
-	} else {
-		// Cria novo status bloqueado
-		m.data[key] = &domain.RateLimitStatus{
-			Key:          key,
-			IsBlocked:    true,
-			BlockedUntil: &blockedUntil,
-			LastReset:    time.Now(),
-		}
+	// Atualiza status existente ou cria novo status bloqueado
+	record, exists := shard.data.get(key)
+	if !exists {
+		record = m.insert(shard, key, memoryRecord{lastReset: now.UnixNano()})
 	}
+	record.blocked = true
+	record.blockedUntil = blockedUntil
 
-	m.logStorageOperation("BLOCK", key, true, time.Since(start).Seconds()*1000, nil)
+	m.logStorageOperation(ctx, "BLOCK", key, true, time.Since(start).Seconds()*1000, nil)
 	return nil
 }
 
 // Reset limpa os dados de uma chave
 func (m *MemoryStorage) Reset(ctx context.Context, key string) error {
+	ctx, span := startSpan(ctx, MemoryStorageType, "RESET", key)
+	defer span.End()
+
 	start := time.Now()
 
-	m.mutex.Lock()
-	defer m.mutex.Unlock()
+	shard := m.lock(ctx, key)
+	defer shard.mutex.Unlock()
 
-	delete(m.data, key)
-	delete(m.blocks, key)
+	shard.remove(key)
+	m.untrack(key)
 
-	m.logStorageOperation("RESET", key, true, time.Since(start).Seconds()*1000, nil)
+	m.logStorageOperation(ctx, "RESET", key, true, time.Since(start).Seconds()*1000, nil)
 	return nil
 }
 
+// Inspect retorna o status e a entrada de bloqueio da chave como estão em memória
+// O TTL não é reportado: a expiração em memória não é rastreada por chave
+func (m *MemoryStorage) Inspect(ctx context.Context, key string) (*domain.StorageRecord, error) {
+	ctx, span := startSpan(ctx, MemoryStorageType, "INSPECT", key)
+	defer span.End()
+
+	shard := m.rlock(ctx, key)
+	defer shard.mutex.RUnlock()
+
+	record := &domain.StorageRecord{Backend: string(MemoryStorageType), StorageKey: key}
+	if blockedUntil, exists := shard.blocks[key]; exists {
+		until := fromUnixNano(blockedUntil)
+		record.BlockedUntil = &until
+	}
+
+	stored, exists := shard.data.get(key)
+	if !exists {
+		record.Exists = record.BlockedUntil != nil
+		return record, nil
+	}
+
+	status := stored.status(key)
+	raw, err := json.Marshal(status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode status for key %s: %w", key, err)
+	}
+
+	record.Exists = true
+	record.Raw = string(raw)
+	record.Status = status
+	return record, nil
+}
+
 // Health verifica se o storage está saudável
 func (m *MemoryStorage) Health(ctx context.Context) error {
+	ctx, span := startSpan(ctx, MemoryStorageType, "HEALTH", "")
+	defer span.End()
+
 	start := time.Now()
 
-	m.mutex.RLock()
-	dataSize := len(m.data)
-	blocksSize := len(m.blocks)
-	m.mutex.RUnlock()
+	dataSize, blocksSize := m.entryCounts()
 
 	if m.logger != nil {
 		m.logger.Debug("Memory storage health check", map[string]interface{}{
@@ -215,18 +454,20 @@ func (m *MemoryStorage) Health(ctx context.Context) error {
 		})
 	}
 
-	m.logStorageOperation("HEALTH", "check", true, time.Since(start).Seconds()*1000, nil)
+	m.logStorageOperation(ctx, "HEALTH", "check", true, time.Since(start).Seconds()*1000, nil)
 	return nil
 }
 
 // Close fecha a conexão com o storage (no-op para memory)
 func (m *MemoryStorage) Close() error {
-	m.mutex.Lock()
-	defer m.mutex.Unlock()
-
 	// Limpa todos os dados
-	m.data = make(map[string]*domain.RateLimitStatus)
-	m.blocks = make(map[string]time.Time)
+	m.eachShard(func(shard *memoryShard) {
+		shard.clear(0)
+		if shard.lru != nil {
+			shard.lru.clear()
+		}
+	})
+	m.lruEntries.Store(0)
 
 	if m.logger != nil {
 		m.logger.Info("Memory storage closed", nil)
@@ -244,61 +485,135 @@ func (m *MemoryStorage) cleanup() {
 	}
 }
 
-// cleanupExpiredEntries remove entradas expiradas
+// cleanupExpiredEntries remove entradas expiradas, uma partição por vez
 func (m *MemoryStorage) cleanupExpiredEntries() {
-	m.mutex.Lock()
-	defer m.mutex.Unlock()
-
-	now := time.Now()
+	now := m.now().UnixNano()
 	removedBlocks := 0
 	removedData := 0
+	m.eachShard(func(shard *memoryShard) {
+		blocks, data := m.cleanupShard(shard, now)
+		removedBlocks += blocks
+		removedData += data
+	})
+	m.cleanupRemovedBlocks.Add(uint64(removedBlocks))
+	m.cleanupRemovedData.Add(uint64(removedData))
 
+	if (removedBlocks > 0 || removedData > 0) && m.logger != nil {
+		m.logger.Debug("Memory storage cleanup completed", map[string]interface{}{
+			"removed_blocks": removedBlocks,
+			"removed_data":   removedData,
+		})
+	}
+}
+
+// cleanupShard remove as entradas expiradas da partição. Exige o write lock da partição
+func (m *MemoryStorage) cleanupShard(shard *memoryShard, now int64) (removedBlocks, removedData int) {
 	// Remove bloqueios expirados
-	for key, blockedUntil := range m.blocks {
-		if now.After(blockedUntil) {
-			delete(m.blocks, key)
+	for key, blockedUntil := range shard.blocks {
+		if now > blockedUntil {
+			delete(shard.blocks, key)
 			removedBlocks++
 		}
 	}
 
 	// Remove dados com janela expirada (assumindo TTL baseado em LastReset + Window)
-	for key, status := range m.data {
-		if status.Window > 0 {
-			windowDuration := time.Duration(status.Window) * time.Second
-			if now.Sub(status.LastReset) > windowDuration*2 { // Grace period
-				delete(m.data, key)
+	shard.data.each(func(key string, record *memoryRecord) {
+		if record.resetAt != 0 {
+			// Mantém o período anterior por mais um ciclo para cálculo de rollover
+			if now > 2*record.resetAt-record.lastReset {
+				shard.data.delete(key)
+				delete(shard.logs, key)
+				delete(shard.buckets, key)
+				delete(shard.leaks, key)
+				m.untrack(key)
 				removedData++
 			}
+			return
 		}
-	}
+		if record.window > 0 {
+			windowDuration := time.Duration(record.window) * time.Second
+			if time.Duration(now-record.lastReset) > windowDuration*2 { // Grace period
+				m.recordWindow(shard, key, record, windowDuration)
+				shard.data.delete(key)
+				delete(shard.logs, key)
+				delete(shard.buckets, key)
+				delete(shard.leaks, key)
+				m.untrack(key)
+				removedData++
+			}
+		}
+	})
 
-	if (removedBlocks > 0 || removedData > 0) && m.logger != nil {
-		m.logger.Debug("Memory storage cleanup completed", map[string]interface{}{
-			"removed_blocks": removedBlocks,
-			"removed_data":   removedData,
-		})
+	if m.historySize > 0 {
+		m.cleanupWindowHistory(shard, now)
 	}
+	return removedBlocks, removedData
 }
 
 // GetStats retorna estatísticas do storage em memória
 func (m *MemoryStorage) GetStats() map[string]interface{} {
-	m.mutex.RLock()
-	defer m.mutex.RUnlock()
+	dataEntries, blockEntries := m.entryCounts()
 
 	return map[string]interface{}{
-		"data_entries":   len(m.data),
-		"blocks_entries": len(m.blocks),
+		"data_entries":   dataEntries,
+		"blocks_entries": blockEntries,
+		"shards":         memoryShardCount,
+		"max_entries":    m.maxEntries,
+		"lru_evictions":  m.lruEvictions.Load(),
 		"type":           "memory",
 	}
 }
 
+// IsLocal indica que o estado é restrito a esta instância
+func (m *MemoryStorage) IsLocal() bool {
+	return true
+}
+
+// MetricsSnapshot retorna tamanhos dos mapas e contadores internos
+func (m *MemoryStorage) MetricsSnapshot() MemoryMetrics {
+	dataEntries, blockEntries := m.entryCounts()
+
+	metrics := MemoryMetrics{
+		DataEntries:          dataEntries,
+		BlockEntries:         blockEntries,
+		CleanupRemovedData:   m.cleanupRemovedData.Load(),
+		CleanupRemovedBlocks: m.cleanupRemovedBlocks.Load(),
+		Evictions:            m.evictions.Load(),
+		LRUEvictions:         m.lruEvictions.Load(),
+		ShardLocks:           make([]MemoryShardLocks, len(m.shards)),
+	}
+	for i, shard := range m.shards {
+		locks := MemoryShardLocks{
+			Acquisitions: shard.lockAcquisitions.Load(),
+			Wait:         time.Duration(shard.lockWaitNanos.Load()),
+		}
+		metrics.ShardLocks[i] = locks
+		metrics.LockAcquisitions += locks.Acquisitions
+		metrics.LockWait += locks.Wait
+	}
+	return metrics
+}
+
+// shardCapacity divide a pré-alocação de expectedKeys entre as partições
+func shardCapacity(expectedKeys int) int {
+	return (expectedKeys + memoryShardCount - 1) / memoryShardCount
+}
+
 // logStorageOperation registra operações de storage
-func (m *MemoryStorage) logStorageOperation(operation, key string, success bool, latency float64, err error) {
+func (m *MemoryStorage) logStorageOperation(ctx context.Context, operation, key string, success bool, latency float64, err error) {
+	if !success {
+		recordSpanError(ctx, err)
+	}
+
 	if m.logger == nil {
 		return
 	}
 	
 	if success {
+		// Sucesso é registrado só em debug: evita montar os campos a cada operação
+		if !domain.DebugEnabled(m.logger) {
+			return
+		}
 		m.logger.Debug("Storage operation completed", map[string]interface{}{
 			"operation": operation,
 			"key":       key,
@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"context"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"rate-limiter/internal/logger"
+)
+
+// Benchmarks do caminho quente do MemoryStorage. Compare versões com:
+//
+//	go test -run '^$' -bench MemoryStorage -benchmem -cpu 1,4,8 ./internal/storage > new.txt
+//	benchstat old.txt new.txt
+
+func newBenchmarkMemoryStorage(b *testing.B) *MemoryStorage {
+	b.Helper()
+
+	storage := NewMemoryStorage(logger.NewNopLogger())
+	b.Cleanup(func() { storage.Close() })
+	return storage
+}
+
+// BenchmarkMemoryStorage_Increment_SingleKey mede a contenção de muitas goroutines na mesma chave
+func BenchmarkMemoryStorage_Increment_SingleKey(b *testing.B) {
+	storage := newBenchmarkMemoryStorage(b)
+	ctx := context.Background()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, _, err := storage.Increment(ctx, "rate_limit:ip:10.0.0.1", 1<<30, time.Hour); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkMemoryStorage_Increment_ManyKeys mede o tráfego distribuído entre chaves já existentes
+func BenchmarkMemoryStorage_Increment_ManyKeys(b *testing.B) {
+	const keys = 1024
+
+	storage := newBenchmarkMemoryStorage(b)
+	ctx := context.Background()
+	names := make([]string, keys)
+	for i := range names {
+		names[i] = "rate_limit:ip:10.0." + strconv.Itoa(i/256) + "." + strconv.Itoa(i%256)
+		if _, _, err := storage.Increment(ctx, names[i], 1<<30, time.Hour); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	var worker atomic.Uint64
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		// Cada goroutine percorre as chaves a partir de um ponto diferente
+		i := int(worker.Add(1) * 7919)
+		for pb.Next() {
+			if _, _, err := storage.Increment(ctx, names[i%keys], 1<<30, time.Hour); err != nil {
+				b.Fatal(err)
+			}
+			i++
+		}
+	})
+}
+
+// BenchmarkMemoryStorage_Increment_ManyKeys_MaxEntries mede o mesmo tráfego com
+// SetMaxEntries: a ordem de uso é atualizada a cada operação, por partição
+func BenchmarkMemoryStorage_Increment_ManyKeys_MaxEntries(b *testing.B) {
+	const keys = 1024
+
+	storage := newBenchmarkMemoryStorage(b)
+	storage.SetMaxEntries(keys)
+	ctx := context.Background()
+	names := make([]string, keys)
+	for i := range names {
+		names[i] = "rate_limit:ip:10.0." + strconv.Itoa(i/256) + "." + strconv.Itoa(i%256)
+		if _, _, err := storage.Increment(ctx, names[i], 1<<30, time.Hour); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	var worker atomic.Uint64
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := int(worker.Add(1) * 7919)
+		for pb.Next() {
+			if _, _, err := storage.Increment(ctx, names[i%keys], 1<<30, time.Hour); err != nil {
+				b.Fatal(err)
+			}
+			i++
+		}
+	})
+}
+
+// BenchmarkMemoryStorage_Increment_NewKeys mede o caminho lento: cada requisição cria uma chave
+func BenchmarkMemoryStorage_Increment_NewKeys(b *testing.B) {
+	storage := newBenchmarkMemoryStorage(b)
+	ctx := context.Background()
+
+	var next atomic.Uint64
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			key := "rate_limit:ip:" + strconv.FormatUint(next.Add(1), 10)
+			if _, _, err := storage.Increment(ctx, key, 1<<30, time.Hour); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkMemoryStorage_GC_ManyKeys mede um ciclo completo do GC com 1M de chaves
+// em memória: registros sem ponteiros não precisam ser percorridos
+func BenchmarkMemoryStorage_GC_ManyKeys(b *testing.B) {
+	const keys = 1 << 20
+
+	storage := NewMemoryStorageWithCapacity(logger.NewNopLogger(), keys)
+	b.Cleanup(func() { storage.Close() })
+	ctx := context.Background()
+	for i := 0; i < keys; i++ {
+		if _, _, err := storage.Increment(ctx, "rate_limit:ip:"+strconv.Itoa(i), 10, time.Hour); err != nil {
+			b.Fatal(err)
+		}
+	}
+	runtime.GC()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		runtime.GC()
+	}
+}
+
+// BenchmarkMemoryStorage_IncrementWithReads mede incrementos concorrendo com IsBlocked, como no middleware
+func BenchmarkMemoryStorage_IncrementWithReads(b *testing.B) {
+	storage := newBenchmarkMemoryStorage(b)
+	ctx := context.Background()
+	key := "rate_limit:token:abc123"
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, _, err := storage.IsBlocked(ctx, key); err != nil {
+				b.Fatal(err)
+			}
+			if _, _, err := storage.Increment(ctx, key, 1<<30, time.Hour); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,194 @@
+package storage
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryLRU ordena as chaves de uma partição do uso mais recente ao mais antigo.
+// Cada partição tem a sua, com lock próprio porque o caminho rápido do Increment
+// só segura o read lock da partição; chaves de partições diferentes não disputam
+// o mesmo lock
+type memoryLRU struct {
+	mutex    sync.Mutex
+	order    *list.List
+	elements map[string]*list.Element
+}
+
+// lruEntry é o valor de cada elemento da lista
+type lruEntry struct {
+	key  string
+	used int64 // Unix nanossegundos do último uso, para comparar partições
+}
+
+func newMemoryLRU() *memoryLRU {
+	return &memoryLRU{order: list.New(), elements: make(map[string]*list.Element)}
+}
+
+// add registra a chave como a mais recente e informa se ela é nova
+func (l *memoryLRU) add(key string, now int64) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if element, ok := l.elements[key]; ok {
+		element.Value.(*lruEntry).used = now
+		l.order.MoveToFront(element)
+		return false
+	}
+	l.elements[key] = l.order.PushFront(&lruEntry{key: key, used: now})
+	return true
+}
+
+// touch marca o uso de uma chave já registrada
+func (l *memoryLRU) touch(key string, now int64) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if element, ok := l.elements[key]; ok {
+		element.Value.(*lruEntry).used = now
+		l.order.MoveToFront(element)
+	}
+}
+
+// remove esquece a chave e informa se ela estava registrada
+func (l *memoryLRU) remove(key string) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	element, ok := l.elements[key]
+	if ok {
+		l.order.Remove(element)
+		delete(l.elements, key)
+	}
+	return ok
+}
+
+// oldest retorna o último uso da chave usada há mais tempo
+func (l *memoryLRU) oldest() (int64, bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	element := l.order.Back()
+	if element == nil {
+		return 0, false
+	}
+	return element.Value.(*lruEntry).used, true
+}
+
+// pop retira a chave usada há mais tempo
+func (l *memoryLRU) pop() (string, bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	element := l.order.Back()
+	if element == nil {
+		return "", false
+	}
+	entry := l.order.Remove(element).(*lruEntry)
+	delete(l.elements, entry.key)
+	return entry.key, true
+}
+
+// clear esquece todas as chaves
+func (l *memoryLRU) clear() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.order.Init()
+	l.elements = make(map[string]*list.Element)
+}
+
+// SetMaxEntries limita o número de chaves em memória (0 = sem limite). Ao passar
+// do limite, as chaves usadas há mais tempo são descartadas com todo o seu estado,
+// o que contém o crescimento sob varreduras com chaves novas entre duas limpezas.
+// Deve ser chamado antes do primeiro uso
+func (m *MemoryStorage) SetMaxEntries(maxEntries int) {
+	if maxEntries < 0 {
+		maxEntries = 0
+	}
+	m.maxEntries = maxEntries
+	for _, shard := range m.shards {
+		shard.lru = nil
+		if maxEntries > 0 {
+			shard.lru = newMemoryLRU()
+		}
+	}
+}
+
+// insert grava o registro da chave na partição e retorna o registro armazenado.
+// Exige o write lock da partição; as chaves acima do limite são descartadas pelo unlock
+func (m *MemoryStorage) insert(shard *memoryShard, key string, record memoryRecord) *memoryRecord {
+	stored := shard.data.put(key, record)
+	if shard.lru != nil && shard.lru.add(key, time.Now().UnixNano()) {
+		m.lruEntries.Add(1)
+	}
+	return stored
+}
+
+// enforceMaxEntries descarta as chaves usadas há mais tempo enquanto houver mais
+// que o limite, como o Reset. Chamado sem lock de partição
+func (m *MemoryStorage) enforceMaxEntries() {
+	if m.maxEntries <= 0 {
+		return
+	}
+
+	for {
+		// Reserva um descarte: descartes concorrentes não passam do limite
+		entries := m.lruEntries.Load()
+		if entries <= int64(m.maxEntries) {
+			return
+		}
+		if !m.lruEntries.CompareAndSwap(entries, entries-1) {
+			continue
+		}
+
+		shard := m.oldestShard()
+		if shard == nil {
+			m.lruEntries.Add(1)
+			return
+		}
+
+		m.lockShard(context.Background(), shard)
+		key, ok := shard.lru.pop()
+		if ok {
+			shard.remove(key)
+			m.lruEvictions.Add(1)
+		} else {
+			// A partição esvaziou entre a escolha e o lock: tenta de novo
+			m.lruEntries.Add(1)
+		}
+		shard.mutex.Unlock()
+	}
+}
+
+// oldestShard retorna a partição cuja chave usada há mais tempo é a mais antiga
+// de todas. Consulta só o fim da lista de cada partição
+func (m *MemoryStorage) oldestShard() *memoryShard {
+	var oldest *memoryShard
+	var oldestUsed int64
+	for _, shard := range m.shards {
+		if used, ok := shard.lru.oldest(); ok && (oldest == nil || used < oldestUsed) {
+			oldest, oldestUsed = shard, used
+		}
+	}
+	return oldest
+}
+
+// touch marca o uso da chave; vale com o read ou o write lock, ou sem lock
+func (m *MemoryStorage) touch(key string) {
+	if m.maxEntries <= 0 {
+		return
+	}
+	m.shard(key).lru.touch(key, time.Now().UnixNano())
+}
+
+// untrack esquece a chave removida do storage. Exige o write lock da partição
+func (m *MemoryStorage) untrack(key string) {
+	if m.maxEntries <= 0 {
+		return
+	}
+	if m.shard(key).lru.remove(key) {
+		m.lruEntries.Add(-1)
+	}
+}
@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMemoryStorage_MaxEntries testa o descarte da chave usada há mais tempo
+func TestMemoryStorage_MaxEntries(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	storage := NewMemoryStorage(nil)
+	defer storage.Close()
+	storage.SetMaxEntries(2)
+
+	_, _, err := storage.Increment(ctx, "rate_limit:ip:10.0.0.1", 10, time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, storage.Block(ctx, "rate_limit:ip:10.0.0.2", time.Minute))
+	_, _, err = storage.Increment(ctx, "rate_limit:ip:10.0.0.1", 10, time.Minute)
+	require.NoError(t, err)
+
+	// Act: a chave nova passa do limite e descarta 10.0.0.2, usada há mais tempo
+	_, _, err = storage.Increment(ctx, "rate_limit:ip:10.0.0.3", 10, time.Minute)
+	require.NoError(t, err)
+
+	// Assert
+	kept, err := storage.Get(ctx, "rate_limit:ip:10.0.0.1")
+	require.NoError(t, err)
+	require.NotNil(t, kept)
+	assert.Equal(t, 2, kept.Count)
+
+	evicted, err := storage.Get(ctx, "rate_limit:ip:10.0.0.2")
+	require.NoError(t, err)
+	assert.Nil(t, evicted)
+	blocked, _, err := storage.IsBlocked(ctx, "rate_limit:ip:10.0.0.2")
+	require.NoError(t, err)
+	assert.False(t, blocked, "the block is evicted with the key")
+
+	stats := storage.GetStats()
+	assert.Equal(t, 2, stats["data_entries"])
+	assert.Equal(t, 2, stats["max_entries"])
+	assert.Equal(t, uint64(1), stats["lru_evictions"])
+	assert.Equal(t, uint64(1), storage.MetricsSnapshot().LRUEvictions)
+}
+
+// TestMemoryStorage_MaxEntries_ResetFreesSlot testa que chaves removidas não ocupam o limite
+func TestMemoryStorage_MaxEntries_ResetFreesSlot(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	storage := NewMemoryStorage(nil)
+	defer storage.Close()
+	storage.SetMaxEntries(2)
+
+	_, _, err := storage.Increment(ctx, "a", 10, time.Minute)
+	require.NoError(t, err)
+	_, _, err = storage.Increment(ctx, "b", 10, time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, storage.Reset(ctx, "b"))
+
+	// Act
+	_, _, err = storage.Increment(ctx, "c", 10, time.Minute)
+	require.NoError(t, err)
+
+	// Assert
+	status, err := storage.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.NotNil(t, status)
+	assert.Equal(t, uint64(0), storage.GetStats()["lru_evictions"])
+}
+
+// TestMemoryStorage_MaxEntries_ScanAttack testa o tamanho limitado sob chaves novas concorrentes
+func TestMemoryStorage_MaxEntries_ScanAttack(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	storage := NewMemoryStorage(nil)
+	defer storage.Close()
+	storage.SetMaxEntries(100)
+
+	// Act
+	var wg sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < 500; i++ {
+				key := fmt.Sprintf("rate_limit:ip:10.%d.%d.%d", worker, i/256, i%256)
+				storage.Increment(ctx, key, 10, time.Minute)
+				storage.IsBlocked(ctx, key)
+			}
+		}(worker)
+	}
+	wg.Wait()
+
+	// Assert
+	stats := storage.GetStats()
+	assert.Equal(t, 100, stats["data_entries"])
+	assert.Equal(t, uint64(8*500-100), stats["lru_evictions"])
+}
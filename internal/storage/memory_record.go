@@ -0,0 +1,241 @@
+package storage
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+
+	"rate-limiter/internal/domain"
+)
+
+// memoryRecord é a representação compacta de um status no MemoryStorage.
+// Tamanho fixo e sem ponteiros (strings, *time.Time): com milhões de chaves,
+// os registros não precisam ser percorridos pelo GC.
+// count é atômico para o caminho rápido do Increment, que só segura o read lock;
+// os demais campos só mudam sob o write lock
+type memoryRecord struct {
+	lastReset    int64 // Unix nanossegundos
+	resetAt      int64 // Unix nanossegundos; 0 = janela deslizante
+	blockedUntil int64 // Unix nanossegundos; 0 = sem bloqueio
+	count        int32
+	limit        int32
+	window       int32 // Segundos
+	credit       int32
+	previous     int32 // Contagem da janela anterior (sliding window counter)
+	limiterType  uint8
+	blocked      bool
+}
+
+// Tipos de limiter codificados no registro
+const (
+	recordTypeUnknown uint8 = iota
+	recordTypeIP
+	recordTypeToken
+)
+
+// newMemoryRecord converte um status para a representação compacta
+func newMemoryRecord(status *domain.RateLimitStatus) memoryRecord {
+	record := memoryRecord{
+		lastReset:   unixNano(status.LastReset),
+		count:       clampInt32(status.Count),
+		limit:       clampInt32(status.Limit),
+		window:      clampInt32(status.Window),
+		credit:      clampInt32(status.Credit),
+		limiterType: encodeLimiterType(status.Type),
+		blocked:     status.IsBlocked,
+	}
+	if status.ResetAt != nil {
+		record.resetAt = unixNano(*status.ResetAt)
+	}
+	if status.BlockedUntil != nil {
+		record.blockedUntil = unixNano(*status.BlockedUntil)
+	}
+	return record
+}
+
+// status reconstrói o status da chave a partir do registro
+func (r *memoryRecord) status(key string) *domain.RateLimitStatus {
+	status := &domain.RateLimitStatus{
+		Key:       key,
+		Type:      decodeLimiterType(r.limiterType),
+		Count:     int(r.loadCount()),
+		Limit:     int(r.limit),
+		Window:    int(r.window),
+		LastReset: fromUnixNano(r.lastReset),
+		IsBlocked: r.blocked,
+		Credit:    int(r.credit),
+	}
+	if r.resetAt != 0 {
+		resetAt := fromUnixNano(r.resetAt)
+		status.ResetAt = &resetAt
+	}
+	if r.blockedUntil != 0 {
+		blockedUntil := fromUnixNano(r.blockedUntil)
+		status.BlockedUntil = &blockedUntil
+	}
+	return status
+}
+
+// effectiveLimit retorna o limite considerando o crédito acumulado
+func (r *memoryRecord) effectiveLimit() int64 {
+	return int64(r.limit) + int64(r.credit)
+}
+
+// loadCount lê a contagem; com só o read lock, incrementos podem estar em andamento
+func (r *memoryRecord) loadCount() int32 {
+	return atomic.LoadInt32(&r.count)
+}
+
+// increment soma uma requisição, saturando no máximo do int32
+func (r *memoryRecord) increment() int32 {
+	for {
+		count := atomic.LoadInt32(&r.count)
+		if count == math.MaxInt32 {
+			return count
+		}
+		if atomic.CompareAndSwapInt32(&r.count, count, count+1) {
+			return count + 1
+		}
+	}
+}
+
+// incrementUpTo soma uma requisição só se a contagem não passar de limit
+func (r *memoryRecord) incrementUpTo(limit int32) (int32, bool) {
+	for {
+		count := atomic.LoadInt32(&r.count)
+		if count >= limit {
+			return count, false
+		}
+		if atomic.CompareAndSwapInt32(&r.count, count, count+1) {
+			return count + 1, true
+		}
+	}
+}
+
+// memoryRecordChunkSize é o número de registros de cada bloco de memoryRecords
+const memoryRecordChunkSize = 256
+
+// memoryRecords guarda os registros de uma partição em blocos de tamanho fixo,
+// indexados pela chave. Os blocos não têm ponteiros e nunca são movidos: o
+// ponteiro de um registro vale enquanto o lock da partição for mantido, mesmo
+// com inserções no meio. Exige o lock da partição (read para leitura)
+type memoryRecords struct {
+	index  map[string]uint32 // chave -> posição do registro
+	chunks []*[memoryRecordChunkSize]memoryRecord
+	free   []uint32 // posições liberadas, reaproveitadas antes de crescer
+	next   uint32   // próxima posição nunca usada
+}
+
+func newMemoryRecords(expectedKeys int) memoryRecords {
+	return memoryRecords{index: make(map[string]uint32, expectedKeys)}
+}
+
+// get retorna o registro da chave
+func (r *memoryRecords) get(key string) (*memoryRecord, bool) {
+	slot, exists := r.index[key]
+	if !exists {
+		return nil, false
+	}
+	return r.at(slot), true
+}
+
+// put grava (ou substitui) o registro da chave e retorna o registro armazenado
+func (r *memoryRecords) put(key string, record memoryRecord) *memoryRecord {
+	slot, exists := r.index[key]
+	if !exists {
+		slot = r.allocate()
+		r.index[key] = slot
+	}
+	stored := r.at(slot)
+	*stored = record
+	return stored
+}
+
+// delete remove o registro da chave, liberando a posição
+func (r *memoryRecords) delete(key string) {
+	slot, exists := r.index[key]
+	if !exists {
+		return
+	}
+	delete(r.index, key)
+	*r.at(slot) = memoryRecord{}
+	r.free = append(r.free, slot)
+}
+
+// len retorna o número de chaves
+func (r *memoryRecords) len() int {
+	return len(r.index)
+}
+
+// each percorre os registros; fn pode remover a chave visitada
+func (r *memoryRecords) each(fn func(key string, record *memoryRecord)) {
+	for key, slot := range r.index {
+		fn(key, r.at(slot))
+	}
+}
+
+func (r *memoryRecords) at(slot uint32) *memoryRecord {
+	return &r.chunks[slot/memoryRecordChunkSize][slot%memoryRecordChunkSize]
+}
+
+func (r *memoryRecords) allocate() uint32 {
+	if n := len(r.free); n > 0 {
+		slot := r.free[n-1]
+		r.free = r.free[:n-1]
+		return slot
+	}
+	if int(r.next/memoryRecordChunkSize) == len(r.chunks) {
+		r.chunks = append(r.chunks, new([memoryRecordChunkSize]memoryRecord))
+	}
+	slot := r.next
+	r.next++
+	return slot
+}
+
+func encodeLimiterType(limiterType domain.LimiterType) uint8 {
+	switch limiterType {
+	case domain.IPLimiter:
+		return recordTypeIP
+	case domain.TokenLimiter:
+		return recordTypeToken
+	default:
+		return recordTypeUnknown
+	}
+}
+
+func decodeLimiterType(limiterType uint8) domain.LimiterType {
+	switch limiterType {
+	case recordTypeIP:
+		return domain.IPLimiter
+	case recordTypeToken:
+		return domain.TokenLimiter
+	default:
+		return ""
+	}
+}
+
+func clampInt32(value int) int32 {
+	if value > math.MaxInt32 {
+		return math.MaxInt32
+	}
+	if value < math.MinInt32 {
+		return math.MinInt32
+	}
+	return int32(value)
+}
+
+// unixNano converte o instante, mantendo o zero como zero
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+// fromUnixNano é o inverso de unixNano
+func fromUnixNano(nanos int64) time.Time {
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
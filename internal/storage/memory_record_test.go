@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"testing"
+	"time"
+	"unsafe"
+
+	"rate-limiter/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRecord_RoundTrip(t *testing.T) {
+	// Arrange
+	lastReset := time.Now()
+	resetAt := lastReset.Add(time.Hour)
+	blockedUntil := lastReset.Add(3 * time.Minute)
+	status := &domain.RateLimitStatus{
+		Key:          "ignored",
+		Type:         domain.TokenLimiter,
+		Count:        11,
+		Limit:        10,
+		Window:       3600,
+		LastReset:    lastReset,
+		BlockedUntil: &blockedUntil,
+		IsBlocked:    true,
+		ResetAt:      &resetAt,
+		Credit:       5,
+	}
+
+	// Act
+	record := newMemoryRecord(status)
+	restored := record.status("rate_limit:token:abc")
+
+	// Assert
+	assert.Equal(t, "rate_limit:token:abc", restored.Key)
+	assert.Equal(t, domain.TokenLimiter, restored.Type)
+	assert.Equal(t, 11, restored.Count)
+	assert.Equal(t, 10, restored.Limit)
+	assert.Equal(t, 3600, restored.Window)
+	assert.Equal(t, 5, restored.Credit)
+	assert.True(t, restored.IsBlocked)
+	assert.True(t, lastReset.Equal(restored.LastReset))
+	require.NotNil(t, restored.ResetAt)
+	assert.True(t, resetAt.Equal(*restored.ResetAt))
+	require.NotNil(t, restored.BlockedUntil)
+	assert.True(t, blockedUntil.Equal(*restored.BlockedUntil))
+
+	// Campos ausentes continuam ausentes
+	emptyRecord := newMemoryRecord(&domain.RateLimitStatus{})
+	empty := emptyRecord.status("key")
+	assert.True(t, empty.LastReset.IsZero())
+	assert.Nil(t, empty.ResetAt)
+	assert.Nil(t, empty.BlockedUntil)
+}
+
+func TestMemoryRecord_Compact(t *testing.T) {
+	// O registro cabe em 48 bytes; o RateLimitStatus ocupa mais que o dobro, sem contar as alocações dos ponteiros
+	assert.LessOrEqual(t, int(unsafe.Sizeof(memoryRecord{})), 48)
+
+	record := memoryRecord{count: math.MaxInt32}
+	record.increment()
+	assert.Equal(t, int32(math.MaxInt32), record.count)
+	assert.Equal(t, int32(math.MaxInt32), clampInt32(math.MaxInt64))
+
+	count, ok := record.incrementUpTo(math.MaxInt32)
+	assert.False(t, ok)
+	assert.Equal(t, int32(math.MaxInt32), count)
+}
+
+func TestMemoryRecords_ReusesSlotsAndKeepsAddresses(t *testing.T) {
+	// Arrange
+	records := newMemoryRecords(0)
+	first := records.put("a", memoryRecord{count: 1})
+
+	// Act: inserções que abrem novos blocos não movem os registros existentes
+	for i := 0; i < 3*memoryRecordChunkSize; i++ {
+		records.put("key:"+strconv.Itoa(i), memoryRecord{count: int32(i)})
+	}
+	records.delete("key:0")
+	reused := records.put("b", memoryRecord{count: 2})
+
+	// Assert
+	stored, exists := records.get("a")
+	require.True(t, exists)
+	assert.Same(t, first, stored)
+	assert.Equal(t, int32(1), first.count)
+
+	_, exists = records.get("key:0")
+	assert.False(t, exists)
+	assert.Equal(t, 3*memoryRecordChunkSize+1, records.len())
+	assert.Equal(t, uint32(3*memoryRecordChunkSize+1), records.next, "freed slot is reused")
+	assert.Equal(t, int32(2), reused.count)
+}
+
+func TestNewMemoryStorageWithCapacity(t *testing.T) {
+	// Arrange
+	storage := NewMemoryStorageWithCapacity(nil, 1000)
+	defer storage.Close()
+
+	// Act
+	count, _, err := storage.Increment(context.Background(), "rate_limit:ip:10.0.0.1", 10, time.Minute)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
@@ -0,0 +1,176 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// memoryShardCount é o número de partições do MemoryStorage. Chaves em partições
+// diferentes não disputam o mesmo lock
+const memoryShardCount = 64
+
+// memoryShard guarda o estado de um subconjunto das chaves, com lock próprio
+type memoryShard struct {
+	mutex  sync.RWMutex
+	data   memoryRecords
+	blocks map[string]int64 // chave -> bloqueado até (Unix nanossegundos)
+
+	// Sliding window log: instantes (Unix nanossegundos) das requisições aceitas por chave
+	logs map[string][]int64
+
+	// Token bucket: fichas restantes por chave
+	buckets map[string]*tokenBucketState
+
+	// Leaky bucket: requisições na fila por chave
+	leaks map[string]*leakyBucketState
+
+	// Janelas já fechadas por chave
+	history map[string]*memoryWindowHistory
+
+	// Ordem de uso das chaves com registro (nil = sem SetMaxEntries)
+	lru *memoryLRU
+
+	lockAcquisitions atomic.Uint64
+	lockWaitNanos    atomic.Uint64
+}
+
+func newMemoryShard(expectedKeys int) *memoryShard {
+	shard := &memoryShard{}
+	shard.clear(expectedKeys)
+	return shard
+}
+
+// clear descarta todo o estado da partição. Exige o write lock
+func (s *memoryShard) clear(expectedKeys int) {
+	s.data = newMemoryRecords(expectedKeys)
+	s.blocks = make(map[string]int64)
+	s.logs = make(map[string][]int64)
+	s.buckets = make(map[string]*tokenBucketState)
+	s.leaks = make(map[string]*leakyBucketState)
+	s.history = make(map[string]*memoryWindowHistory)
+}
+
+// remove descarta todo o estado da chave. Exige o write lock
+func (s *memoryShard) remove(key string) {
+	s.data.delete(key)
+	delete(s.blocks, key)
+	delete(s.logs, key)
+	delete(s.buckets, key)
+	delete(s.leaks, key)
+	delete(s.history, key)
+}
+
+// shardIndex distribui as chaves entre as partições (FNV-1a, sem alocação)
+func shardIndex(key string) int {
+	hash := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		hash ^= uint32(key[i])
+		hash *= 16777619
+	}
+	return int(hash % memoryShardCount)
+}
+
+// shard retorna a partição da chave
+func (m *MemoryStorage) shard(key string) *memoryShard {
+	return m.shards[shardIndex(key)]
+}
+
+// lock adquire o write lock da partição da chave. Libere com unlock, que também
+// aplica o limite de SetMaxEntries
+func (m *MemoryStorage) lock(ctx context.Context, key string) *memoryShard {
+	shard := m.shard(key)
+	m.lockShard(ctx, shard)
+	return shard
+}
+
+// rlock adquire o read lock da partição da chave
+func (m *MemoryStorage) rlock(ctx context.Context, key string) *memoryShard {
+	shard := m.shard(key)
+	m.rlockShard(ctx, shard)
+	return shard
+}
+
+// unlock libera o write lock e descarta as chaves acima do limite, sem segurar
+// o lock de uma partição enquanto adquire o de outra
+func (m *MemoryStorage) unlock(shard *memoryShard) {
+	shard.mutex.Unlock()
+	m.enforceMaxEntries()
+}
+
+// lockKeys adquire o write lock das partições das chaves, sempre em ordem
+// crescente para não haver deadlock, e retorna a função que libera todas
+func (m *MemoryStorage) lockKeys(ctx context.Context, keys []string) func() {
+	indexes := make([]int, 0, len(keys))
+	seen := make(map[int]bool, len(keys))
+	for _, key := range keys {
+		if index := shardIndex(key); !seen[index] {
+			seen[index] = true
+			indexes = append(indexes, index)
+		}
+	}
+	sort.Ints(indexes)
+
+	for _, index := range indexes {
+		m.lockShard(ctx, m.shards[index])
+	}
+	return func() {
+		for i := len(indexes) - 1; i >= 0; i-- {
+			m.shards[indexes[i]].mutex.Unlock()
+		}
+		m.enforceMaxEntries()
+	}
+}
+
+// lockShard adquire o write lock registrando o tempo de espera (métricas e span em ctx)
+func (m *MemoryStorage) lockShard(ctx context.Context, shard *memoryShard) {
+	start := time.Now()
+	shard.mutex.Lock()
+	wait := time.Since(start)
+	shard.recordLockWait(wait)
+	recordLockWaitEvent(ctx, "write", wait)
+}
+
+// rlockShard adquire o read lock registrando o tempo de espera (métricas e span em ctx)
+func (m *MemoryStorage) rlockShard(ctx context.Context, shard *memoryShard) {
+	start := time.Now()
+	shard.mutex.RLock()
+	wait := time.Since(start)
+	shard.recordLockWait(wait)
+	recordLockWaitEvent(ctx, "read", wait)
+}
+
+// recordLockWait acumula o tempo de espera pelo lock da partição
+func (s *memoryShard) recordLockWait(wait time.Duration) {
+	s.lockAcquisitions.Add(1)
+	s.lockWaitNanos.Add(uint64(wait))
+}
+
+// eachShard percorre as partições uma a uma sob o write lock
+func (m *MemoryStorage) eachShard(fn func(shard *memoryShard)) {
+	for _, shard := range m.shards {
+		m.lockShard(context.Background(), shard)
+		fn(shard)
+		shard.mutex.Unlock()
+	}
+}
+
+// eachShardRead percorre as partições uma a uma sob o read lock
+func (m *MemoryStorage) eachShardRead(fn func(shard *memoryShard)) {
+	for _, shard := range m.shards {
+		m.rlockShard(context.Background(), shard)
+		fn(shard)
+		shard.mutex.RUnlock()
+	}
+}
+
+// entryCounts soma as chaves e os bloqueios de todas as partições
+func (m *MemoryStorage) entryCounts() (data, blocks int) {
+	m.eachShardRead(func(shard *memoryShard) {
+		data += shard.data.len()
+		blocks += len(shard.blocks)
+	})
+	return data, blocks
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"rate-limiter/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestShardIndex testa que a partição é estável por chave e espalha chaves parecidas
+func TestShardIndex(t *testing.T) {
+	// Act
+	used := make(map[int]bool)
+	for i := 0; i < 1024; i++ {
+		key := fmt.Sprintf("rate_limit:ip:10.0.%d.%d", i/256, i%256)
+		index := shardIndex(key)
+		require.Equal(t, index, shardIndex(key))
+		require.GreaterOrEqual(t, index, 0)
+		require.Less(t, index, memoryShardCount)
+		used[index] = true
+	}
+
+	// Assert
+	assert.Len(t, used, memoryShardCount)
+}
+
+// TestMemoryStorage_ShardLocks testa que uma chave só usa o lock da sua partição
+func TestMemoryStorage_ShardLocks(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	storage := NewMemoryStorage(nil)
+	defer storage.Close()
+	key := "rate_limit:ip:10.0.0.1"
+
+	// Act
+	for i := 0; i < 3; i++ {
+		_, _, err := storage.Increment(ctx, key, 10, time.Minute)
+		require.NoError(t, err)
+	}
+	snapshot := storage.MetricsSnapshot()
+
+	// Assert: o próprio MetricsSnapshot lê cada partição uma vez
+	for index, locks := range snapshot.ShardLocks {
+		if index == shardIndex(key) {
+			assert.Greater(t, locks.Acquisitions, uint64(1))
+			continue
+		}
+		assert.Equal(t, uint64(1), locks.Acquisitions, "shard %d", index)
+	}
+}
+
+// TestMemoryStorage_Shards_ConcurrentHierarchy testa incrementos concorrentes de
+// hierarquias que cruzam partições em ordens diferentes, sem deadlock nem perda
+func TestMemoryStorage_Shards_ConcurrentHierarchy(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	storage := NewMemoryStorage(nil)
+	defer storage.Close()
+	organization := domain.HierarchyCounter{Key: BuildLevelKey(domain.LimitLevelOrganization, "acme"), Limit: 1 << 20}
+
+	// Act: cada token também incrementa chaves avulsas entre os níveis
+	var wg sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			token := domain.HierarchyCounter{Key: fmt.Sprintf("rate_limit:token:%d", worker), Limit: 1 << 20}
+			project := domain.HierarchyCounter{Key: BuildLevelKey(domain.LimitLevelProject, fmt.Sprint(worker%2)), Limit: 1 << 20}
+			for i := 0; i < 200; i++ {
+				_, err := storage.IncrementHierarchy(ctx, []domain.HierarchyCounter{token, project, organization}, time.Minute)
+				assert.NoError(t, err)
+				_, _, err = storage.Increment(ctx, fmt.Sprintf("rate_limit:ip:%d.%d", worker, i), 10, time.Minute)
+				assert.NoError(t, err)
+			}
+		}(worker)
+	}
+	wg.Wait()
+
+	// Assert
+	status, err := storage.Get(ctx, organization.Key)
+	require.NoError(t, err)
+	assert.Equal(t, 8*200, status.Count)
+	status, err = storage.Get(ctx, BuildLevelKey(domain.LimitLevelProject, "0"))
+	require.NoError(t, err)
+	assert.Equal(t, 4*200, status.Count)
+	assert.Equal(t, 8*200+8+2+1, storage.MetricsSnapshot().DataEntries)
+}
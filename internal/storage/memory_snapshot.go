@@ -0,0 +1,308 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"rate-limiter/internal/domain"
+)
+
+const (
+	// DefaultMemorySnapshotInterval é o intervalo padrão entre snapshots em disco
+	DefaultMemorySnapshotInterval = 30 * time.Second
+
+	// memorySnapshotVersion identifica o formato do arquivo
+	memorySnapshotVersion = 1
+)
+
+// MemorySnapshotConfig configura o snapshot do MemoryStorage em disco
+type MemorySnapshotConfig struct {
+	Path     string        // Arquivo do snapshot
+	Interval time.Duration // Intervalo entre snapshots (0 = DefaultMemorySnapshotInterval)
+	Cipher   *Cipher       // Cifra o arquivo inteiro (nil = JSON em claro)
+}
+
+// memorySnapshot é o conteúdo do arquivo: contadores, bloqueios, logs do sliding
+// window log e baldes. O histórico de janelas não é guardado
+type memorySnapshot struct {
+	Version int                       `json:"version"`
+	TakenAt time.Time                 `json:"takenAt"`
+	Records map[string]snapshotRecord `json:"records"`
+	Blocks  map[string]int64          `json:"blocks"`
+	Logs    map[string][]int64        `json:"logs,omitempty"`
+	Buckets map[string]snapshotBucket `json:"buckets,omitempty"`
+	Leaks   map[string]snapshotBucket `json:"leaks,omitempty"`
+}
+
+// snapshotRecord é o memoryRecord serializado (instantes em Unix nanossegundos)
+type snapshotRecord struct {
+	Count        int64 `json:"count"`
+	LastReset    int64 `json:"lastReset"`
+	ResetAt      int64 `json:"resetAt,omitempty"`
+	BlockedUntil int64 `json:"blockedUntil,omitempty"`
+	Limit        int32 `json:"limit"`
+	Window       int32 `json:"window"`
+	Credit       int32 `json:"credit,omitempty"`
+	Previous     int32 `json:"previous,omitempty"`
+	Type         uint8 `json:"type"`
+	Blocked      bool  `json:"blocked,omitempty"`
+}
+
+// snapshotBucket é o estado de um token bucket (fichas) ou leaky bucket (nível)
+type snapshotBucket struct {
+	Value   float64 `json:"value"`
+	Updated int64   `json:"updated"`
+}
+
+// Snapshot serializa o estado atual do storage
+func (m *MemoryStorage) Snapshot() ([]byte, error) {
+	snapshot := memorySnapshot{
+		Version: memorySnapshotVersion,
+		TakenAt: m.now(),
+		Records: make(map[string]snapshotRecord),
+		Blocks:  make(map[string]int64),
+		Logs:    make(map[string][]int64),
+		Buckets: make(map[string]snapshotBucket),
+		Leaks:   make(map[string]snapshotBucket),
+	}
+	// Cada partição é lida de forma consistente; as demais seguem atendendo
+	m.eachShardRead(func(shard *memoryShard) {
+		snapshot.add(shard)
+	})
+
+	return json.Marshal(snapshot)
+}
+
+// add copia o estado da partição para o snapshot. Exige o read lock da partição
+func (snapshot *memorySnapshot) add(shard *memoryShard) {
+	shard.data.each(func(key string, record *memoryRecord) {
+		snapshot.Records[key] = snapshotRecord{
+			Count:        int64(record.loadCount()),
+			LastReset:    record.lastReset,
+			ResetAt:      record.resetAt,
+			BlockedUntil: record.blockedUntil,
+			Limit:        record.limit,
+			Window:       record.window,
+			Credit:       record.credit,
+			Previous:     record.previous,
+			Type:         record.limiterType,
+			Blocked:      record.blocked,
+		}
+	})
+	for key, blockedUntil := range shard.blocks {
+		snapshot.Blocks[key] = blockedUntil
+	}
+	for key, entries := range shard.logs {
+		snapshot.Logs[key] = append([]int64(nil), entries...)
+	}
+	for key, bucket := range shard.buckets {
+		snapshot.Buckets[key] = snapshotBucket{Value: bucket.tokens, Updated: bucket.updated}
+	}
+	for key, leak := range shard.leaks {
+		snapshot.Leaks[key] = snapshotBucket{Value: leak.level, Updated: leak.updated}
+	}
+}
+
+// Restore carrega um snapshot gerado por Snapshot, sobrescrevendo as chaves
+// presentes nele. Entradas que venceram enquanto a instância estava parada são
+// descartadas. Retorna quantas chaves continuam no storage
+func (m *MemoryStorage) Restore(data []byte) (int, error) {
+	var snapshot memorySnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return 0, fmt.Errorf("failed to decode memory snapshot: %w", err)
+	}
+	if snapshot.Version != memorySnapshotVersion {
+		return 0, fmt.Errorf("unsupported memory snapshot version %d", snapshot.Version)
+	}
+
+	for key, saved := range snapshot.Records {
+		record := memoryRecord{
+			lastReset:    saved.LastReset,
+			resetAt:      saved.ResetAt,
+			blockedUntil: saved.BlockedUntil,
+			limit:        saved.Limit,
+			window:       saved.Window,
+			credit:       saved.Credit,
+			previous:     saved.Previous,
+			limiterType:  saved.Type,
+			count:        clampInt32(int(saved.Count)),
+			blocked:      saved.Blocked,
+		}
+		m.restoreKey(key, func(shard *memoryShard) { m.insert(shard, key, record) })
+	}
+	for key, blockedUntil := range snapshot.Blocks {
+		m.restoreKey(key, func(shard *memoryShard) { shard.blocks[key] = blockedUntil })
+	}
+	for key, entries := range snapshot.Logs {
+		m.restoreKey(key, func(shard *memoryShard) { shard.logs[key] = entries })
+	}
+	for key, bucket := range snapshot.Buckets {
+		state := &tokenBucketState{tokens: bucket.Value, updated: bucket.Updated}
+		m.restoreKey(key, func(shard *memoryShard) { shard.buckets[key] = state })
+	}
+	for key, leak := range snapshot.Leaks {
+		state := &leakyBucketState{level: leak.Value, updated: leak.Updated}
+		m.restoreKey(key, func(shard *memoryShard) { shard.leaks[key] = state })
+	}
+
+	m.cleanupExpiredEntries()
+
+	keys := 0
+	m.eachShardRead(func(shard *memoryShard) {
+		keys += shard.data.len()
+		for key := range shard.blocks {
+			if _, ok := shard.data.get(key); !ok {
+				keys++
+			}
+		}
+	})
+	return keys, nil
+}
+
+// restoreKey aplica uma entrada do snapshot sob o write lock da partição da chave
+func (m *MemoryStorage) restoreKey(key string, apply func(shard *memoryShard)) {
+	shard := m.lock(context.Background(), key)
+	apply(shard)
+	m.unlock(shard)
+}
+
+// MemorySnapshotter grava periodicamente o MemoryStorage em disco e o restaura
+// na inicialização, para que um reinício curto não zere contadores nem
+// desbloqueie clientes abusivos. O arquivo é substituído atomicamente
+type MemorySnapshotter struct {
+	storage  *MemoryStorage
+	path     string
+	interval time.Duration
+	cipher   *Cipher
+	logger   domain.Logger
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// NewMemorySnapshotter restaura o último snapshot, se houver, e inicia os
+// snapshots periódicos. Um snapshot ilegível é ignorado e o storage começa vazio
+func NewMemorySnapshotter(storage *MemoryStorage, config MemorySnapshotConfig, logger domain.Logger) *MemorySnapshotter {
+	if config.Interval <= 0 {
+		config.Interval = DefaultMemorySnapshotInterval
+	}
+
+	snapshotter := &MemorySnapshotter{
+		storage:  storage,
+		path:     config.Path,
+		interval: config.Interval,
+		cipher:   config.Cipher,
+		logger:   logger,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+
+	keys, err := snapshotter.Restore()
+	if logger != nil {
+		if err != nil {
+			logger.Error("Failed to restore memory snapshot, starting empty", err, map[string]interface{}{
+				"path": config.Path,
+			})
+		} else {
+			logger.Info("Memory snapshots enabled", map[string]interface{}{
+				"path":          config.Path,
+				"interval_s":    config.Interval.Seconds(),
+				"encrypted":     config.Cipher != nil,
+				"restored_keys": keys,
+			})
+		}
+	}
+
+	go snapshotter.run()
+	return snapshotter
+}
+
+// Save grava o snapshot em um arquivo temporário e o renomeia sobre o anterior
+func (s *MemorySnapshotter) Save() error {
+	data, err := s.storage.Snapshot()
+	if err != nil {
+		return fmt.Errorf("failed to encode memory snapshot: %w", err)
+	}
+	if s.cipher != nil {
+		data = s.cipher.Seal(data)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create memory snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write memory snapshot: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write memory snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write memory snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to replace memory snapshot: %w", err)
+	}
+	return nil
+}
+
+// Restore carrega o snapshot do disco; sem arquivo, não há o que restaurar
+func (s *MemorySnapshotter) Restore() (int, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read memory snapshot: %w", err)
+	}
+	if s.cipher != nil {
+		if data, err = s.cipher.Open(data); err != nil {
+			return 0, fmt.Errorf("failed to decrypt memory snapshot: %w", err)
+		}
+	}
+	return s.storage.Restore(data)
+}
+
+// Close encerra os snapshots periódicos e grava o último
+func (s *MemorySnapshotter) Close() error {
+	var err error
+	s.stopOnce.Do(func() {
+		close(s.stop)
+		<-s.done
+		err = s.Save()
+	})
+	return err
+}
+
+// run grava um snapshot a cada intervalo até o Close
+func (s *MemorySnapshotter) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			if err := s.Save(); err != nil && s.logger != nil {
+				s.logger.Warn("Memory snapshot failed", map[string]interface{}{
+					"path":  s.path,
+					"error": err.Error(),
+				})
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"rate-limiter/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMemorySnapshotter_RestoresAfterRestart testa contadores e bloqueios entre duas instâncias
+func TestMemorySnapshotter_RestoresAfterRestart(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "memory.snapshot")
+	config := MemorySnapshotConfig{Path: path, Interval: time.Hour}
+
+	before := NewMemoryStorage(nil)
+	snapshotter := NewMemorySnapshotter(before, config, nil)
+	_, _, err := before.Increment(ctx, "rate_limit:ip:10.0.0.1", 10, time.Minute)
+	require.NoError(t, err)
+	_, _, err = before.Increment(ctx, "rate_limit:ip:10.0.0.1", 10, time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, before.Block(ctx, "rate_limit:ip:10.0.0.2", time.Minute))
+	_, err = before.TakeToken(ctx, "rate_limit:token:abc123", domain.TokenBucket{Capacity: 5, RefillRate: 1})
+	require.NoError(t, err)
+	require.NoError(t, snapshotter.Close())
+
+	// Act
+	after := NewMemoryStorage(nil)
+	restarted := NewMemorySnapshotter(after, config, nil)
+	defer restarted.Close()
+
+	// Assert
+	status, err := after.Get(ctx, "rate_limit:ip:10.0.0.1")
+	require.NoError(t, err)
+	require.NotNil(t, status)
+	assert.Equal(t, 2, status.Count)
+	blocked, blockedUntil, err := after.IsBlocked(ctx, "rate_limit:ip:10.0.0.2")
+	require.NoError(t, err)
+	assert.True(t, blocked, "a restart must not unblock the client")
+	assert.NotNil(t, blockedUntil)
+	bucket, err := after.TakeToken(ctx, "rate_limit:token:abc123", domain.TokenBucket{Capacity: 5, RefillRate: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 3, bucket.Limit-bucket.Count, "the bucket keeps the tokens already taken")
+}
+
+// TestMemoryStorage_RestoreDropsExpiredEntries testa o descarte do que venceu com a instância parada
+func TestMemoryStorage_RestoreDropsExpiredEntries(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	source := NewMemoryStorage(nil)
+	require.NoError(t, source.Block(ctx, "rate_limit:ip:10.0.0.1", time.Millisecond))
+	require.NoError(t, source.Block(ctx, "rate_limit:ip:10.0.0.2", time.Minute))
+	data, err := source.Snapshot()
+	require.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+
+	// Act
+	target := NewMemoryStorage(nil)
+	_, err = target.Restore(data)
+
+	// Assert
+	require.NoError(t, err)
+	expired, _, err := target.IsBlocked(ctx, "rate_limit:ip:10.0.0.1")
+	require.NoError(t, err)
+	active, _, err := target.IsBlocked(ctx, "rate_limit:ip:10.0.0.2")
+	require.NoError(t, err)
+	assert.False(t, expired)
+	assert.True(t, active)
+	assert.Equal(t, 1, target.MetricsSnapshot().BlockEntries)
+}
+
+// TestMemorySnapshotter_Encrypted testa o arquivo cifrado e a rejeição de outra chave
+func TestMemorySnapshotter_Encrypted(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "memory.snapshot")
+	cipher, err := NewCipher(bytes.Repeat([]byte{1}, 32))
+	require.NoError(t, err)
+	otherCipher, err := NewCipher(bytes.Repeat([]byte{2}, 32))
+	require.NoError(t, err)
+
+	source := NewMemoryStorage(nil)
+	snapshotter := NewMemorySnapshotter(source, MemorySnapshotConfig{Path: path, Cipher: cipher}, nil)
+	require.NoError(t, source.Block(ctx, "rate_limit:ip:10.0.0.1", time.Minute))
+	require.NoError(t, snapshotter.Close())
+
+	// Act
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	_, restoreErr := (&MemorySnapshotter{storage: NewMemoryStorage(nil), path: path, cipher: otherCipher}).Restore()
+	keys, err := (&MemorySnapshotter{storage: NewMemoryStorage(nil), path: path, cipher: cipher}).Restore()
+
+	// Assert
+	assert.NotContains(t, string(raw), "10.0.0.1")
+	assert.ErrorIs(t, restoreErr, ErrInvalidCiphertext)
+	require.NoError(t, err)
+	assert.Equal(t, 1, keys)
+}
+
+func TestMemorySnapshotter_MissingFile(t *testing.T) {
+	snapshotter := &MemorySnapshotter{storage: NewMemoryStorage(nil), path: filepath.Join(t.TempDir(), "missing.snapshot")}
+
+	keys, err := snapshotter.Restore()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, keys)
+}
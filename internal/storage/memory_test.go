@@ -22,14 +22,14 @@ func TestMemoryStorage_Get(t *testing.T) {
 			name: "Should return status when key exists",
 			key:  "rate_limit:ip:192.168.1.1",
 			setup: func(storage *MemoryStorage) {
-				storage.data["rate_limit:ip:192.168.1.1"] = &domain.RateLimitStatus{
+				storage.shard("rate_limit:ip:192.168.1.1").data.put("rate_limit:ip:192.168.1.1", newMemoryRecord(&domain.RateLimitStatus{
 					Key:       "rate_limit:ip:192.168.1.1",
 					Count:     5,
 					Limit:     10,
 					Window:    60,
 					LastReset: time.Now(),
 					IsBlocked: false,
-				}
+				}))
 			},
 			expected: &domain.RateLimitStatus{
 				Key:       "rate_limit:ip:192.168.1.1",
@@ -119,10 +119,10 @@ func TestMemoryStorage_Set(t *testing.T) {
 			assert.NoError(t, err)
 
 			// Verify data was stored
-			stored, exists := storage.data[tt.key]
+			stored, exists := storage.shard(tt.key).data.get(tt.key)
 			assert.True(t, exists)
-			assert.Equal(t, tt.status.Key, stored.Key)
-			assert.Equal(t, tt.status.Count, stored.Count)
+			assert.Equal(t, tt.status.Key, stored.status(tt.key).Key)
+			assert.Equal(t, tt.status.Count, stored.status(tt.key).Count)
 		})
 	}
 }
@@ -147,16 +147,18 @@ func TestMemoryStorage_Set_WithTTL(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Verify data exists initially
-	_, exists := storage.data[key]
+	storage.shard(key).mutex.RLock()
+	_, exists := storage.shard(key).data.get(key)
+	storage.shard(key).mutex.RUnlock()
 	assert.True(t, exists)
 
 	// Wait for TTL to expire
 	time.Sleep(150 * time.Millisecond)
 
 	// Verify data was removed
-	storage.mutex.RLock()
-	_, exists = storage.data[key]
-	storage.mutex.RUnlock()
+	storage.shard(key).mutex.RLock()
+	_, exists = storage.shard(key).data.get(key)
+	storage.shard(key).mutex.RUnlock()
 	assert.False(t, exists)
 }
 
@@ -185,14 +187,14 @@ func TestMemoryStorage_Increment(t *testing.T) {
 			limit:  10,
 			window: time.Minute,
 			setup: func(storage *MemoryStorage) {
-				storage.data["rate_limit:ip:192.168.1.2"] = &domain.RateLimitStatus{
+				storage.shard("rate_limit:ip:192.168.1.2").data.put("rate_limit:ip:192.168.1.2", newMemoryRecord(&domain.RateLimitStatus{
 					Key:       "rate_limit:ip:192.168.1.2",
 					Count:     5,
 					Limit:     10,
 					Window:    60,
 					LastReset: time.Now(),
 					IsBlocked: false,
-				}
+				}))
 			},
 			expectedCount: 6,
 			expectBlocked: false,
@@ -203,14 +205,14 @@ func TestMemoryStorage_Increment(t *testing.T) {
 			limit:  5,
 			window: time.Minute,
 			setup: func(storage *MemoryStorage) {
-				storage.data["rate_limit:ip:192.168.1.3"] = &domain.RateLimitStatus{
+				storage.shard("rate_limit:ip:192.168.1.3").data.put("rate_limit:ip:192.168.1.3", newMemoryRecord(&domain.RateLimitStatus{
 					Key:       "rate_limit:ip:192.168.1.3",
 					Count:     5,
 					Limit:     5,
 					Window:    60,
 					LastReset: time.Now(),
 					IsBlocked: false,
-				}
+				}))
 			},
 			expectedCount: 6,
 			expectBlocked: true,
@@ -221,14 +223,14 @@ func TestMemoryStorage_Increment(t *testing.T) {
 			limit:  10,
 			window: 100 * time.Millisecond,
 			setup: func(storage *MemoryStorage) {
-				storage.data["rate_limit:ip:192.168.1.4"] = &domain.RateLimitStatus{
+				storage.shard("rate_limit:ip:192.168.1.4").data.put("rate_limit:ip:192.168.1.4", newMemoryRecord(&domain.RateLimitStatus{
 					Key:       "rate_limit:ip:192.168.1.4",
 					Count:     5,
 					Limit:     10,
 					Window:    1,
 					LastReset: time.Now().Add(-2 * time.Second), // Expired
 					IsBlocked: false,
-				}
+				}))
 			},
 			expectedCount: 1,
 			expectBlocked: false,
@@ -253,13 +255,108 @@ func TestMemoryStorage_Increment(t *testing.T) {
 			assert.NotZero(t, lastReset)
 
 			// Verify storage state
-			stored := storage.data[tt.key]
+			record, _ := storage.shard(tt.key).data.get(tt.key)
+			stored := record.status(tt.key)
 			assert.Equal(t, tt.expectedCount, stored.Count)
 			assert.Equal(t, tt.expectBlocked, stored.IsBlocked)
 		})
 	}
 }
 
+func TestMemoryStorage_IncrementQuota(t *testing.T) {
+	// Arrange
+	testLogger := logger.NewLogger("debug", "text")
+	storage := NewMemoryStorage(testLogger)
+	ctx := context.Background()
+	key := "rate_limit:token:quota"
+	resetAt := time.Now().Add(time.Hour)
+
+	// Act & Assert - incrementa dentro do período
+	for i := 1; i <= 3; i++ {
+		status, err := storage.IncrementQuota(ctx, key, domain.QuotaPeriod{Limit: 2, ResetAt: resetAt})
+		assert.NoError(t, err)
+		assert.Equal(t, i, status.Count)
+		assert.True(t, resetAt.Equal(*status.ResetAt))
+		assert.Equal(t, i > 2, status.IsBlocked)
+	}
+
+	// Período expirado: contador reinicia com o novo reset
+	expireQuota(storage, key)
+
+	nextReset := time.Now().Add(2 * time.Hour)
+	status, err := storage.IncrementQuota(ctx, key, domain.QuotaPeriod{Limit: 2, ResetAt: nextReset})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, status.Count)
+	assert.False(t, status.IsBlocked)
+	assert.True(t, nextReset.Equal(*status.ResetAt))
+}
+
+// expireQuota faz o período atual da cota já ter passado
+func expireQuota(storage *MemoryStorage, key string) {
+	record, _ := storage.shard(key).data.get(key)
+	record.resetAt = time.Now().Add(-time.Second).UnixNano()
+}
+
+func TestMemoryStorage_IncrementQuota_Rollover(t *testing.T) {
+	// Arrange
+	testLogger := logger.NewLogger("debug", "text")
+	storage := NewMemoryStorage(testLogger)
+	ctx := context.Background()
+	key := "rate_limit:token:rollover"
+	period := domain.QuotaPeriod{Limit: 10, ResetAt: time.Now().Add(time.Hour), RolloverPercent: 50}
+
+	// Primeiro período: usa 4 de 10
+	for i := 0; i < 4; i++ {
+		_, err := storage.IncrementQuota(ctx, key, period)
+		assert.NoError(t, err)
+	}
+
+	// Act - período expira; 50% dos 6 restantes viram crédito
+	expireQuota(storage, key)
+	period.ResetAt = time.Now().Add(2 * time.Hour)
+
+	status, err := storage.IncrementQuota(ctx, key, period)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, status.Count)
+	assert.Equal(t, 3, status.Credit)
+	assert.Equal(t, 13, status.EffectiveLimit())
+
+	for i := 2; i <= 14; i++ {
+		status, err = storage.IncrementQuota(ctx, key, period)
+		assert.NoError(t, err)
+		assert.Equal(t, i > 13, status.IsBlocked)
+	}
+
+	// Período totalmente consumido não gera crédito
+	expireQuota(storage, key)
+	status, err = storage.IncrementQuota(ctx, key, period)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, status.Credit)
+}
+
+func TestRolloverCredit(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous domain.RateLimitStatus
+		percent  int
+		expected int
+	}{
+		{"Disabled", domain.RateLimitStatus{Limit: 10, Count: 0}, 0, 0},
+		{"Half of unused", domain.RateLimitStatus{Limit: 10, Count: 4}, 50, 3},
+		{"Includes previous credit", domain.RateLimitStatus{Limit: 10, Count: 5, Credit: 5}, 100, 10},
+		{"Capped at limit", domain.RateLimitStatus{Limit: 10, Count: 0, Credit: 10}, 100, 10},
+		{"Overused", domain.RateLimitStatus{Limit: 10, Count: 12}, 100, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, rolloverCredit(&tt.previous, tt.percent))
+		})
+	}
+}
+
 func TestMemoryStorage_IsBlocked(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -279,10 +376,10 @@ func TestMemoryStorage_IsBlocked(t *testing.T) {
 			name: "Should return false for non-blocked key",
 			key:  "rate_limit:ip:192.168.1.2",
 			setup: func(storage *MemoryStorage) {
-				storage.data["rate_limit:ip:192.168.1.2"] = &domain.RateLimitStatus{
+				storage.shard("rate_limit:ip:192.168.1.2").data.put("rate_limit:ip:192.168.1.2", newMemoryRecord(&domain.RateLimitStatus{
 					Key:       "rate_limit:ip:192.168.1.2",
 					IsBlocked: false,
-				}
+				}))
 			},
 			expectedBlocked: false,
 			expectedTime:   false,
@@ -291,10 +388,10 @@ func TestMemoryStorage_IsBlocked(t *testing.T) {
 			name: "Should return true for blocked key",
 			key:  "rate_limit:ip:192.168.1.3",
 			setup: func(storage *MemoryStorage) {
-				storage.data["rate_limit:ip:192.168.1.3"] = &domain.RateLimitStatus{
+				storage.shard("rate_limit:ip:192.168.1.3").data.put("rate_limit:ip:192.168.1.3", newMemoryRecord(&domain.RateLimitStatus{
 					Key:       "rate_limit:ip:192.168.1.3",
 					IsBlocked: true,
-				}
+				}))
 			},
 			expectedBlocked: true,
 			expectedTime:   false,
@@ -304,7 +401,7 @@ func TestMemoryStorage_IsBlocked(t *testing.T) {
 			key:  "rate_limit:ip:192.168.1.4",
 			setup: func(storage *MemoryStorage) {
 				futureTime := time.Now().Add(5 * time.Minute)
-				storage.blocks["rate_limit:ip:192.168.1.4"] = futureTime
+				storage.shard("rate_limit:ip:192.168.1.4").blocks["rate_limit:ip:192.168.1.4"] = futureTime.UnixNano()
 			},
 			expectedBlocked: true,
 			expectedTime:   true,
@@ -314,7 +411,7 @@ func TestMemoryStorage_IsBlocked(t *testing.T) {
 			key:  "rate_limit:ip:192.168.1.5",
 			setup: func(storage *MemoryStorage) {
 				pastTime := time.Now().Add(-5 * time.Minute)
-				storage.blocks["rate_limit:ip:192.168.1.5"] = pastTime
+				storage.shard("rate_limit:ip:192.168.1.5").blocks["rate_limit:ip:192.168.1.5"] = pastTime.UnixNano()
 			},
 			expectedBlocked: false,
 			expectedTime:   false,
@@ -366,11 +463,11 @@ func TestMemoryStorage_Block(t *testing.T) {
 			key:      "rate_limit:ip:192.168.1.2",
 			duration: 3 * time.Minute,
 			setup: func(storage *MemoryStorage) {
-				storage.data["rate_limit:ip:192.168.1.2"] = &domain.RateLimitStatus{
+				storage.shard("rate_limit:ip:192.168.1.2").data.put("rate_limit:ip:192.168.1.2", newMemoryRecord(&domain.RateLimitStatus{
 					Key:       "rate_limit:ip:192.168.1.2",
 					Count:     5,
 					IsBlocked: false,
-				}
+				}))
 			},
 		},
 	}
@@ -391,13 +488,14 @@ func TestMemoryStorage_Block(t *testing.T) {
 			assert.NoError(t, err)
 
 			// Verify block was set
-			blockedUntil, exists := storage.blocks[tt.key]
+			blockedUntil, exists := storage.shard(tt.key).blocks[tt.key]
 			assert.True(t, exists)
-			assert.True(t, blockedUntil.After(time.Now()))
+			assert.True(t, blockedUntil > time.Now().UnixNano())
 
 			// Verify status was updated
-			status := storage.data[tt.key]
-			assert.NotNil(t, status)
+			stored, exists := storage.shard(tt.key).data.get(tt.key)
+			assert.True(t, exists)
+			status := stored.status(tt.key)
 			assert.True(t, status.IsBlocked)
 			assert.NotNil(t, status.BlockedUntil)
 		})
@@ -412,11 +510,11 @@ func TestMemoryStorage_Reset(t *testing.T) {
 	key := "rate_limit:ip:192.168.1.1"
 	
 	// Setup data and block
-	storage.data[key] = &domain.RateLimitStatus{
+	storage.shard(key).data.put(key, newMemoryRecord(&domain.RateLimitStatus{
 		Key:   key,
 		Count: 5,
-	}
-	storage.blocks[key] = time.Now().Add(5 * time.Minute)
+	}))
+	storage.shard(key).blocks[key] = time.Now().Add(5 * time.Minute).UnixNano()
 
 	ctx := context.Background()
 
@@ -427,13 +525,41 @@ func TestMemoryStorage_Reset(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Verify data was removed
-	_, dataExists := storage.data[key]
+	_, dataExists := storage.shard(key).data.get(key)
 	assert.False(t, dataExists)
 
-	_, blockExists := storage.blocks[key]
+	_, blockExists := storage.shard(key).blocks[key]
 	assert.False(t, blockExists)
 }
 
+func TestMemoryStorage_Inspect(t *testing.T) {
+	// Arrange
+	storage := NewMemoryStorage(nil)
+	ctx := context.Background()
+	key := "rate_limit:ip:192.168.1.1"
+	blockedUntil := time.Now().Add(5 * time.Minute)
+	storage.shard(key).data.put(key, newMemoryRecord(&domain.RateLimitStatus{Key: key, Count: 11, Limit: 10}))
+	storage.shard(key).blocks[key] = blockedUntil.UnixNano()
+
+	// Act
+	record, err := storage.Inspect(ctx, key)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, record.Exists)
+	assert.Equal(t, "memory", record.Backend)
+	assert.Contains(t, record.Raw, `"count":11`)
+	assert.Equal(t, 11, record.Status.Count)
+	assert.True(t, blockedUntil.Equal(*record.BlockedUntil))
+	assert.Nil(t, record.TTL)
+
+	// Chave inexistente
+	record, err = storage.Inspect(ctx, "rate_limit:ip:10.0.0.1")
+	assert.NoError(t, err)
+	assert.False(t, record.Exists)
+	assert.Nil(t, record.Status)
+}
+
 func TestMemoryStorage_Health(t *testing.T) {
 	// Arrange
 	testLogger := logger.NewLogger("debug", "text")
@@ -453,16 +579,16 @@ func TestMemoryStorage_Close(t *testing.T) {
 	storage := NewMemoryStorage(testLogger)
 
 	// Add some data
-	storage.data["test"] = &domain.RateLimitStatus{Key: "test"}
-	storage.blocks["test"] = time.Now()
+	storage.shard("test").data.put("test", newMemoryRecord(&domain.RateLimitStatus{Key: "test"}))
+	storage.shard("test").blocks["test"] = time.Now().UnixNano()
 
 	// Act
 	err := storage.Close()
 
 	// Assert
 	assert.NoError(t, err)
-	assert.Empty(t, storage.data)
-	assert.Empty(t, storage.blocks)
+	assert.Zero(t, storage.shard("test").data.len())
+	assert.Empty(t, storage.shard("test").blocks)
 }
 
 func TestMemoryStorage_GetStats(t *testing.T) {
@@ -471,9 +597,9 @@ func TestMemoryStorage_GetStats(t *testing.T) {
 	storage := NewMemoryStorage(testLogger)
 
 	// Add some data
-	storage.data["test1"] = &domain.RateLimitStatus{Key: "test1"}
-	storage.data["test2"] = &domain.RateLimitStatus{Key: "test2"}
-	storage.blocks["block1"] = time.Now()
+	storage.shard("test1").data.put("test1", newMemoryRecord(&domain.RateLimitStatus{Key: "test1"}))
+	storage.shard("test2").data.put("test2", newMemoryRecord(&domain.RateLimitStatus{Key: "test2"}))
+	storage.shard("block1").blocks["block1"] = time.Now().UnixNano()
 
 	// Act
 	stats := storage.GetStats()
@@ -492,39 +618,39 @@ func TestMemoryStorage_CleanupExpiredEntries(t *testing.T) {
 	now := time.Now()
 	
 	// Add expired block
-	storage.blocks["expired_block"] = now.Add(-5 * time.Minute)
+	storage.shard("expired_block").blocks["expired_block"] = now.Add(-5 * time.Minute).UnixNano()
 	// Add valid block
-	storage.blocks["valid_block"] = now.Add(5 * time.Minute)
+	storage.shard("valid_block").blocks["valid_block"] = now.Add(5 * time.Minute).UnixNano()
 	
 	// Add expired data
-	storage.data["expired_data"] = &domain.RateLimitStatus{
+	storage.shard("expired_data").data.put("expired_data", newMemoryRecord(&domain.RateLimitStatus{
 		Key:       "expired_data",
 		Window:    60,
 		LastReset: now.Add(-3 * time.Minute), // Expired (> 2 * window)
-	}
+	}))
 	// Add valid data
-	storage.data["valid_data"] = &domain.RateLimitStatus{
+	storage.shard("valid_data").data.put("valid_data", newMemoryRecord(&domain.RateLimitStatus{
 		Key:       "valid_data",
 		Window:    60,
 		LastReset: now.Add(-30 * time.Second), // Valid
-	}
+	}))
 
 	// Act
 	storage.cleanupExpiredEntries()
 
 	// Assert
 	// Expired entries should be removed
-	_, expiredBlockExists := storage.blocks["expired_block"]
+	_, expiredBlockExists := storage.shard("expired_block").blocks["expired_block"]
 	assert.False(t, expiredBlockExists)
 
-	_, expiredDataExists := storage.data["expired_data"]
+	_, expiredDataExists := storage.shard("expired_data").data.get("expired_data")
 	assert.False(t, expiredDataExists)
 
 	// Valid entries should remain
-	_, validBlockExists := storage.blocks["valid_block"]
+	_, validBlockExists := storage.shard("valid_block").blocks["valid_block"]
 	assert.True(t, validBlockExists)
 
-	_, validDataExists := storage.data["valid_data"]
+	_, validDataExists := storage.shard("valid_data").data.get("valid_data")
 	assert.True(t, validDataExists)
 }
 
@@ -556,4 +682,65 @@ func TestMemoryStorage_ConcurrentAccess(t *testing.T) {
 	status, err := storage.Get(ctx, key)
 	assert.NoError(t, err)
 	assert.Equal(t, numGoroutines, status.Count)
-} 
\ No newline at end of file
+}
+
+func TestMemoryStorage_ConcurrentIncrement_CrossesLimit(t *testing.T) {
+	// Arrange: chave já existente, para que os incrementos usem o caminho rápido
+	storage := NewMemoryStorage(nil)
+	defer storage.Close()
+	ctx := context.Background()
+	key := "rate_limit:ip:192.168.1.1"
+	_, _, err := storage.Increment(ctx, key, 50, time.Minute)
+	assert.NoError(t, err)
+
+	// Act
+	numGoroutines := 20
+	done := make(chan int, numGoroutines)
+	for i := 0; i < numGoroutines; i++ {
+		go func() {
+			allowed := 0
+			for j := 0; j < 10; j++ {
+				count, _, err := storage.Increment(ctx, key, 50, time.Minute)
+				assert.NoError(t, err)
+				if count <= 50 {
+					allowed++
+				}
+			}
+			done <- allowed
+		}()
+	}
+	allowed := 1
+	for i := 0; i < numGoroutines; i++ {
+		allowed += <-done
+	}
+
+	// Assert: cada contagem é devolvida uma vez e a chave termina bloqueada
+	status, err := storage.Get(ctx, key)
+	assert.NoError(t, err)
+	assert.Equal(t, 201, status.Count)
+	assert.Equal(t, 50, allowed)
+	assert.True(t, status.IsBlocked)
+}
+
+func TestMemoryStorage_MetricsSnapshot(t *testing.T) {
+	// Arrange
+	storage := NewMemoryStorage(nil)
+	defer storage.Close()
+
+	past := time.Now().Add(-time.Hour)
+	storage.shard("rate_limit:ip:old").data.put("rate_limit:ip:old", newMemoryRecord(&domain.RateLimitStatus{Key: "rate_limit:ip:old", Window: 1, LastReset: past}))
+	storage.shard("rate_limit:ip:old").blocks["rate_limit:ip:old"] = past.UnixNano()
+
+	// Act
+	storage.cleanupExpiredEntries()
+	snapshot := storage.MetricsSnapshot()
+
+	// Assert
+	assert.Equal(t, 0, snapshot.DataEntries)
+	assert.Equal(t, 0, snapshot.BlockEntries)
+	assert.Equal(t, uint64(1), snapshot.CleanupRemovedData)
+	assert.Equal(t, uint64(1), snapshot.CleanupRemovedBlocks)
+	// A limpeza e o próprio snapshot percorrem cada partição uma vez
+	assert.Equal(t, uint64(2*memoryShardCount), snapshot.LockAcquisitions)
+	assert.Len(t, snapshot.ShardLocks, memoryShardCount)
+}
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// MeterName identifica as métricas emitidas pelos storages
+const MeterName = "rate-limiter/internal/storage"
+
+// storageMetrics são os instrumentos criados no MeterProvider global vigente.
+// Sem provider configurado as medições não têm custo
+type storageMetrics struct {
+	provider metric.MeterProvider
+	duration metric.Float64Histogram
+
+	mutex   sync.RWMutex
+	options map[StorageType]map[string][]metric.RecordOption
+}
+
+var currentStorageMetrics atomic.Pointer[storageMetrics]
+
+// loadStorageMetrics retorna os instrumentos do provider global, recriados quando
+// ele é trocado (ex: tracing.SetupMetrics ou testes)
+func loadStorageMetrics() *storageMetrics {
+	provider := otel.GetMeterProvider()
+	if current := currentStorageMetrics.Load(); current != nil && current.provider == provider {
+		return current
+	}
+
+	duration, err := provider.Meter(MeterName).Float64Histogram(
+		"rate_limiter.storage.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("Duration of rate limiter storage operations."),
+	)
+	if err != nil {
+		otel.Handle(err)
+	}
+	loaded := &storageMetrics{
+		provider: provider,
+		duration: duration,
+		options:  make(map[StorageType]map[string][]metric.RecordOption),
+	}
+	currentStorageMetrics.Store(loaded)
+	return loaded
+}
+
+// recordOptions retorna os atributos da operação sem montá-los a cada medição
+func (m *storageMetrics) recordOptions(backend StorageType, operation string) []metric.RecordOption {
+	m.mutex.RLock()
+	options, ok := m.options[backend][operation]
+	m.mutex.RUnlock()
+	if ok {
+		return options
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.options[backend] == nil {
+		m.options[backend] = make(map[string][]metric.RecordOption)
+	}
+	options = []metric.RecordOption{metric.WithAttributeSet(attribute.NewSet(
+		attribute.String("db.system", string(backend)),
+		attribute.String("db.operation", operation),
+	))}
+	m.options[backend][operation] = options
+	return options
+}
+
+// storageSpan é o span de uma operação de storage; End também registra a duração
+type storageSpan struct {
+	trace.Span
+	ctx       context.Context
+	backend   StorageType
+	operation string
+	start     time.Time
+}
+
+// End encerra o span e registra a duração da operação
+func (s storageSpan) End(options ...trace.SpanEndOption) {
+	s.Span.End(options...)
+	if metrics := loadStorageMetrics(); metrics.duration != nil {
+		metrics.duration.Record(s.ctx, time.Since(s.start).Seconds(), metrics.recordOptions(s.backend, s.operation)...)
+	}
+}
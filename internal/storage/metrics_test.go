@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	metricnoop "go.opentelemetry.io/otel/metric/noop"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// useMetricReader instala um meter provider com leitura manual durante o teste.
+// Ao fim, instala um provider noop: o provider global original passa a delegar ao
+// do teste na primeira troca e continuaria medindo nos testes seguintes
+func useMetricReader(t *testing.T) *sdkmetric.ManualReader {
+	reader := sdkmetric.NewManualReader()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	t.Cleanup(func() { otel.SetMeterProvider(metricnoop.NewMeterProvider()) })
+	return reader
+}
+
+func TestMemoryStorage_DurationMetric(t *testing.T) {
+	// Arrange
+	reader := useMetricReader(t)
+	memory := NewMemoryStorage(nil)
+	defer memory.Close()
+
+	// Act
+	for i := 0; i < 3; i++ {
+		_, _, err := memory.Increment(context.Background(), "rate_limit:ip:10.0.0.1", 10, time.Minute)
+		require.NoError(t, err)
+	}
+	_, _, err := memory.IsBlocked(context.Background(), "rate_limit:ip:10.0.0.1")
+	require.NoError(t, err)
+
+	var collected metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &collected))
+
+	// Assert: um histograma por operação, sem a chave nos atributos
+	require.Len(t, collected.ScopeMetrics, 1)
+	assert.Equal(t, MeterName, collected.ScopeMetrics[0].Scope.Name)
+	require.Len(t, collected.ScopeMetrics[0].Metrics, 1)
+	duration := collected.ScopeMetrics[0].Metrics[0]
+	assert.Equal(t, "rate_limiter.storage.duration", duration.Name)
+	assert.Equal(t, "s", duration.Unit)
+
+	counts := make(map[string]uint64)
+	for _, point := range duration.Data.(metricdata.Histogram[float64]).DataPoints {
+		system, _ := point.Attributes.Value("db.system")
+		operation, _ := point.Attributes.Value("db.operation")
+		assert.Equal(t, "memory", system.AsString())
+		assert.Equal(t, 2, point.Attributes.Len())
+		counts[operation.AsString()] = point.Count
+	}
+	assert.Equal(t, map[string]uint64{"INCREMENT": 3, "IS_BLOCKED": 1}, counts)
+}
@@ -0,0 +1,84 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"rate-limiter/internal/domain"
+)
+
+// PrefixedStorage isola um conjunto de chaves dentro de outro storage
+// Usado pela avaliação em shadow para contar sem tocar nos contadores aplicados
+type PrefixedStorage struct {
+	inner  domain.RateLimiterStorage
+	prefix string
+}
+
+// NewPrefixedStorage cria uma visão do storage com as chaves prefixadas
+func NewPrefixedStorage(inner domain.RateLimiterStorage, prefix string) *PrefixedStorage {
+	return &PrefixedStorage{inner: inner, prefix: prefix}
+}
+
+// Get implementa domain.RateLimiterStorage
+func (p *PrefixedStorage) Get(ctx context.Context, key string) (*domain.RateLimitStatus, error) {
+	return p.inner.Get(ctx, p.prefix+key)
+}
+
+// Set implementa domain.RateLimiterStorage
+func (p *PrefixedStorage) Set(ctx context.Context, key string, status *domain.RateLimitStatus, ttl time.Duration) error {
+	return p.inner.Set(ctx, p.prefix+key, status, ttl)
+}
+
+// Increment implementa domain.RateLimiterStorage
+func (p *PrefixedStorage) Increment(ctx context.Context, key string, limit int, window time.Duration) (int, time.Time, error) {
+	return p.inner.Increment(ctx, p.prefix+key, limit, window)
+}
+
+// IncrementQuota implementa domain.RateLimiterStorage
+func (p *PrefixedStorage) IncrementQuota(ctx context.Context, key string, period domain.QuotaPeriod) (*domain.RateLimitStatus, error) {
+	return p.inner.IncrementQuota(ctx, p.prefix+key, period)
+}
+
+// TakeToken implementa domain.RateLimiterStorage
+func (p *PrefixedStorage) TakeToken(ctx context.Context, key string, bucket domain.TokenBucket) (*domain.RateLimitStatus, error) {
+	return p.inner.TakeToken(ctx, p.prefix+key, bucket)
+}
+
+// Leak implementa domain.RateLimiterStorage
+func (p *PrefixedStorage) Leak(ctx context.Context, key string, bucket domain.LeakyBucket) (*domain.RateLimitStatus, error) {
+	return p.inner.Leak(ctx, p.prefix+key, bucket)
+}
+
+// IsBlocked implementa domain.RateLimiterStorage
+func (p *PrefixedStorage) IsBlocked(ctx context.Context, key string) (bool, *time.Time, error) {
+	return p.inner.IsBlocked(ctx, p.prefix+key)
+}
+
+// Block implementa domain.RateLimiterStorage
+func (p *PrefixedStorage) Block(ctx context.Context, key string, duration time.Duration) error {
+	return p.inner.Block(ctx, p.prefix+key, duration)
+}
+
+// Reset implementa domain.RateLimiterStorage
+func (p *PrefixedStorage) Reset(ctx context.Context, key string) error {
+	return p.inner.Reset(ctx, p.prefix+key)
+}
+
+// Inspect implementa domain.StorageInspector quando o storage interno o suporta
+func (p *PrefixedStorage) Inspect(ctx context.Context, key string) (*domain.StorageRecord, error) {
+	inspector, ok := p.inner.(domain.StorageInspector)
+	if !ok {
+		return nil, domain.ErrInspectionUnsupported
+	}
+	return inspector.Inspect(ctx, p.prefix+key)
+}
+
+// Health implementa domain.RateLimiterStorage
+func (p *PrefixedStorage) Health(ctx context.Context) error {
+	return p.inner.Health(ctx)
+}
+
+// Close não fecha o storage compartilhado (o dono do storage interno o fecha)
+func (p *PrefixedStorage) Close() error {
+	return nil
+}
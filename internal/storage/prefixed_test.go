@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/logger"
+)
+
+func TestPrefixedStorage_IsolatesKeys(t *testing.T) {
+	// Arrange
+	inner := NewMemoryStorage(logger.NewNopLogger())
+	defer inner.Close()
+	shadow := NewPrefixedStorage(inner, "shadow:")
+	ctx := context.Background()
+
+	// Act
+	_, _, err := inner.Increment(ctx, "rate_limit:ip:10.0.0.1", 10, time.Minute)
+	require.NoError(t, err)
+	count, _, err := shadow.Increment(ctx, "rate_limit:ip:10.0.0.1", 10, time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, shadow.Block(ctx, "rate_limit:ip:10.0.0.1", time.Minute))
+
+	// Assert
+	assert.Equal(t, 1, count)
+
+	blocked, _, err := inner.IsBlocked(ctx, "rate_limit:ip:10.0.0.1")
+	require.NoError(t, err)
+	assert.False(t, blocked)
+
+	blocked, _, err = inner.IsBlocked(ctx, "shadow:rate_limit:ip:10.0.0.1")
+	require.NoError(t, err)
+	assert.True(t, blocked)
+
+	// Close não fecha o storage compartilhado
+	require.NoError(t, shadow.Close())
+	assert.NoError(t, inner.Health(ctx))
+}
@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"sort"
+	"sync"
+
+	"rate-limiter/internal/domain"
+)
+
+// ReadinessStatus descreve o aquecimento da instância antes de receber tráfego
+type ReadinessStatus struct {
+	Ready          bool     `json:"ready"`
+	HealthyChecks  int      `json:"healthyChecks"`
+	RequiredChecks int      `json:"requiredChecks"`
+	PendingSteps   []string `json:"pendingSteps,omitempty"`
+	Draining       bool     `json:"draining,omitempty"`
+}
+
+// ReadinessGate segura a readiness na inicialização até o storage passar
+// N health checks consecutivos e as etapas registradas (ex: restauração de estado) concluírem
+// Depois de liberado, o gate só volta a fechar na drenagem para o desligamento:
+// falhas posteriores são tratadas pelo HealthMonitor
+type ReadinessGate struct {
+	health         domain.StorageHealthReporter
+	requiredChecks int
+
+	mutex    sync.Mutex
+	pending  map[string]struct{}
+	open     bool
+	draining bool
+}
+
+// NewReadinessGate cria o gate; requiredChecks <= 0 exige apenas um check saudável
+func NewReadinessGate(health domain.StorageHealthReporter, requiredChecks int) *ReadinessGate {
+	if requiredChecks <= 0 {
+		requiredChecks = 1
+	}
+	return &ReadinessGate{
+		health:         health,
+		requiredChecks: requiredChecks,
+		pending:        make(map[string]struct{}),
+	}
+}
+
+// Require registra uma etapa de inicialização que precisa concluir antes da readiness
+func (g *ReadinessGate) Require(step string) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if !g.open {
+		g.pending[step] = struct{}{}
+	}
+}
+
+// Complete marca a etapa como concluída
+func (g *ReadinessGate) Complete(step string) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	delete(g.pending, step)
+}
+
+// Drain fecha o gate em definitivo, para o balanceador parar de enviar tráfego
+// antes do desligamento
+func (g *ReadinessGate) Drain() {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.draining = true
+}
+
+// Readiness retorna o estado atual do aquecimento
+func (g *ReadinessGate) Readiness() ReadinessStatus {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	status := ReadinessStatus{RequiredChecks: g.requiredChecks}
+	if g.health != nil {
+		status.HealthyChecks = g.health.HealthStatus().ConsecutiveSuccesses
+	} else {
+		status.HealthyChecks = g.requiredChecks
+	}
+
+	if !g.open && status.HealthyChecks >= g.requiredChecks && len(g.pending) == 0 {
+		g.open = true
+	}
+
+	status.Ready = g.open && !g.draining
+	status.Draining = g.draining
+	for step := range g.pending {
+		status.PendingSteps = append(status.PendingSteps, step)
+	}
+	sort.Strings(status.PendingSteps)
+	return status
+}
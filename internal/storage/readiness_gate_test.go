@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/logger"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// staticHealth é um StorageHealthReporter com estado controlado pelo teste
+type staticHealth struct {
+	health domain.StorageHealth
+}
+
+func (s *staticHealth) IsHealthy() bool                    { return s.health.Healthy }
+func (s *staticHealth) HealthStatus() domain.StorageHealth { return s.health }
+
+func TestReadinessGate_WaitsForConsecutiveChecks(t *testing.T) {
+	health := &staticHealth{health: domain.StorageHealth{Healthy: true, ConsecutiveSuccesses: 1}}
+	gate := NewReadinessGate(health, 3)
+
+	status := gate.Readiness()
+	assert.False(t, status.Ready)
+	assert.Equal(t, 1, status.HealthyChecks)
+	assert.Equal(t, 3, status.RequiredChecks)
+
+	health.health.ConsecutiveSuccesses = 3
+	assert.True(t, gate.Readiness().Ready)
+
+	// Depois de aberto, o gate não fecha novamente
+	health.health = domain.StorageHealth{Healthy: false, ConsecutiveFailures: 1}
+	assert.True(t, gate.Readiness().Ready)
+}
+
+func TestReadinessGate_Drain(t *testing.T) {
+	health := &staticHealth{health: domain.StorageHealth{Healthy: true, ConsecutiveSuccesses: 1}}
+	gate := NewReadinessGate(health, 1)
+	assert.True(t, gate.Readiness().Ready)
+
+	gate.Drain()
+
+	status := gate.Readiness()
+	assert.False(t, status.Ready)
+	assert.True(t, status.Draining)
+}
+
+func TestReadinessGate_PendingSteps(t *testing.T) {
+	health := &staticHealth{health: domain.StorageHealth{Healthy: true, ConsecutiveSuccesses: 5}}
+	gate := NewReadinessGate(health, 1)
+	gate.Require("state_restore")
+
+	status := gate.Readiness()
+	assert.False(t, status.Ready)
+	assert.Equal(t, []string{"state_restore"}, status.PendingSteps)
+
+	gate.Complete("state_restore")
+	status = gate.Readiness()
+	assert.True(t, status.Ready)
+	assert.Empty(t, status.PendingSteps)
+}
+
+func TestReadinessGate_WithHealthMonitor(t *testing.T) {
+	testLogger := logger.NewLogger("error", "text")
+	storage := &flakyStorage{MemoryStorage: NewMemoryStorage(testLogger), healthy: true}
+	monitor := NewHealthMonitor(storage, testLogger, 10*time.Millisecond, 40*time.Millisecond)
+	gate := NewReadinessGate(monitor, 3)
+
+	monitor.Start()
+	defer monitor.Stop()
+
+	// O primeiro check é síncrono; os demais chegam pelo loop do monitor
+	assert.False(t, gate.Readiness().Ready)
+	assert.Eventually(t, func() bool { return gate.Readiness().Ready }, time.Second, 5*time.Millisecond)
+}
@@ -2,204 +2,741 @@ package storage
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"rate-limiter/internal/domain"
 
 	"github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // RedisStorage implementa a interface domain.RateLimiterStorage usando Redis
 type RedisStorage struct {
-	client redis.Cmdable
-	logger domain.Logger
+	client  redis.Cmdable
+	logger  domain.Logger
+	options *redis.Options
+	mutex   sync.RWMutex
+
+	failover *redis.FailoverOptions // Modo sentinel (nil = conexão direta por options)
+
+	algorithm   Algorithm // Algoritmo de contagem do Increment
+	historySize int       // Janelas guardadas por chave em <chave>:history (0 = desabilitado)
+
+	blockPartitions int // Partições do índice de chaves bloqueadas (0 = sem índice)
+
+	retry *RetryPolicy // Novas tentativas das operações (nil = uma tentativa só)
+
+	cipher *Cipher // Cifra dos valores gravados (nil = JSON em claro)
 }
 
 // NewRedisStorage cria uma nova instância do RedisStorage
 func NewRedisStorage(host, port, password string, db int, logger domain.Logger) (*RedisStorage, error) {
-	// Configura cliente Redis
-	rdb := redis.NewClient(&redis.Options{
+	return NewRedisStorageWithOptions(&redis.Options{
 		Addr:     fmt.Sprintf("%s:%s", host, port),
 		Password: password,
 		DB:       db,
-		
-		// Configurações de performance
-		PoolSize:        20,
-		MinIdleConns:    5,
-		MaxRetries:      3,
-		DialTimeout:     5 * time.Second,
-		ReadTimeout:     3 * time.Second,
-		WriteTimeout:    3 * time.Second,
-		PoolTimeout:     4 * time.Second,
-		IdleTimeout:     5 * time.Minute,
-	})
+	}, logger)
+}
+
+// NewRedisStorageWithOptions cria o storage com as opções de conexão informadas
+// (usuário ACL, TLS), aplicando as configurações de performance do storage
+func NewRedisStorageWithOptions(options *redis.Options, logger domain.Logger) (*RedisStorage, error) {
+	// Configurações de performance
+	options.PoolSize = 20
+	options.MinIdleConns = 5
+	options.MaxRetries = -1 // Novas tentativas pela RetryPolicy do storage
+	options.DialTimeout = 5 * time.Second
+	options.ReadTimeout = 3 * time.Second
+	options.WriteTimeout = 3 * time.Second
+	options.PoolTimeout = 4 * time.Second
+	options.IdleTimeout = 5 * time.Minute
+	rdb := redis.NewClient(options)
 
 	// Testa a conexão
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
 	if err := rdb.Ping(ctx).Err(); err != nil {
+		rdb.Close()
 		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
 	}
 
 	logger.Info("Redis connection established", map[string]interface{}{
-		"host": host,
-		"port": port,
-		"db":   db,
+		"addr": options.Addr,
+		"db":   options.DB,
+		"tls":  options.TLSConfig != nil,
 	})
 
+	loadScripts(ctx, rdb, logger)
+
 	return &RedisStorage{
-		client: rdb,
-		logger: logger,
+		client:  rdb,
+		logger:  logger,
+		options: options,
+		retry:   NewRetryPolicy(RetryConfig{}),
 	}, nil
 }
 
+// NewRedisStorageWithClient cria o storage sobre um cliente já configurado,
+// sem testar a conexão (ex: miniredis em testes)
+func NewRedisStorageWithClient(client *redis.Client, logger domain.Logger) *RedisStorage {
+	loadScripts(context.Background(), client, logger)
+
+	return &RedisStorage{
+		client:  client,
+		logger:  logger,
+		options: client.Options(),
+	}
+}
+
+// redisRecord é o status como gravado no Redis. Os instantes são epoch em
+// milissegundos, o mesmo formato lido e gravado pelos scripts Lua
+type redisRecord struct {
+	Key          string             `json:"key"`
+	Type         domain.LimiterType `json:"type"`
+	Count        int                `json:"count"`
+	Limit        int                `json:"limit"`
+	Window       int                `json:"window"`
+	LastReset    int64              `json:"lastReset"`
+	BlockedUntil *int64             `json:"blockedUntil,omitempty"`
+	IsBlocked    bool               `json:"isBlocked"`
+	ResetAt      *int64             `json:"resetAt,omitempty"`
+	Credit       int                `json:"credit,omitempty"`
+}
+
+// encodeStatus serializa o status no formato dos scripts Lua, cifrado se houver cipher
+func (r *RedisStorage) encodeStatus(status *domain.RateLimitStatus) ([]byte, error) {
+	return r.encodeRecord(newRedisRecord(status))
+}
+
+// encodeRecord serializa o registro. Com cipher, o JSON é cifrado por inteiro e
+// gravado em base64, sem o campo key: o nome da chave já identifica o registro
+func (r *RedisStorage) encodeRecord(record redisRecord) ([]byte, error) {
+	if r.cipher != nil {
+		record.Key = ""
+	}
+	data, err := json.Marshal(record)
+	if err != nil || r.cipher == nil {
+		return data, err
+	}
+	return []byte(base64.StdEncoding.EncodeToString(r.cipher.Seal(data))), nil
+}
+
+// decodeRecord interpreta um registro gravado pelo encodeRecord ou pelos scripts Lua
+func (r *RedisStorage) decodeRecord(data []byte) (redisRecord, error) {
+	var record redisRecord
+	if r.cipher != nil {
+		sealed, err := base64.StdEncoding.DecodeString(string(data))
+		if err != nil {
+			return record, ErrInvalidCiphertext
+		}
+		if data, err = r.cipher.Open(sealed); err != nil {
+			return record, err
+		}
+	}
+	err := json.Unmarshal(data, &record)
+	return record, err
+}
+
+// newRedisRecord converte o status para o formato gravado
+func newRedisRecord(status *domain.RateLimitStatus) redisRecord {
+	record := redisRecord{
+		Key:       status.Key,
+		Type:      status.Type,
+		Count:     status.Count,
+		Limit:     status.Limit,
+		Window:    status.Window,
+		LastReset: status.LastReset.UnixMilli(),
+		IsBlocked: status.IsBlocked,
+		Credit:    status.Credit,
+	}
+	if status.BlockedUntil != nil {
+		blockedUntil := status.BlockedUntil.UnixMilli()
+		record.BlockedUntil = &blockedUntil
+	}
+	if status.ResetAt != nil {
+		resetAt := status.ResetAt.UnixMilli()
+		record.ResetAt = &resetAt
+	}
+	return record
+}
+
+// decodeStatus interpreta o status gravado na chave pelo Go ou pelos scripts Lua
+func (r *RedisStorage) decodeStatus(key string, data []byte) (*domain.RateLimitStatus, error) {
+	record, err := r.decodeRecord(data)
+	if err != nil {
+		return nil, err
+	}
+	if record.Key == "" {
+		record.Key = key
+	}
+	return record.status(), nil
+}
+
+// status converte o registro gravado de volta para o status
+func (record redisRecord) status() *domain.RateLimitStatus {
+	status := &domain.RateLimitStatus{
+		Key:       record.Key,
+		Type:      record.Type,
+		Count:     record.Count,
+		Limit:     record.Limit,
+		Window:    record.Window,
+		LastReset: time.UnixMilli(record.LastReset),
+		IsBlocked: record.IsBlocked,
+		Credit:    record.Credit,
+	}
+	if record.BlockedUntil != nil {
+		blockedUntil := time.UnixMilli(*record.BlockedUntil)
+		status.BlockedUntil = &blockedUntil
+	}
+	if record.ResetAt != nil {
+		resetAt := time.UnixMilli(*record.ResetAt)
+		status.ResetAt = &resetAt
+	}
+	return status
+}
+
+// Reconnect recria o cliente Redis, substituindo a conexão atual
+func (r *RedisStorage) Reconnect(ctx context.Context) error {
+	if r.options == nil && r.failover == nil {
+		return fmt.Errorf("Redis options not available for reconnection")
+	}
+
+	ctx, span := startSpan(ctx, RedisStorageType, "RECONNECT", "")
+	defer span.End()
+
+	rdb := r.newClient()
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		rdb.Close()
+		err = fmt.Errorf("failed to reconnect to Redis: %w", err)
+		recordSpanError(ctx, err)
+		return err
+	}
+	loadScripts(ctx, rdb, r.logger)
+
+	r.mutex.Lock()
+	old := r.client
+	r.client = rdb
+	r.mutex.Unlock()
+
+	if client, ok := old.(*redis.Client); ok {
+		client.Close()
+	}
+
+	if r.logger != nil {
+		r.logger.Info("Redis connection re-established", r.connectionFields())
+	}
+	return nil
+}
+
+// eval executa um script Lua pelo SHA1 em um span próprio, separando o tempo do script do restante da operação.
+// Se o Redis perdeu o cache de scripts (restart, failover, SCRIPT FLUSH), recarrega o script e tenta de novo
+func (r *RedisStorage) eval(ctx context.Context, name string, script *redis.Script, keys []string, args ...interface{}) (interface{}, error) {
+	ctx, span := otel.Tracer(TracerName).Start(ctx, "redis.EVALSHA",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", string(RedisStorageType)),
+			attribute.String("db.operation", "EVALSHA"),
+			attribute.String("db.redis.script", name),
+		),
+	)
+	defer span.End()
+
+	var result interface{}
+	err := r.withRetry(ctx, func(ctx context.Context) error {
+		var err error
+		client := r.getClient()
+		result, err = script.EvalSha(ctx, client, keys, args...).Result()
+		if isNoScript(err) {
+			span.AddEvent("script reloaded")
+			if err = script.Load(ctx, client).Err(); err == nil {
+				result, err = script.EvalSha(ctx, client, keys, args...).Result()
+			}
+		}
+		return err
+	})
+	if err != nil {
+		recordSpanError(ctx, err)
+	}
+	return result, err
+}
+
+// isNoScript informa se o Redis não conhece o SHA1 do script
+func isNoScript(err error) bool {
+	return err != nil && strings.HasPrefix(err.Error(), "NOSCRIPT ")
+}
+
+// loadScripts carrega os scripts Lua no cache do Redis (SCRIPT LOAD).
+// Falhas não são fatais: eval recarrega o script ao receber NOSCRIPT
+func loadScripts(ctx context.Context, client redis.Cmdable, logger domain.Logger) {
+	for name, script := range map[string]*redis.Script{"increment": incrementScript, "quota": quotaScript, "sliding_log": slidingLogScript, "take_token": takeTokenScript, "leak": leakScript, "hierarchy": hierarchyScript, "check_limit": checkLimitScript, "block": blockScript} {
+		if err := script.Load(ctx, client).Err(); err != nil && logger != nil {
+			logger.Warn("Failed to preload Redis script", map[string]interface{}{
+				"script": name,
+				"error":  err.Error(),
+			})
+		}
+	}
+}
+
+// getClient retorna o cliente Redis atual de forma segura para concorrência
+func (r *RedisStorage) getClient() redis.Cmdable {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.client
+}
+
 // Get recupera o status atual de rate limit para uma chave
 func (r *RedisStorage) Get(ctx context.Context, key string) (*domain.RateLimitStatus, error) {
+	ctx, span := startSpan(ctx, RedisStorageType, "GET", key)
+	defer span.End()
+
 	start := time.Now()
 	
 	// Busca dados no Redis
-	result, err := r.client.Get(ctx, key).Result()
+	var result string
+	err := r.withRetry(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = r.getClient().Get(ctx, key).Result()
+		return err
+	})
 	if err != nil {
 		if err == redis.Nil {
 			// Chave não existe, retorna status vazio
-			r.logStorageOperation("GET", key, true, time.Since(start).Seconds()*1000, nil)
+			r.logStorageOperation(ctx, "GET", key, true, time.Since(start).Seconds()*1000, nil)
 			return nil, nil
 		}
-		r.logStorageOperation("GET", key, false, time.Since(start).Seconds()*1000, err)
+		r.logStorageOperation(ctx, "GET", key, false, time.Since(start).Seconds()*1000, err)
 		return nil, fmt.Errorf("failed to get key %s: %w", key, err)
 	}
 
 	// Parse do JSON
-	var status domain.RateLimitStatus
-	if err := json.Unmarshal([]byte(result), &status); err != nil {
-		r.logStorageOperation("GET", key, false, time.Since(start).Seconds()*1000, err)
+	status, err := r.decodeStatus(key, []byte(result))
+	if err != nil {
+		r.logStorageOperation(ctx, "GET", key, false, time.Since(start).Seconds()*1000, err)
 		return nil, fmt.Errorf("failed to unmarshal status for key %s: %w", key, err)
 	}
 
-	r.logStorageOperation("GET", key, true, time.Since(start).Seconds()*1000, nil)
-	return &status, nil
+	r.logStorageOperation(ctx, "GET", key, true, time.Since(start).Seconds()*1000, nil)
+	return status, nil
 }
 
 // Set define o status de rate limit para uma chave
 func (r *RedisStorage) Set(ctx context.Context, key string, status *domain.RateLimitStatus, ttl time.Duration) error {
+	ctx, span := startSpan(ctx, RedisStorageType, "SET", key)
+	defer span.End()
+
 	start := time.Now()
 
 	// Serializa para JSON
-	data, err := json.Marshal(status)
+	data, err := r.encodeStatus(status)
 	if err != nil {
-		r.logStorageOperation("SET", key, false, time.Since(start).Seconds()*1000, err)
+		r.logStorageOperation(ctx, "SET", key, false, time.Since(start).Seconds()*1000, err)
 		return fmt.Errorf("failed to marshal status for key %s: %w", key, err)
 	}
 
 	// Define no Redis com TTL
-	if err := r.client.Set(ctx, key, data, ttl).Err(); err != nil {
-		r.logStorageOperation("SET", key, false, time.Since(start).Seconds()*1000, err)
+	err = r.withRetry(ctx, func(ctx context.Context) error {
+		return r.getClient().Set(ctx, key, data, ttl).Err()
+	})
+	if err != nil {
+		r.logStorageOperation(ctx, "SET", key, false, time.Since(start).Seconds()*1000, err)
 		return fmt.Errorf("failed to set key %s: %w", key, err)
 	}
 
-	r.logStorageOperation("SET", key, true, time.Since(start).Seconds()*1000, nil)
+	r.logStorageOperation(ctx, "SET", key, true, time.Since(start).Seconds()*1000, nil)
 	return nil
 }
 
-// Increment incrementa o contador para uma chave e retorna o novo valor
-func (r *RedisStorage) Increment(ctx context.Context, key string, limit int, window time.Duration) (int, time.Time, error) {
-	start := time.Now()
-
-	// Script Lua para operação atômica
-	script := `
-		local key = KEYS[1]
-		local limit = tonumber(ARGV[1])
-		local window = tonumber(ARGV[2])
-		local now = tonumber(ARGV[3])
-		
-		-- Busca valor atual
-		local current = redis.call('GET', key)
-		local data = {}
-		
-		if current then
-			data = cjson.decode(current)
+// incrementSource incrementa atomicamente o contador da janela de uma chave.
+// ARGV[4] (opcional) soma vários incrementos de uma vez, usado pelo HybridStorage.
+// ARGV[5] = 'sliding_window' retorna a contagem ponderada com a janela anterior.
+// KEYS[2] e ARGV[6] (opcionais) mantêm a lista com as últimas ARGV[6] janelas
+const incrementSource = `
+	local key = KEYS[1]
+	local limit = tonumber(ARGV[1])
+	local window = tonumber(ARGV[2]) -- em milissegundos
+	local now = tonumber(ARGV[3])
+	local amount = tonumber(ARGV[4]) or 1
+	local sliding = ARGV[5] == 'sliding_window'
+	
+	-- Busca valor atual
+	local current = redis.call('GET', key)
+	local data = {}
+	
+	if current then
+		data = cjson.decode(current)
+	else
+		data = {
+			key = key,
+			type = '',
+			count = 0,
+			limit = limit,
+			window = math.floor(window / 1000),
+			lastReset = now,
+			isBlocked = false
+		}
+	end
+	
+	-- Verifica se precisa resetar a janela
+	local timeSinceReset = now - data.lastReset
+	if timeSinceReset >= window then
+		if sliding then
+			-- Janelas contíguas: a que acabou de fechar vira a anterior
+			local elapsed = math.floor(timeSinceReset / window)
+			if elapsed == 1 then
+				data.previousCount = data.count
+			else
+				data.previousCount = 0
+			end
+			data.lastReset = data.lastReset + elapsed * window
+			timeSinceReset = now - data.lastReset
 		else
-			data = {
-				key = key,
-				type = '',
-				count = 0,
-				limit = limit,
-				window = window,
-				lastReset = now,
-				isBlocked = false
-			}
-		end
-		
-		-- Verifica se precisa resetar a janela
-		local timeSinceReset = now - data.lastReset
-		if timeSinceReset >= window * 1000 then
-			data.count = 0
 			data.lastReset = now
-			data.isBlocked = false
 		end
-		
-		-- Incrementa contador
-		data.count = data.count + 1
-		
-		-- Verifica se excedeu o limite
-		if data.count > limit then
-			data.isBlocked = true
-			-- Define tempo de bloqueio (será usado externalmente)
+		data.count = 0
+		data.isBlocked = false
+	end
+	
+	-- Incrementa contador
+	local count
+	if sliding then
+		-- A janela anterior pesa a fração dela ainda coberta pela janela deslizante.
+		-- Requisições recusadas não entram no contador
+		local previous = data.previousCount or 0
+		count = math.floor(previous * (window - timeSinceReset) / window) + data.count + amount
+		if count <= limit then
+			data.count = data.count + amount
 		end
-		
-		-- Calcula TTL restante
-		local ttl = window - (timeSinceReset / 1000)
-		if ttl <= 0 then
-			ttl = window
+	else
+		data.count = data.count + amount
+		count = data.count
+	end
+	
+	-- Verifica se excedeu o limite
+	if count > limit then
+		data.isBlocked = true
+		-- Define tempo de bloqueio (será usado externalmente)
+	end
+	
+	-- Calcula TTL restante (ms)
+	local ttl = window - timeSinceReset
+	if ttl <= 0 then
+		ttl = window
+	end
+	if sliding then
+		-- A janela atual ainda pesa na próxima
+		ttl = ttl + window
+	end
+	
+	-- Salva no Redis
+	local encoded = cjson.encode(data)
+	redis.call('SET', key, encoded, 'PX', math.ceil(ttl))
+	
+	-- Histórico: o primeiro item é a janela atual, reescrito a cada incremento
+	local historySize = tonumber(ARGV[6]) or 0
+	if historySize > 0 and KEYS[2] then
+		local prefix = string.format('%d:', data.lastReset)
+		local entry = prefix .. data.count .. ':' .. limit
+		local head = redis.call('LINDEX', KEYS[2], 0)
+		if head and string.sub(head, 1, #prefix) == prefix then
+			redis.call('LSET', KEYS[2], 0, entry)
+		else
+			redis.call('LPUSH', KEYS[2], entry)
+			redis.call('LTRIM', KEYS[2], 0, historySize - 1)
 		end
-		
-		-- Salva no Redis
-		local encoded = cjson.encode(data)
-		redis.call('SET', key, encoded, 'EX', math.ceil(ttl))
-		
-		return {data.count, data.lastReset}
-	`
+		redis.call('PEXPIRE', KEYS[2], historySize * window)
+	end
+	
+	return {count, data.lastReset, data.blockedUntil or 0}
+`
+
+// Scripts Lua carregados uma vez (SCRIPT LOAD) e executados pelo SHA1 (EVALSHA),
+// sem enviar o corpo do script a cada requisição
+var (
+	incrementScript = redis.NewScript(incrementSource)
+	quotaScript     = redis.NewScript(quotaSource)
+)
+
+// Increment incrementa o contador para uma chave e retorna o novo valor
+func (r *RedisStorage) Increment(ctx context.Context, key string, limit int, window time.Duration) (int, time.Time, error) {
+	ctx, span := startSpan(ctx, RedisStorageType, "INCREMENT", key)
+	defer span.End()
+
+	start := time.Now()
+
+	if r.cipher != nil {
+		record, err := r.incrementSealed(ctx, key, limit, window, 1)
+		if err != nil {
+			r.logStorageOperation(ctx, "INCREMENT", key, false, time.Since(start).Seconds()*1000, err)
+			return 0, time.Time{}, fmt.Errorf("failed to increment key %s: %w", key, err)
+		}
+		r.logStorageOperation(ctx, "INCREMENT", key, true, time.Since(start).Seconds()*1000, nil)
+		return record.Count, time.UnixMilli(record.LastReset), nil
+	}
+
+	if r.algorithm == AlgorithmSlidingLog {
+		count, oldest, err := r.incrementSlidingLog(ctx, key, limit, window)
+		r.logStorageOperation(ctx, "INCREMENT", key, err == nil, time.Since(start).Seconds()*1000, err)
+		return count, oldest, err
+	}
 
 	now := time.Now().UnixMilli()
-	windowMs := int64(window.Seconds())
+	windowMs := window.Milliseconds()
 
-	result, err := r.client.Eval(ctx, script, []string{key}, limit, windowMs, now).Result()
+	var result interface{}
+	var err error
+	if r.historySize > 0 {
+		// Mesmo script, atualizando também a lista do histórico de janelas
+		result, err = r.eval(ctx, "increment", incrementScript, []string{key, key + windowHistorySuffix}, limit, windowMs, now, 1, string(r.algorithm), r.historySize)
+	} else if r.algorithm == AlgorithmSlidingWindow {
+		// Mesmo script, com a contagem ponderada entre a janela atual e a anterior
+		result, err = r.eval(ctx, "increment", incrementScript, []string{key}, limit, windowMs, now, 1, string(AlgorithmSlidingWindow))
+	} else {
+		result, err = r.eval(ctx, "increment", incrementScript, []string{key}, limit, windowMs, now)
+	}
 	if err != nil {
-		r.logStorageOperation("INCREMENT", key, false, time.Since(start).Seconds()*1000, err)
+		r.logStorageOperation(ctx, "INCREMENT", key, false, time.Since(start).Seconds()*1000, err)
 		return 0, time.Time{}, fmt.Errorf("failed to increment key %s: %w", key, err)
 	}
 
 	// Parse do resultado
 	resultSlice, ok := result.([]interface{})
-	if !ok || len(resultSlice) != 2 {
-		r.logStorageOperation("INCREMENT", key, false, time.Since(start).Seconds()*1000, fmt.Errorf("invalid result format"))
+	if !ok || len(resultSlice) < 2 {
+		r.logStorageOperation(ctx, "INCREMENT", key, false, time.Since(start).Seconds()*1000, fmt.Errorf("invalid result format"))
 		return 0, time.Time{}, fmt.Errorf("invalid increment result for key %s", key)
 	}
 
 	count, err := strconv.Atoi(fmt.Sprint(resultSlice[0]))
 	if err != nil {
-		r.logStorageOperation("INCREMENT", key, false, time.Since(start).Seconds()*1000, err)
+		r.logStorageOperation(ctx, "INCREMENT", key, false, time.Since(start).Seconds()*1000, err)
 		return 0, time.Time{}, fmt.Errorf("invalid count in result for key %s: %w", key, err)
 	}
 
 	lastResetMs, err := strconv.ParseInt(fmt.Sprint(resultSlice[1]), 10, 64)
 	if err != nil {
-		r.logStorageOperation("INCREMENT", key, false, time.Since(start).Seconds()*1000, err)
+		r.logStorageOperation(ctx, "INCREMENT", key, false, time.Since(start).Seconds()*1000, err)
 		return 0, time.Time{}, fmt.Errorf("invalid lastReset in result for key %s: %w", key, err)
 	}
 
 	lastReset := time.UnixMilli(lastResetMs)
 
-	r.logStorageOperation("INCREMENT", key, true, time.Since(start).Seconds()*1000, nil)
+	r.logStorageOperation(ctx, "INCREMENT", key, true, time.Since(start).Seconds()*1000, nil)
 	return count, lastReset, nil
 }
 
+// CounterDelta são incrementos acumulados fora do Redis para uma chave
+type CounterDelta struct {
+	Key    string
+	Delta  int
+	Limit  int
+	Window time.Duration
+}
+
+// CounterSnapshot é o estado global da chave depois de aplicar um CounterDelta
+type CounterSnapshot struct {
+	Key          string
+	Count        int
+	LastReset    time.Time
+	BlockedUntil *time.Time
+}
+
+// MergeCounters aplica os incrementos em lote, em um único round trip (pipeline
+// de EVALSHA do script de incremento), e retorna o estado global de cada chave
+func (r *RedisStorage) MergeCounters(ctx context.Context, deltas []CounterDelta) ([]CounterSnapshot, error) {
+	ctx, span := startSpan(ctx, RedisStorageType, "MERGE_COUNTERS", "")
+	defer span.End()
+	span.SetAttributes(attribute.Int("rate_limit.batch_size", len(deltas)))
+
+	start := time.Now()
+	if len(deltas) == 0 {
+		return nil, nil
+	}
+
+	if r.cipher != nil {
+		return r.mergeSealed(ctx, deltas, start)
+	}
+
+	client := r.getClient()
+	now := time.Now().UnixMilli()
+	run := func() ([]*redis.Cmd, error) {
+		cmds := make([]*redis.Cmd, len(deltas))
+		_, err := client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for i, delta := range deltas {
+				cmds[i] = incrementScript.EvalSha(ctx, pipe, []string{delta.Key}, delta.Limit, delta.Window.Milliseconds(), now, delta.Delta)
+			}
+			return nil
+		})
+		return cmds, err
+	}
+
+	cmds, err := run()
+	if isNoScript(err) {
+		span.AddEvent("script reloaded")
+		if err = incrementScript.Load(ctx, client).Err(); err == nil {
+			cmds, err = run()
+		}
+	}
+	if err != nil {
+		r.logStorageOperation(ctx, "MERGE_COUNTERS", "", false, time.Since(start).Seconds()*1000, err)
+		return nil, fmt.Errorf("failed to merge %d counters: %w", len(deltas), err)
+	}
+
+	snapshots := make([]CounterSnapshot, len(deltas))
+	for i, cmd := range cmds {
+		values, err := cmd.Int64Slice()
+		if err != nil || len(values) < 3 {
+			err = fmt.Errorf("invalid merge result for key %s: %v", deltas[i].Key, err)
+			r.logStorageOperation(ctx, "MERGE_COUNTERS", deltas[i].Key, false, time.Since(start).Seconds()*1000, err)
+			return nil, err
+		}
+
+		snapshots[i] = CounterSnapshot{
+			Key:       deltas[i].Key,
+			Count:     int(values[0]),
+			LastReset: time.UnixMilli(values[1]),
+		}
+		if values[2] > 0 {
+			blockedUntil := time.UnixMilli(values[2])
+			snapshots[i].BlockedUntil = &blockedUntil
+		}
+	}
+
+	r.logStorageOperation(ctx, "MERGE_COUNTERS", "", true, time.Since(start).Seconds()*1000, nil)
+	return snapshots, nil
+}
+
+// quotaSource incrementa atomicamente uma cota com reset absoluto
+const quotaSource = `
+	local key = KEYS[1]
+	local limit = tonumber(ARGV[1])
+	local resetAt = tonumber(ARGV[2])
+	local now = tonumber(ARGV[3])
+	local rollover = tonumber(ARGV[4])
+
+	-- Busca valor atual
+	local current = redis.call('GET', key)
+	local data = {}
+
+	if current then
+		data = cjson.decode(current)
+	end
+
+	-- Inicia um novo período quando não há reset registrado ou ele já passou
+	if data.resetAt == nil or now >= data.resetAt then
+		-- Calcula crédito da cota não utilizada no período anterior
+		local credit = 0
+		if data.resetAt ~= nil and rollover > 0 then
+			local unused = (data.limit or 0) + (data.credit or 0) - (data.count or 0)
+			if unused > 0 then
+				credit = math.floor(unused * rollover / 100)
+				if credit > (data.limit or 0) then
+					credit = data.limit or 0
+				end
+			end
+		end
+
+		data = {
+			key = key,
+			type = '',
+			count = 0,
+			window = math.floor((resetAt - now) / 1000),
+			lastReset = now,
+			resetAt = resetAt,
+			credit = credit,
+			isBlocked = false
+		}
+	end
+
+	-- Incrementa contador
+	data.count = data.count + 1
+	data.limit = limit
+
+	if data.count > limit + data.credit then
+		data.isBlocked = true
+	end
+
+	-- Expira a chave um ciclo após o período (mantém base para rollover)
+	local ttl = math.ceil((2 * data.resetAt - data.lastReset - now) / 1000)
+	if ttl <= 0 then
+		ttl = 1
+	end
+
+	redis.call('SET', key, cjson.encode(data), 'EX', ttl)
+
+	return {data.count, data.lastReset, data.resetAt, data.credit}
+`
+
+// IncrementQuota incrementa o contador de uma cota com reset absoluto
+func (r *RedisStorage) IncrementQuota(ctx context.Context, key string, period domain.QuotaPeriod) (*domain.RateLimitStatus, error) {
+	ctx, span := startSpan(ctx, RedisStorageType, "INCREMENT_QUOTA", key)
+	defer span.End()
+
+	start := time.Now()
+
+	if r.cipher != nil {
+		record, err := r.incrementQuotaSealed(ctx, key, period)
+		if err != nil {
+			r.logStorageOperation(ctx, "INCREMENT_QUOTA", key, false, time.Since(start).Seconds()*1000, err)
+			return nil, fmt.Errorf("failed to increment quota for key %s: %w", key, err)
+		}
+		record.Key = key
+		r.logStorageOperation(ctx, "INCREMENT_QUOTA", key, true, time.Since(start).Seconds()*1000, nil)
+		return record.status(), nil
+	}
+
+	now := time.Now().UnixMilli()
+	result, err := r.eval(ctx, "quota", quotaScript, []string{key}, period.Limit, period.ResetAt.UnixMilli(), now, period.RolloverPercent)
+	if err != nil {
+		r.logStorageOperation(ctx, "INCREMENT_QUOTA", key, false, time.Since(start).Seconds()*1000, err)
+		return nil, fmt.Errorf("failed to increment quota for key %s: %w", key, err)
+	}
+
+	// Parse do resultado
+	values, ok := result.([]interface{})
+	if !ok || len(values) != 4 {
+		r.logStorageOperation(ctx, "INCREMENT_QUOTA", key, false, time.Since(start).Seconds()*1000, fmt.Errorf("invalid result format"))
+		return nil, fmt.Errorf("invalid quota result for key %s", key)
+	}
+
+	parsed := make([]int64, len(values))
+	for i, value := range values {
+		parsed[i], err = strconv.ParseInt(fmt.Sprint(value), 10, 64)
+		if err != nil {
+			r.logStorageOperation(ctx, "INCREMENT_QUOTA", key, false, time.Since(start).Seconds()*1000, err)
+			return nil, fmt.Errorf("invalid quota result for key %s: %w", key, err)
+		}
+	}
+
+	resetAt := time.UnixMilli(parsed[2])
+	status := &domain.RateLimitStatus{
+		Key:       key,
+		Count:     int(parsed[0]),
+		Limit:     period.Limit,
+		LastReset: time.UnixMilli(parsed[1]),
+		ResetAt:   &resetAt,
+		Credit:    int(parsed[3]),
+	}
+	status.IsBlocked = status.Count > status.EffectiveLimit()
+	status.Window = int(resetAt.Sub(status.LastReset).Seconds())
+
+	r.logStorageOperation(ctx, "INCREMENT_QUOTA", key, true, time.Since(start).Seconds()*1000, nil)
+	return status, nil
+}
+
 // IsBlocked verifica se uma chave está bloqueada
 func (r *RedisStorage) IsBlocked(ctx context.Context, key string) (bool, *time.Time, error) {
+	ctx, span := startSpan(ctx, RedisStorageType, "IS_BLOCKED", key)
+	defer span.End()
+
 	start := time.Now()
 
 	// Busca status
@@ -208,80 +745,173 @@ func (r *RedisStorage) IsBlocked(ctx context.Context, key string) (bool, *time.T
 		return false, nil, err
 	}
 
-	if status == nil {
-		r.logStorageOperation("IS_BLOCKED", key, true, time.Since(start).Seconds()*1000, nil)
+	// Bloqueio expirado não vale mais, mesmo que a chave ainda não tenha expirado
+	if status == nil || (status.BlockedUntil != nil && !time.Now().Before(*status.BlockedUntil)) {
+		r.logStorageOperation(ctx, "IS_BLOCKED", key, true, time.Since(start).Seconds()*1000, nil)
 		return false, nil, nil
 	}
 
-	r.logStorageOperation("IS_BLOCKED", key, true, time.Since(start).Seconds()*1000, nil)
+	r.logStorageOperation(ctx, "IS_BLOCKED", key, true, time.Since(start).Seconds()*1000, nil)
 	return status.IsBlocked, status.BlockedUntil, nil
 }
 
-// Block bloqueia uma chave por um período específico
+// blockSource bloqueia atomicamente uma chave, no mesmo formato JSON do
+// incrementSource. ARGV[1] é o instante atual e ARGV[2] a duração (ms). O contador
+// é preservado, e um bloqueio vigente mais longo não é encurtado. O registro vive
+// por mais um minuto além do bloqueio, ou até o fim da janela se ela for maior.
+// Retorna o fim do bloqueio
+const blockSource = `
+	local key = KEYS[1]
+	local now = tonumber(ARGV[1])
+	local duration = tonumber(ARGV[2])
+
+	local current = redis.call('GET', key)
+	local data
+	if current then
+		data = cjson.decode(current)
+	else
+		data = {
+			key = key,
+			type = '',
+			count = 0,
+			limit = 0,
+			window = 0,
+			lastReset = now,
+			isBlocked = false
+		}
+	end
+
+	local blockedUntil = now + duration
+	local previous = tonumber(data.blockedUntil)
+	if data.isBlocked and previous and previous > blockedUntil then
+		blockedUntil = previous
+	end
+	data.isBlocked = true
+	data.blockedUntil = blockedUntil
+
+	local ttl = blockedUntil - now + 60000
+	local remaining = redis.call('PTTL', key)
+	if remaining > ttl then
+		ttl = remaining
+	end
+	redis.call('SET', key, cjson.encode(data), 'PX', ttl)
+	return blockedUntil
+`
+
+var blockScript = redis.NewScript(blockSource)
+
+// Block bloqueia uma chave por um período específico em um único script, sem
+// perder incrementos ou bloqueios de outras instâncias feitos entre leitura e escrita
 func (r *RedisStorage) Block(ctx context.Context, key string, duration time.Duration) error {
+	ctx, span := startSpan(ctx, RedisStorageType, "BLOCK", key)
+	defer span.End()
+
 	start := time.Now()
 
-	// Busca status atual
-	status, err := r.Get(ctx, key)
+	var result interface{}
+	var err error
+	if r.cipher != nil {
+		result, err = r.blockSealed(ctx, key, duration)
+	} else {
+		result, err = r.eval(ctx, "block", blockScript, []string{key}, time.Now().UnixMilli(), duration.Milliseconds())
+	}
 	if err != nil {
-		return err
+		r.logStorageOperation(ctx, "BLOCK", key, false, time.Since(start).Seconds()*1000, err)
+		return fmt.Errorf("failed to block key %s: %w", key, err)
 	}
 
-	if status == nil {
-		// Cria novo status bloqueado
-		blockedUntil := time.Now().Add(duration)
-		status = &domain.RateLimitStatus{
-			Key:          key,
-			IsBlocked:    true,
-			BlockedUntil: &blockedUntil,
-			LastReset:    time.Now(),
-		}
-	} else {
-		// Atualiza status existente
-		blockedUntil := time.Now().Add(duration)
-		status.IsBlocked = true
-		status.BlockedUntil = &blockedUntil
+	blockedUntilMs, err := strconv.ParseInt(fmt.Sprint(result), 10, 64)
+	if err != nil {
+		r.logStorageOperation(ctx, "BLOCK", key, false, time.Since(start).Seconds()*1000, err)
+		return fmt.Errorf("invalid block result for key %s: %w", key, err)
 	}
 
-	// Salva status atualizado
-	if err := r.Set(ctx, key, status, duration+time.Minute); err != nil {
-		r.logStorageOperation("BLOCK", key, false, time.Since(start).Seconds()*1000, err)
-		return err
-	}
+	r.indexBlock(ctx, key, time.UnixMilli(blockedUntilMs))
 
-	r.logStorageOperation("BLOCK", key, true, time.Since(start).Seconds()*1000, nil)
+	r.logStorageOperation(ctx, "BLOCK", key, true, time.Since(start).Seconds()*1000, nil)
 	return nil
 }
 
 // Reset limpa os dados de uma chave
 func (r *RedisStorage) Reset(ctx context.Context, key string) error {
+	ctx, span := startSpan(ctx, RedisStorageType, "RESET", key)
+	defer span.End()
+
 	start := time.Now()
 
-	if err := r.client.Del(ctx, key).Err(); err != nil {
-		r.logStorageOperation("RESET", key, false, time.Since(start).Seconds()*1000, err)
+	// Remove também o log do sliding window log e o histórico de janelas, se houver
+	err := r.withRetry(ctx, func(ctx context.Context) error {
+		return r.getClient().Del(ctx, key, key+slidingLogSuffix, key+windowHistorySuffix).Err()
+	})
+	if err != nil {
+		r.logStorageOperation(ctx, "RESET", key, false, time.Since(start).Seconds()*1000, err)
 		return fmt.Errorf("failed to reset key %s: %w", key, err)
 	}
+	r.unindexBlock(ctx, key)
 
-	r.logStorageOperation("RESET", key, true, time.Since(start).Seconds()*1000, nil)
+	r.logStorageOperation(ctx, "RESET", key, true, time.Since(start).Seconds()*1000, nil)
 	return nil
 }
 
+// Inspect retorna o valor salvo e o TTL da chave sem interpretá-los
+// Falhas de decodificação são reportadas no registro, não como erro
+func (r *RedisStorage) Inspect(ctx context.Context, key string) (*domain.StorageRecord, error) {
+	ctx, span := startSpan(ctx, RedisStorageType, "INSPECT", key)
+	defer span.End()
+
+	start := time.Now()
+
+	pipe := r.getClient().Pipeline()
+	getCmd := pipe.Get(ctx, key)
+	ttlCmd := pipe.PTTL(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		r.logStorageOperation(ctx, "INSPECT", key, false, time.Since(start).Seconds()*1000, err)
+		return nil, fmt.Errorf("failed to inspect key %s: %w", key, err)
+	}
+
+	record := &domain.StorageRecord{Backend: string(RedisStorageType), StorageKey: key}
+	raw, err := getCmd.Result()
+	if err == redis.Nil {
+		r.logStorageOperation(ctx, "INSPECT", key, true, time.Since(start).Seconds()*1000, nil)
+		return record, nil
+	}
+
+	record.Exists = true
+	record.Raw = raw
+	// PTTL retorna -1 para chaves sem expiração
+	if ttl := ttlCmd.Val(); ttl > 0 {
+		record.TTL = &ttl
+	}
+
+	if status, err := r.decodeStatus(key, []byte(raw)); err != nil {
+		record.DecodeError = err.Error()
+	} else {
+		record.Status = status
+	}
+
+	r.logStorageOperation(ctx, "INSPECT", key, true, time.Since(start).Seconds()*1000, nil)
+	return record, nil
+}
+
 // Health verifica se o storage está saudável
 func (r *RedisStorage) Health(ctx context.Context) error {
+	ctx, span := startSpan(ctx, RedisStorageType, "HEALTH", "")
+	defer span.End()
+
 	start := time.Now()
 
-	if err := r.client.Ping(ctx).Err(); err != nil {
-		r.logStorageOperation("HEALTH", "ping", false, time.Since(start).Seconds()*1000, err)
+	if err := r.getClient().Ping(ctx).Err(); err != nil {
+		r.logStorageOperation(ctx, "HEALTH", "ping", false, time.Since(start).Seconds()*1000, err)
 		return fmt.Errorf("Redis health check failed: %w", err)
 	}
 
-	r.logStorageOperation("HEALTH", "ping", true, time.Since(start).Seconds()*1000, nil)
+	r.logStorageOperation(ctx, "HEALTH", "ping", true, time.Since(start).Seconds()*1000, nil)
 	return nil
 }
 
 // Close fecha a conexão com o storage
 func (r *RedisStorage) Close() error {
-	if client, ok := r.client.(*redis.Client); ok {
+	if client, ok := r.getClient().(*redis.Client); ok {
 		if err := client.Close(); err != nil {
 			r.logger.Error("Failed to close Redis connection", err, nil)
 			return err
@@ -292,9 +922,17 @@ func (r *RedisStorage) Close() error {
 }
 
 // logStorageOperation registra operações de storage
-func (r *RedisStorage) logStorageOperation(operation, key string, success bool, latency float64, err error) {
+func (r *RedisStorage) logStorageOperation(ctx context.Context, operation, key string, success bool, latency float64, err error) {
+	if !success {
+		recordSpanError(ctx, err)
+	}
+
 	if r.logger != nil {
 		if success {
+			// Sucesso é registrado só em debug: evita montar os campos a cada operação
+			if !domain.DebugEnabled(r.logger) {
+				return
+			}
 			r.logger.Debug("Storage operation completed", map[string]interface{}{
 				"operation": operation,
 				"key":       key,
@@ -310,14 +948,101 @@ func (r *RedisStorage) logStorageOperation(operation, key string, success bool,
 	}
 }
 
+// KeyOption ajusta as chaves construídas por BuildKey
+type KeyOption func(*keyOptions)
+
+type keyOptions struct {
+	hashTag bool
+	cipher  *Cipher
+}
+
+// WithHashTag envolve o identificador em {hash tag}, para que todas as chaves da
+// mesma identidade (contador, bloqueio, cota) caiam no mesmo slot do Redis Cluster
+// e possam ser alteradas em um único script
+func WithHashTag() KeyOption {
+	return func(o *keyOptions) {
+		o.hashTag = true
+	}
+}
+
+// WithEncryptedIdentifier cifra o identificador (IP ou token) com AES-GCM
+// determinístico: nem o nome da chave nem o status gravado o expõem em repouso
+func WithEncryptedIdentifier(c *Cipher) KeyOption {
+	return func(o *keyOptions) {
+		o.cipher = c
+	}
+}
+
 // BuildKey constrói chaves padronizadas para Redis
-func BuildKey(limiterType domain.LimiterType, identifier string) string {
+func BuildKey(limiterType domain.LimiterType, identifier string, opts ...KeyOption) string {
+	identifier = applyKeyOptions(identifier, opts)
+
+	// Concatenação em vez de fmt.Sprintf: a chave é montada a cada requisição
+	return KeyPrefix(limiterType) + identifier
+}
+
+// KeyPrefix retorna o prefixo das chaves montadas por BuildKey para o tipo
+func KeyPrefix(limiterType domain.LimiterType) string {
 	switch limiterType {
 	case domain.IPLimiter:
-		return fmt.Sprintf("rate_limit:ip:%s", identifier)
+		return "rate_limit:ip:"
 	case domain.TokenLimiter:
-		return fmt.Sprintf("rate_limit:token:%s", identifier)
+		return "rate_limit:token:"
 	default:
-		return fmt.Sprintf("rate_limit:unknown:%s", identifier)
+		return "rate_limit:unknown:"
+	}
+}
+
+// ParseKey extrai o identificador de uma chave montada por BuildKey com as mesmas opções
+func ParseKey(limiterType domain.LimiterType, key string, opts ...KeyOption) (string, error) {
+	identifier, found := strings.CutPrefix(key, KeyPrefix(limiterType))
+	if !found {
+		return "", fmt.Errorf("key %s is not a %s key", key, limiterType)
+	}
+
+	var options keyOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.hashTag {
+		identifier = strings.TrimSuffix(strings.TrimPrefix(identifier, "{"), "}")
+	}
+	if options.cipher != nil {
+		return options.cipher.OpenIdentifier(identifier)
+	}
+	return identifier, nil
+}
+
+// BuildFamilyKey constrói a chave do contador compartilhado por uma família de tokens
+func BuildFamilyKey(family string, opts ...KeyOption) string {
+	return "rate_limit:family:" + applyKeyOptions(family, opts)
+}
+
+// applyKeyOptions cifra e envolve o identificador conforme as opções
+func applyKeyOptions(identifier string, opts []KeyOption) string {
+	var options keyOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.cipher != nil {
+		identifier = options.cipher.SealIdentifier(identifier)
+	}
+	if options.hashTag {
+		identifier = "{" + identifier + "}"
+	}
+	return identifier
+}
+
+// HashTag retorna a parte da chave usada pelo Redis Cluster para escolher o slot:
+// o conteúdo entre o primeiro "{" e o "}" seguinte, se não vazio; senão, a chave inteira
+func HashTag(key string) string {
+	start := strings.IndexByte(key, '{')
+	if start < 0 {
+		return key
+	}
+	end := strings.IndexByte(key[start+1:], '}')
+	if end <= 0 {
+		return key
 	}
+	return key[start+1 : start+1+end]
 } 
\ No newline at end of file
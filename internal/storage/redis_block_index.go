@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// blockIndexPrefix é o prefixo dos sorted sets do índice de chaves bloqueadas
+const blockIndexPrefix = "rate_limit:blocked:"
+
+// BlockLister é implementado por storages que indexam as chaves bloqueadas em
+// partições, lidas uma a uma na reconstrução do filtro de bloqueios
+type BlockLister interface {
+	BlockPartitions() int
+	BlockedKeys(ctx context.Context, partition int) ([]string, error)
+}
+
+// SetBlockIndex mantém as chaves bloqueadas em partitions sorted sets
+// (rate_limit:blocked:<n>, score = fim do bloqueio em ms), para que conjuntos
+// grandes não fiquem em uma única chave. 0 = sem índice. Deve ser chamado antes
+// do primeiro uso
+func (r *RedisStorage) SetBlockIndex(partitions int) {
+	r.blockPartitions = partitions
+}
+
+// BlockPartitions implementa BlockLister
+func (r *RedisStorage) BlockPartitions() int {
+	return r.blockPartitions
+}
+
+// BlockedKeys implementa BlockLister: remove da partição os bloqueios vencidos e
+// retorna os vigentes
+func (r *RedisStorage) BlockedKeys(ctx context.Context, partition int) ([]string, error) {
+	ctx, span := startSpan(ctx, RedisStorageType, "BLOCKED_KEYS", "")
+	defer span.End()
+
+	if partition < 0 || partition >= r.blockPartitions {
+		return nil, fmt.Errorf("block index partition %d out of range [0, %d)", partition, r.blockPartitions)
+	}
+
+	indexKey := blockIndexPrefix + strconv.Itoa(partition)
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+
+	pipe := r.getClient().Pipeline()
+	pipe.ZRemRangeByScore(ctx, indexKey, "-inf", "("+now)
+	keysCmd := pipe.ZRangeByScore(ctx, indexKey, &redis.ZRangeBy{Min: now, Max: "+inf"})
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		err = fmt.Errorf("failed to list blocked keys in partition %d: %w", partition, err)
+		recordSpanError(ctx, err)
+		return nil, err
+	}
+	return keysCmd.Val(), nil
+}
+
+// blockPartition escolhe a partição do índice de uma chave
+func (r *RedisStorage) blockPartition(key string) string {
+	hash := fnv.New32a()
+	hash.Write([]byte(key))
+	return blockIndexPrefix + strconv.Itoa(int(hash.Sum32()%uint32(r.blockPartitions)))
+}
+
+// indexBlock registra o bloqueio no índice. Falhas só atrasam a visão das outras
+// instâncias até o bloqueio ser lido do próprio registro, e não falham o Block
+func (r *RedisStorage) indexBlock(ctx context.Context, key string, until time.Time) {
+	if r.blockPartitions <= 0 {
+		return
+	}
+	member := &redis.Z{Score: float64(until.UnixMilli()), Member: key}
+	if err := r.getClient().ZAdd(ctx, r.blockPartition(key), member).Err(); err != nil && r.logger != nil {
+		r.logger.Warn("Failed to index blocked key", map[string]interface{}{
+			"key":   key,
+			"error": err.Error(),
+		})
+	}
+}
+
+// unindexBlock remove a chave do índice após um reset
+func (r *RedisStorage) unindexBlock(ctx context.Context, key string) {
+	if r.blockPartitions <= 0 {
+		return
+	}
+	if err := r.getClient().ZRem(ctx, r.blockPartition(key), key).Err(); err != nil && r.logger != nil {
+		r.logger.Warn("Failed to remove key from block index", map[string]interface{}{
+			"key":   key,
+			"error": err.Error(),
+		})
+	}
+}
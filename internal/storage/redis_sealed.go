@@ -0,0 +1,341 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"rate-limiter/internal/domain"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// ErrSealedUnsupported indica uma operação que depende de scripts Lua lendo o
+// JSON em claro, indisponível com os valores cifrados
+var ErrSealedUnsupported = errors.New("operation not supported with encrypted storage values")
+
+// maxSealedAttempts limita as repetições de uma transação disputada por outras instâncias
+const maxSealedAttempts = 64
+
+// SetCipher cifra com AES-GCM os status gravados no Redis (nil = JSON em claro).
+// Como os scripts Lua não leem valores cifrados, as operações de contagem passam
+// a ler, alterar e gravar o registro em transações otimistas (WATCH/MULTI),
+// refeitas quando outra instância altera a chave no meio. Só a janela fixa, sem
+// histórico de janelas, é suportada. Deve ser chamado antes do primeiro uso
+func (r *RedisStorage) SetCipher(cipher *Cipher) {
+	r.cipher = cipher
+}
+
+// sealedRecord é um registro lido dentro da transação
+type sealedRecord struct {
+	redisRecord
+	exists bool
+	pttl   int64 // TTL atual em ms (<= 0 = inexistente ou sem expiração)
+	write  int64 // TTL em ms do valor a gravar (0 = não grava)
+}
+
+// watcher é o cliente Redis com suporte a transações otimistas
+type watcher interface {
+	Watch(ctx context.Context, fn func(*redis.Tx) error, keys ...string) error
+}
+
+// updateSealed lê e decifra os registros das chaves, aplica update e grava os
+// registros marcados em um MULTI/EXEC. Se alguma chave mudou depois da leitura,
+// a transação é refeita com os valores novos, então update não deve guardar estado.
+// now é o instante da tentativa em epoch ms
+func (r *RedisStorage) updateSealed(ctx context.Context, keys []string, update func(records []*sealedRecord, now int64)) error {
+	client, ok := r.getClient().(watcher)
+	if !ok {
+		return fmt.Errorf("Redis client does not support transactions")
+	}
+
+	return r.withRetry(ctx, func(ctx context.Context) error {
+		for attempt := 0; attempt < maxSealedAttempts; attempt++ {
+			err := client.Watch(ctx, func(tx *redis.Tx) error {
+				records, err := r.readSealed(ctx, tx, keys)
+				if err != nil {
+					return err
+				}
+				update(records, time.Now().UnixMilli())
+
+				_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+					for i, record := range records {
+						if record.write <= 0 {
+							continue
+						}
+						data, err := r.encodeRecord(record.redisRecord)
+						if err != nil {
+							return err
+						}
+						pipe.Set(ctx, keys[i], data, time.Duration(record.write)*time.Millisecond)
+					}
+					return nil
+				})
+				return err
+			}, keys...)
+			if err != redis.TxFailedErr {
+				return err
+			}
+
+			// Espera aleatória crescente para não repetir a disputa no mesmo instante
+			backoff := time.Duration(rand.Int63n(int64(attempt+1) * int64(time.Millisecond)))
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+		}
+		return fmt.Errorf("too many concurrent updates to key %s", keys[0])
+	})
+}
+
+// readSealed lê os registros e seus TTLs em um único round trip
+func (r *RedisStorage) readSealed(ctx context.Context, tx *redis.Tx, keys []string) ([]*sealedRecord, error) {
+	gets := make([]*redis.StringCmd, len(keys))
+	ttls := make([]*redis.DurationCmd, len(keys))
+	_, err := tx.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			gets[i] = pipe.Get(ctx, key)
+			ttls[i] = pipe.PTTL(ctx, key)
+		}
+		return nil
+	})
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	records := make([]*sealedRecord, len(keys))
+	for i, key := range keys {
+		records[i] = &sealedRecord{pttl: ttls[i].Val().Milliseconds()}
+		raw, err := gets[i].Bytes()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if records[i].redisRecord, err = r.decodeRecord(raw); err != nil {
+			return nil, fmt.Errorf("failed to decode key %s: %w", key, err)
+		}
+		records[i].exists = true
+	}
+	return records, nil
+}
+
+// startWindow cria o registro da chave inexistente, como os scripts Lua
+func (record *sealedRecord) startWindow(limit int, window, now int64) {
+	if !record.exists {
+		record.redisRecord = redisRecord{Limit: limit, Window: int(window / 1000), LastReset: now}
+	}
+}
+
+// incrementSealed é o incrementSource na janela fixa, somando amount
+func (r *RedisStorage) incrementSealed(ctx context.Context, key string, limit int, window time.Duration, amount int) (redisRecord, error) {
+	if !fixedWindow(r.algorithm) || r.historySize > 0 {
+		return redisRecord{}, ErrSealedUnsupported
+	}
+
+	windowMs := window.Milliseconds()
+	var result redisRecord
+	err := r.updateSealed(ctx, []string{key}, func(records []*sealedRecord, now int64) {
+		record := records[0]
+		record.startWindow(limit, windowMs, now)
+
+		elapsed := now - record.LastReset
+		if elapsed >= windowMs {
+			record.LastReset = now
+			record.Count = 0
+			record.IsBlocked = false
+			elapsed = 0
+		}
+		record.Count += amount
+		if record.Count > limit {
+			record.IsBlocked = true
+		}
+		record.write = windowMs - elapsed
+		result = record.redisRecord
+	})
+	return result, err
+}
+
+// incrementQuotaSealed é o quotaSource: novo período com crédito do anterior
+func (r *RedisStorage) incrementQuotaSealed(ctx context.Context, key string, period domain.QuotaPeriod) (redisRecord, error) {
+	resetAt := period.ResetAt.UnixMilli()
+	var result redisRecord
+	err := r.updateSealed(ctx, []string{key}, func(records []*sealedRecord, now int64) {
+		record := records[0]
+		if record.ResetAt == nil || now >= *record.ResetAt {
+			credit := 0
+			if record.ResetAt != nil && period.RolloverPercent > 0 {
+				if unused := record.Limit + record.Credit - record.Count; unused > 0 {
+					credit = unused * period.RolloverPercent / 100
+					if credit > record.Limit {
+						credit = record.Limit
+					}
+				}
+			}
+			record.redisRecord = redisRecord{
+				Window:    int((resetAt - now) / 1000),
+				LastReset: now,
+				ResetAt:   &resetAt,
+				Credit:    credit,
+			}
+		}
+
+		record.Count++
+		record.Limit = period.Limit
+		if record.Count > period.Limit+record.Credit {
+			record.IsBlocked = true
+		}
+
+		// Expira a chave um ciclo após o período (mantém base para rollover)
+		record.write = 2**record.ResetAt - record.LastReset - now
+		if record.write <= 0 {
+			record.write = 1000
+		}
+		result = record.redisRecord
+	})
+	return result, err
+}
+
+// checkLimitSealed é o checkLimitSource: nega sem contar se bloqueada, senão
+// incrementa e bloqueia ao passar do limite. Retorna se já estava bloqueada
+func (r *RedisStorage) checkLimitSealed(ctx context.Context, key string, limit int, window, blockDuration time.Duration) (redisRecord, bool, error) {
+	if r.historySize > 0 {
+		return redisRecord{}, false, ErrSealedUnsupported
+	}
+
+	windowMs := window.Milliseconds()
+	var result redisRecord
+	var blocked bool
+	err := r.updateSealed(ctx, []string{key}, func(records []*sealedRecord, now int64) {
+		record := records[0]
+		record.startWindow(limit, windowMs, now)
+
+		blocked = record.IsBlocked && (record.BlockedUntil == nil || *record.BlockedUntil > now)
+		result = record.redisRecord
+		if blocked {
+			return
+		}
+
+		elapsed := now - record.LastReset
+		if elapsed >= windowMs {
+			record.LastReset = now
+			record.Count = 0
+			record.IsBlocked = false
+			elapsed = 0
+		}
+		record.Count++
+
+		record.write = windowMs - elapsed
+		if record.Count > limit {
+			// Bloqueia como o Block: o registro sobrevive ao bloqueio por mais um minuto
+			blockedUntil := now + blockDuration.Milliseconds()
+			record.IsBlocked = true
+			record.BlockedUntil = &blockedUntil
+			if ttl := blockDuration.Milliseconds() + 60000; ttl > record.write {
+				record.write = ttl
+			}
+		}
+		result = record.redisRecord
+	})
+	return result, blocked, err
+}
+
+// blockSealed é o blockSource: preserva o contador e não encurta um bloqueio mais longo
+func (r *RedisStorage) blockSealed(ctx context.Context, key string, duration time.Duration) (int64, error) {
+	var blockedUntil int64
+	err := r.updateSealed(ctx, []string{key}, func(records []*sealedRecord, now int64) {
+		record := records[0]
+		record.startWindow(0, 0, now)
+
+		blockedUntil = now + duration.Milliseconds()
+		if record.IsBlocked && record.BlockedUntil != nil && *record.BlockedUntil > blockedUntil {
+			blockedUntil = *record.BlockedUntil
+		}
+		until := blockedUntil
+		record.IsBlocked = true
+		record.BlockedUntil = &until
+
+		record.write = blockedUntil - now + 60000
+		if record.pttl > record.write {
+			record.write = record.pttl
+		}
+	})
+	return blockedUntil, err
+}
+
+// incrementHierarchySealed é o hierarchySource: todos os níveis na mesma transação
+func (r *RedisStorage) incrementHierarchySealed(ctx context.Context, counters []domain.HierarchyCounter, window time.Duration) (*domain.HierarchyResult, error) {
+	keys := make([]string, len(counters))
+	for i, counter := range counters {
+		keys[i] = counter.Key
+	}
+
+	windowMs := window.Milliseconds()
+	result := &domain.HierarchyResult{
+		Counts:  make([]int, len(counters)),
+		ResetAt: make([]time.Time, len(counters)),
+	}
+	err := r.updateSealed(ctx, keys, func(records []*sealedRecord, now int64) {
+		result.Exceeded = -1
+		for i, record := range records {
+			record.startWindow(0, windowMs, now)
+			if now-record.LastReset >= windowMs {
+				record.Count = 0
+				record.LastReset = now
+				record.IsBlocked = false
+			}
+			record.Limit = counters[i].Limit
+
+			if result.Exceeded < 0 && record.Count >= counters[i].Limit {
+				result.Exceeded = i
+			}
+		}
+
+		for i, record := range records {
+			if result.Exceeded < 0 {
+				record.Count++
+
+				// Preserva o TTL de um bloqueio mais longo que a janela
+				record.write = windowMs - (now - record.LastReset)
+				if record.pttl > record.write {
+					record.write = record.pttl
+				}
+			}
+			result.Counts[i] = record.Count
+			result.ResetAt[i] = time.UnixMilli(record.LastReset).Add(window)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// mergeSealed aplica cada CounterDelta em sua própria transação
+func (r *RedisStorage) mergeSealed(ctx context.Context, deltas []CounterDelta, start time.Time) ([]CounterSnapshot, error) {
+	snapshots := make([]CounterSnapshot, len(deltas))
+	for i, delta := range deltas {
+		record, err := r.incrementSealed(ctx, delta.Key, delta.Limit, delta.Window, delta.Delta)
+		if err != nil {
+			r.logStorageOperation(ctx, "MERGE_COUNTERS", delta.Key, false, time.Since(start).Seconds()*1000, err)
+			return nil, fmt.Errorf("failed to merge %d counters: %w", len(deltas), err)
+		}
+
+		snapshots[i] = CounterSnapshot{
+			Key:       delta.Key,
+			Count:     record.Count,
+			LastReset: time.UnixMilli(record.LastReset),
+		}
+		if record.BlockedUntil != nil && *record.BlockedUntil > 0 {
+			blockedUntil := time.UnixMilli(*record.BlockedUntil)
+			snapshots[i].BlockedUntil = &blockedUntil
+		}
+	}
+
+	r.logStorageOperation(ctx, "MERGE_COUNTERS", "", true, time.Since(start).Seconds()*1000, nil)
+	return snapshots, nil
+}
@@ -0,0 +1,199 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"rate-limiter/internal/domain"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSealedStorage cria um RedisStorage sobre miniredis com os valores cifrados
+func newSealedStorage(t *testing.T) (*RedisStorage, *miniredis.Miniredis) {
+	t.Helper()
+
+	storage, server := newMiniredisStorage(t)
+	cipher, err := NewCipher([]byte("0123456789abcdef0123456789abcdef"))
+	require.NoError(t, err)
+	storage.SetCipher(cipher)
+	return storage, server
+}
+
+// TestRedisStorage_Sealed_NoPlaintextAtRest testa que nenhum valor gravado, pelo
+// Set ou pelas operações que usariam os scripts Lua, é JSON legível
+func TestRedisStorage_Sealed_NoPlaintextAtRest(t *testing.T) {
+	// Arrange
+	storage, server := newSealedStorage(t)
+	ctx := context.Background()
+	keys := map[string]func(key string) error{
+		"rate_limit:ip:10.0.0.1": func(key string) error {
+			return storage.Set(ctx, key, &domain.RateLimitStatus{Key: key, Count: 3, Limit: 10, Window: 60, LastReset: time.Now()}, time.Minute)
+		},
+		"rate_limit:ip:10.0.0.2": func(key string) error {
+			_, _, err := storage.Increment(ctx, key, 10, time.Minute)
+			return err
+		},
+		"rate_limit:ip:10.0.0.3": func(key string) error {
+			return storage.Block(ctx, key, time.Minute)
+		},
+		"rate_limit:quota:10.0.0.4": func(key string) error {
+			_, err := storage.IncrementQuota(ctx, key, domain.QuotaPeriod{Limit: 5, ResetAt: time.Now().Add(time.Hour)})
+			return err
+		},
+		"rate_limit:ip:10.0.0.5": func(key string) error {
+			_, err := storage.CheckAndIncrement(ctx, key, 10, time.Minute, time.Minute)
+			return err
+		},
+		"rate_limit:token:10.0.0.6": func(key string) error {
+			_, err := storage.IncrementHierarchy(ctx, []domain.HierarchyCounter{{Key: key, Limit: 10}}, time.Minute)
+			return err
+		},
+	}
+
+	for key, write := range keys {
+		// Act
+		require.NoError(t, write(key), key)
+
+		// Assert
+		raw, err := server.Get(key)
+		require.NoError(t, err)
+		assert.False(t, json.Valid([]byte(raw)), key)
+		assert.NotContains(t, raw, "10.0.0.", key)
+		assert.NotContains(t, raw, "count", key)
+
+		status, err := storage.Get(ctx, key)
+		require.NoError(t, err)
+		require.NotNil(t, status)
+		assert.Equal(t, key, status.Key)
+
+		record, err := storage.Inspect(ctx, key)
+		require.NoError(t, err)
+		assert.Empty(t, record.DecodeError)
+		require.NotNil(t, record.Status)
+	}
+
+	statuses, _, err := storage.ListKeys(ctx, "rate_limit:ip:", "", 10)
+	require.NoError(t, err)
+	assert.Len(t, statuses, 4)
+}
+
+// TestRedisStorage_Sealed_OtherKeyRejected testa que um valor cifrado com outra
+// chave, ou em claro, não é aceito como status
+func TestRedisStorage_Sealed_OtherKeyRejected(t *testing.T) {
+	// Arrange
+	storage, server := newSealedStorage(t)
+	ctx := context.Background()
+	require.NoError(t, server.Set("rate_limit:ip:10.0.0.1", `{"count":1,"limit":10,"window":60,"lastReset":0}`))
+
+	// Act
+	_, err := storage.Get(ctx, "rate_limit:ip:10.0.0.1")
+	_, _, incrementErr := storage.Increment(ctx, "rate_limit:ip:10.0.0.1", 10, time.Minute)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrInvalidCiphertext)
+	assert.ErrorIs(t, incrementErr, ErrInvalidCiphertext)
+}
+
+// TestRedisStorage_Sealed_Increment testa contagem, bloqueio e expiração da janela
+// com a transação otimista no lugar do script de incremento
+func TestRedisStorage_Sealed_Increment(t *testing.T) {
+	// Arrange
+	storage, server := newSealedStorage(t)
+	ctx := context.Background()
+	key := "rate_limit:ip:10.0.0.1"
+
+	// Act
+	for i := 1; i <= 3; i++ {
+		count, _, err := storage.Increment(ctx, key, 2, time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, i, count)
+	}
+
+	// Assert
+	blocked, _, err := storage.IsBlocked(ctx, key)
+	require.NoError(t, err)
+	assert.True(t, blocked)
+	assert.Equal(t, time.Minute, server.TTL(key).Round(time.Second))
+
+	server.FastForward(time.Minute)
+	count, _, err := storage.Increment(ctx, key, 2, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+// TestRedisStorage_Sealed_ConcurrentIncrements testa que transações disputadas
+// são refeitas sem perder incrementos
+func TestRedisStorage_Sealed_ConcurrentIncrements(t *testing.T) {
+	// Arrange
+	storage, _ := newSealedStorage(t)
+	ctx := context.Background()
+	key := "rate_limit:ip:10.0.0.1"
+
+	// Act
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				_, _, err := storage.Increment(ctx, key, 1000, time.Minute)
+				assert.NoError(t, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Assert
+	status, err := storage.Get(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, 50, status.Count)
+}
+
+// TestRedisStorage_Sealed_MergeCounters testa os lotes do HybridStorage com valores cifrados
+func TestRedisStorage_Sealed_MergeCounters(t *testing.T) {
+	// Arrange
+	storage, _ := newSealedStorage(t)
+	ctx := context.Background()
+	require.NoError(t, storage.Block(ctx, "rate_limit:ip:10.0.0.2", time.Minute))
+
+	// Act
+	snapshots, err := storage.MergeCounters(ctx, []CounterDelta{
+		{Key: "rate_limit:ip:10.0.0.1", Delta: 3, Limit: 10, Window: time.Minute},
+		{Key: "rate_limit:ip:10.0.0.2", Delta: 2, Limit: 10, Window: time.Minute},
+	})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+	assert.Equal(t, 3, snapshots[0].Count)
+	assert.Nil(t, snapshots[0].BlockedUntil)
+	assert.Equal(t, 2, snapshots[1].Count)
+	require.NotNil(t, snapshots[1].BlockedUntil)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), *snapshots[1].BlockedUntil, time.Second)
+}
+
+// TestRedisStorage_Sealed_Unsupported testa que as operações que dependem dos
+// scripts Lua falham em vez de gravar em claro
+func TestRedisStorage_Sealed_Unsupported(t *testing.T) {
+	// Arrange
+	storage, server := newSealedStorage(t)
+	ctx := context.Background()
+
+	// Act
+	_, tokenErr := storage.TakeToken(ctx, "rate_limit:ip:bucket", domain.TokenBucket{Capacity: 2, RefillRate: 1})
+	_, leakErr := storage.Leak(ctx, "rate_limit:ip:leaky", domain.LeakyBucket{Capacity: 2, LeakRate: 1})
+	storage.algorithm = AlgorithmSlidingLog
+	_, _, logErr := storage.Increment(ctx, "rate_limit:ip:log", 10, time.Minute)
+
+	// Assert
+	assert.ErrorIs(t, tokenErr, ErrSealedUnsupported)
+	assert.ErrorIs(t, leakErr, ErrSealedUnsupported)
+	assert.ErrorIs(t, logErr, ErrSealedUnsupported)
+	assert.Empty(t, server.Keys())
+}
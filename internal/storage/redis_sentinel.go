@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"rate-limiter/internal/domain"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Modos de conexão com o Redis (RedisConfig.Mode)
+const (
+	RedisModeStandalone = "standalone" // conexão direta com Host:Port (padrão)
+	RedisModeSentinel   = "sentinel"   // master descoberto pelos sentinels
+)
+
+// NewRedisSentinelStorage cria o storage sobre o master masterName descoberto
+// pelos sentinels. Após um failover o cliente passa a usar o novo master, sem
+// recriar o storage
+func NewRedisSentinelStorage(masterName string, sentinelAddrs []string, sentinelPassword, password string, db int, logger domain.Logger) (*RedisStorage, error) {
+	return NewRedisSentinelStorageWithOptions(&redis.FailoverOptions{
+		MasterName:       masterName,
+		SentinelAddrs:    sentinelAddrs,
+		SentinelPassword: sentinelPassword,
+		Password:         password,
+		DB:               db,
+	}, logger)
+}
+
+// NewRedisSentinelStorageWithOptions cria o storage no modo sentinel com as opções
+// informadas (usuário ACL, TLS), aplicando as configurações de performance do storage
+func NewRedisSentinelStorageWithOptions(failover *redis.FailoverOptions, logger domain.Logger) (*RedisStorage, error) {
+	// Mesmas configurações de performance da conexão direta
+	failover.PoolSize = 20
+	failover.MinIdleConns = 5
+	failover.MaxRetries = -1
+	failover.DialTimeout = 5 * time.Second
+	failover.ReadTimeout = 3 * time.Second
+	failover.WriteTimeout = 3 * time.Second
+	failover.PoolTimeout = 4 * time.Second
+	failover.IdleTimeout = 5 * time.Minute
+	rdb := redis.NewFailoverClient(failover)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		rdb.Close()
+		return nil, fmt.Errorf("failed to connect to Redis master %s through sentinels: %w", failover.MasterName, err)
+	}
+
+	if logger != nil {
+		logger.Info("Redis connection established through sentinels", map[string]interface{}{
+			"master":    failover.MasterName,
+			"sentinels": len(failover.SentinelAddrs),
+			"db":        failover.DB,
+			"tls":       failover.TLSConfig != nil,
+		})
+	}
+
+	loadScripts(ctx, rdb, logger)
+
+	return &RedisStorage{
+		client:   rdb,
+		logger:   logger,
+		failover: failover,
+		retry:    NewRetryPolicy(RetryConfig{}),
+	}, nil
+}
+
+// NewRedisClient cria um cliente para os demais usos do Redis (registro de
+// instâncias, invalidações, eventos), no mesmo modo de conexão do storage
+func NewRedisClient(config *RedisConfig) (*redis.Client, error) {
+	if config.Mode == RedisModeSentinel {
+		failover, err := redisFailoverOptions(config)
+		if err != nil {
+			return nil, err
+		}
+		return redis.NewFailoverClient(failover), nil
+	}
+
+	options, err := redisOptions(config)
+	if err != nil {
+		return nil, err
+	}
+	return redis.NewClient(options), nil
+}
+
+// redisOptions monta as opções da conexão direta (usuário ACL e TLS inclusos)
+func redisOptions(config *RedisConfig) (*redis.Options, error) {
+	tlsConfig, err := config.TLS.Load()
+	if err != nil {
+		return nil, err
+	}
+	return &redis.Options{
+		Addr:      net.JoinHostPort(config.Host, config.Port),
+		Username:  config.Username,
+		Password:  config.Password,
+		DB:        config.Database,
+		TLSConfig: tlsConfig,
+	}, nil
+}
+
+// redisFailoverOptions monta as opções do modo sentinel. O TLS vale para os
+// sentinels e para o master
+func redisFailoverOptions(config *RedisConfig) (*redis.FailoverOptions, error) {
+	tlsConfig, err := config.TLS.Load()
+	if err != nil {
+		return nil, err
+	}
+	return &redis.FailoverOptions{
+		MasterName:       config.MasterName,
+		SentinelAddrs:    config.SentinelAddrs,
+		SentinelUsername: config.SentinelUsername,
+		SentinelPassword: config.SentinelPassword,
+		Username:         config.Username,
+		Password:         config.Password,
+		DB:               config.Database,
+		TLSConfig:        tlsConfig,
+	}, nil
+}
+
+// newClient cria um cliente com as opções originais do storage (Reconnect)
+func (r *RedisStorage) newClient() *redis.Client {
+	if r.failover != nil {
+		return redis.NewFailoverClient(r.failover)
+	}
+	return redis.NewClient(r.options)
+}
+
+// connectionFields identifica a conexão nos logs
+func (r *RedisStorage) connectionFields() map[string]interface{} {
+	if r.failover != nil {
+		return map[string]interface{}{"master": r.failover.MasterName}
+	}
+	return map[string]interface{}{"addr": r.options.Addr}
+}
@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"rate-limiter/internal/logger"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSentinel simula um sentinel com os comandos usados pelo cliente de
+// failover: consulta do master, descoberta de sentinels e o canal +switch-master
+type fakeSentinel struct {
+	listener net.Listener
+
+	mutex       sync.Mutex
+	master      string
+	subscribers []net.Conn
+}
+
+func newFakeSentinel(t *testing.T, master string) *fakeSentinel {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	sentinel := &fakeSentinel{listener: listener, master: master}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go sentinel.serve(conn)
+		}
+	}()
+	return sentinel
+}
+
+func (s *fakeSentinel) addr() string {
+	return s.listener.Addr().String()
+}
+
+// failover troca o master e o anuncia aos inscritos, como o sentinel após eleger uma réplica
+func (s *fakeSentinel) failover(master string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	oldHost, oldPort, _ := net.SplitHostPort(s.master)
+	newHost, newPort, _ := net.SplitHostPort(master)
+	s.master = master
+	payload := strings.Join([]string{"mymaster", oldHost, oldPort, newHost, newPort}, " ")
+	for _, conn := range s.subscribers {
+		writeRESPArray(conn, "message", "+switch-master", payload)
+	}
+}
+
+func (s *fakeSentinel) subscriberCount() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.subscribers)
+}
+
+func (s *fakeSentinel) serve(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	for {
+		args, err := readRESPCommand(reader)
+		if err != nil {
+			return
+		}
+
+		switch strings.ToLower(strings.Join(args[:min(2, len(args))], " ")) {
+		case "sentinel get-master-addr-by-name":
+			s.mutex.Lock()
+			host, port, _ := net.SplitHostPort(s.master)
+			s.mutex.Unlock()
+			writeRESPArray(conn, host, port)
+		case "sentinel sentinels", "sentinel slaves", "sentinel replicas":
+			io.WriteString(conn, "*0\r\n")
+		default:
+			if strings.ToLower(args[0]) != "subscribe" {
+				io.WriteString(conn, "-ERR unknown command\r\n")
+				continue
+			}
+			s.mutex.Lock()
+			for i, channel := range args[1:] {
+				fmt.Fprintf(conn, "*3\r\n$9\r\nsubscribe\r\n$%d\r\n%s\r\n:%d\r\n", len(channel), channel, i+1)
+			}
+			s.subscribers = append(s.subscribers, conn)
+			s.mutex.Unlock()
+		}
+	}
+}
+
+// readRESPCommand lê um comando no formato de array do protocolo do Redis
+func readRESPCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil || count < 1 {
+		return nil, fmt.Errorf("malformed command %q", line)
+	}
+
+	args := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		if _, err := reader.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		arg, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, strings.TrimSuffix(arg, "\r\n"))
+	}
+	return args, nil
+}
+
+func writeRESPArray(w io.Writer, items ...string) {
+	fmt.Fprintf(w, "*%d\r\n", len(items))
+	for _, item := range items {
+		fmt.Fprintf(w, "$%d\r\n%s\r\n", len(item), item)
+	}
+}
+
+// TestRedisSentinelStorage_Failover testa a troca de master anunciada pelos
+// sentinels sem recriar o storage
+func TestRedisSentinelStorage_Failover(t *testing.T) {
+	// Arrange
+	primary := miniredis.RunT(t)
+	replica := miniredis.RunT(t)
+	sentinel := newFakeSentinel(t, primary.Addr())
+
+	storage, err := NewRedisSentinelStorage("mymaster", []string{sentinel.addr()}, "", "", 0, logger.NewLogger("error", "text"))
+	require.NoError(t, err)
+	t.Cleanup(func() { storage.Close() })
+	ctx := context.Background()
+	key := "rate_limit:ip:10.0.0.1"
+
+	count, _, err := storage.Increment(ctx, key, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.True(t, primary.Exists(key))
+	require.Eventually(t, func() bool { return sentinel.subscriberCount() > 0 }, 2*time.Second, 10*time.Millisecond)
+
+	// Act: o master cai e o sentinel promove a réplica
+	primary.Close()
+	sentinel.failover(replica.Addr())
+
+	// Assert
+	require.Eventually(t, func() bool {
+		_, _, err := storage.Increment(ctx, key, 10, time.Minute)
+		return err == nil
+	}, 2*time.Second, 20*time.Millisecond)
+	assert.True(t, replica.Exists(key))
+	require.NoError(t, storage.Reconnect(ctx))
+}
+
+func TestNewRedisSentinelStorage_Unreachable(t *testing.T) {
+	// Arrange
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	// Act
+	_, err = NewRedisSentinelStorage("mymaster", []string{addr}, "", "", 0, nil)
+
+	// Assert
+	assert.ErrorContains(t, err, "through sentinels")
+}
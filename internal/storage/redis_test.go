@@ -0,0 +1,334 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/logger"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newMiniredisStorage cria um RedisStorage sobre um miniredis em memória,
+// exercitando os comandos e scripts Lua reais sem um servidor Redis
+func newMiniredisStorage(t *testing.T) (*RedisStorage, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	return NewRedisStorageWithClient(client, logger.NewLogger("error", "text")), server
+}
+
+// TestRedisStorage_SetGet testa o formato gravado e lido pelo Go
+func TestRedisStorage_SetGet(t *testing.T) {
+	// Arrange
+	storage, server := newMiniredisStorage(t)
+	ctx := context.Background()
+	blockedUntil := time.Now().Add(time.Minute).Truncate(time.Millisecond)
+	status := &domain.RateLimitStatus{
+		Key:          "rate_limit:ip:10.0.0.1",
+		Type:         domain.IPLimiter,
+		Count:        3,
+		Limit:        10,
+		Window:       60,
+		LastReset:    time.Now().Truncate(time.Millisecond),
+		IsBlocked:    true,
+		BlockedUntil: &blockedUntil,
+	}
+
+	// Act
+	require.NoError(t, storage.Set(ctx, status.Key, status, time.Minute))
+	loaded, err := storage.Get(ctx, status.Key)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, loaded)
+	assert.Equal(t, 3, loaded.Count)
+	assert.True(t, status.LastReset.Equal(loaded.LastReset))
+	assert.True(t, blockedUntil.Equal(*loaded.BlockedUntil))
+	assert.Equal(t, time.Minute, server.TTL(status.Key))
+
+	missing, err := storage.Get(ctx, "rate_limit:ip:missing")
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+// TestRedisStorage_Increment testa o script Lua de incremento e a expiração da janela
+func TestRedisStorage_Increment(t *testing.T) {
+	// Arrange
+	storage, server := newMiniredisStorage(t)
+	ctx := context.Background()
+	key := "rate_limit:ip:10.0.0.1"
+
+	// Act
+	var lastReset time.Time
+	for i := 1; i <= 3; i++ {
+		count, reset, err := storage.Increment(ctx, key, 2, time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, i, count)
+		if i == 1 {
+			lastReset = reset
+		}
+		assert.True(t, lastReset.Equal(reset))
+	}
+
+	// Assert: o status gravado pelo Lua é legível pelo Go
+	status, err := storage.Get(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, 3, status.Count)
+	assert.True(t, status.IsBlocked)
+	assert.True(t, lastReset.Equal(status.LastReset))
+
+	blocked, _, err := storage.IsBlocked(ctx, key)
+	require.NoError(t, err)
+	assert.True(t, blocked)
+
+	// A chave expira com a janela
+	server.FastForward(time.Minute)
+	count, _, err := storage.Increment(ctx, key, 2, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+// TestRedisStorage_BlockThenIncrement testa o Lua lendo um status gravado pelo Go
+func TestRedisStorage_BlockThenIncrement(t *testing.T) {
+	// Arrange
+	storage, server := newMiniredisStorage(t)
+	ctx := context.Background()
+	key := "rate_limit:token:abc123"
+
+	// Act
+	require.NoError(t, storage.Block(ctx, key, 3*time.Minute))
+	blocked, blockedUntil, err := storage.IsBlocked(ctx, key)
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, blocked)
+	require.NotNil(t, blockedUntil)
+	assert.WithinDuration(t, time.Now().Add(3*time.Minute), *blockedUntil, time.Second)
+	assert.Equal(t, 4*time.Minute, server.TTL(key))
+
+	count, _, err := storage.Increment(ctx, key, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	status, err := storage.Get(ctx, key)
+	require.NoError(t, err)
+	require.NotNil(t, status.BlockedUntil)
+	assert.True(t, blockedUntil.Equal(*status.BlockedUntil))
+}
+
+// TestRedisStorage_Block_Atomic testa que o bloqueio preserva o contador e não
+// encurta um bloqueio mais longo já aplicado por outra instância
+func TestRedisStorage_Block_Atomic(t *testing.T) {
+	// Arrange
+	storage, server := newMiniredisStorage(t)
+	ctx := context.Background()
+	key := "rate_limit:ip:10.0.0.2"
+	for i := 0; i < 3; i++ {
+		_, _, err := storage.Increment(ctx, key, 2, time.Hour)
+		require.NoError(t, err)
+	}
+
+	// Act
+	require.NoError(t, storage.Block(ctx, key, 10*time.Minute))
+	require.NoError(t, storage.Block(ctx, key, time.Minute))
+
+	// Assert
+	status, err := storage.Get(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, 3, status.Count)
+	assert.True(t, status.IsBlocked)
+	require.NotNil(t, status.BlockedUntil)
+	assert.WithinDuration(t, time.Now().Add(10*time.Minute), *status.BlockedUntil, time.Second)
+	assert.InDelta(t, time.Hour.Seconds(), server.TTL(key).Seconds(), 1, "the window outlives the block")
+}
+
+// TestRedisStorage_Block_ConcurrentIncrements testa que bloqueios simultâneos não
+// descartam incrementos feitos entre a leitura e a escrita do registro
+func TestRedisStorage_Block_ConcurrentIncrements(t *testing.T) {
+	// Arrange
+	storage, _ := newMiniredisStorage(t)
+	ctx := context.Background()
+	key := "rate_limit:ip:10.0.0.3"
+
+	// Act
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, _, err := storage.Increment(ctx, key, 100, time.Minute)
+			assert.NoError(t, err)
+		}()
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, storage.Block(ctx, key, time.Minute))
+		}()
+	}
+	wg.Wait()
+
+	// Assert
+	status, err := storage.Get(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, 20, status.Count)
+	assert.True(t, status.IsBlocked)
+}
+
+// TestRedisStorage_IncrementQuota testa o script Lua de cotas com reset absoluto
+func TestRedisStorage_IncrementQuota(t *testing.T) {
+	// Arrange
+	storage, _ := newMiniredisStorage(t)
+	ctx := context.Background()
+	key := "rate_limit:token:daily_token"
+	resetAt := time.Now().Add(time.Hour).Truncate(time.Millisecond)
+	period := domain.QuotaPeriod{Limit: 2, ResetAt: resetAt}
+
+	// Act
+	var status *domain.RateLimitStatus
+	var err error
+	for i := 0; i < 3; i++ {
+		status, err = storage.IncrementQuota(ctx, key, period)
+		require.NoError(t, err)
+	}
+
+	// Assert
+	assert.Equal(t, 3, status.Count)
+	assert.True(t, status.IsBlocked)
+	assert.True(t, resetAt.Equal(*status.ResetAt))
+
+	stored, err := storage.Get(ctx, key)
+	require.NoError(t, err)
+	require.NotNil(t, stored.ResetAt)
+	assert.True(t, resetAt.Equal(*stored.ResetAt))
+}
+
+// TestRedisStorage_ScriptReload testa o EVALSHA depois que o Redis perde o cache de scripts
+func TestRedisStorage_ScriptReload(t *testing.T) {
+	// Arrange
+	storage, _ := newMiniredisStorage(t)
+	ctx := context.Background()
+	key := "rate_limit:ip:10.0.0.1"
+
+	_, _, err := storage.Increment(ctx, key, 10, time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, storage.getClient().ScriptFlush(ctx).Err())
+
+	// Act
+	count, _, err := storage.Increment(ctx, key, 10, time.Minute)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
+
+// TestRedisStorage_ResetInspectHealth testa as operações administrativas e o health check
+func TestRedisStorage_ResetInspectHealth(t *testing.T) {
+	// Arrange
+	storage, server := newMiniredisStorage(t)
+	ctx := context.Background()
+	key := "rate_limit:ip:10.0.0.1"
+	_, _, err := storage.Increment(ctx, key, 10, time.Minute)
+	require.NoError(t, err)
+
+	// Act & Assert
+	record, err := storage.Inspect(ctx, key)
+	require.NoError(t, err)
+	assert.True(t, record.Exists)
+	assert.Empty(t, record.DecodeError)
+	require.NotNil(t, record.Status)
+	assert.Equal(t, 1, record.Status.Count)
+	require.NotNil(t, record.TTL)
+
+	require.NoError(t, storage.Reset(ctx, key))
+	assert.False(t, server.Exists(key))
+
+	assert.NoError(t, storage.Health(ctx))
+	server.Close()
+	assert.Error(t, storage.Health(ctx))
+}
+
+func TestBuildKey(t *testing.T) {
+	tests := []struct {
+		name        string
+		limiterType domain.LimiterType
+		identifier  string
+		opts        []KeyOption
+		expected    string
+	}{
+		{
+			name:        "Should build IP key correctly",
+			limiterType: domain.IPLimiter,
+			identifier:  "192.168.1.1",
+			expected:    "rate_limit:ip:192.168.1.1",
+		},
+		{
+			name:        "Should build Token key correctly",
+			limiterType: domain.TokenLimiter,
+			identifier:  "abc123def456",
+			expected:    "rate_limit:token:abc123def456",
+		},
+		{
+			name:        "Should handle unknown type",
+			limiterType: domain.LimiterType("unknown"),
+			identifier:  "test",
+			expected:    "rate_limit:unknown:test",
+		},
+		{
+			name:        "Should wrap identifier in hash tag",
+			limiterType: domain.IPLimiter,
+			identifier:  "192.168.1.1",
+			opts:        []KeyOption{WithHashTag()},
+			expected:    "rate_limit:ip:{192.168.1.1}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			result := BuildKey(tt.limiterType, tt.identifier, tt.opts...)
+
+			// Assert
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+// TestBuildKey_EncryptedIdentifier testa que o identificador não aparece em
+// claro na chave e que a mesma identidade gera sempre a mesma chave
+func TestBuildKey_EncryptedIdentifier(t *testing.T) {
+	// Arrange
+	cipher, err := NewCipher([]byte("0123456789abcdef0123456789abcdef"))
+	require.NoError(t, err)
+
+	// Act
+	key := BuildKey(domain.IPLimiter, "192.168.1.1", WithEncryptedIdentifier(cipher), WithHashTag())
+
+	// Assert
+	assert.NotContains(t, key, "192.168.1.1")
+	assert.Equal(t, key, BuildKey(domain.IPLimiter, "192.168.1.1", WithEncryptedIdentifier(cipher), WithHashTag()))
+	assert.NotEqual(t, key, BuildKey(domain.IPLimiter, "192.168.1.2", WithEncryptedIdentifier(cipher), WithHashTag()))
+	identifier, err := cipher.OpenIdentifier(HashTag(key))
+	require.NoError(t, err)
+	assert.Equal(t, "192.168.1.1", identifier)
+}
+
+// TestHashTag testa a extração do hash tag segundo as regras do Redis Cluster
+func TestHashTag(t *testing.T) {
+	tagged := BuildKey(domain.TokenLimiter, "premium_token", WithHashTag())
+
+	assert.Equal(t, "premium_token", HashTag(tagged))
+	// Chaves derivadas da mesma identidade (ex: prefixo de tenant, sufixo de cota) ficam no mesmo slot
+	assert.Equal(t, HashTag(tagged), HashTag("tenant_a:"+tagged+":quota"))
+	assert.Equal(t, "rate_limit:ip:10.0.0.1", HashTag("rate_limit:ip:10.0.0.1"))
+	assert.Equal(t, "rate_limit:ip:{}", HashTag("rate_limit:ip:{}"))
+	assert.Equal(t, "a", HashTag("rate_limit:token:{a}b}"))
+}
@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// RedisTLSConfig habilita TLS na conexão com o Redis, exigido por ofertas
+// gerenciadas como ElastiCache com criptografia em trânsito e Azure Cache
+type RedisTLSConfig struct {
+	Enabled            bool
+	CAFile             string // CA do servidor em PEM (vazio = CAs do sistema)
+	CertFile           string // Certificado do cliente para mTLS (exige KeyFile)
+	KeyFile            string
+	InsecureSkipVerify bool // Não valida o certificado do servidor (apenas testes)
+}
+
+// Load carrega os certificados e monta o tls.Config. Retorna nil quando o TLS
+// está desabilitado
+func (c *RedisTLSConfig) Load() (*tls.Config, error) {
+	if c == nil || !c.Enabled {
+		return nil, nil
+	}
+	if (c.CertFile == "") != (c.KeyFile == "") {
+		return nil, fmt.Errorf("Redis TLS client certificate and key must be set together")
+	}
+
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Redis TLS CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in Redis TLS CA file %s", c.CAFile)
+		}
+		config.RootCAs = pool
+	}
+
+	if c.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load Redis TLS client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
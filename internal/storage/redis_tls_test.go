@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"rate-limiter/internal/logger"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeSelfSignedCert gera um certificado autoassinado para 127.0.0.1 e grava
+// o certificado e a chave em PEM, retornando os caminhos
+func writeSelfSignedCert(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "redis-test"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "redis.crt")
+	keyFile = filepath.Join(dir, "redis.key")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return certFile, keyFile
+}
+
+// TestStorageFactory_RedisTLSWithACL testa a conexão por TLS autenticada com usuário ACL
+func TestStorageFactory_RedisTLSWithACL(t *testing.T) {
+	// Arrange
+	certFile, keyFile := writeSelfSignedCert(t)
+	serverCert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	require.NoError(t, err)
+	server, err := miniredis.RunTLS(&tls.Config{Certificates: []tls.Certificate{serverCert}})
+	require.NoError(t, err)
+	t.Cleanup(server.Close)
+	server.RequireUserAuth("limiter", "secret")
+
+	host, port, err := net.SplitHostPort(server.Addr())
+	require.NoError(t, err)
+	config := &StorageConfig{
+		Type: RedisStorageType,
+		RedisConfig: &RedisConfig{
+			Host:     host,
+			Port:     port,
+			Username: "limiter",
+			Password: "secret",
+			TLS:      &RedisTLSConfig{Enabled: true, CAFile: certFile},
+		},
+	}
+
+	// Act
+	created, err := NewStorageFactory().CreateStorage(config, logger.NewLogger("error", "text"))
+
+	// Assert
+	require.NoError(t, err)
+	t.Cleanup(func() { created.Close() })
+	count, _, err := created.Increment(context.Background(), "rate_limit:ip:10.0.0.1", 10, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestRedisTLSConfig_Load(t *testing.T) {
+	certFile, keyFile := writeSelfSignedCert(t)
+
+	tests := []struct {
+		name        string
+		config      *RedisTLSConfig
+		expectNil   bool
+		expectError string
+	}{
+		{name: "nil config", config: nil, expectNil: true},
+		{name: "disabled", config: &RedisTLSConfig{CAFile: "/missing.pem"}, expectNil: true},
+		{name: "system CAs", config: &RedisTLSConfig{Enabled: true}},
+		{name: "mutual TLS", config: &RedisTLSConfig{Enabled: true, CAFile: certFile, CertFile: certFile, KeyFile: keyFile}},
+		{name: "cert without key", config: &RedisTLSConfig{Enabled: true, CertFile: certFile}, expectError: "must be set together"},
+		{name: "missing CA file", config: &RedisTLSConfig{Enabled: true, CAFile: filepath.Join(t.TempDir(), "ca.pem")}, expectError: "failed to read Redis TLS CA file"},
+		{name: "CA file without certificates", config: &RedisTLSConfig{Enabled: true, CAFile: keyFile}, expectError: "no certificates found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			tlsConfig, err := tt.config.Load()
+
+			// Assert
+			if tt.expectError != "" {
+				assert.ErrorContains(t, err, tt.expectError)
+				return
+			}
+			require.NoError(t, err)
+			if tt.expectNil {
+				assert.Nil(t, tlsConfig)
+				return
+			}
+			require.NotNil(t, tlsConfig)
+			assert.Equal(t, uint16(tls.VersionTLS12), tlsConfig.MinVersion)
+			assert.Equal(t, tt.config.CertFile != "", len(tlsConfig.Certificates) == 1)
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package storage
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"rate-limiter/internal/domain"
+)
+
+// Registry mantém storages nomeados para que regras possam fixar um backend
+// Ex.: "memory" para limites por instância e "redis" para limites globais
+type Registry struct {
+	mutex       sync.RWMutex
+	storages    map[string]domain.RateLimiterStorage
+	defaultName string
+}
+
+// NewRegistry cria um registro com o storage padrão
+func NewRegistry(defaultName string, defaultStorage domain.RateLimiterStorage) *Registry {
+	defaultName = normalizeStorageName(defaultName)
+
+	return &Registry{
+		storages:    map[string]domain.RateLimiterStorage{defaultName: defaultStorage},
+		defaultName: defaultName,
+	}
+}
+
+// Register adiciona um storage nomeado
+func (r *Registry) Register(name string, storage domain.RateLimiterStorage) error {
+	name = normalizeStorageName(name)
+	if name == "" {
+		return fmt.Errorf("storage name cannot be empty")
+	}
+	if storage == nil {
+		return fmt.Errorf("storage %s cannot be nil", name)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.storages[name]; exists {
+		return fmt.Errorf("storage %s already registered", name)
+	}
+
+	r.storages[name] = storage
+	return nil
+}
+
+// Get retorna o storage pelo nome; nome vazio retorna o padrão
+func (r *Registry) Get(name string) (domain.RateLimiterStorage, bool) {
+	name = normalizeStorageName(name)
+	if name == "" {
+		name = r.defaultName
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	storage, exists := r.storages[name]
+	return storage, exists
+}
+
+// Default retorna o storage padrão
+func (r *Registry) Default() domain.RateLimiterStorage {
+	storage, _ := r.Get("")
+	return storage
+}
+
+// DefaultName retorna o nome do storage padrão
+func (r *Registry) DefaultName() string {
+	return r.defaultName
+}
+
+// Names retorna os nomes registrados em ordem alfabética
+func (r *Registry) Names() []string {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	names := make([]string, 0, len(r.storages))
+	for name := range r.storages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Storages retorna uma cópia do mapa de storages nomeados
+func (r *Registry) Storages() map[string]domain.RateLimiterStorage {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	storages := make(map[string]domain.RateLimiterStorage, len(r.storages))
+	for name, storage := range r.storages {
+		storages[name] = storage
+	}
+	return storages
+}
+
+// Close fecha todos os storages registrados
+func (r *Registry) Close() error {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var firstErr error
+	for name, storage := range r.storages {
+		if err := storage.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to close storage %s: %w", name, err)
+		}
+	}
+	return firstErr
+}
+
+// normalizeStorageName padroniza nomes de storage
+func normalizeStorageName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
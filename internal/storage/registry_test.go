@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/logger"
+)
+
+func TestRegistry(t *testing.T) {
+	// Arrange
+	testLogger := logger.NewLogger("debug", "text")
+	primary := NewMemoryStorage(testLogger)
+	local := NewMemoryStorage(testLogger)
+	registry := NewRegistry("Redis", primary)
+	defer registry.Close()
+
+	// Act
+	err := registry.Register(" memory ", local)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"memory", "redis"}, registry.Names())
+	assert.Same(t, primary, registry.Default())
+
+	storage, exists := registry.Get("MEMORY")
+	assert.True(t, exists)
+	assert.Same(t, local, storage)
+
+	storage, exists = registry.Get("")
+	assert.True(t, exists)
+	assert.Same(t, primary, storage)
+
+	_, exists = registry.Get("etcd")
+	assert.False(t, exists)
+
+	assert.Error(t, registry.Register("memory", NewMemoryStorage(testLogger)))
+	assert.Error(t, registry.Register("", local))
+	assert.Error(t, registry.Register("other", nil))
+}
+
+func TestStorageFactory_CreateRegistry(t *testing.T) {
+	factory := NewStorageFactory()
+	testLogger := logger.NewLogger("debug", "text")
+
+	registry, err := factory.CreateRegistry([]*StorageConfig{
+		{Type: MemoryStorageType},
+		{Type: MemoryStorageType, Name: "local"},
+	}, testLogger)
+	require.NoError(t, err)
+	defer registry.Close()
+
+	assert.Equal(t, []string{"local", "memory"}, registry.Names())
+	assert.IsType(t, &MemoryStorage{}, registry.Default())
+
+	_, err = factory.CreateRegistry(nil, testLogger)
+	assert.Error(t, err)
+
+	_, err = factory.CreateRegistry([]*StorageConfig{
+		{Type: MemoryStorageType},
+		{Type: MemoryStorageType},
+	}, testLogger)
+	assert.Error(t, err)
+}
@@ -0,0 +1,208 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"rate-limiter/internal/domain"
+)
+
+// resetScanCount é o COUNT de cada SCAN do reset por padrão
+const resetScanCount = 500
+
+// BuildKeyPattern monta o glob das chaves do tipo a partir de um glob de
+// identificadores ("*" e "?"), com as mesmas opções de BuildKey. Identificadores
+// cifrados não podem ser filtrados: só "*" (todas as chaves do tipo) é aceito
+func BuildKeyPattern(limiterType domain.LimiterType, pattern string, opts ...KeyOption) (string, error) {
+	// [ ] e \ têm significado no MATCH do Redis e não no filtro em memória
+	if pattern == "" || strings.ContainsAny(pattern, `[]\`) {
+		return "", fmt.Errorf("%w: %q (only * and ? are supported)", domain.ErrInvalidPattern, pattern)
+	}
+
+	var options keyOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.cipher != nil && pattern != "*" {
+		return "", fmt.Errorf("%w: identifiers are encrypted, only * is supported", domain.ErrInvalidPattern)
+	}
+	if options.hashTag {
+		pattern = "{" + pattern + "}"
+	}
+	return KeyPrefix(limiterType) + pattern, nil
+}
+
+// matchGlob informa se key casa com o glob, em que "*" casa qualquer sequência
+// (inclusive vazia) e "?" um único byte, como no MATCH do Redis
+func matchGlob(pattern, key string) bool {
+	p, k := 0, 0
+	star, mark := -1, 0
+	for k < len(key) {
+		switch {
+		case p < len(pattern) && (pattern[p] == '?' || pattern[p] == key[k]):
+			p++
+			k++
+		case p < len(pattern) && pattern[p] == '*':
+			star, mark = p, k
+			p++
+		case star >= 0:
+			// Volta ao último "*" e o faz consumir mais um byte
+			mark++
+			p, k = star+1, mark
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// ResetPattern implementa domain.PatternResetter removendo de todas as partições
+// as chaves que casam com o glob (contadores, bloqueios, logs e buckets)
+func (m *MemoryStorage) ResetPattern(ctx context.Context, pattern string) (int, error) {
+	ctx, span := startSpan(ctx, MemoryStorageType, "RESET_PATTERN", pattern)
+	defer span.End()
+
+	start := time.Now()
+
+	removed := 0
+	for _, shard := range m.shards {
+		m.lockShard(ctx, shard)
+		matched := make(map[string]struct{})
+		collect := func(key string) {
+			if matchGlob(pattern, key) {
+				matched[key] = struct{}{}
+			}
+		}
+		for key := range shard.data.index {
+			collect(key)
+		}
+		for key := range shard.blocks {
+			collect(key)
+		}
+		for key := range shard.logs {
+			collect(key)
+		}
+		for key := range shard.buckets {
+			collect(key)
+		}
+		for key := range shard.leaks {
+			collect(key)
+		}
+		for key := range shard.history {
+			collect(key)
+		}
+		for key := range matched {
+			shard.remove(key)
+			m.untrack(key)
+		}
+		shard.mutex.Unlock()
+		removed += len(matched)
+	}
+
+	m.logStorageOperation(ctx, "RESET_PATTERN", pattern, true, time.Since(start).Seconds()*1000, nil)
+	return removed, nil
+}
+
+// ResetPattern implementa domain.PatternResetter com SCAN+DEL. Cada chave é
+// removida com o log e o histórico de janelas, como no Reset; chaves criadas
+// durante a varredura podem ou não ser removidas
+func (r *RedisStorage) ResetPattern(ctx context.Context, pattern string) (int, error) {
+	ctx, span := startSpan(ctx, RedisStorageType, "RESET_PATTERN", pattern)
+	defer span.End()
+
+	start := time.Now()
+	client := r.getClient()
+
+	removed := 0
+	var cursor uint64
+	for {
+		batch, next, err := client.Scan(ctx, cursor, pattern, resetScanCount).Result()
+		if err != nil {
+			r.logStorageOperation(ctx, "RESET_PATTERN", pattern, false, time.Since(start).Seconds()*1000, err)
+			return removed, fmt.Errorf("failed to scan keys %s: %w", pattern, err)
+		}
+
+		// Um DEL por chave: no Redis Cluster, as três chaves ficam no mesmo slot
+		// apenas com hash tag, e um DEL de várias chaves quebraria entre slots
+		pipe := client.Pipeline()
+		var deletes []*redis.IntCmd
+		for _, key := range batch {
+			if strings.HasSuffix(key, slidingLogSuffix) || strings.HasSuffix(key, windowHistorySuffix) {
+				continue
+			}
+			deletes = append(deletes, pipe.Del(ctx, key, key+slidingLogSuffix, key+windowHistorySuffix))
+			if r.blockPartitions > 0 {
+				pipe.ZRem(ctx, r.blockPartition(key), key)
+			}
+		}
+		if len(deletes) > 0 {
+			if _, err := pipe.Exec(ctx); err != nil {
+				r.logStorageOperation(ctx, "RESET_PATTERN", pattern, false, time.Since(start).Seconds()*1000, err)
+				return removed, fmt.Errorf("failed to delete keys %s: %w", pattern, err)
+			}
+			for _, del := range deletes {
+				if del.Val() > 0 {
+					removed++
+				}
+			}
+		}
+
+		cursor = next
+		if cursor == 0 {
+			break
+		}
+	}
+
+	r.logStorageOperation(ctx, "RESET_PATTERN", pattern, true, time.Since(start).Seconds()*1000, nil)
+	return removed, nil
+}
+
+// ResetPattern implementa domain.PatternResetter no Redis e no cache desta instância
+func (t *TieredStorage) ResetPattern(ctx context.Context, pattern string) (int, error) {
+	removed, err := t.RedisStorage.ResetPattern(ctx, pattern)
+	if err != nil {
+		return removed, err
+	}
+	if _, err := t.cache.ResetPattern(ctx, pattern); err != nil {
+		return removed, err
+	}
+	return removed, nil
+}
+
+// ResetPattern implementa domain.PatternResetter descartando os incrementos
+// pendentes e limpando os dois storages; a contagem é a do Redis
+func (h *HybridStorage) ResetPattern(ctx context.Context, pattern string) (int, error) {
+	h.mutex.Lock()
+	for key := range h.pending {
+		if matchGlob(pattern, key) {
+			delete(h.pending, key)
+		}
+	}
+	h.mutex.Unlock()
+
+	if _, err := h.local.ResetPattern(ctx, pattern); err != nil {
+		return 0, err
+	}
+	return h.remote.ResetPattern(ctx, pattern)
+}
+
+// ResetPattern implementa domain.PatternResetter quando o storage interno o
+// suporta, restrito às chaves da visão
+func (p *PrefixedStorage) ResetPattern(ctx context.Context, pattern string) (int, error) {
+	resetter, ok := p.inner.(domain.PatternResetter)
+	if !ok {
+		return 0, domain.ErrPatternResetUnsupported
+	}
+	// O prefixo é literal: caracteres de glob nele casariam outras visões
+	if strings.ContainsAny(p.prefix, `*?[]\`) {
+		return 0, fmt.Errorf("%w: prefix %q contains glob characters", domain.ErrInvalidPattern, p.prefix)
+	}
+	return resetter.ResetPattern(ctx, p.prefix+pattern)
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/logger"
+)
+
+func TestPatternResetter(t *testing.T) {
+	storages := map[string]func(t *testing.T) domain.RateLimiterStorage{
+		"memory": func(t *testing.T) domain.RateLimiterStorage {
+			return NewMemoryStorage(nil)
+		},
+		"redis": func(t *testing.T) domain.RateLimiterStorage {
+			client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+			return NewRedisStorageWithClient(client, logger.NewNopLogger())
+		},
+	}
+
+	for name, create := range storages {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			storage := create(t)
+			defer storage.Close()
+			ctx := context.Background()
+			for _, ip := range []string{"192.168.1.1", "192.168.1.2", "10.0.0.1"} {
+				_, _, err := storage.Increment(ctx, BuildKey(domain.IPLimiter, ip), 10, time.Minute)
+				require.NoError(t, err)
+			}
+			require.NoError(t, storage.Block(ctx, BuildKey(domain.IPLimiter, "192.168.1.3"), time.Minute))
+			_, _, err := storage.Increment(ctx, BuildKey(domain.TokenLimiter, "192.168.1.9"), 10, time.Minute)
+			require.NoError(t, err)
+			pattern, err := BuildKeyPattern(domain.IPLimiter, "192.168.1.*")
+			require.NoError(t, err)
+
+			// Act
+			removed, err := storage.(domain.PatternResetter).ResetPattern(ctx, pattern)
+
+			// Assert: só as chaves de IP da faixa, incluindo a que tem apenas o bloqueio
+			require.NoError(t, err)
+			assert.Equal(t, 3, removed)
+			status, err := storage.Get(ctx, BuildKey(domain.IPLimiter, "192.168.1.1"))
+			require.NoError(t, err)
+			assert.Nil(t, status)
+			blocked, _, err := storage.IsBlocked(ctx, BuildKey(domain.IPLimiter, "192.168.1.3"))
+			require.NoError(t, err)
+			assert.False(t, blocked)
+			for _, key := range []string{BuildKey(domain.IPLimiter, "10.0.0.1"), BuildKey(domain.TokenLimiter, "192.168.1.9")} {
+				status, err := storage.Get(ctx, key)
+				require.NoError(t, err)
+				assert.NotNil(t, status, key)
+			}
+		})
+	}
+}
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		key     string
+		want    bool
+	}{
+		{"*", "", true},
+		{"rate_limit:ip:192.168.1.*", "rate_limit:ip:192.168.1.20", true},
+		{"rate_limit:ip:192.168.1.*", "rate_limit:ip:192.168.10.1", false},
+		{"rate_limit:ip:10.0.0.?", "rate_limit:ip:10.0.0.7", true},
+		{"rate_limit:ip:10.0.0.?", "rate_limit:ip:10.0.0.17", false},
+		{"rate_limit:token:*_test", "rate_limit:token:a_test_b_test", true},
+		{"rate_limit:token:*_test", "rate_limit:token:a_test_b", false},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, matchGlob(tt.pattern, tt.key), "%s ~ %s", tt.pattern, tt.key)
+	}
+}
+
+func TestBuildKeyPattern(t *testing.T) {
+	// Arrange
+	cipher, err := NewCipher([]byte("0123456789abcdef"))
+	require.NoError(t, err)
+
+	// Act
+	tagged, err := BuildKeyPattern(domain.IPLimiter, "192.168.1.*", WithHashTag())
+	require.NoError(t, err)
+	encrypted, err := BuildKeyPattern(domain.TokenLimiter, "*", WithEncryptedIdentifier(cipher))
+	require.NoError(t, err)
+	_, encryptedErr := BuildKeyPattern(domain.TokenLimiter, "abc*", WithEncryptedIdentifier(cipher))
+	_, classErr := BuildKeyPattern(domain.IPLimiter, "10.0.0.[12]")
+
+	// Assert
+	assert.Equal(t, "rate_limit:ip:{192.168.1.*}", tagged)
+	assert.Equal(t, "rate_limit:token:*", encrypted)
+	assert.ErrorIs(t, encryptedErr, domain.ErrInvalidPattern)
+	assert.ErrorIs(t, classErr, domain.ErrInvalidPattern)
+}
@@ -0,0 +1,173 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// DefaultRedisMaxRetries é o número padrão de novas tentativas com o circuito fechado
+	DefaultRedisMaxRetries = 3
+	// DefaultRedisMinRetryBackoff e DefaultRedisMaxRetryBackoff limitam a espera entre tentativas
+	DefaultRedisMinRetryBackoff = 8 * time.Millisecond
+	DefaultRedisMaxRetryBackoff = 512 * time.Millisecond
+)
+
+// RetryConfig configura a política de novas tentativas no Redis
+type RetryConfig struct {
+	MaxRetries int           // Novas tentativas com o circuito fechado (0 = DefaultRedisMaxRetries, < 0 = nenhuma)
+	MinBackoff time.Duration // Espera antes da primeira nova tentativa, dobrada a cada uma
+	MaxBackoff time.Duration
+}
+
+// RetryStats resume as decisões da política
+type RetryStats struct {
+	Retries         uint64 // Novas tentativas executadas
+	SkippedBudget   uint64 // Dispensadas: a espera e a tentativa não cabiam no prazo
+	SkippedHalfOpen uint64 // Dispensadas: circuito half-open ou aberto
+}
+
+// RetryPolicy substitui o MaxRetries fixo do cliente Redis por novas tentativas
+// adaptativas. Uma operação só é repetida com o circuito fechado e quando a
+// espera mais a duração da última tentativa cabem no prazo do contexto. Assim
+// um Redis em recuperação ou lento não recebe rajadas de repetições justamente
+// quando o orçamento de latência da requisição já acabou
+type RetryPolicy struct {
+	config RetryConfig
+
+	mutex   sync.RWMutex
+	circuit CircuitReporter
+
+	retries         atomic.Uint64
+	skippedBudget   atomic.Uint64
+	skippedHalfOpen atomic.Uint64
+}
+
+// NewRetryPolicy cria a política; sem circuito associado, ele é tratado como fechado
+func NewRetryPolicy(config RetryConfig) *RetryPolicy {
+	if config.MaxRetries == 0 {
+		config.MaxRetries = DefaultRedisMaxRetries
+	}
+	if config.MinBackoff <= 0 {
+		config.MinBackoff = DefaultRedisMinRetryBackoff
+	}
+	if config.MaxBackoff < config.MinBackoff {
+		config.MaxBackoff = DefaultRedisMaxRetryBackoff
+	}
+	return &RetryPolicy{config: config}
+}
+
+// SetCircuit associa o estado do circuito (normalmente o HealthMonitor do storage)
+func (p *RetryPolicy) SetCircuit(circuit CircuitReporter) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.circuit = circuit
+}
+
+// Stats retorna os contadores da política
+func (p *RetryPolicy) Stats() RetryStats {
+	return RetryStats{
+		Retries:         p.retries.Load(),
+		SkippedBudget:   p.skippedBudget.Load(),
+		SkippedHalfOpen: p.skippedHalfOpen.Load(),
+	}
+}
+
+// Do executa op e a repete enquanto o erro for transitório e a política permitir
+func (p *RetryPolicy) Do(ctx context.Context, op func(ctx context.Context) error) error {
+	start := time.Now()
+	err := op(ctx)
+	for attempt := 1; attempt <= p.config.MaxRetries && isRetryable(err); attempt++ {
+		backoff := p.backoff(attempt)
+		if !p.allow(ctx, backoff, time.Since(start)) {
+			return err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		p.retries.Add(1)
+		start = time.Now()
+		err = op(ctx)
+	}
+	return err
+}
+
+// allow decide se ainda há espaço para uma nova tentativa
+func (p *RetryPolicy) allow(ctx context.Context, backoff, lastAttempt time.Duration) bool {
+	p.mutex.RLock()
+	circuit := p.circuit
+	p.mutex.RUnlock()
+
+	if circuit != nil && circuit.CircuitState() != CircuitClosed {
+		p.skippedHalfOpen.Add(1)
+		return false
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < backoff+lastAttempt {
+		p.skippedBudget.Add(1)
+		return false
+	}
+	return true
+}
+
+// backoff dobra a espera a cada tentativa, até MaxBackoff
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := p.config.MinBackoff
+	for i := 1; i < attempt && backoff < p.config.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > p.config.MaxBackoff {
+		backoff = p.config.MaxBackoff
+	}
+	return backoff
+}
+
+// isRetryable informa se o erro é transitório: falhas de rede e respostas do
+// Redis que indicam indisponibilidade momentânea (carregando, failover)
+func isRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	for _, prefix := range []string{"LOADING ", "READONLY ", "MASTERDOWN ", "TRYAGAIN ", "CLUSTERDOWN "} {
+		if strings.HasPrefix(err.Error(), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetRetryPolicy substitui a política de novas tentativas do storage
+func (r *RedisStorage) SetRetryPolicy(policy *RetryPolicy) {
+	r.retry = policy
+}
+
+// RetryPolicy retorna a política de novas tentativas do storage (nil = nenhuma)
+func (r *RedisStorage) RetryPolicy() *RetryPolicy {
+	return r.retry
+}
+
+// withRetry executa op pela política do storage, ou uma única vez sem política
+func (r *RedisStorage) withRetry(ctx context.Context, op func(ctx context.Context) error) error {
+	if r.retry == nil {
+		return op(ctx)
+	}
+	return r.retry.Do(ctx, op)
+}
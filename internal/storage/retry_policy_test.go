@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"rate-limiter/internal/logger"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixedCircuit reporta sempre o mesmo estado de circuito
+type fixedCircuit CircuitState
+
+func (c fixedCircuit) CircuitState() CircuitState {
+	return CircuitState(c)
+}
+
+// failingOp falha com err nas primeiras failures chamadas
+func failingOp(failures int, err error) (func(ctx context.Context) error, *int) {
+	calls := 0
+	return func(ctx context.Context) error {
+		calls++
+		if calls <= failures {
+			return err
+		}
+		return nil
+	}, &calls
+}
+
+func TestRetryPolicy_RetriesTransientErrors(t *testing.T) {
+	// Arrange
+	policy := NewRetryPolicy(RetryConfig{MaxRetries: 3, MinBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond})
+	policy.SetCircuit(fixedCircuit(CircuitClosed))
+	op, calls := failingOp(2, io.EOF)
+
+	// Act
+	err := policy.Do(context.Background(), op)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 3, *calls)
+	assert.Equal(t, uint64(2), policy.Stats().Retries)
+}
+
+func TestRetryPolicy_SkipsRetries(t *testing.T) {
+	tests := []struct {
+		name            string
+		circuit         CircuitState
+		timeout         time.Duration
+		err             error
+		skippedBudget   uint64
+		skippedHalfOpen uint64
+	}{
+		{name: "Half-open circuit", circuit: CircuitHalfOpen, timeout: time.Second, err: io.EOF, skippedHalfOpen: 1},
+		{name: "Open circuit", circuit: CircuitOpen, timeout: time.Second, err: io.EOF, skippedHalfOpen: 1},
+		{name: "Latency budget exhausted", circuit: CircuitClosed, timeout: 5 * time.Millisecond, err: io.EOF, skippedBudget: 1},
+		{name: "Permanent error", circuit: CircuitClosed, timeout: time.Second, err: errors.New("WRONGTYPE Operation against a key holding the wrong kind of value")},
+		{name: "Deadline exceeded", circuit: CircuitClosed, timeout: time.Second, err: context.DeadlineExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			policy := NewRetryPolicy(RetryConfig{MaxRetries: 3, MinBackoff: 10 * time.Millisecond})
+			policy.SetCircuit(fixedCircuit(tt.circuit))
+			op, calls := failingOp(1, tt.err)
+			ctx, cancel := context.WithTimeout(context.Background(), tt.timeout)
+			defer cancel()
+
+			// Act
+			err := policy.Do(ctx, op)
+
+			// Assert
+			assert.ErrorIs(t, err, tt.err)
+			assert.Equal(t, 1, *calls)
+			stats := policy.Stats()
+			assert.Equal(t, uint64(0), stats.Retries)
+			assert.Equal(t, tt.skippedBudget, stats.SkippedBudget)
+			assert.Equal(t, tt.skippedHalfOpen, stats.SkippedHalfOpen)
+		})
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := NewRetryPolicy(RetryConfig{MinBackoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond})
+
+	assert.Equal(t, 10*time.Millisecond, policy.backoff(1))
+	assert.Equal(t, 20*time.Millisecond, policy.backoff(2))
+	assert.Equal(t, 40*time.Millisecond, policy.backoff(3))
+	assert.Equal(t, 50*time.Millisecond, policy.backoff(4))
+}
+
+// TestRedisStorage_RetryPolicy testa as novas tentativas do storage enquanto o Redis carrega o dataset
+func TestRedisStorage_RetryPolicy(t *testing.T) {
+	// Arrange
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1})
+	storage := NewRedisStorageWithClient(client, logger.NewNopLogger())
+	policy := NewRetryPolicy(RetryConfig{MaxRetries: 5, MinBackoff: 5 * time.Millisecond, MaxBackoff: 10 * time.Millisecond})
+	storage.SetRetryPolicy(policy)
+	defer storage.Close()
+
+	server.SetError("LOADING Redis is loading the dataset in memory")
+	go func() {
+		time.Sleep(12 * time.Millisecond)
+		server.SetError("")
+	}()
+
+	// Act
+	status, err := storage.Get(context.Background(), "rate_limit:ip:10.0.0.1")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Nil(t, status)
+	assert.Greater(t, policy.Stats().Retries, uint64(0))
+}
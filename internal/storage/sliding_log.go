@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// slidingLogSuffix identifica o sorted set com o log de requisições da chave no
+// Redis; a chave de status continua com o JSON lido por Get, Block e Inspect
+const slidingLogSuffix = ":log"
+
+// incrementSlidingLog registra a requisição no log da chave e conta as
+// requisições aceitas nos últimos window. Requisições acima do limite não
+// entram no log, que fica limitado a limit entradas por chave.
+// Retorna a contagem (incluindo a requisição atual) e o instante da mais antiga
+func (m *MemoryStorage) incrementSlidingLog(ctx context.Context, key string, limit int, window time.Duration) (int, time.Time) {
+	shard := m.lock(ctx, key)
+	defer m.unlock(shard)
+
+	now := m.now().UnixNano()
+	entries := trimSlidingLog(shard.logs[key], now-int64(window))
+	count := len(entries) + 1
+	if count <= limit {
+		entries = append(entries, now)
+	}
+
+	oldest := now
+	if len(entries) > 0 {
+		oldest = entries[0]
+		shard.logs[key] = entries
+	} else {
+		delete(shard.logs, key)
+	}
+
+	// O registro de status acompanha o log para Get, Inspect e a limpeza periódica
+	record, exists := shard.data.get(key)
+	if !exists {
+		record = m.insert(shard, key, memoryRecord{})
+	}
+	record.limit = clampInt32(limit)
+	record.window = clampInt32(int(window.Seconds()))
+	record.lastReset = oldest
+	record.count = clampInt32(count)
+	if count > limit {
+		record.blocked = true
+	} else if blockedUntil, blocked := shard.blocks[key]; !blocked || now > blockedUntil {
+		// Bloqueio expirado: a requisição voltou a caber no log
+		record.blocked = false
+		record.blockedUntil = 0
+	}
+
+	return count, fromUnixNano(oldest)
+}
+
+// trimSlidingLog descarta os instantes até cutoff (inclusive); o log é ordenado
+func trimSlidingLog(entries []int64, cutoff int64) []int64 {
+	for len(entries) > 0 && entries[0] <= cutoff {
+		entries = entries[1:]
+	}
+	return entries
+}
+
+// slidingLogSource registra a requisição no sorted set KEYS[2] e atualiza o
+// status JSON em KEYS[1] com a contagem e o instante da requisição mais antiga.
+// ARGV[4] é o membro único da requisição no sorted set
+const slidingLogSource = `
+	local key = KEYS[1]
+	local logKey = KEYS[2]
+	local limit = tonumber(ARGV[1])
+	local window = tonumber(ARGV[2]) -- em milissegundos
+	local now = tonumber(ARGV[3])
+	local member = ARGV[4]
+
+	-- Descarta as requisições fora da janela e registra a atual, se aceita
+	redis.call('ZREMRANGEBYSCORE', logKey, '-inf', now - window)
+	local count = redis.call('ZCARD', logKey) + 1
+	if count <= limit then
+		redis.call('ZADD', logKey, now, member)
+	end
+	redis.call('PEXPIRE', logKey, window)
+
+	local oldest = now
+	local first = redis.call('ZRANGE', logKey, 0, 0, 'WITHSCORES')
+	if first[2] then
+		oldest = tonumber(first[2])
+	end
+
+	-- Status lido por Get, IsBlocked e Inspect
+	local current = redis.call('GET', key)
+	local data = {}
+	if current then
+		data = cjson.decode(current)
+	else
+		data = { key = key, type = '' }
+	end
+
+	local blocked = type(data.blockedUntil) == 'number' and data.blockedUntil > now
+	if not blocked then
+		data.blockedUntil = nil
+	end
+	data.count = count
+	data.limit = limit
+	data.window = math.floor(window / 1000)
+	data.lastReset = oldest
+	data.isBlocked = blocked or count > limit
+
+	-- Preserva o TTL de um bloqueio mais longo que a janela
+	local ttl = redis.call('PTTL', key)
+	if ttl < window then
+		ttl = window
+	end
+	redis.call('SET', key, cjson.encode(data), 'PX', ttl)
+
+	return {count, oldest}
+`
+
+var slidingLogScript = redis.NewScript(slidingLogSource)
+
+// incrementSlidingLog executa o sliding window log atomicamente no Redis
+func (r *RedisStorage) incrementSlidingLog(ctx context.Context, key string, limit int, window time.Duration) (int, time.Time, error) {
+	now := time.Now().UnixMilli()
+	// Requisições no mesmo milissegundo, de qualquer instância, precisam de membros distintos
+	member := strconv.FormatInt(time.Now().UnixNano(), 36) + "-" + strconv.FormatUint(rand.Uint64(), 36)
+
+	result, err := r.eval(ctx, "sliding_log", slidingLogScript, []string{key, key + slidingLogSuffix}, limit, window.Milliseconds(), now, member)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("failed to increment key %s: %w", key, err)
+	}
+
+	values, ok := result.([]interface{})
+	if !ok || len(values) < 2 {
+		return 0, time.Time{}, fmt.Errorf("invalid sliding log result for key %s", key)
+	}
+	count, err := strconv.Atoi(fmt.Sprint(values[0]))
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("invalid count in result for key %s: %w", key, err)
+	}
+	oldest, err := strconv.ParseInt(fmt.Sprint(values[1]), 10, 64)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("invalid oldest entry in result for key %s: %w", key, err)
+	}
+
+	return count, time.UnixMilli(oldest), nil
+}
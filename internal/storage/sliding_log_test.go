@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"rate-limiter/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// incrementCounts executa n incrementos e retorna as contagens obtidas
+func incrementCounts(t *testing.T, storage domain.RateLimiterStorage, key string, n, limit int, window time.Duration) []int {
+	t.Helper()
+
+	counts := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		count, _, err := storage.Increment(context.Background(), key, limit, window)
+		require.NoError(t, err)
+		counts = append(counts, count)
+	}
+	return counts
+}
+
+func TestMemoryStorage_SlidingLog(t *testing.T) {
+	// Arrange: limite 3 em 200ms
+	storage := NewMemoryStorage(nil)
+	storage.algorithm = AlgorithmSlidingLog
+	window := 200 * time.Millisecond
+	key := "rate_limit:ip:10.0.0.1"
+
+	// Act: 2 requisições, mais 2 após 120ms e mais 1 após a saída das primeiras do log
+	first := incrementCounts(t, storage, key, 2, 3, window)
+	time.Sleep(120 * time.Millisecond)
+	second := incrementCounts(t, storage, key, 2, 3, window)
+	time.Sleep(100 * time.Millisecond)
+	third := incrementCounts(t, storage, key, 1, 3, window)
+
+	// Assert: a recusada não entra no log; só a terceira requisição continua na janela
+	assert.Equal(t, []int{1, 2}, first)
+	assert.Equal(t, []int{3, 4}, second)
+	assert.Equal(t, []int{2}, third)
+	assert.Len(t, storage.shard(key).logs[key], 2)
+
+	status, err := storage.Get(context.Background(), key)
+	require.NoError(t, err)
+	assert.Equal(t, 2, status.Count)
+	assert.False(t, status.IsBlocked)
+
+	require.NoError(t, storage.Reset(context.Background(), key))
+	assert.NotContains(t, storage.shard(key).logs, key)
+}
+
+func TestRedisStorage_SlidingLog(t *testing.T) {
+	// Arrange
+	storage, server := newMiniredisStorage(t)
+	storage.algorithm = AlgorithmSlidingLog
+	window := 200 * time.Millisecond
+	key := "rate_limit:{ip:10.0.0.1}"
+
+	// Act
+	first := incrementCounts(t, storage, key, 2, 3, window)
+	time.Sleep(120 * time.Millisecond)
+	second := incrementCounts(t, storage, key, 2, 3, window)
+	time.Sleep(100 * time.Millisecond)
+	third := incrementCounts(t, storage, key, 1, 3, window)
+
+	// Assert: o log guarda só as aceitas na janela; o status acompanha a contagem
+	assert.Equal(t, []int{1, 2}, first)
+	assert.Equal(t, []int{3, 4}, second)
+	assert.Equal(t, []int{2}, third)
+
+	members, err := server.ZMembers(key + slidingLogSuffix)
+	require.NoError(t, err)
+	assert.Len(t, members, 2)
+
+	status, err := storage.Get(context.Background(), key)
+	require.NoError(t, err)
+	require.NotNil(t, status)
+	assert.Equal(t, 2, status.Count)
+	assert.Equal(t, 3, status.Limit)
+	assert.False(t, status.IsBlocked)
+
+	require.NoError(t, storage.Reset(context.Background(), key))
+	assert.False(t, server.Exists(key+slidingLogSuffix))
+}
+
+func TestStorageFactory_Algorithm(t *testing.T) {
+	factory := NewStorageFactory()
+
+	// Act
+	memory, err := factory.CreateStorage(&StorageConfig{Type: MemoryStorageType, Algorithm: AlgorithmSlidingLog}, nil)
+	hybridErr := factory.ValidateConfig(&StorageConfig{Type: HybridStorageType, Algorithm: AlgorithmSlidingLog, RedisConfig: &RedisConfig{Host: "localhost", Port: "6379"}})
+	unknownErr := factory.ValidateConfig(&StorageConfig{Type: MemoryStorageType, Algorithm: "gcra"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, AlgorithmSlidingLog, memory.(*MemoryStorage).algorithm)
+	assert.EqualError(t, hybridErr, "storage type hybrid does not support the sliding_log algorithm")
+	assert.EqualError(t, unknownErr, "unsupported rate limit algorithm: gcra")
+}
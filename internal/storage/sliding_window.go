@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// incrementSlidingWindow aplica o sliding window counter: as janelas são
+// contíguas e a contagem da anterior pesa a fração dela ainda coberta pela
+// janela deslizante. Requisições acima do limite não entram no contador.
+// Retorna a contagem ponderada (incluindo a requisição atual) e o início da janela atual
+func (m *MemoryStorage) incrementSlidingWindow(ctx context.Context, key string, limit int, window time.Duration) (int, time.Time) {
+	shard := m.lock(ctx, key)
+	defer m.unlock(shard)
+
+	now := m.now().UnixNano()
+
+	record, exists := shard.data.get(key)
+	if !exists {
+		record = m.insert(shard, key, memoryRecord{lastReset: now})
+	}
+	record.limit = clampInt32(limit)
+	record.window = clampInt32(int(window.Seconds()))
+
+	// Na virada, a janela que acabou de fechar vira a anterior; sem tráfego por
+	// mais de uma janela, a anterior fica vazia
+	if elapsed := now - record.lastReset; elapsed >= int64(window) {
+		if exists {
+			m.recordWindow(shard, key, record, window)
+		}
+		windows := elapsed / int64(window)
+		record.previous = 0
+		if windows == 1 {
+			record.previous = record.count
+		}
+		record.lastReset += windows * int64(window)
+		record.count = 0
+	}
+
+	count := slidingWindowCount(int(record.previous), int(record.count), now-record.lastReset, int64(window)) + 1
+	if count <= limit {
+		record.increment()
+	}
+
+	if count > limit {
+		record.blocked = true
+	} else if blockedUntil, blocked := shard.blocks[key]; !blocked || now > blockedUntil {
+		// Bloqueio expirado: a requisição voltou a caber na janela
+		record.blocked = false
+		record.blockedUntil = 0
+	}
+
+	return count, fromUnixNano(record.lastReset)
+}
+
+// slidingWindowCount pondera a contagem da janela anterior pela fração dela
+// ainda coberta após elapsed na janela atual, como o script Lua do Redis
+func slidingWindowCount(previous, current int, elapsed, window int64) int {
+	weight := float64(window-elapsed) / float64(window)
+	return int(float64(previous)*weight) + current
+}
@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStorage_SlidingWindow(t *testing.T) {
+	// Arrange: limite 4 em 200ms
+	storage := NewMemoryStorage(nil)
+	storage.algorithm = AlgorithmSlidingWindow
+	window := 200 * time.Millisecond
+	key := "rate_limit:ip:10.0.0.1"
+
+	// Act: 5 requisições e mais 3 a ~75ms da janela seguinte, quando a anterior pesa 5/8
+	first := incrementCounts(t, storage, key, 5, 4, window)
+	time.Sleep(275 * time.Millisecond)
+	second := incrementCounts(t, storage, key, 3, 4, window)
+
+	// Assert: a recusada não conta; a anterior contribui com floor(4 * 0.625) = 2
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, first)
+	assert.Equal(t, []int{3, 4, 5}, second)
+
+	status, err := storage.Get(context.Background(), key)
+	require.NoError(t, err)
+	assert.Equal(t, 2, status.Count)
+	record, exists := storage.shard(key).data.get(key)
+	require.True(t, exists)
+	assert.Equal(t, int32(4), record.previous)
+}
+
+func TestRedisStorage_SlidingWindow(t *testing.T) {
+	// Arrange
+	storage, _ := newMiniredisStorage(t)
+	storage.algorithm = AlgorithmSlidingWindow
+	window := 200 * time.Millisecond
+	key := "rate_limit:{ip:10.0.0.1}"
+
+	// Act
+	first := incrementCounts(t, storage, key, 5, 4, window)
+	time.Sleep(275 * time.Millisecond)
+	second := incrementCounts(t, storage, key, 3, 4, window)
+
+	// Assert: mesmo resultado do MemoryStorage, pelo script de incremento
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, first)
+	assert.Equal(t, []int{3, 4, 5}, second)
+
+	status, err := storage.Get(context.Background(), key)
+	require.NoError(t, err)
+	require.NotNil(t, status)
+	assert.Equal(t, 2, status.Count)
+	assert.Equal(t, 4, status.Limit)
+}
+
+func TestSlidingWindowCount(t *testing.T) {
+	assert.Equal(t, 10, slidingWindowCount(10, 0, 0, 100))
+	assert.Equal(t, 7, slidingWindowCount(10, 2, 50, 100))
+	assert.Equal(t, 2, slidingWindowCount(10, 0, 75, 100))
+	assert.Equal(t, 2, slidingWindowCount(10, 2, 100, 100))
+}
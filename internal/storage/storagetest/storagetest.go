@@ -0,0 +1,348 @@
+// Package storagetest é a suíte de conformidade de domain.RateLimiterStorage.
+// Novas implementações de storage devem passar por ela:
+//
+//	func TestMyStorage_Conformance(t *testing.T) {
+//		storagetest.Run(t, func(t *testing.T) domain.RateLimiterStorage {
+//			return NewMyStorage(...)
+//		})
+//	}
+package storagetest
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"rate-limiter/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Factory cria um storage vazio e isolado para cada caso da suíte.
+// Recursos do storage devem ser liberados com t.Cleanup
+type Factory func(t *testing.T) domain.RateLimiterStorage
+
+// Durações curtas, mas acima da resolução de milissegundos dos backends
+const (
+	shortWindow = 200 * time.Millisecond
+	shortBlock  = 150 * time.Millisecond
+	margin      = 100 * time.Millisecond
+)
+
+// Run executa a suíte de conformidade contra os storages criados por factory
+func Run(t *testing.T, factory Factory) {
+	tests := []struct {
+		name string
+		run  func(t *testing.T, storage domain.RateLimiterStorage)
+	}{
+		{"GetMissingKey", testGetMissingKey},
+		{"SetGet", testSetGet},
+		{"IncrementCounts", testIncrementCounts},
+		{"IncrementWindowReset", testIncrementWindowReset},
+		{"IncrementIsolatesKeys", testIncrementIsolatesKeys},
+		{"IncrementConcurrent", testIncrementConcurrent},
+		{"BlockAndExpiry", testBlockAndExpiry},
+		{"BlockKeepsCounter", testBlockKeepsCounter},
+		{"Reset", testReset},
+		{"IncrementQuota", testIncrementQuota},
+		{"TakeToken", testTakeToken},
+		{"Leak", testLeak},
+		{"Health", testHealth},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.run(t, factory(t))
+		})
+	}
+}
+
+func testGetMissingKey(t *testing.T, storage domain.RateLimiterStorage) {
+	status, err := storage.Get(context.Background(), "rate_limit:ip:missing")
+
+	require.NoError(t, err)
+	assert.Nil(t, status, "missing keys must return (nil, nil)")
+
+	blocked, blockedUntil, err := storage.IsBlocked(context.Background(), "rate_limit:ip:missing")
+	require.NoError(t, err)
+	assert.False(t, blocked)
+	assert.Nil(t, blockedUntil)
+}
+
+func testSetGet(t *testing.T, storage domain.RateLimiterStorage) {
+	ctx := context.Background()
+	key := "rate_limit:ip:10.0.0.1"
+	status := &domain.RateLimitStatus{
+		Key:       key,
+		Type:      domain.IPLimiter,
+		Count:     3,
+		Limit:     10,
+		Window:    60,
+		LastReset: time.Now(),
+	}
+
+	require.NoError(t, storage.Set(ctx, key, status, time.Minute))
+	loaded, err := storage.Get(ctx, key)
+
+	require.NoError(t, err)
+	require.NotNil(t, loaded)
+	assert.Equal(t, 3, loaded.Count)
+	assert.Equal(t, 10, loaded.Limit)
+	assert.WithinDuration(t, status.LastReset, loaded.LastReset, time.Millisecond)
+}
+
+func testIncrementCounts(t *testing.T, storage domain.RateLimiterStorage) {
+	ctx := context.Background()
+	key := "rate_limit:ip:10.0.0.1"
+
+	var windowStart time.Time
+	for i := 1; i <= 5; i++ {
+		count, start, err := storage.Increment(ctx, key, 3, time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, i, count, "Increment must keep counting past the limit")
+		if i == 1 {
+			windowStart = start
+			assert.WithinDuration(t, time.Now(), start, time.Second)
+		}
+		assert.True(t, windowStart.Equal(start), "window start must not move inside the window")
+	}
+
+	status, err := storage.Get(ctx, key)
+	require.NoError(t, err)
+	require.NotNil(t, status)
+	assert.Equal(t, 5, status.Count)
+}
+
+func testIncrementWindowReset(t *testing.T, storage domain.RateLimiterStorage) {
+	ctx := context.Background()
+	key := "rate_limit:ip:10.0.0.1"
+
+	_, firstStart, err := storage.Increment(ctx, key, 10, shortWindow)
+	require.NoError(t, err)
+	count, _, err := storage.Increment(ctx, key, 10, shortWindow)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+
+	time.Sleep(shortWindow + margin)
+
+	count, start, err := storage.Increment(ctx, key, 10, shortWindow)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count, "the counter must restart after the window")
+	assert.True(t, start.After(firstStart), "a new window must start")
+}
+
+func testIncrementIsolatesKeys(t *testing.T, storage domain.RateLimiterStorage) {
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		_, _, err := storage.Increment(ctx, "rate_limit:ip:10.0.0.1", 10, time.Minute)
+		require.NoError(t, err)
+	}
+	count, _, err := storage.Increment(ctx, "rate_limit:token:10.0.0.1", 10, time.Minute)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func testIncrementConcurrent(t *testing.T, storage domain.RateLimiterStorage) {
+	const (
+		workers    = 20
+		increments = 10
+	)
+	ctx := context.Background()
+	key := "rate_limit:token:concurrent"
+
+	var (
+		wg     sync.WaitGroup
+		mutex  sync.Mutex
+		counts []int
+		errs   []error
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < increments; i++ {
+				count, _, err := storage.Increment(ctx, key, 1000, time.Minute)
+				mutex.Lock()
+				if err != nil {
+					errs = append(errs, err)
+				} else {
+					counts = append(counts, count)
+				}
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	require.Empty(t, errs)
+	// Atomicidade: cada incremento observa um valor distinto, sem perdas
+	sort.Ints(counts)
+	for i, count := range counts {
+		require.Equal(t, i+1, count, "concurrent increments must return every value exactly once")
+	}
+
+	status, err := storage.Get(ctx, key)
+	require.NoError(t, err)
+	require.NotNil(t, status)
+	assert.Equal(t, workers*increments, status.Count)
+}
+
+func testBlockAndExpiry(t *testing.T, storage domain.RateLimiterStorage) {
+	ctx := context.Background()
+	key := "rate_limit:ip:10.0.0.1"
+
+	require.NoError(t, storage.Block(ctx, key, shortBlock))
+	blocked, blockedUntil, err := storage.IsBlocked(ctx, key)
+
+	require.NoError(t, err)
+	assert.True(t, blocked)
+	require.NotNil(t, blockedUntil)
+	assert.WithinDuration(t, time.Now().Add(shortBlock), *blockedUntil, margin)
+
+	time.Sleep(shortBlock + margin)
+
+	blocked, _, err = storage.IsBlocked(ctx, key)
+	require.NoError(t, err)
+	assert.False(t, blocked, "blocks must expire after the duration")
+}
+
+func testBlockKeepsCounter(t *testing.T, storage domain.RateLimiterStorage) {
+	ctx := context.Background()
+	key := "rate_limit:ip:10.0.0.1"
+
+	for i := 0; i < 4; i++ {
+		_, _, err := storage.Increment(ctx, key, 3, time.Minute)
+		require.NoError(t, err)
+	}
+	require.NoError(t, storage.Block(ctx, key, time.Minute))
+
+	status, err := storage.Get(ctx, key)
+	require.NoError(t, err)
+	require.NotNil(t, status)
+	assert.Equal(t, 4, status.Count)
+	assert.True(t, status.IsBlocked)
+}
+
+func testReset(t *testing.T, storage domain.RateLimiterStorage) {
+	ctx := context.Background()
+	key := "rate_limit:ip:10.0.0.1"
+
+	for i := 0; i < 3; i++ {
+		_, _, err := storage.Increment(ctx, key, 2, time.Minute)
+		require.NoError(t, err)
+	}
+	require.NoError(t, storage.Block(ctx, key, time.Minute))
+
+	require.NoError(t, storage.Reset(ctx, key))
+
+	status, err := storage.Get(ctx, key)
+	require.NoError(t, err)
+	assert.Nil(t, status)
+	blocked, _, err := storage.IsBlocked(ctx, key)
+	require.NoError(t, err)
+	assert.False(t, blocked)
+	count, _, err := storage.Increment(ctx, key, 2, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	assert.NoError(t, storage.Reset(ctx, "rate_limit:ip:missing"), "resetting a missing key is not an error")
+}
+
+func testIncrementQuota(t *testing.T, storage domain.RateLimiterStorage) {
+	ctx := context.Background()
+	key := "rate_limit:token:daily_token"
+	resetAt := time.Now().Add(shortWindow)
+
+	var status *domain.RateLimitStatus
+	var err error
+	for i := 1; i <= 3; i++ {
+		status, err = storage.IncrementQuota(ctx, key, domain.QuotaPeriod{Limit: 2, ResetAt: resetAt})
+		require.NoError(t, err)
+		assert.Equal(t, i, status.Count)
+	}
+	assert.True(t, status.IsBlocked)
+	require.NotNil(t, status.ResetAt)
+	assert.WithinDuration(t, resetAt, *status.ResetAt, time.Millisecond)
+
+	time.Sleep(shortWindow + margin)
+
+	nextReset := time.Now().Add(time.Hour)
+	status, err = storage.IncrementQuota(ctx, key, domain.QuotaPeriod{Limit: 2, ResetAt: nextReset})
+	require.NoError(t, err)
+	assert.Equal(t, 1, status.Count, "the quota must restart after its reset")
+	assert.False(t, status.IsBlocked)
+	assert.WithinDuration(t, nextReset, *status.ResetAt, time.Millisecond)
+}
+
+func testTakeToken(t *testing.T, storage domain.RateLimiterStorage) {
+	ctx := context.Background()
+	key := "rate_limit:ip:bucket"
+	// Rajada de 2, uma ficha reposta a cada shortWindow
+	bucket := domain.TokenBucket{Capacity: 2, RefillRate: float64(time.Second) / float64(shortWindow)}
+
+	for i := 1; i <= 2; i++ {
+		status, err := storage.TakeToken(ctx, key, bucket)
+		require.NoError(t, err)
+		assert.False(t, status.IsBlocked)
+		assert.Equal(t, i, status.Count)
+	}
+
+	status, err := storage.TakeToken(ctx, key, bucket)
+	require.NoError(t, err)
+	assert.True(t, status.IsBlocked, "an empty bucket must reject")
+	assert.Equal(t, 3, status.Count)
+	require.NotNil(t, status.BlockedUntil)
+	assert.WithinDuration(t, time.Now().Add(shortWindow), *status.BlockedUntil, shortWindow)
+
+	time.Sleep(shortWindow + margin)
+
+	status, err = storage.TakeToken(ctx, key, bucket)
+	require.NoError(t, err)
+	assert.False(t, status.IsBlocked, "a refilled token must be available")
+	assert.Equal(t, 2, status.Count)
+}
+
+func testLeak(t *testing.T, storage domain.RateLimiterStorage) {
+	ctx := context.Background()
+	key := "rate_limit:ip:leaky"
+	// Fila de 2, uma requisição escoada a cada shortWindow
+	bucket := domain.LeakyBucket{Capacity: 2, LeakRate: float64(time.Second) / float64(shortWindow)}
+
+	first, err := storage.Leak(ctx, key, bucket)
+	require.NoError(t, err)
+	assert.False(t, first.IsBlocked)
+	assert.Equal(t, 1, first.Count)
+	require.NotNil(t, first.ReleaseAt)
+	assert.WithinDuration(t, time.Now(), *first.ReleaseAt, margin, "an empty bucket releases at once")
+
+	second, err := storage.Leak(ctx, key, bucket)
+	require.NoError(t, err)
+	assert.False(t, second.IsBlocked)
+	assert.Equal(t, 2, second.Count)
+	require.NotNil(t, second.ReleaseAt)
+	assert.WithinDuration(t, first.ReleaseAt.Add(shortWindow), *second.ReleaseAt, margin, "queued requests leave at the leak rate")
+
+	status, err := storage.Leak(ctx, key, bucket)
+	require.NoError(t, err)
+	assert.True(t, status.IsBlocked, "a full bucket must overflow")
+	assert.Equal(t, 3, status.Count)
+	assert.Nil(t, status.ReleaseAt)
+	require.NotNil(t, status.BlockedUntil)
+	assert.WithinDuration(t, time.Now().Add(shortWindow), *status.BlockedUntil, margin)
+
+	time.Sleep(shortWindow + margin)
+
+	status, err = storage.Leak(ctx, key, bucket)
+	require.NoError(t, err)
+	assert.False(t, status.IsBlocked, "a drained slot must accept a request")
+	assert.Equal(t, 2, status.Count)
+}
+
+func testHealth(t *testing.T, storage domain.RateLimiterStorage) {
+	assert.NoError(t, storage.Health(context.Background()))
+}
@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"rate-limiter/internal/domain"
+)
+
+const (
+	TieredStorageType StorageType = "tiered"
+
+	// DefaultTieredCacheTTL é o tempo padrão de uma resposta no cache em memória
+	DefaultTieredCacheTTL = 200 * time.Millisecond
+)
+
+// TieredConfig contém configurações específicas do storage em camadas
+type TieredConfig struct {
+	CacheTTL time.Duration // Validade de cada resposta em cache (0 = DefaultTieredCacheTTL)
+}
+
+// TieredStats resume o uso do cache
+type TieredStats struct {
+	Hits   uint64 // IsBlocked respondidos pela memória
+	Misses uint64 // IsBlocked que foram ao Redis
+}
+
+// TieredStorage usa um MemoryStorage como cache de leitura de curta duração na
+// frente do Redis. As respostas de IsBlocked ficam em memória por CacheTTL, e os
+// contadores continuam no Redis, a fonte de verdade. Block, Set e Reset desta
+// instância atualizam o cache na hora; os de outras instâncias são vistos quando
+// a resposta em cache expira
+type TieredStorage struct {
+	*RedisStorage
+	cache *MemoryStorage
+	ttl   time.Duration
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// NewTieredStorage cria o storage em camadas sobre o Redis storage
+func NewTieredStorage(cache *MemoryStorage, remote *RedisStorage, config TieredConfig, logger domain.Logger) *TieredStorage {
+	if config.CacheTTL <= 0 {
+		config.CacheTTL = DefaultTieredCacheTTL
+	}
+
+	if logger != nil {
+		logger.Info("Tiered storage initialized", map[string]interface{}{
+			"cache_ttl_ms": config.CacheTTL.Milliseconds(),
+		})
+	}
+
+	return &TieredStorage{
+		RedisStorage: remote,
+		cache:        cache,
+		ttl:          config.CacheTTL,
+	}
+}
+
+// IsBlocked responde pelo cache enquanto a resposta é válida; senão consulta o
+// Redis e guarda a resposta
+func (t *TieredStorage) IsBlocked(ctx context.Context, key string) (bool, *time.Time, error) {
+	if blocked, blockedUntil, ok := t.cached(ctx, key); ok {
+		t.hits.Add(1)
+		return blocked, blockedUntil, nil
+	}
+	t.misses.Add(1)
+
+	blocked, blockedUntil, err := t.RedisStorage.IsBlocked(ctx, key)
+	if err != nil {
+		return false, nil, err
+	}
+	t.store(ctx, key, blocked, blockedUntil)
+	return blocked, blockedUntil, nil
+}
+
+// Block bloqueia no Redis e guarda o bloqueio no cache
+func (t *TieredStorage) Block(ctx context.Context, key string, duration time.Duration) error {
+	if err := t.RedisStorage.Block(ctx, key, duration); err != nil {
+		return err
+	}
+	blockedUntil := time.Now().Add(duration)
+	t.store(ctx, key, true, &blockedUntil)
+	return nil
+}
+
+// Set grava no Redis e descarta a resposta em cache da chave
+func (t *TieredStorage) Set(ctx context.Context, key string, status *domain.RateLimitStatus, ttl time.Duration) error {
+	if err := t.RedisStorage.Set(ctx, key, status, ttl); err != nil {
+		return err
+	}
+	return t.cache.Reset(ctx, key)
+}
+
+// Reset limpa a chave no Redis e no cache
+func (t *TieredStorage) Reset(ctx context.Context, key string) error {
+	if err := t.RedisStorage.Reset(ctx, key); err != nil {
+		return err
+	}
+	return t.cache.Reset(ctx, key)
+}
+
+// Stats retorna os contadores do cache
+func (t *TieredStorage) Stats() TieredStats {
+	return TieredStats{Hits: t.hits.Load(), Misses: t.misses.Load()}
+}
+
+// Close fecha o cache e o Redis storage
+func (t *TieredStorage) Close() error {
+	t.cache.Close()
+	return t.RedisStorage.Close()
+}
+
+// cached retorna a resposta guardada se ela ainda vale. LastReset marca quando
+// ela foi guardada, e um bloqueio vencido não é servido pelo cache
+func (t *TieredStorage) cached(ctx context.Context, key string) (bool, *time.Time, bool) {
+	status, err := t.cache.Get(ctx, key)
+	if err != nil || status == nil {
+		return false, nil, false
+	}
+
+	now := time.Now()
+	if now.Sub(status.LastReset) >= t.ttl {
+		return false, nil, false
+	}
+	if status.IsBlocked && status.BlockedUntil != nil && !now.Before(*status.BlockedUntil) {
+		return false, nil, false
+	}
+	return status.IsBlocked, status.BlockedUntil, true
+}
+
+// store guarda a resposta de IsBlocked por CacheTTL
+func (t *TieredStorage) store(ctx context.Context, key string, blocked bool, blockedUntil *time.Time) {
+	t.cache.Set(ctx, key, &domain.RateLimitStatus{
+		Key:          key,
+		IsBlocked:    blocked,
+		BlockedUntil: blockedUntil,
+		LastReset:    time.Now(),
+	}, t.ttl)
+}
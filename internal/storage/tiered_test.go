@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/logger"
+	"rate-limiter/internal/storage/storagetest"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTieredStorage cria o storage em camadas sobre um Redis storage próprio, no servidor informado
+func newTieredStorage(t *testing.T, server *miniredis.Miniredis, ttl time.Duration) *TieredStorage {
+	t.Helper()
+
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	remote := NewRedisStorageWithClient(client, logger.NewNopLogger())
+	storage := NewTieredStorage(NewMemoryStorage(logger.NewNopLogger()), remote, TieredConfig{CacheTTL: ttl}, logger.NewNopLogger())
+	t.Cleanup(func() { storage.Close() })
+	return storage
+}
+
+func TestTieredStorage_Conformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) domain.RateLimiterStorage {
+		return newTieredStorage(t, miniredis.RunT(t), 20*time.Millisecond)
+	})
+}
+
+// TestTieredStorage_IsBlockedCache testa as respostas servidas pela memória e a expiração delas
+func TestTieredStorage_IsBlockedCache(t *testing.T) {
+	// Arrange
+	server := miniredis.RunT(t)
+	storage := newTieredStorage(t, server, 50*time.Millisecond)
+	other := newTieredStorage(t, server, 50*time.Millisecond)
+	ctx := context.Background()
+	key := "rate_limit:ip:10.0.0.1"
+
+	first, _, err := storage.IsBlocked(ctx, key)
+	require.NoError(t, err)
+
+	// Act: outra instância bloqueia a chave no Redis
+	require.NoError(t, other.Block(ctx, key, time.Minute))
+	cached, _, err := storage.IsBlocked(ctx, key)
+	require.NoError(t, err)
+	time.Sleep(60 * time.Millisecond)
+	expired, blockedUntil, err := storage.IsBlocked(ctx, key)
+	require.NoError(t, err)
+
+	// Assert
+	assert.False(t, first)
+	assert.False(t, cached, "the cached answer is served until it expires")
+	assert.True(t, expired)
+	assert.NotNil(t, blockedUntil)
+	assert.Equal(t, TieredStats{Hits: 1, Misses: 2}, storage.Stats())
+}
+
+// TestTieredStorage_LocalWrites testa o cache atualizado pelos bloqueios e resets da própria instância
+func TestTieredStorage_LocalWrites(t *testing.T) {
+	// Arrange
+	storage := newTieredStorage(t, miniredis.RunT(t), time.Minute)
+	ctx := context.Background()
+	key := "rate_limit:token:abc123"
+
+	_, _, err := storage.IsBlocked(ctx, key)
+	require.NoError(t, err)
+
+	// Act
+	require.NoError(t, storage.Block(ctx, key, time.Minute))
+	blocked, _, err := storage.IsBlocked(ctx, key)
+	require.NoError(t, err)
+	require.NoError(t, storage.Reset(ctx, key))
+	afterReset, _, err := storage.IsBlocked(ctx, key)
+	require.NoError(t, err)
+
+	// Assert
+	assert.True(t, blocked)
+	assert.False(t, afterReset)
+	assert.Equal(t, TieredStats{Hits: 1, Misses: 2}, storage.Stats())
+}
+
+// TestTieredStorage_ExpiredBlock testa que um bloqueio vencido não é servido pelo cache
+func TestTieredStorage_ExpiredBlock(t *testing.T) {
+	// Arrange
+	storage := newTieredStorage(t, miniredis.RunT(t), time.Minute)
+	ctx := context.Background()
+	key := "rate_limit:ip:10.0.0.2"
+	require.NoError(t, storage.Block(ctx, key, 10*time.Millisecond))
+	time.Sleep(20 * time.Millisecond)
+
+	// Act
+	blocked, _, err := storage.IsBlocked(ctx, key)
+
+	// Assert
+	require.NoError(t, err)
+	assert.False(t, blocked)
+	assert.Equal(t, uint64(1), storage.Stats().Misses)
+}
@@ -0,0 +1,201 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+
+	"rate-limiter/internal/domain"
+)
+
+// tokenBucketState é o balde de uma chave no MemoryStorage
+type tokenBucketState struct {
+	tokens  float64
+	updated int64 // Unix nanossegundos
+}
+
+// refillAndTake repõe as fichas pelo tempo decorrido e consome uma, se houver
+func refillAndTake(tokens float64, elapsed time.Duration, bucket domain.TokenBucket) (float64, bool) {
+	if elapsed > 0 {
+		tokens = math.Min(float64(bucket.Capacity), tokens+elapsed.Seconds()*bucket.RefillRate)
+	}
+	if tokens < 1 {
+		return tokens, false
+	}
+	return tokens - 1, true
+}
+
+// bucketFillTime é o tempo para o balde vazio encher de novo
+func bucketFillTime(bucket domain.TokenBucket) time.Duration {
+	return time.Duration(float64(bucket.Capacity) / bucket.RefillRate * float64(time.Second))
+}
+
+// tokenBucketStatus monta o status retornado pelo TakeToken a partir das fichas restantes
+func tokenBucketStatus(key string, bucket domain.TokenBucket, tokens float64, allowed bool, now time.Time) *domain.RateLimitStatus {
+	perToken := time.Duration(float64(time.Second) / bucket.RefillRate)
+	resetAt := now.Add(time.Duration((float64(bucket.Capacity) - tokens) * float64(perToken)))
+
+	status := &domain.RateLimitStatus{
+		Key:       key,
+		Count:     bucket.Capacity - int(math.Floor(tokens)),
+		Limit:     bucket.Capacity,
+		Window:    int(math.Ceil(bucketFillTime(bucket).Seconds())),
+		LastReset: now,
+		ResetAt:   &resetAt,
+	}
+	if !allowed {
+		nextToken := now.Add(time.Duration((1 - tokens) * float64(perToken)))
+		status.Count = bucket.Capacity + 1
+		status.IsBlocked = true
+		status.BlockedUntil = &nextToken
+	}
+	return status
+}
+
+// validateTokenBucket rejeita baldes que nunca liberariam uma requisição
+func validateTokenBucket(bucket domain.TokenBucket) error {
+	if bucket.Capacity < 1 || bucket.RefillRate <= 0 {
+		return fmt.Errorf("invalid token bucket: capacity %d, refill rate %g", bucket.Capacity, bucket.RefillRate)
+	}
+	return nil
+}
+
+// TakeToken consome uma ficha do balde da chave. O registro de status acompanha
+// o balde para Get, Inspect e a limpeza periódica
+func (m *MemoryStorage) TakeToken(ctx context.Context, key string, bucket domain.TokenBucket) (*domain.RateLimitStatus, error) {
+	ctx, span := startSpan(ctx, MemoryStorageType, "TAKE_TOKEN", key)
+	defer span.End()
+	m.touch(key)
+
+	if err := validateTokenBucket(bucket); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+
+	shard := m.lock(ctx, key)
+	defer m.unlock(shard)
+
+	now := m.now()
+	state, exists := shard.buckets[key]
+	if !exists {
+		state = &tokenBucketState{tokens: float64(bucket.Capacity), updated: now.UnixNano()}
+		shard.buckets[key] = state
+	}
+	tokens, allowed := refillAndTake(state.tokens, time.Duration(now.UnixNano()-state.updated), bucket)
+	state.tokens = tokens
+	state.updated = now.UnixNano()
+
+	status := tokenBucketStatus(key, bucket, tokens, allowed, now)
+
+	record, exists := shard.data.get(key)
+	if !exists {
+		record = m.insert(shard, key, memoryRecord{})
+	}
+	record.limit = clampInt32(bucket.Capacity)
+	record.window = clampInt32(status.Window)
+	record.lastReset = now.UnixNano()
+	record.count = clampInt32(bucket.Capacity - int(math.Floor(tokens)))
+
+	m.logStorageOperation(ctx, "TAKE_TOKEN", key, true, time.Since(start).Seconds()*1000, nil)
+	return status, nil
+}
+
+// takeTokenSource repõe e consome as fichas do balde gravado no status JSON da
+// chave (campos tokens e updatedAt). Retorna {permitida (0/1), fichas restantes}
+const takeTokenSource = `
+	local key = KEYS[1]
+	local capacity = tonumber(ARGV[1])
+	local rate = tonumber(ARGV[2]) -- fichas por segundo
+	local now = tonumber(ARGV[3])
+
+	local current = redis.call('GET', key)
+	local data = {}
+	if current then
+		data = cjson.decode(current)
+	else
+		data = { key = key, type = '' }
+	end
+
+	-- Reposição pelo tempo decorrido desde a última requisição
+	local tokens = tonumber(data.tokens) or capacity
+	local updated = tonumber(data.updatedAt) or now
+	if now > updated then
+		tokens = math.min(capacity, tokens + (now - updated) * rate / 1000)
+	end
+
+	local allowed = 0
+	if tokens >= 1 then
+		tokens = tokens - 1
+		allowed = 1
+	end
+
+	-- Tempo até o balde encher de novo (ms); depois disso a chave pode expirar
+	local fill = math.ceil(capacity * 1000 / rate)
+
+	local blocked = type(data.blockedUntil) == 'number' and data.blockedUntil > now
+	if not blocked then
+		data.blockedUntil = nil
+	end
+	data.tokens = tokens
+	data.updatedAt = now
+	data.count = capacity - math.floor(tokens)
+	data.limit = capacity
+	data.window = math.ceil(fill / 1000)
+	data.lastReset = now
+	data.isBlocked = blocked
+
+	-- Preserva o TTL de um bloqueio mais longo que a reposição
+	local ttl = redis.call('PTTL', key)
+	if ttl < fill then
+		ttl = fill
+	end
+	redis.call('SET', key, cjson.encode(data), 'PX', ttl)
+
+	-- Frações viram inteiros no retorno do Lua: as fichas vão como string
+	return {allowed, tostring(tokens)}
+`
+
+var takeTokenScript = redis.NewScript(takeTokenSource)
+
+// TakeToken consome atomicamente uma ficha do balde da chave no Redis
+func (r *RedisStorage) TakeToken(ctx context.Context, key string, bucket domain.TokenBucket) (*domain.RateLimitStatus, error) {
+	ctx, span := startSpan(ctx, RedisStorageType, "TAKE_TOKEN", key)
+	defer span.End()
+
+	if err := validateTokenBucket(bucket); err != nil {
+		return nil, err
+	}
+	if r.cipher != nil {
+		return nil, fmt.Errorf("failed to take token for key %s: %w", key, ErrSealedUnsupported)
+	}
+
+	start := time.Now()
+	now := time.Now()
+
+	result, err := r.eval(ctx, "take_token", takeTokenScript, []string{key}, bucket.Capacity, bucket.RefillRate, now.UnixMilli())
+	if err != nil {
+		r.logStorageOperation(ctx, "TAKE_TOKEN", key, false, time.Since(start).Seconds()*1000, err)
+		return nil, fmt.Errorf("failed to take token for key %s: %w", key, err)
+	}
+
+	values, ok := result.([]interface{})
+	if !ok || len(values) < 2 {
+		err := fmt.Errorf("invalid token bucket result for key %s", key)
+		r.logStorageOperation(ctx, "TAKE_TOKEN", key, false, time.Since(start).Seconds()*1000, err)
+		return nil, err
+	}
+	tokens, err := strconv.ParseFloat(fmt.Sprint(values[1]), 64)
+	if err != nil {
+		r.logStorageOperation(ctx, "TAKE_TOKEN", key, false, time.Since(start).Seconds()*1000, err)
+		return nil, fmt.Errorf("invalid tokens in result for key %s: %w", key, err)
+	}
+	allowed := fmt.Sprint(values[0]) == "1"
+
+	r.logStorageOperation(ctx, "TAKE_TOKEN", key, true, time.Since(start).Seconds()*1000, nil)
+	return tokenBucketStatus(key, bucket, tokens, allowed, time.UnixMilli(now.UnixMilli())), nil
+}
@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracerName identifica os spans emitidos pelos storages
+const TracerName = "rate-limiter/internal/storage"
+
+// startSpan abre o span filho de uma operação de storage
+// A chave não é registrada por conter tokens; apenas o tipo de limiter é anexado
+// Os atributos só são montados para spans gravados: sem exporter, o span não
+// custa alocações além das do próprio OpenTelemetry. Ao encerrar, o span também
+// registra a duração da operação (rate_limiter.storage.duration)
+func startSpan(ctx context.Context, backend StorageType, operation, key string) (context.Context, storageSpan) {
+	kind := spanKindInternal
+	if backend == RedisStorageType || backend == EtcdStorageType {
+		kind = spanKindClient
+	}
+
+	ctx, span := otel.Tracer(TracerName).Start(ctx, spanName(backend, operation), kind...)
+	if span.IsRecording() {
+		span.SetAttributes(
+			attribute.String("db.system", string(backend)),
+			attribute.String("db.operation", operation),
+		)
+		if key != "" {
+			span.SetAttributes(attribute.String("rate_limit.key_type", keyType(key)))
+		}
+	}
+	return ctx, storageSpan{Span: span, ctx: ctx, backend: backend, operation: operation, start: time.Now()}
+}
+
+// Opções de tipo de span, montadas uma única vez
+var (
+	spanKindInternal = []trace.SpanStartOption{trace.WithSpanKind(trace.SpanKindInternal)}
+	spanKindClient   = []trace.SpanStartOption{trace.WithSpanKind(trace.SpanKindClient)}
+)
+
+// spanNames guarda os nomes "<backend>.<operação>" já montados
+var (
+	spanNamesMutex sync.RWMutex
+	spanNames      = make(map[StorageType]map[string]string)
+)
+
+// spanName retorna o nome do span da operação sem concatenar a cada chamada
+func spanName(backend StorageType, operation string) string {
+	spanNamesMutex.RLock()
+	name, ok := spanNames[backend][operation]
+	spanNamesMutex.RUnlock()
+	if ok {
+		return name
+	}
+
+	spanNamesMutex.Lock()
+	defer spanNamesMutex.Unlock()
+	if spanNames[backend] == nil {
+		spanNames[backend] = make(map[string]string)
+	}
+	name = string(backend) + "." + operation
+	spanNames[backend][operation] = name
+	return name
+}
+
+// recordSpanError marca o span da operação em ctx como falho
+func recordSpanError(ctx context.Context, err error) {
+	if err == nil {
+		return
+	}
+	span := trace.SpanFromContext(ctx)
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// recordLockWaitEvent anexa ao span o tempo de espera pelo lock do MemoryStorage
+func recordLockWaitEvent(ctx context.Context, mode string, wait time.Duration) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+	span.AddEvent("lock.acquired", trace.WithAttributes(
+		attribute.String("lock.mode", mode),
+		attribute.Int64("lock.wait_us", wait.Microseconds()),
+	))
+}
+
+// keyType extrai o tipo de limiter da chave ("rate_limit:<tipo>:<id>", possivelmente prefixada)
+func keyType(key string) string {
+	for rest := key; ; {
+		segment, after, found := strings.Cut(rest, ":")
+		if !found {
+			return "unknown"
+		}
+		if segment == "rate_limit" {
+			kind, _, _ := strings.Cut(after, ":")
+			return kind
+		}
+		rest = after
+	}
+}
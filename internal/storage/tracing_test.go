@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// useSpanRecorder instala um tracer provider que grava os spans durante o teste
+func useSpanRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestMemoryStorage_Spans(t *testing.T) {
+	recorder := useSpanRecorder(t)
+	memory := NewMemoryStorage(nil)
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "request")
+	_, _, err := memory.Increment(ctx, "rate_limit:token:secret-token", 10, time.Minute)
+	require.NoError(t, err)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	increment := spans[0]
+	assert.Equal(t, "memory.INCREMENT", increment.Name())
+	assert.Equal(t, parent.SpanContext().SpanID(), increment.Parent().SpanID())
+	assert.Contains(t, increment.Attributes(), attribute.String("rate_limit.key_type", "token"))
+	for _, kv := range increment.Attributes() {
+		assert.NotContains(t, kv.Value.Emit(), "secret-token")
+	}
+
+	require.NotEmpty(t, increment.Events())
+	assert.Equal(t, "lock.acquired", increment.Events()[0].Name)
+}
+
+func TestMemoryStorage_NestedSpans(t *testing.T) {
+	recorder := useSpanRecorder(t)
+	memory := NewMemoryStorage(nil)
+
+	require.NoError(t, memory.Block(context.Background(), "rate_limit:ip:10.0.0.1", time.Minute))
+	blocked, _, err := memory.IsBlocked(context.Background(), "rate_limit:ip:10.0.0.1")
+	require.NoError(t, err)
+	assert.True(t, blocked)
+
+	var names []string
+	for _, span := range recorder.Ended() {
+		names = append(names, span.Name())
+	}
+	assert.Equal(t, []string{"memory.BLOCK", "memory.IS_BLOCKED"}, names)
+}
+
+func TestRecordSpanError(t *testing.T) {
+	recorder := useSpanRecorder(t)
+
+	ctx, span := startSpan(context.Background(), RedisStorageType, "GET", "rate_limit:ip:10.0.0.1")
+	recordSpanError(ctx, errors.New("connection refused"))
+	span.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "connection refused", spans[0].Status().Description)
+	require.Len(t, spans[0].Events(), 1)
+	assert.Equal(t, "exception", spans[0].Events()[0].Name)
+}
+
+func TestKeyType(t *testing.T) {
+	assert.Equal(t, "ip", keyType("rate_limit:ip:10.0.0.1"))
+	assert.Equal(t, "token", keyType("tenant-a:rate_limit:token:abc"))
+	assert.Equal(t, "unknown", keyType("other"))
+}
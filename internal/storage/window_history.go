@@ -0,0 +1,185 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"rate-limiter/internal/domain"
+)
+
+// windowHistorySuffix identifica a lista com o histórico de janelas da chave no
+// Redis. Cada item é "início:contagem:limite" e o primeiro é a janela atual
+const windowHistorySuffix = ":history"
+
+// memoryWindowHistory são as janelas já fechadas de uma chave, da mais recente à mais antiga
+type memoryWindowHistory struct {
+	windows []domain.WindowCount
+	window  time.Duration
+}
+
+// SetWindowHistory guarda as contagens das últimas size janelas de cada chave
+// (0 = desabilitado). Deve ser chamado antes do primeiro uso
+func (m *MemoryStorage) SetWindowHistory(size int) {
+	m.historySize = size
+}
+
+// recordWindow guarda a contagem da janela que está fechando. Chamado sob o
+// write lock da partição, antes de o registro trocar de janela ou ser removido
+func (m *MemoryStorage) recordWindow(shard *memoryShard, key string, record *memoryRecord, window time.Duration) {
+	if m.historySize <= 0 || record.resetAt != 0 || window <= 0 || !shard.countsWindows(key) {
+		return
+	}
+	count := int(record.count)
+	if count == 0 {
+		return
+	}
+
+	history, exists := shard.history[key]
+	if !exists {
+		history = &memoryWindowHistory{}
+		shard.history[key] = history
+	}
+	history.window = window
+
+	closed := domain.WindowCount{Start: fromUnixNano(record.lastReset), Count: count, Limit: int(record.limit)}
+	history.windows = append([]domain.WindowCount{closed}, history.windows...)
+	if len(history.windows) > m.historySize {
+		history.windows = history.windows[:m.historySize]
+	}
+}
+
+// countsWindows informa se a chave é contada em janelas (e não em baldes ou no log)
+func (s *memoryShard) countsWindows(key string) bool {
+	if _, exists := s.buckets[key]; exists {
+		return false
+	}
+	if _, exists := s.leaks[key]; exists {
+		return false
+	}
+	_, exists := s.logs[key]
+	return !exists
+}
+
+// cleanupWindowHistory remove históricos cuja janela mais recente já saiu do
+// alcance do histórico. Chamado sob o write lock da partição
+func (m *MemoryStorage) cleanupWindowHistory(shard *memoryShard, now int64) {
+	for key, history := range shard.history {
+		if len(history.windows) == 0 {
+			delete(shard.history, key)
+			continue
+		}
+		newest := unixNano(history.windows[0].Start)
+		if time.Duration(now-newest) > history.window*time.Duration(m.historySize+1) {
+			delete(shard.history, key)
+		}
+	}
+}
+
+// WindowHistory implementa domain.WindowHistoryReader: a janela atual seguida
+// das janelas já fechadas guardadas
+func (m *MemoryStorage) WindowHistory(ctx context.Context, key string) ([]domain.WindowCount, error) {
+	ctx, span := startSpan(ctx, MemoryStorageType, "WINDOW_HISTORY", key)
+	defer span.End()
+
+	if m.historySize <= 0 {
+		return nil, domain.ErrHistoryUnsupported
+	}
+
+	shard := m.rlock(ctx, key)
+	defer shard.mutex.RUnlock()
+
+	windows := make([]domain.WindowCount, 0, m.historySize)
+	if record, exists := shard.data.get(key); exists && record.resetAt == 0 && shard.countsWindows(key) {
+		if count := int(record.loadCount()); count > 0 {
+			windows = append(windows, domain.WindowCount{Start: fromUnixNano(record.lastReset), Count: count, Limit: int(record.limit)})
+		}
+	}
+	if history, exists := shard.history[key]; exists {
+		windows = append(windows, history.windows...)
+	}
+	if len(windows) > m.historySize {
+		windows = windows[:m.historySize]
+	}
+	return windows, nil
+}
+
+// SetWindowHistory guarda as contagens das últimas size janelas de cada chave,
+// atualizadas pelo script de incremento (0 = desabilitado). Deve ser chamado
+// antes do primeiro uso
+func (r *RedisStorage) SetWindowHistory(size int) {
+	r.historySize = size
+}
+
+// WindowHistory implementa domain.WindowHistoryReader com a lista mantida pelo
+// script de incremento
+func (r *RedisStorage) WindowHistory(ctx context.Context, key string) ([]domain.WindowCount, error) {
+	ctx, span := startSpan(ctx, RedisStorageType, "WINDOW_HISTORY", key)
+	defer span.End()
+
+	if r.historySize <= 0 {
+		return nil, domain.ErrHistoryUnsupported
+	}
+
+	start := time.Now()
+
+	items, err := r.getClient().LRange(ctx, key+windowHistorySuffix, 0, int64(r.historySize-1)).Result()
+	if err != nil {
+		r.logStorageOperation(ctx, "WINDOW_HISTORY", key, false, time.Since(start).Seconds()*1000, err)
+		return nil, fmt.Errorf("failed to read window history for key %s: %w", key, err)
+	}
+
+	windows := make([]domain.WindowCount, 0, len(items))
+	for _, item := range items {
+		window, err := parseWindowCount(item)
+		if err != nil {
+			r.logStorageOperation(ctx, "WINDOW_HISTORY", key, false, time.Since(start).Seconds()*1000, err)
+			return nil, fmt.Errorf("invalid window history for key %s: %w", key, err)
+		}
+		windows = append(windows, window)
+	}
+
+	r.logStorageOperation(ctx, "WINDOW_HISTORY", key, true, time.Since(start).Seconds()*1000, nil)
+	return windows, nil
+}
+
+// parseWindowCount interpreta um item "início:contagem:limite" gravado pelo script
+func parseWindowCount(item string) (domain.WindowCount, error) {
+	parts := strings.Split(item, ":")
+	if len(parts) != 3 {
+		return domain.WindowCount{}, fmt.Errorf("malformed entry %q", item)
+	}
+
+	values := make([]int64, len(parts))
+	for i, part := range parts {
+		value, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return domain.WindowCount{}, fmt.Errorf("malformed entry %q: %w", item, err)
+		}
+		values[i] = value
+	}
+	return domain.WindowCount{Start: time.UnixMilli(values[0]), Count: int(values[1]), Limit: int(values[2])}, nil
+}
+
+// SetWindowHistory guarda o histórico de janelas no storage local, que conta as
+// janelas entre as sincronizações
+func (h *HybridStorage) SetWindowHistory(size int) {
+	h.local.SetWindowHistory(size)
+}
+
+// WindowHistory implementa domain.WindowHistoryReader com as janelas locais,
+// que passam a ter o total global a cada sincronização
+func (h *HybridStorage) WindowHistory(ctx context.Context, key string) ([]domain.WindowCount, error) {
+	return h.local.WindowHistory(ctx, key)
+}
+
+// WindowHistory implementa domain.WindowHistoryReader quando o storage interno o suporta
+func (p *PrefixedStorage) WindowHistory(ctx context.Context, key string) ([]domain.WindowCount, error) {
+	reader, ok := p.inner.(domain.WindowHistoryReader)
+	if !ok {
+		return nil, domain.ErrHistoryUnsupported
+	}
+	return reader.WindowHistory(ctx, p.prefix+key)
+}
@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"rate-limiter/internal/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// windowCounts extrai contagem e limite de cada janela do histórico
+func windowCounts(windows []domain.WindowCount) [][2]int {
+	counts := make([][2]int, 0, len(windows))
+	for _, window := range windows {
+		counts = append(counts, [2]int{window.Count, window.Limit})
+	}
+	return counts
+}
+
+func TestMemoryStorage_WindowHistory(t *testing.T) {
+	// Arrange: histórico de 2 janelas de 100ms
+	storage := NewMemoryStorage(nil)
+	storage.SetWindowHistory(2)
+	ctx := context.Background()
+	key := "rate_limit:ip:10.0.0.1"
+	window := 100 * time.Millisecond
+
+	// Act: três janelas com 5, 2 e 1 requisições
+	incrementCounts(t, storage, key, 5, 4, window)
+	time.Sleep(120 * time.Millisecond)
+	incrementCounts(t, storage, key, 2, 4, window)
+	time.Sleep(120 * time.Millisecond)
+	incrementCounts(t, storage, key, 1, 4, window)
+	history, err := storage.WindowHistory(ctx, key)
+
+	// Assert: a janela atual primeiro, limitado ao tamanho configurado
+	require.NoError(t, err)
+	assert.Equal(t, [][2]int{{1, 4}, {2, 4}}, windowCounts(history))
+	assert.True(t, history[0].Start.After(history[1].Start))
+
+	require.NoError(t, storage.Reset(ctx, key))
+	history, err = storage.WindowHistory(ctx, key)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+}
+
+func TestMemoryStorage_WindowHistory_Disabled(t *testing.T) {
+	// Arrange
+	storage := NewMemoryStorage(nil)
+
+	// Act
+	_, err := storage.WindowHistory(context.Background(), "rate_limit:ip:10.0.0.1")
+
+	// Assert
+	assert.ErrorIs(t, err, domain.ErrHistoryUnsupported)
+}
+
+func TestRedisStorage_WindowHistory(t *testing.T) {
+	// Arrange
+	storage, server := newMiniredisStorage(t)
+	storage.SetWindowHistory(2)
+	ctx := context.Background()
+	key := "rate_limit:{ip:10.0.0.1}"
+	window := 100 * time.Millisecond
+
+	// Act
+	incrementCounts(t, storage, key, 5, 4, window)
+	time.Sleep(120 * time.Millisecond)
+	incrementCounts(t, storage, key, 2, 4, window)
+	time.Sleep(120 * time.Millisecond)
+	incrementCounts(t, storage, key, 1, 4, window)
+	history, err := storage.WindowHistory(ctx, key)
+
+	// Assert: mesmo resultado do MemoryStorage, pelo script de incremento
+	require.NoError(t, err)
+	assert.Equal(t, [][2]int{{1, 4}, {2, 4}}, windowCounts(history))
+	assert.True(t, history[0].Start.After(history[1].Start))
+	assert.Greater(t, server.TTL(key+windowHistorySuffix), time.Duration(0))
+
+	require.NoError(t, storage.Reset(ctx, key))
+	assert.False(t, server.Exists(key+windowHistorySuffix))
+}
@@ -0,0 +1,62 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+// MetricsConfig contém a configuração do exporter de métricas
+type MetricsConfig struct {
+	// Endpoint é a URL completa do coletor (ex: http://otel-collector:4318/v1/metrics).
+	// Headers, timeout e compressão seguem as variáveis OTEL_EXPORTER_OTLP_* padrão
+	Endpoint string
+
+	ServiceName    string
+	ServiceVersion string
+
+	// Interval é o intervalo entre exportações (0 = padrão do SDK, 1 minuto)
+	Interval time.Duration
+}
+
+// SetupMetrics registra o MeterProvider global. As medições são agregadas em
+// memória e exportadas periodicamente, fora do caminho da requisição
+func SetupMetrics(ctx context.Context, config MetricsConfig) (Shutdown, error) {
+	endpoint, err := parseEndpoint(config.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	options := []otlpmetrichttp.Option{otlpmetrichttp.WithEndpoint(endpoint.Host)}
+	if endpoint.Path != "" {
+		options = append(options, otlpmetrichttp.WithURLPath(endpoint.Path))
+	}
+	if endpoint.Scheme == "http" {
+		options = append(options, otlpmetrichttp.WithInsecure())
+	}
+	exporter, err := otlpmetrichttp.New(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP metric exporter: %w", err)
+	}
+
+	res, err := newResource(ctx, config.ServiceName, config.ServiceVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	var readerOptions []sdkmetric.PeriodicReaderOption
+	if config.Interval > 0 {
+		readerOptions = append(readerOptions, sdkmetric.WithInterval(config.Interval))
+	}
+	provider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter, readerOptions...)),
+		sdkmetric.WithResource(res),
+	)
+	otel.SetMeterProvider(provider)
+
+	return provider.Shutdown, nil
+}
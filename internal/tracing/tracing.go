@@ -0,0 +1,97 @@
+// Package tracing configura o OpenTelemetry da aplicação: o TracerProvider global
+// que exporta por OTLP/HTTP os spans do middleware, do service e dos storages, a
+// propagação W3C do contexto de trace entre serviços e o MeterProvider global das
+// métricas do limiter.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+// Config contém a configuração do exporter
+type Config struct {
+	// Endpoint é a URL completa do coletor (ex: http://otel-collector:4318/v1/traces).
+	// Headers, timeout e compressão seguem as variáveis OTEL_EXPORTER_OTLP_* padrão
+	Endpoint string
+
+	ServiceName    string
+	ServiceVersion string
+
+	// SampleRatio é a fração dos traces iniciados aqui que são gravados; traces
+	// recebidos de outro serviço seguem a decisão de quem os iniciou
+	SampleRatio float64
+}
+
+// Shutdown envia os dados pendentes e encerra o exporter
+type Shutdown func(ctx context.Context) error
+
+// Setup registra o TracerProvider e o propagator globais. Os spans são
+// exportados em lote, fora do caminho da requisição
+func Setup(ctx context.Context, config Config) (Shutdown, error) {
+	endpoint, err := parseEndpoint(config.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(endpoint.Host)}
+	if endpoint.Path != "" {
+		options = append(options, otlptracehttp.WithURLPath(endpoint.Path))
+	}
+	if endpoint.Scheme == "http" {
+		options = append(options, otlptracehttp.WithInsecure())
+	}
+	exporter, err := otlptracehttp.New(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	res, err := newResource(ctx, config.ServiceName, config.ServiceVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(config.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider.Shutdown, nil
+}
+
+// parseEndpoint valida a URL completa do coletor
+func parseEndpoint(value string) (*url.URL, error) {
+	endpoint, err := url.Parse(value)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid OTLP endpoint: %s", value)
+	}
+	return endpoint, nil
+}
+
+// newResource descreve o serviço nos dados exportados. OTEL_RESOURCE_ATTRIBUTES
+// complementa o recurso; nome e versão vêm da configuração
+func newResource(ctx context.Context, serviceName, serviceVersion string) (*resource.Resource, error) {
+	res, err := resource.New(ctx,
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(
+			semconv.ServiceName(serviceName),
+			semconv.ServiceVersion(serviceVersion),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OpenTelemetry resource: %w", err)
+	}
+	return res, nil
+}
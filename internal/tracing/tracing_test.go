@@ -0,0 +1,107 @@
+package tracing
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+)
+
+// restoreGlobals devolve os providers e o propagator globais ao fim do teste
+func restoreGlobals(t *testing.T) {
+	provider, meterProvider, propagator := otel.GetTracerProvider(), otel.GetMeterProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(provider)
+		otel.SetMeterProvider(meterProvider)
+		otel.SetTextMapPropagator(propagator)
+	})
+}
+
+// newCollector sobe um coletor OTLP/HTTP falso que entrega a primeira requisição recebida
+func newCollector(t *testing.T) (*httptest.Server, chan *http.Request) {
+	received := make(chan *http.Request, 1)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		select {
+		case received <- r:
+		default:
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(collector.Close)
+	return collector, received
+}
+
+func TestSetup_ExportsSpans(t *testing.T) {
+	// Arrange: coletor OTLP/HTTP falso
+	restoreGlobals(t)
+	collector, received := newCollector(t)
+
+	shutdown, err := Setup(context.Background(), Config{
+		Endpoint:       collector.URL + "/v1/traces",
+		ServiceName:    "rate-limiter",
+		ServiceVersion: "1.0.0",
+		SampleRatio:    1,
+	})
+	require.NoError(t, err)
+
+	// Act: o shutdown envia o lote pendente
+	_, span := otel.Tracer("test").Start(context.Background(), "request")
+	span.End()
+	require.NoError(t, shutdown(context.Background()))
+
+	// Assert
+	select {
+	case request := <-received:
+		assert.Equal(t, http.MethodPost, request.Method)
+		assert.Equal(t, "/v1/traces", request.URL.Path)
+		assert.Equal(t, "application/x-protobuf", request.Header.Get("Content-Type"))
+	default:
+		t.Fatal("no spans exported")
+	}
+	assert.Contains(t, otel.GetTextMapPropagator().Fields(), "traceparent")
+}
+
+func TestSetupMetrics_ExportsMetrics(t *testing.T) {
+	// Arrange
+	restoreGlobals(t)
+	collector, received := newCollector(t)
+
+	shutdown, err := SetupMetrics(context.Background(), MetricsConfig{
+		Endpoint:       collector.URL + "/v1/metrics",
+		ServiceName:    "rate-limiter",
+		ServiceVersion: "1.0.0",
+		Interval:       time.Hour,
+	})
+	require.NoError(t, err)
+
+	// Act: o shutdown exporta as medições antes do intervalo
+	counter, err := otel.Meter("test").Int64Counter("requests")
+	require.NoError(t, err)
+	counter.Add(context.Background(), 1)
+	require.NoError(t, shutdown(context.Background()))
+
+	// Assert
+	select {
+	case request := <-received:
+		assert.Equal(t, http.MethodPost, request.Method)
+		assert.Equal(t, "/v1/metrics", request.URL.Path)
+		assert.Equal(t, "application/x-protobuf", request.Header.Get("Content-Type"))
+	default:
+		t.Fatal("no metrics exported")
+	}
+}
+
+func TestSetup_InvalidEndpoint(t *testing.T) {
+	restoreGlobals(t)
+
+	_, err := Setup(context.Background(), Config{Endpoint: "otel-collector", SampleRatio: 1})
+
+	assert.ErrorContains(t, err, "invalid OTLP endpoint")
+}
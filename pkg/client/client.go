@@ -0,0 +1,131 @@
+// Package client é o SDK Go para serviços protegidos pelo rate limiter
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TokenHeader é o header em que o token de API é enviado
+const TokenHeader = "API_KEY"
+
+// Decision é a decisão de rate limit de uma requisição
+type Decision struct {
+	Allowed     bool
+	Limit       int
+	Remaining   int
+	Reset       time.Time
+	LimiterType string // "ip" ou "token"
+}
+
+// Client consulta o endpoint /check do rate limiter
+type Client struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// Option configura o cliente
+type Option func(*Client)
+
+// WithToken envia o token de API em cada requisição
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithHTTPClient substitui o http.Client padrão (timeouts, transporte)
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// New cria um cliente para o rate limiter em baseURL (ex: "http://localhost:8080")
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Check consome uma requisição da cota e retorna a decisão.
+// Quando o limite foi excedido, retorna *RateLimitedError
+func (c *Client) Check(ctx context.Context) (*Decision, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/check", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build check request: %w", err)
+	}
+	if c.token != "" {
+		req.Header.Set(TokenHeader, c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call rate limiter: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := CheckResponse(resp); err != nil {
+		return nil, err
+	}
+	return DecisionFromHeaders(resp.Header), nil
+}
+
+// CheckResponse converte respostas de erro do rate limiter em erros tipados.
+// Serve para qualquer resposta de um serviço protegido, não apenas de /check:
+// 429 vira *RateLimitedError, outros status >= 400 viram *APIError
+func CheckResponse(resp *http.Response) error {
+	if resp.StatusCode < http.StatusBadRequest {
+		return nil
+	}
+
+	var body errorBody
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	_ = json.Unmarshal(data, &body)
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return newRateLimitedError(resp.Header, body)
+	}
+	return &APIError{StatusCode: resp.StatusCode, Code: body.Error, Message: body.Message}
+}
+
+// DecisionFromHeaders lê a decisão dos headers X-RateLimit-*.
+// Retorna nil quando a resposta não passou pelo rate limiter (ex: allowlist)
+func DecisionFromHeaders(header http.Header) *Decision {
+	if header.Get("X-RateLimit-Limit") == "" {
+		return nil
+	}
+
+	return &Decision{
+		Allowed:     true,
+		Limit:       headerInt(header, "X-RateLimit-Limit"),
+		Remaining:   headerInt(header, "X-RateLimit-Remaining"),
+		Reset:       headerUnix(header, "X-RateLimit-Reset"),
+		LimiterType: header.Get("X-RateLimit-Type"),
+	}
+}
+
+func headerInt(header http.Header, name string) int {
+	value, _ := strconv.Atoi(header.Get(name))
+	return value
+}
+
+func headerUnix(header http.Header, name string) time.Time {
+	value, err := strconv.ParseInt(header.Get(name), 10, 64)
+	if err != nil || value <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(value, 0)
+}
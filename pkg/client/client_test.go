@@ -0,0 +1,88 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Check(t *testing.T) {
+	// Arrange
+	reset := time.Now().Add(time.Minute).Truncate(time.Second)
+	blockedUntil := time.Now().Add(3 * time.Minute).Truncate(time.Second)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/check", r.URL.Path)
+		assert.Equal(t, "premium_token", r.Header.Get(TokenHeader))
+		requests++
+
+		w.Header().Set("X-RateLimit-Limit", "1")
+		w.Header().Set("X-RateLimit-Reset", fmt.Sprint(reset.Unix()))
+		w.Header().Set("X-RateLimit-Type", "token")
+		if requests == 1 {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			fmt.Fprint(w, `{"allowed":true}`)
+			return
+		}
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("Retry-After", "180")
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprintf(w, `{"error":"rate_limit_exceeded","message":"Upgrade your plan","request_id":"req-1","docs_url":"https://example.com/pricing","details":{"limit":1,"remaining":0,"reset_time":%d,"limiter_type":"token","blocked_until":%d}}`,
+			reset.Unix(), blockedUntil.Unix())
+	}))
+	defer server.Close()
+	client := New(server.URL+"/", WithToken("premium_token"))
+
+	// Act
+	decision, err := client.Check(context.Background())
+	require.NoError(t, err)
+	_, err = client.Check(context.Background())
+
+	// Assert
+	assert.Equal(t, &Decision{Allowed: true, Limit: 1, Remaining: 0, Reset: reset, LimiterType: "token"}, decision)
+
+	limited, ok := IsRateLimited(fmt.Errorf("calling api: %w", err))
+	require.True(t, ok)
+	assert.False(t, limited.Allowed)
+	assert.Equal(t, 1, limited.Limit)
+	assert.Equal(t, reset, limited.Reset)
+	assert.Equal(t, blockedUntil, *limited.BlockedUntil)
+	assert.Equal(t, 180*time.Second, limited.RetryAfter)
+	assert.Equal(t, "Upgrade your plan", limited.Message)
+	assert.Equal(t, "https://example.com/pricing", limited.DocsURL)
+	assert.Equal(t, "req-1", limited.RequestID)
+	assert.Equal(t, blockedUntil, limited.RetryAt(time.Now()))
+}
+
+func TestCheckResponse(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	// HEAD: sem corpo, apenas headers
+	resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}, Body: http.NoBody}
+	resp.Header.Set("X-RateLimit-Limit", "10")
+	resp.Header.Set("X-RateLimit-Remaining", "0")
+	resp.Header.Set("Retry-After", "30")
+	limited, ok := IsRateLimited(CheckResponse(resp))
+	require.True(t, ok)
+	assert.Equal(t, 10, limited.Limit)
+	assert.Nil(t, limited.BlockedUntil)
+	assert.Equal(t, now.Add(30*time.Second), limited.RetryAt(now))
+
+	// Outros erros não são de rate limit
+	resp = &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}, Body: http.NoBody}
+	err := CheckResponse(resp)
+	var apiErr *APIError
+	require.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+	_, ok = IsRateLimited(err)
+	assert.False(t, ok)
+
+	assert.NoError(t, CheckResponse(&http.Response{StatusCode: http.StatusOK}))
+}
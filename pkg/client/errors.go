@@ -0,0 +1,117 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// errorBody é o corpo JSON das respostas de erro do rate limiter
+type errorBody struct {
+	Error     string `json:"error"`
+	Message   string `json:"message"`
+	RequestID string `json:"request_id"`
+	DocsURL   string `json:"docs_url"`
+	Details   struct {
+		Limit        int    `json:"limit"`
+		Remaining    int    `json:"remaining"`
+		ResetTime    int64  `json:"reset_time"`
+		BlockedUntil int64  `json:"blocked_until"`
+		LimiterType  string `json:"limiter_type"`
+	} `json:"details"`
+}
+
+// RateLimitedError é retornado quando o rate limiter responde 429
+type RateLimitedError struct {
+	Decision
+	BlockedUntil *time.Time    // Fim do bloqueio, quando a chave foi bloqueada
+	RetryAfter   time.Duration // Header Retry-After (zero se ausente)
+	Message      string
+	DocsURL      string
+	RequestID    string // Cite ao contestar um bloqueio
+}
+
+// Error implementa a interface error
+func (e *RateLimitedError) Error() string {
+	if e.BlockedUntil != nil {
+		return fmt.Sprintf("rate limit exceeded: blocked until %s", e.BlockedUntil.UTC().Format(time.RFC3339))
+	}
+	return fmt.Sprintf("rate limit exceeded: %d/%d remaining, resets at %s", e.Remaining, e.Limit, e.Reset.UTC().Format(time.RFC3339))
+}
+
+// RetryAt retorna quando vale tentar de novo: fim do bloqueio, Retry-After ou reset da janela
+func (e *RateLimitedError) RetryAt(now time.Time) time.Time {
+	if e.BlockedUntil != nil {
+		return *e.BlockedUntil
+	}
+	if e.RetryAfter > 0 {
+		return now.Add(e.RetryAfter)
+	}
+	if !e.Reset.IsZero() {
+		return e.Reset
+	}
+	return now
+}
+
+// APIError é uma resposta de erro que não é de rate limit (ex: 503 com storage indisponível)
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+}
+
+// Error implementa a interface error
+func (e *APIError) Error() string {
+	return fmt.Sprintf("rate limiter returned %d: %s", e.StatusCode, e.Message)
+}
+
+// IsRateLimited informa se o erro (ou algum erro encadeado) é um *RateLimitedError
+func IsRateLimited(err error) (*RateLimitedError, bool) {
+	var limited *RateLimitedError
+	if errors.As(err, &limited) {
+		return limited, true
+	}
+	return nil, false
+}
+
+// newRateLimitedError combina os headers com o corpo; os headers prevalecem,
+// pois também estão presentes em respostas HEAD
+func newRateLimitedError(header http.Header, body errorBody) *RateLimitedError {
+	limited := &RateLimitedError{
+		Decision: Decision{
+			Limit:       body.Details.Limit,
+			Remaining:   body.Details.Remaining,
+			LimiterType: body.Details.LimiterType,
+		},
+		Message:   body.Message,
+		DocsURL:   body.DocsURL,
+		RequestID: body.RequestID,
+	}
+	if body.Details.ResetTime > 0 {
+		limited.Reset = time.Unix(body.Details.ResetTime, 0)
+	}
+	if body.Details.BlockedUntil > 0 {
+		blockedUntil := time.Unix(body.Details.BlockedUntil, 0)
+		limited.BlockedUntil = &blockedUntil
+	}
+
+	if decision := DecisionFromHeaders(header); decision != nil {
+		limited.Limit = decision.Limit
+		limited.Remaining = decision.Remaining
+		if !decision.Reset.IsZero() {
+			limited.Reset = decision.Reset
+		}
+		if decision.LimiterType != "" {
+			limited.LimiterType = decision.LimiterType
+		}
+	}
+	if seconds, err := strconv.Atoi(header.Get("Retry-After")); err == nil && seconds > 0 {
+		limited.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	if limited.RequestID == "" {
+		limited.RequestID = header.Get("X-Request-ID")
+	}
+	return limited
+}
@@ -0,0 +1,317 @@
+// Package ratelimiter embute o rate limiter em uma aplicação Gin existente.
+// Attach carrega a configuração das mesmas variáveis de ambiente e do mesmo
+// tokens.json da API, cria o storage, o service e o monitor de saúde, e monta
+// as rotas administrativas e de status no router da aplicação:
+//
+//	limiter, err := ratelimiter.Attach(router, ratelimiter.WithAdminPrefix("/internal/ratelimit"))
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer limiter.Close()
+//
+//	api := router.Group("/api", limiter.Middleware())
+//
+// Recursos que dependem do processo da API (drenagem, registro na frota,
+// tokens no banco, analytics etc.) continuam exclusivos do cmd/api.
+package ratelimiter
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"rate-limiter/internal/adminauth"
+	"rate-limiter/internal/config"
+	"rate-limiter/internal/domain"
+	"rate-limiter/internal/handler"
+	"rate-limiter/internal/logger"
+	"rate-limiter/internal/middleware"
+	"rate-limiter/internal/service"
+	"rate-limiter/internal/storage"
+)
+
+// Prefixos padrão das rotas montadas por Attach
+const (
+	DefaultAdminPrefix  = "/admin"
+	DefaultStatusPrefix = "/ratelimit"
+)
+
+// options são as escolhas de Attach
+type options struct {
+	adminPrefix  string // vazio = API administrativa desabilitada
+	statusPrefix string // vazio = rotas de status desabilitadas
+	storageType  string // vazio = STORAGE_TYPE (padrão "redis")
+	global       bool
+}
+
+// Option configura Attach
+type Option func(*options)
+
+// WithAdminPrefix monta a API administrativa em prefix (padrão DefaultAdminPrefix)
+func WithAdminPrefix(prefix string) Option {
+	return func(o *options) {
+		o.adminPrefix = prefix
+	}
+}
+
+// WithoutAdmin não monta a API administrativa
+func WithoutAdmin() Option {
+	return func(o *options) {
+		o.adminPrefix = ""
+	}
+}
+
+// WithStatusPrefix monta /health, /ready, /livez, /readyz, /metrics e /me/limits em prefix
+// (padrão DefaultStatusPrefix, para não conflitar com o /health da aplicação)
+func WithStatusPrefix(prefix string) Option {
+	return func(o *options) {
+		o.statusPrefix = prefix
+	}
+}
+
+// WithoutStatus não monta as rotas de status
+func WithoutStatus() Option {
+	return func(o *options) {
+		o.statusPrefix = ""
+	}
+}
+
+// WithStorageType escolhe o storage ("memory", "redis", "hybrid", "tiered" ou "etcd")
+// no lugar da variável STORAGE_TYPE
+func WithStorageType(storageType string) Option {
+	return func(o *options) {
+		o.storageType = storageType
+	}
+}
+
+// WithGlobalMiddleware aplica o rate limiting a todo o router. Como no Gin,
+// vale apenas para as rotas registradas depois de Attach
+func WithGlobalMiddleware() Option {
+	return func(o *options) {
+		o.global = true
+	}
+}
+
+// Limiter é o rate limiter montado em um router
+type Limiter struct {
+	middleware    gin.HandlerFunc
+	storage       domain.RateLimiterStorage
+	healthMonitor *storage.HealthMonitor
+}
+
+// Attach carrega a configuração, cria o rate limiter e monta suas rotas no router.
+// Se o storage configurado não puder ser criado, usa memória, como a API
+func Attach(router gin.IRouter, opts ...Option) (*Limiter, error) {
+	o := options{adminPrefix: DefaultAdminPrefix, statusPrefix: DefaultStatusPrefix}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	configLoader := config.NewConfigLoader()
+	cfg, err := configLoader.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	serverConfig := configLoader.GetConfig()
+	appLogger := logger.NewLogger(serverConfig.LogLevel, serverConfig.LogFormat)
+
+	if o.storageType == "" {
+		o.storageType = getEnvWithDefault("STORAGE_TYPE", string(storage.RedisStorageType))
+	}
+	rateLimiterStorage, err := newStorage(serverConfig, o.storageType, appLogger)
+	if err != nil {
+		return nil, err
+	}
+
+	healthMonitor := storage.NewHealthMonitor(
+		rateLimiterStorage,
+		appLogger,
+		time.Duration(serverConfig.HealthCheckInterval)*time.Second,
+		time.Duration(serverConfig.HealthCheckMaxBackoff)*time.Second,
+	)
+	healthMonitor.SetRecoveryChecks(serverConfig.HealthRecoveryChecks)
+	healthMonitor.Start()
+
+	rateLimiterService := service.NewRateLimiterService(
+		rateLimiterStorage,
+		cfg,
+		appLogger,
+		service.WithHealthReporter(healthMonitor),
+	)
+
+	handlers := handler.NewHandlers(rateLimiterService, appLogger)
+	handlers.SetHealthReporter(healthMonitor)
+	handlers.SetReadinessGate(storage.NewReadinessGate(healthMonitor, serverConfig.ReadinessHealthChecks))
+
+	adminKeys, _ := adminauth.ParseKeys(serverConfig.AdminKeys)
+	if adminAuth := adminauth.New(adminauth.Config{
+		APIKey:     serverConfig.AdminAPIKey,
+		HMACSecret: serverConfig.AdminHMACSecret,
+		MaxSkew:    time.Duration(serverConfig.AdminSignatureMaxSkew) * time.Second,
+		Keys:       adminKeys,
+	}); adminAuth != nil {
+		handlers.SetAdminAuthenticator(adminAuth)
+	} else if o.adminPrefix != "" {
+		appLogger.Warn("Admin API is not authenticated", map[string]interface{}{
+			"prefix": o.adminPrefix,
+			"hint":   "set ADMIN_API_KEY, ADMIN_HMAC_SECRET or ADMIN_KEYS",
+		})
+	}
+
+	middlewareConfig, err := newMiddlewareConfig(serverConfig)
+	if err != nil {
+		healthMonitor.Stop()
+		rateLimiterStorage.Close()
+		return nil, err
+	}
+	handlers.SetMiddlewareConfig(middlewareConfig)
+
+	limiter := &Limiter{
+		middleware:    handlers.RateLimiterMiddleware(),
+		storage:       rateLimiterStorage,
+		healthMonitor: healthMonitor,
+	}
+
+	if o.statusPrefix != "" {
+		handlers.SetupPublicRoutes(router, o.statusPrefix)
+		// /me/limits conta na cota do cliente, como as rotas da aplicação
+		router.Group(o.statusPrefix).GET("/me/limits", limiter.middleware, handlers.MeLimitsHandler)
+	}
+	if o.adminPrefix != "" {
+		handlers.SetupAdminRoutes(router, o.adminPrefix)
+	}
+	if o.global {
+		router.Use(limiter.middleware)
+	}
+
+	appLogger.Info("Rate limiter attached", map[string]interface{}{
+		"storage_type":  o.storageType,
+		"admin_prefix":  o.adminPrefix,
+		"status_prefix": o.statusPrefix,
+		"global":        o.global,
+	})
+	return limiter, nil
+}
+
+// Middleware retorna o middleware de rate limiting, para grupos ou rotas específicas
+func (l *Limiter) Middleware() gin.HandlerFunc {
+	return l.middleware
+}
+
+// Close para o monitor de saúde e fecha o storage
+func (l *Limiter) Close() error {
+	l.healthMonitor.Stop()
+	return l.storage.Close()
+}
+
+// newStorage cria o storage configurado, com fallback para memória
+func newStorage(serverConfig *config.Config, storageType string, appLogger domain.Logger) (domain.RateLimiterStorage, error) {
+	storageCfg := storage.BuildStorageConfigFromEnv(
+		storageType,
+		serverConfig.RedisHost,
+		serverConfig.RedisPort,
+		serverConfig.RedisPassword,
+		serverConfig.RedisDB,
+	)
+	if storageCfg.RedisConfig != nil {
+		storageCfg.RedisConfig.Username = serverConfig.RedisUsername
+		storageCfg.RedisConfig.TLS = &storage.RedisTLSConfig{
+			Enabled:            serverConfig.RedisTLSEnabled,
+			CAFile:             serverConfig.RedisTLSCAFile,
+			CertFile:           serverConfig.RedisTLSCertFile,
+			KeyFile:            serverConfig.RedisTLSKeyFile,
+			InsecureSkipVerify: serverConfig.RedisTLSInsecureSkipVerify,
+		}
+		storageCfg.RedisConfig.Mode = serverConfig.RedisMode
+		storageCfg.RedisConfig.MasterName = serverConfig.RedisSentinelMaster
+		storageCfg.RedisConfig.SentinelAddrs = serverConfig.RedisSentinelAddrs
+		storageCfg.RedisConfig.SentinelUsername = serverConfig.RedisSentinelUsername
+		storageCfg.RedisConfig.SentinelPassword = serverConfig.RedisSentinelPassword
+	}
+	storageCfg.MemoryConfig = &storage.MemoryConfig{ExpectedKeys: serverConfig.MemoryExpectedKeys, MaxEntries: serverConfig.MemoryMaxKeys}
+	storageCfg.HybridConfig = &storage.HybridConfig{
+		SyncInterval: time.Duration(serverConfig.HybridSyncInterval) * time.Millisecond,
+		BatchSize:    serverConfig.HybridSyncBatchSize,
+	}
+	storageCfg.TieredConfig = &storage.TieredConfig{CacheTTL: time.Duration(serverConfig.TieredCacheTTL) * time.Millisecond}
+	storageCfg.EtcdConfig = &storage.EtcdConfig{
+		Endpoints: serverConfig.EtcdEndpoints,
+		Username:  serverConfig.EtcdUsername,
+		Password:  serverConfig.EtcdPassword,
+		Prefix:    serverConfig.EtcdPrefix,
+	}
+	storageCfg.Algorithm = storage.Algorithm(serverConfig.RateLimitAlgorithm)
+	storageCfg.HistorySize = serverConfig.WindowHistorySize
+	if !storage.SupportsAlgorithm(storageCfg.Type, storageCfg.Algorithm) {
+		return nil, fmt.Errorf("storage type %s does not support the %s algorithm", storageType, serverConfig.RateLimitAlgorithm)
+	}
+
+	factory := storage.NewStorageFactory()
+	created, err := factory.CreateStorage(storageCfg, appLogger)
+	if err == nil {
+		return created, nil
+	}
+
+	appLogger.Error("Failed to initialize configured storage, falling back to memory", err, map[string]interface{}{
+		"storage_type": storageType,
+	})
+	created, err = factory.CreateStorage(&storage.StorageConfig{
+		Type:         storage.MemoryStorageType,
+		MemoryConfig: storageCfg.MemoryConfig,
+		Algorithm:    storageCfg.Algorithm,
+		HistorySize:  storageCfg.HistorySize,
+	}, appLogger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize fallback memory storage: %w", err)
+	}
+	return created, nil
+}
+
+// newMiddlewareConfig converte a configuração do middleware, com allowlist e probes
+func newMiddlewareConfig(serverConfig *config.Config) (middleware.Config, error) {
+	middlewareConfig := middleware.Config{
+		FailureMode: middleware.FailureMode(serverConfig.FailureMode),
+		Timeout:     time.Duration(serverConfig.DecisionTimeout) * time.Millisecond,
+		RejectionCache: middleware.RejectionCache{
+			TTL:     time.Duration(serverConfig.RateLimitedCacheTTL) * time.Second,
+			Headers: serverConfig.RateLimitedCacheHeaders,
+		},
+		Tarpit: middleware.Tarpit{
+			MaxDelay:      time.Duration(serverConfig.TarpitMaxDelay) * time.Millisecond,
+			BandPercent:   serverConfig.TarpitBandPercent,
+			MaxConcurrent: serverConfig.TarpitMaxConcurrent,
+		},
+		Challenge: middleware.Challenge{
+			URL:    serverConfig.ChallengeURL,
+			Secret: serverConfig.ChallengeSecret,
+		},
+	}
+
+	probeFilter, err := middleware.NewProbeFilter(serverConfig.ProbeUserAgents, serverConfig.ProbeSourceRanges)
+	if err != nil {
+		return middleware.Config{}, fmt.Errorf("failed to build probe filter: %w", err)
+	}
+	if !probeFilter.IsEmpty() {
+		middlewareConfig.PreChecks = append(middlewareConfig.PreChecks, probeFilter.PreCheck())
+	}
+
+	allowlist, err := middleware.NewAllowlist(serverConfig.AllowlistIPs, serverConfig.AllowlistTokens)
+	if err != nil {
+		return middleware.Config{}, fmt.Errorf("failed to build allowlist: %w", err)
+	}
+	if !allowlist.IsEmpty() {
+		middlewareConfig.PreChecks = append(middlewareConfig.PreChecks, allowlist.PreCheck())
+	}
+	return middlewareConfig, nil
+}
+
+// getEnvWithDefault lê a variável de ambiente, com valor padrão quando vazia
+func getEnvWithDefault(key, defaultValue string) string {
+	if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,114 @@
+package ratelimiter
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAttach(t *testing.T) {
+	// Arrange: aplicação existente com as próprias rotas
+	t.Setenv("DEFAULT_IP_LIMIT", "2")
+	t.Setenv("ADMIN_API_KEY", "admin-secret")
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/health", func(c *gin.Context) { c.String(http.StatusOK, "app") })
+
+	limiter, err := Attach(router, WithStorageType("memory"), WithAdminPrefix("/internal/ratelimit"))
+	require.NoError(t, err)
+	defer limiter.Close()
+
+	api := router.Group("/api", limiter.Middleware())
+	api.GET("/orders", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/public", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	serve := func(path string, setup func(req *http.Request)) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = "192.168.1.1:1234"
+		setup(req)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	none := func(req *http.Request) {}
+
+	// Act
+	var codes []int
+	for i := 0; i < 3; i++ {
+		codes = append(codes, serve("/api/orders", none).Code)
+	}
+	public := serve("/public", none)
+	appHealth := serve("/health", none)
+	status := serve("/ratelimit/health", none)
+	unauthorized := serve("/internal/ratelimit/status?type=ip&key=192.168.1.1", none)
+	admin := serve("/internal/ratelimit/status?type=ip&key=192.168.1.1", func(req *http.Request) {
+		req.Header.Set("Authorization", "Bearer admin-secret")
+	})
+
+	// Assert: só o grupo com o middleware é limitado
+	assert.Equal(t, []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}, codes)
+	assert.Equal(t, http.StatusOK, public.Code)
+
+	// As rotas de status ficam no prefixo próprio, sem tomar o /health da aplicação
+	assert.Equal(t, "app", appHealth.Body.String())
+	assert.Equal(t, http.StatusOK, status.Code)
+
+	// A API administrativa fica no prefixo configurado, com a autenticação do ambiente
+	assert.Equal(t, http.StatusUnauthorized, unauthorized.Code)
+	assert.Equal(t, http.StatusOK, admin.Code)
+	assert.Contains(t, admin.Body.String(), `"is_blocked":true`)
+}
+
+func TestAttach_Options(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		path     string
+		expected int
+	}{
+		{"default admin prefix", nil, "/admin/status", http.StatusBadRequest},
+		{"without admin", []Option{WithoutAdmin()}, "/admin/status", http.StatusNotFound},
+		{"without status", []Option{WithoutStatus()}, "/ratelimit/health", http.StatusNotFound},
+		{"status prefix", []Option{WithStatusPrefix("/rl")}, "/rl/ready", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			limiter, err := Attach(router, append(tt.opts, WithStorageType("memory"))...)
+			require.NoError(t, err)
+			defer limiter.Close()
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			assert.Equal(t, tt.expected, w.Code)
+		})
+	}
+}
+
+func TestAttach_GlobalMiddleware(t *testing.T) {
+	// Arrange
+	t.Setenv("DEFAULT_IP_LIMIT", "1")
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	limiter, err := Attach(router, WithStorageType("memory"), WithGlobalMiddleware())
+	require.NoError(t, err)
+	defer limiter.Close()
+	router.GET("/orders", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	// Act
+	first, second := httptest.NewRecorder(), httptest.NewRecorder()
+	router.ServeHTTP(first, httptest.NewRequest(http.MethodGet, "/orders", nil))
+	router.ServeHTTP(second, httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, http.StatusTooManyRequests, second.Code)
+}
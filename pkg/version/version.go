@@ -0,0 +1,13 @@
+// Package version expõe a versão semântica do contrato HTTP do rate limiter.
+//
+// A versão segue SemVer sobre o que os clientes enxergam: headers X-RateLimit-*,
+// corpo do 429 e payloads dos endpoints administrativos. Mudanças nesses
+// formatos precisam atualizar os golden files de tests/contract e a versão:
+//
+//   - MAJOR: remover ou renomear campo/header, mudar tipo ou status HTTP
+//   - MINOR: adicionar campo, header ou endpoint
+//   - PATCH: correções sem mudança de formato
+package version
+
+// Version é a versão atual da API, reportada no /health e nos logs
+const Version = "1.0.0"
@@ -0,0 +1,346 @@
+// Package contract verifica o contrato HTTP do rate limiter contra golden files:
+// headers de rate limit, corpo do 429 e payloads administrativos. Valores que
+// mudam a cada requisição (contagens, horários, IDs) viram o tipo, então o
+// golden guarda o formato: nomes dos campos, tipos, status e valores estáveis.
+//
+// Por padrão a suíte sobe a API em processo. Times que consomem a API podem
+// rodá-la contra uma instância de uma nova versão antes de atualizar:
+//
+//	CONTRACT_BASE_URL=https://rate-limiter.staging CONTRACT_ADMIN_KEY=... go test ./tests/contract/
+//
+// A instância precisa confiar no X-Forwarded-For da suíte (cada execução usa
+// IPs próprios). Uma mudança intencional de formato é registrada com
+//
+//	go test ./tests/contract/ -update
+//
+// e acompanha a versão em pkg/version e a nota de migração no README.
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"rate-limiter/internal/config"
+	"rate-limiter/internal/handler"
+	"rate-limiter/internal/logger"
+	"rate-limiter/internal/service"
+	"rate-limiter/internal/storage"
+	"rate-limiter/pkg/version"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files with the current responses")
+
+// contractHeaders são os headers de resposta que fazem parte do contrato
+var contractHeaders = []string{
+	"Content-Type",
+	"Retry-After",
+	"X-Ratelimit-Limit",
+	"X-Ratelimit-Remaining",
+	"X-Ratelimit-Reset",
+	"X-Ratelimit-Type",
+	"X-Request-Id",
+}
+
+// stableHeaders são os headers cujo valor, e não só o tipo, faz parte do contrato
+var stableHeaders = map[string]bool{
+	"Content-Type":     true,
+	"X-Ratelimit-Type": true,
+}
+
+// stableFields são os campos do corpo cujo valor, e não só o tipo, faz parte do contrato
+var stableFields = map[string]bool{
+	"error":        true,
+	"limiter_type": true,
+	"status":       true,
+	"type":         true,
+}
+
+// semver valida a versão reportada no /health (MAJOR.MINOR.PATCH)
+var semver = regexp.MustCompile(`^\d+\.\d+\.\d+$`)
+
+// maxRequestsToLimit limita as requisições até a API responder 429
+const maxRequestsToLimit = 1000
+
+// contractTarget é a API sob teste
+type contractTarget struct {
+	baseURL  string
+	adminKey string
+	client   *http.Client
+}
+
+// newContractTarget aponta para CONTRACT_BASE_URL ou, sem ela, sobe a API em processo
+func newContractTarget(t *testing.T) *contractTarget {
+	t.Helper()
+
+	target := &contractTarget{client: &http.Client{Timeout: 10 * time.Second}}
+	if baseURL := os.Getenv("CONTRACT_BASE_URL"); baseURL != "" {
+		target.baseURL = strings.TrimSuffix(baseURL, "/")
+		target.adminKey = os.Getenv("CONTRACT_ADMIN_KEY")
+		return target
+	}
+
+	gin.SetMode(gin.TestMode)
+	cfg, err := config.NewConfigLoader().LoadConfig()
+	require.NoError(t, err)
+
+	appLogger := logger.NewNopLogger()
+	memory := storage.NewMemoryStorage(appLogger)
+	handlers := handler.NewHandlers(service.NewRateLimiterService(memory, cfg, appLogger), appLogger)
+	handlers.SetStorageRegistry(storage.NewRegistry(string(storage.MemoryStorageType), memory))
+
+	router := gin.New()
+	router.Use(gin.Recovery())
+	handlers.SetupRoutes(router)
+	server := httptest.NewServer(router)
+	t.Cleanup(func() {
+		server.Close()
+		memory.Close()
+	})
+
+	target.baseURL = server.URL
+	return target
+}
+
+// clientIP gera um IP de teste (198.18.0.0/15) para isolar a execução de outras
+func clientIP() string {
+	return fmt.Sprintf("198.18.%d.%d", rand.Intn(256), rand.Intn(254)+1)
+}
+
+// do envia a requisição como o cliente ip; admin autentica na API administrativa
+func (target *contractTarget) do(t *testing.T, method, path, ip, body string, admin bool) *http.Response {
+	t.Helper()
+
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req, err := http.NewRequest(method, target.baseURL+path, reader)
+	require.NoError(t, err)
+	req.Header.Set("X-Forwarded-For", ip)
+	req.Header.Set("Accept", "application/json")
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if admin && target.adminKey != "" {
+		req.Header.Set("Authorization", "Bearer "+target.adminKey)
+	}
+
+	resp, err := target.client.Do(req)
+	require.NoError(t, err)
+	return resp
+}
+
+// snapshot é o formato de uma resposta guardado no golden file
+type snapshot struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers"`
+	Body    interface{}       `json:"body"`
+}
+
+// capture lê e normaliza a resposta
+func capture(t *testing.T, resp *http.Response) snapshot {
+	t.Helper()
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	result := snapshot{Status: resp.StatusCode, Headers: map[string]string{}}
+	for _, name := range contractHeaders {
+		if value := resp.Header.Get(name); value != "" {
+			result.Headers[name] = normalizeHeader(name, value)
+		}
+	}
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		return result
+	}
+	var body interface{}
+	if err := json.Unmarshal(data, &body); err != nil {
+		result.Body = "<text>"
+		return result
+	}
+	result.Body = shape("", body)
+	return result
+}
+
+// normalizeHeader troca o valor do header pelo tipo, exceto nos headers estáveis
+func normalizeHeader(name, value string) string {
+	if stableHeaders[name] {
+		return value
+	}
+	if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return "<number>"
+	}
+	return "<string>"
+}
+
+// shape reduz o valor ao formato: objetos mantêm os campos, listas o formato do
+// primeiro item e escalares viram o tipo (exceto campos estáveis e booleanos)
+func shape(field string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = shape(key, item)
+		}
+		return result
+	case []interface{}:
+		if len(v) == 0 {
+			return []interface{}{}
+		}
+		return []interface{}{shape(field, v[0])}
+	case string:
+		if stableFields[field] {
+			return v
+		}
+		return "<string>"
+	case float64:
+		return "<number>"
+	default:
+		return v
+	}
+}
+
+// assertGolden compara a resposta com testdata/<name>.json (ou o reescreve com -update)
+func assertGolden(t *testing.T, name string, actual snapshot) {
+	t.Helper()
+
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	require.NoError(t, encoder.Encode(actual))
+	data := buffer.Bytes()
+
+	path := filepath.Join("testdata", name+".json")
+	if *update {
+		require.NoError(t, os.MkdirAll("testdata", 0o755))
+		require.NoError(t, os.WriteFile(path, data, 0o644))
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	require.NoError(t, err, "missing golden file; run go test ./tests/contract/ -update")
+	assert.JSONEq(t, string(expected), string(data), "response format changed: %s", name)
+}
+
+// limit envia requisições como o cliente ip até a API responder 429
+func (target *contractTarget) limit(t *testing.T, ip string) *http.Response {
+	t.Helper()
+
+	for i := 0; i < maxRequestsToLimit; i++ {
+		resp := target.do(t, http.MethodGet, "/", ip, "", false)
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return resp
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	require.FailNow(t, "rate limit not reached", "no 429 after %d requests", maxRequestsToLimit)
+	return nil
+}
+
+func TestContract_Health(t *testing.T) {
+	target := newContractTarget(t)
+
+	assertGolden(t, "health", capture(t, target.do(t, http.MethodGet, "/health", clientIP(), "", false)))
+}
+
+// TestContract_Version testa que o /health reporta a versão semântica; em processo,
+// a de pkg/version
+func TestContract_Version(t *testing.T) {
+	target := newContractTarget(t)
+	resp := target.do(t, http.MethodGet, "/health", clientIP(), "", false)
+	defer resp.Body.Close()
+
+	var health struct {
+		Version string `json:"version"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&health))
+
+	assert.Regexp(t, semver, health.Version)
+	if os.Getenv("CONTRACT_BASE_URL") == "" {
+		assert.Equal(t, version.Version, health.Version)
+	}
+}
+
+func TestContract_Allowed(t *testing.T) {
+	target := newContractTarget(t)
+
+	assertGolden(t, "allowed", capture(t, target.do(t, http.MethodGet, "/check", clientIP(), "", false)))
+}
+
+func TestContract_RateLimited(t *testing.T) {
+	target := newContractTarget(t)
+	ip := clientIP()
+
+	// A requisição que estoura o limite e a seguinte, já com a chave bloqueada
+	assertGolden(t, "rate_limited", capture(t, target.limit(t, ip)))
+	assertGolden(t, "blocked", capture(t, target.do(t, http.MethodGet, "/", ip, "", false)))
+}
+
+func TestContract_Admin(t *testing.T) {
+	target := newContractTarget(t)
+	ip := clientIP()
+	capture(t, target.limit(t, ip))
+	reset := fmt.Sprintf(`{"key":%q,"type":"ip"}`, ip)
+
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{"admin_status", http.MethodGet, "/admin/status?type=ip&key=" + ip, ""},
+		{"admin_reset", http.MethodPost, "/admin/reset", reset},
+		{"admin_status_after_reset", http.MethodGet, "/admin/status?type=ip&key=" + ip, ""},
+		{"admin_reset_invalid", http.MethodPost, "/admin/reset", `{}`},
+	}
+
+	// Em sequência: cada caso depende do estado deixado pelo anterior
+	for _, tc := range cases {
+		assertGolden(t, tc.name, capture(t, target.do(t, tc.method, tc.path, ip, tc.body, true)))
+	}
+}
+
+// TestContract_GoldenFiles garante que todo golden file corresponde a um caso
+func TestContract_GoldenFiles(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*.json"))
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		names = append(names, strings.TrimSuffix(filepath.Base(file), ".json"))
+	}
+	sort.Strings(names)
+
+	assert.Equal(t, []string{
+		"admin_reset",
+		"admin_reset_invalid",
+		"admin_status",
+		"admin_status_after_reset",
+		"allowed",
+		"blocked",
+		"health",
+		"rate_limited",
+	}, names)
+}
@@ -2,6 +2,7 @@ package e2e
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -18,6 +19,7 @@ import (
 	"rate-limiter/internal/logger"
 	"rate-limiter/internal/service"
 	"rate-limiter/internal/storage"
+	"rate-limiter/pkg/client"
 )
 
 // E2ETestSuite contém os componentes necessários para os testes E2E
@@ -186,6 +188,29 @@ func TestE2E_RateLimiter_IPLimiting(t *testing.T) {
 	})
 }
 
+// TestE2E_ClientSDK_RateLimitedError testa o SDK contra as respostas reais do middleware
+func TestE2E_ClientSDK_RateLimitedError(t *testing.T) {
+	suite := setupE2ETest(t)
+	defer suite.teardownE2ETest()
+
+	sdk := client.New(suite.server.URL)
+
+	var checkErr error
+	for i := 0; i < 15 && checkErr == nil; i++ {
+		_, checkErr = sdk.Check(context.Background())
+	}
+
+	limited, ok := client.IsRateLimited(checkErr)
+	require.True(t, ok, "expected a RateLimitedError, got %v", checkErr)
+	assert.Equal(t, 10, limited.Limit)
+	assert.Equal(t, 0, limited.Remaining)
+	assert.Equal(t, "ip", limited.LimiterType)
+	require.NotNil(t, limited.BlockedUntil)
+	assert.True(t, limited.BlockedUntil.After(time.Now()))
+	assert.Greater(t, limited.RetryAfter, time.Duration(0))
+	assert.NotEmpty(t, limited.RequestID)
+}
+
 // TestE2E_RateLimiter_TokenLimiting testa limitação por token
 func TestE2E_RateLimiter_TokenLimiting(t *testing.T) {
 	suite := setupE2ETest(t)